@@ -6,9 +6,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/spacemeshos/go-scale"
 	"github.com/spacemeshos/post/shared"
 	"go.uber.org/zap"
@@ -42,6 +44,13 @@ type PoetConfig struct {
 	PhaseShift time.Duration `mapstructure:"phase-shift"`
 	// CycleGap gives the duration between the end of a PoET round and the start of the next
 	CycleGap time.Duration `mapstructure:"cycle-gap"`
+	// FallbackCycleGap is the cycle gap of the fallback poets, that are used instead of the configured
+	// ones if generating a PoST takes longer than CycleGap.
+	FallbackCycleGap time.Duration `mapstructure:"fallback-cycle-gap"`
+	// LatePublishWindow extends the deadline to generate a PoST and publish an ATX past the end of
+	// the publish epoch. A late ATX is still accepted by the network, but may miss rewards in the
+	// target epoch. Zero disables late publishing.
+	LatePublishWindow time.Duration `mapstructure:"late-publish-window"`
 	// GracePeriod defines the time before the start of the next PoET round until the node
 	// waits before building its NiPoST challenge. Shorter durations allow the node to
 	// possibly pick a better positioning ATX, but come with the risk that the node might
@@ -54,6 +63,145 @@ type PoetConfig struct {
 	InfoCacheTTL                   time.Duration `mapstructure:"info-cache-ttl"`
 	PowParamsCacheTTL              time.Duration `mapstructure:"pow-params-cache-ttl"`
 	MaxRequestRetries              int           `mapstructure:"retry-max"`
+	// SubmitQuorum stops waiting for the remaining poet submissions once the challenge is registered
+	// with that many poets. Zero waits for all submissions.
+	SubmitQuorum int `mapstructure:"submit-quorum"`
+	// PreferredPoets are addresses of poets, a registration with any of them stops waiting for
+	// the remaining poet submissions.
+	PreferredPoets []string `mapstructure:"preferred-poets"`
+	// ProofQuorum stops waiting for the remaining poet proofs once that many proofs including the challenge
+	// are received, the best of them is used. Zero waits for the proofs of all registrations.
+	ProofQuorum int `mapstructure:"proof-quorum"`
+	// ProofTimeout bounds the time spent fetching the proof of a poet after its round ended, failed
+	// requests are retried until then. Zero makes a single attempt.
+	ProofTimeout time.Duration `mapstructure:"proof-timeout"`
+	// SecondaryAfter is the number of consecutive attempts to submit a challenge, in which all primary
+	// poets were unstable, before the challenge is submitted to the secondary poets as well. Zero is treated as one.
+	SecondaryAfter int `mapstructure:"secondary-after"`
+	// Overrides replace the request settings for individual poets.
+	Overrides []PoetOverride `mapstructure:"overrides"`
+}
+
+// PoetTransport is the protocol used to talk to a poet.
+type PoetTransport string
+
+const (
+	// PoetTransportHTTP uses the REST api of the poet.
+	PoetTransportHTTP PoetTransport = "http"
+	// PoetTransportGRPC uses the gRPC api of the poet. The host and port of the poet address are dialed,
+	// with TLS if the scheme of the address is https.
+	PoetTransportGRPC PoetTransport = "grpc"
+)
+
+// PoetTier is the tier of a poet in the failover pool.
+type PoetTier string
+
+const (
+	// PoetTierPrimary poets are always submitted to.
+	PoetTierPrimary PoetTier = "primary"
+	// PoetTierSecondary poets are submitted to only if the primary poets are unstable,
+	// see PoetConfig.SecondaryAfter.
+	PoetTierSecondary PoetTier = "secondary"
+)
+
+// PoetOverride overrides the request settings of PoetConfig for the poet with the address.
+// The address must include the scheme, e.g. "https://poet.example.org". Zero values keep the global setting.
+type PoetOverride struct {
+	Address string `mapstructure:"address"`
+	// Transport is the protocol used to talk to the poet, PoetTransportHTTP if empty.
+	Transport         PoetTransport `mapstructure:"transport"`
+	RequestTimeout    time.Duration `mapstructure:"poet-request-timeout"`
+	RequestRetryDelay time.Duration `mapstructure:"retry-delay"`
+	MaxRequestRetries int           `mapstructure:"retry-max"`
+	// ProofJitter is the maximal random delay after the end of a round before the proof is queried,
+	// the minimal delay is half of it. By default the jitter is a fraction of the cycle gap.
+	ProofJitter  time.Duration `mapstructure:"proof-jitter"`
+	ProofTimeout time.Duration `mapstructure:"proof-timeout"`
+	// Tier is the tier of the poet, PoetTierPrimary if empty.
+	Tier PoetTier `mapstructure:"tier"`
+	// Weight prefers the proof of the poet over proofs with the same leaf count of poets with a lower weight.
+	Weight int `mapstructure:"weight"`
+}
+
+// PoetSettings are the request settings in effect for a single poet.
+type PoetSettings struct {
+	Transport         PoetTransport
+	RequestTimeout    time.Duration
+	RequestRetryDelay time.Duration
+	MaxRequestRetries int
+	// MinProofJitter and MaxProofJitter bound the random delay after the end of a round
+	// before the proof is queried.
+	MinProofJitter time.Duration
+	MaxProofJitter time.Duration
+	ProofTimeout   time.Duration
+	Tier           PoetTier
+	Weight         int
+}
+
+// Fallback returns the config of the fallback poets, it differs in the cycle gap,
+// which is FallbackCycleGap if it is set.
+func (c PoetConfig) Fallback() PoetConfig {
+	if c.FallbackCycleGap != 0 {
+		c.CycleGap = c.FallbackCycleGap
+	}
+	return c
+}
+
+// Validate checks that the config is consistent with the epoch duration.
+func (c PoetConfig) Validate(epochDuration time.Duration) error {
+	if c.LatePublishWindow < 0 {
+		return fmt.Errorf("late publish window (%v) must not be negative", c.LatePublishWindow)
+	}
+	// a late ATX is published in its target epoch, past it the ATX is useless
+	if c.LatePublishWindow > 0 && c.LatePublishWindow >= epochDuration {
+		return fmt.Errorf("late publish window (%v) must be shorter than the epoch (%v)",
+			c.LatePublishWindow, epochDuration)
+	}
+	return nil
+}
+
+// Settings returns the settings of the poet with the given address and cycle gap,
+// with its override applied.
+func (c PoetConfig) Settings(address string, cycleGap time.Duration) PoetSettings {
+	settings := PoetSettings{
+		Transport:         PoetTransportHTTP,
+		RequestTimeout:    c.RequestTimeout,
+		RequestRetryDelay: c.RequestRetryDelay,
+		MaxRequestRetries: c.MaxRequestRetries,
+		MinProofJitter:    time.Duration(float64(cycleGap) * minPoetGetProofJitter / 100.0),
+		MaxProofJitter:    time.Duration(float64(cycleGap) * maxPoetGetProofJitter / 100.0),
+		ProofTimeout:      c.ProofTimeout,
+		Tier:              PoetTierPrimary,
+	}
+	idx := slices.IndexFunc(c.Overrides, func(o PoetOverride) bool { return o.Address == address })
+	if idx < 0 {
+		return settings
+	}
+	override := c.Overrides[idx]
+	if override.Transport != "" {
+		settings.Transport = override.Transport
+	}
+	if override.RequestTimeout != 0 {
+		settings.RequestTimeout = override.RequestTimeout
+	}
+	if override.RequestRetryDelay != 0 {
+		settings.RequestRetryDelay = override.RequestRetryDelay
+	}
+	if override.MaxRequestRetries != 0 {
+		settings.MaxRequestRetries = override.MaxRequestRetries
+	}
+	if override.ProofJitter != 0 {
+		settings.MinProofJitter = override.ProofJitter / 2
+		settings.MaxProofJitter = override.ProofJitter
+	}
+	if override.ProofTimeout != 0 {
+		settings.ProofTimeout = override.ProofTimeout
+	}
+	if override.Tier != "" {
+		settings.Tier = override.Tier
+	}
+	settings.Weight = override.Weight
+	return settings
 }
 
 func DefaultPoetConfig() PoetConfig {
@@ -73,6 +221,13 @@ const (
 type Config struct {
 	GoldenATXID      types.ATXID
 	RegossipInterval time.Duration
+	// PublishInterval is the minimal time between broadcasts of ATXs of different identities.
+	// Zero broadcasts ATXs as soon as they are ready. The node clamps it to the duration of an epoch,
+	// the window in which ATXs are published.
+	PublishInterval time.Duration
+	// AutoPauseEpochs pauses attempts to publish ATXs of an identity after they failed in that many
+	// consecutive epochs, until the identity is resumed with ResumeSmeshing. Zero never pauses.
+	AutoPauseEpochs int
 }
 
 // Builder struct is the struct that orchestrates the creation of activation transactions
@@ -89,6 +244,7 @@ type Builder struct {
 	nipostBuilder     nipostBuilder
 	validator         nipostValidator
 	layerClock        layerClock
+	clock             clockwork.Clock
 	syncer            syncer
 	logger            *zap.Logger
 	parentCtx         context.Context
@@ -101,9 +257,12 @@ type Builder struct {
 	versions []atxVersion
 
 	posAtxFinder positioningAtxFinder
+	pacer        *publishPacer
 
 	// states of each known identity
 	postStates PostStates
+	// failures of each identity to publish ATXs
+	health identitiesHealth
 
 	// smeshingMutex protects methods like `StartSmeshing` and `StopSmeshing` from concurrent execution
 	// since they (can) modify the fields below.
@@ -169,6 +328,14 @@ func WithPostStates(ps PostStates) BuilderOption {
 	}
 }
 
+// WithWallClock sets the clock that is used for scheduling instead of the system clock,
+// e.g. to fast-forward time in tests. It should be consistent with the layer clock.
+func WithWallClock(clock clockwork.Clock) BuilderOption {
+	return func(b *Builder) {
+		b.clock = clock
+	}
+}
+
 func BuilderAtxVersions(v AtxVersions) BuilderOption {
 	return func(h *Builder) {
 		h.versions = append([]atxVersion{{0, types.AtxV1}}, v.asSlice()...)
@@ -198,6 +365,7 @@ func NewBuilder(
 		publisher:         publisher,
 		nipostBuilder:     nipostBuilder,
 		layerClock:        layerClock,
+		clock:             clockwork.NewRealClock(),
 		syncer:            syncer,
 		logger:            log,
 		poetRetryInterval: defaultPoetRetryInterval,
@@ -208,6 +376,7 @@ func NewBuilder(
 	for _, opt := range opts {
 		opt(b)
 	}
+	b.pacer = newPublishPacer(conf.PublishInterval, b.clock)
 
 	return b
 }
@@ -244,7 +413,7 @@ func (b *Builder) PostStates() map[types.IdentityDescriptor]types.PostState {
 	defer b.smeshingMutex.Unlock()
 	for id, state := range states {
 		if sig, exists := b.signers[id]; exists {
-			res[sig] = state
+			res[sig] = b.health.state(id, state)
 		}
 	}
 	return res
@@ -264,6 +433,14 @@ func (b *Builder) StartSmeshing(coinbase types.Address) error {
 		return errors.New("already started")
 	}
 
+	states := b.postStates.Get()
+	for id := range b.signers {
+		if states[id] == types.PostStateInvalid {
+			return fmt.Errorf("%w: identity %s must pass verification of the initial post before smeshing",
+				ErrInvalidPostData, id.ShortString())
+		}
+	}
+
 	b.coinbaseAccount = coinbase
 	ctx, stop := context.WithCancel(b.parentCtx)
 	b.stop = stop
@@ -399,6 +576,69 @@ func (b *Builder) BuildInitialPost(ctx context.Context, nodeID types.NodeID) err
 	return nipost.AddPost(b.localDB, nodeID, initialPost)
 }
 
+// VerifyInitialPost regenerates the initial post of the identity from its existing post data
+// and verifies it, e.g. after the data was migrated to a different disk.
+//
+// The result is recorded in the post state of the identity. If the proof can't be generated
+// or is invalid, the identity is marked as invalid and smeshing is refused until the
+// verification succeeds. If the identity didn't publish an ATX yet, the regenerated proof
+// replaces the stored initial post.
+func (b *Builder) VerifyInitialPost(ctx context.Context, nodeID types.NodeID) error {
+	b.smeshingMutex.Lock()
+	_, registered := b.signers[nodeID]
+	smeshing := b.stop != nil
+	b.smeshingMutex.Unlock()
+	switch {
+	case !registered:
+		return fmt.Errorf("identity %s is not registered", nodeID.ShortString())
+	case smeshing:
+		return errors.New("can't verify initial post while smeshing")
+	}
+
+	logger := b.logger.With(log.ZShortStringer("smesherID", nodeID))
+	logger.Info("regenerating initial post from post data")
+	invalid := func(err error) error {
+		logger.Error("post data failed verification of the initial post", zap.Error(err))
+		b.postStates.Set(nodeID, types.PostStateInvalid)
+		return fmt.Errorf("%w: %w", ErrInvalidPostData, err)
+	}
+	post, postInfo, err := b.nipostBuilder.Proof(ctx, nodeID, shared.ZeroChallenge, nil)
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil:
+		return invalid(fmt.Errorf("post execution: %w", err))
+	case postInfo.Nonce == nil:
+		return invalid(errNilVrfNonce)
+	}
+	err = b.validator.PostV2(ctx, nodeID, postInfo.CommitmentATX, post, shared.ZeroChallenge, postInfo.NumUnits)
+	if err != nil {
+		return invalid(err)
+	}
+	b.postStates.Set(nodeID, types.PostStateIdle)
+	logger.Info("post data passed verification of the initial post")
+
+	if _, err := atxs.GetLastIDByNodeID(b.db, nodeID); err == nil {
+		return nil
+	}
+	initialPost := nipost.Post{
+		Nonce:     post.Nonce,
+		Indices:   post.Indices,
+		Pow:       post.Pow,
+		Challenge: shared.ZeroChallenge,
+
+		NumUnits:      postInfo.NumUnits,
+		CommitmentATX: postInfo.CommitmentATX,
+		VRFNonce:      *postInfo.Nonce,
+	}
+	return b.localDB.WithTx(ctx, func(tx sql.Transaction) error {
+		if err := nipost.RemovePost(tx, nodeID); err != nil {
+			return err
+		}
+		return nipost.AddPost(tx, nodeID, initialPost)
+	})
+}
+
 func (b *Builder) buildPost(ctx context.Context, nodeID types.NodeID) error {
 	for {
 		err := b.BuildInitialPost(ctx, nodeID)
@@ -417,6 +657,12 @@ func (b *Builder) buildPost(ctx context.Context, nodeID types.NodeID) error {
 
 func (b *Builder) run(ctx context.Context, sig *signing.EdSigner) {
 	defer b.logger.Info("atx builder stopped")
+	if b.postStates.Get()[sig.NodeID()] == types.PostStateInvalid {
+		b.logger.Error("not smeshing with identity that failed verification of the initial post",
+			log.ZShortStringer("smesherID", sig.NodeID()),
+		)
+		return
+	}
 	if err := b.buildPost(ctx, sig.NodeID()); err != nil {
 		b.logger.Error("failed to build initial post:", zap.Error(err))
 		return
@@ -442,12 +688,15 @@ func (b *Builder) run(ctx context.Context, sig *signing.EdSigner) {
 	for {
 		err := b.PublishActivationTx(ctx, sig)
 		if err == nil {
+			b.publishSucceeded(sig.NodeID())
 			continue
 		} else if errors.Is(err, context.Canceled) {
 			return
 		}
 
-		b.logger.Warn("failed to publish atx", zap.Error(err))
+		if err := b.publishFailed(ctx, sig.NodeID(), err); err != nil {
+			return
+		}
 
 		poetErr := &PoetSvcUnstableError{}
 		switch {
@@ -466,6 +715,17 @@ func (b *Builder) run(ctx context.Context, sig *signing.EdSigner) {
 				return
 			case <-b.layerClock.AwaitLayer(currentLayer.Add(1)):
 			}
+		case errors.Is(err, ErrPoetVersionUnsupported):
+			b.logger.Error("configured poets serve an unsupported api version, node needs to be upgraded",
+				log.ZShortStringer("smesherID", sig.NodeID()),
+				zap.Error(err),
+			)
+			b.postStates.Set(sig.NodeID(), types.PostStatePoetUnsupported)
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.clock.After(b.poetRetryInterval):
+			}
 		case errors.As(err, &poetErr):
 			b.logger.Warn("retrying after poet retry interval",
 				zap.Duration("interval", b.poetRetryInterval),
@@ -474,7 +734,7 @@ func (b *Builder) run(ctx context.Context, sig *signing.EdSigner) {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(b.poetRetryInterval):
+			case <-b.clock.After(b.poetRetryInterval):
 			}
 		case errors.Is(err, ErrInvalidInitialPost):
 			// delete the existing db post
@@ -538,11 +798,11 @@ func (b *Builder) BuildNIPostChallenge(ctx context.Context, nodeID types.NodeID)
 	}
 
 	// 2. check if we didn't miss beginning of PoET round
-	until := time.Until(b.poetRoundStart(currentEpochId))
+	until := b.poetRoundStart(currentEpochId).Sub(b.clock.Now())
 	if until <= 0 {
 		metrics.PublishLateWindowLatency.Observe(-until.Seconds())
 		currentEpochId++
-		until = time.Until(b.poetRoundStart(currentEpochId))
+		until = b.poetRoundStart(currentEpochId).Sub(b.clock.Now())
 	}
 
 	metrics.PublishOntimeWindowLatency.Observe(until.Seconds())
@@ -552,7 +812,7 @@ func (b *Builder) BuildNIPostChallenge(ctx context.Context, nodeID types.NodeID)
 	// 3. wait if needed till getting closer to PoET round start
 	poetStartsAt := b.poetRoundStart(currentEpochId)
 	wait := poetStartsAt.Add(-b.poetCfg.GracePeriod)
-	if time.Until(wait) > 0 {
+	if wait.After(b.clock.Now()) {
 		logger.Info("paused building NiPoST challenge. Waiting until closer to poet start to get a better posATX",
 			zap.Duration("till poet round", until),
 			zap.Uint32("current epoch", currentEpochId.Uint32()),
@@ -562,14 +822,14 @@ func (b *Builder) BuildNIPostChallenge(ctx context.Context, nodeID types.NodeID)
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-time.After(time.Until(wait)):
+		case <-b.clock.After(wait.Sub(b.clock.Now())):
 		}
 	}
 	if b.poetCfg.PositioningATXSelectionTimeout > 0 {
 		var cancel context.CancelFunc
 
 		deadline := poetStartsAt.Add(-b.poetCfg.GracePeriod).Add(b.poetCfg.PositioningATXSelectionTimeout)
-		ctx, cancel = context.WithDeadline(ctx, deadline)
+		ctx, cancel = context.WithTimeout(ctx, deadline.Sub(b.clock.Now()))
 		defer cancel()
 	}
 
@@ -624,7 +884,7 @@ func (b *Builder) getExistingChallenge(
 	case err != nil:
 		return nil, fmt.Errorf("get nipost challenge: %w", err)
 
-	case challenge.PublishEpoch < currentEpochId:
+	case challenge.PublishEpoch < currentEpochId && !b.inLatePublishWindow(challenge.PublishEpoch):
 		logger.Info(
 			"existing NiPoST challenge is stale, resetting state",
 			zap.Uint32("current_epoch", currentEpochId.Uint32()),
@@ -728,7 +988,8 @@ func (b *Builder) PublishActivationTx(ctx context.Context, sig *signing.EdSigner
 		zap.Object("challenge", challenge),
 	)
 	targetEpoch := challenge.PublishEpoch.Add(1)
-	ctx, cancel := context.WithDeadline(ctx, b.layerClock.LayerToTime(targetEpoch.FirstLayer()))
+	publishEpochEnd := b.layerClock.LayerToTime(targetEpoch.FirstLayer())
+	ctx, cancel := context.WithDeadline(ctx, publishEpochEnd.Add(b.poetCfg.LatePublishWindow))
 	defer cancel()
 	atx, err := b.createAtx(ctx, sig, challenge)
 	if err != nil {
@@ -747,19 +1008,42 @@ func (b *Builder) PublishActivationTx(ctx context.Context, sig *signing.EdSigner
 	case <-b.layerClock.AwaitLayer(challenge.PublishEpoch.FirstLayer()):
 	}
 
-	for {
+	b.postStates.Set(sig.NodeID(), types.PostStatePublishing)
+	// the state might have changed while publishing, e.g. the identity became invalid
+	defer b.postStates.CompareAndSet(sig.NodeID(), types.PostStatePublishing, types.PostStateIdle)
+	for attempt := 1; ; attempt++ {
+		if err := b.pacer.wait(ctx); err != nil {
+			return fmt.Errorf("wait for broadcast: %w", err)
+		}
 		b.logger.Info(
 			"broadcasting ATX",
 			log.ZShortStringer("atx_id", atx.ID()),
 			log.ZShortStringer("smesherID", sig.NodeID()),
+			zap.Int("attempt", attempt),
 			log.DebugField(b.logger, zap.Object("atx", atx)),
 		)
 		size, err := b.broadcast(ctx, atx)
 		if err == nil {
+			if b.clock.Now().After(publishEpochEnd) {
+				b.logger.Warn("atx published after the end of the publish epoch",
+					log.ZShortStringer("atx_id", atx.ID()),
+					zap.Int("size", size),
+					zap.Duration("late", b.clock.Now().Sub(publishEpochEnd)),
+				)
+				metrics.PublishedLate.Inc()
+				break
+			}
 			b.logger.Info("atx published", log.ZShortStringer("atx_id", atx.ID()), zap.Int("size", size))
+			metrics.PublishedOnTime.Inc()
 			break
 		}
 
+		b.logger.Warn("failed to broadcast ATX",
+			log.ZShortStringer("atx_id", atx.ID()),
+			log.ZShortStringer("smesherID", sig.NodeID()),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("broadcast: %w", ctx.Err())
@@ -784,6 +1068,16 @@ func (b *Builder) PublishActivationTx(ctx context.Context, sig *signing.EdSigner
 	return nil
 }
 
+// inLatePublishWindow returns true if the publish epoch has ended, but an ATX for it
+// can still be published within the configured late publish window.
+func (b *Builder) inLatePublishWindow(publish types.EpochID) bool {
+	if b.poetCfg.LatePublishWindow == 0 {
+		return false
+	}
+	publishEpochEnd := b.layerClock.LayerToTime((publish + 1).FirstLayer())
+	return b.clock.Now().Before(publishEpochEnd.Add(b.poetCfg.LatePublishWindow))
+}
+
 func (b *Builder) poetRoundStart(epoch types.EpochID) time.Time {
 	return b.layerClock.LayerToTime(epoch.FirstLayer()).Add(b.poetCfg.PhaseShift)
 }
@@ -816,7 +1110,7 @@ func (b *Builder) createAtx(
 		return nil, fmt.Errorf("build NIPost: %w", err)
 	}
 
-	if challenge.PublishEpoch < b.layerClock.CurrentLayer().GetEpoch() {
+	if challenge.PublishEpoch < b.layerClock.CurrentLayer().GetEpoch() && !b.inLatePublishWindow(challenge.PublishEpoch) {
 		if challenge.PrevATXID == types.EmptyATXID {
 			// initial NIPoST challenge is not discarded; don't return ErrATXChallengeExpired
 			return nil, errors.New("atx publish epoch has passed during nipost construction")
@@ -944,7 +1238,7 @@ func (b *Builder) searchPositioningAtx(
 		b.conf.GoldenATXID,
 		b.validator,
 		logger,
-		VerifyChainOpts.AssumeValidBefore(time.Now().Add(-b.postValidityDelay)),
+		VerifyChainOpts.AssumeValidBefore(b.clock.Now().Add(-b.postValidityDelay)),
 		VerifyChainOpts.WithTrustedID(nodeID),
 		VerifyChainOpts.WithLogger(b.logger),
 	)
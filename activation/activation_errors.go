@@ -4,6 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
 )
 
 var (
@@ -11,6 +16,10 @@ var (
 	ErrATXChallengeExpired = errors.New("builder: atx expired")
 	// ErrPoetProofNotReceived is returned when no poet proof was received.
 	ErrPoetProofNotReceived = errors.New("builder: didn't receive any poet proof")
+	// ErrInvalidPostData is returned when the initial post regenerated from the post data of an identity is invalid.
+	ErrInvalidPostData = errors.New("builder: invalid post data")
+	// ErrNotPaused is returned when resuming an identity whose attempts to publish ATXs aren't paused.
+	ErrNotPaused = errors.New("builder: identity is not paused")
 )
 
 // PoetSvcUnstableError means there was a problem communicating
@@ -50,3 +59,64 @@ func (e PoetRegistrationMismatchError) Error() string {
 	}
 	return sb.String()
 }
+
+// NIPostPhase is the phase of building a NIPoST.
+type NIPostPhase string
+
+const (
+	// NIPostPhaseSubmit is the submission of the challenge to the poets.
+	NIPostPhaseSubmit NIPostPhase = "submit"
+	// NIPostPhasePoetProof is waiting for the poet proof and fetching it.
+	NIPostPhasePoetProof NIPostPhase = "poet_proof"
+	// NIPostPhasePost is the generation of the PoST proof.
+	NIPostPhasePost NIPostPhase = "post"
+)
+
+// NIPostError is returned by BuildNIPost. It wraps the error that caused the failure, so
+// errors.Is and errors.As still match ErrATXChallengeExpired, ErrPoetProofNotReceived,
+// PoetSvcUnstableError and the like.
+type NIPostError struct {
+	Phase        NIPostPhase
+	PublishEpoch types.EpochID
+	// Poet is the address of the poet the error relates to, empty if it isn't specific to a single poet.
+	Poet string
+	// Round is the round of Poet the challenge was registered in, empty if it isn't known.
+	Round string
+	// Deadline is the time by which the phase has to complete.
+	Deadline time.Time
+	// Retryable is true if building the NIPoST can be retried with the same challenge.
+	// Otherwise the challenge has to be discarded or the configuration of the node fixed.
+	Retryable bool
+	Err       error
+}
+
+func (e *NIPostError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "nipost %s phase for publish epoch %d", e.Phase, e.PublishEpoch)
+	if e.Poet != "" {
+		fmt.Fprintf(&sb, " (poet %s", e.Poet)
+		if e.Round != "" {
+			fmt.Fprintf(&sb, " round %s", e.Round)
+		}
+		sb.WriteString(")")
+	}
+	fmt.Fprintf(&sb, ": %v", e.Err)
+	return sb.String()
+}
+
+func (e *NIPostError) Unwrap() error { return e.Err }
+
+func (e *NIPostError) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("phase", string(e.Phase))
+	encoder.AddUint32("publish_epoch", e.PublishEpoch.Uint32())
+	if e.Poet != "" {
+		encoder.AddString("poet", e.Poet)
+	}
+	if e.Round != "" {
+		encoder.AddString("round", e.Round)
+	}
+	encoder.AddTime("deadline", e.Deadline)
+	encoder.AddBool("retryable", e.Retryable)
+	encoder.AddString("error", e.Err.Error())
+	return nil
+}
@@ -1573,3 +1573,98 @@ func Test_Builder_RegenerateInitialPost(t *testing.T) {
 	})
 	t.Cleanup(func() { assert.NoError(t, eg.Wait()) })
 }
+
+func TestBuilder_VerifyInitialPost(t *testing.T) {
+	commitmentATX := types.RandomATXID()
+	nonce := types.VRFPostIndex(rand.Uint64())
+	numUnits := uint32(12)
+	initialPost := &types.Post{
+		Nonce:   rand.Uint32(),
+		Indices: types.RandomBytes(10),
+		Pow:     rand.Uint64(),
+	}
+	postInfo := func(nodeID types.NodeID) *types.PostInfo {
+		return &types.PostInfo{
+			NodeID:        nodeID,
+			CommitmentATX: commitmentATX,
+			Nonce:         &nonce,
+
+			NumUnits:      numUnits,
+			LabelsPerUnit: DefaultPostConfig().LabelsPerUnit,
+		}
+	}
+
+	t.Run("valid post replaces stored initial post", func(t *testing.T) {
+		tab := newTestBuilder(t, 1)
+		sig := maps.Values(tab.signers)[0]
+		require.NoError(t, nipost.AddPost(tab.localDb, sig.NodeID(), nipost.Post{
+			Indices:       types.RandomBytes(10),
+			Challenge:     shared.ZeroChallenge,
+			NumUnits:      numUnits,
+			CommitmentATX: commitmentATX,
+		}))
+
+		tab.mnipost.EXPECT().Proof(gomock.Any(), sig.NodeID(), shared.ZeroChallenge, nil).
+			Return(initialPost, postInfo(sig.NodeID()), nil)
+		tab.mValidator.EXPECT().
+			PostV2(gomock.Any(), sig.NodeID(), commitmentATX, initialPost, shared.ZeroChallenge, numUnits)
+		require.NoError(t, tab.VerifyInitialPost(context.Background(), sig.NodeID()))
+		require.Equal(t, types.PostStateIdle, tab.postStates.Get()[sig.NodeID()])
+
+		post, err := nipost.GetPost(tab.localDb, sig.NodeID())
+		require.NoError(t, err)
+		require.Equal(t, initialPost.Indices, post.Indices)
+		require.Equal(t, nonce, post.VRFNonce)
+	})
+	t.Run("invalid post refuses smeshing", func(t *testing.T) {
+		tab := newTestBuilder(t, 1)
+		sig := maps.Values(tab.signers)[0]
+
+		tab.mnipost.EXPECT().Proof(gomock.Any(), sig.NodeID(), shared.ZeroChallenge, nil).
+			Return(initialPost, postInfo(sig.NodeID()), nil)
+		tab.mValidator.EXPECT().
+			PostV2(gomock.Any(), sig.NodeID(), commitmentATX, initialPost, shared.ZeroChallenge, numUnits).
+			Return(errors.New("invalid post"))
+		err := tab.VerifyInitialPost(context.Background(), sig.NodeID())
+		require.ErrorIs(t, err, ErrInvalidPostData)
+		require.Equal(t, types.PostStateInvalid, tab.postStates.Get()[sig.NodeID()])
+
+		err = tab.StartSmeshing(types.Address{})
+		require.ErrorIs(t, err, ErrInvalidPostData)
+		require.False(t, tab.Smeshing())
+
+		// successful verification allows smeshing again
+		tab.mnipost.EXPECT().Proof(gomock.Any(), sig.NodeID(), shared.ZeroChallenge, nil).
+			Return(initialPost, postInfo(sig.NodeID()), nil)
+		tab.mValidator.EXPECT().
+			PostV2(gomock.Any(), sig.NodeID(), commitmentATX, initialPost, shared.ZeroChallenge, numUnits)
+		require.NoError(t, tab.VerifyInitialPost(context.Background(), sig.NodeID()))
+		require.Equal(t, types.PostStateIdle, tab.postStates.Get()[sig.NodeID()])
+	})
+	t.Run("proof generation fails", func(t *testing.T) {
+		tab := newTestBuilder(t, 1)
+		sig := maps.Values(tab.signers)[0]
+
+		tab.mnipost.EXPECT().Proof(gomock.Any(), sig.NodeID(), shared.ZeroChallenge, nil).
+			Return(nil, nil, errors.New("missing post data"))
+		err := tab.VerifyInitialPost(context.Background(), sig.NodeID())
+		require.ErrorIs(t, err, ErrInvalidPostData)
+		require.Equal(t, types.PostStateInvalid, tab.postStates.Get()[sig.NodeID()])
+	})
+	t.Run("missing vrf nonce", func(t *testing.T) {
+		tab := newTestBuilder(t, 1)
+		sig := maps.Values(tab.signers)[0]
+
+		info := postInfo(sig.NodeID())
+		info.Nonce = nil
+		tab.mnipost.EXPECT().Proof(gomock.Any(), sig.NodeID(), shared.ZeroChallenge, nil).
+			Return(initialPost, info, nil)
+		err := tab.VerifyInitialPost(context.Background(), sig.NodeID())
+		require.ErrorIs(t, err, ErrInvalidPostData)
+		require.ErrorIs(t, err, errNilVrfNonce)
+	})
+	t.Run("unknown identity", func(t *testing.T) {
+		tab := newTestBuilder(t, 1)
+		require.Error(t, tab.VerifyInitialPost(context.Background(), types.RandomNodeID()))
+	})
+}
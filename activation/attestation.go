@@ -0,0 +1,83 @@
+package activation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/activation/wire"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+)
+
+// Attest returns smeshing attestations for all registered identities, each signed by the key
+// of the identity it describes.
+//
+// An attestation states the ATX that makes the identity eligible in the current epoch, the size
+// of its PoST and the state of its PoST service, so that it can be verified off-chain by
+// pool and delegation operators.
+func (b *Builder) Attest() ([]*wire.SmeshingAttestation, error) {
+	current := b.layerClock.CurrentLayer().GetEpoch()
+	now := uint64(time.Now().Unix())
+	states := b.postStates.Get()
+
+	b.smeshingMutex.Lock()
+	defer b.smeshingMutex.Unlock()
+	attestations := make([]*wire.SmeshingAttestation, 0, len(b.signers))
+	for id, sig := range b.signers {
+		status := wire.SmeshingStatus{
+			Timestamp: now,
+			Epoch:     current,
+			PostState: uint8(states[id]),
+		}
+		if current > 0 {
+			atxID, err := atxs.GetIDByEpochAndNodeID(b.db, current-1, id)
+			switch {
+			case errors.Is(err, sql.ErrNotFound):
+			case err != nil:
+				return nil, fmt.Errorf("get atx for %s in epoch %d: %w", id.ShortString(), current-1, err)
+			default:
+				units, err := atxs.Units(b.db, atxID, id)
+				if err != nil {
+					return nil, fmt.Errorf("get units for %s in atx %s: %w", id.ShortString(), atxID.ShortString(), err)
+				}
+				status.ATX = atxID
+				status.NumUnits = units
+			}
+		}
+		attestations = append(attestations, wire.NewSmeshingAttestation(sig, status))
+	}
+	return attestations, nil
+}
+
+// VerifyAttestation checks that the attestation is signed by the identity it describes and
+// that the attested ATX was published by that identity with the attested PoST size.
+func VerifyAttestation(db sql.Executor, verifier *signing.EdVerifier, attestation *wire.SmeshingAttestation) error {
+	if !attestation.Verify(verifier) {
+		return errors.New("invalid attestation signature")
+	}
+	status := &attestation.Status
+	if status.ATX == types.EmptyATXID {
+		return nil
+	}
+	if status.Epoch == 0 {
+		return errors.New("attested atx in genesis epoch")
+	}
+	atxID, err := atxs.GetIDByEpochAndNodeID(db, status.Epoch-1, attestation.SmesherID)
+	if err != nil {
+		return fmt.Errorf("get atx for %s in epoch %d: %w", attestation.SmesherID.ShortString(), status.Epoch-1, err)
+	}
+	if atxID != status.ATX {
+		return fmt.Errorf("attested atx %s does not match published atx %s", status.ATX.ShortString(), atxID.ShortString())
+	}
+	units, err := atxs.Units(db, atxID, attestation.SmesherID)
+	if err != nil {
+		return fmt.Errorf("get units for %s in atx %s: %w", attestation.SmesherID.ShortString(), atxID.ShortString(), err)
+	}
+	if units != status.NumUnits {
+		return fmt.Errorf("attested units %d do not match units %d in atx", status.NumUnits, units)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package activation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+
+	"github.com/spacemeshos/go-spacemesh/activation/wire"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+)
+
+func TestBuilder_Attest(t *testing.T) {
+	tab := newTestBuilder(t, 2)
+	verifier := signing.NewEdVerifier()
+	sigs := maps.Values(tab.signers)
+
+	// only the first identity published an ATX for the current epoch
+	watx := newInitialATXv1(t, tab.goldenATXID)
+	watx.Sign(sigs[0])
+	atx := toAtx(t, watx)
+	require.NoError(t, atxs.Add(tab.db, atx, watx.Blob()))
+	require.NoError(t, atxs.SetPost(tab.db, atx.ID(), types.EmptyATXID, 0, sigs[0].NodeID(), watx.NumUnits,
+		watx.PublishEpoch))
+	tab.postStates.Set(sigs[0].NodeID(), types.PostStateProving)
+
+	current := watx.PublishEpoch + 1
+	tab.mclock.EXPECT().CurrentLayer().Return(current.FirstLayer())
+	attestations, err := tab.Attest()
+	require.NoError(t, err)
+	require.Len(t, attestations, 2)
+	for _, attestation := range attestations {
+		require.True(t, attestation.Verify(verifier))
+		require.NoError(t, VerifyAttestation(tab.db, verifier, attestation))
+		require.Equal(t, current, attestation.Status.Epoch)
+		switch attestation.SmesherID {
+		case sigs[0].NodeID():
+			require.Equal(t, atx.ID(), attestation.Status.ATX)
+			require.Equal(t, watx.NumUnits, attestation.Status.NumUnits)
+			require.Equal(t, uint8(types.PostStateProving), attestation.Status.PostState)
+		case sigs[1].NodeID():
+			require.Equal(t, types.EmptyATXID, attestation.Status.ATX)
+			require.Zero(t, attestation.Status.NumUnits)
+			require.Equal(t, uint8(types.PostStateIdle), attestation.Status.PostState)
+		default:
+			require.FailNow(t, "unexpected smesher")
+		}
+	}
+
+	t.Run("overstated units", func(t *testing.T) {
+		status := wire.SmeshingStatus{Epoch: current, ATX: atx.ID(), NumUnits: watx.NumUnits + 1}
+		attestation := wire.NewSmeshingAttestation(sigs[0], status)
+		require.ErrorContains(t, VerifyAttestation(tab.db, verifier, attestation), "do not match units")
+	})
+	t.Run("unknown atx", func(t *testing.T) {
+		status := wire.SmeshingStatus{Epoch: current, ATX: types.RandomATXID(), NumUnits: watx.NumUnits}
+		attestation := wire.NewSmeshingAttestation(sigs[0], status)
+		require.ErrorContains(t, VerifyAttestation(tab.db, verifier, attestation), "does not match published atx")
+	})
+	t.Run("no atx", func(t *testing.T) {
+		status := wire.SmeshingStatus{Epoch: current, ATX: atx.ID(), NumUnits: watx.NumUnits}
+		attestation := wire.NewSmeshingAttestation(sigs[1], status)
+		require.ErrorIs(t, VerifyAttestation(tab.db, verifier, attestation), sql.ErrNotFound)
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		attestation := *attestations[0]
+		attestation.Status.Timestamp++
+		require.ErrorContains(t, VerifyAttestation(tab.db, verifier, &attestation), "invalid attestation signature")
+	})
+}
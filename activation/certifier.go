@@ -9,16 +9,21 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jonboulle/clockwork"
 	"github.com/spacemeshos/poet/shared"
 	"go.uber.org/zap"
 	"golang.org/x/sync/singleflight"
 
+	"github.com/spacemeshos/go-spacemesh/activation/metrics"
 	"github.com/spacemeshos/go-spacemesh/activation/wire"
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	certifierdb "github.com/spacemeshos/go-spacemesh/sql/localsql/certifier"
@@ -34,8 +39,23 @@ type CertifierClientConfig struct {
 	MaxRetries int `mapstructure:"max-retries"`
 }
 
+// TrustedCertifier is a certifier whose certificates are accepted by a poet, or by all poets.
+type TrustedCertifier struct {
+	URL    string          `mapstructure:"url"`
+	Pubkey types.Base64Enc `mapstructure:"pubkey"`
+	// Poet is the address of the poet that trusts the certifier.
+	// If empty, the certifier is trusted by all poets.
+	Poet string `mapstructure:"poet"`
+}
+
 type CertifierConfig struct {
 	Client CertifierClientConfig `mapstructure:"client"`
+	// Trusted are certifiers used in addition to the one advertised by a poet.
+	Trusted []TrustedCertifier `mapstructure:"trusted"`
+	// RefreshInterval is how often stored certificates are checked for expiration, zero disables the refresh.
+	RefreshInterval time.Duration `mapstructure:"refresh-interval"`
+	// RefreshBefore is how long before the expiration a stored certificate is refreshed.
+	RefreshBefore time.Duration `mapstructure:"refresh-before"`
 }
 
 func DefaultCertifierClientConfig() CertifierClientConfig {
@@ -48,7 +68,9 @@ func DefaultCertifierClientConfig() CertifierClientConfig {
 
 func DefaultCertifierConfig() CertifierConfig {
 	return CertifierConfig{
-		Client: DefaultCertifierClientConfig(),
+		Client:          DefaultCertifierClientConfig(),
+		RefreshInterval: 10 * time.Minute,
+		RefreshBefore:   time.Hour,
 	}
 }
 
@@ -78,50 +100,230 @@ type CertifyResponse struct {
 }
 
 type Certifier struct {
-	logger *zap.Logger
-	db     sql.LocalDatabase
-	client certifierClient
+	logger  *zap.Logger
+	db      sql.LocalDatabase
+	client  certifierClient
+	clock   clockwork.Clock
+	trusted []TrustedCertifier
+
+	refreshInterval time.Duration
+	refreshBefore   time.Duration
 
 	certifications singleflight.Group
 }
 
+type certifierOpts func(*Certifier)
+
+// WithTrustedCertifiers sets the certifiers that are used in addition to the one advertised by a poet.
+func WithTrustedCertifiers(trusted []TrustedCertifier) certifierOpts {
+	return func(c *Certifier) {
+		c.trusted = trusted
+	}
+}
+
+// WithCertifierWallClock sets the clock that is used to check expiration of stored certificates.
+func WithCertifierWallClock(clock clockwork.Clock) certifierOpts {
+	return func(c *Certifier) {
+		c.clock = clock
+	}
+}
+
+// WithCertificateRefresh enables the refresh of stored certificates by Run. Every interval the certificates
+// that expire within before are recertified.
+func WithCertificateRefresh(interval, before time.Duration) certifierOpts {
+	return func(c *Certifier) {
+		c.refreshInterval = interval
+		c.refreshBefore = before
+	}
+}
+
 func NewCertifier(
 	db sql.LocalDatabase,
 	logger *zap.Logger,
 	client certifierClient,
+	opts ...certifierOpts,
 ) *Certifier {
 	c := &Certifier{
 		client: client,
 		logger: logger,
 		db:     db,
+		clock:  clockwork.NewRealClock(),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 
 	return c
 }
 
+// certifierCandidate is a certifier that can certify a node for a poet.
+type certifierCandidate struct {
+	// poet under which the certificate is stored, certifierdb.AnyPoet if the certificate
+	// is accepted by all poets.
+	poet   string
+	url    *url.URL
+	pubkey []byte
+}
+
+// candidates returns certifiers trusted by the poet. The certifier advertised by the poet goes first,
+// followed by certifiers configured for this poet and certifiers configured for all poets.
+func (c *Certifier) candidates(poet string, advertised *types.CertifierInfo) []certifierCandidate {
+	var candidates []certifierCandidate
+	if advertised != nil {
+		candidates = append(candidates, certifierCandidate{
+			poet:   certifierdb.AnyPoet,
+			url:    advertised.Url,
+			pubkey: advertised.Pubkey,
+		})
+	}
+	for _, trustedBy := range []string{poet, certifierdb.AnyPoet} {
+		for _, trusted := range c.trusted {
+			if trusted.Poet != trustedBy {
+				continue
+			}
+			if slices.ContainsFunc(candidates, func(other certifierCandidate) bool {
+				return bytes.Equal(other.pubkey, trusted.Pubkey.Bytes())
+			}) {
+				continue
+			}
+			addr, err := url.Parse(trusted.URL)
+			if err != nil {
+				c.logger.Warn("invalid trusted certifier address", zap.String("url", trusted.URL), zap.Error(err))
+				continue
+			}
+			candidates = append(candidates, certifierCandidate{
+				poet:   trusted.Poet,
+				url:    addr,
+				pubkey: trusted.Pubkey.Bytes(),
+			})
+		}
+	}
+	return candidates
+}
+
+// storedCertificate returns the certificate stored for the candidate if it didn't expire yet.
+// Expired certificates are deleted.
+func (c *Certifier) storedCertificate(
+	id types.NodeID,
+	poet string,
+	candidate certifierCandidate,
+) (*certifierdb.PoetCert, error) {
+	cert, err := certifierdb.Certificate(c.db, id, candidate.poet, candidate.pubkey)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Expiration != nil && !cert.Expiration.After(c.clock.Now()) {
+		c.logger.Info("stored poet certificate expired",
+			log.ZShortStringer("smesherID", id),
+			zap.String("poet", poet),
+			zap.Stringer("certifier", candidate.url),
+			zap.Time("expiration", *cert.Expiration),
+		)
+		metrics.CertificatesExpired.WithLabelValues(poet).Inc()
+		events.ReportPoetCertificate(events.EventPoetCertificate{
+			NodeID:     id,
+			Poet:       poet,
+			Certifier:  candidate.url.String(),
+			Status:     events.CertificateExpired,
+			Expiration: cert.Expiration,
+		})
+		if err := certifierdb.DeleteCertificate(c.db, id, candidate.poet, candidate.pubkey); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNotFound
+	}
+	return cert, nil
+}
+
+// Certificate returns a certificate for the ID that can be used to submit in the poet.
+// A valid certificate stored for any of the certifiers trusted by the poet is preferred. Otherwise
+// the ID is certified with the trusted certifiers in order, until one of them succeeds.
+//
+// Returns ErrCertificatesNotSupported if the poet doesn't trust any certifier.
 func (c *Certifier) Certificate(
 	ctx context.Context,
 	id types.NodeID,
-	certifier *url.URL,
-	pubkey []byte,
+	poet string,
+	advertised *types.CertifierInfo,
 ) (*certifierdb.PoetCert, error) {
-	// We index certs in DB by node ID and pubkey. To avoid redundant queries, we allow only 1
-	// request per (nodeID, pubkey) pair to be in flight at a time.
-	key := string(append(id.Bytes(), pubkey...))
-	cert, err, _ := c.certifications.Do(key, func() (any, error) {
-		cert, err := certifierdb.Certificate(c.db, id, pubkey)
+	candidates := c.candidates(poet, advertised)
+	if len(candidates) == 0 {
+		return nil, ErrCertificatesNotSupported
+	}
+	for _, candidate := range candidates {
+		cert, err := c.storedCertificate(id, poet, candidate)
 		switch {
 		case err == nil:
+			metrics.CertificateHit.Inc()
+			events.ReportPoetCertificate(events.EventPoetCertificate{
+				NodeID:     id,
+				Poet:       poet,
+				Certifier:  candidate.url.String(),
+				Status:     events.CertificateStored,
+				Expiration: cert.Expiration,
+			})
 			return cert, nil
 		case !errors.Is(err, sql.ErrNotFound):
 			return nil, fmt.Errorf("getting certificate from DB for: %w", err)
 		}
-		cert, err = c.client.Certify(ctx, id, certifier, pubkey)
-		if err != nil {
-			return nil, fmt.Errorf("certifying POST at %v: %w", certifier, err)
+	}
+	metrics.CertificateMiss.Inc()
+	var errs error
+	for _, candidate := range candidates {
+		cert, err := c.certify(ctx, id, poet, candidate, false)
+		if err == nil {
+			return cert, nil
 		}
+		errs = errors.Join(errs, err)
+	}
+	return nil, errs
+}
 
-		if err := certifierdb.AddCertificate(c.db, id, *cert, pubkey); err != nil {
+// certify obtains a certificate from the candidate, unless a valid one is stored already.
+// If refresh is set, the stored certificate is replaced with a new one.
+func (c *Certifier) certify(
+	ctx context.Context,
+	id types.NodeID,
+	poet string,
+	candidate certifierCandidate,
+	refresh bool,
+) (*certifierdb.PoetCert, error) {
+	// We index certs in DB by node ID, poet and pubkey. To avoid redundant queries, we allow only 1
+	// request per (nodeID, poet, pubkey) to be in flight at a time.
+	key := string(append(append(id.Bytes(), candidate.poet...), candidate.pubkey...))
+	cert, err, _ := c.certifications.Do(key, func() (any, error) {
+		if !refresh {
+			cert, err := c.storedCertificate(id, poet, candidate)
+			switch {
+			case err == nil:
+				return cert, nil
+			case !errors.Is(err, sql.ErrNotFound):
+				return nil, fmt.Errorf("getting certificate from DB for: %w", err)
+			}
+		}
+		start := time.Now()
+		cert, err := c.client.Certify(ctx, id, candidate.url, candidate.pubkey)
+		ev := events.EventPoetCertificate{
+			NodeID:    id,
+			Poet:      poet,
+			Certifier: candidate.url.String(),
+			Duration:  time.Since(start),
+		}
+		if err != nil {
+			metrics.CertifyFailed.Observe(ev.Duration.Seconds())
+			ev.Status = events.CertificateFailed
+			ev.Error = err.Error()
+			events.ReportPoetCertificate(ev)
+			return nil, fmt.Errorf("certifying POST at %v: %w", candidate.url, err)
+		}
+		metrics.CertifySucceeded.Observe(ev.Duration.Seconds())
+		ev.Status = events.CertificateCertified
+		ev.Expiration = cert.Expiration
+		events.ReportPoetCertificate(ev)
+
+		cert.Issued = time.Unix(0, c.clock.Now().UnixNano())
+		cert.Certifier = candidate.url.String()
+		if err := certifierdb.AddCertificate(c.db, id, *cert, candidate.poet, candidate.pubkey); err != nil {
 			c.logger.Warn("failed to persist poet cert", zap.Error(err))
 		}
 		return cert, nil
@@ -133,18 +335,123 @@ func (c *Certifier) Certificate(
 	return cert.(*certifierdb.PoetCert), nil
 }
 
-func (c *Certifier) DeleteCertificate(id types.NodeID, pubkey []byte) error {
-	if err := certifierdb.DeleteCertificate(c.db, id, pubkey); err != nil {
-		return err
+// DeleteCertificate deletes certificates of the ID from all certifiers trusted by the poet.
+func (c *Certifier) DeleteCertificate(id types.NodeID, poet string, advertised *types.CertifierInfo) error {
+	for _, candidate := range c.candidates(poet, advertised) {
+		if err := certifierdb.DeleteCertificate(c.db, id, candidate.poet, candidate.pubkey); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// CertificateRefresh is the outcome of the refresh of a stored certificate.
+type CertificateRefresh struct {
+	NodeID types.NodeID `json:"node_id"`
+	// Poet is the address of the poet that accepts the certificate, empty if all poets accept it.
+	Poet      string `json:"poet,omitempty"`
+	Certifier string `json:"certifier"`
+	// Expiration of the new certificate, nil if it doesn't expire or the refresh failed.
+	Expiration *time.Time `json:"expiration,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Run refreshes the stored certificates that are about to expire, until the context is canceled.
+// It returns immediately if the refresh is not enabled with WithCertificateRefresh.
+func (c *Certifier) Run(ctx context.Context) error {
+	if c.refreshInterval == 0 {
+		return nil
+	}
+	ticker := c.clock.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.Chan():
+		}
+		certs, err := certifierdb.ExpiringCertificates(c.db, c.clock.Now().Add(c.refreshBefore))
+		if err != nil {
+			c.logger.Warn("failed to get expiring poet certificates", zap.Error(err))
+			continue
+		}
+		c.refresh(ctx, certs)
+	}
+}
+
+// RecertifyAll replaces all stored certificates with new ones, regardless of their expiration.
+// A certificate is kept if the certifier fails to issue a new one.
+func (c *Certifier) RecertifyAll(ctx context.Context) ([]CertificateRefresh, error) {
+	certs, err := certifierdb.Certificates(c.db)
+	if err != nil {
+		return nil, err
+	}
+	return c.refresh(ctx, certs), nil
+}
+
+func (c *Certifier) refresh(ctx context.Context, certs []certifierdb.StoredCertificate) []CertificateRefresh {
+	rst := make([]CertificateRefresh, 0, len(certs))
+	for _, stored := range certs {
+		refresh := CertificateRefresh{NodeID: stored.NodeID, Poet: stored.Poet, Certifier: stored.Certifier}
+		logger := c.logger.With(
+			log.ZShortStringer("smesherID", stored.NodeID),
+			zap.String("poet", stored.Poet),
+		)
+		candidate, err := c.storedCandidate(stored)
+		if err == nil {
+			refresh.Certifier = candidate.url.String()
+			var cert *certifierdb.PoetCert
+			cert, err = c.certify(ctx, stored.NodeID, stored.Poet, candidate, true)
+			if err == nil {
+				refresh.Expiration = cert.Expiration
+			}
+		}
+		if err != nil {
+			metrics.CertificateRefreshFailed.Inc()
+			refresh.Error = err.Error()
+			logger.Warn("failed to refresh poet certificate",
+				zap.String("certifier", refresh.Certifier),
+				zap.Timep("expiration", stored.Expiration),
+				zap.Error(err),
+			)
+		} else {
+			metrics.CertificateRefreshed.Inc()
+			logger.Info("refreshed poet certificate",
+				zap.String("certifier", refresh.Certifier),
+				zap.Timep("expiration", refresh.Expiration),
+			)
+		}
+		rst = append(rst, refresh)
+	}
+	return rst
+}
+
+// storedCandidate returns the certifier that issued the stored certificate. Certificates stored without
+// the address of the certifier are matched with the trusted certifiers by the public key.
+func (c *Certifier) storedCandidate(stored certifierdb.StoredCertificate) (certifierCandidate, error) {
+	addr := stored.Certifier
+	if addr == "" {
+		idx := slices.IndexFunc(c.trusted, func(trusted TrustedCertifier) bool {
+			return bytes.Equal(trusted.Pubkey.Bytes(), stored.CertifierID)
+		})
+		if idx == -1 {
+			return certifierCandidate{}, fmt.Errorf("unknown address of certifier %x", stored.CertifierID)
+		}
+		addr = c.trusted[idx].URL
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return certifierCandidate{}, fmt.Errorf("invalid certifier address %q: %w", addr, err)
+	}
+	return certifierCandidate{poet: stored.Poet, url: u, pubkey: stored.CertifierID}, nil
+}
+
 type CertifierClient struct {
 	client  *retryablehttp.Client
 	logger  *zap.Logger
 	db      sql.Executor
 	localDb sql.LocalDatabase
+	clock   clockwork.Clock
 }
 
 type certifierClientOpts func(*CertifierClient)
@@ -157,6 +464,13 @@ func WithCertifierClientConfig(cfg CertifierClientConfig) certifierClientOpts {
 	}
 }
 
+// WithCertifierClientWallClock sets the clock that is used to check expiration of certificates.
+func WithCertifierClientWallClock(clock clockwork.Clock) certifierClientOpts {
+	return func(c *CertifierClient) {
+		c.clock = clock
+	}
+}
+
 func NewCertifierClient(
 	db sql.Executor,
 	localDb sql.LocalDatabase,
@@ -168,6 +482,7 @@ func NewCertifierClient(
 		logger:  logger,
 		db:      db,
 		localDb: localDb,
+		clock:   clockwork.NewRealClock(),
 	}
 	config := DefaultCertifierClientConfig()
 	c.client.RetryMax = config.MaxRetries
@@ -313,14 +628,15 @@ func (c *CertifierClient) Certify(
 
 	if cert.Expiration != nil {
 		c.logger.Info("certificate has expiration date", zap.Time("expiration", *cert.Expiration))
-		if time.Until(*cert.Expiration) < 0 {
+		if cert.Expiration.Before(c.clock.Now()) {
 			return nil, errors.New("certificate is expired")
 		}
 	}
 
 	return &certifierdb.PoetCert{
-		Data:      opaqueCert.Data,
-		Signature: opaqueCert.Signature,
+		Data:       opaqueCert.Data,
+		Signature:  opaqueCert.Signature,
+		Expiration: cert.Expiration,
 	}, nil
 }
 
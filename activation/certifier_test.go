@@ -2,15 +2,19 @@ package activation
 
 import (
 	"context"
+	"errors"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap/zaptest"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/localsql"
@@ -26,30 +30,31 @@ func TestPersistsCerts(t *testing.T) {
 	cert := &certdb.PoetCert{Data: []byte("cert"), Signature: []byte("sig")}
 	certifierAddress := &url.URL{Scheme: "http", Host: "certifier.org"}
 	pubkey := []byte("pubkey")
+	info := &types.CertifierInfo{Url: certifierAddress, Pubkey: pubkey}
 	{
 		c := NewCertifier(db, zaptest.NewLogger(t), client)
 		client.EXPECT().
 			Certify(gomock.Any(), id, certifierAddress, pubkey).
 			Return(cert, nil)
 
-		_, err := certdb.Certificate(db, id, pubkey)
+		_, err := certdb.Certificate(db, id, certdb.AnyPoet, pubkey)
 		require.ErrorIs(t, err, sql.ErrNotFound)
-		got, err := c.Certificate(context.Background(), id, certifierAddress, pubkey)
+		got, err := c.Certificate(context.Background(), id, "poet", info)
 		require.NoError(t, err)
 		require.Equal(t, cert, got)
 
-		got, err = c.Certificate(context.Background(), id, certifierAddress, pubkey)
+		got, err = c.Certificate(context.Background(), id, "poet", info)
 		require.NoError(t, err)
 		require.Equal(t, cert, got)
 
-		got, err = certdb.Certificate(db, id, pubkey)
+		got, err = certdb.Certificate(db, id, certdb.AnyPoet, pubkey)
 		require.NoError(t, err)
 		require.Equal(t, cert, got)
 	}
 	{
 		// Create new certifier and check that it loads the certs back.
 		c := NewCertifier(db, zaptest.NewLogger(t), client)
-		got, err := c.Certificate(context.Background(), id, certifierAddress, pubkey)
+		got, err := c.Certificate(context.Background(), id, "poet", info)
 		require.NoError(t, err)
 		require.Equal(t, cert, got)
 	}
@@ -66,10 +71,12 @@ func TestAvoidsRedundantQueries(t *testing.T) {
 	certifierAddress := &url.URL{Scheme: "http", Host: "certifier.org"}
 	pubkey := []byte("pubkey")
 	pubkey2 := []byte("pubkey2")
+	info := &types.CertifierInfo{Url: certifierAddress, Pubkey: pubkey}
+	info2 := &types.CertifierInfo{Url: certifierAddress, Pubkey: pubkey2}
 
 	c := NewCertifier(db, zaptest.NewLogger(t), client)
-	// The key is (id, pubkey) so we should only have one request in flight at a time
-	// for a given pair.
+	// The key is (id, poet, pubkey) so we should only have one request in flight at a time
+	// for a given tuple.
 	client.EXPECT().Certify(gomock.Any(), id1, certifierAddress, pubkey).Return(cert1, nil)
 	client.EXPECT().Certify(gomock.Any(), id2, certifierAddress, pubkey).Return(cert2, nil)
 	client.EXPECT().Certify(gomock.Any(), id1, certifierAddress, pubkey2).Return(cert3, nil)
@@ -77,19 +84,19 @@ func TestAvoidsRedundantQueries(t *testing.T) {
 	var eg errgroup.Group
 	for i := 0; i < 10; i++ {
 		eg.Go(func() error {
-			got, err := c.Certificate(context.Background(), id1, certifierAddress, pubkey)
+			got, err := c.Certificate(context.Background(), id1, "poet", info)
 			require.NoError(t, err)
 			require.Equal(t, cert1, got)
 			return nil
 		})
 		eg.Go(func() error {
-			got, err := c.Certificate(context.Background(), id2, certifierAddress, pubkey)
+			got, err := c.Certificate(context.Background(), id2, "poet", info)
 			require.NoError(t, err)
 			require.Equal(t, cert2, got)
 			return nil
 		})
 		eg.Go(func() error {
-			got, err := c.Certificate(context.Background(), id1, certifierAddress, pubkey2)
+			got, err := c.Certificate(context.Background(), id1, "poet", info2)
 			require.NoError(t, err)
 			require.Equal(t, cert3, got)
 			return nil
@@ -97,19 +104,106 @@ func TestAvoidsRedundantQueries(t *testing.T) {
 	}
 	eg.Wait()
 
-	got, err := certdb.Certificate(db, id1, pubkey)
+	got, err := certdb.Certificate(db, id1, certdb.AnyPoet, pubkey)
 	require.NoError(t, err)
 	require.Equal(t, cert1, got)
 	// different id - different cert
-	got, err = certdb.Certificate(db, id2, pubkey)
+	got, err = certdb.Certificate(db, id2, certdb.AnyPoet, pubkey)
 	require.NoError(t, err)
 	require.Equal(t, cert2, got)
 	// different pubkey - different cert
-	got, err = certdb.Certificate(db, id1, pubkey2)
+	got, err = certdb.Certificate(db, id1, certdb.AnyPoet, pubkey2)
 	require.NoError(t, err)
 	require.Equal(t, cert3, got)
 }
 
+func TestSelectsTrustedCertifier(t *testing.T) {
+	advertisedAddress := &url.URL{Scheme: "http", Host: "advertised.org"}
+	advertised := &types.CertifierInfo{Url: advertisedAddress, Pubkey: []byte("advertised")}
+	perPoetAddress := &url.URL{Scheme: "http", Host: "per-poet.org"}
+	globalAddress := &url.URL{Scheme: "http", Host: "global.org"}
+	trusted := []TrustedCertifier{
+		{URL: globalAddress.String(), Pubkey: types.NewBase64Enc([]byte("global"))},
+		{URL: perPoetAddress.String(), Pubkey: types.NewBase64Enc([]byte("per-poet")), Poet: "poet"},
+		{URL: "http://other.org", Pubkey: types.NewBase64Enc([]byte("other")), Poet: "other-poet"},
+	}
+	id := types.RandomNodeID()
+	cert := &certdb.PoetCert{Data: []byte("cert"), Signature: []byte("sig")}
+
+	t.Run("no trusted certifiers", func(t *testing.T) {
+		c := NewCertifier(localsql.InMemory(), zaptest.NewLogger(t), NewMockcertifierClient(gomock.NewController(t)))
+		_, err := c.Certificate(context.Background(), id, "poet", nil)
+		require.ErrorIs(t, err, ErrCertificatesNotSupported)
+	})
+	t.Run("certifies in order", func(t *testing.T) {
+		db := localsql.InMemory()
+		client := NewMockcertifierClient(gomock.NewController(t))
+		c := NewCertifier(db, zaptest.NewLogger(t), client, WithTrustedCertifiers(trusted))
+		gomock.InOrder(
+			client.EXPECT().
+				Certify(gomock.Any(), id, advertisedAddress, advertised.Pubkey).
+				Return(nil, errors.New("unavailable")),
+			client.EXPECT().
+				Certify(gomock.Any(), id, perPoetAddress, []byte("per-poet")).
+				Return(cert, nil),
+		)
+		got, err := c.Certificate(context.Background(), id, "poet", advertised)
+		require.NoError(t, err)
+		require.Equal(t, cert, got)
+
+		got, err = certdb.Certificate(db, id, "poet", []byte("per-poet"))
+		require.NoError(t, err)
+		require.Equal(t, cert, got)
+
+		// stored certificate is used
+		got, err = c.Certificate(context.Background(), id, "poet", advertised)
+		require.NoError(t, err)
+		require.Equal(t, cert, got)
+
+		// but not for other poets
+		client.EXPECT().
+			Certify(gomock.Any(), id, globalAddress, []byte("global")).
+			Return(cert, nil)
+		got, err = c.Certificate(context.Background(), id, "poet-2", nil)
+		require.NoError(t, err)
+		require.Equal(t, cert, got)
+
+		got, err = certdb.Certificate(db, id, certdb.AnyPoet, []byte("global"))
+		require.NoError(t, err)
+		require.Equal(t, cert, got)
+
+		require.NoError(t, c.DeleteCertificate(id, "poet", advertised))
+		_, err = certdb.Certificate(db, id, "poet", []byte("per-poet"))
+		require.ErrorIs(t, err, sql.ErrNotFound)
+		_, err = certdb.Certificate(db, id, certdb.AnyPoet, []byte("global"))
+		require.ErrorIs(t, err, sql.ErrNotFound)
+	})
+	t.Run("skips expired certificates", func(t *testing.T) {
+		db := localsql.InMemory()
+		clock := clockwork.NewFakeClock()
+		expired := time.Unix(0, clock.Now().Add(-time.Second).UnixNano())
+		expiredCert := &certdb.PoetCert{Data: []byte("expired"), Signature: []byte("sig"), Expiration: &expired}
+		require.NoError(t, certdb.AddCertificate(db, id, *expiredCert, certdb.AnyPoet, advertised.Pubkey))
+		valid := time.Unix(0, clock.Now().Add(time.Hour).UnixNano())
+		validCert := &certdb.PoetCert{Data: []byte("valid"), Signature: []byte("sig"), Expiration: &valid}
+		require.NoError(t, certdb.AddCertificate(db, id, *validCert, certdb.AnyPoet, []byte("global")))
+
+		c := NewCertifier(
+			db,
+			zaptest.NewLogger(t),
+			NewMockcertifierClient(gomock.NewController(t)),
+			WithTrustedCertifiers(trusted),
+			WithCertifierWallClock(clock),
+		)
+		got, err := c.Certificate(context.Background(), id, "poet", advertised)
+		require.NoError(t, err)
+		require.Equal(t, validCert, got)
+
+		_, err = certdb.Certificate(db, id, certdb.AnyPoet, advertised.Pubkey)
+		require.ErrorIs(t, err, sql.ErrNotFound)
+	})
+}
+
 func TestObtainingPost(t *testing.T) {
 	id := types.RandomNodeID()
 
@@ -161,3 +255,159 @@ func TestObtainingPost(t *testing.T) {
 		require.Equal(t, atx.CommitmentATXID, &got.CommitmentATX)
 	})
 }
+
+func TestCertifierReportsEvents(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub := events.SubscribePoetCertificates()
+	require.NotNil(t, sub)
+	t.Cleanup(func() { require.NoError(t, sub.Close()) })
+	next := func(t *testing.T) events.EventPoetCertificate {
+		select {
+		case ev := <-sub.Out():
+			return ev.(events.EventPoetCertificate)
+		case <-time.After(time.Second):
+			require.FailNow(t, "no event")
+		}
+		return events.EventPoetCertificate{}
+	}
+
+	client := NewMockcertifierClient(gomock.NewController(t))
+	id := types.RandomNodeID()
+	db := localsql.InMemory()
+	clock := clockwork.NewFakeClock()
+	certifierAddress := &url.URL{Scheme: "http", Host: "certifier.org"}
+	info := &types.CertifierInfo{Url: certifierAddress, Pubkey: []byte("pubkey")}
+	expired := time.Unix(0, clock.Now().Add(-time.Second).UnixNano())
+	expiredCert := &certdb.PoetCert{Data: []byte("expired"), Signature: []byte("sig"), Expiration: &expired}
+	require.NoError(t, certdb.AddCertificate(db, id, *expiredCert, certdb.AnyPoet, info.Pubkey))
+	c := NewCertifier(db, zaptest.NewLogger(t), client, WithCertifierWallClock(clock))
+
+	client.EXPECT().Certify(gomock.Any(), id, certifierAddress, info.Pubkey).Return(nil, errors.New("unavailable"))
+	_, err := c.Certificate(context.Background(), id, "poet", info)
+	require.Error(t, err)
+	ev := next(t)
+	require.Equal(t, events.CertificateExpired, ev.Status)
+	require.Equal(t, "poet", ev.Poet)
+	require.Equal(t, certifierAddress.String(), ev.Certifier)
+	require.Equal(t, expired, *ev.Expiration)
+	ev = next(t)
+	require.Equal(t, events.CertificateFailed, ev.Status)
+	require.Equal(t, "unavailable", ev.Error)
+
+	valid := time.Unix(0, clock.Now().Add(time.Hour).UnixNano())
+	cert := &certdb.PoetCert{Data: []byte("cert"), Signature: []byte("sig"), Expiration: &valid}
+	client.EXPECT().Certify(gomock.Any(), id, certifierAddress, info.Pubkey).Return(cert, nil)
+	_, err = c.Certificate(context.Background(), id, "poet", info)
+	require.NoError(t, err)
+	ev = next(t)
+	require.Equal(t, events.CertificateCertified, ev.Status)
+	require.Equal(t, id, ev.NodeID)
+	require.Equal(t, valid, *ev.Expiration)
+
+	_, err = c.Certificate(context.Background(), id, "poet", info)
+	require.NoError(t, err)
+	ev = next(t)
+	require.Equal(t, events.CertificateStored, ev.Status)
+	require.Zero(t, ev.Duration)
+}
+
+func TestCertifier_Refresh(t *testing.T) {
+	client := NewMockcertifierClient(gomock.NewController(t))
+	db := localsql.InMemory()
+	clock := clockwork.NewFakeClock()
+	certifierAddress := &url.URL{Scheme: "http", Host: "certifier.org"}
+	info := &types.CertifierInfo{Url: certifierAddress, Pubkey: []byte("pubkey")}
+	c := NewCertifier(
+		db,
+		zaptest.NewLogger(t),
+		client,
+		WithCertifierWallClock(clock),
+		WithCertificateRefresh(10*time.Minute, time.Hour),
+	)
+
+	expiring := time.Unix(0, clock.Now().Add(90*time.Minute).UnixNano())
+	id := types.RandomNodeID()
+	client.EXPECT().Certify(gomock.Any(), id, certifierAddress, info.Pubkey).
+		Return(&certdb.PoetCert{Data: []byte("old"), Signature: []byte("sig"), Expiration: &expiring}, nil)
+	_, err := c.Certificate(context.Background(), id, "poet", info)
+	require.NoError(t, err)
+	stored, err := certdb.Certificate(db, id, certdb.AnyPoet, info.Pubkey)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(0, clock.Now().UnixNano()), stored.Issued)
+	require.Equal(t, certifierAddress.String(), stored.Certifier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error { return c.Run(ctx) })
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, eg.Wait())
+	})
+
+	// the certificate doesn't expire within an hour yet
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Minute)
+	clock.BlockUntil(1)
+
+	refreshed := time.Unix(0, clock.Now().Add(48*time.Hour).UnixNano())
+	done := make(chan struct{})
+	client.EXPECT().Certify(gomock.Any(), id, certifierAddress, info.Pubkey).
+		DoAndReturn(func(context.Context, types.NodeID, *url.URL, []byte) (*certdb.PoetCert, error) {
+			close(done)
+			return &certdb.PoetCert{Data: []byte("new"), Signature: []byte("sig"), Expiration: &refreshed}, nil
+		})
+	clock.Advance(30 * time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "certificate not refreshed")
+	}
+	require.Eventually(t, func() bool {
+		stored, err := certdb.Certificate(db, id, certdb.AnyPoet, info.Pubkey)
+		return err == nil && string(stored.Data) == "new"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCertifier_RecertifyAll(t *testing.T) {
+	client := NewMockcertifierClient(gomock.NewController(t))
+	db := localsql.InMemory()
+	trusted := TrustedCertifier{URL: "http://trusted.org", Pubkey: types.NewBase64Enc([]byte("trusted"))}
+	c := NewCertifier(db, zaptest.NewLogger(t), client, WithTrustedCertifiers([]TrustedCertifier{trusted}))
+
+	// certificates stored before the address of the certifier was kept
+	legacy := certdb.PoetCert{Data: []byte("legacy"), Signature: []byte("sig")}
+	id1 := types.RandomNodeID()
+	id2 := types.RandomNodeID()
+	require.NoError(t, certdb.AddCertificate(db, id1, legacy, "poet", []byte("trusted")))
+	require.NoError(t, certdb.AddCertificate(db, id2, legacy, certdb.AnyPoet, []byte("unknown")))
+	failing := legacy
+	failing.Certifier = "http://failing.org"
+	require.NoError(t, certdb.AddCertificate(db, id2, failing, certdb.AnyPoet, []byte("failing")))
+
+	trustedAddress := &url.URL{Scheme: "http", Host: "trusted.org"}
+	cert := &certdb.PoetCert{Data: []byte("new"), Signature: []byte("sig")}
+	client.EXPECT().Certify(gomock.Any(), id1, trustedAddress, []byte("trusted")).Return(cert, nil)
+	client.EXPECT().Certify(gomock.Any(), id2, &url.URL{Scheme: "http", Host: "failing.org"}, []byte("failing")).
+		Return(nil, errors.New("unavailable"))
+
+	refreshed, err := c.RecertifyAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, refreshed, 3)
+	byCertifier := make(map[string]CertificateRefresh)
+	for _, refresh := range refreshed {
+		byCertifier[refresh.Certifier] = refresh
+	}
+	require.Equal(t, CertificateRefresh{NodeID: id1, Poet: "poet", Certifier: "http://trusted.org"},
+		byCertifier["http://trusted.org"])
+	require.Contains(t, byCertifier["http://failing.org"].Error, "unavailable")
+	require.Contains(t, byCertifier[""].Error, "unknown address of certifier")
+
+	got, err := certdb.Certificate(db, id1, "poet", []byte("trusted"))
+	require.NoError(t, err)
+	require.Equal(t, cert, got)
+	// certificates that failed to refresh are kept
+	got, err = certdb.Certificate(db, id2, certdb.AnyPoet, []byte("failing"))
+	require.NoError(t, err)
+	require.Equal(t, &failing, got)
+}
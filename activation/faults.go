@@ -0,0 +1,186 @@
+//go:build faultinjection
+
+package activation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// FaultInjectionEnabled is true if the node is built with the `faultinjection` tag.
+const FaultInjectionEnabled = true
+
+// ErrFaultInjected is returned by poet and post clients when a failure is injected by the FaultInjector.
+var ErrFaultInjected = errors.New("fault injected")
+
+// Faults describes failures injected into interactions with poet and post services.
+type Faults struct {
+	// DropPoetSubmissions makes every submission to a poet fail.
+	DropPoetSubmissions bool `json:"drop_poet_submissions"`
+	// PoetProofDelay delays every query for a poet proof.
+	PoetProofDelay time.Duration `json:"poet_proof_delay"`
+	// CorruptPoetMembership replaces members of every poet proof with random ones,
+	// so that the registered challenge is not found in the membership.
+	CorruptPoetMembership bool `json:"corrupt_poet_membership"`
+	// PostProofDelay delays every request for a PoST proof.
+	PostProofDelay time.Duration `json:"post_proof_delay"`
+	// FailPostProof makes every request for a PoST proof fail.
+	FailPostProof bool `json:"fail_post_proof"`
+}
+
+// FaultInjector injects failures into poet and post clients used by the NIPostBuilder,
+// so that its error paths can be exercised deterministically in system and chaos tests.
+//
+// It is only created by the node if built with the `faultinjection` tag (see FaultInjectionEnabled)
+// and started with fault injection enabled in the config. Faults are controlled over the private
+// JSON API of the admin service: GET returns active faults, PUT replaces them.
+type FaultInjector struct {
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	faults Faults
+}
+
+// NewFaultInjector creates a FaultInjector without any active faults.
+func NewFaultInjector(logger *zap.Logger) *FaultInjector {
+	return &FaultInjector{logger: logger}
+}
+
+// Set replaces active faults.
+func (f *FaultInjector) Set(faults Faults) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = faults
+	f.logger.Warn("fault injection updated", zap.Any("faults", faults))
+}
+
+// Get returns active faults.
+func (f *FaultInjector) Get() Faults {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.faults
+}
+
+func (f *FaultInjector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var faults Faults
+		if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.Set(faults)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(f.Get()); err != nil {
+		f.logger.Debug("failed to write faults", zap.Error(err))
+	}
+}
+
+// WithFaultInjector injects failures configured in the FaultInjector into the poet client.
+// It is a no-op if the injector is nil.
+func WithFaultInjector(f *FaultInjector) PoetServiceOpt {
+	return func(c *poetService) {
+		if f != nil {
+			c.client = &faultyPoetClient{PoetClient: c.client, faults: f}
+		}
+	}
+}
+
+// NipostbuilderWithFaultInjector injects failures configured in the FaultInjector into the post clients.
+// It is a no-op if the injector is nil.
+func NipostbuilderWithFaultInjector(f *FaultInjector) NIPostBuilderOption {
+	return func(nb *NIPostBuilder) {
+		if f != nil {
+			nb.postService = &faultyPostService{postService: nb.postService, faults: f}
+		}
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+type faultyPoetClient struct {
+	PoetClient
+	faults *FaultInjector
+}
+
+func (c *faultyPoetClient) Submit(
+	ctx context.Context,
+	deadline time.Time,
+	prefix, challenge []byte,
+	signature types.EdSignature,
+	nodeID types.NodeID,
+	auth PoetAuth,
+) (*types.PoetRound, error) {
+	if c.faults.Get().DropPoetSubmissions {
+		return nil, ErrFaultInjected
+	}
+	return c.PoetClient.Submit(ctx, deadline, prefix, challenge, signature, nodeID, auth)
+}
+
+func (c *faultyPoetClient) Proof(ctx context.Context, roundID string) (*types.PoetProofMessage, []types.Hash32, error) {
+	faults := c.faults.Get()
+	if err := sleepCtx(ctx, faults.PoetProofDelay); err != nil {
+		return nil, nil, err
+	}
+	proof, members, err := c.PoetClient.Proof(ctx, roundID)
+	if err != nil || !faults.CorruptPoetMembership {
+		return proof, members, err
+	}
+	corrupted := make([]types.Hash32, len(members))
+	for i := range corrupted {
+		corrupted[i] = types.RandomHash()
+	}
+	return proof, corrupted, nil
+}
+
+type faultyPostService struct {
+	postService
+	faults *FaultInjector
+}
+
+func (s *faultyPostService) Client(nodeID types.NodeID) (PostClient, error) {
+	client, err := s.postService.Client(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyPostClient{PostClient: client, faults: s.faults}, nil
+}
+
+type faultyPostClient struct {
+	PostClient
+	faults *FaultInjector
+}
+
+func (c *faultyPostClient) Proof(ctx context.Context, challenge []byte) (*types.Post, *types.PostInfo, error) {
+	faults := c.faults.Get()
+	if err := sleepCtx(ctx, faults.PostProofDelay); err != nil {
+		return nil, nil, err
+	}
+	if faults.FailPostProof {
+		return nil, nil, ErrFaultInjected
+	}
+	return c.PostClient.Proof(ctx, challenge)
+}
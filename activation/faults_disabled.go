@@ -0,0 +1,35 @@
+//go:build !faultinjection
+
+package activation
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// FaultInjectionEnabled is true if the node is built with the `faultinjection` tag.
+const FaultInjectionEnabled = false
+
+// FaultInjector can't inject any failures in a node built without the `faultinjection` tag,
+// see faults.go for the real implementation.
+type FaultInjector struct{}
+
+// NewFaultInjector returns nil, failures are never injected without the `faultinjection` tag.
+func NewFaultInjector(*zap.Logger) *FaultInjector {
+	return nil
+}
+
+func (*FaultInjector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	http.NotFound(w, r)
+}
+
+// WithFaultInjector is a no-op without the `faultinjection` tag.
+func WithFaultInjector(*FaultInjector) PoetServiceOpt {
+	return func(*poetService) {}
+}
+
+// NipostbuilderWithFaultInjector is a no-op without the `faultinjection` tag.
+func NipostbuilderWithFaultInjector(*FaultInjector) NIPostBuilderOption {
+	return func(*NIPostBuilder) {}
+}
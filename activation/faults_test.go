@@ -0,0 +1,109 @@
+//go:build faultinjection
+
+package activation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestFaultyPoetClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := NewMockPoetClient(ctrl)
+	faults := NewFaultInjector(zaptest.NewLogger(t))
+	faulty := &faultyPoetClient{PoetClient: client, faults: faults}
+
+	members := []types.Hash32{types.RandomHash(), types.RandomHash()}
+	client.EXPECT().
+		Submit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&types.PoetRound{ID: "1"}, nil)
+	client.EXPECT().Proof(gomock.Any(), "1").Return(&types.PoetProofMessage{}, members, nil).Times(2)
+
+	submit := func() (*types.PoetRound, error) {
+		ctx := context.Background()
+		return faulty.Submit(ctx, time.Time{}, nil, nil, types.EmptyEdSignature, types.EmptyNodeID, PoetAuth{})
+	}
+	round, err := submit()
+	require.NoError(t, err)
+	require.Equal(t, "1", round.ID)
+	_, got, err := faulty.Proof(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, members, got)
+
+	faults.Set(Faults{DropPoetSubmissions: true, CorruptPoetMembership: true})
+	_, err = submit()
+	require.ErrorIs(t, err, ErrFaultInjected)
+	_, got, err = faulty.Proof(context.Background(), "1")
+	require.NoError(t, err)
+	require.Len(t, got, len(members))
+	require.NotContains(t, got, members[0])
+	require.NotContains(t, got, members[1])
+
+	faults.Set(Faults{PoetProofDelay: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = faulty.Proof(ctx, "1")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFaultyPostClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := NewMockPostClient(ctrl)
+	service := NewMockpostService(ctrl)
+	service.EXPECT().Client(gomock.Any()).Return(client, nil).AnyTimes()
+	faults := NewFaultInjector(zaptest.NewLogger(t))
+	faulty := &faultyPostService{postService: service, faults: faults}
+
+	post := &types.Post{Nonce: 1}
+	client.EXPECT().Proof(gomock.Any(), gomock.Any()).Return(post, &types.PostInfo{}, nil)
+
+	c, err := faulty.Client(types.RandomNodeID())
+	require.NoError(t, err)
+	got, _, err := c.Proof(context.Background(), []byte("challenge"))
+	require.NoError(t, err)
+	require.Equal(t, post, got)
+
+	faults.Set(Faults{FailPostProof: true})
+	_, _, err = c.Proof(context.Background(), []byte("challenge"))
+	require.ErrorIs(t, err, ErrFaultInjected)
+}
+
+func TestFaultInjector_HTTP(t *testing.T) {
+	faults := NewFaultInjector(zaptest.NewLogger(t))
+	srv := httptest.NewServer(faults)
+	t.Cleanup(srv.Close)
+
+	expected := Faults{DropPoetSubmissions: true, PostProofDelay: time.Second}
+	body, err := json.Marshal(expected)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, srv.URL, bytes.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, expected, faults.Get())
+
+	resp, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var got Faults
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, expected, got)
+
+	resp, err = http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package activation
+
+import "golang.org/x/sys/unix"
+
+// freeSpace returns the space in bytes available to the node on the disk of the directory.
+func freeSpace(dir string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,16 @@
+package activation
+
+import "golang.org/x/sys/windows"
+
+// freeSpace returns the space in bytes available to the node on the disk of the directory.
+func freeSpace(dir string) (uint64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, nil, nil); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
 	"github.com/spacemeshos/go-spacemesh/system"
 )
 
@@ -487,6 +488,11 @@ func (h *HandlerV1) storeAtx(
 		if err != nil && !errors.Is(err, sql.ErrObjectExists) {
 			return fmt.Errorf("set atx units: %w", err)
 		}
+		var ref types.PoetProofRef
+		copy(ref[:], watx.NIPost.PostMetadata.Challenge)
+		if err := poets.AddReference(tx, ref, atx.ID()); err != nil {
+			return fmt.Errorf("add poet reference: %w", err)
+		}
 
 		return nil
 	}); err != nil {
@@ -580,7 +586,7 @@ func (h *HandlerV1) processATX(
 		return nil, fmt.Errorf("cannot store atx %s: %w", atx.ShortString(), err)
 	}
 
-	if err := events.ReportNewActivation(atx); err != nil {
+	if err := events.ReportNewActivation(atx, peer); err != nil {
 		h.logger.Error("failed to emit activation",
 			log.ZShortStringer("atx_id", atx.ID()),
 			zap.Uint32("epoch", atx.PublishEpoch.Uint32()),
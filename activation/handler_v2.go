@@ -29,6 +29,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
 	"github.com/spacemeshos/go-spacemesh/system"
 )
 
@@ -145,7 +146,7 @@ func (h *HandlerV2) processATX(
 		return fmt.Errorf("cannot store atx %s: %w", atx.ShortString(), err)
 	}
 
-	if err := events.ReportNewActivation(atx); err != nil {
+	if err := events.ReportNewActivation(atx, peer); err != nil {
 		h.logger.Error("failed to emit activation",
 			log.ZShortStringer("atx_id", atx.ID()),
 			zap.Uint32("epoch", atx.PublishEpoch.Uint32()),
@@ -876,6 +877,11 @@ func (h *HandlerV2) storeAtx(ctx context.Context, atx *types.ActivationTx, watx
 				return fmt.Errorf("setting atx units for ID %s: %w", id, err)
 			}
 		}
+		for _, niposts := range watx.NiPosts {
+			if err := poets.AddReference(tx, types.PoetProofRef(niposts.Challenge), atx.ID()); err != nil {
+				return fmt.Errorf("add poet reference: %w", err)
+			}
+		}
 		return nil
 	}); err != nil {
 		return fmt.Errorf("store atx: %w", err)
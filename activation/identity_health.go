@@ -0,0 +1,212 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// degradedEpochs is the number of consecutive epochs in which publishing an ATX failed
+// after which the identity is reported as degraded.
+const degradedEpochs = 2
+
+// identityHealth tracks consecutive epochs in which an identity failed to publish an ATX.
+type identityHealth struct {
+	lastFailed types.EpochID
+	// failedEpochs is the number of consecutive epochs, ending with lastFailed, in which
+	// at least one attempt failed and none succeeded.
+	failedEpochs int
+	// resume is not nil while attempts of the identity are paused, closing it resumes them.
+	resume chan struct{}
+	// nipostErr is the error of the latest failed attempt to build a NIPoST, nil if the latest
+	// attempt failed for another reason or the identity succeeded since.
+	nipostErr *NIPostError
+}
+
+type identitiesHealth struct {
+	mu     sync.Mutex
+	health map[types.NodeID]*identityHealth
+}
+
+func (h *identitiesHealth) get(id types.NodeID) *identityHealth {
+	if h.health == nil {
+		h.health = make(map[types.NodeID]*identityHealth)
+	}
+	ih, ok := h.health[id]
+	if !ok {
+		ih = &identityHealth{}
+		h.health[id] = ih
+	}
+	return ih
+}
+
+// failed records a failed attempt in the epoch. It returns the number of consecutive failed
+// epochs and true if it is the first failure of the identity in the epoch.
+func (h *identitiesHealth) failed(id types.NodeID, epoch types.EpochID) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ih := h.get(id)
+	switch {
+	case ih.failedEpochs > 0 && ih.lastFailed == epoch:
+		return ih.failedEpochs, false
+	case ih.failedEpochs > 0 && ih.lastFailed+1 == epoch:
+		ih.failedEpochs++
+	default:
+		ih.failedEpochs = 1
+	}
+	ih.lastFailed = epoch
+	return ih.failedEpochs, true
+}
+
+// succeeded resets failures of the identity. It returns the number of failed epochs before.
+func (h *identitiesHealth) succeeded(id types.NodeID) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ih := h.get(id)
+	failed := ih.failedEpochs
+	ih.failedEpochs = 0
+	ih.nipostErr = nil
+	return failed
+}
+
+func (h *identitiesHealth) setNIPostError(id types.NodeID, err *NIPostError) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.get(id).nipostErr = err
+}
+
+func (h *identitiesHealth) nipostError(id types.NodeID) *NIPostError {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ih, ok := h.health[id]; ok {
+		return ih.nipostErr
+	}
+	return nil
+}
+
+// pause returns a channel that is closed when the identity is resumed.
+func (h *identitiesHealth) pause(id types.NodeID) <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ih := h.get(id)
+	if ih.resume == nil {
+		ih.resume = make(chan struct{})
+	}
+	return ih.resume
+}
+
+// unpause clears the paused state without resetting failures, e.g. when smeshing is stopped.
+func (h *identitiesHealth) unpause(id types.NodeID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.get(id).resume = nil
+}
+
+func (h *identitiesHealth) resume(id types.NodeID) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ih := h.get(id)
+	if ih.resume == nil {
+		return ErrNotPaused
+	}
+	close(ih.resume)
+	ih.resume = nil
+	ih.failedEpochs = 0
+	return nil
+}
+
+// state overrides the post state of the identity if it is paused or degraded.
+// A degraded identity reports the state of its current attempt unless it is idle.
+func (h *identitiesHealth) state(id types.NodeID, state types.PostState) types.PostState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ih, ok := h.health[id]
+	switch {
+	case !ok:
+		return state
+	case ih.resume != nil:
+		return types.PostStatePaused
+	case ih.failedEpochs >= degradedEpochs && state == types.PostStateIdle:
+		return types.PostStateDegraded
+	}
+	return state
+}
+
+// publishFailed records the failure of an attempt to publish an ATX and logs it. Repeated failures
+// in the same epoch of a degraded identity are logged at debug level.
+// If the identity failed in Config.AutoPauseEpochs consecutive epochs, further attempts are paused
+// until the identity is resumed with ResumeSmeshing or the context is canceled.
+func (b *Builder) publishFailed(ctx context.Context, nodeID types.NodeID, err error) error {
+	failed, first := b.health.failed(nodeID, b.layerClock.CurrentLayer().GetEpoch())
+	logger := b.logger.With(log.ZShortStringer("smesherID", nodeID), zap.Int("failed_epochs", failed))
+	var nipostErr *NIPostError
+	if errors.As(err, &nipostErr) {
+		logger = logger.With(zap.Object("nipost", nipostErr))
+	}
+	b.health.setNIPostError(nodeID, nipostErr)
+	switch {
+	case failed < degradedEpochs:
+		logger.Warn("failed to publish atx", zap.Error(err))
+	case first:
+		logger.Warn("identity is degraded, failed to publish atx in consecutive epochs", zap.Error(err))
+	default:
+		logger.Debug("failed to publish atx", zap.Error(err))
+	}
+	if b.conf.AutoPauseEpochs == 0 || failed < b.conf.AutoPauseEpochs {
+		return nil
+	}
+	logger.Error("pausing attempts to publish atx until the identity is resumed", zap.Error(err))
+	resume := b.health.pause(nodeID)
+	select {
+	case <-ctx.Done():
+		b.health.unpause(nodeID)
+		return ctx.Err()
+	case <-resume:
+		logger.Info("resumed attempts to publish atx")
+		return nil
+	}
+}
+
+func (b *Builder) publishSucceeded(nodeID types.NodeID) {
+	if failed := b.health.succeeded(nodeID); failed >= degradedEpochs {
+		b.logger.Info("identity recovered",
+			log.ZShortStringer("smesherID", nodeID),
+			zap.Int("failed_epochs", failed),
+		)
+	}
+}
+
+// NIPostErrors returns the errors of the latest failed attempts to build a NIPoST of registered
+// identities. Identities whose latest attempt didn't fail while building the NIPoST are omitted.
+func (b *Builder) NIPostErrors() map[types.NodeID]*NIPostError {
+	b.smeshingMutex.Lock()
+	defer b.smeshingMutex.Unlock()
+	errs := make(map[types.NodeID]*NIPostError)
+	for id := range b.signers {
+		if err := b.health.nipostError(id); err != nil {
+			errs[id] = err
+		}
+	}
+	return errs
+}
+
+// ResumeSmeshing resumes attempts to publish ATXs of the identity that were paused after
+// Config.AutoPauseEpochs consecutive failed epochs.
+func (b *Builder) ResumeSmeshing(nodeID types.NodeID) error {
+	b.smeshingMutex.Lock()
+	_, registered := b.signers[nodeID]
+	b.smeshingMutex.Unlock()
+	if !registered {
+		return fmt.Errorf("identity %s is not registered", nodeID.ShortString())
+	}
+	if err := b.health.resume(nodeID); err != nil {
+		return fmt.Errorf("resume %s: %w", nodeID.ShortString(), err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestIdentitiesHealth(t *testing.T) {
+	var h identitiesHealth
+	id := types.RandomNodeID()
+	require.Equal(t, types.PostStateIdle, h.state(id, types.PostStateIdle))
+
+	failed, first := h.failed(id, 3)
+	require.Equal(t, 1, failed)
+	require.True(t, first)
+	failed, first = h.failed(id, 3)
+	require.Equal(t, 1, failed)
+	require.False(t, first)
+	require.Equal(t, types.PostStateIdle, h.state(id, types.PostStateIdle))
+
+	failed, first = h.failed(id, 4)
+	require.Equal(t, degradedEpochs, failed)
+	require.True(t, first)
+	require.Equal(t, types.PostStateDegraded, h.state(id, types.PostStateIdle))
+	require.Equal(t, types.PostStateProving, h.state(id, types.PostStateProving))
+
+	// an epoch without failures breaks the sequence
+	failed, _ = h.failed(id, 6)
+	require.Equal(t, 1, failed)
+
+	h.failed(id, 7)
+	require.Equal(t, degradedEpochs, h.succeeded(id))
+	require.Equal(t, types.PostStateIdle, h.state(id, types.PostStateIdle))
+
+	require.ErrorIs(t, h.resume(id), ErrNotPaused)
+	resume := h.pause(id)
+	require.Equal(t, types.PostStatePaused, h.state(id, types.PostStateProving))
+	require.NoError(t, h.resume(id))
+	require.Eventually(t, func() bool {
+		select {
+		case <-resume:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, types.PostStateIdle, h.state(id, types.PostStateIdle))
+}
+
+func TestBuilder_AutoPause(t *testing.T) {
+	tab := newTestBuilder(t, 1)
+	tab.conf.AutoPauseEpochs = 2
+	sig := maps.Keys(tab.signers)[0]
+	errPublish := errors.New("publish failed")
+
+	tab.mclock.EXPECT().CurrentLayer().Return(types.EpochID(2).FirstLayer())
+	require.NoError(t, tab.publishFailed(context.Background(), sig, errPublish))
+	require.ErrorIs(t, tab.ResumeSmeshing(sig), ErrNotPaused)
+
+	tab.mclock.EXPECT().CurrentLayer().Return(types.EpochID(3).FirstLayer())
+	paused := make(chan error, 1)
+	go func() { paused <- tab.publishFailed(context.Background(), sig, errPublish) }()
+	require.Eventually(t, func() bool {
+		return tab.health.state(sig, types.PostStateIdle) == types.PostStatePaused
+	}, time.Second, 10*time.Millisecond)
+	for _, state := range tab.PostStates() {
+		require.Equal(t, types.PostStatePaused, state)
+	}
+
+	require.NoError(t, tab.ResumeSmeshing(sig))
+	select {
+	case err := <-paused:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "identity wasn't resumed")
+	}
+	for _, state := range tab.PostStates() {
+		require.Equal(t, types.PostStateIdle, state)
+	}
+	require.Error(t, tab.ResumeSmeshing(types.RandomNodeID()))
+
+	t.Run("stopped while paused", func(t *testing.T) {
+		tab.mclock.EXPECT().CurrentLayer().Return(types.EpochID(4).FirstLayer())
+		require.NoError(t, tab.publishFailed(context.Background(), sig, errPublish))
+		tab.mclock.EXPECT().CurrentLayer().Return(types.EpochID(5).FirstLayer())
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() { paused <- tab.publishFailed(ctx, sig, errPublish) }()
+		require.Eventually(t, func() bool {
+			return tab.health.state(sig, types.PostStateIdle) == types.PostStatePaused
+		}, time.Second, 10*time.Millisecond)
+		cancel()
+		require.ErrorIs(t, <-paused, context.Canceled)
+		require.ErrorIs(t, tab.ResumeSmeshing(sig), ErrNotPaused)
+	})
+}
+
+func TestBuilder_NIPostErrors(t *testing.T) {
+	tab := newTestBuilder(t, 1)
+	sig := maps.Keys(tab.signers)[0]
+	require.Empty(t, tab.NIPostErrors())
+
+	nipostErr := &NIPostError{
+		Phase:        NIPostPhasePoetProof,
+		PublishEpoch: 3,
+		Poet:         "http://poet",
+		Round:        "7",
+		Retryable:    true,
+		Err:          &PoetSvcUnstableError{source: ErrPoetProofNotReceived},
+	}
+	tab.mclock.EXPECT().CurrentLayer().Return(types.EpochID(2).FirstLayer()).Times(2)
+	err := fmt.Errorf("build NIPost: %w", nipostErr)
+	require.NoError(t, tab.publishFailed(context.Background(), sig, err))
+	require.Equal(t, map[types.NodeID]*NIPostError{sig: nipostErr}, tab.NIPostErrors())
+
+	// a failure outside of the NIPoST construction replaces the error
+	require.NoError(t, tab.publishFailed(context.Background(), sig, errors.New("publish failed")))
+	require.Empty(t, tab.NIPostErrors())
+
+	tab.mclock.EXPECT().CurrentLayer().Return(types.EpochID(3).FirstLayer())
+	require.NoError(t, tab.publishFailed(context.Background(), sig, err))
+	require.Len(t, tab.NIPostErrors(), 1)
+	tab.publishSucceeded(sig)
+	require.Empty(t, tab.NIPostErrors())
+}
+
+func TestNIPostError(t *testing.T) {
+	err := fmt.Errorf("build NIPost: %w", &NIPostError{
+		Phase:        NIPostPhasePoetProof,
+		PublishEpoch: 3,
+		Poet:         "http://poet",
+		Round:        "7",
+		Err:          fmt.Errorf("%w: deadline exceeded", ErrATXChallengeExpired),
+	})
+	require.ErrorIs(t, err, ErrATXChallengeExpired)
+	require.EqualError(t, err,
+		"build NIPost: nipost poet_proof phase for publish epoch 3 (poet http://poet round 7): "+
+			"builder: atx expired: deadline exceeded",
+	)
+}
@@ -123,9 +123,12 @@ type SmeshingProvider interface {
 	Smeshing() bool
 	StartSmeshing(types.Address) error
 	StopSmeshing(bool) error
+	ResumeSmeshing(types.NodeID) error
+	NIPostErrors() map[types.NodeID]*NIPostError
 	SmesherIDs() []types.NodeID
 	Coinbase() types.Address
 	SetCoinbase(coinbase types.Address)
+	Attest() ([]*wire.SmeshingAttestation, error)
 }
 
 // PoetService servers as an interface to communicate with a PoET server.
@@ -149,6 +152,11 @@ type PoetService interface {
 
 	// Proof returns the proof for the given round ID.
 	Proof(ctx context.Context, roundID string) (*types.PoetProof, []types.Hash32, error)
+
+	// Preflight runs the checks of a submission for the given nodeID without registering.
+	//
+	// The returned result is not nil even if a check failed.
+	Preflight(ctx context.Context, id types.NodeID) (*PoetPreflight, error)
 }
 
 // A certifier client that the certifierService uses to obtain certificates
@@ -167,16 +175,20 @@ type certifierClient interface {
 // certifierService is used to certify nodeID for registering in the poet.
 // It holds the certificates and can recertify if needed.
 type certifierService interface {
-	// Certificate acquires a certificate for the ID in the given certifier.
+	// Certificate acquires a certificate for the ID in any certifier trusted by the poet:
+	// the one advertised by the poet (can be nil) or the ones configured for the poet or all poets.
 	// The certificate confirms that the ID is verified and it can be later used to submit in poet.
+	//
+	// Returns ErrCertificatesNotSupported if the poet doesn't trust any certifier.
 	Certificate(
 		ctx context.Context,
 		id types.NodeID,
-		certifierAddress *url.URL,
-		pubkey []byte,
+		poet string,
+		advertised *types.CertifierInfo,
 	) (*certifier.PoetCert, error)
 
-	DeleteCertificate(id types.NodeID, pubkey []byte) error
+	// DeleteCertificate deletes certificates of the ID in all certifiers trusted by the poet.
+	DeleteCertificate(id types.NodeID, poet string, advertised *types.CertifierInfo) error
 }
 
 type poetDbAPI interface {
@@ -205,5 +217,23 @@ type PostClient interface {
 
 type PostStates interface {
 	Set(id types.NodeID, state types.PostState)
+	// CompareAndSet sets the state of the identity only if it is still in the old state,
+	// and reports whether the state was set.
+	CompareAndSet(id types.NodeID, old, state types.PostState) bool
 	Get() map[types.NodeID]types.PostState
 }
+
+// identityProvider provides the identities managed by the node.
+type identityProvider interface {
+	SmesherIDs() []types.NodeID
+}
+
+// poetPreflighter runs the checks of submissions to the poets without registering.
+type poetPreflighter interface {
+	Preflight(ctx context.Context, nodeID types.NodeID) []PoetPreflight
+}
+
+// clockChecker reports the offset of the system clock to the clocks of peers.
+type clockChecker interface {
+	ClockOffset() (time.Duration, error)
+}
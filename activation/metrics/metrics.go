@@ -17,6 +17,13 @@ var PostDuration = metrics.NewGauge(
 	[]string{},
 ).WithLabelValues()
 
+var PostMargin = metrics.NewGauge(
+	"post_margin",
+	namespace,
+	"seconds left in the poet cycle gap after the last PoST, negative if the PoST didn't fit",
+	[]string{},
+).WithLabelValues()
+
 var PoetPowDuration = metrics.NewGauge(
 	"poet_pow_duration",
 	namespace,
@@ -43,6 +50,17 @@ var (
 	PublishLateWindowLatency   = publishWindowLatency.WithLabelValues("late")
 )
 
+var (
+	atxPublished = metrics.NewCounter(
+		"atx_published",
+		namespace,
+		"number of published atxs, late ones were published within the late publish window",
+		[]string{"condition"},
+	)
+	PublishedOnTime = atxPublished.WithLabelValues("ontime")
+	PublishedLate   = atxPublished.WithLabelValues("late")
+)
+
 var PostVerificationLatency = metrics.NewHistogramWithBuckets(
 	"post_verification_seconds",
 	namespace,
@@ -50,3 +68,56 @@ var PostVerificationLatency = metrics.NewHistogramWithBuckets(
 	[]string{},
 	prometheus.ExponentialBuckets(1, 2, 20),
 ).WithLabelValues()
+
+var (
+	certificateLookups = metrics.NewCounter(
+		"certificate_lookups",
+		namespace,
+		"number of lookups of poet certificates, a miss requires a request to a certifier",
+		[]string{"result"},
+	)
+	CertificateHit  = certificateLookups.WithLabelValues("hit")
+	CertificateMiss = certificateLookups.WithLabelValues("miss")
+)
+
+var (
+	certifyLatency = metrics.NewHistogramWithBuckets(
+		"certify_seconds",
+		namespace,
+		"duration of requests to certifiers in seconds",
+		[]string{"outcome"},
+		prometheus.ExponentialBuckets(0.01, 2, 16),
+	)
+	CertifySucceeded = certifyLatency.WithLabelValues("succeeded")
+	CertifyFailed    = certifyLatency.WithLabelValues("failed")
+)
+
+// CertificatesExpired counts stored poet certificates that expired, per poet.
+var CertificatesExpired = metrics.NewCounter(
+	"certificates_expired",
+	namespace,
+	"number of stored poet certificates that expired",
+	[]string{"poet"},
+)
+
+var (
+	certificateRefreshes = metrics.NewCounter(
+		"certificate_refreshes",
+		namespace,
+		"number of refreshes of stored poet certificates",
+		[]string{"outcome"},
+	)
+	CertificateRefreshed     = certificateRefreshes.WithLabelValues("succeeded")
+	CertificateRefreshFailed = certificateRefreshes.WithLabelValues("failed")
+)
+
+var (
+	poetAuthorizations = metrics.NewCounter(
+		"poet_authorizations",
+		namespace,
+		"number of authorizations for poet registration by method, pow is the fallback if certification fails",
+		[]string{"method"},
+	)
+	PoetAuthorizedCertificate = poetAuthorizations.WithLabelValues("certificate")
+	PoetAuthorizedPoW         = poetAuthorizations.WithLabelValues("pow")
+)
@@ -1406,6 +1406,45 @@ func (m *MockSmeshingProvider) EXPECT() *MockSmeshingProviderMockRecorder {
 	return m.recorder
 }
 
+// Attest mocks base method.
+func (m *MockSmeshingProvider) Attest() ([]*wire.SmeshingAttestation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attest")
+	ret0, _ := ret[0].([]*wire.SmeshingAttestation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Attest indicates an expected call of Attest.
+func (mr *MockSmeshingProviderMockRecorder) Attest() *MockSmeshingProviderAttestCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attest", reflect.TypeOf((*MockSmeshingProvider)(nil).Attest))
+	return &MockSmeshingProviderAttestCall{Call: call}
+}
+
+// MockSmeshingProviderAttestCall wrap *gomock.Call
+type MockSmeshingProviderAttestCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSmeshingProviderAttestCall) Return(arg0 []*wire.SmeshingAttestation, arg1 error) *MockSmeshingProviderAttestCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSmeshingProviderAttestCall) Do(f func() ([]*wire.SmeshingAttestation, error)) *MockSmeshingProviderAttestCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSmeshingProviderAttestCall) DoAndReturn(f func() ([]*wire.SmeshingAttestation, error)) *MockSmeshingProviderAttestCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Coinbase mocks base method.
 func (m *MockSmeshingProvider) Coinbase() types.Address {
 	m.ctrl.T.Helper()
@@ -1444,6 +1483,82 @@ func (c *MockSmeshingProviderCoinbaseCall) DoAndReturn(f func() types.Address) *
 	return c
 }
 
+// NIPostErrors mocks base method.
+func (m *MockSmeshingProvider) NIPostErrors() map[types.NodeID]*NIPostError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NIPostErrors")
+	ret0, _ := ret[0].(map[types.NodeID]*NIPostError)
+	return ret0
+}
+
+// NIPostErrors indicates an expected call of NIPostErrors.
+func (mr *MockSmeshingProviderMockRecorder) NIPostErrors() *MockSmeshingProviderNIPostErrorsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NIPostErrors", reflect.TypeOf((*MockSmeshingProvider)(nil).NIPostErrors))
+	return &MockSmeshingProviderNIPostErrorsCall{Call: call}
+}
+
+// MockSmeshingProviderNIPostErrorsCall wrap *gomock.Call
+type MockSmeshingProviderNIPostErrorsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSmeshingProviderNIPostErrorsCall) Return(arg0 map[types.NodeID]*NIPostError) *MockSmeshingProviderNIPostErrorsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSmeshingProviderNIPostErrorsCall) Do(f func() map[types.NodeID]*NIPostError) *MockSmeshingProviderNIPostErrorsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSmeshingProviderNIPostErrorsCall) DoAndReturn(f func() map[types.NodeID]*NIPostError) *MockSmeshingProviderNIPostErrorsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ResumeSmeshing mocks base method.
+func (m *MockSmeshingProvider) ResumeSmeshing(arg0 types.NodeID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSmeshing", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeSmeshing indicates an expected call of ResumeSmeshing.
+func (mr *MockSmeshingProviderMockRecorder) ResumeSmeshing(arg0 any) *MockSmeshingProviderResumeSmeshingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSmeshing", reflect.TypeOf((*MockSmeshingProvider)(nil).ResumeSmeshing), arg0)
+	return &MockSmeshingProviderResumeSmeshingCall{Call: call}
+}
+
+// MockSmeshingProviderResumeSmeshingCall wrap *gomock.Call
+type MockSmeshingProviderResumeSmeshingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSmeshingProviderResumeSmeshingCall) Return(arg0 error) *MockSmeshingProviderResumeSmeshingCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSmeshingProviderResumeSmeshingCall) Do(f func(types.NodeID) error) *MockSmeshingProviderResumeSmeshingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSmeshingProviderResumeSmeshingCall) DoAndReturn(f func(types.NodeID) error) *MockSmeshingProviderResumeSmeshingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetCoinbase mocks base method.
 func (m *MockSmeshingProvider) SetCoinbase(coinbase types.Address) {
 	m.ctrl.T.Helper()
@@ -1732,6 +1847,45 @@ func (c *MockPoetServiceCertifyCall) DoAndReturn(f func(context.Context, types.N
 	return c
 }
 
+// Preflight mocks base method.
+func (m *MockPoetService) Preflight(ctx context.Context, id types.NodeID) (*PoetPreflight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preflight", ctx, id)
+	ret0, _ := ret[0].(*PoetPreflight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preflight indicates an expected call of Preflight.
+func (mr *MockPoetServiceMockRecorder) Preflight(ctx, id any) *MockPoetServicePreflightCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preflight", reflect.TypeOf((*MockPoetService)(nil).Preflight), ctx, id)
+	return &MockPoetServicePreflightCall{Call: call}
+}
+
+// MockPoetServicePreflightCall wrap *gomock.Call
+type MockPoetServicePreflightCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPoetServicePreflightCall) Return(arg0 *PoetPreflight, arg1 error) *MockPoetServicePreflightCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPoetServicePreflightCall) Do(f func(context.Context, types.NodeID) (*PoetPreflight, error)) *MockPoetServicePreflightCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPoetServicePreflightCall) DoAndReturn(f func(context.Context, types.NodeID) (*PoetPreflight, error)) *MockPoetServicePreflightCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Proof mocks base method.
 func (m *MockPoetService) Proof(ctx context.Context, roundID string) (*types.PoetProof, []types.Hash32, error) {
 	m.ctrl.T.Helper()
@@ -1897,18 +2051,18 @@ func (m *MockcertifierService) EXPECT() *MockcertifierServiceMockRecorder {
 }
 
 // Certificate mocks base method.
-func (m *MockcertifierService) Certificate(ctx context.Context, id types.NodeID, certifierAddress *url.URL, pubkey []byte) (*certifier.PoetCert, error) {
+func (m *MockcertifierService) Certificate(ctx context.Context, id types.NodeID, poet string, advertised *types.CertifierInfo) (*certifier.PoetCert, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Certificate", ctx, id, certifierAddress, pubkey)
+	ret := m.ctrl.Call(m, "Certificate", ctx, id, poet, advertised)
 	ret0, _ := ret[0].(*certifier.PoetCert)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Certificate indicates an expected call of Certificate.
-func (mr *MockcertifierServiceMockRecorder) Certificate(ctx, id, certifierAddress, pubkey any) *MockcertifierServiceCertificateCall {
+func (mr *MockcertifierServiceMockRecorder) Certificate(ctx, id, poet, advertised any) *MockcertifierServiceCertificateCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Certificate", reflect.TypeOf((*MockcertifierService)(nil).Certificate), ctx, id, certifierAddress, pubkey)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Certificate", reflect.TypeOf((*MockcertifierService)(nil).Certificate), ctx, id, poet, advertised)
 	return &MockcertifierServiceCertificateCall{Call: call}
 }
 
@@ -1924,29 +2078,29 @@ func (c *MockcertifierServiceCertificateCall) Return(arg0 *certifier.PoetCert, a
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockcertifierServiceCertificateCall) Do(f func(context.Context, types.NodeID, *url.URL, []byte) (*certifier.PoetCert, error)) *MockcertifierServiceCertificateCall {
+func (c *MockcertifierServiceCertificateCall) Do(f func(context.Context, types.NodeID, string, *types.CertifierInfo) (*certifier.PoetCert, error)) *MockcertifierServiceCertificateCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockcertifierServiceCertificateCall) DoAndReturn(f func(context.Context, types.NodeID, *url.URL, []byte) (*certifier.PoetCert, error)) *MockcertifierServiceCertificateCall {
+func (c *MockcertifierServiceCertificateCall) DoAndReturn(f func(context.Context, types.NodeID, string, *types.CertifierInfo) (*certifier.PoetCert, error)) *MockcertifierServiceCertificateCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
 // DeleteCertificate mocks base method.
-func (m *MockcertifierService) DeleteCertificate(id types.NodeID, pubkey []byte) error {
+func (m *MockcertifierService) DeleteCertificate(id types.NodeID, poet string, advertised *types.CertifierInfo) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCertificate", id, pubkey)
+	ret := m.ctrl.Call(m, "DeleteCertificate", id, poet, advertised)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCertificate indicates an expected call of DeleteCertificate.
-func (mr *MockcertifierServiceMockRecorder) DeleteCertificate(id, pubkey any) *MockcertifierServiceDeleteCertificateCall {
+func (mr *MockcertifierServiceMockRecorder) DeleteCertificate(id, poet, advertised any) *MockcertifierServiceDeleteCertificateCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCertificate", reflect.TypeOf((*MockcertifierService)(nil).DeleteCertificate), id, pubkey)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCertificate", reflect.TypeOf((*MockcertifierService)(nil).DeleteCertificate), id, poet, advertised)
 	return &MockcertifierServiceDeleteCertificateCall{Call: call}
 }
 
@@ -1962,13 +2116,13 @@ func (c *MockcertifierServiceDeleteCertificateCall) Return(arg0 error) *Mockcert
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockcertifierServiceDeleteCertificateCall) Do(f func(types.NodeID, []byte) error) *MockcertifierServiceDeleteCertificateCall {
+func (c *MockcertifierServiceDeleteCertificateCall) Do(f func(types.NodeID, string, *types.CertifierInfo) error) *MockcertifierServiceDeleteCertificateCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockcertifierServiceDeleteCertificateCall) DoAndReturn(f func(types.NodeID, []byte) error) *MockcertifierServiceDeleteCertificateCall {
+func (c *MockcertifierServiceDeleteCertificateCall) DoAndReturn(f func(types.NodeID, string, *types.CertifierInfo) error) *MockcertifierServiceDeleteCertificateCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -2359,6 +2513,44 @@ func (m *MockPostStates) EXPECT() *MockPostStatesMockRecorder {
 	return m.recorder
 }
 
+// CompareAndSet mocks base method.
+func (m *MockPostStates) CompareAndSet(id types.NodeID, old, state types.PostState) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndSet", id, old, state)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// CompareAndSet indicates an expected call of CompareAndSet.
+func (mr *MockPostStatesMockRecorder) CompareAndSet(id, old, state any) *MockPostStatesCompareAndSetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndSet", reflect.TypeOf((*MockPostStates)(nil).CompareAndSet), id, old, state)
+	return &MockPostStatesCompareAndSetCall{Call: call}
+}
+
+// MockPostStatesCompareAndSetCall wrap *gomock.Call
+type MockPostStatesCompareAndSetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPostStatesCompareAndSetCall) Return(arg0 bool) *MockPostStatesCompareAndSetCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPostStatesCompareAndSetCall) Do(f func(types.NodeID, types.PostState, types.PostState) bool) *MockPostStatesCompareAndSetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPostStatesCompareAndSetCall) DoAndReturn(f func(types.NodeID, types.PostState, types.PostState) bool) *MockPostStatesCompareAndSetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Get mocks base method.
 func (m *MockPostStates) Get() map[types.NodeID]types.PostState {
 	m.ctrl.T.Helper()
@@ -2432,3 +2624,187 @@ func (c *MockPostStatesSetCall) DoAndReturn(f func(types.NodeID, types.PostState
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// MockidentityProvider is a mock of identityProvider interface.
+type MockidentityProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockidentityProviderMockRecorder
+}
+
+// MockidentityProviderMockRecorder is the mock recorder for MockidentityProvider.
+type MockidentityProviderMockRecorder struct {
+	mock *MockidentityProvider
+}
+
+// NewMockidentityProvider creates a new mock instance.
+func NewMockidentityProvider(ctrl *gomock.Controller) *MockidentityProvider {
+	mock := &MockidentityProvider{ctrl: ctrl}
+	mock.recorder = &MockidentityProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockidentityProvider) EXPECT() *MockidentityProviderMockRecorder {
+	return m.recorder
+}
+
+// SmesherIDs mocks base method.
+func (m *MockidentityProvider) SmesherIDs() []types.NodeID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SmesherIDs")
+	ret0, _ := ret[0].([]types.NodeID)
+	return ret0
+}
+
+// SmesherIDs indicates an expected call of SmesherIDs.
+func (mr *MockidentityProviderMockRecorder) SmesherIDs() *MockidentityProviderSmesherIDsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SmesherIDs", reflect.TypeOf((*MockidentityProvider)(nil).SmesherIDs))
+	return &MockidentityProviderSmesherIDsCall{Call: call}
+}
+
+// MockidentityProviderSmesherIDsCall wrap *gomock.Call
+type MockidentityProviderSmesherIDsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockidentityProviderSmesherIDsCall) Return(arg0 []types.NodeID) *MockidentityProviderSmesherIDsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockidentityProviderSmesherIDsCall) Do(f func() []types.NodeID) *MockidentityProviderSmesherIDsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockidentityProviderSmesherIDsCall) DoAndReturn(f func() []types.NodeID) *MockidentityProviderSmesherIDsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockpoetPreflighter is a mock of poetPreflighter interface.
+type MockpoetPreflighter struct {
+	ctrl     *gomock.Controller
+	recorder *MockpoetPreflighterMockRecorder
+}
+
+// MockpoetPreflighterMockRecorder is the mock recorder for MockpoetPreflighter.
+type MockpoetPreflighterMockRecorder struct {
+	mock *MockpoetPreflighter
+}
+
+// NewMockpoetPreflighter creates a new mock instance.
+func NewMockpoetPreflighter(ctrl *gomock.Controller) *MockpoetPreflighter {
+	mock := &MockpoetPreflighter{ctrl: ctrl}
+	mock.recorder = &MockpoetPreflighterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpoetPreflighter) EXPECT() *MockpoetPreflighterMockRecorder {
+	return m.recorder
+}
+
+// Preflight mocks base method.
+func (m *MockpoetPreflighter) Preflight(ctx context.Context, nodeID types.NodeID) []PoetPreflight {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preflight", ctx, nodeID)
+	ret0, _ := ret[0].([]PoetPreflight)
+	return ret0
+}
+
+// Preflight indicates an expected call of Preflight.
+func (mr *MockpoetPreflighterMockRecorder) Preflight(ctx, nodeID any) *MockpoetPreflighterPreflightCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preflight", reflect.TypeOf((*MockpoetPreflighter)(nil).Preflight), ctx, nodeID)
+	return &MockpoetPreflighterPreflightCall{Call: call}
+}
+
+// MockpoetPreflighterPreflightCall wrap *gomock.Call
+type MockpoetPreflighterPreflightCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockpoetPreflighterPreflightCall) Return(arg0 []PoetPreflight) *MockpoetPreflighterPreflightCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockpoetPreflighterPreflightCall) Do(f func(context.Context, types.NodeID) []PoetPreflight) *MockpoetPreflighterPreflightCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockpoetPreflighterPreflightCall) DoAndReturn(f func(context.Context, types.NodeID) []PoetPreflight) *MockpoetPreflighterPreflightCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockclockChecker is a mock of clockChecker interface.
+type MockclockChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockclockCheckerMockRecorder
+}
+
+// MockclockCheckerMockRecorder is the mock recorder for MockclockChecker.
+type MockclockCheckerMockRecorder struct {
+	mock *MockclockChecker
+}
+
+// NewMockclockChecker creates a new mock instance.
+func NewMockclockChecker(ctrl *gomock.Controller) *MockclockChecker {
+	mock := &MockclockChecker{ctrl: ctrl}
+	mock.recorder = &MockclockCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockclockChecker) EXPECT() *MockclockCheckerMockRecorder {
+	return m.recorder
+}
+
+// ClockOffset mocks base method.
+func (m *MockclockChecker) ClockOffset() (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClockOffset")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClockOffset indicates an expected call of ClockOffset.
+func (mr *MockclockCheckerMockRecorder) ClockOffset() *MockclockCheckerClockOffsetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClockOffset", reflect.TypeOf((*MockclockChecker)(nil).ClockOffset))
+	return &MockclockCheckerClockOffsetCall{Call: call}
+}
+
+// MockclockCheckerClockOffsetCall wrap *gomock.Call
+type MockclockCheckerClockOffsetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockclockCheckerClockOffsetCall) Return(arg0 time.Duration, arg1 error) *MockclockCheckerClockOffsetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockclockCheckerClockOffsetCall) Do(f func() (time.Duration, error)) *MockclockCheckerClockOffsetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockclockCheckerClockOffsetCall) DoAndReturn(f func() (time.Duration, error)) *MockclockCheckerClockOffsetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
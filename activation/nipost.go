@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/spacemeshos/merkle-tree"
 	"github.com/spacemeshos/poet/shared"
 	postshared "github.com/spacemeshos/post/shared"
@@ -43,17 +47,38 @@ const (
 	maxPoetGetProofJitter = 0.04
 )
 
-var ErrInvalidInitialPost = errors.New("invalid initial post")
+var (
+	ErrInvalidInitialPost = errors.New("invalid initial post")
+
+	errNotMember = errors.New("challenge is not a member of the proof")
+)
+
+// postMarginWarning is the share of the cycle gap below which the margin left after PoST generation
+// is considered too small.
+const postMarginWarning = 0.2
 
 // NIPostBuilder holds the required state and dependencies to create Non-Interactive Proofs of Space-Time (NIPost).
 type NIPostBuilder struct {
 	localDB sql.LocalDatabase
 
 	poetProvers map[string]PoetService
+	// fallbackPoets replace poetProvers for new registrations of an identity once its PoST generation
+	// took longer than the cycle gap of poetProvers.
+	fallbackPoets map[string]PoetService
+	// fallback are the identities that use fallbackPoets for new registrations.
+	fallbackMu sync.Mutex
+	fallback   map[types.NodeID]struct{}
+
+	// unstableAttempts counts for every identity the consecutive attempts to submit a challenge
+	// in which all primary poets were unstable.
+	unstableMu       sync.Mutex
+	unstableAttempts map[types.NodeID]int
+
 	postService postService
 	logger      *zap.Logger
 	poetCfg     PoetConfig
 	layerClock  layerClock
+	clock       clockwork.Clock
 	postStates  PostStates
 	validator   nipostValidator
 }
@@ -69,12 +94,32 @@ func WithPoetServices(clients ...PoetService) NIPostBuilderOption {
 	}
 }
 
+// WithFallbackPoetServices sets poet services with the longer cycle gap (PoetConfig.FallbackCycleGap),
+// that are used for new registrations if generating a PoST doesn't fit into the cycle gap of the
+// poets configured with WithPoetServices.
+func WithFallbackPoetServices(clients ...PoetService) NIPostBuilderOption {
+	return func(nb *NIPostBuilder) {
+		nb.fallbackPoets = make(map[string]PoetService, len(clients))
+		for _, client := range clients {
+			nb.fallbackPoets[client.Address()] = client
+		}
+	}
+}
+
 func NipostbuilderWithPostStates(ps PostStates) NIPostBuilderOption {
 	return func(nb *NIPostBuilder) {
 		nb.postStates = ps
 	}
 }
 
+// NipostbuilderWithWallClock sets the clock that is used for deadlines and waiting for poet rounds
+// instead of the system clock. It should be consistent with the layer clock.
+func NipostbuilderWithWallClock(clock clockwork.Clock) NIPostBuilderOption {
+	return func(nb *NIPostBuilder) {
+		nb.clock = clock
+	}
+}
+
 // NewNIPostBuilder returns a NIPostBuilder.
 func NewNIPostBuilder(
 	db sql.LocalDatabase,
@@ -91,8 +136,12 @@ func NewNIPostBuilder(
 		logger:      lg,
 		poetCfg:     poetCfg,
 		layerClock:  layerClock,
+		clock:       clockwork.NewRealClock(),
 		postStates:  NewPostStates(lg),
 		validator:   validator,
+
+		unstableAttempts: make(map[types.NodeID]int),
+		fallback:         make(map[types.NodeID]struct{}),
 	}
 
 	for _, opt := range opts {
@@ -129,7 +178,7 @@ func (nb *NIPostBuilder) Proof(
 					events.EmitPostFailure(nodeID)
 				}
 				return nil, nil, ctx.Err()
-			case <-time.After(2 * time.Second): // Wait a few seconds and try connecting again
+			case <-nb.clock.After(2 * time.Second): // Wait a few seconds and try connecting again
 				retries++
 				if retries%10 == 0 { // every 20 seconds inform user about lost connection (for remote post service)
 					// TODO(mafa): emit event warning user about lost connection
@@ -207,11 +256,12 @@ func (nb *NIPostBuilder) BuildNIPost(
 	//  WE ARE HERE            PROOF BECOMES         ATX PUBLICATION
 	//                           AVAILABLE               DEADLINE
 
+	cycleGap := nb.cycleGap(signer.NodeID())
 	poetRoundStart := nb.layerClock.LayerToTime((postChallenge.PublishEpoch - 1).FirstLayer()).
 		Add(nb.poetCfg.PhaseShift)
 	curPoetRoundEnd := nb.layerClock.LayerToTime(postChallenge.PublishEpoch.FirstLayer()).
 		Add(nb.poetCfg.PhaseShift).
-		Add(-nb.poetCfg.CycleGap)
+		Add(-cycleGap)
 
 	// we want to publish before the publish epoch ends or we won't receive rewards
 	publishEpochEnd := nb.layerClock.LayerToTime((postChallenge.PublishEpoch + 1).FirstLayer())
@@ -219,7 +269,7 @@ func (nb *NIPostBuilder) BuildNIPost(
 	// we want to fetch the PoET proof latest 1 CycleGap before the publish epoch ends
 	// so that a node that is setup correctly (i.e. can generate a PoST proof within the cycle gap)
 	// has enough time left to generate a post proof and publish
-	poetProofDeadline := publishEpochEnd.Add(-nb.poetCfg.CycleGap)
+	poetProofDeadline := publishEpochEnd.Add(-cycleGap)
 
 	logger.Info("building nipost",
 		zap.Time("poet round start", poetRoundStart),
@@ -247,7 +297,17 @@ func (nb *NIPostBuilder) BuildNIPost(
 		)
 		return nil, err
 	case err != nil:
-		return nil, fmt.Errorf("submitting to poets: %w", err)
+		nipostErr := &NIPostError{
+			Phase:        NIPostPhaseSubmit,
+			PublishEpoch: postChallenge.PublishEpoch,
+			Deadline:     poetRoundStart,
+			Retryable:    !errors.Is(err, ErrATXChallengeExpired) && !errors.Is(err, ErrPoetVersionUnsupported),
+			Err:          fmt.Errorf("submitting to poets: %w", err),
+		}
+		if poets := maps.Keys(nb.activePoets(signer.NodeID())); len(poets) == 1 {
+			nipostErr.Poet = poets[0]
+		}
+		return nil, nipostErr
 	}
 
 	// Phase 1: query PoET services for proofs
@@ -256,26 +316,40 @@ func (nb *NIPostBuilder) BuildNIPost(
 		nb.logger.Warn("cannot get poet proof ref", zap.Error(err))
 	}
 	if poetProofRef == types.EmptyPoetProofRef {
-		now := time.Now()
+		now := nb.clock.Now()
 		// Deadline: the end of the publish epoch minus the cycle gap. A node that is setup correctly (i.e. can
 		// generate a PoST proof within the cycle gap) has enough time left to generate a post proof and publish.
+		proofErr := func(retryable bool, err error) *NIPostError {
+			nipostErr := &NIPostError{
+				Phase:        NIPostPhasePoetProof,
+				PublishEpoch: postChallenge.PublishEpoch,
+				Deadline:     poetProofDeadline,
+				Retryable:    retryable,
+				Err:          err,
+			}
+			if len(submittedRegistrations) == 1 {
+				nipostErr.Poet = submittedRegistrations[0].Address
+				nipostErr.Round = submittedRegistrations[0].RoundID
+			}
+			return nipostErr
+		}
 		if poetProofDeadline.Before(now) {
-			return nil, fmt.Errorf(
+			return nil, proofErr(false, fmt.Errorf(
 				"%w: deadline to query poet proof for pub epoch %d exceeded (deadline: %s, now: %s)",
 				ErrATXChallengeExpired,
 				postChallenge.PublishEpoch,
 				poetProofDeadline,
 				now,
-			)
+			))
 		}
 
 		events.EmitPoetWaitProof(signer.NodeID(), postChallenge.PublishEpoch, curPoetRoundEnd)
 		poetProofRef, membership, err = nb.getBestProof(ctx, signer.NodeID(), challenge, submittedRegistrations)
 		if err != nil {
-			return nil, &PoetSvcUnstableError{msg: "getBestProof failed", source: err}
+			return nil, proofErr(true, &PoetSvcUnstableError{msg: "getBestProof failed", source: err})
 		}
 		if poetProofRef == types.EmptyPoetProofRef {
-			return nil, &PoetSvcUnstableError{source: ErrPoetProofNotReceived}
+			return nil, proofErr(true, &PoetSvcUnstableError{source: ErrPoetProofNotReceived})
 		}
 		if err := nipost.UpdatePoetProofRef(nb.localDB, signer.NodeID(), poetProofRef, membership); err != nil {
 			nb.logger.Warn("cannot persist poet proof ref", zap.Error(err))
@@ -288,19 +362,32 @@ func (nb *NIPostBuilder) BuildNIPost(
 		nb.logger.Warn("cannot get nipost", zap.Error(err))
 	}
 	if nipostState == nil {
-		now := time.Now()
+		now := nb.clock.Now()
 		// Deadline: the end of the publish epoch. If we do not publish within
 		// the publish epoch we won't receive any rewards in the target epoch.
+		// It is extended by the late publish window if configured.
+		publishDeadline := publishEpochEnd.Add(nb.poetCfg.LatePublishWindow)
+		if publishDeadline.Before(now) {
+			return nil, &NIPostError{
+				Phase:        NIPostPhasePost,
+				PublishEpoch: postChallenge.PublishEpoch,
+				Deadline:     publishDeadline,
+				Err: fmt.Errorf(
+					"%w: deadline to publish ATX for pub epoch %d exceeded (deadline: %s, now: %s)",
+					ErrATXChallengeExpired,
+					postChallenge.PublishEpoch,
+					publishDeadline,
+					now,
+				),
+			}
+		}
 		if publishEpochEnd.Before(now) {
-			return nil, fmt.Errorf(
-				"%w: deadline to publish ATX for pub epoch %d exceeded (deadline: %s, now: %s)",
-				ErrATXChallengeExpired,
-				postChallenge.PublishEpoch,
-				publishEpochEnd,
-				now,
+			logger.Warn("publish epoch has ended, generating PoST within the late publish window",
+				zap.Time("publish epoch end", publishEpochEnd),
+				zap.Time("deadline", publishDeadline),
 			)
 		}
-		postCtx, cancel := context.WithDeadline(ctx, publishEpochEnd)
+		postCtx, cancel := context.WithDeadline(ctx, publishDeadline)
 		defer cancel()
 
 		nb.logger.Info("starting post execution", zap.Binary("challenge", poetProofRef[:]))
@@ -308,7 +395,14 @@ func (nb *NIPostBuilder) BuildNIPost(
 		startTime := time.Now()
 		proof, postInfo, err := nb.Proof(postCtx, signer.NodeID(), poetProofRef[:], postChallenge)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate Post: %w", err)
+			return nil, &NIPostError{
+				Phase:        NIPostPhasePost,
+				PublishEpoch: postChallenge.PublishEpoch,
+				Deadline:     publishDeadline,
+				// the initial post has to be regenerated, and a missed deadline can't be met on retry
+				Retryable: !errors.Is(err, ErrInvalidInitialPost) && !errors.Is(err, context.DeadlineExceeded),
+				Err:       fmt.Errorf("failed to generate Post: %w", err),
+			}
 		}
 
 		postGenDuration := time.Since(startTime)
@@ -317,6 +411,7 @@ func (nb *NIPostBuilder) BuildNIPost(
 
 		metrics.PostDuration.Set(float64(postGenDuration.Nanoseconds()))
 		public.PostSeconds.Set(postGenDuration.Seconds())
+		nb.checkPostDuration(signer.NodeID(), postGenDuration)
 
 		nipostState = &nipost.NIPostState{
 			NIPost: &types.NIPost{
@@ -339,6 +434,219 @@ func (nb *NIPostBuilder) BuildNIPost(
 	return nipostState, nil
 }
 
+// usesFallback returns true if the identity uses the fallback poets for new registrations.
+func (nb *NIPostBuilder) usesFallback(nodeID types.NodeID) bool {
+	nb.fallbackMu.Lock()
+	defer nb.fallbackMu.Unlock()
+	_, ok := nb.fallback[nodeID]
+	return ok
+}
+
+// activePoets returns the poets used for new registrations of the identity.
+func (nb *NIPostBuilder) activePoets(nodeID types.NodeID) map[string]PoetService {
+	if nb.usesFallback(nodeID) {
+		return nb.fallbackPoets
+	}
+	return nb.poetProvers
+}
+
+// registrationPoets returns the poets the challenge of the identity is submitted to. Secondary poets
+// are left out unless the primary poets were unstable in PoetConfig.SecondaryAfter consecutive attempts.
+// The second return value is true if secondary poets were left out.
+func (nb *NIPostBuilder) registrationPoets(nodeID types.NodeID) (map[string]PoetService, bool) {
+	poets := nb.activePoets(nodeID)
+	nb.unstableMu.Lock()
+	attempts := nb.unstableAttempts[nodeID]
+	nb.unstableMu.Unlock()
+	if attempts >= max(nb.poetCfg.SecondaryAfter, 1) {
+		return poets, false
+	}
+	primary := make(map[string]PoetService, len(poets))
+	for address, client := range poets {
+		if nb.poetCfg.Settings(address, nb.cycleGapOf(address)).Tier != PoetTierSecondary {
+			primary[address] = client
+		}
+	}
+	if len(primary) == 0 || len(primary) == len(poets) {
+		return poets, false
+	}
+	return primary, true
+}
+
+// primaryUnstable records an attempt of the identity in which all primary poets were unstable.
+func (nb *NIPostBuilder) primaryUnstable(nodeID types.NodeID) {
+	nb.unstableMu.Lock()
+	defer nb.unstableMu.Unlock()
+	nb.unstableAttempts[nodeID]++
+	if nb.unstableAttempts[nodeID] >= max(nb.poetCfg.SecondaryAfter, 1) {
+		nb.logger.Warn("primary poets are unstable, submitting to secondary poets",
+			zap.Int("attempts", nb.unstableAttempts[nodeID]),
+			log.ZShortStringer("smesherID", nodeID),
+		)
+	}
+}
+
+// cycleGap returns the cycle gap of the poets used for new registrations of the identity.
+func (nb *NIPostBuilder) cycleGap(nodeID types.NodeID) time.Duration {
+	if nb.usesFallback(nodeID) {
+		return nb.poetCfg.FallbackCycleGap
+	}
+	return nb.poetCfg.CycleGap
+}
+
+// cycleGapOf returns the cycle gap of the poet with the given address.
+func (nb *NIPostBuilder) cycleGapOf(address string) time.Duration {
+	if _, ok := nb.fallbackPoets[address]; ok {
+		return nb.poetCfg.FallbackCycleGap
+	}
+	return nb.poetCfg.CycleGap
+}
+
+// PoetStatus describes a poet used to build NIPoSTs and the settings in effect for it.
+type PoetStatus struct {
+	Address  string
+	Fallback bool
+	CycleGap time.Duration
+	PoetSettings
+}
+
+// Poets returns the primary poets followed by the fallback poets, each ordered by address.
+func (nb *NIPostBuilder) Poets() []PoetStatus {
+	rst := make([]PoetStatus, 0, len(nb.poetProvers)+len(nb.fallbackPoets))
+	for _, fallback := range []bool{false, true} {
+		poets := nb.poetProvers
+		if fallback {
+			poets = nb.fallbackPoets
+		}
+		addresses := maps.Keys(poets)
+		slices.Sort(addresses)
+		for _, address := range addresses {
+			cycleGap := nb.cycleGapOf(address)
+			rst = append(rst, PoetStatus{
+				Address:      address,
+				Fallback:     fallback,
+				CycleGap:     cycleGap,
+				PoetSettings: nb.poetCfg.Settings(address, cycleGap),
+			})
+		}
+	}
+	return rst
+}
+
+// Preflight runs the checks of a submission of the identity to every poet, primary and fallback,
+// without registering. The results are ordered like the poets returned by Poets.
+func (nb *NIPostBuilder) Preflight(ctx context.Context, nodeID types.NodeID) []PoetPreflight {
+	roundStart := nb.nextPoetRoundStart()
+	poets := nb.Poets()
+	rst := make([]PoetPreflight, len(poets))
+	var wg sync.WaitGroup
+	for i, poet := range poets {
+		client, _ := nb.poetClient(poet.Address)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.Preflight(ctx, nodeID)
+			if result == nil {
+				result = &PoetPreflight{Address: poet.Address}
+			}
+			result.Fallback = poet.Fallback
+			result.Err = err
+			result.RoundStart = roundStart
+			rst[i] = *result
+		}()
+	}
+	wg.Wait()
+	return rst
+}
+
+// nextPoetRoundStart returns the start of the next poet round.
+func (nb *NIPostBuilder) nextPoetRoundStart() time.Time {
+	epoch := nb.layerClock.CurrentLayer().GetEpoch()
+	start := nb.layerClock.LayerToTime(epoch.FirstLayer()).Add(nb.poetCfg.PhaseShift)
+	if !start.After(nb.clock.Now()) {
+		start = nb.layerClock.LayerToTime((epoch + 1).FirstLayer()).Add(nb.poetCfg.PhaseShift)
+	}
+	return start
+}
+
+// poetClient returns the primary or fallback poet with the given address.
+func (nb *NIPostBuilder) poetClient(address string) (PoetService, bool) {
+	if client, ok := nb.poetProvers[address]; ok {
+		return client, true
+	}
+	client, ok := nb.fallbackPoets[address]
+	return client, ok
+}
+
+// checkPostDuration compares the duration of PoST generation with the cycle gap, that is the time
+// available to generate a PoST after the poet round ended and still make it in time for the next round.
+// If the PoST took longer than the cycle gap and fallback poets with a longer cycle gap are configured,
+// new registrations of the identity are sent to the fallback poets, until its PoST fits into the cycle
+// gap of the primary poets again.
+func (nb *NIPostBuilder) checkPostDuration(nodeID types.NodeID, duration time.Duration) {
+	if nb.fitsPrimary(duration) && nb.leaveFallback(nodeID) {
+		nb.logger.Info("PoST generation fits into the cycle gap of primary poets again, switching back to them",
+			log.ZShortStringer("smesherID", nodeID),
+			zap.Duration("post_duration", duration),
+			zap.Duration("cycle_gap", nb.poetCfg.CycleGap),
+		)
+	}
+	cycleGap := nb.cycleGap(nodeID)
+	margin := cycleGap - duration
+	metrics.PostMargin.Set(margin.Seconds())
+	if margin >= time.Duration(float64(cycleGap)*postMarginWarning) {
+		return
+	}
+	fields := []zap.Field{
+		log.ZShortStringer("smesherID", nodeID),
+		zap.Duration("post_duration", duration),
+		zap.Duration("cycle_gap", cycleGap),
+		zap.Duration("margin", margin),
+	}
+	events.ReportPostTooSlow(events.EventPostTooSlow{
+		Smesher:  nodeID,
+		Duration: duration,
+		CycleGap: cycleGap,
+		Fallback: nb.usesFallback(nodeID),
+	})
+	if margin >= 0 {
+		nb.logger.Warn("PoST generation barely fits into the poet cycle gap, "+
+			"consider using faster hardware or a poet with a longer cycle gap", fields...)
+		return
+	}
+	nb.logger.Error("PoST generation took longer than the poet cycle gap, "+
+		"the node will miss poet rounds unless it uses faster hardware or a poet with a longer cycle gap", fields...)
+	if len(nb.fallbackPoets) == 0 || nb.poetCfg.FallbackCycleGap <= cycleGap {
+		return
+	}
+	nb.fallbackMu.Lock()
+	defer nb.fallbackMu.Unlock()
+	if _, ok := nb.fallback[nodeID]; !ok {
+		nb.fallback[nodeID] = struct{}{}
+		nb.logger.Warn("switching to fallback poets",
+			log.ZShortStringer("smesherID", nodeID),
+			zap.Strings("poets", maps.Keys(nb.fallbackPoets)),
+			zap.Duration("cycle_gap", nb.poetCfg.FallbackCycleGap),
+		)
+	}
+}
+
+// fitsPrimary returns true if a PoST generated in the duration fits into the cycle gap of the primary
+// poets with the warning margin, so that an identity that switched to the fallback poets can switch back.
+func (nb *NIPostBuilder) fitsPrimary(duration time.Duration) bool {
+	margin := nb.poetCfg.CycleGap - duration
+	return margin >= time.Duration(float64(nb.poetCfg.CycleGap)*postMarginWarning)
+}
+
+// leaveFallback returns true if the identity used the fallback poets.
+func (nb *NIPostBuilder) leaveFallback(nodeID types.NodeID) bool {
+	nb.fallbackMu.Lock()
+	defer nb.fallbackMu.Unlock()
+	_, ok := nb.fallback[nodeID]
+	delete(nb.fallback, nodeID)
+	return ok
+}
+
 // withConditionalTimeout returns a context.WithTimeout if the timeout is greater than 0, otherwise it returns
 // the original context.
 func withConditionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
@@ -409,13 +717,18 @@ func (nb *NIPostBuilder) submitPoetChallenges(
 
 	existingRegistrationsMap := make(map[string]nipost.PoETRegistration)
 	var missingRegistrations []PoetService
-	for addr, poet := range nb.poetProvers {
-		if val, ok := registrationsMap[addr]; ok {
-			existingRegistrationsMap[addr] = val
-		} else {
+	poets, primaryOnly := nb.registrationPoets(nodeID)
+	for addr, poet := range poets {
+		if _, ok := registrationsMap[addr]; !ok {
 			missingRegistrations = append(missingRegistrations, poet)
 		}
 	}
+	for addr, reg := range registrationsMap {
+		// registrations made before switching to the fallback poets are still valid
+		if _, ok := nb.poetClient(addr); ok {
+			existingRegistrationsMap[addr] = reg
+		}
+	}
 
 	misconfiguredRegistrations := make(map[string]struct{})
 	for addr := range registrationsMap {
@@ -433,11 +746,11 @@ func (nb *NIPostBuilder) submitPoetChallenges(
 	}
 
 	existingRegistrations := maps.Values(existingRegistrationsMap)
-	if len(missingRegistrations) == 0 {
+	if len(missingRegistrations) == 0 || nb.enoughRegistrations(existingRegistrations) {
 		return existingRegistrations, nil
 	}
 
-	now := time.Now()
+	now := nb.clock.Now()
 
 	if curPoetRoundStartDeadline.Before(now) {
 		switch {
@@ -453,7 +766,7 @@ func (nb *NIPostBuilder) submitPoetChallenges(
 			// no existing registration for given poets set
 			return nil, &PoetRegistrationMismatchError{
 				registrations:   maps.Keys(registrationsMap),
-				configuredPoets: maps.Keys(nb.poetProvers),
+				configuredPoets: maps.Keys(nb.activePoets(nodeID)),
 			}
 		default:
 			return existingRegistrations, nil
@@ -464,10 +777,11 @@ func (nb *NIPostBuilder) submitPoetChallenges(
 	signature := signer.Sign(signing.POET, challenge)
 	prefix := bytes.Join([][]byte{signer.Prefix(), {byte(signing.POET)}}, nil)
 
-	submitCtx, cancel := context.WithDeadline(ctx, curPoetRoundStartDeadline)
+	submitCtx, cancel := context.WithTimeout(ctx, curPoetRoundStartDeadline.Sub(now))
 	defer cancel()
 
 	eg, ctx := errgroup.WithContext(submitCtx)
+	var unsupported, enough atomic.Bool
 	submittedRegistrationsChan := make(chan nipost.PoETRegistration, len(missingRegistrations))
 
 	for _, client := range missingRegistrations {
@@ -477,35 +791,77 @@ func (nb *NIPostBuilder) submitPoetChallenges(
 				poetProofDeadline,
 				client, prefix, challenge, signature,
 			)
-			if err != nil {
+			switch {
+			case err == nil:
+				submittedRegistrationsChan <- registration
+			case enough.Load() && errors.Is(err, context.Canceled):
+				nb.logger.Debug("canceled poet submission, enough registrations",
+					zap.String("poet", client.Address()),
+					log.ZShortStringer("smesherID", nodeID),
+				)
+			default:
 				nb.logger.Warn("failed to submit challenge to poet",
 					zap.Error(err),
 					log.ZShortStringer("smesherID", nodeID),
 				)
-			} else {
-				submittedRegistrationsChan <- registration
+				if errors.Is(err, ErrPoetVersionUnsupported) {
+					unsupported.Store(true)
+				}
 			}
 			return nil
 		})
 	}
 
-	eg.Wait()
-	close(submittedRegistrationsChan)
+	go func() {
+		eg.Wait()
+		close(submittedRegistrationsChan)
+	}()
 
 	for registration := range submittedRegistrationsChan {
 		existingRegistrations = append(existingRegistrations, registration)
+		if !enough.Load() && nb.enoughRegistrations(existingRegistrations) {
+			nb.logger.Info("not waiting for remaining poet submissions",
+				zap.Int("registrations", len(existingRegistrations)),
+				log.ZShortStringer("smesherID", nodeID),
+			)
+			enough.Store(true)
+			cancel()
+		}
 	}
 
 	if len(existingRegistrations) == 0 {
-		if curPoetRoundStartDeadline.Before(time.Now()) {
+		if curPoetRoundStartDeadline.Before(nb.clock.Now()) {
 			return nil, ErrATXChallengeExpired
 		}
+		if unsupported.Load() {
+			return nil, fmt.Errorf("failed to submit challenge to any PoET: %w", ErrPoetVersionUnsupported)
+		}
+		if primaryOnly {
+			nb.primaryUnstable(nodeID)
+		}
 		return nil, &PoetSvcUnstableError{msg: "failed to submit challenge to any PoET", source: ctx.Err()}
 	}
 
+	nb.unstableMu.Lock()
+	delete(nb.unstableAttempts, nodeID)
+	nb.unstableMu.Unlock()
 	return existingRegistrations, nil
 }
 
+// enoughRegistrations returns true if, according to PoetConfig.SubmitQuorum and PoetConfig.PreferredPoets,
+// the registrations are enough to stop waiting for the remaining poet submissions.
+func (nb *NIPostBuilder) enoughRegistrations(registrations []nipost.PoETRegistration) bool {
+	if nb.poetCfg.SubmitQuorum > 0 && len(registrations) >= nb.poetCfg.SubmitQuorum {
+		return true
+	}
+	for _, reg := range registrations {
+		if slices.Contains(nb.poetCfg.PreferredPoets, reg.Address) {
+			return true
+		}
+	}
+	return false
+}
+
 // membersContainChallenge verifies that the challenge is included in proof's members.
 func membersContainChallenge(members []types.Hash32, challenge types.Hash32) (uint64, error) {
 	for id, member := range members {
@@ -513,21 +869,28 @@ func membersContainChallenge(members []types.Hash32, challenge types.Hash32) (ui
 			return uint64(id), nil
 		}
 	}
-	return 0, errors.New("challenge is not a member of the proof")
+	return 0, errNotMember
 }
 
+// getBestProof fetches the proofs of the registrations concurrently and selects the one with the most leaves,
+// among proofs with the same leaf count the one of the poet with the highest weight.
+// It stops waiting for the remaining proofs once PoetConfig.ProofQuorum proofs are received.
 func (nb *NIPostBuilder) getBestProof(
 	ctx context.Context,
 	nodeID types.NodeID,
 	challenge types.Hash32,
 	registrations []nipost.PoETRegistration,
 ) (types.PoetProofRef, *types.MerkleProof, error) {
-	type poetProof struct {
-		poet       *types.PoetProof
-		membership *types.MerkleProof
+	// weightedProof is a proof with the weight of the poet it was received from.
+	type weightedProof struct {
+		*nipost.PoETRegistrationProof
+		weight int
 	}
-	proofs := make(chan *poetProof, len(registrations))
+	proofs := make(chan weightedProof, len(registrations))
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var enough atomic.Bool
 	var eg errgroup.Group
 	for _, r := range registrations {
 		logger := nb.logger.With(
@@ -537,75 +900,146 @@ func (nb *NIPostBuilder) getBestProof(
 			zap.String("round", r.RoundID),
 		)
 
-		client, ok := nb.poetProvers[r.Address]
+		settings := nb.poetCfg.Settings(r.Address, nb.cycleGapOf(r.Address))
+		if r.Proof != nil {
+			// the proof was fetched before restart, no need to wait for it again
+			logger.Info("using previously fetched poet proof", zap.Bool("member", r.Proof.Membership != nil))
+			if r.Proof.Membership != nil {
+				proofs <- weightedProof{r.Proof, settings.Weight}
+			}
+			continue
+		}
+
+		client, ok := nb.poetClient(r.Address)
 		if !ok {
 			logger.Warn("poet client not found")
 			continue
 		}
 
 		round := r.RoundID
-		waitDeadline := proofDeadline(r.RoundEnd, nb.poetCfg.CycleGap)
+		address := r.Address
+		waitDeadline := proofDeadline(r.RoundEnd, settings)
 		eg.Go(func() error {
-			logger.Info("waiting until poet round end", zap.Duration("wait time", time.Until(waitDeadline)))
+			wait := waitDeadline.Sub(nb.clock.Now())
+			logger.Info("waiting until poet round end", zap.Duration("wait time", wait))
 			select {
 			case <-ctx.Done():
+				if enough.Load() {
+					return nil
+				}
 				return fmt.Errorf("waiting to query proof: %w", ctx.Err())
-			case <-time.After(time.Until(waitDeadline)):
+			case <-nb.clock.After(wait):
 			}
 
-			proof, members, err := client.Proof(ctx, round)
-			if err != nil {
+			proof, members, err := nb.fetchProof(ctx, client, round, settings, logger)
+			switch {
+			case err != nil && enough.Load():
+				logger.Debug("canceled fetching poet proof, enough proofs")
+				return nil
+			case err != nil:
 				logger.Warn("failed to get proof from poet", zap.Error(err))
 				return nil
 			}
+			ref, err := proof.Ref()
+			if err != nil {
+				logger.Warn("failed to compute proof ref", zap.Error(err))
+				return nil
+			}
+			fetched := &nipost.PoETRegistrationProof{Ref: ref, LeafCount: proof.LeafCount}
 
 			membership, err := constructMerkleProof(challenge, members)
 			if err != nil {
 				logger.Warn("failed to construct merkle proof", zap.Error(err))
-				return nil
+				if !errors.Is(err, errNotMember) {
+					return nil
+				}
 			}
-
-			proofs <- &poetProof{
-				poet:       proof,
-				membership: membership,
+			fetched.Membership = membership
+			// persist the progress, so that the proof is not fetched again after restart
+			if err := nipost.SetPoetRegistrationProof(nb.localDB, nodeID, address, *fetched); err != nil {
+				logger.Warn("cannot persist poet proof progress", zap.Error(err))
+			}
+			if membership != nil {
+				proofs <- weightedProof{fetched, settings.Weight}
 			}
 			return nil
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		return types.PoetProofRef{}, nil, fmt.Errorf("querying for proofs: %w", err)
-	}
-	close(proofs)
-
-	var bestProof *poetProof
+	var waitErr error
+	go func() {
+		waitErr = eg.Wait()
+		close(proofs)
+	}()
+
+	var bestProof *weightedProof
+	received := 0
 	for proof := range proofs {
 		nb.logger.Info(
 			"got poet proof",
-			zap.Uint64("leaf count", proof.poet.LeafCount),
+			zap.Uint64("leaf count", proof.LeafCount),
 			log.ZShortStringer("smesherID", nodeID),
 		)
-		if bestProof == nil || bestProof.poet.LeafCount < proof.poet.LeafCount {
-			bestProof = proof
+		switch {
+		case bestProof == nil, bestProof.LeafCount < proof.LeafCount:
+			bestProof = &proof
+		case bestProof.LeafCount == proof.LeafCount && bestProof.weight < proof.weight:
+			bestProof = &proof
 		}
+		received++
+		if !enough.Load() && nb.poetCfg.ProofQuorum > 0 && received >= nb.poetCfg.ProofQuorum {
+			nb.logger.Info("not waiting for remaining poet proofs",
+				zap.Int("proofs", received),
+				log.ZShortStringer("smesherID", nodeID),
+			)
+			enough.Store(true)
+			cancel()
+		}
+	}
+	if waitErr != nil {
+		return types.PoetProofRef{}, nil, fmt.Errorf("querying for proofs: %w", waitErr)
 	}
 
 	if bestProof != nil {
-		ref, err := bestProof.poet.Ref()
-		if err != nil {
-			return types.PoetProofRef{}, nil, err
-		}
 		nb.logger.Info(
 			"selected the best proof",
-			zap.Uint64("leafCount", bestProof.poet.LeafCount),
-			zap.Binary("ref", ref[:]),
+			zap.Uint64("leafCount", bestProof.LeafCount),
+			zap.Binary("ref", bestProof.Ref[:]),
 			log.ZShortStringer("smesherID", nodeID),
 		)
-		return ref, bestProof.membership, nil
+		return bestProof.Ref, bestProof.Membership, nil
 	}
 
 	return types.PoetProofRef{}, nil, ErrPoetProofNotReceived
 }
 
+// fetchProof queries the proof of the round. Failed queries are retried until the proof timeout
+// of the poet expires, without a timeout a single query is made.
+func (nb *NIPostBuilder) fetchProof(
+	ctx context.Context,
+	client PoetService,
+	round string,
+	settings PoetSettings,
+	logger *zap.Logger,
+) (*types.PoetProof, []types.Hash32, error) {
+	if settings.ProofTimeout == 0 {
+		return client.Proof(ctx, round)
+	}
+	ctx, cancel := context.WithTimeout(ctx, settings.ProofTimeout)
+	defer cancel()
+	for {
+		proof, members, err := client.Proof(ctx, round)
+		if err == nil || ctx.Err() != nil {
+			return proof, members, err
+		}
+		logger.Debug("failed to get proof from poet, retrying", zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-nb.clock.After(settings.RequestRetryDelay):
+		}
+	}
+}
+
 func constructMerkleProof(challenge types.Hash32, members []types.Hash32) (*types.MerkleProof, error) {
 	// We are interested only in proofs that we are members of
 	id, err := membersContainChallenge(members, challenge)
@@ -642,9 +1076,7 @@ func randomDurationInRange(min, max time.Duration) time.Duration {
 
 // Calculate the time to wait before querying for the proof
 // We add a jitter to avoid all nodes querying for the proof at the same time.
-func proofDeadline(roundEnd time.Time, cycleGap time.Duration) (waitTime time.Time) {
-	minJitter := time.Duration(float64(cycleGap) * minPoetGetProofJitter / 100.0)
-	maxJitter := time.Duration(float64(cycleGap) * maxPoetGetProofJitter / 100.0)
-	jitter := randomDurationInRange(minJitter, maxJitter)
+func proofDeadline(roundEnd time.Time, settings PoetSettings) (waitTime time.Time) {
+	jitter := randomDurationInRange(settings.MinProofJitter, settings.MaxProofJitter)
 	return roundEnd.Add(jitter)
 }
@@ -3,9 +3,12 @@ package activation
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/jonboulle/clockwork"
+	poetShared "github.com/spacemeshos/poet/shared"
 	"github.com/spacemeshos/post/shared"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -414,6 +417,83 @@ func Test_NIPostBuilder_WithMocks(t *testing.T) {
 	require.NotNil(t, nipost)
 }
 
+func TestNIPostBuilder_SlowPostSwitchesToFallbackPoets(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	primary := defaultPoetServiceMock(t, ctrl, "http://primary")
+	fallback := defaultPoetServiceMock(t, ctrl, "http://fallback")
+	cfg := PoetConfig{
+		CycleGap:         time.Hour,
+		FallbackCycleGap: 3 * time.Hour,
+	}
+
+	nb, err := NewNIPostBuilder(
+		localsql.InMemory(),
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		cfg,
+		defaultLayerClockMock(ctrl),
+		nil,
+		WithPoetServices(primary),
+		WithFallbackPoetServices(fallback),
+	)
+	require.NoError(t, err)
+	nodeID := types.RandomNodeID()
+	other := types.RandomNodeID()
+
+	nb.checkPostDuration(nodeID, 55*time.Minute)
+	require.Equal(t, cfg.CycleGap, nb.cycleGap(nodeID))
+	require.Contains(t, nb.activePoets(nodeID), primary.Address())
+
+	nb.checkPostDuration(nodeID, 90*time.Minute)
+	require.Equal(t, cfg.FallbackCycleGap, nb.cycleGap(nodeID))
+	require.Contains(t, nb.activePoets(nodeID), fallback.Address())
+	require.NotContains(t, nb.activePoets(nodeID), primary.Address())
+
+	// other identities keep using the primary poets
+	require.Equal(t, cfg.CycleGap, nb.cycleGap(other))
+	require.Contains(t, nb.activePoets(other), primary.Address())
+
+	// registrations at the primary poet are still served
+	client, ok := nb.poetClient(primary.Address())
+	require.True(t, ok)
+	require.Equal(t, primary, client)
+	require.Equal(t, cfg.CycleGap, nb.cycleGapOf(primary.Address()))
+	require.Equal(t, cfg.FallbackCycleGap, nb.cycleGapOf(fallback.Address()))
+
+	// PoST that barely fits into the primary cycle gap doesn't switch back
+	nb.checkPostDuration(nodeID, 55*time.Minute)
+	require.Equal(t, cfg.FallbackCycleGap, nb.cycleGap(nodeID))
+
+	// but switches back once it fits with the margin
+	nb.checkPostDuration(nodeID, 30*time.Minute)
+	require.Equal(t, cfg.CycleGap, nb.cycleGap(nodeID))
+	require.Contains(t, nb.activePoets(nodeID), primary.Address())
+}
+
+func TestPoetConfig_Fallback(t *testing.T) {
+	cfg := PoetConfig{CycleGap: time.Hour, FallbackCycleGap: 3 * time.Hour, RequestTimeout: time.Minute}
+	fallback := cfg.Fallback()
+	require.Equal(t, 3*time.Hour, fallback.CycleGap)
+	require.Equal(t, cfg.RequestTimeout, fallback.RequestTimeout)
+
+	cfg.FallbackCycleGap = 0
+	require.Equal(t, time.Hour, cfg.Fallback().CycleGap)
+}
+
+func TestPoetConfig_Validate(t *testing.T) {
+	cfg := PoetConfig{LatePublishWindow: time.Hour}
+	require.NoError(t, cfg.Validate(2*time.Hour))
+	cfg.LatePublishWindow = 0
+	require.NoError(t, cfg.Validate(2*time.Hour))
+
+	cfg.LatePublishWindow = -time.Second
+	require.ErrorContains(t, cfg.Validate(2*time.Hour), "must not be negative")
+	cfg.LatePublishWindow = 2 * time.Hour
+	require.ErrorContains(t, cfg.Validate(2*time.Hour), "must be shorter than the epoch")
+}
+
 func TestPostSetup(t *testing.T) {
 	challenge := types.RandomHash()
 	sig, err := signing.NewEdSigner()
@@ -763,6 +843,12 @@ func TestNIPSTBuilder_PoetUnstable(t *testing.T) {
 		)
 		require.ErrorIs(t, err, ErrATXChallengeExpired)
 		require.Nil(t, nipst)
+		nipostErr := &NIPostError{}
+		require.ErrorAs(t, err, &nipostErr)
+		require.Equal(t, NIPostPhaseSubmit, nipostErr.Phase)
+		require.Equal(t, postGenesisEpoch+2, nipostErr.PublishEpoch)
+		require.Equal(t, "http://localhost:9999", nipostErr.Poet)
+		require.False(t, nipostErr.Retryable)
 	})
 	t.Run("GetProof fails", func(t *testing.T) {
 		t.Parallel()
@@ -787,6 +873,13 @@ func TestNIPSTBuilder_PoetUnstable(t *testing.T) {
 			&types.NIPostChallenge{PublishEpoch: postGenesisEpoch + 2})
 		require.ErrorIs(t, err, ErrPoetProofNotReceived)
 		require.Nil(t, nipst)
+		poetErr := &PoetSvcUnstableError{}
+		require.ErrorAs(t, err, &poetErr)
+		nipostErr := &NIPostError{}
+		require.ErrorAs(t, err, &nipostErr)
+		require.Equal(t, NIPostPhasePoetProof, nipostErr.Phase)
+		require.Equal(t, "http://localhost:9999", nipostErr.Poet)
+		require.True(t, nipostErr.Retryable)
 	})
 	t.Run("Challenge is not included in proof members", func(t *testing.T) {
 		t.Parallel()
@@ -1112,6 +1205,123 @@ func TestNIPoSTBuilder_PoETConfigChange(t *testing.T) {
 		})
 }
 
+// TestNIPoSTBuilder_SubmitShortCircuit checks that the builder stops waiting for poet submissions
+// once the registrations satisfy the configured policy.
+func TestNIPoSTBuilder_SubmitShortCircuit(t *testing.T) {
+	t.Parallel()
+
+	sig, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	challengeHash := types.RandomHash()
+
+	const (
+		fastPoetAddr = "http://localhost:9999"
+		slowPoetAddr = "http://localhost:9988"
+	)
+
+	newPoets := func(ctrl *gomock.Controller) []PoetService {
+		fast := NewMockPoetService(ctrl)
+		fast.EXPECT().
+			Submit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&types.PoetRound{ID: "1"}, nil)
+		fast.EXPECT().Address().Return(fastPoetAddr).AnyTimes()
+
+		slow := NewMockPoetService(ctrl)
+		slow.EXPECT().
+			Submit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(
+				ctx context.Context,
+				_ time.Time,
+				_, _ []byte,
+				_ types.EdSignature,
+				_ types.NodeID,
+			) (*types.PoetRound, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+		slow.EXPECT().Address().Return(slowPoetAddr).AnyTimes()
+		return []PoetService{fast, slow}
+	}
+
+	for _, tc := range []struct {
+		desc string
+		cfg  PoetConfig
+	}{
+		{desc: "quorum reached", cfg: PoetConfig{SubmitQuorum: 1}},
+		{desc: "preferred poet registered", cfg: PoetConfig{PreferredPoets: []string{fastPoetAddr}}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			db := localsql.InMemory()
+			ctrl := gomock.NewController(t)
+
+			nb, err := NewNIPostBuilder(
+				db,
+				nil,
+				zaptest.NewLogger(t),
+				tc.cfg,
+				nil,
+				nil,
+				WithPoetServices(newPoets(ctrl)...),
+			)
+			require.NoError(t, err)
+
+			// the slow poet blocks until the round starts, unless the builder stops waiting for it
+			registrations, err := nb.submitPoetChallenges(
+				context.Background(),
+				sig,
+				time.Now().Add(time.Hour),
+				time.Now().Add(time.Hour),
+				challengeHash.Bytes(),
+			)
+			require.NoError(t, err)
+			require.Len(t, registrations, 1)
+			require.Equal(t, fastPoetAddr, registrations[0].Address)
+		})
+	}
+
+	t.Run("existing registrations satisfy policy", func(t *testing.T) {
+		t.Parallel()
+		db := localsql.InMemory()
+		ctrl := gomock.NewController(t)
+
+		fast := NewMockPoetService(ctrl)
+		fast.EXPECT().Address().Return(fastPoetAddr).AnyTimes()
+		slow := NewMockPoetService(ctrl)
+		slow.EXPECT().Address().Return(slowPoetAddr).AnyTimes()
+
+		err := nipost.AddPoetRegistration(db, sig.NodeID(), nipost.PoETRegistration{
+			ChallengeHash: challengeHash,
+			Address:       fastPoetAddr,
+			RoundID:       "1",
+			RoundEnd:      time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		nb, err := NewNIPostBuilder(
+			db,
+			nil,
+			zaptest.NewLogger(t),
+			PoetConfig{SubmitQuorum: 1},
+			nil,
+			nil,
+			WithPoetServices(fast, slow),
+		)
+		require.NoError(t, err)
+
+		registrations, err := nb.submitPoetChallenges(
+			context.Background(),
+			sig,
+			time.Now().Add(time.Hour),
+			time.Now().Add(time.Hour),
+			challengeHash.Bytes(),
+		)
+		require.NoError(t, err)
+		require.Len(t, registrations, 1)
+		require.Equal(t, fastPoetAddr, registrations[0].Address)
+	})
+}
+
 // TestNIPoSTBuilder_StaleChallenge checks if
 // it properly detects that the challenge is stale and the poet round has already started.
 func TestNIPoSTBuilder_StaleChallenge(t *testing.T) {
@@ -1155,6 +1365,38 @@ func TestNIPoSTBuilder_StaleChallenge(t *testing.T) {
 		require.ErrorContains(t, err, "poet round has already started")
 		require.Nil(t, nipost)
 	})
+	t.Run("poet round started according to wall clock", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mclock := NewMocklayerClock(ctrl)
+		poetProver := NewMockPoetService(ctrl)
+		poetProver.EXPECT().Address().Return(poetAddr).AnyTimes()
+		mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(
+			func(got types.LayerID) time.Time {
+				return genesis.Add(layerDuration * time.Duration(got))
+			},
+		).AnyTimes()
+		postService := NewMockpostService(ctrl)
+		// wall clock is fast-forwarded past the start of the poet round for the publish epoch
+		clock := clockwork.NewFakeClockAt(mclock.LayerToTime(types.EpochID(13).FirstLayer()))
+
+		nb, err := NewNIPostBuilder(
+			localsql.InMemory(),
+			postService,
+			zaptest.NewLogger(t),
+			PoetConfig{},
+			mclock,
+			nil,
+			WithPoetServices(poetProver),
+			NipostbuilderWithWallClock(clock),
+		)
+		require.NoError(t, err)
+
+		nipost, err := nb.BuildNIPost(context.Background(), sig, types.RandomHash(),
+			&types.NIPostChallenge{PublishEpoch: currLayer.GetEpoch() + 2})
+		require.ErrorIs(t, err, ErrATXChallengeExpired)
+		require.ErrorContains(t, err, "poet round has already started")
+		require.Nil(t, nipost)
+	})
 	t.Run("no response before deadline", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mclock := NewMocklayerClock(ctrl)
@@ -1243,6 +1485,55 @@ func TestNIPoSTBuilder_StaleChallenge(t *testing.T) {
 		require.ErrorContains(t, err, "deadline to publish ATX for pub epoch")
 		require.Nil(t, nipost)
 	})
+	t.Run("proof generation within late publish window", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mclock := NewMocklayerClock(ctrl)
+		poetProver := NewMockPoetService(ctrl)
+		poetProver.EXPECT().Address().Return(poetAddr).AnyTimes()
+		mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(
+			func(got types.LayerID) time.Time {
+				return genesis.Add(layerDuration * time.Duration(got))
+			}).AnyTimes()
+		postClient := NewMockPostClient(ctrl)
+		nonce := types.VRFPostIndex(1)
+		postClient.EXPECT().Proof(gomock.Any(), gomock.Any()).Return(&types.Post{}, &types.PostInfo{
+			Nonce: &nonce,
+		}, nil)
+		postService := NewMockpostService(ctrl)
+		postService.EXPECT().Client(sig.NodeID()).Return(postClient, nil)
+
+		db := localsql.InMemory()
+		nb, err := NewNIPostBuilder(
+			db,
+			postService,
+			zaptest.NewLogger(t),
+			PoetConfig{LatePublishWindow: time.Hour},
+			mclock,
+			nil,
+			WithPoetServices(poetProver),
+		)
+		require.NoError(t, err)
+
+		challenge := &types.NIPostChallenge{PublishEpoch: currLayer.GetEpoch() - 1}
+		challengeHash := wire.NIPostChallengeToWireV1(challenge).Hash()
+		err = nipost.AddChallenge(db, sig.NodeID(), challenge)
+		require.NoError(t, err)
+
+		err = nipost.AddPoetRegistration(db, sig.NodeID(), nipost.PoETRegistration{
+			ChallengeHash: challengeHash,
+			Address:       poetAddr,
+			RoundID:       "1",
+			RoundEnd:      time.Now().Add(10 * time.Second),
+		})
+		require.NoError(t, err)
+
+		err = nipost.UpdatePoetProofRef(db, sig.NodeID(), [32]byte{1, 2, 3}, &types.MerkleProof{})
+		require.NoError(t, err)
+
+		nipost, err := nb.BuildNIPost(context.Background(), sig, challengeHash, challenge)
+		require.NoError(t, err)
+		require.NotNil(t, nipost)
+	})
 }
 
 // Test if the NIPoSTBuilder continues after being interrupted just after
@@ -1319,6 +1610,295 @@ func TestNIPoSTBuilder_Continues_After_Interrupted(t *testing.T) {
 	require.Equal(t, ref[:], nipost.PostMetadata.Challenge)
 }
 
+func TestNIPoSTBuilder_ResumesFetchedProofs(t *testing.T) {
+	t.Parallel()
+
+	challenge := types.RandomHash()
+	nodeID := types.RandomNodeID()
+	proofMember := &types.PoetProof{LeafCount: 111}
+	proofNotMember := &types.PoetProof{LeafCount: 999}
+
+	ctrl := gomock.NewController(t)
+	poetMember := defaultPoetServiceMock(t, ctrl, "http://localhost:9999")
+	poetMember.EXPECT().Proof(gomock.Any(), "1").Return(proofMember, []types.Hash32{challenge}, nil)
+	poetNotMember := defaultPoetServiceMock(t, ctrl, "http://localhost:9998")
+	poetNotMember.EXPECT().Proof(gomock.Any(), "2").Return(proofNotMember, []types.Hash32{{1}, {2}}, nil)
+
+	db := localsql.InMemory()
+	nb, err := NewNIPostBuilder(
+		db,
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		PoetConfig{},
+		defaultLayerClockMock(ctrl),
+		nil,
+		WithPoetServices(poetMember, poetNotMember),
+	)
+	require.NoError(t, err)
+
+	for i, address := range []string{"http://localhost:9999", "http://localhost:9998"} {
+		require.NoError(t, nipost.AddPoetRegistration(db, nodeID, nipost.PoETRegistration{
+			ChallengeHash: challenge,
+			Address:       address,
+			RoundID:       strconv.Itoa(i + 1),
+			RoundEnd:      time.Now().Add(-time.Minute).Round(time.Second),
+		}))
+	}
+	registrations, err := nipost.PoetRegistrations(db, nodeID)
+	require.NoError(t, err)
+
+	ref, membership, err := nb.getBestProof(context.Background(), nodeID, challenge, registrations)
+	require.NoError(t, err)
+	expectedRef, err := proofMember.Ref()
+	require.NoError(t, err)
+	require.Equal(t, expectedRef, ref)
+
+	// the progress is persisted for both poets
+	registrations, err = nipost.PoetRegistrations(db, nodeID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 2)
+	for _, reg := range registrations {
+		require.NotNil(t, reg.Proof, reg.Address)
+		switch reg.Address {
+		case poetMember.Address():
+			require.Equal(t, expectedRef, reg.Proof.Ref)
+			require.NotNil(t, reg.Proof.Membership)
+			require.Equal(t, membership.LeafIndex, reg.Proof.Membership.LeafIndex)
+			require.ElementsMatch(t, membership.Nodes, reg.Proof.Membership.Nodes)
+		case poetNotMember.Address():
+			require.Equal(t, proofNotMember.LeafCount, reg.Proof.LeafCount)
+			require.Nil(t, reg.Proof.Membership)
+		}
+	}
+
+	// after restart the proofs are not fetched again
+	restarted, err := NewNIPostBuilder(
+		db,
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		PoetConfig{},
+		defaultLayerClockMock(ctrl),
+		nil,
+		WithPoetServices(poetMember, poetNotMember),
+	)
+	require.NoError(t, err)
+	got, gotMembership, err := restarted.getBestProof(context.Background(), nodeID, challenge, registrations)
+	require.NoError(t, err)
+	require.Equal(t, ref, got)
+	require.Equal(t, membership.LeafIndex, gotMembership.LeafIndex)
+	require.ElementsMatch(t, membership.Nodes, gotMembership.Nodes)
+}
+
+func TestNIPoSTBuilder_ProofQuorum(t *testing.T) {
+	t.Parallel()
+
+	challenge := types.RandomHash()
+	nodeID := types.RandomNodeID()
+	proof := &types.PoetProof{LeafCount: 111}
+
+	ctrl := gomock.NewController(t)
+	fast := defaultPoetServiceMock(t, ctrl, "http://localhost:9999")
+	fast.EXPECT().Proof(gomock.Any(), "1").Return(proof, []types.Hash32{challenge}, nil)
+	slow := defaultPoetServiceMock(t, ctrl, "http://localhost:9998")
+	slow.EXPECT().Proof(gomock.Any(), "2").DoAndReturn(
+		func(ctx context.Context, _ string) (*types.PoetProof, []types.Hash32, error) {
+			<-ctx.Done()
+			return nil, nil, ctx.Err()
+		})
+
+	db := localsql.InMemory()
+	nb, err := NewNIPostBuilder(
+		db,
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		PoetConfig{ProofQuorum: 1},
+		defaultLayerClockMock(ctrl),
+		nil,
+		WithPoetServices(fast, slow),
+	)
+	require.NoError(t, err)
+
+	for i, address := range []string{"http://localhost:9999", "http://localhost:9998"} {
+		require.NoError(t, nipost.AddPoetRegistration(db, nodeID, nipost.PoETRegistration{
+			ChallengeHash: challenge,
+			Address:       address,
+			RoundID:       strconv.Itoa(i + 1),
+			RoundEnd:      time.Now().Add(-time.Minute).Round(time.Second),
+		}))
+	}
+	registrations, err := nipost.PoetRegistrations(db, nodeID)
+	require.NoError(t, err)
+
+	// the slow poet is canceled after the proof of the fast one is received
+	ref, _, err := nb.getBestProof(context.Background(), nodeID, challenge, registrations)
+	require.NoError(t, err)
+	expectedRef, err := proof.Ref()
+	require.NoError(t, err)
+	require.Equal(t, expectedRef, ref)
+}
+
+func TestNIPoSTBuilder_ProofTimeout(t *testing.T) {
+	t.Parallel()
+
+	challenge := types.RandomHash()
+	nodeID := types.RandomNodeID()
+	proof := &types.PoetProof{LeafCount: 111}
+	const address = "http://localhost:9999"
+
+	ctrl := gomock.NewController(t)
+	poet := defaultPoetServiceMock(t, ctrl, address)
+	gomock.InOrder(
+		poet.EXPECT().Proof(gomock.Any(), "1").Return(nil, nil, errors.New("not ready")),
+		poet.EXPECT().Proof(gomock.Any(), "1").Return(proof, []types.Hash32{challenge}, nil),
+	)
+
+	db := localsql.InMemory()
+	cfg := PoetConfig{
+		RequestRetryDelay: time.Millisecond,
+		Overrides:         []PoetOverride{{Address: address, ProofTimeout: time.Minute}},
+	}
+	nb, err := NewNIPostBuilder(
+		db,
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		cfg,
+		defaultLayerClockMock(ctrl),
+		nil,
+		WithPoetServices(poet),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, nipost.AddPoetRegistration(db, nodeID, nipost.PoETRegistration{
+		ChallengeHash: challenge,
+		Address:       address,
+		RoundID:       "1",
+		RoundEnd:      time.Now().Add(-time.Minute).Round(time.Second),
+	}))
+	registrations, err := nipost.PoetRegistrations(db, nodeID)
+	require.NoError(t, err)
+
+	// the failed request is retried until the proof timeout
+	ref, _, err := nb.getBestProof(context.Background(), nodeID, challenge, registrations)
+	require.NoError(t, err)
+	expectedRef, err := proof.Ref()
+	require.NoError(t, err)
+	require.Equal(t, expectedRef, ref)
+}
+
+func TestNIPoSTBuilder_ProofWeight(t *testing.T) {
+	t.Parallel()
+
+	challenge := types.RandomHash()
+	nodeID := types.RandomNodeID()
+	light := &types.PoetProof{MerkleProof: poetShared.MerkleProof{Root: []byte{1}}, LeafCount: 111}
+	heavy := &types.PoetProof{MerkleProof: poetShared.MerkleProof{Root: []byte{2}}, LeafCount: 111}
+
+	ctrl := gomock.NewController(t)
+	poet1 := defaultPoetServiceMock(t, ctrl, "http://localhost:9999")
+	poet1.EXPECT().Proof(gomock.Any(), "1").Return(light, []types.Hash32{challenge}, nil)
+	poet2 := defaultPoetServiceMock(t, ctrl, "http://localhost:9998")
+	poet2.EXPECT().Proof(gomock.Any(), "2").Return(heavy, []types.Hash32{challenge}, nil)
+
+	db := localsql.InMemory()
+	cfg := PoetConfig{
+		Overrides: []PoetOverride{{Address: "http://localhost:9998", Weight: 10}},
+	}
+	nb, err := NewNIPostBuilder(
+		db,
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		cfg,
+		defaultLayerClockMock(ctrl),
+		nil,
+		WithPoetServices(poet1, poet2),
+	)
+	require.NoError(t, err)
+
+	for i, address := range []string{"http://localhost:9999", "http://localhost:9998"} {
+		require.NoError(t, nipost.AddPoetRegistration(db, nodeID, nipost.PoETRegistration{
+			ChallengeHash: challenge,
+			Address:       address,
+			RoundID:       strconv.Itoa(i + 1),
+			RoundEnd:      time.Now().Add(-time.Minute).Round(time.Second),
+		}))
+	}
+	registrations, err := nipost.PoetRegistrations(db, nodeID)
+	require.NoError(t, err)
+
+	// both proofs have the same leaf count, the one of the poet with the higher weight is selected
+	ref, _, err := nb.getBestProof(context.Background(), nodeID, challenge, registrations)
+	require.NoError(t, err)
+	expectedRef, err := heavy.Ref()
+	require.NoError(t, err)
+	require.Equal(t, expectedRef, ref)
+}
+
+func TestNIPoSTBuilder_SecondaryPoets(t *testing.T) {
+	t.Parallel()
+
+	challenge := types.RandomHash()
+	sig, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	const (
+		primaryAddr   = "http://localhost:9999"
+		secondaryAddr = "http://localhost:9998"
+	)
+
+	ctrl := gomock.NewController(t)
+	primary := NewMockPoetService(ctrl)
+	primary.EXPECT().Address().AnyTimes().Return(primaryAddr)
+	primary.EXPECT().
+		Submit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), sig.NodeID()).
+		Times(3).
+		Return(nil, errors.New("unavailable"))
+	secondary := NewMockPoetService(ctrl)
+	secondary.EXPECT().Address().AnyTimes().Return(secondaryAddr)
+	secondary.EXPECT().
+		Submit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), sig.NodeID()).
+		Return(&types.PoetRound{ID: "1"}, nil)
+
+	cfg := PoetConfig{
+		SecondaryAfter: 2,
+		Overrides:      []PoetOverride{{Address: secondaryAddr, Tier: PoetTierSecondary}},
+	}
+	nb, err := NewNIPostBuilder(
+		localsql.InMemory(),
+		nil,
+		zaptest.NewLogger(t),
+		cfg,
+		nil,
+		nil,
+		WithPoetServices(primary, secondary),
+	)
+	require.NoError(t, err)
+
+	submit := func() ([]nipost.PoETRegistration, error) {
+		return nb.submitPoetChallenges(
+			context.Background(),
+			sig,
+			time.Now().Add(time.Hour),
+			time.Now().Add(time.Hour),
+			challenge.Bytes(),
+		)
+	}
+	// the secondary poet isn't used until the primary poet was unstable in two attempts
+	for range 2 {
+		_, err := submit()
+		poetErr := &PoetSvcUnstableError{}
+		require.ErrorAs(t, err, &poetErr)
+	}
+	registrations, err := submit()
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	require.Equal(t, secondaryAddr, registrations[0].Address)
+
+	// a successful registration resets the attempts, the next challenge is submitted to the primary poet first
+	poets, primaryOnly := nb.registrationPoets(sig.NodeID())
+	require.True(t, primaryOnly)
+	require.Contains(t, poets, primaryAddr)
+	require.NotContains(t, poets, secondaryAddr)
+}
+
 func TestConstructingMerkleProof(t *testing.T) {
 	challenge := types.RandomHash()
 
@@ -1328,7 +1908,7 @@ func TestConstructingMerkleProof(t *testing.T) {
 	})
 	t.Run("not a member", func(t *testing.T) {
 		_, err := constructMerkleProof(challenge, []types.Hash32{{}, {}})
-		require.Error(t, err)
+		require.ErrorIs(t, err, errNotMember)
 	})
 
 	t.Run("is odd member", func(t *testing.T) {
@@ -1467,13 +2047,13 @@ func TestCalculatingGetProofWaitTime(t *testing.T) {
 	t.Parallel()
 	t.Run("past round end", func(t *testing.T) {
 		t.Parallel()
-		deadline := proofDeadline(time.Now().Add(-time.Hour), time.Hour*12)
+		deadline := proofDeadline(time.Now().Add(-time.Hour), PoetConfig{}.Settings("", time.Hour*12))
 		require.Less(t, time.Until(deadline), time.Duration(0))
 	})
 	t.Run("before round end", func(t *testing.T) {
 		t.Parallel()
 		cycleGap := 12 * time.Hour
-		deadline := proofDeadline(time.Now().Add(time.Hour), cycleGap)
+		deadline := proofDeadline(time.Now().Add(time.Hour), PoetConfig{}.Settings("", cycleGap))
 
 		require.Greater(t, time.Until(deadline), time.Hour+time.Duration(float64(cycleGap)*minPoetGetProofJitter/100))
 		require.LessOrEqual(
@@ -1548,3 +2128,121 @@ func TestNIPostBuilderProof_WithBadInitialPost(t *testing.T) {
 	_, _, err = nb.Proof(ctx, sig.NodeID(), challenge[:], &types.NIPostChallenge{InitialPost: &types.Post{}})
 	require.ErrorIs(t, err, ErrInvalidInitialPost)
 }
+
+func TestNIPostBuilder_Poets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := PoetConfig{
+		CycleGap:          time.Hour,
+		FallbackCycleGap:  3 * time.Hour,
+		RequestTimeout:    time.Minute,
+		RequestRetryDelay: time.Second,
+		MaxRequestRetries: 10,
+		Overrides: []PoetOverride{{
+			Address:           "http://slow",
+			Transport:         PoetTransportGRPC,
+			RequestTimeout:    5 * time.Minute,
+			MaxRequestRetries: 20,
+			ProofJitter:       time.Minute,
+			Tier:              PoetTierSecondary,
+			Weight:            2,
+		}},
+	}
+	nb, err := NewNIPostBuilder(
+		localsql.InMemory(),
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		cfg,
+		defaultLayerClockMock(ctrl),
+		nil,
+		WithPoetServices(
+			defaultPoetServiceMock(t, ctrl, "http://slow"),
+			defaultPoetServiceMock(t, ctrl, "http://fast"),
+		),
+		WithFallbackPoetServices(defaultPoetServiceMock(t, ctrl, "http://fallback")),
+	)
+	require.NoError(t, err)
+
+	defaults := func(cycleGap time.Duration) PoetSettings {
+		return PoetSettings{
+			Transport:         PoetTransportHTTP,
+			RequestTimeout:    time.Minute,
+			RequestRetryDelay: time.Second,
+			MaxRequestRetries: 10,
+			MinProofJitter:    time.Duration(float64(cycleGap) * minPoetGetProofJitter / 100),
+			MaxProofJitter:    time.Duration(float64(cycleGap) * maxPoetGetProofJitter / 100),
+			Tier:              PoetTierPrimary,
+		}
+	}
+	require.Equal(t, []PoetStatus{
+		{Address: "http://fast", CycleGap: time.Hour, PoetSettings: defaults(time.Hour)},
+		{
+			Address:  "http://slow",
+			CycleGap: time.Hour,
+			PoetSettings: PoetSettings{
+				Transport:         PoetTransportGRPC,
+				RequestTimeout:    5 * time.Minute,
+				RequestRetryDelay: time.Second,
+				MaxRequestRetries: 20,
+				MinProofJitter:    30 * time.Second,
+				MaxProofJitter:    time.Minute,
+				Tier:              PoetTierSecondary,
+				Weight:            2,
+			},
+		},
+		{Address: "http://fallback", Fallback: true, CycleGap: 3 * time.Hour, PoetSettings: defaults(3 * time.Hour)},
+	}, nb.Poets())
+
+	roundEnd := time.Now().Add(time.Hour)
+	deadline := proofDeadline(roundEnd, cfg.Settings("http://slow", cfg.CycleGap))
+	require.GreaterOrEqual(t, deadline.Sub(roundEnd), 30*time.Second)
+	require.LessOrEqual(t, deadline.Sub(roundEnd), time.Minute)
+}
+
+func TestNIPostBuilder_Preflight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	clock := clockwork.NewFakeClock()
+	epoch := types.EpochID(3)
+	mclock := NewMocklayerClock(ctrl)
+	mclock.EXPECT().CurrentLayer().Return(epoch.FirstLayer().Add(1))
+	mclock.EXPECT().LayerToTime(epoch.FirstLayer()).Return(clock.Now().Add(-time.Hour))
+	mclock.EXPECT().LayerToTime((epoch + 1).FirstLayer()).Return(clock.Now().Add(time.Hour))
+
+	nodeID := types.RandomNodeID()
+	primary := defaultPoetServiceMock(t, ctrl, "http://primary")
+	primary.EXPECT().Preflight(gomock.Any(), nodeID).
+		Return(&PoetPreflight{Address: "http://primary", Auth: PoetAuthPoW, PowDifficulty: 8}, nil)
+	fallback := defaultPoetServiceMock(t, ctrl, "http://fallback")
+	fallbackErr := errors.New("unreachable")
+	fallback.EXPECT().Preflight(gomock.Any(), nodeID).
+		Return(&PoetPreflight{Address: "http://fallback"}, fallbackErr)
+
+	nb, err := NewNIPostBuilder(
+		localsql.InMemory(),
+		NewMockpostService(ctrl),
+		zaptest.NewLogger(t),
+		PoetConfig{PhaseShift: 10 * time.Minute},
+		mclock,
+		nil,
+		WithPoetServices(primary),
+		WithFallbackPoetServices(fallback),
+		NipostbuilderWithWallClock(clock),
+	)
+	require.NoError(t, err)
+
+	// the round of the current epoch has started, registrations are for the next one
+	roundStart := clock.Now().Add(time.Hour + 10*time.Minute)
+	require.Equal(t, []PoetPreflight{
+		{
+			Address:       "http://primary",
+			Auth:          PoetAuthPoW,
+			PowDifficulty: 8,
+			RoundStart:    roundStart,
+		},
+		{
+			Address:    "http://fallback",
+			Fallback:   true,
+			Err:        fallbackErr,
+			RoundStart: roundStart,
+		},
+	}, nb.Preflight(context.Background(), nodeID))
+}
@@ -10,9 +10,11 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	lru "github.com/hashicorp/golang-lru/v2"
 	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
 	"github.com/spacemeshos/poet/shared"
 	"go.uber.org/zap"
@@ -35,8 +37,34 @@ var (
 	ErrCertificatesNotSupported = errors.New("poet doesn't support certificates")
 	ErrIncompatiblePhaseShift   = errors.New("fetched poet phase_shift is incompatible with configured phase_shift")
 	ErrCertifierNotConfigured   = errors.New("certifier service not configured")
+	ErrPoetVersionUnsupported   = errors.New("poet api version is not supported")
+
+	errNotFound = errors.New("not found")
 )
 
+// poetAPI is a version of the poet api schema supported by the client.
+type poetAPI struct {
+	version uint32
+	prefix  string
+}
+
+// path returns the path of the endpoint in this version of the api.
+func (a *poetAPI) path(endpoint string) string {
+	return a.prefix + endpoint
+}
+
+// poetAPIs are the versions of the poet api schema supported by the client, newest first.
+// Poets don't announce the version they serve, it is detected by probing the info endpoint
+// of every version, see HTTPPoetClient.Info. A new version is added here together with
+// the changes to the requests and responses that it requires.
+var poetAPIs = []*poetAPI{
+	{version: 1, prefix: "/v1"},
+}
+
+// probeKey marks the context of requests that probe whether an endpoint is served,
+// such requests are not retried if the poet responds with StatusNotFound.
+type probeKey struct{}
+
 type PoetPowParams struct {
 	Challenge  []byte
 	Difficulty uint
@@ -52,6 +80,32 @@ type PoetAuth struct {
 	*certifier.PoetCert
 }
 
+// PoetAuthMethod is how a submission to a poet is authorized.
+type PoetAuthMethod string
+
+const (
+	PoetAuthCertificate PoetAuthMethod = "certificate"
+	PoetAuthPoW         PoetAuthMethod = "pow"
+)
+
+// PoetPreflight is the result of a dry-run submission to a poet, see NIPostBuilder.Preflight.
+type PoetPreflight struct {
+	Address  string
+	Fallback bool
+	// Err is the failed check, nil if the node can register with the poet.
+	Err error
+	// Auth is how the submission would be authorized, empty if the check failed before.
+	Auth PoetAuthMethod
+	// CertExpiration is the expiration of the certificate, nil if it doesn't expire.
+	CertExpiration *time.Time
+	// CertErr is why no certificate was obtained from a certifier, the submission falls back to PoW then.
+	CertErr error
+	// PowDifficulty is the difficulty of the PoW if the submission is authorized with PoW.
+	PowDifficulty uint
+	// RoundStart is the start of the next poet round, registrations must be submitted before it.
+	RoundStart time.Time
+}
+
 type PoetClient interface {
 	Id() []byte
 	Address() string
@@ -76,10 +130,13 @@ type HTTPPoetClient struct {
 	client                *retryablehttp.Client
 	submitChallengeClient *retryablehttp.Client
 	logger                *zap.Logger
+
+	// api is the version of the api negotiated when querying poet info.
+	api atomic.Pointer[poetAPI]
 }
 
 func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
-	if resp != nil && resp.StatusCode == http.StatusNotFound {
+	if resp != nil && resp.StatusCode == http.StatusNotFound && ctx.Value(probeKey{}) == nil {
 		return true, nil
 	}
 	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
@@ -137,31 +194,30 @@ func customLinearJitterBackoff(min, max time.Duration, _ int, _ *http.Response)
 }
 
 // NewHTTPPoetClient returns new instance of HTTPPoetClient connecting to the specified url.
+// The retries are configured with the settings of the poet, see PoetConfig.Settings.
 func NewHTTPPoetClient(server types.PoetServer, cfg PoetConfig, opts ...PoetClientOpts) (*HTTPPoetClient, error) {
+	baseURL, err := parsePoetAddress(server.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := cfg.Settings(baseURL.String(), cfg.CycleGap)
 	client := &retryablehttp.Client{
-		RetryMax:     cfg.MaxRequestRetries,
-		RetryWaitMin: cfg.RequestRetryDelay,
-		RetryWaitMax: 2 * cfg.RequestRetryDelay,
+		RetryMax:     settings.MaxRequestRetries,
+		RetryWaitMin: settings.RequestRetryDelay,
+		RetryWaitMax: 2 * settings.RequestRetryDelay,
 		Backoff:      retryablehttp.LinearJitterBackoff,
 		CheckRetry:   checkRetry,
 	}
 
 	submitChallengeClient := &retryablehttp.Client{
 		RetryMax:     math.MaxInt,
-		RetryWaitMin: cfg.RequestRetryDelay,
-		RetryWaitMax: 2 * cfg.RequestRetryDelay,
+		RetryWaitMin: settings.RequestRetryDelay,
+		RetryWaitMax: 2 * settings.RequestRetryDelay,
 		Backoff:      customLinearJitterBackoff,
 		CheckRetry:   retryablehttp.DefaultRetryPolicy,
 	}
 
-	baseURL, err := url.Parse(server.Address)
-	if err != nil {
-		return nil, fmt.Errorf("parsing address: %w", err)
-	}
-	if baseURL.Scheme == "" {
-		baseURL.Scheme = "http"
-	}
-
 	poetClient := &HTTPPoetClient{
 		id:                    server.Pubkey.Bytes(),
 		baseURL:               baseURL,
@@ -170,6 +226,8 @@ func NewHTTPPoetClient(server types.PoetServer, cfg PoetConfig, opts ...PoetClie
 		logger:                zap.NewNop(),
 	}
 
+	poetClient.api.Store(poetAPIs[len(poetAPIs)-1])
+
 	for _, opt := range opts {
 		opt(poetClient)
 	}
@@ -196,14 +254,10 @@ func (c *HTTPPoetClient) Address() string {
 
 func (c *HTTPPoetClient) PowParams(ctx context.Context) (*PoetPowParams, error) {
 	resBody := rpcapi.PowParamsResponse{}
-	if err := c.req(ctx, http.MethodGet, "/v1/pow_params", nil, &resBody, c.client); err != nil {
+	if err := c.req(ctx, http.MethodGet, c.api.Load().path("/pow_params"), nil, &resBody, c.client); err != nil {
 		return nil, fmt.Errorf("querying PoW params: %w", err)
 	}
-
-	return &PoetPowParams{
-		Challenge:  resBody.GetPowParams().GetChallenge(),
-		Difficulty: uint(resBody.GetPowParams().GetDifficulty()),
-	}, nil
+	return powParamsFromResponse(&resBody), nil
 }
 
 // Submit registers a challenge in the proving service current open round.
@@ -215,7 +269,32 @@ func (c *HTTPPoetClient) Submit(
 	nodeID types.NodeID,
 	auth PoetAuth,
 ) (*types.PoetRound, error) {
-	request := rpcapi.SubmitRequest{
+	request := submitRequest(deadline, prefix, challenge, signature, nodeID, auth)
+	resBody := rpcapi.SubmitResponse{}
+	path := c.api.Load().path("/submit")
+	if err := c.req(ctx, http.MethodPost, path, request, &resBody, c.submitChallengeClient); err != nil {
+		return nil, fmt.Errorf("submitting challenge: %w", err)
+	}
+	return roundFromResponse(&resBody), nil
+}
+
+// The requests and responses are the same for all transports of the poet api.
+
+func powParamsFromResponse(resp *rpcapi.PowParamsResponse) *PoetPowParams {
+	return &PoetPowParams{
+		Challenge:  resp.GetPowParams().GetChallenge(),
+		Difficulty: uint(resp.GetPowParams().GetDifficulty()),
+	}
+}
+
+func submitRequest(
+	deadline time.Time,
+	prefix, challenge []byte,
+	signature types.EdSignature,
+	nodeID types.NodeID,
+	auth PoetAuth,
+) *rpcapi.SubmitRequest {
+	request := &rpcapi.SubmitRequest{
 		Prefix:    prefix,
 		Challenge: challenge,
 		Signature: signature.Bytes(),
@@ -235,55 +314,41 @@ func (c *HTTPPoetClient) Submit(
 			Signature: auth.PoetCert.Signature,
 		}
 	}
+	return request
+}
 
-	resBody := rpcapi.SubmitResponse{}
-	if err := c.req(ctx, http.MethodPost, "/v1/submit", &request, &resBody, c.submitChallengeClient); err != nil {
-		return nil, fmt.Errorf("submitting challenge: %w", err)
-	}
+func roundFromResponse(resp *rpcapi.SubmitResponse) *types.PoetRound {
 	roundEnd := time.Time{}
-	if resBody.RoundEnd != nil {
-		roundEnd = time.Now().Add(resBody.RoundEnd.AsDuration())
+	if resp.RoundEnd != nil {
+		roundEnd = time.Now().Add(resp.RoundEnd.AsDuration())
 	}
-
-	return &types.PoetRound{ID: resBody.RoundId, End: roundEnd}, nil
+	return &types.PoetRound{ID: resp.RoundId, End: roundEnd}
 }
 
-func (c *HTTPPoetClient) Info(ctx context.Context) (*types.PoetInfo, error) {
-	resBody := rpcapi.InfoResponse{}
-	if err := c.req(ctx, http.MethodGet, "/v1/info", nil, &resBody, c.client); err != nil {
-		return nil, fmt.Errorf("getting poet info: %w", err)
-	}
-
+func infoFromResponse(resp *rpcapi.InfoResponse) (*types.PoetInfo, error) {
 	var certifierInfo *types.CertifierInfo
-	if resBody.GetCertifier() != nil {
-		url, err := url.Parse(resBody.GetCertifier().Url)
+	if resp.GetCertifier() != nil {
+		url, err := url.Parse(resp.GetCertifier().Url)
 		if err != nil {
 			return nil, fmt.Errorf("parsing certifier address: %w", err)
 		}
 		certifierInfo = &types.CertifierInfo{
 			Url:    url,
-			Pubkey: resBody.GetCertifier().Pubkey,
+			Pubkey: resp.GetCertifier().Pubkey,
 		}
 	}
-
 	return &types.PoetInfo{
-		ServicePubkey: resBody.ServicePubkey,
-		PhaseShift:    resBody.PhaseShift.AsDuration(),
-		CycleGap:      resBody.CycleGap.AsDuration(),
+		ServicePubkey: resp.ServicePubkey,
+		PhaseShift:    resp.PhaseShift.AsDuration(),
+		CycleGap:      resp.CycleGap.AsDuration(),
 		Certifier:     certifierInfo,
 	}, nil
 }
 
-// Proof implements PoetProvingServiceClient.
-func (c *HTTPPoetClient) Proof(ctx context.Context, roundID string) (*types.PoetProofMessage, []types.Hash32, error) {
-	resBody := rpcapi.ProofResponse{}
-	if err := c.req(ctx, http.MethodGet, fmt.Sprintf("/v1/proofs/%s", roundID), nil, &resBody, c.client); err != nil {
-		return nil, nil, fmt.Errorf("getting proof: %w", err)
-	}
-
-	p := resBody.Proof.GetProof()
+func proofFromResponse(resp *rpcapi.ProofResponse, roundID string) (*types.PoetProofMessage, []types.Hash32, error) {
+	p := resp.Proof.GetProof()
 
-	pMembers := resBody.Proof.GetMembers()
+	pMembers := resp.Proof.GetMembers()
 	members := make([]types.Hash32, len(pMembers))
 	for i, m := range pMembers {
 		copy(members[i][:], m)
@@ -300,41 +365,93 @@ func (c *HTTPPoetClient) Proof(ctx context.Context, roundID string) (*types.Poet
 				ProvenLeaves: p.GetProvenLeaves(),
 				ProofNodes:   p.GetProofNodes(),
 			},
-			LeafCount: resBody.Proof.GetLeaves(),
+			LeafCount: resp.Proof.GetLeaves(),
 		},
-		PoetServiceID: resBody.Pubkey,
+		PoetServiceID: resp.Pubkey,
 		RoundID:       roundID,
 		Statement:     types.BytesToHash(statement),
 	}
 	return &proof, members, nil
 }
 
+// Info queries the poet info and detects the api version used by the following requests.
+// The info endpoint of every supported version is probed, newest first, and the first version
+// that the poet serves is used.
+func (c *HTTPPoetClient) Info(ctx context.Context) (*types.PoetInfo, error) {
+	probe := context.WithValue(ctx, probeKey{}, struct{}{})
+	for _, api := range poetAPIs {
+		resBody := rpcapi.InfoResponse{}
+		err := c.req(probe, http.MethodGet, api.path("/info"), nil, &resBody, c.client)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting poet info: %w", err)
+		}
+		if prev := c.api.Swap(api); prev.version != api.version {
+			c.logger.Info("detected poet api version",
+				zap.Stringer("url", c.baseURL),
+				zap.Uint32("version", api.version),
+			)
+		}
+		return infoFromResponse(&resBody)
+	}
+	return nil, fmt.Errorf("%w: poet serves none of the supported versions", ErrPoetVersionUnsupported)
+}
+
+// Proof implements PoetProvingServiceClient.
+func (c *HTTPPoetClient) Proof(ctx context.Context, roundID string) (*types.PoetProofMessage, []types.Hash32, error) {
+	resBody := rpcapi.ProofResponse{}
+	path := c.api.Load().path(fmt.Sprintf("/proofs/%s", roundID))
+	if err := c.req(ctx, http.MethodGet, path, nil, &resBody, c.client); err != nil {
+		return nil, nil, fmt.Errorf("getting proof: %w", err)
+	}
+	return proofFromResponse(&resBody, roundID)
+}
+
 func (c *HTTPPoetClient) req(
 	ctx context.Context,
 	method, path string,
 	reqBody, resBody proto.Message,
 	client *retryablehttp.Client,
 ) error {
+	data, err := c.do(ctx, method, path, reqBody, client)
+	if err != nil {
+		return err
+	}
+	if resBody != nil {
+		return unmarshalResponse(data, resBody)
+	}
+	return nil
+}
+
+// do sends the request and returns the body of a successful response.
+func (c *HTTPPoetClient) do(
+	ctx context.Context,
+	method, path string,
+	reqBody proto.Message,
+	client *retryablehttp.Client,
+) ([]byte, error) {
 	jsonReqBody, err := protojson.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("marshaling request body: %w", err)
+		return nil, fmt.Errorf("marshaling request body: %w", err)
 	}
 
 	req, err := retryablehttp.NewRequestWithContext(ctx, method, c.baseURL.JoinPath(path).String(), jsonReqBody)
 	if err != nil {
-		return fmt.Errorf("creating HTTP request: %w", err)
+		return nil, fmt.Errorf("creating HTTP request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	res, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("doing request: %w", err)
+		return nil, fmt.Errorf("doing request: %w", err)
 	}
 	defer res.Body.Close()
 
 	data, err := io.ReadAll(res.Body)
 	if err != nil {
-		return fmt.Errorf("reading response body (%w)", err)
+		return nil, fmt.Errorf("reading response body (%w)", err)
 	}
 
 	if res.StatusCode != http.StatusOK {
@@ -343,19 +460,22 @@ func (c *HTTPPoetClient) req(
 
 	switch res.StatusCode {
 	case http.StatusOK:
+		return data, nil
 	case http.StatusBadRequest:
-		return fmt.Errorf("%w: response status code: %s, body: %s", ErrInvalidRequest, res.Status, string(data))
+		return nil, fmt.Errorf("%w: response status code: %s, body: %s", ErrInvalidRequest, res.Status, string(data))
 	case http.StatusUnauthorized:
-		return fmt.Errorf("%w: response status code: %s, body: %s", ErrUnauthorized, res.Status, string(data))
+		return nil, fmt.Errorf("%w: response status code: %s, body: %s", ErrUnauthorized, res.Status, string(data))
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: response status code: %s, body: %s", errNotFound, res.Status, string(data))
 	default:
-		return fmt.Errorf("unrecognized error: status code: %s, body: %s", res.Status, string(data))
+		return nil, fmt.Errorf("unrecognized error: status code: %s, body: %s", res.Status, string(data))
 	}
+}
 
-	if resBody != nil {
-		unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
-		if err := unmarshaler.Unmarshal(data, resBody); err != nil {
-			return fmt.Errorf("decoding response body to proto: %w", err)
-		}
+func unmarshalResponse(data []byte, resBody proto.Message) error {
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err := unmarshaler.Unmarshal(data, resBody); err != nil {
+		return fmt.Errorf("decoding response body to proto: %w", err)
 	}
 	return nil
 }
@@ -381,6 +501,15 @@ func (c *cachedData[T]) get(init func() (T, error)) (T, error) {
 	return d, err
 }
 
+// proofCacheSize is the number of rounds which proofs are cached by the poet service.
+// Identities that registered late can be in the next round, so more than one round is kept.
+const proofCacheSize = 4
+
+type roundProof struct {
+	proof   *types.PoetProof
+	members []types.Hash32
+}
+
 // poetService is a higher-level interface to communicate with a PoET service.
 // It wraps the HTTP client, adding additional functionality.
 type poetService struct {
@@ -392,8 +521,9 @@ type poetService struct {
 
 	// Used to avoid concurrent requests for proof.
 	gettingProof sync.Mutex
-	// cached members of the last queried proof
-	proofMembers map[string][]types.Hash32
+	// verified proofs of recently queried rounds with their members. All identities registered
+	// in a round construct their membership proofs from the same members.
+	proofs *lru.Cache[string, roundProof]
 
 	certifier certifierService
 
@@ -410,6 +540,14 @@ func WithCertifier(certifier certifierService) PoetServiceOpt {
 	}
 }
 
+// withRequestTimeout overrides the timeout of the poet requests, which defaults to the
+// configured RequestTimeout.
+func withRequestTimeout(timeout time.Duration) PoetServiceOpt {
+	return func(c *poetService) {
+		c.requestTimeout = timeout
+	}
+}
+
 func NewPoetService(
 	db poetDbAPI,
 	server types.PoetServer,
@@ -417,16 +555,17 @@ func NewPoetService(
 	logger *zap.Logger,
 	opts ...PoetServiceOpt,
 ) (*poetService, error) {
-	client, err := NewHTTPPoetClient(server, cfg, WithLogger(logger))
+	client, err := NewPoetClient(server, cfg, logger)
 	if err != nil {
 		return nil, err
 	}
+	timeout := cfg.Settings(server.Address, cfg.CycleGap).RequestTimeout
 	return NewPoetServiceWithClient(
 		db,
 		client,
 		cfg,
 		logger,
-		opts...,
+		append([]PoetServiceOpt{withRequestTimeout(timeout)}, opts...)...,
 	), nil
 }
 
@@ -437,6 +576,10 @@ func NewPoetServiceWithClient(
 	logger *zap.Logger,
 	opts ...PoetServiceOpt,
 ) *poetService {
+	proofs, err := lru.New[string, roundProof](proofCacheSize)
+	if err != nil {
+		logger.Fatal("failed to create proof cache", zap.Error(err))
+	}
 	service := &poetService{
 		db:                 db,
 		logger:             logger,
@@ -444,14 +587,14 @@ func NewPoetServiceWithClient(
 		requestTimeout:     cfg.RequestTimeout,
 		infoCache:          cachedData[*types.PoetInfo]{ttl: cfg.InfoCacheTTL},
 		powParamsCache:     cachedData[*PoetPowParams]{ttl: cfg.PowParamsCacheTTL},
-		proofMembers:       make(map[string][]types.Hash32, 1),
+		proofs:             proofs,
 		expectedPhaseShift: cfg.PhaseShift,
 	}
 	for _, opt := range opts {
 		opt(service)
 	}
 
-	err := service.verifyPhaseShiftConfiguration(context.Background())
+	err = service.verifyPhaseShiftConfiguration(context.Background())
 	switch {
 	case errors.Is(err, ErrIncompatiblePhaseShift):
 		logger.Fatal("failed to create poet service", zap.String("poet", client.Address()))
@@ -492,6 +635,7 @@ func (c *poetService) authorize(
 	cert, err := c.Certify(ctx, nodeID)
 	switch {
 	case err == nil:
+		metrics.PoetAuthorizedCertificate.Inc()
 		return &PoetAuth{PoetCert: cert}, nil
 	case errors.Is(err, ErrCertificatesNotSupported):
 		logger.Debug("poet doesn't support certificates")
@@ -522,6 +666,7 @@ func (c *poetService) authorize(
 	if err != nil {
 		return nil, fmt.Errorf("running poet PoW: %w", err)
 	}
+	metrics.PoetAuthorizedPoW.Inc()
 
 	return &PoetAuth{PoetPoW: &PoetPoW{
 		Nonce:  nonce,
@@ -536,7 +681,7 @@ func (c *poetService) reauthorize(
 ) (*PoetAuth, error) {
 	if c.certifier != nil {
 		if info, err := c.getInfo(ctx); err == nil {
-			if err := c.certifier.DeleteCertificate(id, info.Certifier.Pubkey); err != nil {
+			if err := c.certifier.DeleteCertificate(id, c.Address(), info.Certifier); err != nil {
 				return nil, fmt.Errorf("deleting cert: %w", err)
 			}
 		}
@@ -596,13 +741,9 @@ func (c *poetService) Proof(ctx context.Context, roundID string) (*types.PoetPro
 	c.gettingProof.Lock()
 	defer c.gettingProof.Unlock()
 
-	if members, ok := c.proofMembers[roundID]; ok {
-		proof, err := c.db.ProofForRound(c.client.Id(), roundID)
-		if err == nil {
-			c.logger.Debug("returning cached proof", zap.String("round_id", roundID))
-			return proof, members, nil
-		}
-		c.logger.Warn("cached members found but proof not found in db", zap.String("round_id", roundID), zap.Error(err))
+	if cached, ok := c.proofs.Get(roundID); ok {
+		c.logger.Debug("returning cached proof", zap.String("round_id", roundID))
+		return cached.proof, cached.members, nil
 	}
 
 	proof, members, err := c.client.Proof(getProofsCtx, roundID)
@@ -614,8 +755,7 @@ func (c *poetService) Proof(ctx context.Context, roundID string) (*types.PoetPro
 		c.logger.Warn("failed to validate and store proof", zap.Error(err), zap.Object("proof", proof))
 		return nil, nil, fmt.Errorf("validating and storing proof: %w", err)
 	}
-	clear(c.proofMembers)
-	c.proofMembers[roundID] = members
+	c.proofs.Add(roundID, roundProof{proof: &proof.PoetProof, members: members})
 
 	return &proof.PoetProof, members, nil
 }
@@ -629,11 +769,37 @@ func (c *poetService) Certify(ctx context.Context, id types.NodeID) (*certifier.
 	if err != nil {
 		return nil, err
 	}
+	return c.certifier.Certificate(ctx, id, c.Address(), info.Certifier)
+}
 
-	if info.Certifier == nil {
-		return nil, ErrCertificatesNotSupported
+// Preflight verifies that the poet is reachable and its phase shift matches the config, and
+// how the node would authorize with it. Unlike a submission it doesn't run the PoW.
+func (c *poetService) Preflight(ctx context.Context, id types.NodeID) (*PoetPreflight, error) {
+	rst := &PoetPreflight{Address: c.Address()}
+	if err := c.verifyPhaseShiftConfiguration(ctx); err != nil {
+		return rst, err
+	}
+	cert, err := c.Certify(ctx, id)
+	switch {
+	case err == nil:
+		rst.Auth = PoetAuthCertificate
+		rst.CertExpiration = cert.Expiration
+		return rst, nil
+	case errors.Is(err, ErrCertificatesNotSupported), errors.Is(err, ErrCertifierNotConfigured):
+	default:
+		// a submission falls back to PoW as well
+		rst.CertErr = err
+	}
+
+	powCtx, cancel := withConditionalTimeout(ctx, c.requestTimeout)
+	defer cancel()
+	powParams, err := c.powParams(powCtx)
+	if err != nil {
+		return rst, &PoetSvcUnstableError{msg: "failed to get PoW params", source: err}
 	}
-	return c.certifier.Certificate(ctx, id, info.Certifier.Url, info.Certifier.Pubkey)
+	rst.Auth = PoetAuthPoW
+	rst.PowDifficulty = powParams.Difficulty
+	return rst, nil
 }
 
 func (c *poetService) getInfo(ctx context.Context) (*types.PoetInfo, error) {
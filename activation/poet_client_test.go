@@ -51,6 +51,28 @@ func Test_HTTPPoetClient_ParsesURL(t *testing.T) {
 	})
 }
 
+func Test_HTTPPoetClient_Overrides(t *testing.T) {
+	cfg := PoetConfig{
+		RequestRetryDelay: time.Second,
+		MaxRequestRetries: 10,
+		Overrides: []PoetOverride{{
+			Address:           "https://slow",
+			RequestRetryDelay: 3 * time.Second,
+			MaxRequestRetries: 2,
+		}},
+	}
+	client, err := NewHTTPPoetClient(types.PoetServer{Address: "https://slow"}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, 2, client.client.RetryMax)
+	require.Equal(t, 3*time.Second, client.client.RetryWaitMin)
+	require.Equal(t, 3*time.Second, client.submitChallengeClient.RetryWaitMin)
+
+	client, err = NewHTTPPoetClient(types.PoetServer{Address: "https://fast"}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, 10, client.client.RetryMax)
+	require.Equal(t, time.Second, client.client.RetryWaitMin)
+}
+
 func Test_HTTPPoetClient_Submit(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /v1/submit", func(w http.ResponseWriter, r *http.Request) {
@@ -181,6 +203,52 @@ func Test_HTTPPoetClient_Proof(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_HTTPPoetClient_DetectsVersion(t *testing.T) {
+	var served atomic.Bool
+	served.Store(true)
+	var infoCalled atomic.Uint64
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/info", func(w http.ResponseWriter, r *http.Request) {
+		infoCalled.Add(1)
+		if !served.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"phaseShift":"0s","cycleGap":"0s"}`))
+	})
+	mux.HandleFunc("GET /v1/proofs/1", func(w http.ResponseWriter, r *http.Request) {
+		resp, err := protojson.Marshal(&rpcapi.ProofResponse{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(resp)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cfg := server.DefaultRoundConfig()
+	client, err := NewHTTPPoetClient(types.PoetServer{Address: ts.URL}, PoetConfig{
+		PhaseShift:        cfg.PhaseShift,
+		CycleGap:          cfg.CycleGap,
+		MaxRequestRetries: 3,
+	}, withCustomHttpClient(ts.Client()))
+	require.NoError(t, err)
+
+	_, err = client.Info(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 1, client.api.Load().version)
+	_, _, err = client.Proof(context.Background(), "1")
+	require.NoError(t, err)
+
+	// a poet that doesn't serve the info endpoint of any supported version isn't retried
+	served.Store(false)
+	infoCalled.Store(0)
+	_, err = client.Info(context.Background())
+	require.ErrorIs(t, err, ErrPoetVersionUnsupported)
+	require.EqualValues(t, len(poetAPIs), infoCalled.Load())
+}
+
 func TestPoetClient_CachesProof(t *testing.T) {
 	var proofsCalled atomic.Uint64
 	mux := http.NewServeMux()
@@ -203,7 +271,6 @@ func TestPoetClient_CachesProof(t *testing.T) {
 	ctx := context.Background()
 	db := NewMockpoetDbAPI(gomock.NewController(t))
 	db.EXPECT().ValidateAndStore(ctx, gomock.Any())
-	db.EXPECT().ProofForRound(server.Pubkey.Bytes(), "1").Times(19)
 
 	client, err := NewHTTPPoetClient(server, DefaultPoetConfig(), withCustomHttpClient(ts.Client()))
 	require.NoError(t, err)
@@ -223,6 +290,11 @@ func TestPoetClient_CachesProof(t *testing.T) {
 	_, _, err = poet.Proof(ctx, "2")
 	require.NoError(t, err)
 	require.Equal(t, uint64(2), proofsCalled.Load())
+
+	// identities registered in different rounds don't evict each other's proofs
+	_, _, err = poet.Proof(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), proofsCalled.Load())
 }
 
 func TestPoetClient_QueryProofTimeout(t *testing.T) {
@@ -269,6 +341,7 @@ func TestPoetClient_Certify(t *testing.T) {
 	t.Run("poet supports certificate", func(t *testing.T) {
 		certifierAddress := &url.URL{Scheme: "http", Host: "certifier"}
 		certifierPubKey := []byte("certifier-pubkey")
+		certifierInfo := &types.CertifierInfo{Url: certifierAddress, Pubkey: certifierPubKey}
 
 		infoResp, err := protojson.Marshal(&rpcapi.InfoResponse{
 			ServicePubkey: []byte("pubkey"),
@@ -294,7 +367,7 @@ func TestPoetClient_Certify(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mCertifier := NewMockcertifierService(ctrl)
 		mCertifier.EXPECT().
-			Certificate(gomock.Any(), sig.NodeID(), certifierAddress, certifierPubKey).
+			Certificate(gomock.Any(), sig.NodeID(), gomock.Any(), certifierInfo).
 			Return(&cert, nil)
 
 		client, err := NewHTTPPoetClient(server, cfg, withCustomHttpClient(ts.Client()))
@@ -324,6 +397,9 @@ func TestPoetClient_Certify(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mCertifier := NewMockcertifierService(ctrl)
+		mCertifier.EXPECT().
+			Certificate(gomock.Any(), sig.NodeID(), gomock.Any(), (*types.CertifierInfo)(nil)).
+			Return(nil, ErrCertificatesNotSupported)
 
 		client, err := NewHTTPPoetClient(server, cfg, withCustomHttpClient(ts.Client()))
 		require.NoError(t, err)
@@ -340,6 +416,7 @@ func TestPoetClient_ObtainsCertOnSubmit(t *testing.T) {
 
 	certifierAddress := &url.URL{Scheme: "http", Host: "certifier"}
 	certifierPubKey := []byte("certifier-pubkey")
+	certifierInfo := &types.CertifierInfo{Url: certifierAddress, Pubkey: certifierPubKey}
 	mux := http.NewServeMux()
 	infoResp, err := protojson.Marshal(&rpcapi.InfoResponse{
 		ServicePubkey: []byte("pubkey"),
@@ -366,7 +443,7 @@ func TestPoetClient_ObtainsCertOnSubmit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mCertifier := NewMockcertifierService(ctrl)
 	mCertifier.EXPECT().
-		Certificate(gomock.Any(), sig.NodeID(), certifierAddress, certifierPubKey).
+		Certificate(gomock.Any(), sig.NodeID(), gomock.Any(), certifierInfo).
 		Return(&cert, nil)
 
 	client, err := NewHTTPPoetClient(server, cfg, withCustomHttpClient(ts.Client()))
@@ -383,6 +460,7 @@ func TestPoetClient_RecertifiesOnAuthFailure(t *testing.T) {
 
 	certifierAddress := &url.URL{Scheme: "http", Host: "certifier"}
 	certifierPubKey := []byte("certifier-pubkey")
+	certifierInfo := &types.CertifierInfo{Url: certifierAddress, Pubkey: certifierPubKey}
 	submitCount := 0
 	certs := make(chan []byte, 2)
 
@@ -424,11 +502,11 @@ func TestPoetClient_RecertifiesOnAuthFailure(t *testing.T) {
 	mCertifier := NewMockcertifierService(ctrl)
 	gomock.InOrder(
 		mCertifier.EXPECT().
-			Certificate(gomock.Any(), sig.NodeID(), certifierAddress, certifierPubKey).
+			Certificate(gomock.Any(), sig.NodeID(), gomock.Any(), certifierInfo).
 			Return(&certifier.PoetCert{Data: []byte("first")}, nil),
-		mCertifier.EXPECT().DeleteCertificate(sig.NodeID(), certifierPubKey),
+		mCertifier.EXPECT().DeleteCertificate(sig.NodeID(), gomock.Any(), certifierInfo),
 		mCertifier.EXPECT().
-			Certificate(gomock.Any(), sig.NodeID(), certifierAddress, certifierPubKey).
+			Certificate(gomock.Any(), sig.NodeID(), gomock.Any(), certifierInfo).
 			Return(&certifier.PoetCert{Data: []byte("second")}, nil),
 	)
 
@@ -449,6 +527,7 @@ func TestPoetClient_FallbacksToPowWhenCannotRecertify(t *testing.T) {
 
 	certifierAddress := &url.URL{Scheme: "http", Host: "certifier"}
 	certifierPubKey := []byte("certifier-pubkey")
+	certifierInfo := &types.CertifierInfo{Url: certifierAddress, Pubkey: certifierPubKey}
 
 	mux := http.NewServeMux()
 	infoResp, err := protojson.Marshal(&rpcapi.InfoResponse{
@@ -498,11 +577,11 @@ func TestPoetClient_FallbacksToPowWhenCannotRecertify(t *testing.T) {
 	mCertifier := NewMockcertifierService(ctrl)
 	gomock.InOrder(
 		mCertifier.EXPECT().
-			Certificate(gomock.Any(), sig.NodeID(), certifierAddress, certifierPubKey).
+			Certificate(gomock.Any(), sig.NodeID(), gomock.Any(), certifierInfo).
 			Return(&certifier.PoetCert{Data: []byte("first")}, nil),
-		mCertifier.EXPECT().DeleteCertificate(sig.NodeID(), certifierPubKey),
+		mCertifier.EXPECT().DeleteCertificate(sig.NodeID(), gomock.Any(), certifierInfo),
 		mCertifier.EXPECT().
-			Certificate(gomock.Any(), sig.NodeID(), certifierAddress, certifierPubKey).
+			Certificate(gomock.Any(), sig.NodeID(), gomock.Any(), certifierInfo).
 			Return(nil, errors.New("cannot recertify")),
 	)
 
@@ -593,6 +672,85 @@ func TestPoetService_CachesPowParams(t *testing.T) {
 	}
 }
 
+func TestPoetService_Preflight(t *testing.T) {
+	t.Parallel()
+
+	nodeID := types.RandomNodeID()
+	certifierInfo := &types.CertifierInfo{Url: &url.URL{Host: "certifier"}, Pubkey: []byte("pubkey")}
+	params := PoetPowParams{Challenge: types.RandomBytes(10), Difficulty: 8}
+
+	newService := func(t *testing.T, info *types.PoetInfo, opts ...PoetServiceOpt) (*poetService, *MockPoetClient) {
+		client := NewMockPoetClient(gomock.NewController(t))
+		client.EXPECT().Address().Return("some_addr").AnyTimes()
+		client.EXPECT().Info(gomock.Any()).Return(info, nil).AnyTimes()
+		return NewPoetServiceWithClient(nil, client, DefaultPoetConfig(), zaptest.NewLogger(t), opts...), client
+	}
+
+	t.Run("certificate", func(t *testing.T) {
+		t.Parallel()
+		expiration := time.Now().Add(time.Hour)
+		mCertifier := NewMockcertifierService(gomock.NewController(t))
+		mCertifier.EXPECT().Certificate(gomock.Any(), nodeID, "some_addr", certifierInfo).
+			Return(&certifier.PoetCert{Data: []byte("cert"), Expiration: &expiration}, nil)
+		poet, _ := newService(t, &types.PoetInfo{Certifier: certifierInfo}, WithCertifier(mCertifier))
+
+		rst, err := poet.Preflight(context.Background(), nodeID)
+		require.NoError(t, err)
+		require.Equal(t, &PoetPreflight{
+			Address:        "some_addr",
+			Auth:           PoetAuthCertificate,
+			CertExpiration: &expiration,
+		}, rst)
+	})
+	t.Run("falls back to pow if certification fails", func(t *testing.T) {
+		t.Parallel()
+		mCertifier := NewMockcertifierService(gomock.NewController(t))
+		certErr := errors.New("certifier is down")
+		mCertifier.EXPECT().Certificate(gomock.Any(), nodeID, "some_addr", certifierInfo).Return(nil, certErr)
+		poet, client := newService(t, &types.PoetInfo{Certifier: certifierInfo}, WithCertifier(mCertifier))
+		client.EXPECT().PowParams(gomock.Any()).Return(&params, nil)
+
+		rst, err := poet.Preflight(context.Background(), nodeID)
+		require.NoError(t, err)
+		require.Equal(t, &PoetPreflight{
+			Address:       "some_addr",
+			Auth:          PoetAuthPoW,
+			CertErr:       certErr,
+			PowDifficulty: params.Difficulty,
+		}, rst)
+	})
+	t.Run("pow without certifier", func(t *testing.T) {
+		t.Parallel()
+		poet, client := newService(t, &types.PoetInfo{})
+		client.EXPECT().PowParams(gomock.Any()).Return(&params, nil)
+
+		rst, err := poet.Preflight(context.Background(), nodeID)
+		require.NoError(t, err)
+		require.Equal(t, &PoetPreflight{Address: "some_addr", Auth: PoetAuthPoW, PowDifficulty: params.Difficulty}, rst)
+	})
+	t.Run("pow params unavailable", func(t *testing.T) {
+		t.Parallel()
+		poet, client := newService(t, &types.PoetInfo{})
+		client.EXPECT().PowParams(gomock.Any()).Return(nil, errors.New("unavailable"))
+
+		rst, err := poet.Preflight(context.Background(), nodeID)
+		var unstable *PoetSvcUnstableError
+		require.ErrorAs(t, err, &unstable)
+		require.Empty(t, rst.Auth)
+	})
+	t.Run("poet unreachable", func(t *testing.T) {
+		t.Parallel()
+		client := NewMockPoetClient(gomock.NewController(t))
+		client.EXPECT().Address().Return("some_addr").AnyTimes()
+		client.EXPECT().Info(gomock.Any()).Return(nil, errors.New("unreachable")).AnyTimes()
+		poet := NewPoetServiceWithClient(nil, client, DefaultPoetConfig(), zaptest.NewLogger(t))
+
+		rst, err := poet.Preflight(context.Background(), nodeID)
+		require.Error(t, err)
+		require.Equal(t, &PoetPreflight{Address: "some_addr"}, rst)
+	})
+}
+
 func TestPoetService_FetchPoetPhaseShift(t *testing.T) {
 	t.Parallel()
 	const phaseShift = time.Second
@@ -0,0 +1,228 @@
+package activation
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+var errUnsupportedTransport = errors.New("unsupported poet transport")
+
+// NewPoetClient returns a client for the poet using the transport selected for it in the config,
+// see PoetConfig.Settings.
+func NewPoetClient(server types.PoetServer, cfg PoetConfig, logger *zap.Logger) (PoetClient, error) {
+	baseURL, err := parsePoetAddress(server.Address)
+	if err != nil {
+		return nil, err
+	}
+	switch transport := cfg.Settings(baseURL.String(), cfg.CycleGap).Transport; transport {
+	case PoetTransportHTTP:
+		return NewHTTPPoetClient(server, cfg, WithLogger(logger))
+	case PoetTransportGRPC:
+		return NewGRPCPoetClient(server, cfg, logger)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedTransport, transport)
+	}
+}
+
+// parsePoetAddress parses the address of a poet, defaulting to the http scheme.
+func parsePoetAddress(address string) (*url.URL, error) {
+	baseURL, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing address: %w", err)
+	}
+	if baseURL.Scheme == "" {
+		baseURL.Scheme = "http"
+	}
+	return baseURL, nil
+}
+
+// GRPCPoetClient implements PoetClient over the gRPC api of the poet.
+type GRPCPoetClient struct {
+	id       []byte
+	baseURL  *url.URL
+	conn     *grpc.ClientConn
+	client   rpcapi.PoetServiceClient
+	settings PoetSettings
+	logger   *zap.Logger
+}
+
+// NewGRPCPoetClient returns a client connecting to the host and port of the poet address.
+// The connection is established lazily by the first request.
+func NewGRPCPoetClient(server types.PoetServer, cfg PoetConfig, logger *zap.Logger) (*GRPCPoetClient, error) {
+	baseURL, err := parsePoetAddress(server.Address)
+	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	if baseURL.Scheme == "https" {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(baseURL.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("grpc client %s: %w", baseURL.Host, err)
+	}
+	c := &GRPCPoetClient{
+		id:       server.Pubkey.Bytes(),
+		baseURL:  baseURL,
+		conn:     conn,
+		client:   rpcapi.NewPoetServiceClient(conn),
+		settings: cfg.Settings(baseURL.String(), cfg.CycleGap),
+		logger:   logger,
+	}
+	logger.Info(
+		"created grpc poet client",
+		zap.Stringer("url", baseURL),
+		zap.Binary("pubkey", server.Pubkey.Bytes()),
+		zap.Int("default max retries", c.settings.MaxRequestRetries),
+		zap.Duration("min retry wait", c.settings.RequestRetryDelay),
+		zap.Duration("max retry wait", 2*c.settings.RequestRetryDelay),
+	)
+	return c, nil
+}
+
+func (c *GRPCPoetClient) Id() []byte {
+	return c.id
+}
+
+func (c *GRPCPoetClient) Address() string {
+	return c.baseURL.String()
+}
+
+// Close closes the connection to the poet.
+func (c *GRPCPoetClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *GRPCPoetClient) PowParams(ctx context.Context) (*PoetPowParams, error) {
+	var resp *rpcapi.PowParamsResponse
+	err := c.retry(ctx, c.settings.MaxRequestRetries, retryableCode, func(ctx context.Context) (err error) {
+		resp, err = c.client.PowParams(ctx, &rpcapi.PowParamsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying PoW params: %w", err)
+	}
+	return powParamsFromResponse(resp), nil
+}
+
+// Submit registers a challenge in the proving service current open round.
+// Like the HTTP client, it retries until the context is canceled.
+func (c *GRPCPoetClient) Submit(
+	ctx context.Context,
+	deadline time.Time,
+	prefix, challenge []byte,
+	signature types.EdSignature,
+	nodeID types.NodeID,
+	auth PoetAuth,
+) (*types.PoetRound, error) {
+	request := submitRequest(deadline, prefix, challenge, signature, nodeID, auth)
+	var resp *rpcapi.SubmitResponse
+	err := c.retry(ctx, -1, retryableSubmitCode, func(ctx context.Context) (err error) {
+		resp, err = c.client.Submit(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("submitting challenge: %w", err)
+	}
+	return roundFromResponse(resp), nil
+}
+
+func (c *GRPCPoetClient) Info(ctx context.Context) (*types.PoetInfo, error) {
+	var resp *rpcapi.InfoResponse
+	err := c.retry(ctx, c.settings.MaxRequestRetries, retryableCode, func(ctx context.Context) (err error) {
+		resp, err = c.client.Info(ctx, &rpcapi.InfoRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting poet info: %w", err)
+	}
+	return infoFromResponse(resp)
+}
+
+func (c *GRPCPoetClient) Proof(ctx context.Context, roundID string) (*types.PoetProofMessage, []types.Hash32, error) {
+	var resp *rpcapi.ProofResponse
+	err := c.retry(ctx, c.settings.MaxRequestRetries, retryableCode, func(ctx context.Context) (err error) {
+		resp, err = c.client.Proof(ctx, &rpcapi.ProofRequest{RoundId: roundID})
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting proof: %w", err)
+	}
+	return proofFromResponse(resp, roundID)
+}
+
+// retry calls the poet until it succeeds, fails with a code that is not retryable or
+// it was retried max times. A negative max retries until the context is canceled.
+func (c *GRPCPoetClient) retry(
+	ctx context.Context,
+	max int,
+	retryable func(codes.Code) bool,
+	call func(context.Context) error,
+) error {
+	for attempt := 0; ; attempt++ {
+		err := call(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		code := status.Code(err)
+		if !retryable(code) || (max >= 0 && attempt >= max) {
+			return grpcPoetError(err)
+		}
+		c.logger.Debug("poet request failed, retrying", zap.Stringer("code", code), zap.Int("attempt", attempt))
+		wait := customLinearJitterBackoff(c.settings.RequestRetryDelay, 2*c.settings.RequestRetryDelay, attempt, nil)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryableSubmitCode matches the retry policy of the HTTP client for submissions:
+// connection errors and server errors are retried.
+func retryableSubmitCode(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// retryableCode additionally retries NotFound, as the poet answers it for proofs
+// of rounds that are not finished yet.
+func retryableCode(code codes.Code) bool {
+	return code == codes.NotFound || retryableSubmitCode(code)
+}
+
+// grpcPoetError translates the status of a failed request to the errors of the HTTP client.
+func grpcPoetError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", ErrInvalidRequest, st.Message())
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, st.Message())
+	default:
+		return fmt.Errorf("unrecognized error: %w", err)
+	}
+}
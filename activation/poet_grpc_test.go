@@ -0,0 +1,153 @@
+package activation
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+type testPoetServer struct {
+	rpcapi.UnimplementedPoetServiceServer
+
+	proofRequests atomic.Int32
+	submitted     chan *rpcapi.SubmitRequest
+}
+
+func (s *testPoetServer) Info(context.Context, *rpcapi.InfoRequest) (*rpcapi.InfoResponse, error) {
+	return &rpcapi.InfoResponse{
+		ServicePubkey: []byte("pubkey"),
+		PhaseShift:    durationpb.New(time.Hour),
+		CycleGap:      durationpb.New(10 * time.Minute),
+	}, nil
+}
+
+func (s *testPoetServer) Submit(_ context.Context, req *rpcapi.SubmitRequest) (*rpcapi.SubmitResponse, error) {
+	if len(req.Challenge) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty challenge")
+	}
+	s.submitted <- req
+	return &rpcapi.SubmitResponse{RoundId: "7", RoundEnd: durationpb.New(time.Hour)}, nil
+}
+
+func (s *testPoetServer) Proof(_ context.Context, req *rpcapi.ProofRequest) (*rpcapi.ProofResponse, error) {
+	// the proof of the round is ready on the second request
+	if s.proofRequests.Add(1) == 1 {
+		return nil, status.Error(codes.NotFound, "round is not finished")
+	}
+	return &rpcapi.ProofResponse{
+		Proof: &rpcapi.PoetProof{
+			Proof:   &rpcapi.MerkleProof{Root: []byte("root")},
+			Members: [][]byte{types.RandomHash().Bytes()},
+			Leaves:  100,
+		},
+		Pubkey: []byte("pubkey"),
+	}, nil
+}
+
+func launchTestPoet(t *testing.T) (*testPoetServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	poet := &testPoetServer{submitted: make(chan *rpcapi.SubmitRequest, 1)}
+	rpcapi.RegisterPoetServiceServer(srv, poet)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return poet, "http://" + lis.Addr().String()
+}
+
+func TestNewPoetClient_Transport(t *testing.T) {
+	cfg := PoetConfig{Overrides: []PoetOverride{
+		{Address: "http://grpc:9000", Transport: PoetTransportGRPC},
+		{Address: "http://other:9000", Transport: "other"},
+	}}
+	client, err := NewPoetClient(types.PoetServer{Address: "http://grpc:9000"}, cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.IsType(t, &GRPCPoetClient{}, client)
+	require.NoError(t, client.(*GRPCPoetClient).Close())
+
+	client, err = NewPoetClient(types.PoetServer{Address: "http://http:9000"}, cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.IsType(t, &HTTPPoetClient{}, client)
+
+	_, err = NewPoetClient(types.PoetServer{Address: "http://other:9000"}, cfg, zaptest.NewLogger(t))
+	require.ErrorIs(t, err, errUnsupportedTransport)
+}
+
+func TestGRPCPoetClient(t *testing.T) {
+	poet, address := launchTestPoet(t)
+	cfg := PoetConfig{RequestRetryDelay: time.Millisecond, MaxRequestRetries: 3}
+	client, err := NewGRPCPoetClient(types.PoetServer{Address: address}, cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, client.Close()) })
+	require.Equal(t, address, client.Address())
+
+	info, err := client.Info(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &types.PoetInfo{
+		ServicePubkey: []byte("pubkey"),
+		PhaseShift:    time.Hour,
+		CycleGap:      10 * time.Minute,
+	}, info)
+
+	nodeID := types.RandomNodeID()
+	auth := PoetAuth{PoetPoW: &PoetPoW{Nonce: 5, Params: PoetPowParams{Challenge: []byte("pow"), Difficulty: 3}}}
+	round, err := client.Submit(context.Background(), time.Now(), []byte("prefix"), []byte("challenge"),
+		types.EmptyEdSignature, nodeID, auth)
+	require.NoError(t, err)
+	require.Equal(t, "7", round.ID)
+	require.WithinDuration(t, time.Now().Add(time.Hour), round.End, time.Minute)
+	submitted := <-poet.submitted
+	require.Equal(t, nodeID.Bytes(), submitted.Pubkey)
+	require.Equal(t, uint64(5), submitted.Nonce)
+	require.Equal(t, uint32(3), submitted.PowParams.Difficulty)
+
+	_, err = client.Submit(context.Background(), time.Now(), []byte("prefix"), nil,
+		types.EmptyEdSignature, nodeID, auth)
+	require.ErrorIs(t, err, ErrInvalidRequest)
+
+	// not found is retried
+	proof, members, err := client.Proof(context.Background(), "7")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, poet.proofRequests.Load())
+	require.Len(t, members, 1)
+	require.Equal(t, "7", proof.RoundID)
+	require.EqualValues(t, 100, proof.LeafCount)
+
+	// PowParams is not implemented by the test server
+	_, err = client.PowParams(context.Background())
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestGRPCPoetClient_SubmitRetriesUntilCanceled(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := "http://" + lis.Addr().String()
+	require.NoError(t, lis.Close()) // nothing listens on the address
+
+	cfg := PoetConfig{RequestRetryDelay: time.Millisecond, MaxRequestRetries: 1}
+	client, err := NewGRPCPoetClient(types.PoetServer{Address: address}, cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, client.Close()) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = client.Submit(ctx, time.Now(), nil, []byte("challenge"), types.EmptyEdSignature, types.RandomNodeID(),
+		PoetAuth{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.Info(context.Background())
+	require.Error(t, err)
+}
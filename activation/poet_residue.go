@@ -0,0 +1,144 @@
+package activation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/certifier"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/nipost"
+)
+
+// PoetResidue is the local state kept for a poet that is not configured anymore.
+type PoetResidue struct {
+	Address string `json:"address"`
+	// Registrations is the number of registrations with the poet, proofs for them are not fetched.
+	Registrations int `json:"registrations"`
+	// Certificates is the number of certificates for the poet, they are not used.
+	Certificates int `json:"certificates"`
+}
+
+// PoetResidueChecker cross-checks the configured poets with the registrations and certificates
+// in the local database.
+type PoetResidueChecker struct {
+	logger     *zap.Logger
+	db         sql.LocalDatabase
+	configured map[string]struct{}
+}
+
+// NewPoetResidueChecker creates a checker for the addresses of the configured poets,
+// including the fallback ones.
+func NewPoetResidueChecker(logger *zap.Logger, db sql.LocalDatabase, configured []string) *PoetResidueChecker {
+	c := &PoetResidueChecker{
+		logger:     logger,
+		db:         db,
+		configured: make(map[string]struct{}, len(configured)),
+	}
+	for _, address := range configured {
+		c.configured[address] = struct{}{}
+	}
+	return c
+}
+
+// Find returns the residual state of poets that are not configured, ordered by address.
+func (c *PoetResidueChecker) Find() ([]PoetResidue, error) {
+	return c.find(c.db)
+}
+
+func (c *PoetResidueChecker) find(db sql.Executor) ([]PoetResidue, error) {
+	registrations, err := nipost.CountPoetRegistrations(db)
+	if err != nil {
+		return nil, err
+	}
+	certificates, err := certifier.CountCertificates(db)
+	if err != nil {
+		return nil, err
+	}
+	byAddress := make(map[string]*PoetResidue)
+	get := func(address string) *PoetResidue {
+		if byAddress[address] == nil {
+			byAddress[address] = &PoetResidue{Address: address}
+		}
+		return byAddress[address]
+	}
+	for address, n := range registrations {
+		if _, ok := c.configured[address]; !ok {
+			get(address).Registrations = n
+		}
+	}
+	for address, n := range certificates {
+		if _, ok := c.configured[address]; !ok {
+			get(address).Certificates = n
+		}
+	}
+	rst := make([]PoetResidue, 0, len(byAddress))
+	for _, residue := range byAddress {
+		rst = append(rst, *residue)
+	}
+	slices.SortFunc(rst, func(a, b PoetResidue) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return rst, nil
+}
+
+// Warn logs a warning with the action to take for every poet that is not configured anymore,
+// but still has state in the local database.
+func (c *PoetResidueChecker) Warn() error {
+	residues, err := c.Find()
+	if err != nil {
+		return fmt.Errorf("find poet residue: %w", err)
+	}
+	for _, residue := range residues {
+		if residue.Registrations > 0 {
+			c.logger.Warn("registration exists for poet no longer configured - proofs will not be fetched. "+
+				"Add the poet back to the config or purge its state with the admin API",
+				zap.String("poet", residue.Address),
+				zap.Int("registrations", residue.Registrations),
+			)
+		}
+		if residue.Certificates > 0 {
+			c.logger.Warn("certificate exists for poet no longer configured - it will not be used",
+				zap.String("poet", residue.Address),
+				zap.Int("certificates", residue.Certificates),
+			)
+		}
+	}
+	return nil
+}
+
+// Purge deletes the registrations and certificates of poets that are not configured
+// and returns what was deleted.
+func (c *PoetResidueChecker) Purge(ctx context.Context) ([]PoetResidue, error) {
+	var residues []PoetResidue
+	err := c.db.WithTx(ctx, func(tx sql.Transaction) error {
+		var err error
+		residues, err = c.find(tx)
+		if err != nil {
+			return err
+		}
+		for _, residue := range residues {
+			if _, err := nipost.DeletePoetRegistrationsByAddress(tx, residue.Address); err != nil {
+				return err
+			}
+			if _, err := certifier.DeleteCertificatesByPoet(tx, residue.Address); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge poet residue: %w", err)
+	}
+	for _, residue := range residues {
+		c.logger.Info("purged state of poet no longer configured",
+			zap.String("poet", residue.Address),
+			zap.Int("registrations", residue.Registrations),
+			zap.Int("certificates", residue.Certificates),
+		)
+	}
+	return residues, nil
+}
@@ -0,0 +1,73 @@
+package activation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+	certdb "github.com/spacemeshos/go-spacemesh/sql/localsql/certifier"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/nipost"
+)
+
+func TestPoetResidueChecker(t *testing.T) {
+	db := localsql.InMemory()
+	id := types.RandomNodeID()
+	for _, address := range []string{"http://poet1", "http://removed1"} {
+		require.NoError(t, nipost.AddPoetRegistration(db, id, nipost.PoETRegistration{
+			ChallengeHash: types.RandomHash(),
+			Address:       address,
+			RoundID:       "1",
+			RoundEnd:      time.Now(),
+		}))
+	}
+	cert := certdb.PoetCert{Data: []byte("cert"), Signature: []byte("sig")}
+	for _, poet := range []string{certdb.AnyPoet, "http://poet1", "http://removed1", "http://removed2"} {
+		require.NoError(t, certdb.AddCertificate(db, id, cert, poet, []byte("certifier")))
+	}
+
+	core, logs := observer.New(zap.WarnLevel)
+	checker := NewPoetResidueChecker(zap.New(core), db, []string{"http://poet1", "http://poet2"})
+	expected := []PoetResidue{
+		{Address: "http://removed1", Registrations: 1, Certificates: 1},
+		{Address: "http://removed2", Certificates: 1},
+	}
+	residues, err := checker.Find()
+	require.NoError(t, err)
+	require.Equal(t, expected, residues)
+
+	require.NoError(t, checker.Warn())
+	require.Equal(t, 3, logs.Len())
+	require.Len(t, logs.FilterField(zap.String("poet", "http://removed1")).All(), 2)
+
+	purged, err := checker.Purge(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, expected, purged)
+
+	residues, err = checker.Find()
+	require.NoError(t, err)
+	require.Empty(t, residues)
+	registrations, err := nipost.PoetRegistrations(db, id)
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	_, err = certdb.Certificate(db, id, "http://poet1", []byte("certifier"))
+	require.NoError(t, err)
+	_, err = certdb.Certificate(db, id, certdb.AnyPoet, []byte("certifier"))
+	require.NoError(t, err)
+}
+
+func TestPoetResidueChecker_Empty(t *testing.T) {
+	checker := NewPoetResidueChecker(zaptest.NewLogger(t), localsql.InMemory(), []string{"http://poet1"})
+	residues, err := checker.Find()
+	require.NoError(t, err)
+	require.Empty(t, residues)
+	purged, err := checker.Purge(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, purged)
+}
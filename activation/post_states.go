@@ -30,6 +30,19 @@ func (s *postStates) Set(id types.NodeID, state types.PostState) {
 	s.log.Info("post state changed", zap.Stringer("id", id), zap.Stringer("state", state))
 }
 
+func (s *postStates) CompareAndSet(id types.NodeID, old, state types.PostState) bool {
+	s.mu.Lock()
+	if s.states[id] != old {
+		s.mu.Unlock()
+		return false
+	}
+	s.states[id] = state
+	s.mu.Unlock()
+
+	s.log.Info("post state changed", zap.Stringer("id", id), zap.Stringer("state", state))
+	return true
+}
+
 func (s *postStates) Get() map[types.NodeID]types.PostState {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
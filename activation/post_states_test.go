@@ -41,6 +41,19 @@ func TestPostStates_SetState(t *testing.T) {
 	require.Equal(t, types.PostStateIdle, states[id])
 }
 
+func TestPostStates_CompareAndSet(t *testing.T) {
+	postStates := NewPostStates(zaptest.NewLogger(t))
+	id := types.RandomNodeID()
+	postStates.Set(id, types.PostStatePublishing)
+
+	require.True(t, postStates.CompareAndSet(id, types.PostStatePublishing, types.PostStateIdle))
+	require.Equal(t, types.PostStateIdle, postStates.Get()[id])
+
+	postStates.Set(id, types.PostStateInvalid)
+	require.False(t, postStates.CompareAndSet(id, types.PostStatePublishing, types.PostStateIdle))
+	require.Equal(t, types.PostStateInvalid, postStates.Get()[id])
+}
+
 func TestPostState_OnProof(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
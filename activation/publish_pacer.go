@@ -0,0 +1,52 @@
+package activation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// publishPacer spreads ATX broadcasts of many identities over time. Identities that finish PoST
+// at the same time would otherwise publish their ATXs in one burst, that can exceed gossip limits
+// and trip rate limiters of peers.
+//
+// Broadcasts are scheduled in the order in which identities ask for them.
+type publishPacer struct {
+	interval time.Duration
+	clock    clockwork.Clock
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newPublishPacer(interval time.Duration, clock clockwork.Clock) *publishPacer {
+	return &publishPacer{interval: interval, clock: clock}
+}
+
+// wait blocks until the next broadcast is allowed. Broadcasts are at least interval apart.
+// The slot is consumed even if the context is canceled while waiting.
+func (p *publishPacer) wait(ctx context.Context) error {
+	if p.interval == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	now := p.clock.Now()
+	slot := now
+	if p.next.After(now) {
+		slot = p.next
+	}
+	p.next = slot.Add(p.interval)
+	p.mu.Unlock()
+
+	if slot.Equal(now) {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.clock.After(slot.Sub(now)):
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package activation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishPacer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		pacer := newPublishPacer(0, clockwork.NewFakeClock())
+		for range 3 {
+			require.NoError(t, pacer.wait(context.Background()))
+		}
+	})
+
+	t.Run("spreads broadcasts", func(t *testing.T) {
+		t.Parallel()
+		clock := clockwork.NewFakeClock()
+		pacer := newPublishPacer(time.Second, clock)
+		require.NoError(t, pacer.wait(context.Background()))
+
+		done := make(chan error, 2)
+		for range 2 {
+			go func() { done <- pacer.wait(context.Background()) }()
+		}
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+		require.NoError(t, <-done)
+		select {
+		case <-done:
+			require.Fail(t, "second broadcast must wait for its slot")
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.Advance(time.Second)
+		require.NoError(t, <-done)
+
+		// slots don't accumulate while idle
+		clock.Advance(10 * time.Second)
+		require.NoError(t, pacer.wait(context.Background()))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		pacer := newPublishPacer(time.Hour, clockwork.NewFakeClock())
+		require.NoError(t, pacer.wait(context.Background()))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, pacer.wait(ctx), context.Canceled)
+	})
+}
@@ -0,0 +1,305 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// Names of the readiness checks.
+const (
+	// ReadinessPostService checks that the post service of the identity is connected.
+	ReadinessPostService = "post_service"
+	// ReadinessPostData checks that the post service can access the PoST data of the identity.
+	ReadinessPostData = "post_data"
+	// ReadinessPoets checks that the identity can register with at least one poet.
+	ReadinessPoets = "poets"
+	// ReadinessCertificates checks that the certificates for the poets are valid until the round starts.
+	ReadinessCertificates = "poet_certificates"
+	// ReadinessClock checks that the system clock is in sync with the clocks of peers.
+	ReadinessClock = "clock"
+	// ReadinessDiskSpace checks that enough space is available to store the proofs.
+	ReadinessDiskSpace = "disk_space"
+)
+
+// ReadinessConfig configures the readiness checks of the identities.
+type ReadinessConfig struct {
+	// Lead is how long before the registration window of a poet round the checks run, zero disables them.
+	// The registration window opens PoetConfig.GracePeriod before the round starts.
+	Lead time.Duration
+	// DataDir is the directory the node keeps its state and proofs in.
+	DataDir string
+	// MinFreeSpace is the space in bytes that must be available on the disk of DataDir, zero skips the check.
+	MinFreeSpace uint64
+}
+
+// ReadinessCheck is the outcome of a single readiness check.
+type ReadinessCheck struct {
+	Name string
+	// Skipped is true if the check is not configured or can't be evaluated,
+	// it doesn't count towards the score.
+	Skipped bool
+	// Err is why the check failed, nil if it passed.
+	Err error
+}
+
+// ReadinessReport is the readiness of an identity to register for the poet round, that the ATX
+// published in PublishEpoch is built for.
+type ReadinessReport struct {
+	NodeID       types.NodeID
+	PublishEpoch types.EpochID
+	Time         time.Time
+	Checks       []ReadinessCheck
+}
+
+// Score returns the share of the checks that passed, 1 if the identity is ready.
+func (r *ReadinessReport) Score() float64 {
+	evaluated, passed := 0, 0
+	for _, check := range r.Checks {
+		if check.Skipped {
+			continue
+		}
+		evaluated++
+		if check.Err == nil {
+			passed++
+		}
+	}
+	if evaluated == 0 {
+		return 1
+	}
+	return float64(passed) / float64(evaluated)
+}
+
+// Failed returns the names of the checks that failed.
+func (r *ReadinessReport) Failed() []string {
+	var rst []string
+	for _, check := range r.Checks {
+		if !check.Skipped && check.Err != nil {
+			rst = append(rst, check.Name)
+		}
+	}
+	return rst
+}
+
+// ReadinessChecker evaluates the readiness of the identities every epoch, before the registration
+// window of the next poet round opens, so that problems are noticed while they can still be fixed.
+type ReadinessChecker struct {
+	logger      *zap.Logger
+	cfg         ReadinessConfig
+	poetCfg     PoetConfig
+	layerClock  layerClock
+	clock       clockwork.Clock
+	identities  identityProvider
+	postService postService
+	poets       poetPreflighter
+	timesync    clockChecker
+
+	mu      sync.Mutex
+	reports map[types.NodeID]*ReadinessReport
+}
+
+type ReadinessOpt func(*ReadinessChecker)
+
+// WithReadinessClockCheck sets the source of the clock offset, the clock check is skipped without it.
+func WithReadinessClockCheck(timesync clockChecker) ReadinessOpt {
+	return func(c *ReadinessChecker) {
+		c.timesync = timesync
+	}
+}
+
+// WithReadinessWallClock sets the clock that is used to schedule the checks instead of the system clock.
+func WithReadinessWallClock(clock clockwork.Clock) ReadinessOpt {
+	return func(c *ReadinessChecker) {
+		c.clock = clock
+	}
+}
+
+// NewReadinessChecker creates a checker of the identities provided by identities.
+func NewReadinessChecker(
+	logger *zap.Logger,
+	cfg ReadinessConfig,
+	poetCfg PoetConfig,
+	layerClock layerClock,
+	identities identityProvider,
+	postService postService,
+	poets poetPreflighter,
+	opts ...ReadinessOpt,
+) *ReadinessChecker {
+	c := &ReadinessChecker{
+		logger:      logger,
+		cfg:         cfg,
+		poetCfg:     poetCfg,
+		layerClock:  layerClock,
+		clock:       clockwork.NewRealClock(),
+		identities:  identities,
+		postService: postService,
+		poets:       poets,
+		reports:     make(map[types.NodeID]*ReadinessReport),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Run checks the identities every epoch, ReadinessConfig.Lead before the registration window opens,
+// until the context is canceled.
+func (c *ReadinessChecker) Run(ctx context.Context) error {
+	if c.cfg.Lead == 0 {
+		return nil
+	}
+	var next types.EpochID
+	for {
+		epoch, at := c.nextCheck(next)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.clock.After(at.Sub(c.clock.Now())):
+		}
+		for _, id := range c.identities.SmesherIDs() {
+			c.check(ctx, id, epoch+1)
+		}
+		next = epoch + 1
+	}
+}
+
+// nextCheck returns the epoch of the next registration window, starting at epoch from, that is not open yet,
+// and the time when the checks for it run.
+func (c *ReadinessChecker) nextCheck(from types.EpochID) (types.EpochID, time.Time) {
+	epoch := max(c.layerClock.CurrentLayer().GetEpoch(), from)
+	for {
+		window := c.layerClock.LayerToTime(epoch.FirstLayer()).Add(c.poetCfg.PhaseShift - c.poetCfg.GracePeriod)
+		if window.After(c.clock.Now()) {
+			return epoch, window.Add(-c.cfg.Lead)
+		}
+		epoch++
+	}
+}
+
+// Check evaluates the readiness of the identity for the next registration window that is not open yet,
+// the report is kept until the next check of the identity.
+func (c *ReadinessChecker) Check(ctx context.Context, id types.NodeID) *ReadinessReport {
+	epoch, _ := c.nextCheck(0)
+	return c.check(ctx, id, epoch+1)
+}
+
+func (c *ReadinessChecker) check(ctx context.Context, id types.NodeID, publish types.EpochID) *ReadinessReport {
+	report := &ReadinessReport{
+		NodeID:       id,
+		PublishEpoch: publish,
+		Time:         c.clock.Now(),
+	}
+	report.Checks = append(report.Checks, c.checkPost(ctx, id)...)
+	report.Checks = append(report.Checks, c.checkPoets(ctx, id)...)
+	report.Checks = append(report.Checks, c.checkClock(), c.checkDiskSpace())
+
+	c.mu.Lock()
+	c.reports[id] = report
+	c.mu.Unlock()
+
+	logger := c.logger.With(
+		log.ZShortStringer("smesherID", id),
+		zap.Uint32("publish_epoch", publish.Uint32()),
+		zap.Float64("score", report.Score()),
+	)
+	ev := events.EventReadiness{NodeID: id, PublishEpoch: publish, Score: report.Score()}
+	for _, check := range report.Checks {
+		rc := events.ReadinessCheck{Name: check.Name, Skipped: check.Skipped}
+		if check.Err != nil {
+			rc.Error = check.Err.Error()
+			if !check.Skipped {
+				logger.Warn("readiness check failed", zap.String("check", check.Name), zap.Error(check.Err))
+			}
+		}
+		ev.Checks = append(ev.Checks, rc)
+	}
+	if failed := report.Failed(); len(failed) > 0 {
+		logger.Warn("identity is not ready for the next poet round", zap.Strings("failed", failed))
+	} else {
+		logger.Info("identity is ready for the next poet round")
+	}
+	events.ReportReadiness(ev)
+	return report
+}
+
+// Report returns the last readiness report of the identity.
+func (c *ReadinessChecker) Report(id types.NodeID) (*ReadinessReport, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report, ok := c.reports[id]
+	return report, ok
+}
+
+func (c *ReadinessChecker) checkPost(ctx context.Context, id types.NodeID) []ReadinessCheck {
+	client, err := c.postService.Client(id)
+	if err != nil {
+		return []ReadinessCheck{
+			{Name: ReadinessPostService, Err: err},
+			{Name: ReadinessPostData, Skipped: true, Err: err},
+		}
+	}
+	infoCtx, cancel := withConditionalTimeout(ctx, c.poetCfg.RequestTimeout)
+	defer cancel()
+	if _, err := client.Info(infoCtx); err != nil {
+		return []ReadinessCheck{
+			{Name: ReadinessPostService},
+			{Name: ReadinessPostData, Err: fmt.Errorf("post info: %w", err)},
+		}
+	}
+	return []ReadinessCheck{{Name: ReadinessPostService}, {Name: ReadinessPostData}}
+}
+
+func (c *ReadinessChecker) checkPoets(ctx context.Context, id types.NodeID) []ReadinessCheck {
+	var unreachable, certs []error
+	reachable := 0
+	for _, preflight := range c.poets.Preflight(ctx, id) {
+		switch {
+		case preflight.Err != nil:
+			unreachable = append(unreachable, fmt.Errorf("%s: %w", preflight.Address, preflight.Err))
+			continue
+		case preflight.CertErr != nil:
+			certs = append(certs, fmt.Errorf("%s: %w", preflight.Address, preflight.CertErr))
+		case preflight.CertExpiration != nil && preflight.CertExpiration.Before(preflight.RoundStart):
+			certs = append(certs, fmt.Errorf("%s: certificate expires at %s, before the round starts",
+				preflight.Address, preflight.CertExpiration.Format(time.RFC3339)))
+		}
+		reachable++
+	}
+	poets := ReadinessCheck{Name: ReadinessPoets}
+	if reachable == 0 {
+		poets.Err = fmt.Errorf("no poet is reachable: %w", errors.Join(unreachable...))
+		return []ReadinessCheck{poets, {Name: ReadinessCertificates, Skipped: true, Err: poets.Err}}
+	}
+	return []ReadinessCheck{poets, {Name: ReadinessCertificates, Err: errors.Join(certs...)}}
+}
+
+func (c *ReadinessChecker) checkClock() ReadinessCheck {
+	if c.timesync == nil {
+		return ReadinessCheck{Name: ReadinessClock, Skipped: true}
+	}
+	_, err := c.timesync.ClockOffset()
+	return ReadinessCheck{Name: ReadinessClock, Err: err}
+}
+
+func (c *ReadinessChecker) checkDiskSpace() ReadinessCheck {
+	if c.cfg.MinFreeSpace == 0 {
+		return ReadinessCheck{Name: ReadinessDiskSpace, Skipped: true}
+	}
+	free, err := freeSpace(c.cfg.DataDir)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("free space of %s: %w", c.cfg.DataDir, err)
+	case free < c.cfg.MinFreeSpace:
+		err = fmt.Errorf("%d bytes available in %s, %d required", free, c.cfg.DataDir, c.cfg.MinFreeSpace)
+	}
+	return ReadinessCheck{Name: ReadinessDiskSpace, Err: err}
+}
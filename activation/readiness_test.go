@@ -0,0 +1,184 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+)
+
+type testReadiness struct {
+	*ReadinessChecker
+
+	clock       clockwork.FakeClock
+	identities  *MockidentityProvider
+	postService *MockpostService
+	poets       *MockpoetPreflighter
+	timesync    *MockclockChecker
+}
+
+// newTestReadiness creates a checker in epoch 3, the registration window of the epoch opens in 30 minutes.
+func newTestReadiness(t *testing.T, cfg ReadinessConfig) *testReadiness {
+	ctrl := gomock.NewController(t)
+	clock := clockwork.NewFakeClock()
+	start := clock.Now()
+	mclock := NewMocklayerClock(ctrl)
+	mclock.EXPECT().CurrentLayer().Return(types.EpochID(3).FirstLayer().Add(1)).AnyTimes()
+	mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(func(layer types.LayerID) time.Time {
+		return start.Add(time.Duration(layer.GetEpoch()-3)*24*time.Hour - time.Hour)
+	}).AnyTimes()
+
+	tr := &testReadiness{
+		clock:       clock,
+		identities:  NewMockidentityProvider(ctrl),
+		postService: NewMockpostService(ctrl),
+		poets:       NewMockpoetPreflighter(ctrl),
+		timesync:    NewMockclockChecker(ctrl),
+	}
+	tr.ReadinessChecker = NewReadinessChecker(
+		zaptest.NewLogger(t),
+		cfg,
+		PoetConfig{PhaseShift: 2 * time.Hour, GracePeriod: 30 * time.Minute},
+		mclock,
+		tr.identities,
+		tr.postService,
+		tr.poets,
+		WithReadinessClockCheck(tr.timesync),
+		WithReadinessWallClock(clock),
+	)
+	return tr
+}
+
+func TestReadinessChecker_Ready(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub := events.SubscribeReadiness()
+	require.NotNil(t, sub)
+	t.Cleanup(func() { require.NoError(t, sub.Close()) })
+
+	tr := newTestReadiness(t, ReadinessConfig{DataDir: t.TempDir(), MinFreeSpace: 1})
+	id := types.RandomNodeID()
+	client := NewMockPostClient(gomock.NewController(t))
+	client.EXPECT().Info(gomock.Any()).Return(&types.PostInfo{}, nil)
+	tr.postService.EXPECT().Client(id).Return(client, nil)
+	roundStart := tr.clock.Now().Add(time.Hour)
+	expiration := roundStart.Add(time.Hour)
+	tr.poets.EXPECT().Preflight(gomock.Any(), id).Return([]PoetPreflight{
+		{Address: "http://cert", Auth: PoetAuthCertificate, CertExpiration: &expiration, RoundStart: roundStart},
+		{Address: "http://unreachable", Err: errors.New("unreachable"), RoundStart: roundStart},
+	})
+	tr.timesync.EXPECT().ClockOffset().Return(time.Second, nil)
+
+	_, ok := tr.Report(id)
+	require.False(t, ok)
+	report := tr.Check(context.Background(), id)
+	require.Equal(t, types.EpochID(4), report.PublishEpoch)
+	require.Equal(t, 1.0, report.Score())
+	require.Empty(t, report.Failed())
+	require.Len(t, report.Checks, 6)
+	stored, ok := tr.Report(id)
+	require.True(t, ok)
+	require.Same(t, report, stored)
+
+	select {
+	case ev := <-sub.Out():
+		readiness := ev.(events.EventReadiness)
+		require.Equal(t, id, readiness.NodeID)
+		require.Equal(t, types.EpochID(4), readiness.PublishEpoch)
+		require.Equal(t, 1.0, readiness.Score)
+		require.Len(t, readiness.Checks, 6)
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event")
+	}
+}
+
+func TestReadinessChecker_NotReady(t *testing.T) {
+	t.Run("nothing works", func(t *testing.T) {
+		tr := newTestReadiness(t, ReadinessConfig{DataDir: t.TempDir(), MinFreeSpace: math.MaxUint64})
+		id := types.RandomNodeID()
+		tr.postService.EXPECT().Client(id).Return(nil, ErrPostClientNotConnected)
+		tr.poets.EXPECT().Preflight(gomock.Any(), id).Return([]PoetPreflight{
+			{Address: "http://unreachable", Err: errors.New("unreachable")},
+		})
+		tr.timesync.EXPECT().ClockOffset().Return(time.Minute, errors.New("not synced"))
+
+		report := tr.Check(context.Background(), id)
+		require.Zero(t, report.Score())
+		// checks that depend on a failed one are skipped
+		require.Equal(t, []string{
+			ReadinessPostService,
+			ReadinessPoets,
+			ReadinessClock,
+			ReadinessDiskSpace,
+		}, report.Failed())
+		require.ErrorIs(t, report.Checks[0].Err, ErrPostClientNotConnected)
+		require.True(t, report.Checks[1].Skipped)
+		require.True(t, report.Checks[3].Skipped)
+	})
+	t.Run("certificates expire", func(t *testing.T) {
+		tr := newTestReadiness(t, ReadinessConfig{})
+		id := types.RandomNodeID()
+		client := NewMockPostClient(gomock.NewController(t))
+		client.EXPECT().Info(gomock.Any()).Return(&types.PostInfo{}, nil)
+		tr.postService.EXPECT().Client(id).Return(client, nil)
+		roundStart := tr.clock.Now().Add(time.Hour)
+		expiration := roundStart.Add(-time.Minute)
+		tr.poets.EXPECT().Preflight(gomock.Any(), id).Return([]PoetPreflight{
+			{Address: "http://expiring", Auth: PoetAuthCertificate, CertExpiration: &expiration, RoundStart: roundStart},
+			{Address: "http://pow", Auth: PoetAuthPoW, CertErr: errors.New("certifier down"), RoundStart: roundStart},
+		})
+		tr.timesync.EXPECT().ClockOffset().Return(time.Second, nil)
+
+		report := tr.Check(context.Background(), id)
+		// disk space isn't checked without MinFreeSpace
+		require.Equal(t, 4.0/5.0, report.Score())
+		require.Equal(t, []string{ReadinessCertificates}, report.Failed())
+		require.ErrorContains(t, report.Checks[3].Err, "http://expiring: certificate expires")
+		require.ErrorContains(t, report.Checks[3].Err, "http://pow: certifier down")
+		require.True(t, report.Checks[5].Skipped)
+	})
+}
+
+func TestReadinessChecker_Run(t *testing.T) {
+	tr := newTestReadiness(t, ReadinessConfig{Lead: 10 * time.Minute})
+	id := types.RandomNodeID()
+	tr.identities.EXPECT().SmesherIDs().Return([]types.NodeID{id})
+	tr.postService.EXPECT().Client(id).Return(nil, ErrPostClientNotConnected)
+	tr.poets.EXPECT().Preflight(gomock.Any(), id).Return(nil)
+	tr.timesync.EXPECT().ClockOffset().Return(time.Second, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tr.Run(ctx) }()
+
+	// the checks run 10 minutes before the registration window opens
+	tr.clock.BlockUntil(1)
+	tr.clock.Advance(19 * time.Minute)
+	_, ok := tr.Report(id)
+	require.False(t, ok)
+	tr.clock.Advance(time.Minute)
+
+	// once checked, the next checks wait for the window of the next epoch
+	tr.clock.BlockUntil(1)
+	report, ok := tr.Report(id)
+	require.True(t, ok)
+	require.Equal(t, types.EpochID(4), report.PublishEpoch)
+	tr.clock.Advance(time.Hour)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestReadinessChecker_Disabled(t *testing.T) {
+	tr := newTestReadiness(t, ReadinessConfig{})
+	require.NoError(t, tr.Run(context.Background()))
+}
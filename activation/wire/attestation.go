@@ -0,0 +1,47 @@
+package wire
+
+import (
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+)
+
+//go:generate scalegen
+
+// SmeshingStatus is an operational status of a smeshing identity at a point in time.
+type SmeshingStatus struct {
+	// Timestamp is the unix time (in seconds) when the status was produced.
+	Timestamp uint64
+	// Epoch is the current epoch at the time of producing the status.
+	Epoch types.EpochID
+	// ATX is the ID of the ATX published by the identity in the previous epoch, i.e. the
+	// ATX that makes the identity eligible in Epoch. EmptyATXID if there is none.
+	ATX types.ATXID
+	// NumUnits is the size of PoST of the identity in space units.
+	NumUnits uint32
+	// PostState is the state of the PoST service of the identity.
+	PostState uint8
+}
+
+// SmeshingAttestation is a SmeshingStatus signed by the identity it describes.
+// It can be verified off-chain, e.g. by pool or delegation operators.
+type SmeshingAttestation struct {
+	Status SmeshingStatus
+
+	SmesherID types.NodeID
+	Signature types.EdSignature
+}
+
+// NewSmeshingAttestation signs the status with the signer.
+func NewSmeshingAttestation(signer *signing.EdSigner, status SmeshingStatus) *SmeshingAttestation {
+	return &SmeshingAttestation{
+		Status:    status,
+		SmesherID: signer.NodeID(),
+		Signature: signer.Sign(signing.ATTESTATION, codec.MustEncode(&status)),
+	}
+}
+
+// Verify returns true if the attestation is signed by SmesherID.
+func (a *SmeshingAttestation) Verify(verifier *signing.EdVerifier) bool {
+	return verifier.Verify(signing.ATTESTATION, a.SmesherID, codec.MustEncode(&a.Status), a.Signature)
+}
@@ -0,0 +1,48 @@
+package wire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+)
+
+func TestSmeshingAttestation(t *testing.T) {
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	verifier := signing.NewEdVerifier()
+
+	attestation := NewSmeshingAttestation(signer, SmeshingStatus{
+		Timestamp: 1000,
+		Epoch:     3,
+		ATX:       types.RandomATXID(),
+		NumUnits:  4,
+		PostState: uint8(types.PostStateProving),
+	})
+	require.Equal(t, signer.NodeID(), attestation.SmesherID)
+	require.True(t, attestation.Verify(verifier))
+
+	var decoded SmeshingAttestation
+	require.NoError(t, codec.Decode(codec.MustEncode(attestation), &decoded))
+	require.Equal(t, *attestation, decoded)
+	require.True(t, decoded.Verify(verifier))
+
+	t.Run("modified status", func(t *testing.T) {
+		modified := *attestation
+		modified.Status.NumUnits++
+		require.False(t, modified.Verify(verifier))
+	})
+	t.Run("other smesher", func(t *testing.T) {
+		modified := *attestation
+		modified.SmesherID = types.RandomNodeID()
+		require.False(t, modified.Verify(verifier))
+	})
+	t.Run("other domain", func(t *testing.T) {
+		modified := *attestation
+		modified.Signature = signer.Sign(signing.ATX, codec.MustEncode(&modified.Status))
+		require.False(t, modified.Verify(verifier))
+	})
+}
@@ -2,16 +2,21 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
 	"github.com/spf13/afero"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -19,17 +24,38 @@ import (
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/spacemeshos/go-spacemesh/activation"
 	"github.com/spacemeshos/go-spacemesh/checkpoint"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
 	"github.com/spacemeshos/go-spacemesh/sql"
+	dbsnapshot "github.com/spacemeshos/go-spacemesh/sql/snapshot"
 )
 
 const (
 	chunksize      = 1024
 	defaultNumAtxs = 4
+
+	// SnapshotPath is the JSON API path that creates a snapshot of the state database.
+	// It is served only on the debug JSON listener.
+	SnapshotPath = "/v1/admin/snapshot"
+	// PoetsPurgePath is the JSON API path that purges the local state of poets that are not configured anymore.
+	// It is registered only when the node checks the poet residue.
+	PoetsPurgePath = "/v1/admin/poets/purge"
+	// CertificatesRecertifyPath is the JSON API path that replaces the stored poet certificates with new ones.
+	// It is registered only when the node certifies its identities for the poets.
+	CertificatesRecertifyPath = "/v1/admin/certificates/recertify"
+	// HareUnregisterPath is the JSON API path that removes the signing key of the identity from hare,
+	// so that a compromised or migrated key stops participating without a restart.
+	HareUnregisterPath = "/v1/admin/hare/identities/{id}/unregister"
+	// FaultsPath is the JSON API path that returns the failures injected into poet and post clients
+	// on GET and replaces them on PUT, see activation.Faults.
+	FaultsPath = "/v1/admin/faults"
+	// PeersPath is the JSON API path that returns details of connected peers that are not
+	// included in PeerInfoStream, see PeerResponse.
+	PeersPath = "/v1/admin/peers"
 )
 
 // AdminService exposes endpoints for node administration.
@@ -38,11 +64,46 @@ type AdminService struct {
 	dataDir string
 	recover func()
 	p       peers
+	poets   poetResidue
+	certs   certificateRefresher
+	hare    []hareSigners
+	faults  http.Handler
+}
+
+type AdminServiceOpt func(*AdminService)
+
+// WithPoetResidue enables the endpoint that purges the local state of poets that are not configured anymore.
+func WithPoetResidue(poets poetResidue) AdminServiceOpt {
+	return func(a *AdminService) {
+		a.poets = poets
+	}
+}
+
+// WithCertificateRefresher enables the endpoint that replaces the stored poet certificates with new ones.
+func WithCertificateRefresher(certs certificateRefresher) AdminServiceOpt {
+	return func(a *AdminService) {
+		a.certs = certs
+	}
+}
+
+// WithHareSigners enables the endpoint that removes the signing keys of identities from hare.
+// It can be passed for each running hare version, the keys are removed from all of them.
+func WithHareSigners(hare hareSigners) AdminServiceOpt {
+	return func(a *AdminService) {
+		a.hare = append(a.hare, hare)
+	}
+}
+
+// WithFaultInjector enables the endpoint that controls the failures injected into poet and post clients.
+func WithFaultInjector(faults http.Handler) AdminServiceOpt {
+	return func(a *AdminService) {
+		a.faults = faults
+	}
 }
 
 // NewAdminService creates a new admin grpc service.
-func NewAdminService(db sql.StateDatabase, dataDir string, p peers) *AdminService {
-	return &AdminService{
+func NewAdminService(db sql.StateDatabase, dataDir string, p peers, opts ...AdminServiceOpt) *AdminService {
+	a := &AdminService{
 		db:      db,
 		dataDir: dataDir,
 		recover: func() {
@@ -54,6 +115,10 @@ func NewAdminService(db sql.StateDatabase, dataDir string, p peers) *AdminServic
 		},
 		p: p,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // RegisterService registers this service with a grpc server instance.
@@ -65,6 +130,44 @@ func (a *AdminService) RegisterHandlerService(mux *runtime.ServeMux) error {
 	return pb.RegisterAdminServiceHandlerServer(context.Background(), mux, a)
 }
 
+// RegisterDebugHandlers registers the endpoints of the admin service that have no counterpart
+// in the api protobufs, they are served only on the debug JSON listener.
+func (a *AdminService) RegisterDebugHandlers(mux *runtime.ServeMux) error {
+	if err := mux.HandlePath(http.MethodPost, SnapshotPath, a.snapshot); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, PeersPath, a.peers); err != nil {
+		return err
+	}
+	if a.poets != nil {
+		if err := mux.HandlePath(http.MethodPost, PoetsPurgePath, a.purgePoets); err != nil {
+			return err
+		}
+	}
+	if a.certs != nil {
+		if err := mux.HandlePath(http.MethodPost, CertificatesRecertifyPath, a.recertify); err != nil {
+			return err
+		}
+	}
+	if len(a.hare) > 0 {
+		if err := mux.HandlePath(http.MethodPost, HareUnregisterPath, a.unregisterHare); err != nil {
+			return err
+		}
+	}
+	if a.faults != nil {
+		serveFaults := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			a.faults.ServeHTTP(w, r)
+		}
+		if err := mux.HandlePath(http.MethodGet, FaultsPath, serveFaults); err != nil {
+			return err
+		}
+		if err := mux.HandlePath(http.MethodPut, FaultsPath, serveFaults); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // String returns the name of this service.
 func (a *AdminService) String() string {
 	return "AdminService"
@@ -118,6 +221,90 @@ func (a *AdminService) CheckpointStream(
 	}
 }
 
+// SnapshotResponse is returned by the snapshot endpoint of the admin service.
+type SnapshotResponse struct {
+	// Path is the directory with the database copy and the manifest.
+	Path     string               `json:"path"`
+	Manifest *dbsnapshot.Manifest `json:"manifest"`
+}
+
+// snapshot writes a point-in-time copy of the state database into the data directory.
+func (a *AdminService) snapshot(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	dir, manifest, err := dbsnapshot.Create(r.Context(), a.db, a.dataDir, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create snapshot: %s", err), http.StatusInternalServerError)
+		return
+	}
+	ctxzap.Info(r.Context(), "created database snapshot",
+		zap.String("path", dir),
+		zap.Uint32("processed", manifest.Processed.Uint32()),
+		zap.String("checksum", manifest.Checksum),
+	)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(SnapshotResponse{Path: dir, Manifest: manifest}); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write snapshot response", zap.Error(err))
+	}
+}
+
+// PoetsPurgeResponse is returned by the poets purge endpoint of the admin service.
+type PoetsPurgeResponse struct {
+	Purged []activation.PoetResidue `json:"purged"`
+}
+
+// purgePoets deletes the registrations and certificates of poets that are not configured anymore.
+func (a *AdminService) purgePoets(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	purged, err := a.poets.Purge(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to purge poets: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(PoetsPurgeResponse{Purged: purged}); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write poets purge response", zap.Error(err))
+	}
+}
+
+// CertificatesRecertifyResponse is returned by the recertify endpoint of the admin service.
+type CertificatesRecertifyResponse struct {
+	Certificates []activation.CertificateRefresh `json:"certificates"`
+}
+
+// recertify replaces all stored poet certificates with new ones, a certificate is kept if the refresh fails.
+func (a *AdminService) recertify(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	certs, err := a.certs.RecertifyAll(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to recertify: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(CertificatesRecertifyResponse{Certificates: certs}); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write recertify response", zap.Error(err))
+	}
+}
+
+// unregisterHare removes the signing key of the identity from hare. The identity doesn't join
+// future sessions, and running sessions stop producing messages for it at the next round boundary.
+func (a *AdminService) unregisterHare(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	raw, err := hex.DecodeString(params["id"])
+	if err != nil || len(raw) != types.NodeIDSize {
+		http.Error(w, fmt.Sprintf("failed to parse node id `%s`", params["id"]), http.StatusBadRequest)
+		return
+	}
+	id := types.BytesToNodeID(raw)
+	registered := false
+	for _, hare := range a.hare {
+		if hare.Unregister(id) {
+			registered = true
+		}
+	}
+	if !registered {
+		http.Error(w, fmt.Sprintf("identity %s is not registered in hare", id), http.StatusNotFound)
+		return
+	}
+	ctxzap.Info(r.Context(), "unregistered identity from hare", zap.Stringer("id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (a *AdminService) Recover(ctx context.Context, _ *pb.RecoverRequest) (*emptypb.Empty, error) {
 	ctxzap.Info(ctx, "going to recover from checkpoint")
 	a.recover()
@@ -156,6 +343,50 @@ func (a *AdminService) EventsStream(_ *pb.EventStreamRequest, stream pb.AdminSer
 	}
 }
 
+// PeerResponse is returned for every connected peer by the peers endpoint of the admin service.
+// RequestAnomalies is the number of times the peer was flagged for sending requests with anomalous sizes.
+// Capabilities are the protocols the peer has successfully served at least one request for, sorted.
+type PeerResponse struct {
+	ID               string   `json:"id"`
+	RequestAnomalies int64    `json:"request_anomalies"`
+	Capabilities     []string `json:"capabilities"`
+}
+
+// peers returns details of connected peers that the admin service proto has no fields for.
+func (a *AdminService) peers(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if a.p == nil {
+		http.Error(w, "peers are not available", http.StatusServiceUnavailable)
+		return
+	}
+	capabilities := make(map[p2p.Peer][]string)
+	for proto, served := range a.p.Capabilities() {
+		for _, p := range served {
+			capabilities[p] = append(capabilities[p], string(proto))
+		}
+	}
+	resp := []PeerResponse{}
+	for _, p := range a.p.GetPeers() {
+		info := a.p.ConnectedPeerInfo(p)
+		if info == nil {
+			continue
+		}
+		protos := capabilities[p]
+		if protos == nil {
+			protos = []string{}
+		}
+		slices.Sort(protos)
+		resp = append(resp, PeerResponse{
+			ID:               info.ID.String(),
+			RequestAnomalies: info.RequestAnomalies,
+			Capabilities:     protos,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write peers response", zap.Error(err))
+	}
+}
+
 func (a *AdminService) PeerInfoStream(_ *emptypb.Empty, stream pb.AdminService_PeerInfoStreamServer) error {
 	for _, p := range a.p.GetPeers() {
 		select {
@@ -0,0 +1,46 @@
+//go:build faultinjection
+
+package grpcserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestAdminService_Faults(t *testing.T) {
+	faults := activation.NewFaultInjector(zaptest.NewLogger(t))
+	svc := NewAdminService(statesql.InMemoryTest(t), t.TempDir(), nil, WithFaultInjector(faults))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	url := fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, FaultsPath)
+	injected := activation.Faults{DropPoetSubmissions: true, PostProofDelay: time.Minute}
+	body, err := json.Marshal(injected)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, injected, faults.Get())
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got activation.Faults
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, injected, got)
+}
@@ -2,23 +2,33 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/libp2p/go-libp2p/core/protocol"
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/spacemeshos/go-spacemesh/activation"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/accounts"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	dbsnapshot "github.com/spacemeshos/go-spacemesh/sql/snapshot"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 )
 
@@ -111,6 +121,103 @@ func TestAdminService_CheckpointError(t *testing.T) {
 	require.ErrorContains(t, err, sql.ErrNotFound.Error())
 }
 
+func TestAdminService_Snapshot(t *testing.T) {
+	db := statesql.InMemoryTest(t)
+	require.NoError(t, layers.SetProcessed(db, 7))
+	dataDir := t.TempDir()
+	svc := NewAdminService(db, dataDir, nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	body, status := callEndpoint(ctx, t, fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, SnapshotPath), nil)
+	require.Equal(t, http.StatusOK, status)
+
+	var resp SnapshotResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Equal(t, types.LayerID(7), resp.Manifest.Processed)
+	require.True(t, strings.HasPrefix(resp.Path, dataDir))
+	require.FileExists(t, filepath.Join(resp.Path, dbsnapshot.DatabaseFile))
+	require.FileExists(t, filepath.Join(resp.Path, dbsnapshot.ManifestFile))
+}
+
+func TestAdminService_PurgePoets(t *testing.T) {
+	poets := NewMockpoetResidue(gomock.NewController(t))
+	svc := NewAdminService(statesql.InMemoryTest(t), t.TempDir(), nil, WithPoetResidue(poets))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	purged := []activation.PoetResidue{{Address: "http://removed", Registrations: 1, Certificates: 2}}
+	poets.EXPECT().Purge(gomock.Any()).Return(purged, nil)
+	body, status := callEndpoint(ctx, t, fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, PoetsPurgePath), nil)
+	require.Equal(t, http.StatusOK, status)
+
+	var resp PoetsPurgeResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Equal(t, purged, resp.Purged)
+}
+
+func TestAdminService_Recertify(t *testing.T) {
+	certs := NewMockcertificateRefresher(gomock.NewController(t))
+	svc := NewAdminService(statesql.InMemoryTest(t), t.TempDir(), nil, WithCertificateRefresher(certs))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	expiration := time.Unix(1700000000, 0).UTC()
+	refreshed := []activation.CertificateRefresh{
+		{NodeID: types.RandomNodeID(), Certifier: "http://certifier", Expiration: &expiration},
+		{NodeID: types.RandomNodeID(), Poet: "http://poet", Certifier: "http://certifier", Error: "unavailable"},
+	}
+	certs.EXPECT().RecertifyAll(gomock.Any()).Return(refreshed, nil)
+	body, status := callEndpoint(ctx, t, fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, CertificatesRecertifyPath), nil)
+	require.Equal(t, http.StatusOK, status)
+
+	var resp CertificatesRecertifyResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Equal(t, refreshed, resp.Certificates)
+}
+
+func TestAdminService_HareUnregister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hare3 := NewMockhareSigners(ctrl)
+	hare4 := NewMockhareSigners(ctrl)
+	svc := NewAdminService(statesql.InMemoryTest(t), t.TempDir(), nil,
+		WithHareSigners(hare3), WithHareSigners(hare4))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	unregister := func(id string) (string, int) {
+		url := fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, strings.Replace(HareUnregisterPath, "{id}", id, 1))
+		resp, err := http.Post(url, "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body), resp.StatusCode
+	}
+	id := types.RandomNodeID()
+	hare3.EXPECT().Unregister(id).Return(true)
+	hare4.EXPECT().Unregister(id).Return(false)
+	_, status := unregister(hex.EncodeToString(id.Bytes()))
+	require.Equal(t, http.StatusNoContent, status)
+
+	unknown := types.RandomNodeID()
+	hare3.EXPECT().Unregister(unknown).Return(false)
+	hare4.EXPECT().Unregister(unknown).Return(false)
+	body, status := unregister(hex.EncodeToString(unknown.Bytes()))
+	require.Equal(t, http.StatusNotFound, status)
+	require.Contains(t, body, "is not registered")
+
+	body, status = unregister("invalid")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, body, "failed to parse node id `invalid`")
+}
+
 func TestAdminService_Recovery(t *testing.T) {
 	db := statesql.InMemory()
 	recoveryCalled := atomic.Bool{}
@@ -231,3 +338,33 @@ func TestAdminService_PeerInfo(t *testing.T) {
 	_, err = stream.Recv()
 	require.ErrorIs(t, err, io.EOF)
 }
+
+func TestAdminService_Peers(t *testing.T) {
+	p := NewMockpeers(gomock.NewController(t))
+	svc := NewAdminService(statesql.InMemoryTest(t), t.TempDir(), p)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	p1 := p2p.Peer("p1")
+	p2 := p2p.Peer("p2")
+	p.EXPECT().GetPeers().Return([]p2p.Peer{p1, p2})
+	p.EXPECT().Capabilities().Return(map[protocol.ID][]p2p.Peer{
+		"/ax/1": {p1, p2},
+		"/ab/1": {p1},
+		"/hs/1": {p2},
+	})
+	p.EXPECT().ConnectedPeerInfo(p1).Return(&p2p.PeerInfo{ID: p1, RequestAnomalies: 3})
+	p.EXPECT().ConnectedPeerInfo(p2).Return(nil) // disconnected
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, PeersPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got []PeerResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, []PeerResponse{{
+		ID:               p1.String(),
+		RequestAnomalies: 3,
+		Capabilities:     []string{"/ab/1", "/ax/1"},
+	}}, got)
+}
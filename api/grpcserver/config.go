@@ -6,21 +6,28 @@ import (
 )
 
 type Config struct {
-	PublicServices         []Service
-	PublicListener         string `mapstructure:"grpc-public-listener"`
-	PrivateServices        []Service
-	PrivateListener        string `mapstructure:"grpc-private-listener"`
-	PostServices           []Service
-	PostListener           string    `mapstructure:"grpc-post-listener"`
-	TLSServices            []Service `mapstructure:"grpc-tls-services"`
-	TLSListener            string    `mapstructure:"grpc-tls-listener"`
-	TLSCACert              string    `mapstructure:"grpc-tls-ca-cert"`
-	TLSCert                string    `mapstructure:"grpc-tls-cert"`
-	TLSKey                 string    `mapstructure:"grpc-tls-key"`
-	GrpcSendMsgSize        int       `mapstructure:"grpc-send-msg-size"`
-	GrpcRecvMsgSize        int       `mapstructure:"grpc-recv-msg-size"`
-	JSONListener           string    `mapstructure:"grpc-json-listener"`
-	JSONCorsAllowedOrigins []string  `mapstructure:"grpc-cors-allowed-origins"`
+	PublicServices  []Service
+	PublicListener  string `mapstructure:"grpc-public-listener"`
+	PrivateServices []Service
+	PrivateListener string `mapstructure:"grpc-private-listener"`
+	PostServices    []Service
+	PostListener    string    `mapstructure:"grpc-post-listener"`
+	TLSServices     []Service `mapstructure:"grpc-tls-services"`
+	TLSListener     string    `mapstructure:"grpc-tls-listener"`
+	TLSCACert       string    `mapstructure:"grpc-tls-ca-cert"`
+	TLSCert         string    `mapstructure:"grpc-tls-cert"`
+	TLSKey          string    `mapstructure:"grpc-tls-key"`
+	GrpcSendMsgSize int       `mapstructure:"grpc-send-msg-size"`
+	GrpcRecvMsgSize int       `mapstructure:"grpc-recv-msg-size"`
+	JSONListener    string    `mapstructure:"grpc-json-listener"`
+	// DebugJSONListener exposes the endpoints of the enabled services that have no counterpart
+	// in the api protobufs via HTTP/JSON, see DebugServiceAPI. It is disabled by default.
+	// The listener is not authenticated and serves state-changing requests, e.g. to purge poets
+	// or to unregister an identity from hare. Such requests must have the application/json content
+	// type, so that they can't be sent as simple cross-origin requests by web pages. Only enable it
+	// on a loopback address.
+	DebugJSONListener      string   `mapstructure:"grpc-debug-json-listener"`
+	JSONCorsAllowedOrigins []string `mapstructure:"grpc-cors-allowed-origins"`
 
 	SmesherStreamInterval time.Duration `mapstructure:"smesherstreaminterval"`
 }
@@ -2,8 +2,14 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -18,17 +24,222 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/hare3"
+	"github.com/spacemeshos/go-spacemesh/layerpatrol"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/accounts"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/haremsgs"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/hareoutputs"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/harestats"
 )
 
+// HareOutputPath is the JSON API path that returns the set of proposals agreed by hare in a layer,
+// see HareOutputResponse. It is served only on the debug JSON listener.
+const HareOutputPath = "/v1/debug/hare/output/{layer}"
+
+// HareOutputResponse is the set of proposals agreed by hare in a layer.
+type HareOutputResponse struct {
+	Layer uint32 `json:"layer"`
+	// Hash is the hex encoded hash of the sorted proposal ids.
+	Hash string `json:"hash"`
+	// Proposals are hex encoded proposal ids, sorted.
+	Proposals []string `json:"proposals"`
+}
+
+// HareEligibilityPath is the JSON API path that returns the participation of an identity in the
+// hare committees of an epoch, see HareEligibilityResponse. The identity is the hex encoded node ID.
+// It is served only on the debug JSON listener.
+const HareEligibilityPath = "/v1/debug/hare/eligibility/{epoch}/{id}"
+
+// HareEligibilityResponse is the participation of an identity in the hare committees of an epoch.
+type HareEligibilityResponse struct {
+	Epoch uint32 `json:"epoch"`
+	// ID is the hex encoded node ID of the identity.
+	ID string `json:"id"`
+	// Active is false if the identity is not in the active set of the epoch used by hare.
+	Active bool `json:"active"`
+	// Weight is the weight of the identity, after the eligibility weight cap is applied.
+	Weight      uint64 `json:"weight"`
+	TotalWeight uint64 `json:"total_weight"`
+	Committee   uint16 `json:"committee"`
+	// ExpectedSeats is the expected number of seats of the identity in the committee of a round.
+	ExpectedSeats float64 `json:"expected_seats"`
+}
+
+// HareCommitteePath is the JSON API path that returns the expected membership of every identity of
+// the active set in the hare committee of a layer, see HareSeatsResponse.
+const HareCommitteePath = "/v1/debug/hare/committee/{layer}"
+
+// HareSeatsResponse is the expected membership of an identity in the hare committee of a round.
+type HareSeatsResponse struct {
+	// ID is the hex encoded node ID of the identity.
+	ID     string `json:"id"`
+	Weight uint64 `json:"weight"`
+	// Expected is the mean number of eligibilities of the identity.
+	Expected float64 `json:"expected"`
+	// Eligible is the probability that the identity gets at least one eligibility.
+	Eligible float64 `json:"eligible"`
+}
+
+// HareMessagesPath is the JSON API path that returns the archived hare messages of a layer,
+// see HareMessageResponse. The messages can be filtered with the iter, round (name of the round)
+// and sender (hex encoded node ID) query parameters.
+const HareMessagesPath = "/v1/debug/hare/messages/{layer}"
+
+// HareMessageResponse is a validated hare message.
+type HareMessageResponse struct {
+	Layer     uint32 `json:"layer"`
+	Iteration uint8  `json:"iteration"`
+	Round     string `json:"round"`
+	// Sender is the hex encoded node ID of the sender.
+	Sender string `json:"sender"`
+	// Proposals are hex encoded proposal ids, set in preround and propose messages.
+	Proposals []string `json:"proposals,omitempty"`
+	// Reference is the hex encoded hash of the proposals, set in commit and notify messages.
+	Reference string `json:"reference,omitempty"`
+	// Eligibilities is the number of eligibilities of the sender in the round.
+	Eligibilities uint16 `json:"eligibilities"`
+}
+
+// HareParticipationPath is the JSON API path that returns the first layer in which every identity
+// registered in hare participates, see HareParticipationResponse.
+const HareParticipationPath = "/v1/debug/hare/participation"
+
+// HareParticipationResponse is the participation of an identity in hare.
+type HareParticipationResponse struct {
+	// ID is the hex encoded node ID of the identity.
+	ID    string `json:"id"`
+	Layer uint32 `json:"layer"`
+	// Reason is one of hare3.ParticipationReason.
+	Reason string `json:"reason"`
+}
+
+// HareStatsPath is the JSON API path that returns the summary of the hare execution in a layer,
+// see HareStatsResponse.
+const HareStatsPath = "/v1/debug/hare/stats/{layer}"
+
+// HareStatsRangePath is the JSON API path that returns the summaries of the hare executions
+// in layers [from, to], ordered by layer. At most maxHareStatsRange layers are served at once.
+const HareStatsRangePath = "/v1/debug/hare/stats/{from}/{to}"
+
+const maxHareStatsRange = 1000
+
+// HareStatsResponse is the summary of the hare execution in a layer.
+type HareStatsResponse struct {
+	Layer uint32 `json:"layer"`
+	// Iterations is the number of iterations that were completed.
+	Iterations uint8  `json:"iterations"`
+	Threshold  uint16 `json:"threshold"`
+	// Preround is the number of proposals that crossed the threshold in preround with each grade,
+	// starting with grade1.
+	Preround []uint16 `json:"preround"`
+	// CommitMargin and NotifyMargin are the largest number of votes for a single reference
+	// minus the threshold in the last completed iteration.
+	CommitMargin int32 `json:"commit_margin"`
+	NotifyMargin int32 `json:"notify_margin"`
+}
+
+// LayersWaitingPath is the JSON API path that returns the layers block generation is waiting for,
+// with the reason for it, see LayerStatusResponse.
+const LayersWaitingPath = "/v1/debug/layers/waiting"
+
+// LayerStatusPath is the JSON API path that returns the reason block generation is waiting for a layer,
+// see LayerStatusResponse.
+const LayerStatusPath = "/v1/debug/layers/{layer}/status"
+
+// LayerStatusResponse describes why block generation is waiting for a layer.
+type LayerStatusResponse struct {
+	Layer uint32 `json:"layer"`
+	// Reason is one of the names of layerpatrol.WaitReason.
+	Reason string `json:"reason"`
+	// Iteration and Error are set only if hare failed for the layer.
+	Iteration uint8  `json:"iteration,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// DBStatsPath is the JSON API path that returns the size and number of rows of the tables
+// of the state database, collected by the database metrics.
+const DBStatsPath = "/v1/debug/db/stats"
+
+// DBStatsResponse is the snapshot of the state database statistics.
+type DBStatsResponse struct {
+	// Collected is the time of the collection, zero if nothing was collected yet.
+	Collected time.Time `json:"collected"`
+	// TotalSize is zero if sqlite is compiled without dbstat.
+	TotalSize int64             `json:"total_size"`
+	Tables    []DBTableResponse `json:"tables"`
+}
+
+// DBTableResponse is the size and number of rows of a table.
+// Rows is zero if counting rows is disabled, Size is zero if sqlite is compiled without dbstat.
+type DBTableResponse struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+	Size int64  `json:"size"`
+}
+
+// DebugServiceOpt configures the debug service.
+type DebugServiceOpt func(*DebugService)
+
+// WithHareCommittee enables the hare eligibility endpoint, committee returns the size of
+// the hare committee in a layer.
+func WithHareCommittee(committee func(types.LayerID) uint16) DebugServiceOpt {
+	return func(d *DebugService) {
+		d.committee = committee
+	}
+}
+
+// WithHareArchive enables the endpoint that serves archived hare messages.
+func WithHareArchive(archive hareArchive) DebugServiceOpt {
+	return func(d *DebugService) {
+		d.archive = archive
+	}
+}
+
+// WithHareParticipation enables the endpoint that reports when identities participate in hare.
+func WithHareParticipation(participation hareParticipation) DebugServiceOpt {
+	return func(d *DebugService) {
+		d.participation = participation
+	}
+}
+
+// WithHareStats enables the endpoints that serve summaries of hare executions.
+func WithHareStats(stats hareStats) DebugServiceOpt {
+	return func(d *DebugService) {
+		d.hareStats = stats
+	}
+}
+
+// WithLayerPatrol enables the endpoints that report why block generation is waiting for layers.
+func WithLayerPatrol(patrol layerPatrol) DebugServiceOpt {
+	return func(d *DebugService) {
+		d.patrol = patrol
+	}
+}
+
+// WithDBStats enables the endpoint that serves the state database statistics.
+func WithDBStats(stats dbStats) DebugServiceOpt {
+	return func(d *DebugService) {
+		d.dbStats = stats
+	}
+}
+
 // DebugService exposes global state data, output from the STF.
 type DebugService struct {
 	db       sql.StateDatabase
+	localDB  sql.LocalDatabase
 	conState conservativeState
 	netInfo  networkInfo
 	oracle   oracle
 	loggers  map[string]*zap.AtomicLevel
+
+	committee func(types.LayerID) uint16
+	patrol    layerPatrol
+	archive   hareArchive
+	hareStats hareStats
+	dbStats   dbStats
+
+	participation hareParticipation
 }
 
 // RegisterService registers this service with a grpc server instance.
@@ -40,22 +251,60 @@ func (d *DebugService) RegisterHandlerService(mux *runtime.ServeMux) error {
 	return pb.RegisterDebugServiceHandlerServer(context.Background(), mux, d)
 }
 
+// RegisterDebugHandlers registers the endpoints of the debug service that have no counterpart
+// in the api protobufs, they are served only on the debug JSON listener.
+func (d *DebugService) RegisterDebugHandlers(mux *runtime.ServeMux) error {
+	if err := mux.HandlePath(http.MethodGet, HareOutputPath, d.hareOutput); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, HareEligibilityPath, d.hareEligibility); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, HareCommitteePath, d.hareCommittee); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, HareMessagesPath, d.hareMessages); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, HareParticipationPath, d.hareParticipation); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, HareStatsPath, d.hareLayerStats); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, HareStatsRangePath, d.hareStatsRange); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, LayersWaitingPath, d.layersWaiting); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, DBStatsPath, d.dbStatistics); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodGet, LayerStatusPath, d.layerStatus)
+}
+
 // String returns the name of this service.
 func (d *DebugService) String() string {
 	return "DebugService"
 }
 
 // NewDebugService creates a new grpc service using config data.
-func NewDebugService(db sql.StateDatabase, conState conservativeState, host networkInfo, oracle oracle,
-	loggers map[string]*zap.AtomicLevel,
+func NewDebugService(db sql.StateDatabase, localDB sql.LocalDatabase, conState conservativeState, host networkInfo,
+	oracle oracle, loggers map[string]*zap.AtomicLevel, opts ...DebugServiceOpt,
 ) *DebugService {
-	return &DebugService{
+	d := &DebugService{
 		db:       db,
+		localDB:  localDB,
 		conState: conState,
 		netInfo:  host,
 		oracle:   oracle,
 		loggers:  loggers,
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // Accounts returns current counter and balance for all accounts.
@@ -247,3 +496,350 @@ func convertReachability(r network.Reachability) pb.NetworkInfoResponse_Reachabi
 		return pb.NetworkInfoResponse_ReachabilityUnknown
 	}
 }
+
+// hareOutput serves the set of proposals agreed by hare in a layer, so that the block of the layer can be
+// verified against it.
+func (d *DebugService) hareOutput(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	layer, err := strconv.ParseUint(params["layer"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse layer `%s`: %s", params["layer"], err), http.StatusBadRequest)
+		return
+	}
+	output, err := hareoutputs.Get(d.localDB, types.LayerID(layer))
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		http.Error(w, fmt.Sprintf("no hare output in layer %d", layer), http.StatusNotFound)
+		return
+	case err != nil:
+		ctxzap.Error(r.Context(), "unable to fetch hare output", zap.Uint64("layer", layer), zap.Error(err))
+		http.Error(w, "error fetching hare output", http.StatusInternalServerError)
+		return
+	}
+	resp := HareOutputResponse{
+		Layer:     output.Layer.Uint32(),
+		Hash:      hex.EncodeToString(output.Hash[:]),
+		Proposals: make([]string, 0, len(output.Proposals)),
+	}
+	for _, id := range output.Proposals {
+		resp.Proposals = append(resp.Proposals, hex.EncodeToString(id[:]))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write hare output response", zap.Error(err))
+	}
+}
+
+// hareParticipation serves the first layer in which every identity registered in hare participates,
+// ordered by identity.
+func (d *DebugService) hareParticipation(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if d.participation == nil {
+		http.Error(w, "hare is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	participation, err := d.participation.Participation()
+	if err != nil {
+		ctxzap.Error(r.Context(), "unable to check hare participation", zap.Error(err))
+		http.Error(w, "error checking hare participation", http.StatusInternalServerError)
+		return
+	}
+	resp := make([]HareParticipationResponse, 0, len(participation))
+	for _, p := range participation {
+		resp = append(resp, HareParticipationResponse{
+			ID:     hex.EncodeToString(p.NodeID.Bytes()),
+			Layer:  p.Layer.Uint32(),
+			Reason: string(p.Reason),
+		})
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i].ID < resp[j].ID })
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write hare participation response", zap.Error(err))
+	}
+}
+
+func hareStatsResponse(stats *harestats.Stats) HareStatsResponse {
+	return HareStatsResponse{
+		Layer:        stats.Layer.Uint32(),
+		Iterations:   stats.Iterations,
+		Threshold:    stats.Threshold,
+		Preround:     stats.Preround[:],
+		CommitMargin: stats.CommitMargin,
+		NotifyMargin: stats.NotifyMargin,
+	}
+}
+
+// hareLayerStats serves the summary of the hare execution in a layer.
+func (d *DebugService) hareLayerStats(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if d.hareStats == nil {
+		http.Error(w, "hare stats are not configured", http.StatusServiceUnavailable)
+		return
+	}
+	layer, err := strconv.ParseUint(params["layer"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse layer `%s`: %s", params["layer"], err), http.StatusBadRequest)
+		return
+	}
+	stats, err := d.hareStats.Get(types.LayerID(layer))
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		http.Error(w, fmt.Sprintf("no hare stats in layer %d", layer), http.StatusNotFound)
+		return
+	case err != nil:
+		ctxzap.Error(r.Context(), "unable to fetch hare stats", zap.Uint64("layer", layer), zap.Error(err))
+		http.Error(w, "error fetching hare stats", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(hareStatsResponse(stats)); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write hare stats response", zap.Error(err))
+	}
+}
+
+// hareStatsRange serves the summaries of the hare executions in a range of layers.
+func (d *DebugService) hareStatsRange(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if d.hareStats == nil {
+		http.Error(w, "hare stats are not configured", http.StatusServiceUnavailable)
+		return
+	}
+	from, err := strconv.ParseUint(params["from"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse layer `%s`: %s", params["from"], err), http.StatusBadRequest)
+		return
+	}
+	to, err := strconv.ParseUint(params["to"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse layer `%s`: %s", params["to"], err), http.StatusBadRequest)
+		return
+	}
+	if to < from || to-from >= maxHareStatsRange {
+		http.Error(w, fmt.Sprintf("invalid range of layers %d-%d, at most %d layers are served",
+			from, to, maxHareStatsRange), http.StatusBadRequest)
+		return
+	}
+	stats, err := d.hareStats.Range(types.LayerID(from), types.LayerID(to))
+	if err != nil {
+		ctxzap.Error(r.Context(), "unable to fetch hare stats",
+			zap.Uint64("from", from), zap.Uint64("to", to), zap.Error(err))
+		http.Error(w, "error fetching hare stats", http.StatusInternalServerError)
+		return
+	}
+	resp := make([]HareStatsResponse, 0, len(stats))
+	for _, s := range stats {
+		resp = append(resp, hareStatsResponse(s))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write hare stats response", zap.Error(err))
+	}
+}
+
+// hareMessages serves the archived hare messages of a layer, ordered by iteration, round and sender.
+func (d *DebugService) hareMessages(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if d.archive == nil {
+		http.Error(w, "hare archive is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	layer, err := strconv.ParseUint(params["layer"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse layer `%s`: %s", params["layer"], err), http.StatusBadRequest)
+		return
+	}
+	filter := haremsgs.Filter{Layer: types.LayerID(layer)}
+	query := r.URL.Query()
+	if query.Has("iter") {
+		iter, err := strconv.ParseUint(query.Get("iter"), 10, 8)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse iter `%s`: %s", query.Get("iter"), err), http.StatusBadRequest)
+			return
+		}
+		filter.Iter = new(uint8)
+		*filter.Iter = uint8(iter)
+	}
+	if query.Has("round") {
+		round, err := hare3.ParseRound(query.Get("round"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse round: %s", err), http.StatusBadRequest)
+			return
+		}
+		filter.Round = new(uint8)
+		*filter.Round = uint8(round)
+	}
+	if query.Has("sender") {
+		raw, err := hex.DecodeString(query.Get("sender"))
+		if err != nil || len(raw) != types.NodeIDSize {
+			http.Error(w, fmt.Sprintf("failed to parse sender `%s`", query.Get("sender")), http.StatusBadRequest)
+			return
+		}
+		sender := types.BytesToNodeID(raw)
+		filter.Sender = &sender
+	}
+	msgs, err := d.archive.Messages(filter)
+	if err != nil {
+		ctxzap.Error(r.Context(), "unable to fetch hare messages", zap.Uint64("layer", layer), zap.Error(err))
+		http.Error(w, "error fetching hare messages", http.StatusInternalServerError)
+		return
+	}
+	resp := make([]HareMessageResponse, 0, len(msgs))
+	for _, msg := range msgs {
+		rmsg := HareMessageResponse{
+			Layer:         msg.Layer.Uint32(),
+			Iteration:     msg.Iter,
+			Round:         msg.Round.String(),
+			Sender:        hex.EncodeToString(msg.Sender.Bytes()),
+			Eligibilities: msg.Eligibility.Count,
+		}
+		for _, id := range msg.Value.Proposals {
+			rmsg.Proposals = append(rmsg.Proposals, hex.EncodeToString(id[:]))
+		}
+		if msg.Value.Reference != nil {
+			rmsg.Reference = hex.EncodeToString(msg.Value.Reference[:])
+		}
+		resp = append(resp, rmsg)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write hare messages response", zap.Error(err))
+	}
+}
+
+func layerStatusResponse(lid types.LayerID, status layerpatrol.LayerStatus) LayerStatusResponse {
+	return LayerStatusResponse{
+		Layer:     lid.Uint32(),
+		Reason:    status.Reason.String(),
+		Iteration: status.Iteration,
+		Error:     status.Error,
+	}
+}
+
+// layersWaiting serves the layers block generation is waiting for, ordered by layer.
+func (d *DebugService) layersWaiting(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if d.patrol == nil {
+		http.Error(w, "layer patrol is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	waiting := d.patrol.Waiting()
+	resp := make([]LayerStatusResponse, 0, len(waiting))
+	for lid, status := range waiting {
+		resp = append(resp, layerStatusResponse(lid, status))
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i].Layer < resp[j].Layer })
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write waiting layers response", zap.Error(err))
+	}
+}
+
+// dbStatistics serves the statistics of the state database from the last collection.
+func (d *DebugService) dbStatistics(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if d.dbStats == nil {
+		http.Error(w, "database metrics are not collected", http.StatusServiceUnavailable)
+		return
+	}
+	stats := d.dbStats.Stats()
+	resp := DBStatsResponse{
+		Collected: stats.Collected,
+		TotalSize: stats.TotalSize,
+		Tables:    make([]DBTableResponse, 0, len(stats.Tables)),
+	}
+	for _, table := range stats.Tables {
+		resp.Tables = append(resp.Tables, DBTableResponse{Name: table.Name, Rows: table.Rows, Size: table.Size})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write database stats response", zap.Error(err))
+	}
+}
+
+// layerStatus serves the reason block generation is waiting for the layer, or StatusNotFound if
+// it's not waiting for it.
+func (d *DebugService) layerStatus(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if d.patrol == nil {
+		http.Error(w, "layer patrol is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	layer, err := strconv.ParseUint(params["layer"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse layer `%s`: %s", params["layer"], err), http.StatusBadRequest)
+		return
+	}
+	status, ok := d.patrol.Status(types.LayerID(layer))
+	if !ok {
+		http.Error(w, fmt.Sprintf("block generation is not waiting for layer %d", layer), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(layerStatusResponse(types.LayerID(layer), status)); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write layer status response", zap.Error(err))
+	}
+}
+
+// hareEligibility serves the participation of an identity in the hare committees of an epoch, so that
+// smeshers can verify how their units translate into hare seats.
+func (d *DebugService) hareEligibility(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if d.committee == nil {
+		http.Error(w, "hare committee is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	epoch, err := strconv.ParseUint(params["epoch"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse epoch `%s`: %s", params["epoch"], err), http.StatusBadRequest)
+		return
+	}
+	raw, err := hex.DecodeString(params["id"])
+	if err != nil || len(raw) != types.NodeIDSize {
+		http.Error(w, fmt.Sprintf("failed to parse node id `%s`", params["id"]), http.StatusBadRequest)
+		return
+	}
+	id := types.BytesToNodeID(raw)
+	committee := d.committee(types.EpochID(epoch).FirstLayer())
+	participation, err := d.oracle.Participation(r.Context(), types.EpochID(epoch), id, int(committee))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get participation in epoch %d: %s", epoch, err), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(HareEligibilityResponse{
+		Epoch:         uint32(epoch),
+		ID:            hex.EncodeToString(id.Bytes()),
+		Active:        participation.Active,
+		Weight:        participation.Weight,
+		TotalWeight:   participation.TotalWeight,
+		Committee:     committee,
+		ExpectedSeats: participation.ExpectedSeats,
+	}); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write hare eligibility response", zap.Error(err))
+	}
+}
+
+// hareCommittee serves the expected membership of every identity of the active set in the hare committee
+// of a layer, ordered by node ID.
+func (d *DebugService) hareCommittee(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if d.committee == nil {
+		http.Error(w, "hare committee is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	layer, err := strconv.ParseUint(params["layer"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse layer `%s`: %s", params["layer"], err), http.StatusBadRequest)
+		return
+	}
+	lid := types.LayerID(layer)
+	seats, err := d.oracle.ExpectedCommittee(r.Context(), lid, int(d.committee(lid)))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get committee in layer %d: %s", layer, err), http.StatusNotFound)
+		return
+	}
+	resp := make([]HareSeatsResponse, 0, len(seats))
+	for _, s := range seats {
+		resp = append(resp, HareSeatsResponse{
+			ID:       hex.EncodeToString(s.ID.Bytes()),
+			Weight:   s.Weight,
+			Expected: s.Expected,
+			Eligible: s.Eligible,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write hare committee response", zap.Error(err))
+	}
+}
@@ -2,8 +2,12 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/libp2p/go-libp2p/core/event"
+	"go.uber.org/zap"
 
 	"github.com/spacemeshos/go-spacemesh/log"
 )
@@ -57,3 +61,40 @@ func closeSubscription(accountSubscription event.Subscription) {
 		log.With().Panic("Failed to close account subscription", log.Err(err))
 	}
 }
+
+// streamJSON writes the events of the subscription as newline delimited JSON, each converted
+// with convert, until the client disconnects or falls behind. It serves the events that have
+// no stream in the proto over the JSON API.
+func streamJSON[T any](w http.ResponseWriter, r *http.Request, sub event.Subscription, convert func(T) any) {
+	if sub == nil {
+		http.Error(w, "event reporting is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	out, full := consumeEvents[T](ctx, sub)
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-full:
+			ctxzap.Info(ctx, "subscriber's event buffer is full, closing the stream")
+			return
+		case ev := <-out:
+			if err := enc.Encode(convert(ev)); err != nil {
+				ctxzap.Warn(ctx, "failed to write event", zap.Error(err))
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
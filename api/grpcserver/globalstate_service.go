@@ -2,7 +2,10 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"slices"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -17,6 +20,22 @@ import (
 	"github.com/spacemeshos/go-spacemesh/events"
 )
 
+// NonceProjectionPath is the JSON API path that returns the nonces of an account used by pending
+// transactions and the next usable nonce, see types.NonceProjection.
+const NonceProjectionPath = "/v1/globalstate/account/{address}/nonce"
+
+// AccountProjectionPath is the JSON API path that returns the projected state of an account.
+// The certainty query parameter selects which pending transactions are accounted for:
+// "mempool" (default), "packed" or "applied", see types.ProjectionCertainty.
+const AccountProjectionPath = "/v1/globalstate/account/{address}/projection"
+
+// AccountProjectionResponse is the projected state of an account.
+type AccountProjectionResponse struct {
+	Certainty string `json:"certainty"`
+	Counter   uint64 `json:"counter"`
+	Balance   uint64 `json:"balance"`
+}
+
 // GlobalStateService exposes global state data, output from the STF.
 type GlobalStateService struct {
 	mesh     meshAPI
@@ -32,6 +51,15 @@ func (s *GlobalStateService) RegisterHandlerService(mux *runtime.ServeMux) error
 	return pb.RegisterGlobalStateServiceHandlerServer(context.Background(), mux, s)
 }
 
+// RegisterDebugHandlers registers the endpoints of the global state service that have no counterpart
+// in the api protobufs, they are served only on the debug JSON listener.
+func (s *GlobalStateService) RegisterDebugHandlers(mux *runtime.ServeMux) error {
+	if err := mux.HandlePath(http.MethodGet, NonceProjectionPath, s.nonceProjection); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodGet, AccountProjectionPath, s.accountProjection)
+}
+
 // String returns the name of the service.
 func (s *GlobalStateService) String() string {
 	return "GlobalStateService"
@@ -60,6 +88,51 @@ func (s *GlobalStateService) GlobalStateHash(
 	}}, nil
 }
 
+// nonceProjection helps wallets that share an account to pick the next nonce, or to find a hole in
+// the nonces of pending transactions that needs to be filled.
+func (s *GlobalStateService) nonceProjection(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	addr, err := types.StringToAddress(params["address"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse address `%s`: %s", params["address"], err), http.StatusBadRequest)
+		return
+	}
+	projection, err := s.conState.GetNonceProjection(addr)
+	if err != nil {
+		ctxzap.Error(r.Context(), "unable to fetch nonce projection", zap.Stringer("address", addr), zap.Error(err))
+		http.Error(w, "error fetching nonce projection", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(projection); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write nonce projection response", zap.Error(err))
+	}
+}
+
+// accountProjection serves the projected state of an account with the requested certainty.
+func (s *GlobalStateService) accountProjection(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	addr, err := types.StringToAddress(params["address"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse address `%s`: %s", params["address"], err), http.StatusBadRequest)
+		return
+	}
+	certainty := types.ProjectMempool
+	if value := r.URL.Query().Get("certainty"); value != "" {
+		certainties := []types.ProjectionCertainty{types.ProjectMempool, types.ProjectPacked, types.ProjectApplied}
+		idx := slices.IndexFunc(certainties, func(c types.ProjectionCertainty) bool { return c.String() == value })
+		if idx < 0 {
+			http.Error(w, fmt.Sprintf("unknown certainty `%s`", value), http.StatusBadRequest)
+			return
+		}
+		certainty = certainties[idx]
+	}
+	counter, balance := s.conState.GetProjectionWithCertainty(addr, certainty)
+	resp := AccountProjectionResponse{Certainty: certainty.String(), Counter: counter, Balance: balance}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write account projection response", zap.Error(err))
+	}
+}
+
 func (s *GlobalStateService) getAccount(addr types.Address) (acct *pb.Account, err error) {
 	balanceActual, err := s.conState.GetBalance(addr)
 	if err != nil {
@@ -2,7 +2,11 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"math"
+	"net/http"
+	"strings"
 	"testing"
 
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
@@ -365,3 +369,77 @@ func TestGlobalStateService(t *testing.T) {
 		})
 	})
 }
+
+func TestGlobalStateService_NonceProjection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conStateAPI := NewMockconservativeState(ctrl)
+	svc := NewGlobalStateService(NewMockmeshAPI(ctrl), conStateAPI)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	addr := types.GenerateAddress(types.RandomBytes(32))
+	url := fmt.Sprintf("http://%s%s", cfg.DebugJSONListener,
+		strings.Replace(NonceProjectionPath, "{address}", addr.String(), 1))
+	expected := &types.NonceProjection{
+		Applied:        2,
+		AppliedBalance: 100,
+		Pending: []types.PendingNonce{
+			{Nonce: 2, ID: types.RandomTransactionID(), Balance: 90, Mempool: true},
+			{Nonce: 4, ID: types.RandomTransactionID(), Balance: 80},
+		},
+		Next:    3,
+		Balance: 90,
+	}
+	conStateAPI.EXPECT().GetNonceProjection(addr).Return(expected, nil)
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got types.NonceProjection
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, *expected, got)
+
+	t.Run("bad address", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener,
+			strings.Replace(NonceProjectionPath, "{address}", "bad", 1)))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestGlobalStateService_AccountProjection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conStateAPI := NewMockconservativeState(ctrl)
+	svc := NewGlobalStateService(NewMockmeshAPI(ctrl), conStateAPI)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	addr := types.GenerateAddress(types.RandomBytes(32))
+	get := func(t *testing.T, address, query string) *http.Response {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s%s", cfg.DebugJSONListener,
+			strings.Replace(AccountProjectionPath, "{address}", address, 1), query))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+
+	conStateAPI.EXPECT().GetProjectionWithCertainty(addr, types.ProjectMempool).Return(uint64(3), uint64(90))
+	resp := get(t, addr.String(), "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got AccountProjectionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, AccountProjectionResponse{Certainty: "mempool", Counter: 3, Balance: 90}, got)
+
+	conStateAPI.EXPECT().GetProjectionWithCertainty(addr, types.ProjectPacked).Return(uint64(2), uint64(95))
+	resp = get(t, addr.String(), "?certainty=packed")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, AccountProjectionResponse{Certainty: "packed", Counter: 2, Balance: 95}, got)
+
+	resp = get(t, addr.String(), "?certainty=certain")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = get(t, "bad", "")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
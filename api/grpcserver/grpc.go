@@ -30,6 +30,13 @@ type ServiceAPI interface {
 	String() string
 }
 
+// DebugServiceAPI is implemented by services with endpoints that have no counterpart in the api
+// protobufs. The endpoints are served only over HTTP/JSON on the debug listener, see Config.DebugJSONListener.
+type DebugServiceAPI interface {
+	ServiceAPI
+	RegisterDebugHandlers(*runtime.ServeMux) error
+}
+
 // Server is a very basic grpc server.
 type Server struct {
 	listener string
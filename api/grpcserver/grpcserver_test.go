@@ -2,6 +2,8 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,9 +11,11 @@ import (
 	"math"
 	"math/big"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,6 +39,7 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/activation/wire"
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/datastore"
@@ -42,15 +47,24 @@ import (
 	vm "github.com/spacemeshos/go-spacemesh/genvm"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
+	"github.com/spacemeshos/go-spacemesh/hare3"
+	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
+	"github.com/spacemeshos/go-spacemesh/layerpatrol"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
 	peerinfomocks "github.com/spacemeshos/go-spacemesh/p2p/peerinfo/mocks"
 	pubsubmocks "github.com/spacemeshos/go-spacemesh/p2p/pubsub/mocks"
 	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/accounts"
 	"github.com/spacemeshos/go-spacemesh/sql/activesets"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/haremsgs"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/hareoutputs"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/harestats"
+	dbmetrics "github.com/spacemeshos/go-spacemesh/sql/metrics"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 	"github.com/spacemeshos/go-spacemesh/system"
 	"github.com/spacemeshos/go-spacemesh/txs"
@@ -302,6 +316,23 @@ func (t *ConStateAPIMock) GetProjection(types.Address) (uint64, uint64) {
 	return accountCounter + 1, accountBalance + 1
 }
 
+func (t *ConStateAPIMock) GetProjectionWithCertainty(
+	addr types.Address,
+	_ types.ProjectionCertainty,
+) (uint64, uint64) {
+	return t.GetProjection(addr)
+}
+
+func (t *ConStateAPIMock) GetNonceProjection(addr types.Address) (*types.NonceProjection, error) {
+	nonce, balance := t.GetProjection(addr)
+	return &types.NonceProjection{
+		Applied:        accountCounter,
+		AppliedBalance: accountBalance,
+		Next:           nonce,
+		Balance:        balance,
+	}, nil
+}
+
 func (t *ConStateAPIMock) GetAllAccounts() (res []*types.Account, err error) {
 	for address, balance := range t.balances {
 		res = append(res, &types.Account{
@@ -329,6 +360,16 @@ func (t *ConStateAPIMock) GetMeshTransaction(id types.TransactionID) (*types.Mes
 	return nil, errors.New("it ain't there")
 }
 
+func (t *ConStateAPIMock) GetTxStatus(id types.TransactionID) (*types.TXStatus, error) {
+	if _, ok := t.returnTx[id]; ok {
+		return &types.TXStatus{State: types.APPLIED, Reason: types.TXReasonApplied, Layer: txReturnLayer}, nil
+	}
+	if _, ok := t.poolByTxId[id]; ok {
+		return &types.TXStatus{State: types.MEMPOOL, Reason: types.TXReasonInMempool}, nil
+	}
+	return nil, errors.New("it ain't there")
+}
+
 func (t *ConStateAPIMock) GetTransactionsByAddress(
 	from, to types.LayerID,
 	account types.Address,
@@ -714,6 +755,381 @@ func TestSmesherService(t *testing.T) {
 	})
 }
 
+func TestSmesherService_ResumeSmeshing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
+	svc := NewSmesherService(
+		smeshingProvider,
+		NewMockpostSupervisor(ctrl),
+		NewMockgrpcPostService(ctrl),
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+		nil,
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+	resume := func(id string) int {
+		url := fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, strings.Replace(ResumeSmeshingPath, "{id}", id, 1))
+		resp, err := http.Post(url, "application/json", nil)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	id := types.RandomNodeID()
+	smeshingProvider.EXPECT().ResumeSmeshing(id).Return(nil)
+	require.Equal(t, http.StatusNoContent, resume(hex.EncodeToString(id.Bytes())))
+
+	smeshingProvider.EXPECT().ResumeSmeshing(id).Return(activation.ErrNotPaused)
+	require.Equal(t, http.StatusConflict, resume(hex.EncodeToString(id.Bytes())))
+
+	smeshingProvider.EXPECT().ResumeSmeshing(id).Return(errors.New("identity is not registered"))
+	require.Equal(t, http.StatusNotFound, resume(hex.EncodeToString(id.Bytes())))
+
+	require.Equal(t, http.StatusBadRequest, resume("bad"))
+	require.Equal(t, http.StatusBadRequest, resume(hex.EncodeToString(id.Bytes()[:10])))
+}
+
+func TestSmesherService_NIPostErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
+	svc := NewSmesherService(
+		smeshingProvider,
+		NewMockpostSupervisor(ctrl),
+		NewMockgrpcPostService(ctrl),
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+		nil,
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	id := types.RandomNodeID()
+	deadline := time.Now().UTC().Truncate(time.Second)
+	smeshingProvider.EXPECT().NIPostErrors().Return(map[types.NodeID]*activation.NIPostError{
+		id: {
+			Phase:        activation.NIPostPhasePoetProof,
+			PublishEpoch: 5,
+			Poet:         "http://poet",
+			Round:        "4",
+			Deadline:     deadline,
+			Retryable:    true,
+			Err:          activation.ErrPoetProofNotReceived,
+		},
+	})
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, NIPostErrorsPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var errs []NIPostErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errs))
+	require.Equal(t, []NIPostErrorResponse{{
+		ID:           hex.EncodeToString(id.Bytes()),
+		Phase:        string(activation.NIPostPhasePoetProof),
+		PublishEpoch: 5,
+		Poet:         "http://poet",
+		Round:        "4",
+		Deadline:     deadline,
+		Retryable:    true,
+		Error:        activation.ErrPoetProofNotReceived.Error(),
+	}}, errs)
+}
+
+func TestSmesherService_Attestations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
+	svc := NewSmesherService(
+		smeshingProvider,
+		NewMockpostSupervisor(ctrl),
+		NewMockgrpcPostService(ctrl),
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+		nil,
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	atxID := types.RandomATXID()
+	attestation := wire.NewSmeshingAttestation(signer, wire.SmeshingStatus{
+		Timestamp: 1700000000,
+		Epoch:     5,
+		ATX:       atxID,
+		NumUnits:  4,
+		PostState: uint8(types.PostStateProving),
+	})
+	smeshingProvider.EXPECT().Attest().Return([]*wire.SmeshingAttestation{attestation}, nil)
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, AttestationsPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var attestations []AttestationResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&attestations))
+	require.Len(t, attestations, 1)
+	require.Equal(t, hex.EncodeToString(signer.NodeID().Bytes()), attestations[0].ID)
+	require.EqualValues(t, 5, attestations[0].Epoch)
+	require.Equal(t, hex.EncodeToString(atxID.Bytes()), attestations[0].ATX)
+	require.EqualValues(t, 4, attestations[0].NumUnits)
+	require.Equal(t, types.PostStateProving.String(), attestations[0].PostState)
+
+	// the attestation can be verified by the operator
+	blob, err := hex.DecodeString(attestations[0].Attestation)
+	require.NoError(t, err)
+	var decoded wire.SmeshingAttestation
+	require.NoError(t, codec.Decode(blob, &decoded))
+	require.True(t, decoded.Verify(signing.NewEdVerifier()))
+	require.Equal(t, *attestation, decoded)
+}
+
+func TestSmesherService_Poets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := NewSmesherService(
+		activation.NewMockSmeshingProvider(ctrl),
+		NewMockpostSupervisor(ctrl),
+		NewMockgrpcPostService(ctrl),
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+		nil,
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func() *http.Response {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, PoetsPath))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	require.Equal(t, http.StatusServiceUnavailable, get().StatusCode)
+
+	poets := NewMockpoetsProvider(ctrl)
+	poets.EXPECT().Poets().Return([]activation.PoetStatus{{
+		Address:  "http://poet",
+		Fallback: true,
+		CycleGap: time.Hour,
+		PoetSettings: activation.PoetSettings{
+			Transport:         activation.PoetTransportGRPC,
+			RequestTimeout:    time.Minute,
+			RequestRetryDelay: time.Second,
+			MaxRequestRetries: 3,
+			MinProofJitter:    5 * time.Second,
+			MaxProofJitter:    10 * time.Second,
+			ProofTimeout:      2 * time.Minute,
+			Tier:              activation.PoetTierSecondary,
+			Weight:            5,
+		},
+	}})
+	svc.SetPoets(poets)
+	resp := get()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var rst []PoetResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rst))
+	require.Equal(t, []PoetResponse{{
+		Address:           "http://poet",
+		Fallback:          true,
+		Transport:         "grpc",
+		CycleGap:          "1h0m0s",
+		RequestTimeout:    "1m0s",
+		RequestRetryDelay: "1s",
+		MaxRequestRetries: 3,
+		MinProofJitter:    "5s",
+		MaxProofJitter:    "10s",
+		ProofTimeout:      "2m0s",
+		Tier:              "secondary",
+		Weight:            5,
+	}}, rst)
+}
+
+func TestSmesherService_PoetsPreflight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	smeshing := activation.NewMockSmeshingProvider(ctrl)
+	svc := NewSmesherService(
+		smeshing,
+		NewMockpostSupervisor(ctrl),
+		NewMockgrpcPostService(ctrl),
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+		nil,
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	id := types.RandomNodeID()
+	post := func(id string) *http.Response {
+		path := strings.Replace(PoetsPreflightPath, "{id}", id, 1)
+		resp, err := http.Post(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, path), "application/json", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	require.Equal(t, http.StatusServiceUnavailable, post(id.String()).StatusCode)
+
+	poets := NewMockpoetsProvider(ctrl)
+	svc.SetPoets(poets)
+	require.Equal(t, http.StatusBadRequest, post("bad").StatusCode)
+	smeshing.EXPECT().SmesherIDs().Return([]types.NodeID{id}).AnyTimes()
+	require.Equal(t, http.StatusNotFound, post(types.RandomNodeID().String()).StatusCode)
+
+	expiration := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	roundStart := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	poets.EXPECT().Preflight(gomock.Any(), id).Return([]activation.PoetPreflight{
+		{
+			Address:        "http://poet",
+			Auth:           activation.PoetAuthCertificate,
+			CertExpiration: &expiration,
+			RoundStart:     roundStart,
+		},
+		{
+			Address:       "http://fallback",
+			Fallback:      true,
+			Auth:          activation.PoetAuthPoW,
+			CertErr:       errors.New("certifier is down"),
+			PowDifficulty: 8,
+			RoundStart:    roundStart,
+		},
+		{
+			Address:    "http://down",
+			Err:        errors.New("unreachable"),
+			RoundStart: roundStart,
+		},
+	})
+	resp := post(id.String())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var rst []PoetPreflightResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rst))
+	require.Equal(t, []PoetPreflightResponse{
+		{Address: "http://poet", Auth: "certificate", CertExpiration: &expiration, RoundStart: roundStart},
+		{
+			Address:       "http://fallback",
+			Fallback:      true,
+			Auth:          "pow",
+			CertError:     "certifier is down",
+			PowDifficulty: 8,
+			RoundStart:    roundStart,
+		},
+		{Address: "http://down", Error: "unreachable", RoundStart: roundStart},
+	}, rst)
+}
+
+func TestSmesherService_Readiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	smeshing := activation.NewMockSmeshingProvider(ctrl)
+	svc := NewSmesherService(
+		smeshing,
+		NewMockpostSupervisor(ctrl),
+		NewMockgrpcPostService(ctrl),
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+		nil,
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	id := types.RandomNodeID()
+	url := func(id string) string {
+		return fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, strings.Replace(ReadinessPath, "{id}", id, 1))
+	}
+	get := func(id string) *http.Response {
+		resp, err := http.Get(url(id))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	post := func(id string) *http.Response {
+		resp, err := http.Post(url(id), "application/json", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	require.Equal(t, http.StatusServiceUnavailable, get(id.String()).StatusCode)
+	require.Equal(t, http.StatusServiceUnavailable, post(id.String()).StatusCode)
+
+	readiness := NewMockreadinessProvider(ctrl)
+	svc.SetReadiness(readiness)
+	require.Equal(t, http.StatusBadRequest, get("bad").StatusCode)
+	smeshing.EXPECT().SmesherIDs().Return([]types.NodeID{id}).AnyTimes()
+	require.Equal(t, http.StatusNotFound, post(types.RandomNodeID().String()).StatusCode)
+	readiness.EXPECT().Report(id).Return(nil, false)
+	require.Equal(t, http.StatusNotFound, get(id.String()).StatusCode)
+
+	report := &activation.ReadinessReport{
+		NodeID:       id,
+		PublishEpoch: 4,
+		Time:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Checks: []activation.ReadinessCheck{
+			{Name: activation.ReadinessPostService},
+			{Name: activation.ReadinessPoets, Err: errors.New("no poet is reachable")},
+			{Name: activation.ReadinessClock, Skipped: true},
+		},
+	}
+	expected := ReadinessResponse{
+		ID:           id.String(),
+		PublishEpoch: 4,
+		Time:         report.Time,
+		Score:        0.5,
+		Checks: []ReadinessCheckResponse{
+			{Name: "post_service"},
+			{Name: "poets", Error: "no poet is reachable"},
+			{Name: "clock", Skipped: true},
+		},
+	}
+	readiness.EXPECT().Check(gomock.Any(), id).Return(report)
+	resp := post(id.String())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var rst ReadinessResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rst))
+	require.Equal(t, expected, rst)
+
+	readiness.EXPECT().Report(id).Return(report, true)
+	resp = get(id.String())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	rst = ReadinessResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rst))
+	require.Equal(t, expected, rst)
+}
+
+func TestSmesherService_PostTooSlow(t *testing.T) {
+	events.CloseEventReporter()
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+
+	ctrl := gomock.NewController(t)
+	svc := NewSmesherService(
+		activation.NewMockSmeshingProvider(ctrl),
+		NewMockpostSupervisor(ctrl),
+		NewMockgrpcPostService(ctrl),
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+		nil,
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, PostTooSlowPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	id := types.RandomNodeID()
+	events.ReportPostTooSlow(events.EventPostTooSlow{
+		Smesher:  id,
+		Duration: 50 * time.Minute,
+		CycleGap: time.Hour,
+		Fallback: true,
+	})
+	var got PostTooSlowResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, PostTooSlowResponse{
+		ID:       hex.EncodeToString(id.Bytes()),
+		Duration: "50m0s",
+		CycleGap: "1h0m0s",
+		Fallback: true,
+	}, got)
+}
+
 func TestMeshService(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	genTime := NewMockgenesisTimeAPI(ctrl)
@@ -1699,7 +2115,7 @@ func TestAccountMeshDataStream_comprehensive(t *testing.T) {
 	// test streaming a tx and an atx that are filtered out
 	// these should not be received
 	require.NoError(t, events.ReportNewTx(0, globalTx2))
-	require.NoError(t, events.ReportNewActivation(globalAtx2))
+	require.NoError(t, events.ReportNewActivation(globalAtx2, p2p.NoPeer))
 
 	_, err = stream.Recv()
 	require.Error(t, err)
@@ -2035,7 +2451,7 @@ func TestDebugService(t *testing.T) {
 		"test": &testLog,
 	}
 
-	svc := NewDebugService(db, conStateAPI, netInfo, mOracle, loggers)
+	svc := NewDebugService(db, localsql.InMemoryTest(t), conStateAPI, netInfo, mOracle, loggers)
 	cfg, cleanup := launchServer(t, svc)
 	t.Cleanup(cleanup)
 
@@ -2215,6 +2631,323 @@ func TestDebugService(t *testing.T) {
 	})
 }
 
+func TestDebugService_HareOutput(t *testing.T) {
+	localDB := localsql.InMemoryTest(t)
+	svc := NewDebugService(statesql.InMemory(), localDB, nil, nil, nil, nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	proposals := types.SortProposalIDs([]types.ProposalID{types.RandomProposalID(), types.RandomProposalID()})
+	output := &hareoutputs.Output{
+		Layer:     11,
+		Hash:      types.CalcProposalHash32Presorted(proposals, nil),
+		Proposals: proposals,
+	}
+	require.NoError(t, hareoutputs.Set(localDB, output))
+
+	get := func(t *testing.T, layer string) *http.Response {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener,
+			strings.Replace(HareOutputPath, "{layer}", layer, 1)))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+
+	resp := get(t, "11")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got HareOutputResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, HareOutputResponse{
+		Layer: 11,
+		Hash:  hex.EncodeToString(output.Hash[:]),
+		Proposals: []string{
+			hex.EncodeToString(proposals[0][:]),
+			hex.EncodeToString(proposals[1][:]),
+		},
+	}, got)
+
+	require.Equal(t, http.StatusNotFound, get(t, "12").StatusCode)
+	require.Equal(t, http.StatusBadRequest, get(t, "bad").StatusCode)
+}
+
+func TestDebugService_HareEligibility(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	oracle := NewMockoracle(ctrl)
+	svc := NewDebugService(statesql.InMemory(), localsql.InMemoryTest(t), nil, nil, oracle, nil,
+		WithHareCommittee(func(layer types.LayerID) uint16 {
+			if layer >= types.EpochID(5).FirstLayer() {
+				return 400
+			}
+			return 50
+		}),
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, epoch, id string) *http.Response {
+		path := strings.Replace(HareEligibilityPath, "{epoch}", epoch, 1)
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, strings.Replace(path, "{id}", id, 1)))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+
+	id := types.RandomNodeID()
+	oracle.EXPECT().Participation(gomock.Any(), types.EpochID(5), id, 400).Return(eligibility.Participation{
+		Active:        true,
+		Weight:        10,
+		TotalWeight:   1000,
+		ExpectedSeats: 4,
+	}, nil)
+	resp := get(t, "5", hex.EncodeToString(id.Bytes()))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got HareEligibilityResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, HareEligibilityResponse{
+		Epoch:         5,
+		ID:            hex.EncodeToString(id.Bytes()),
+		Active:        true,
+		Weight:        10,
+		TotalWeight:   1000,
+		Committee:     400,
+		ExpectedSeats: 4,
+	}, got)
+
+	oracle.EXPECT().Participation(gomock.Any(), types.EpochID(1), id, 50).Return(
+		eligibility.Participation{}, errors.New("empty active set"))
+	require.Equal(t, http.StatusNotFound, get(t, "1", hex.EncodeToString(id.Bytes())).StatusCode)
+	require.Equal(t, http.StatusBadRequest, get(t, "bad", hex.EncodeToString(id.Bytes())).StatusCode)
+	require.Equal(t, http.StatusBadRequest, get(t, "5", "bad").StatusCode)
+}
+
+func TestDebugService_HareCommittee(t *testing.T) {
+	oracle := NewMockoracle(gomock.NewController(t))
+	svc := NewDebugService(statesql.InMemory(), localsql.InMemoryTest(t), nil, nil, oracle, nil,
+		WithHareCommittee(func(types.LayerID) uint16 { return 50 }),
+	)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, layer string) *http.Response {
+		path := strings.Replace(HareCommitteePath, "{layer}", layer, 1)
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, path))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+
+	id := types.RandomNodeID()
+	oracle.EXPECT().ExpectedCommittee(gomock.Any(), types.LayerID(20), 50).Return(
+		[]eligibility.Seats{{ID: id, Weight: 10, Expected: 0.5, Eligible: 0.25}}, nil)
+	resp := get(t, "20")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got []HareSeatsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, []HareSeatsResponse{
+		{ID: hex.EncodeToString(id.Bytes()), Weight: 10, Expected: 0.5, Eligible: 0.25},
+	}, got)
+
+	oracle.EXPECT().ExpectedCommittee(gomock.Any(), types.LayerID(1), 50).Return(nil, errors.New("empty active set"))
+	require.Equal(t, http.StatusNotFound, get(t, "1").StatusCode)
+	require.Equal(t, http.StatusBadRequest, get(t, "bad").StatusCode)
+}
+
+func TestDebugService_HareEligibilityNotConfigured(t *testing.T) {
+	svc := NewDebugService(statesql.InMemory(), localsql.InMemoryTest(t), nil, nil, nil, nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	path := strings.Replace(HareEligibilityPath, "{epoch}", "5", 1)
+	path = strings.Replace(path, "{id}", hex.EncodeToString(types.RandomNodeID().Bytes()), 1)
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, path))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestDebugService_Layers(t *testing.T) {
+	patrol := layerpatrol.New()
+	patrol.SetHareInCharge(12)
+	patrol.HareFailed(12, 3, errors.New("no proposals"))
+	patrol.SetWaiting(11, layerpatrol.ReasonBeaconMissing)
+	svc := NewDebugService(statesql.InMemory(), nil, nil, nil, nil, nil, WithLayerPatrol(patrol))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, path string) *http.Response {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, path))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	failed := LayerStatusResponse{Layer: 12, Reason: "hare failed", Iteration: 3, Error: "no proposals"}
+
+	resp := get(t, LayersWaitingPath)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var waiting []LayerStatusResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&waiting))
+	require.Equal(t, []LayerStatusResponse{{Layer: 11, Reason: "beacon missing"}, failed}, waiting)
+
+	resp = get(t, strings.Replace(LayerStatusPath, "{layer}", "12", 1))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var status LayerStatusResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	require.Equal(t, failed, status)
+
+	resp = get(t, strings.Replace(LayerStatusPath, "{layer}", "13", 1))
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = get(t, strings.Replace(LayerStatusPath, "{layer}", "bad", 1))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestDebugService_HareParticipation(t *testing.T) {
+	participation := NewMockhareParticipation(gomock.NewController(t))
+	svc := NewDebugService(statesql.InMemory(), nil, nil, nil, nil, nil, WithHareParticipation(participation))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	id1 := types.NodeID{1}
+	id2 := types.NodeID{2}
+	participation.EXPECT().Participation().Return([]hare3.Participation{
+		{NodeID: id2, Layer: 11, Reason: hare3.ParticipationNextLayer},
+		{NodeID: id1, Layer: 10, Reason: hare3.ParticipationRunning},
+	}, nil)
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, HareParticipationPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got []HareParticipationResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, []HareParticipationResponse{
+		{ID: hex.EncodeToString(id1.Bytes()), Layer: 10, Reason: "running"},
+		{ID: hex.EncodeToString(id2.Bytes()), Layer: 11, Reason: "next layer"},
+	}, got)
+
+	participation.EXPECT().Participation().Return(nil, errors.New("test"))
+	resp, err = http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, HareParticipationPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestDebugService_HareStats(t *testing.T) {
+	stats := NewMockhareStats(gomock.NewController(t))
+	svc := NewDebugService(statesql.InMemory(), nil, nil, nil, nil, nil, WithHareStats(stats))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, path string) *http.Response {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, path))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	s10 := &harestats.Stats{Layer: 10, Iterations: 1, Threshold: 200, Preround: [5]uint16{1, 2}, CommitMargin: 30}
+	s11 := &harestats.Stats{Layer: 11, Iterations: 2, Threshold: 200, CommitMargin: -5, NotifyMargin: -10}
+	r10 := HareStatsResponse{Layer: 10, Iterations: 1, Threshold: 200, Preround: []uint16{1, 2, 0, 0, 0}, CommitMargin: 30}
+	r11 := HareStatsResponse{
+		Layer: 11, Iterations: 2, Threshold: 200, Preround: make([]uint16, 5), CommitMargin: -5, NotifyMargin: -10,
+	}
+
+	stats.EXPECT().Get(types.LayerID(10)).Return(s10, nil)
+	resp := get(t, strings.Replace(HareStatsPath, "{layer}", "10", 1))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got HareStatsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, r10, got)
+
+	stats.EXPECT().Get(types.LayerID(12)).Return(nil, sql.ErrNotFound)
+	resp = get(t, strings.Replace(HareStatsPath, "{layer}", "12", 1))
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	stats.EXPECT().Range(types.LayerID(10), types.LayerID(12)).Return([]*harestats.Stats{s10, s11}, nil)
+	resp = get(t, strings.NewReplacer("{from}", "10", "{to}", "12").Replace(HareStatsRangePath))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var all []HareStatsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&all))
+	require.Equal(t, []HareStatsResponse{r10, r11}, all)
+
+	resp = get(t, strings.NewReplacer("{from}", "12", "{to}", "10").Replace(HareStatsRangePath))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = get(t, strings.NewReplacer("{from}", "0", "{to}", "5000").Replace(HareStatsRangePath))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestDebugService_DBStats(t *testing.T) {
+	stats := NewMockdbStats(gomock.NewController(t))
+	svc := NewDebugService(statesql.InMemory(), nil, nil, nil, nil, nil, WithDBStats(stats))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	collected := time.Now().UTC().Truncate(time.Second)
+	stats.EXPECT().Stats().Return(dbmetrics.Stats{
+		Collected: collected,
+		TotalSize: 300,
+		Tables:    []dbmetrics.TableStats{{Name: "atxs", Rows: 2, Size: 100}, {Name: "layers", Rows: 3, Size: 200}},
+	})
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, DBStatsPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got DBStatsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, DBStatsResponse{
+		Collected: collected,
+		TotalSize: 300,
+		Tables:    []DBTableResponse{{Name: "atxs", Rows: 2, Size: 100}, {Name: "layers", Rows: 3, Size: 200}},
+	}, got)
+}
+
+func TestDebugService_HareMessages(t *testing.T) {
+	archive := NewMockhareArchive(gomock.NewController(t))
+	svc := NewDebugService(statesql.InMemory(), nil, nil, nil, nil, nil, WithHareArchive(archive))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, path string) *http.Response {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, path))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+	sender := types.RandomNodeID()
+	ref := types.RandomHash()
+	msg := &hare3.Message{Sender: sender}
+	msg.Layer = 7
+	msg.Iter = 1
+	msg.Round = 6 // commit
+	msg.Value.Reference = &ref
+	msg.Eligibility.Count = 2
+
+	iter, round := uint8(1), uint8(6)
+	archive.EXPECT().
+		Messages(haremsgs.Filter{Layer: 7, Iter: &iter, Round: &round, Sender: &sender}).
+		Return([]*hare3.Message{msg}, nil)
+	path := strings.Replace(HareMessagesPath, "{layer}", "7", 1) +
+		"?iter=1&round=commit&sender=" + hex.EncodeToString(sender.Bytes())
+	resp := get(t, path)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var msgs []HareMessageResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&msgs))
+	require.Equal(t, []HareMessageResponse{{
+		Layer:         7,
+		Iteration:     1,
+		Round:         "commit",
+		Sender:        hex.EncodeToString(sender.Bytes()),
+		Reference:     hex.EncodeToString(ref.Bytes()),
+		Eligibilities: 2,
+	}}, msgs)
+
+	resp = get(t, strings.Replace(HareMessagesPath, "{layer}", "7", 1)+"?round=bad")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	svc = NewDebugService(statesql.InMemory(), nil, nil, nil, nil, nil)
+	cfg, cleanup = launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+	resp = get(t, strings.Replace(HareMessagesPath, "{layer}", "7", 1))
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
 func TestEventsReceived(t *testing.T) {
 	events.CloseEventReporter()
 	events.InitializeReporter()
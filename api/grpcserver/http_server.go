@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"mime"
 	"net"
 	"net/http"
 	"time"
@@ -36,6 +37,18 @@ type JSONHTTPServer struct {
 
 	// basic CORS support
 	origins []string
+
+	metricsPrefix string
+}
+
+type JSONHTTPServerOpt func(*JSONHTTPServer)
+
+// WithMetricsPrefix sets the prefix of the metrics of the server, servers that run side by side
+// must use different prefixes.
+func WithMetricsPrefix(prefix string) JSONHTTPServerOpt {
+	return func(s *JSONHTTPServer) {
+		s.metricsPrefix = prefix
+	}
 }
 
 // NewJSONHTTPServer creates a new json http server.
@@ -44,13 +57,19 @@ func NewJSONHTTPServer(
 	listener string,
 	corsAllowedOrigins []string,
 	collectMetrics bool,
+	opts ...JSONHTTPServerOpt,
 ) *JSONHTTPServer {
-	return &JSONHTTPServer{
+	s := &JSONHTTPServer{
 		logger:         lg,
 		listener:       listener,
 		origins:        corsAllowedOrigins,
 		collectMetrics: collectMetrics,
+		metricsPrefix:  metrics.Namespace + "_api",
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Shutdown stops the server.
@@ -90,7 +109,50 @@ func (s *JSONHTTPServer) StartService(
 			return fmt.Errorf("registering service %s with grpc gateway failed: %w", svc, err)
 		}
 	}
+	return s.start(mux)
+}
+
+// StartDebugService starts the server with the debug endpoints of the services, see DebugServiceAPI.
+// Services without debug endpoints are skipped.
+func (s *JSONHTTPServer) StartDebugService(services ...ServiceAPI) error {
+	mux := runtime.NewServeMux()
+	registered := 0
+	for _, svc := range services {
+		debug, ok := svc.(DebugServiceAPI)
+		if !ok {
+			continue
+		}
+		if err := debug.RegisterDebugHandlers(mux); err != nil {
+			return fmt.Errorf("registering debug endpoints of service %s failed: %w", svc, err)
+		}
+		registered++
+	}
+	if registered == 0 {
+		s.logger.Error("not starting debug json service; none of the services has debug endpoints")
+		return errors.New("no services with debug endpoints provided")
+	}
+	return s.start(requireJSON(mux))
+}
+
+// requireJSON rejects requests that may change state unless their content type is JSON.
+// Browsers send cross-origin requests with a JSON body only after a CORS preflight, which isn't
+// allowed by the debug server, so such requests can't be forged by web pages opened on the host.
+func requireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			mediatype, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediatype != "application/json" {
+				http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
+func (s *JSONHTTPServer) start(mux http.Handler) error {
 	// enable cors
 	c := cors.New(cors.Options{
 		AllowedOrigins: s.origins,
@@ -101,7 +163,7 @@ func (s *JSONHTTPServer) StartService(
 	if s.collectMetrics {
 		mdlw := middleware.New(middleware.Config{
 			Recorder: metricsProm.NewRecorder(metricsProm.Config{
-				Prefix: metrics.Namespace + "_api",
+				Prefix: s.metricsPrefix,
 			}),
 		})
 		handler = c.Handler(std.Handler("", mdlw, mux))
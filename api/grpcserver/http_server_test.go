@@ -37,6 +37,33 @@ func launchJsonServer(tb testing.TB, services ...ServiceAPI) (Config, func()) {
 	return cfg, func() { assert.NoError(tb, jsonService.Shutdown(context.Background())) }
 }
 
+// launchDebugJSONServer starts a server with the debug endpoints of the services, see DebugServiceAPI.
+func launchDebugJSONServer(tb testing.TB, services ...ServiceAPI) (Config, func()) {
+	cfg := DefaultTestConfig()
+
+	// run on random port
+	jsonService := NewJSONHTTPServer(zaptest.NewLogger(tb).Named("grpc.DebugJSON"), "127.0.0.1:0",
+		[]string{}, false)
+	require.NoError(tb, jsonService.StartDebugService(services...))
+	cfg.DebugJSONListener = jsonService.BoundAddress
+
+	return cfg, func() { assert.NoError(tb, jsonService.Shutdown(context.Background())) }
+}
+
+func TestDebugJSONRequiresContentType(t *testing.T) {
+	svc := NewAdminService(statesql.InMemoryTest(t), t.TempDir(), nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	url := fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, SnapshotPath)
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded", "multipart/form-data"} {
+		resp, err := http.Post(url, contentType, nil)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode, contentType)
+	}
+}
+
 func callEndpoint(ctx context.Context, tb testing.TB, url string, body []byte) ([]byte, int) {
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	require.NoError(tb, err)
@@ -99,3 +126,23 @@ func TestJsonApi(t *testing.T) {
 	require.NoError(t, protojson.Unmarshal(respBody2, &msg2))
 	require.Equal(t, uint64(now.Unix()), msg2.Unixtime.Value)
 }
+
+func TestJsonApi_DebugEndpoints(t *testing.T) {
+	svc := NewAdminService(statesql.InMemoryTest(t), t.TempDir(), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// the gateway serves only the endpoints of the api protobufs
+	cfg, cleanup := launchJsonServer(t, svc)
+	t.Cleanup(cleanup)
+	_, status := callEndpoint(ctx, t, fmt.Sprintf("http://%s%s", cfg.JSONListener, SnapshotPath), nil)
+	require.Equal(t, http.StatusNotFound, status)
+
+	cfg, cleanup = launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+	_, status = callEndpoint(ctx, t, fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, SnapshotPath), nil)
+	require.Equal(t, http.StatusOK, status)
+
+	server := NewJSONHTTPServer(zaptest.NewLogger(t), "127.0.0.1:0", []string{}, false)
+	require.Error(t, server.StartDebugService(NewNodeService(nil, nil, nil, nil, "", "")))
+}
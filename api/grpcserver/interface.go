@@ -5,14 +5,21 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/spacemeshos/go-spacemesh/activation"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/hare3"
+	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
+	"github.com/spacemeshos/go-spacemesh/layerpatrol"
 	"github.com/spacemeshos/go-spacemesh/malfeasance/wire"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
 	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/haremsgs"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/harestats"
+	dbmetrics "github.com/spacemeshos/go-spacemesh/sql/metrics"
 	"github.com/spacemeshos/go-spacemesh/system"
 )
 
@@ -37,7 +44,10 @@ type conservativeState interface {
 	GetBalance(types.Address) (uint64, error)
 	GetNonce(types.Address) (types.Nonce, error)
 	GetProjection(types.Address) (uint64, uint64)
+	GetProjectionWithCertainty(types.Address, types.ProjectionCertainty) (uint64, uint64)
+	GetNonceProjection(types.Address) (*types.NonceProjection, error)
 	GetMeshTransaction(types.TransactionID) (*types.MeshTransaction, error)
+	GetTxStatus(types.TransactionID) (*types.TXStatus, error)
 	GetMeshTransactions([]types.TransactionID) ([]*types.MeshTransaction, map[types.TransactionID]struct{})
 	GetTransactionsByAddress(types.LayerID, types.LayerID, types.Address) ([]*types.MeshTransaction, error)
 	Validation(raw types.RawTx) system.ValidationRequest
@@ -85,10 +95,39 @@ type peerCounter interface {
 	PeerCount() uint64
 }
 
+// poetResidue is an api to purge the local state of poets that are not configured anymore.
+type poetResidue interface {
+	Purge(ctx context.Context) ([]activation.PoetResidue, error)
+}
+
+// hareSigners is an api to remove the signing keys of identities from hare at runtime.
+// Unregister returns false if the identity is not registered.
+type hareSigners interface {
+	Unregister(id types.NodeID) bool
+}
+
+// certificateRefresher is an api to replace the stored poet certificates with new ones.
+type certificateRefresher interface {
+	RecertifyAll(ctx context.Context) ([]activation.CertificateRefresh, error)
+}
+
+// poetsProvider is an api to get the poets used by the node with their effective settings.
+type poetsProvider interface {
+	Poets() []activation.PoetStatus
+	Preflight(ctx context.Context, id types.NodeID) []activation.PoetPreflight
+}
+
+// readinessProvider is an api to check the readiness of identities for the next poet round.
+type readinessProvider interface {
+	Report(id types.NodeID) (*activation.ReadinessReport, bool)
+	Check(ctx context.Context, id types.NodeID) *activation.ReadinessReport
+}
+
 // Peers is an api to get peer related info.
 type peers interface {
 	ConnectedPeerInfo(p2p.Peer) *p2p.PeerInfo
 	GetPeers() []p2p.Peer
+	Capabilities() map[protocol.ID][]p2p.Peer
 }
 
 // genesisTimeAPI is an API to get genesis time and current layer of the system.
@@ -109,6 +148,34 @@ type meshAPI interface {
 	MeshHash(types.LayerID) (types.Hash32, error)
 }
 
+// hareArchive is the API to read validated hare messages of the recent layers.
+type hareArchive interface {
+	Messages(haremsgs.Filter) ([]*hare3.Message, error)
+}
+
+// hareParticipation is the API to check when registered identities participate in hare.
+type hareParticipation interface {
+	Participation() ([]hare3.Participation, error)
+}
+
+// hareStats is the API to read the summaries of hare executions in the recent layers.
+type hareStats interface {
+	Get(types.LayerID) (*harestats.Stats, error)
+	Range(from, to types.LayerID) ([]*harestats.Stats, error)
+}
+
+// dbStats is the API to read the statistics of the state database.
+type dbStats interface {
+	Stats() dbmetrics.Stats
+}
+
+type layerPatrol interface {
+	Status(types.LayerID) (layerpatrol.LayerStatus, bool)
+	Waiting() map[types.LayerID]layerpatrol.LayerStatus
+}
+
 type oracle interface {
 	ActiveSet(context.Context, types.EpochID) ([]types.ATXID, error)
+	Participation(context.Context, types.EpochID, types.NodeID, int) (eligibility.Participation, error)
+	ExpectedCommittee(context.Context, types.LayerID, int) ([]eligibility.Seats, error)
 }
@@ -15,13 +15,20 @@ import (
 	time "time"
 
 	network "github.com/libp2p/go-libp2p/core/network"
+	protocol "github.com/libp2p/go-libp2p/core/protocol"
 	multiaddr "github.com/multiformats/go-multiaddr"
 	activation "github.com/spacemeshos/go-spacemesh/activation"
 	types "github.com/spacemeshos/go-spacemesh/common/types"
+	hare3 "github.com/spacemeshos/go-spacemesh/hare3"
+	eligibility "github.com/spacemeshos/go-spacemesh/hare3/eligibility"
+	layerpatrol "github.com/spacemeshos/go-spacemesh/layerpatrol"
 	wire "github.com/spacemeshos/go-spacemesh/malfeasance/wire"
 	p2p "github.com/spacemeshos/go-spacemesh/p2p"
 	peerinfo "github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
 	signing "github.com/spacemeshos/go-spacemesh/signing"
+	haremsgs "github.com/spacemeshos/go-spacemesh/sql/localsql/haremsgs"
+	harestats "github.com/spacemeshos/go-spacemesh/sql/localsql/harestats"
+	metrics "github.com/spacemeshos/go-spacemesh/sql/metrics"
 	system "github.com/spacemeshos/go-spacemesh/system"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -573,6 +580,45 @@ func (c *MockconservativeStateGetNonceCall) DoAndReturn(f func(types.Address) (t
 	return c
 }
 
+// GetNonceProjection mocks base method.
+func (m *MockconservativeState) GetNonceProjection(arg0 types.Address) (*types.NonceProjection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNonceProjection", arg0)
+	ret0, _ := ret[0].(*types.NonceProjection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNonceProjection indicates an expected call of GetNonceProjection.
+func (mr *MockconservativeStateMockRecorder) GetNonceProjection(arg0 any) *MockconservativeStateGetNonceProjectionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNonceProjection", reflect.TypeOf((*MockconservativeState)(nil).GetNonceProjection), arg0)
+	return &MockconservativeStateGetNonceProjectionCall{Call: call}
+}
+
+// MockconservativeStateGetNonceProjectionCall wrap *gomock.Call
+type MockconservativeStateGetNonceProjectionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockconservativeStateGetNonceProjectionCall) Return(arg0 *types.NonceProjection, arg1 error) *MockconservativeStateGetNonceProjectionCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockconservativeStateGetNonceProjectionCall) Do(f func(types.Address) (*types.NonceProjection, error)) *MockconservativeStateGetNonceProjectionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockconservativeStateGetNonceProjectionCall) DoAndReturn(f func(types.Address) (*types.NonceProjection, error)) *MockconservativeStateGetNonceProjectionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetProjection mocks base method.
 func (m *MockconservativeState) GetProjection(arg0 types.Address) (uint64, uint64) {
 	m.ctrl.T.Helper()
@@ -612,6 +658,45 @@ func (c *MockconservativeStateGetProjectionCall) DoAndReturn(f func(types.Addres
 	return c
 }
 
+// GetProjectionWithCertainty mocks base method.
+func (m *MockconservativeState) GetProjectionWithCertainty(arg0 types.Address, arg1 types.ProjectionCertainty) (uint64, uint64) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectionWithCertainty", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(uint64)
+	return ret0, ret1
+}
+
+// GetProjectionWithCertainty indicates an expected call of GetProjectionWithCertainty.
+func (mr *MockconservativeStateMockRecorder) GetProjectionWithCertainty(arg0, arg1 any) *MockconservativeStateGetProjectionWithCertaintyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectionWithCertainty", reflect.TypeOf((*MockconservativeState)(nil).GetProjectionWithCertainty), arg0, arg1)
+	return &MockconservativeStateGetProjectionWithCertaintyCall{Call: call}
+}
+
+// MockconservativeStateGetProjectionWithCertaintyCall wrap *gomock.Call
+type MockconservativeStateGetProjectionWithCertaintyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockconservativeStateGetProjectionWithCertaintyCall) Return(arg0, arg1 uint64) *MockconservativeStateGetProjectionWithCertaintyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockconservativeStateGetProjectionWithCertaintyCall) Do(f func(types.Address, types.ProjectionCertainty) (uint64, uint64)) *MockconservativeStateGetProjectionWithCertaintyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockconservativeStateGetProjectionWithCertaintyCall) DoAndReturn(f func(types.Address, types.ProjectionCertainty) (uint64, uint64)) *MockconservativeStateGetProjectionWithCertaintyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetStateRoot mocks base method.
 func (m *MockconservativeState) GetStateRoot() (types.Hash32, error) {
 	m.ctrl.T.Helper()
@@ -690,6 +775,45 @@ func (c *MockconservativeStateGetTransactionsByAddressCall) DoAndReturn(f func(t
 	return c
 }
 
+// GetTxStatus mocks base method.
+func (m *MockconservativeState) GetTxStatus(arg0 types.TransactionID) (*types.TXStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTxStatus", arg0)
+	ret0, _ := ret[0].(*types.TXStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTxStatus indicates an expected call of GetTxStatus.
+func (mr *MockconservativeStateMockRecorder) GetTxStatus(arg0 any) *MockconservativeStateGetTxStatusCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxStatus", reflect.TypeOf((*MockconservativeState)(nil).GetTxStatus), arg0)
+	return &MockconservativeStateGetTxStatusCall{Call: call}
+}
+
+// MockconservativeStateGetTxStatusCall wrap *gomock.Call
+type MockconservativeStateGetTxStatusCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockconservativeStateGetTxStatusCall) Return(arg0 *types.TXStatus, arg1 error) *MockconservativeStateGetTxStatusCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockconservativeStateGetTxStatusCall) Do(f func(types.TransactionID) (*types.TXStatus, error)) *MockconservativeStateGetTxStatusCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockconservativeStateGetTxStatusCall) DoAndReturn(f func(types.TransactionID) (*types.TXStatus, error)) *MockconservativeStateGetTxStatusCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Validation mocks base method.
 func (m *MockconservativeState) Validation(raw types.RawTx) system.ValidationRequest {
 	m.ctrl.T.Helper()
@@ -1463,532 +1587,1340 @@ func (c *MockpeerCounterPeerCountCall) DoAndReturn(f func() uint64) *MockpeerCou
 	return c
 }
 
-// Mockpeers is a mock of peers interface.
-type Mockpeers struct {
+// MockpoetResidue is a mock of poetResidue interface.
+type MockpoetResidue struct {
 	ctrl     *gomock.Controller
-	recorder *MockpeersMockRecorder
+	recorder *MockpoetResidueMockRecorder
 }
 
-// MockpeersMockRecorder is the mock recorder for Mockpeers.
-type MockpeersMockRecorder struct {
-	mock *Mockpeers
+// MockpoetResidueMockRecorder is the mock recorder for MockpoetResidue.
+type MockpoetResidueMockRecorder struct {
+	mock *MockpoetResidue
 }
 
-// NewMockpeers creates a new mock instance.
-func NewMockpeers(ctrl *gomock.Controller) *Mockpeers {
-	mock := &Mockpeers{ctrl: ctrl}
-	mock.recorder = &MockpeersMockRecorder{mock}
+// NewMockpoetResidue creates a new mock instance.
+func NewMockpoetResidue(ctrl *gomock.Controller) *MockpoetResidue {
+	mock := &MockpoetResidue{ctrl: ctrl}
+	mock.recorder = &MockpoetResidueMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *Mockpeers) EXPECT() *MockpeersMockRecorder {
+func (m *MockpoetResidue) EXPECT() *MockpoetResidueMockRecorder {
 	return m.recorder
 }
 
-// ConnectedPeerInfo mocks base method.
-func (m *Mockpeers) ConnectedPeerInfo(arg0 p2p.Peer) *p2p.PeerInfo {
+// Purge mocks base method.
+func (m *MockpoetResidue) Purge(ctx context.Context) ([]activation.PoetResidue, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ConnectedPeerInfo", arg0)
-	ret0, _ := ret[0].(*p2p.PeerInfo)
-	return ret0
+	ret := m.ctrl.Call(m, "Purge", ctx)
+	ret0, _ := ret[0].([]activation.PoetResidue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// ConnectedPeerInfo indicates an expected call of ConnectedPeerInfo.
-func (mr *MockpeersMockRecorder) ConnectedPeerInfo(arg0 any) *MockpeersConnectedPeerInfoCall {
+// Purge indicates an expected call of Purge.
+func (mr *MockpoetResidueMockRecorder) Purge(ctx any) *MockpoetResiduePurgeCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectedPeerInfo", reflect.TypeOf((*Mockpeers)(nil).ConnectedPeerInfo), arg0)
-	return &MockpeersConnectedPeerInfoCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockpoetResidue)(nil).Purge), ctx)
+	return &MockpoetResiduePurgeCall{Call: call}
 }
 
-// MockpeersConnectedPeerInfoCall wrap *gomock.Call
-type MockpeersConnectedPeerInfoCall struct {
+// MockpoetResiduePurgeCall wrap *gomock.Call
+type MockpoetResiduePurgeCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockpeersConnectedPeerInfoCall) Return(arg0 *p2p.PeerInfo) *MockpeersConnectedPeerInfoCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockpoetResiduePurgeCall) Return(arg0 []activation.PoetResidue, arg1 error) *MockpoetResiduePurgeCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockpeersConnectedPeerInfoCall) Do(f func(p2p.Peer) *p2p.PeerInfo) *MockpeersConnectedPeerInfoCall {
+func (c *MockpoetResiduePurgeCall) Do(f func(context.Context) ([]activation.PoetResidue, error)) *MockpoetResiduePurgeCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockpeersConnectedPeerInfoCall) DoAndReturn(f func(p2p.Peer) *p2p.PeerInfo) *MockpeersConnectedPeerInfoCall {
+func (c *MockpoetResiduePurgeCall) DoAndReturn(f func(context.Context) ([]activation.PoetResidue, error)) *MockpoetResiduePurgeCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// GetPeers mocks base method.
-func (m *Mockpeers) GetPeers() []p2p.Peer {
+// MockhareSigners is a mock of hareSigners interface.
+type MockhareSigners struct {
+	ctrl     *gomock.Controller
+	recorder *MockhareSignersMockRecorder
+}
+
+// MockhareSignersMockRecorder is the mock recorder for MockhareSigners.
+type MockhareSignersMockRecorder struct {
+	mock *MockhareSigners
+}
+
+// NewMockhareSigners creates a new mock instance.
+func NewMockhareSigners(ctrl *gomock.Controller) *MockhareSigners {
+	mock := &MockhareSigners{ctrl: ctrl}
+	mock.recorder = &MockhareSignersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhareSigners) EXPECT() *MockhareSignersMockRecorder {
+	return m.recorder
+}
+
+// Unregister mocks base method.
+func (m *MockhareSigners) Unregister(id types.NodeID) bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPeers")
-	ret0, _ := ret[0].([]p2p.Peer)
+	ret := m.ctrl.Call(m, "Unregister", id)
+	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
-// GetPeers indicates an expected call of GetPeers.
-func (mr *MockpeersMockRecorder) GetPeers() *MockpeersGetPeersCall {
+// Unregister indicates an expected call of Unregister.
+func (mr *MockhareSignersMockRecorder) Unregister(id any) *MockhareSignersUnregisterCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeers", reflect.TypeOf((*Mockpeers)(nil).GetPeers))
-	return &MockpeersGetPeersCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unregister", reflect.TypeOf((*MockhareSigners)(nil).Unregister), id)
+	return &MockhareSignersUnregisterCall{Call: call}
 }
 
-// MockpeersGetPeersCall wrap *gomock.Call
-type MockpeersGetPeersCall struct {
+// MockhareSignersUnregisterCall wrap *gomock.Call
+type MockhareSignersUnregisterCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockpeersGetPeersCall) Return(arg0 []p2p.Peer) *MockpeersGetPeersCall {
+func (c *MockhareSignersUnregisterCall) Return(arg0 bool) *MockhareSignersUnregisterCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockpeersGetPeersCall) Do(f func() []p2p.Peer) *MockpeersGetPeersCall {
+func (c *MockhareSignersUnregisterCall) Do(f func(types.NodeID) bool) *MockhareSignersUnregisterCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockpeersGetPeersCall) DoAndReturn(f func() []p2p.Peer) *MockpeersGetPeersCall {
+func (c *MockhareSignersUnregisterCall) DoAndReturn(f func(types.NodeID) bool) *MockhareSignersUnregisterCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// MockgenesisTimeAPI is a mock of genesisTimeAPI interface.
-type MockgenesisTimeAPI struct {
+// MockcertificateRefresher is a mock of certificateRefresher interface.
+type MockcertificateRefresher struct {
 	ctrl     *gomock.Controller
-	recorder *MockgenesisTimeAPIMockRecorder
+	recorder *MockcertificateRefresherMockRecorder
 }
 
-// MockgenesisTimeAPIMockRecorder is the mock recorder for MockgenesisTimeAPI.
-type MockgenesisTimeAPIMockRecorder struct {
-	mock *MockgenesisTimeAPI
+// MockcertificateRefresherMockRecorder is the mock recorder for MockcertificateRefresher.
+type MockcertificateRefresherMockRecorder struct {
+	mock *MockcertificateRefresher
 }
 
-// NewMockgenesisTimeAPI creates a new mock instance.
-func NewMockgenesisTimeAPI(ctrl *gomock.Controller) *MockgenesisTimeAPI {
-	mock := &MockgenesisTimeAPI{ctrl: ctrl}
-	mock.recorder = &MockgenesisTimeAPIMockRecorder{mock}
+// NewMockcertificateRefresher creates a new mock instance.
+func NewMockcertificateRefresher(ctrl *gomock.Controller) *MockcertificateRefresher {
+	mock := &MockcertificateRefresher{ctrl: ctrl}
+	mock.recorder = &MockcertificateRefresherMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockgenesisTimeAPI) EXPECT() *MockgenesisTimeAPIMockRecorder {
+func (m *MockcertificateRefresher) EXPECT() *MockcertificateRefresherMockRecorder {
 	return m.recorder
 }
 
-// CurrentLayer mocks base method.
-func (m *MockgenesisTimeAPI) CurrentLayer() types.LayerID {
+// RecertifyAll mocks base method.
+func (m *MockcertificateRefresher) RecertifyAll(ctx context.Context) ([]activation.CertificateRefresh, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CurrentLayer")
-	ret0, _ := ret[0].(types.LayerID)
+	ret := m.ctrl.Call(m, "RecertifyAll", ctx)
+	ret0, _ := ret[0].([]activation.CertificateRefresh)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecertifyAll indicates an expected call of RecertifyAll.
+func (mr *MockcertificateRefresherMockRecorder) RecertifyAll(ctx any) *MockcertificateRefresherRecertifyAllCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecertifyAll", reflect.TypeOf((*MockcertificateRefresher)(nil).RecertifyAll), ctx)
+	return &MockcertificateRefresherRecertifyAllCall{Call: call}
+}
+
+// MockcertificateRefresherRecertifyAllCall wrap *gomock.Call
+type MockcertificateRefresherRecertifyAllCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockcertificateRefresherRecertifyAllCall) Return(arg0 []activation.CertificateRefresh, arg1 error) *MockcertificateRefresherRecertifyAllCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockcertificateRefresherRecertifyAllCall) Do(f func(context.Context) ([]activation.CertificateRefresh, error)) *MockcertificateRefresherRecertifyAllCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockcertificateRefresherRecertifyAllCall) DoAndReturn(f func(context.Context) ([]activation.CertificateRefresh, error)) *MockcertificateRefresherRecertifyAllCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockpoetsProvider is a mock of poetsProvider interface.
+type MockpoetsProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockpoetsProviderMockRecorder
+}
+
+// MockpoetsProviderMockRecorder is the mock recorder for MockpoetsProvider.
+type MockpoetsProviderMockRecorder struct {
+	mock *MockpoetsProvider
+}
+
+// NewMockpoetsProvider creates a new mock instance.
+func NewMockpoetsProvider(ctrl *gomock.Controller) *MockpoetsProvider {
+	mock := &MockpoetsProvider{ctrl: ctrl}
+	mock.recorder = &MockpoetsProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpoetsProvider) EXPECT() *MockpoetsProviderMockRecorder {
+	return m.recorder
+}
+
+// Poets mocks base method.
+func (m *MockpoetsProvider) Poets() []activation.PoetStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Poets")
+	ret0, _ := ret[0].([]activation.PoetStatus)
 	return ret0
 }
 
-// CurrentLayer indicates an expected call of CurrentLayer.
-func (mr *MockgenesisTimeAPIMockRecorder) CurrentLayer() *MockgenesisTimeAPICurrentLayerCall {
+// Poets indicates an expected call of Poets.
+func (mr *MockpoetsProviderMockRecorder) Poets() *MockpoetsProviderPoetsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentLayer", reflect.TypeOf((*MockgenesisTimeAPI)(nil).CurrentLayer))
-	return &MockgenesisTimeAPICurrentLayerCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Poets", reflect.TypeOf((*MockpoetsProvider)(nil).Poets))
+	return &MockpoetsProviderPoetsCall{Call: call}
 }
 
-// MockgenesisTimeAPICurrentLayerCall wrap *gomock.Call
-type MockgenesisTimeAPICurrentLayerCall struct {
+// MockpoetsProviderPoetsCall wrap *gomock.Call
+type MockpoetsProviderPoetsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockgenesisTimeAPICurrentLayerCall) Return(arg0 types.LayerID) *MockgenesisTimeAPICurrentLayerCall {
+func (c *MockpoetsProviderPoetsCall) Return(arg0 []activation.PoetStatus) *MockpoetsProviderPoetsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockgenesisTimeAPICurrentLayerCall) Do(f func() types.LayerID) *MockgenesisTimeAPICurrentLayerCall {
+func (c *MockpoetsProviderPoetsCall) Do(f func() []activation.PoetStatus) *MockpoetsProviderPoetsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockgenesisTimeAPICurrentLayerCall) DoAndReturn(f func() types.LayerID) *MockgenesisTimeAPICurrentLayerCall {
+func (c *MockpoetsProviderPoetsCall) DoAndReturn(f func() []activation.PoetStatus) *MockpoetsProviderPoetsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// GenesisTime mocks base method.
-func (m *MockgenesisTimeAPI) GenesisTime() time.Time {
+// Preflight mocks base method.
+func (m *MockpoetsProvider) Preflight(ctx context.Context, id types.NodeID) []activation.PoetPreflight {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenesisTime")
-	ret0, _ := ret[0].(time.Time)
+	ret := m.ctrl.Call(m, "Preflight", ctx, id)
+	ret0, _ := ret[0].([]activation.PoetPreflight)
 	return ret0
 }
 
-// GenesisTime indicates an expected call of GenesisTime.
-func (mr *MockgenesisTimeAPIMockRecorder) GenesisTime() *MockgenesisTimeAPIGenesisTimeCall {
+// Preflight indicates an expected call of Preflight.
+func (mr *MockpoetsProviderMockRecorder) Preflight(ctx, id any) *MockpoetsProviderPreflightCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenesisTime", reflect.TypeOf((*MockgenesisTimeAPI)(nil).GenesisTime))
-	return &MockgenesisTimeAPIGenesisTimeCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preflight", reflect.TypeOf((*MockpoetsProvider)(nil).Preflight), ctx, id)
+	return &MockpoetsProviderPreflightCall{Call: call}
 }
 
-// MockgenesisTimeAPIGenesisTimeCall wrap *gomock.Call
-type MockgenesisTimeAPIGenesisTimeCall struct {
+// MockpoetsProviderPreflightCall wrap *gomock.Call
+type MockpoetsProviderPreflightCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockgenesisTimeAPIGenesisTimeCall) Return(arg0 time.Time) *MockgenesisTimeAPIGenesisTimeCall {
+func (c *MockpoetsProviderPreflightCall) Return(arg0 []activation.PoetPreflight) *MockpoetsProviderPreflightCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockgenesisTimeAPIGenesisTimeCall) Do(f func() time.Time) *MockgenesisTimeAPIGenesisTimeCall {
+func (c *MockpoetsProviderPreflightCall) Do(f func(context.Context, types.NodeID) []activation.PoetPreflight) *MockpoetsProviderPreflightCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockgenesisTimeAPIGenesisTimeCall) DoAndReturn(f func() time.Time) *MockgenesisTimeAPIGenesisTimeCall {
+func (c *MockpoetsProviderPreflightCall) DoAndReturn(f func(context.Context, types.NodeID) []activation.PoetPreflight) *MockpoetsProviderPreflightCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// MockmeshAPI is a mock of meshAPI interface.
-type MockmeshAPI struct {
+// MockreadinessProvider is a mock of readinessProvider interface.
+type MockreadinessProvider struct {
 	ctrl     *gomock.Controller
-	recorder *MockmeshAPIMockRecorder
+	recorder *MockreadinessProviderMockRecorder
 }
 
-// MockmeshAPIMockRecorder is the mock recorder for MockmeshAPI.
-type MockmeshAPIMockRecorder struct {
-	mock *MockmeshAPI
+// MockreadinessProviderMockRecorder is the mock recorder for MockreadinessProvider.
+type MockreadinessProviderMockRecorder struct {
+	mock *MockreadinessProvider
 }
 
-// NewMockmeshAPI creates a new mock instance.
-func NewMockmeshAPI(ctrl *gomock.Controller) *MockmeshAPI {
-	mock := &MockmeshAPI{ctrl: ctrl}
-	mock.recorder = &MockmeshAPIMockRecorder{mock}
+// NewMockreadinessProvider creates a new mock instance.
+func NewMockreadinessProvider(ctrl *gomock.Controller) *MockreadinessProvider {
+	mock := &MockreadinessProvider{ctrl: ctrl}
+	mock.recorder = &MockreadinessProviderMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockmeshAPI) EXPECT() *MockmeshAPIMockRecorder {
+func (m *MockreadinessProvider) EXPECT() *MockreadinessProviderMockRecorder {
 	return m.recorder
 }
 
-// GetLayer mocks base method.
-func (m *MockmeshAPI) GetLayer(arg0 types.LayerID) (*types.Layer, error) {
+// Check mocks base method.
+func (m *MockreadinessProvider) Check(ctx context.Context, id types.NodeID) *activation.ReadinessReport {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLayer", arg0)
-	ret0, _ := ret[0].(*types.Layer)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Check", ctx, id)
+	ret0, _ := ret[0].(*activation.ReadinessReport)
+	return ret0
 }
 
-// GetLayer indicates an expected call of GetLayer.
-func (mr *MockmeshAPIMockRecorder) GetLayer(arg0 any) *MockmeshAPIGetLayerCall {
+// Check indicates an expected call of Check.
+func (mr *MockreadinessProviderMockRecorder) Check(ctx, id any) *MockreadinessProviderCheckCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayer", reflect.TypeOf((*MockmeshAPI)(nil).GetLayer), arg0)
-	return &MockmeshAPIGetLayerCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockreadinessProvider)(nil).Check), ctx, id)
+	return &MockreadinessProviderCheckCall{Call: call}
 }
 
-// MockmeshAPIGetLayerCall wrap *gomock.Call
-type MockmeshAPIGetLayerCall struct {
+// MockreadinessProviderCheckCall wrap *gomock.Call
+type MockreadinessProviderCheckCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPIGetLayerCall) Return(arg0 *types.Layer, arg1 error) *MockmeshAPIGetLayerCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockreadinessProviderCheckCall) Return(arg0 *activation.ReadinessReport) *MockreadinessProviderCheckCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPIGetLayerCall) Do(f func(types.LayerID) (*types.Layer, error)) *MockmeshAPIGetLayerCall {
+func (c *MockreadinessProviderCheckCall) Do(f func(context.Context, types.NodeID) *activation.ReadinessReport) *MockreadinessProviderCheckCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPIGetLayerCall) DoAndReturn(f func(types.LayerID) (*types.Layer, error)) *MockmeshAPIGetLayerCall {
+func (c *MockreadinessProviderCheckCall) DoAndReturn(f func(context.Context, types.NodeID) *activation.ReadinessReport) *MockreadinessProviderCheckCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// GetLayerVerified mocks base method.
-func (m *MockmeshAPI) GetLayerVerified(arg0 types.LayerID) (*types.Block, error) {
+// Report mocks base method.
+func (m *MockreadinessProvider) Report(id types.NodeID) (*activation.ReadinessReport, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLayerVerified", arg0)
-	ret0, _ := ret[0].(*types.Block)
-	ret1, _ := ret[1].(error)
+	ret := m.ctrl.Call(m, "Report", id)
+	ret0, _ := ret[0].(*activation.ReadinessReport)
+	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
-// GetLayerVerified indicates an expected call of GetLayerVerified.
-func (mr *MockmeshAPIMockRecorder) GetLayerVerified(arg0 any) *MockmeshAPIGetLayerVerifiedCall {
+// Report indicates an expected call of Report.
+func (mr *MockreadinessProviderMockRecorder) Report(id any) *MockreadinessProviderReportCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayerVerified", reflect.TypeOf((*MockmeshAPI)(nil).GetLayerVerified), arg0)
-	return &MockmeshAPIGetLayerVerifiedCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockreadinessProvider)(nil).Report), id)
+	return &MockreadinessProviderReportCall{Call: call}
 }
 
-// MockmeshAPIGetLayerVerifiedCall wrap *gomock.Call
-type MockmeshAPIGetLayerVerifiedCall struct {
+// MockreadinessProviderReportCall wrap *gomock.Call
+type MockreadinessProviderReportCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPIGetLayerVerifiedCall) Return(arg0 *types.Block, arg1 error) *MockmeshAPIGetLayerVerifiedCall {
+func (c *MockreadinessProviderReportCall) Return(arg0 *activation.ReadinessReport, arg1 bool) *MockreadinessProviderReportCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPIGetLayerVerifiedCall) Do(f func(types.LayerID) (*types.Block, error)) *MockmeshAPIGetLayerVerifiedCall {
+func (c *MockreadinessProviderReportCall) Do(f func(types.NodeID) (*activation.ReadinessReport, bool)) *MockreadinessProviderReportCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPIGetLayerVerifiedCall) DoAndReturn(f func(types.LayerID) (*types.Block, error)) *MockmeshAPIGetLayerVerifiedCall {
+func (c *MockreadinessProviderReportCall) DoAndReturn(f func(types.NodeID) (*activation.ReadinessReport, bool)) *MockreadinessProviderReportCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// GetRewardsByCoinbase mocks base method.
-func (m *MockmeshAPI) GetRewardsByCoinbase(arg0 types.Address) ([]*types.Reward, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRewardsByCoinbase", arg0)
+// Mockpeers is a mock of peers interface.
+type Mockpeers struct {
+	ctrl     *gomock.Controller
+	recorder *MockpeersMockRecorder
+}
+
+// MockpeersMockRecorder is the mock recorder for Mockpeers.
+type MockpeersMockRecorder struct {
+	mock *Mockpeers
+}
+
+// NewMockpeers creates a new mock instance.
+func NewMockpeers(ctrl *gomock.Controller) *Mockpeers {
+	mock := &Mockpeers{ctrl: ctrl}
+	mock.recorder = &MockpeersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockpeers) EXPECT() *MockpeersMockRecorder {
+	return m.recorder
+}
+
+// Capabilities mocks base method.
+func (m *Mockpeers) Capabilities() map[protocol.ID][]p2p.Peer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capabilities")
+	ret0, _ := ret[0].(map[protocol.ID][]p2p.Peer)
+	return ret0
+}
+
+// Capabilities indicates an expected call of Capabilities.
+func (mr *MockpeersMockRecorder) Capabilities() *MockpeersCapabilitiesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capabilities", reflect.TypeOf((*Mockpeers)(nil).Capabilities))
+	return &MockpeersCapabilitiesCall{Call: call}
+}
+
+// MockpeersCapabilitiesCall wrap *gomock.Call
+type MockpeersCapabilitiesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockpeersCapabilitiesCall) Return(arg0 map[protocol.ID][]p2p.Peer) *MockpeersCapabilitiesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockpeersCapabilitiesCall) Do(f func() map[protocol.ID][]p2p.Peer) *MockpeersCapabilitiesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockpeersCapabilitiesCall) DoAndReturn(f func() map[protocol.ID][]p2p.Peer) *MockpeersCapabilitiesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ConnectedPeerInfo mocks base method.
+func (m *Mockpeers) ConnectedPeerInfo(arg0 p2p.Peer) *p2p.PeerInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectedPeerInfo", arg0)
+	ret0, _ := ret[0].(*p2p.PeerInfo)
+	return ret0
+}
+
+// ConnectedPeerInfo indicates an expected call of ConnectedPeerInfo.
+func (mr *MockpeersMockRecorder) ConnectedPeerInfo(arg0 any) *MockpeersConnectedPeerInfoCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectedPeerInfo", reflect.TypeOf((*Mockpeers)(nil).ConnectedPeerInfo), arg0)
+	return &MockpeersConnectedPeerInfoCall{Call: call}
+}
+
+// MockpeersConnectedPeerInfoCall wrap *gomock.Call
+type MockpeersConnectedPeerInfoCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockpeersConnectedPeerInfoCall) Return(arg0 *p2p.PeerInfo) *MockpeersConnectedPeerInfoCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockpeersConnectedPeerInfoCall) Do(f func(p2p.Peer) *p2p.PeerInfo) *MockpeersConnectedPeerInfoCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockpeersConnectedPeerInfoCall) DoAndReturn(f func(p2p.Peer) *p2p.PeerInfo) *MockpeersConnectedPeerInfoCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetPeers mocks base method.
+func (m *Mockpeers) GetPeers() []p2p.Peer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeers")
+	ret0, _ := ret[0].([]p2p.Peer)
+	return ret0
+}
+
+// GetPeers indicates an expected call of GetPeers.
+func (mr *MockpeersMockRecorder) GetPeers() *MockpeersGetPeersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeers", reflect.TypeOf((*Mockpeers)(nil).GetPeers))
+	return &MockpeersGetPeersCall{Call: call}
+}
+
+// MockpeersGetPeersCall wrap *gomock.Call
+type MockpeersGetPeersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockpeersGetPeersCall) Return(arg0 []p2p.Peer) *MockpeersGetPeersCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockpeersGetPeersCall) Do(f func() []p2p.Peer) *MockpeersGetPeersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockpeersGetPeersCall) DoAndReturn(f func() []p2p.Peer) *MockpeersGetPeersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockgenesisTimeAPI is a mock of genesisTimeAPI interface.
+type MockgenesisTimeAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockgenesisTimeAPIMockRecorder
+}
+
+// MockgenesisTimeAPIMockRecorder is the mock recorder for MockgenesisTimeAPI.
+type MockgenesisTimeAPIMockRecorder struct {
+	mock *MockgenesisTimeAPI
+}
+
+// NewMockgenesisTimeAPI creates a new mock instance.
+func NewMockgenesisTimeAPI(ctrl *gomock.Controller) *MockgenesisTimeAPI {
+	mock := &MockgenesisTimeAPI{ctrl: ctrl}
+	mock.recorder = &MockgenesisTimeAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockgenesisTimeAPI) EXPECT() *MockgenesisTimeAPIMockRecorder {
+	return m.recorder
+}
+
+// CurrentLayer mocks base method.
+func (m *MockgenesisTimeAPI) CurrentLayer() types.LayerID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CurrentLayer")
+	ret0, _ := ret[0].(types.LayerID)
+	return ret0
+}
+
+// CurrentLayer indicates an expected call of CurrentLayer.
+func (mr *MockgenesisTimeAPIMockRecorder) CurrentLayer() *MockgenesisTimeAPICurrentLayerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentLayer", reflect.TypeOf((*MockgenesisTimeAPI)(nil).CurrentLayer))
+	return &MockgenesisTimeAPICurrentLayerCall{Call: call}
+}
+
+// MockgenesisTimeAPICurrentLayerCall wrap *gomock.Call
+type MockgenesisTimeAPICurrentLayerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockgenesisTimeAPICurrentLayerCall) Return(arg0 types.LayerID) *MockgenesisTimeAPICurrentLayerCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockgenesisTimeAPICurrentLayerCall) Do(f func() types.LayerID) *MockgenesisTimeAPICurrentLayerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockgenesisTimeAPICurrentLayerCall) DoAndReturn(f func() types.LayerID) *MockgenesisTimeAPICurrentLayerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GenesisTime mocks base method.
+func (m *MockgenesisTimeAPI) GenesisTime() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenesisTime")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// GenesisTime indicates an expected call of GenesisTime.
+func (mr *MockgenesisTimeAPIMockRecorder) GenesisTime() *MockgenesisTimeAPIGenesisTimeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenesisTime", reflect.TypeOf((*MockgenesisTimeAPI)(nil).GenesisTime))
+	return &MockgenesisTimeAPIGenesisTimeCall{Call: call}
+}
+
+// MockgenesisTimeAPIGenesisTimeCall wrap *gomock.Call
+type MockgenesisTimeAPIGenesisTimeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockgenesisTimeAPIGenesisTimeCall) Return(arg0 time.Time) *MockgenesisTimeAPIGenesisTimeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockgenesisTimeAPIGenesisTimeCall) Do(f func() time.Time) *MockgenesisTimeAPIGenesisTimeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockgenesisTimeAPIGenesisTimeCall) DoAndReturn(f func() time.Time) *MockgenesisTimeAPIGenesisTimeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockmeshAPI is a mock of meshAPI interface.
+type MockmeshAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockmeshAPIMockRecorder
+}
+
+// MockmeshAPIMockRecorder is the mock recorder for MockmeshAPI.
+type MockmeshAPIMockRecorder struct {
+	mock *MockmeshAPI
+}
+
+// NewMockmeshAPI creates a new mock instance.
+func NewMockmeshAPI(ctrl *gomock.Controller) *MockmeshAPI {
+	mock := &MockmeshAPI{ctrl: ctrl}
+	mock.recorder = &MockmeshAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmeshAPI) EXPECT() *MockmeshAPIMockRecorder {
+	return m.recorder
+}
+
+// GetLayer mocks base method.
+func (m *MockmeshAPI) GetLayer(arg0 types.LayerID) (*types.Layer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLayer", arg0)
+	ret0, _ := ret[0].(*types.Layer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLayer indicates an expected call of GetLayer.
+func (mr *MockmeshAPIMockRecorder) GetLayer(arg0 any) *MockmeshAPIGetLayerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayer", reflect.TypeOf((*MockmeshAPI)(nil).GetLayer), arg0)
+	return &MockmeshAPIGetLayerCall{Call: call}
+}
+
+// MockmeshAPIGetLayerCall wrap *gomock.Call
+type MockmeshAPIGetLayerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPIGetLayerCall) Return(arg0 *types.Layer, arg1 error) *MockmeshAPIGetLayerCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPIGetLayerCall) Do(f func(types.LayerID) (*types.Layer, error)) *MockmeshAPIGetLayerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPIGetLayerCall) DoAndReturn(f func(types.LayerID) (*types.Layer, error)) *MockmeshAPIGetLayerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetLayerVerified mocks base method.
+func (m *MockmeshAPI) GetLayerVerified(arg0 types.LayerID) (*types.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLayerVerified", arg0)
+	ret0, _ := ret[0].(*types.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLayerVerified indicates an expected call of GetLayerVerified.
+func (mr *MockmeshAPIMockRecorder) GetLayerVerified(arg0 any) *MockmeshAPIGetLayerVerifiedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayerVerified", reflect.TypeOf((*MockmeshAPI)(nil).GetLayerVerified), arg0)
+	return &MockmeshAPIGetLayerVerifiedCall{Call: call}
+}
+
+// MockmeshAPIGetLayerVerifiedCall wrap *gomock.Call
+type MockmeshAPIGetLayerVerifiedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPIGetLayerVerifiedCall) Return(arg0 *types.Block, arg1 error) *MockmeshAPIGetLayerVerifiedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPIGetLayerVerifiedCall) Do(f func(types.LayerID) (*types.Block, error)) *MockmeshAPIGetLayerVerifiedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPIGetLayerVerifiedCall) DoAndReturn(f func(types.LayerID) (*types.Block, error)) *MockmeshAPIGetLayerVerifiedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetRewardsByCoinbase mocks base method.
+func (m *MockmeshAPI) GetRewardsByCoinbase(arg0 types.Address) ([]*types.Reward, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRewardsByCoinbase", arg0)
+	ret0, _ := ret[0].([]*types.Reward)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRewardsByCoinbase indicates an expected call of GetRewardsByCoinbase.
+func (mr *MockmeshAPIMockRecorder) GetRewardsByCoinbase(arg0 any) *MockmeshAPIGetRewardsByCoinbaseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRewardsByCoinbase", reflect.TypeOf((*MockmeshAPI)(nil).GetRewardsByCoinbase), arg0)
+	return &MockmeshAPIGetRewardsByCoinbaseCall{Call: call}
+}
+
+// MockmeshAPIGetRewardsByCoinbaseCall wrap *gomock.Call
+type MockmeshAPIGetRewardsByCoinbaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPIGetRewardsByCoinbaseCall) Return(arg0 []*types.Reward, arg1 error) *MockmeshAPIGetRewardsByCoinbaseCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPIGetRewardsByCoinbaseCall) Do(f func(types.Address) ([]*types.Reward, error)) *MockmeshAPIGetRewardsByCoinbaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPIGetRewardsByCoinbaseCall) DoAndReturn(f func(types.Address) ([]*types.Reward, error)) *MockmeshAPIGetRewardsByCoinbaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetRewardsBySmesherId mocks base method.
+func (m *MockmeshAPI) GetRewardsBySmesherId(id types.NodeID) ([]*types.Reward, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRewardsBySmesherId", id)
 	ret0, _ := ret[0].([]*types.Reward)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRewardsByCoinbase indicates an expected call of GetRewardsByCoinbase.
-func (mr *MockmeshAPIMockRecorder) GetRewardsByCoinbase(arg0 any) *MockmeshAPIGetRewardsByCoinbaseCall {
+// GetRewardsBySmesherId indicates an expected call of GetRewardsBySmesherId.
+func (mr *MockmeshAPIMockRecorder) GetRewardsBySmesherId(id any) *MockmeshAPIGetRewardsBySmesherIdCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRewardsBySmesherId", reflect.TypeOf((*MockmeshAPI)(nil).GetRewardsBySmesherId), id)
+	return &MockmeshAPIGetRewardsBySmesherIdCall{Call: call}
+}
+
+// MockmeshAPIGetRewardsBySmesherIdCall wrap *gomock.Call
+type MockmeshAPIGetRewardsBySmesherIdCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPIGetRewardsBySmesherIdCall) Return(arg0 []*types.Reward, arg1 error) *MockmeshAPIGetRewardsBySmesherIdCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPIGetRewardsBySmesherIdCall) Do(f func(types.NodeID) ([]*types.Reward, error)) *MockmeshAPIGetRewardsBySmesherIdCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPIGetRewardsBySmesherIdCall) DoAndReturn(f func(types.NodeID) ([]*types.Reward, error)) *MockmeshAPIGetRewardsBySmesherIdCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// LatestLayer mocks base method.
+func (m *MockmeshAPI) LatestLayer() types.LayerID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestLayer")
+	ret0, _ := ret[0].(types.LayerID)
+	return ret0
+}
+
+// LatestLayer indicates an expected call of LatestLayer.
+func (mr *MockmeshAPIMockRecorder) LatestLayer() *MockmeshAPILatestLayerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestLayer", reflect.TypeOf((*MockmeshAPI)(nil).LatestLayer))
+	return &MockmeshAPILatestLayerCall{Call: call}
+}
+
+// MockmeshAPILatestLayerCall wrap *gomock.Call
+type MockmeshAPILatestLayerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPILatestLayerCall) Return(arg0 types.LayerID) *MockmeshAPILatestLayerCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPILatestLayerCall) Do(f func() types.LayerID) *MockmeshAPILatestLayerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPILatestLayerCall) DoAndReturn(f func() types.LayerID) *MockmeshAPILatestLayerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// LatestLayerInState mocks base method.
+func (m *MockmeshAPI) LatestLayerInState() types.LayerID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestLayerInState")
+	ret0, _ := ret[0].(types.LayerID)
+	return ret0
+}
+
+// LatestLayerInState indicates an expected call of LatestLayerInState.
+func (mr *MockmeshAPIMockRecorder) LatestLayerInState() *MockmeshAPILatestLayerInStateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestLayerInState", reflect.TypeOf((*MockmeshAPI)(nil).LatestLayerInState))
+	return &MockmeshAPILatestLayerInStateCall{Call: call}
+}
+
+// MockmeshAPILatestLayerInStateCall wrap *gomock.Call
+type MockmeshAPILatestLayerInStateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPILatestLayerInStateCall) Return(arg0 types.LayerID) *MockmeshAPILatestLayerInStateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPILatestLayerInStateCall) Do(f func() types.LayerID) *MockmeshAPILatestLayerInStateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPILatestLayerInStateCall) DoAndReturn(f func() types.LayerID) *MockmeshAPILatestLayerInStateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MeshHash mocks base method.
+func (m *MockmeshAPI) MeshHash(arg0 types.LayerID) (types.Hash32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeshHash", arg0)
+	ret0, _ := ret[0].(types.Hash32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeshHash indicates an expected call of MeshHash.
+func (mr *MockmeshAPIMockRecorder) MeshHash(arg0 any) *MockmeshAPIMeshHashCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeshHash", reflect.TypeOf((*MockmeshAPI)(nil).MeshHash), arg0)
+	return &MockmeshAPIMeshHashCall{Call: call}
+}
+
+// MockmeshAPIMeshHashCall wrap *gomock.Call
+type MockmeshAPIMeshHashCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPIMeshHashCall) Return(arg0 types.Hash32, arg1 error) *MockmeshAPIMeshHashCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPIMeshHashCall) Do(f func(types.LayerID) (types.Hash32, error)) *MockmeshAPIMeshHashCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPIMeshHashCall) DoAndReturn(f func(types.LayerID) (types.Hash32, error)) *MockmeshAPIMeshHashCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ProcessedLayer mocks base method.
+func (m *MockmeshAPI) ProcessedLayer() types.LayerID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessedLayer")
+	ret0, _ := ret[0].(types.LayerID)
+	return ret0
+}
+
+// ProcessedLayer indicates an expected call of ProcessedLayer.
+func (mr *MockmeshAPIMockRecorder) ProcessedLayer() *MockmeshAPIProcessedLayerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessedLayer", reflect.TypeOf((*MockmeshAPI)(nil).ProcessedLayer))
+	return &MockmeshAPIProcessedLayerCall{Call: call}
+}
+
+// MockmeshAPIProcessedLayerCall wrap *gomock.Call
+type MockmeshAPIProcessedLayerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockmeshAPIProcessedLayerCall) Return(arg0 types.LayerID) *MockmeshAPIProcessedLayerCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockmeshAPIProcessedLayerCall) Do(f func() types.LayerID) *MockmeshAPIProcessedLayerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockmeshAPIProcessedLayerCall) DoAndReturn(f func() types.LayerID) *MockmeshAPIProcessedLayerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockhareArchive is a mock of hareArchive interface.
+type MockhareArchive struct {
+	ctrl     *gomock.Controller
+	recorder *MockhareArchiveMockRecorder
+}
+
+// MockhareArchiveMockRecorder is the mock recorder for MockhareArchive.
+type MockhareArchiveMockRecorder struct {
+	mock *MockhareArchive
+}
+
+// NewMockhareArchive creates a new mock instance.
+func NewMockhareArchive(ctrl *gomock.Controller) *MockhareArchive {
+	mock := &MockhareArchive{ctrl: ctrl}
+	mock.recorder = &MockhareArchiveMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhareArchive) EXPECT() *MockhareArchiveMockRecorder {
+	return m.recorder
+}
+
+// Messages mocks base method.
+func (m *MockhareArchive) Messages(arg0 haremsgs.Filter) ([]*hare3.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Messages", arg0)
+	ret0, _ := ret[0].([]*hare3.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Messages indicates an expected call of Messages.
+func (mr *MockhareArchiveMockRecorder) Messages(arg0 any) *MockhareArchiveMessagesCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRewardsByCoinbase", reflect.TypeOf((*MockmeshAPI)(nil).GetRewardsByCoinbase), arg0)
-	return &MockmeshAPIGetRewardsByCoinbaseCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Messages", reflect.TypeOf((*MockhareArchive)(nil).Messages), arg0)
+	return &MockhareArchiveMessagesCall{Call: call}
 }
 
-// MockmeshAPIGetRewardsByCoinbaseCall wrap *gomock.Call
-type MockmeshAPIGetRewardsByCoinbaseCall struct {
+// MockhareArchiveMessagesCall wrap *gomock.Call
+type MockhareArchiveMessagesCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPIGetRewardsByCoinbaseCall) Return(arg0 []*types.Reward, arg1 error) *MockmeshAPIGetRewardsByCoinbaseCall {
+func (c *MockhareArchiveMessagesCall) Return(arg0 []*hare3.Message, arg1 error) *MockhareArchiveMessagesCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPIGetRewardsByCoinbaseCall) Do(f func(types.Address) ([]*types.Reward, error)) *MockmeshAPIGetRewardsByCoinbaseCall {
+func (c *MockhareArchiveMessagesCall) Do(f func(haremsgs.Filter) ([]*hare3.Message, error)) *MockhareArchiveMessagesCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPIGetRewardsByCoinbaseCall) DoAndReturn(f func(types.Address) ([]*types.Reward, error)) *MockmeshAPIGetRewardsByCoinbaseCall {
+func (c *MockhareArchiveMessagesCall) DoAndReturn(f func(haremsgs.Filter) ([]*hare3.Message, error)) *MockhareArchiveMessagesCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// GetRewardsBySmesherId mocks base method.
-func (m *MockmeshAPI) GetRewardsBySmesherId(id types.NodeID) ([]*types.Reward, error) {
+// MockhareParticipation is a mock of hareParticipation interface.
+type MockhareParticipation struct {
+	ctrl     *gomock.Controller
+	recorder *MockhareParticipationMockRecorder
+}
+
+// MockhareParticipationMockRecorder is the mock recorder for MockhareParticipation.
+type MockhareParticipationMockRecorder struct {
+	mock *MockhareParticipation
+}
+
+// NewMockhareParticipation creates a new mock instance.
+func NewMockhareParticipation(ctrl *gomock.Controller) *MockhareParticipation {
+	mock := &MockhareParticipation{ctrl: ctrl}
+	mock.recorder = &MockhareParticipationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhareParticipation) EXPECT() *MockhareParticipationMockRecorder {
+	return m.recorder
+}
+
+// Participation mocks base method.
+func (m *MockhareParticipation) Participation() ([]hare3.Participation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRewardsBySmesherId", id)
-	ret0, _ := ret[0].([]*types.Reward)
+	ret := m.ctrl.Call(m, "Participation")
+	ret0, _ := ret[0].([]hare3.Participation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRewardsBySmesherId indicates an expected call of GetRewardsBySmesherId.
-func (mr *MockmeshAPIMockRecorder) GetRewardsBySmesherId(id any) *MockmeshAPIGetRewardsBySmesherIdCall {
+// Participation indicates an expected call of Participation.
+func (mr *MockhareParticipationMockRecorder) Participation() *MockhareParticipationParticipationCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRewardsBySmesherId", reflect.TypeOf((*MockmeshAPI)(nil).GetRewardsBySmesherId), id)
-	return &MockmeshAPIGetRewardsBySmesherIdCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Participation", reflect.TypeOf((*MockhareParticipation)(nil).Participation))
+	return &MockhareParticipationParticipationCall{Call: call}
 }
 
-// MockmeshAPIGetRewardsBySmesherIdCall wrap *gomock.Call
-type MockmeshAPIGetRewardsBySmesherIdCall struct {
+// MockhareParticipationParticipationCall wrap *gomock.Call
+type MockhareParticipationParticipationCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPIGetRewardsBySmesherIdCall) Return(arg0 []*types.Reward, arg1 error) *MockmeshAPIGetRewardsBySmesherIdCall {
+func (c *MockhareParticipationParticipationCall) Return(arg0 []hare3.Participation, arg1 error) *MockhareParticipationParticipationCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPIGetRewardsBySmesherIdCall) Do(f func(types.NodeID) ([]*types.Reward, error)) *MockmeshAPIGetRewardsBySmesherIdCall {
+func (c *MockhareParticipationParticipationCall) Do(f func() ([]hare3.Participation, error)) *MockhareParticipationParticipationCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPIGetRewardsBySmesherIdCall) DoAndReturn(f func(types.NodeID) ([]*types.Reward, error)) *MockmeshAPIGetRewardsBySmesherIdCall {
+func (c *MockhareParticipationParticipationCall) DoAndReturn(f func() ([]hare3.Participation, error)) *MockhareParticipationParticipationCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// LatestLayer mocks base method.
-func (m *MockmeshAPI) LatestLayer() types.LayerID {
+// MockhareStats is a mock of hareStats interface.
+type MockhareStats struct {
+	ctrl     *gomock.Controller
+	recorder *MockhareStatsMockRecorder
+}
+
+// MockhareStatsMockRecorder is the mock recorder for MockhareStats.
+type MockhareStatsMockRecorder struct {
+	mock *MockhareStats
+}
+
+// NewMockhareStats creates a new mock instance.
+func NewMockhareStats(ctrl *gomock.Controller) *MockhareStats {
+	mock := &MockhareStats{ctrl: ctrl}
+	mock.recorder = &MockhareStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhareStats) EXPECT() *MockhareStatsMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockhareStats) Get(arg0 types.LayerID) (*harestats.Stats, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LatestLayer")
-	ret0, _ := ret[0].(types.LayerID)
-	return ret0
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*harestats.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// LatestLayer indicates an expected call of LatestLayer.
-func (mr *MockmeshAPIMockRecorder) LatestLayer() *MockmeshAPILatestLayerCall {
+// Get indicates an expected call of Get.
+func (mr *MockhareStatsMockRecorder) Get(arg0 any) *MockhareStatsGetCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestLayer", reflect.TypeOf((*MockmeshAPI)(nil).LatestLayer))
-	return &MockmeshAPILatestLayerCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockhareStats)(nil).Get), arg0)
+	return &MockhareStatsGetCall{Call: call}
 }
 
-// MockmeshAPILatestLayerCall wrap *gomock.Call
-type MockmeshAPILatestLayerCall struct {
+// MockhareStatsGetCall wrap *gomock.Call
+type MockhareStatsGetCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPILatestLayerCall) Return(arg0 types.LayerID) *MockmeshAPILatestLayerCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockhareStatsGetCall) Return(arg0 *harestats.Stats, arg1 error) *MockhareStatsGetCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPILatestLayerCall) Do(f func() types.LayerID) *MockmeshAPILatestLayerCall {
+func (c *MockhareStatsGetCall) Do(f func(types.LayerID) (*harestats.Stats, error)) *MockhareStatsGetCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPILatestLayerCall) DoAndReturn(f func() types.LayerID) *MockmeshAPILatestLayerCall {
+func (c *MockhareStatsGetCall) DoAndReturn(f func(types.LayerID) (*harestats.Stats, error)) *MockhareStatsGetCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// LatestLayerInState mocks base method.
-func (m *MockmeshAPI) LatestLayerInState() types.LayerID {
+// Range mocks base method.
+func (m *MockhareStats) Range(from, to types.LayerID) ([]*harestats.Stats, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LatestLayerInState")
-	ret0, _ := ret[0].(types.LayerID)
+	ret := m.ctrl.Call(m, "Range", from, to)
+	ret0, _ := ret[0].([]*harestats.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockhareStatsMockRecorder) Range(from, to any) *MockhareStatsRangeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockhareStats)(nil).Range), from, to)
+	return &MockhareStatsRangeCall{Call: call}
+}
+
+// MockhareStatsRangeCall wrap *gomock.Call
+type MockhareStatsRangeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockhareStatsRangeCall) Return(arg0 []*harestats.Stats, arg1 error) *MockhareStatsRangeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockhareStatsRangeCall) Do(f func(types.LayerID, types.LayerID) ([]*harestats.Stats, error)) *MockhareStatsRangeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockhareStatsRangeCall) DoAndReturn(f func(types.LayerID, types.LayerID) ([]*harestats.Stats, error)) *MockhareStatsRangeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockdbStats is a mock of dbStats interface.
+type MockdbStats struct {
+	ctrl     *gomock.Controller
+	recorder *MockdbStatsMockRecorder
+}
+
+// MockdbStatsMockRecorder is the mock recorder for MockdbStats.
+type MockdbStatsMockRecorder struct {
+	mock *MockdbStats
+}
+
+// NewMockdbStats creates a new mock instance.
+func NewMockdbStats(ctrl *gomock.Controller) *MockdbStats {
+	mock := &MockdbStats{ctrl: ctrl}
+	mock.recorder = &MockdbStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdbStats) EXPECT() *MockdbStatsMockRecorder {
+	return m.recorder
+}
+
+// Stats mocks base method.
+func (m *MockdbStats) Stats() metrics.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(metrics.Stats)
 	return ret0
 }
 
-// LatestLayerInState indicates an expected call of LatestLayerInState.
-func (mr *MockmeshAPIMockRecorder) LatestLayerInState() *MockmeshAPILatestLayerInStateCall {
+// Stats indicates an expected call of Stats.
+func (mr *MockdbStatsMockRecorder) Stats() *MockdbStatsStatsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestLayerInState", reflect.TypeOf((*MockmeshAPI)(nil).LatestLayerInState))
-	return &MockmeshAPILatestLayerInStateCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockdbStats)(nil).Stats))
+	return &MockdbStatsStatsCall{Call: call}
 }
 
-// MockmeshAPILatestLayerInStateCall wrap *gomock.Call
-type MockmeshAPILatestLayerInStateCall struct {
+// MockdbStatsStatsCall wrap *gomock.Call
+type MockdbStatsStatsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPILatestLayerInStateCall) Return(arg0 types.LayerID) *MockmeshAPILatestLayerInStateCall {
+func (c *MockdbStatsStatsCall) Return(arg0 metrics.Stats) *MockdbStatsStatsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPILatestLayerInStateCall) Do(f func() types.LayerID) *MockmeshAPILatestLayerInStateCall {
+func (c *MockdbStatsStatsCall) Do(f func() metrics.Stats) *MockdbStatsStatsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPILatestLayerInStateCall) DoAndReturn(f func() types.LayerID) *MockmeshAPILatestLayerInStateCall {
+func (c *MockdbStatsStatsCall) DoAndReturn(f func() metrics.Stats) *MockdbStatsStatsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// MeshHash mocks base method.
-func (m *MockmeshAPI) MeshHash(arg0 types.LayerID) (types.Hash32, error) {
+// MocklayerPatrol is a mock of layerPatrol interface.
+type MocklayerPatrol struct {
+	ctrl     *gomock.Controller
+	recorder *MocklayerPatrolMockRecorder
+}
+
+// MocklayerPatrolMockRecorder is the mock recorder for MocklayerPatrol.
+type MocklayerPatrolMockRecorder struct {
+	mock *MocklayerPatrol
+}
+
+// NewMocklayerPatrol creates a new mock instance.
+func NewMocklayerPatrol(ctrl *gomock.Controller) *MocklayerPatrol {
+	mock := &MocklayerPatrol{ctrl: ctrl}
+	mock.recorder = &MocklayerPatrolMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocklayerPatrol) EXPECT() *MocklayerPatrolMockRecorder {
+	return m.recorder
+}
+
+// Status mocks base method.
+func (m *MocklayerPatrol) Status(arg0 types.LayerID) (layerpatrol.LayerStatus, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MeshHash", arg0)
-	ret0, _ := ret[0].(types.Hash32)
-	ret1, _ := ret[1].(error)
+	ret := m.ctrl.Call(m, "Status", arg0)
+	ret0, _ := ret[0].(layerpatrol.LayerStatus)
+	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
-// MeshHash indicates an expected call of MeshHash.
-func (mr *MockmeshAPIMockRecorder) MeshHash(arg0 any) *MockmeshAPIMeshHashCall {
+// Status indicates an expected call of Status.
+func (mr *MocklayerPatrolMockRecorder) Status(arg0 any) *MocklayerPatrolStatusCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeshHash", reflect.TypeOf((*MockmeshAPI)(nil).MeshHash), arg0)
-	return &MockmeshAPIMeshHashCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MocklayerPatrol)(nil).Status), arg0)
+	return &MocklayerPatrolStatusCall{Call: call}
 }
 
-// MockmeshAPIMeshHashCall wrap *gomock.Call
-type MockmeshAPIMeshHashCall struct {
+// MocklayerPatrolStatusCall wrap *gomock.Call
+type MocklayerPatrolStatusCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPIMeshHashCall) Return(arg0 types.Hash32, arg1 error) *MockmeshAPIMeshHashCall {
+func (c *MocklayerPatrolStatusCall) Return(arg0 layerpatrol.LayerStatus, arg1 bool) *MocklayerPatrolStatusCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPIMeshHashCall) Do(f func(types.LayerID) (types.Hash32, error)) *MockmeshAPIMeshHashCall {
+func (c *MocklayerPatrolStatusCall) Do(f func(types.LayerID) (layerpatrol.LayerStatus, bool)) *MocklayerPatrolStatusCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPIMeshHashCall) DoAndReturn(f func(types.LayerID) (types.Hash32, error)) *MockmeshAPIMeshHashCall {
+func (c *MocklayerPatrolStatusCall) DoAndReturn(f func(types.LayerID) (layerpatrol.LayerStatus, bool)) *MocklayerPatrolStatusCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ProcessedLayer mocks base method.
-func (m *MockmeshAPI) ProcessedLayer() types.LayerID {
+// Waiting mocks base method.
+func (m *MocklayerPatrol) Waiting() map[types.LayerID]layerpatrol.LayerStatus {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessedLayer")
-	ret0, _ := ret[0].(types.LayerID)
+	ret := m.ctrl.Call(m, "Waiting")
+	ret0, _ := ret[0].(map[types.LayerID]layerpatrol.LayerStatus)
 	return ret0
 }
 
-// ProcessedLayer indicates an expected call of ProcessedLayer.
-func (mr *MockmeshAPIMockRecorder) ProcessedLayer() *MockmeshAPIProcessedLayerCall {
+// Waiting indicates an expected call of Waiting.
+func (mr *MocklayerPatrolMockRecorder) Waiting() *MocklayerPatrolWaitingCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessedLayer", reflect.TypeOf((*MockmeshAPI)(nil).ProcessedLayer))
-	return &MockmeshAPIProcessedLayerCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Waiting", reflect.TypeOf((*MocklayerPatrol)(nil).Waiting))
+	return &MocklayerPatrolWaitingCall{Call: call}
 }
 
-// MockmeshAPIProcessedLayerCall wrap *gomock.Call
-type MockmeshAPIProcessedLayerCall struct {
+// MocklayerPatrolWaitingCall wrap *gomock.Call
+type MocklayerPatrolWaitingCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockmeshAPIProcessedLayerCall) Return(arg0 types.LayerID) *MockmeshAPIProcessedLayerCall {
+func (c *MocklayerPatrolWaitingCall) Return(arg0 map[types.LayerID]layerpatrol.LayerStatus) *MocklayerPatrolWaitingCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockmeshAPIProcessedLayerCall) Do(f func() types.LayerID) *MockmeshAPIProcessedLayerCall {
+func (c *MocklayerPatrolWaitingCall) Do(f func() map[types.LayerID]layerpatrol.LayerStatus) *MocklayerPatrolWaitingCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockmeshAPIProcessedLayerCall) DoAndReturn(f func() types.LayerID) *MockmeshAPIProcessedLayerCall {
+func (c *MocklayerPatrolWaitingCall) DoAndReturn(f func() map[types.LayerID]layerpatrol.LayerStatus) *MocklayerPatrolWaitingCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -2054,3 +2986,81 @@ func (c *MockoracleActiveSetCall) DoAndReturn(f func(context.Context, types.Epoc
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// ExpectedCommittee mocks base method.
+func (m *Mockoracle) ExpectedCommittee(arg0 context.Context, arg1 types.LayerID, arg2 int) ([]eligibility.Seats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpectedCommittee", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]eligibility.Seats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpectedCommittee indicates an expected call of ExpectedCommittee.
+func (mr *MockoracleMockRecorder) ExpectedCommittee(arg0, arg1, arg2 any) *MockoracleExpectedCommitteeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpectedCommittee", reflect.TypeOf((*Mockoracle)(nil).ExpectedCommittee), arg0, arg1, arg2)
+	return &MockoracleExpectedCommitteeCall{Call: call}
+}
+
+// MockoracleExpectedCommitteeCall wrap *gomock.Call
+type MockoracleExpectedCommitteeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockoracleExpectedCommitteeCall) Return(arg0 []eligibility.Seats, arg1 error) *MockoracleExpectedCommitteeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockoracleExpectedCommitteeCall) Do(f func(context.Context, types.LayerID, int) ([]eligibility.Seats, error)) *MockoracleExpectedCommitteeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockoracleExpectedCommitteeCall) DoAndReturn(f func(context.Context, types.LayerID, int) ([]eligibility.Seats, error)) *MockoracleExpectedCommitteeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Participation mocks base method.
+func (m *Mockoracle) Participation(arg0 context.Context, arg1 types.EpochID, arg2 types.NodeID, arg3 int) (eligibility.Participation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Participation", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(eligibility.Participation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Participation indicates an expected call of Participation.
+func (mr *MockoracleMockRecorder) Participation(arg0, arg1, arg2, arg3 any) *MockoracleParticipationCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Participation", reflect.TypeOf((*Mockoracle)(nil).Participation), arg0, arg1, arg2, arg3)
+	return &MockoracleParticipationCall{Call: call}
+}
+
+// MockoracleParticipationCall wrap *gomock.Call
+type MockoracleParticipationCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockoracleParticipationCall) Return(arg0 eligibility.Participation, arg1 error) *MockoracleParticipationCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockoracleParticipationCall) Do(f func(context.Context, types.EpochID, types.NodeID, int) (eligibility.Participation, error)) *MockoracleParticipationCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockoracleParticipationCall) DoAndReturn(f func(context.Context, types.EpochID, types.NodeID, int) (eligibility.Participation, error)) *MockoracleParticipationCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
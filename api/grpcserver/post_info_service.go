@@ -2,7 +2,13 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
 
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
 	"go.uber.org/zap"
@@ -14,8 +20,24 @@ import (
 var statusMap map[types.PostState]pb.PostState_State = map[types.PostState]pb.PostState_State{
 	types.PostStateIdle:    pb.PostState_IDLE,
 	types.PostStateProving: pb.PostState_PROVING,
+	// api doesn't have a dedicated state for identities with invalid post data, they don't need
+	// the post service as smeshing is refused for them. The state is served by PostStatesPath.
+	types.PostStateInvalid: pb.PostState_IDLE,
+	// neither for identities that can't talk to their poets, they don't need the post service
+	// until their poets are upgraded or replaced
+	types.PostStatePoetUnsupported: pb.PostState_IDLE,
+	// the post service is not used while the ATX is published
+	types.PostStatePublishing: pb.PostState_IDLE,
+	// nor for identities that fail repeatedly, a degraded identity is idle until it retries
+	// and paused identities don't need the post service until they are resumed
+	types.PostStateDegraded: pb.PostState_IDLE,
+	types.PostStatePaused:   pb.PostState_IDLE,
 }
 
+// PostStatesPath is the JSON API path that returns the states of identities including those
+// the post info service proto has no dedicated state for, see PostStateResponse.
+const PostStatesPath = "/v1/post/states"
+
 // PostInfoService provides information about connected PostServices.
 type PostInfoService struct {
 	log *zap.Logger
@@ -32,6 +54,12 @@ func (s *PostInfoService) RegisterHandlerService(mux *runtime.ServeMux) error {
 	return pb.RegisterPostInfoServiceHandlerServer(context.Background(), mux, s)
 }
 
+// RegisterDebugHandlers registers the endpoints of the post info service that have no counterpart
+// in the api protobufs, they are served only on the debug JSON listener.
+func (s *PostInfoService) RegisterDebugHandlers(mux *runtime.ServeMux) error {
+	return mux.HandlePath(http.MethodGet, PostStatesPath, s.postStates)
+}
+
 // String returns the name of this service.
 func (s *PostInfoService) String() string {
 	return "PostInfoService"
@@ -58,3 +86,29 @@ func (s *PostInfoService) PostStates(context.Context, *pb.PostStatesRequest) (*p
 
 	return &pb.PostStatesResponse{States: pbStates}, nil
 }
+
+// PostStateResponse is returned for every identity by the states endpoint of the post info service.
+// State is one of the names of types.PostState.
+type PostStateResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// postStates returns the states of identities by their names rather than by the proto states.
+func (s *PostInfoService) postStates(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	states := s.states.PostStates()
+	resp := make([]PostStateResponse, 0, len(states))
+	for id, state := range states {
+		resp = append(resp, PostStateResponse{
+			ID:    hex.EncodeToString(id.NodeID().Bytes()),
+			Name:  id.Name(),
+			State: state.String(),
+		})
+	}
+	slices.SortFunc(resp, func(a, b PostStateResponse) int { return strings.Compare(a.ID, b.ID) })
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write post states response", zap.Error(err))
+	}
+}
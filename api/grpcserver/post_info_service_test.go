@@ -2,6 +2,10 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"testing"
 
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
@@ -62,3 +66,28 @@ func TestPostInfoService(t *testing.T) {
 		})
 	}
 }
+
+func TestPostInfoService_JSONStates(t *testing.T) {
+	mpostStates := NewMockpostState(gomock.NewController(t))
+	svc := NewPostInfoService(zaptest.NewLogger(t), mpostStates)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	idle := newIdMock("idle.key")
+	invalid := newIdMock("invalid.key")
+	mpostStates.EXPECT().PostStates().Return(map[types.IdentityDescriptor]types.PostState{
+		idle:    types.PostStateIdle,
+		invalid: types.PostStateInvalid,
+	})
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, PostStatesPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got []PostStateResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.ElementsMatch(t, []PostStateResponse{
+		{ID: hex.EncodeToString(idle.id.Bytes()), Name: idle.name, State: "idle"},
+		{ID: hex.EncodeToString(invalid.id.Bytes()), Name: invalid.name, State: "invalid"},
+	}, got)
+}
@@ -2,8 +2,13 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
@@ -19,10 +24,131 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/signing"
 )
 
+// ResumeSmeshingPath is the JSON API path that resumes attempts to publish ATXs of an identity
+// that were paused after repeated failures. The identity is the hex encoded node ID.
+const ResumeSmeshingPath = "/v1/smesher/identities/{id}/resume"
+
+// NIPostErrorsPath is the JSON API path that returns the errors of the latest failed attempts
+// to build a NIPoST of the identities, see NIPostErrorResponse. It is served only on the debug
+// JSON listener.
+const NIPostErrorsPath = "/v1/smesher/nipost/errors"
+
+// AttestationsPath is the JSON API path that returns signed attestations of the smeshing status
+// of the identities, see AttestationResponse.
+const AttestationsPath = "/v1/smesher/attestations"
+
+// PoetsPath is the JSON API path that returns the poets used by the node with the settings
+// in effect for them, see PoetResponse.
+const PoetsPath = "/v1/smesher/poets"
+
+// PoetsPreflightPath is the JSON API path that runs the checks of a submission of the identity
+// to every poet without registering, see PoetPreflightResponse. The identity is the hex encoded node ID.
+// It is served on the debug JSON listener, the endpoint responds 503 if the node doesn't smesh.
+const PoetsPreflightPath = "/v1/smesher/identities/{id}/poets/preflight"
+
+// ReadinessPath is the JSON API path that returns the last readiness report of the identity on GET,
+// and checks the readiness of the identity on POST, see ReadinessResponse. The identity is the hex
+// encoded node ID. Both methods are served on the debug JSON listener.
+const ReadinessPath = "/v1/smesher/identities/{id}/readiness"
+
+// PostTooSlowPath is the JSON API path that streams reports of PoST generation that barely fits
+// or doesn't fit into the poet cycle gap, as newline delimited PostTooSlowResponse.
+const PostTooSlowPath = "/v1/smesher/post/too_slow"
+
+// PostTooSlowResponse reports that PoST generation of an identity left less than the warning margin
+// of the cycle gap of its poets. Durations are formatted as Go durations.
+type PostTooSlowResponse struct {
+	// ID is the hex encoded node ID of the identity.
+	ID       string `json:"id"`
+	Duration string `json:"duration"`
+	CycleGap string `json:"cycle_gap"`
+	// Fallback is true if the identity uses the fallback poets.
+	Fallback bool `json:"fallback"`
+}
+
+// PoetResponse describes a poet and the settings in effect for it, after per-poet overrides
+// are applied. Durations are formatted as Go durations, e.g. "1m30s".
+type PoetResponse struct {
+	Address           string `json:"address"`
+	Fallback          bool   `json:"fallback"`
+	Transport         string `json:"transport"`
+	CycleGap          string `json:"cycle_gap"`
+	RequestTimeout    string `json:"request_timeout"`
+	RequestRetryDelay string `json:"request_retry_delay"`
+	MaxRequestRetries int    `json:"max_request_retries"`
+	MinProofJitter    string `json:"min_proof_jitter"`
+	MaxProofJitter    string `json:"max_proof_jitter"`
+	ProofTimeout      string `json:"proof_timeout"`
+	Tier              string `json:"tier"`
+	Weight            int    `json:"weight"`
+}
+
+// PoetPreflightResponse describes whether the identity can register with a poet.
+// Error is the failed check, empty if the identity can register. CertError is why no certificate
+// was obtained, the submission is authorized with PoW then.
+type PoetPreflightResponse struct {
+	Address        string     `json:"address"`
+	Fallback       bool       `json:"fallback"`
+	Error          string     `json:"error,omitempty"`
+	Auth           string     `json:"auth,omitempty"`
+	CertExpiration *time.Time `json:"cert_expiration,omitempty"`
+	CertError      string     `json:"cert_error,omitempty"`
+	PowDifficulty  uint       `json:"pow_difficulty,omitempty"`
+	RoundStart     time.Time  `json:"round_start"`
+}
+
+// ReadinessResponse is the readiness of an identity to register for the poet round, that the ATX
+// published in PublishEpoch is built for. Score is the share of the checks that passed.
+type ReadinessResponse struct {
+	// ID is the hex encoded node ID of the identity.
+	ID           string                   `json:"id"`
+	PublishEpoch uint32                   `json:"publish_epoch"`
+	Time         time.Time                `json:"time"`
+	Score        float64                  `json:"score"`
+	Checks       []ReadinessCheckResponse `json:"checks"`
+}
+
+// ReadinessCheckResponse is the outcome of a readiness check. Skipped checks don't count towards
+// the score, Error is empty if the check passed.
+type ReadinessCheckResponse struct {
+	Name    string `json:"name"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AttestationResponse is the smeshing status of an identity signed by its key. Attestation is
+// the hex encoded scale encoding of the signed status, it can be verified off-chain.
+type AttestationResponse struct {
+	// ID is the hex encoded node ID of the identity.
+	ID        string `json:"id"`
+	Epoch     uint32 `json:"epoch"`
+	Timestamp uint64 `json:"timestamp"`
+	// ATX is the hex encoded ID of the ATX that makes the identity eligible in Epoch, empty if there is none.
+	ATX         string `json:"atx,omitempty"`
+	NumUnits    uint32 `json:"num_units"`
+	PostState   string `json:"post_state"`
+	Attestation string `json:"attestation"`
+}
+
+// NIPostErrorResponse describes the error of the latest failed attempt to build a NIPoST of an identity.
+type NIPostErrorResponse struct {
+	// ID is the hex encoded node ID of the identity.
+	ID           string    `json:"id"`
+	Phase        string    `json:"phase"`
+	PublishEpoch uint32    `json:"publish_epoch"`
+	Poet         string    `json:"poet,omitempty"`
+	Round        string    `json:"round,omitempty"`
+	Deadline     time.Time `json:"deadline"`
+	Retryable    bool      `json:"retryable"`
+	Error        string    `json:"error"`
+}
+
 // SmesherService exposes endpoints to manage smeshing.
 type SmesherService struct {
 	smeshingProvider activation.SmeshingProvider
@@ -33,6 +159,8 @@ type SmesherService struct {
 	cmdCfg         *activation.PostSupervisorConfig
 	postOpts       activation.PostSetupOpts
 	sig            *signing.EdSigner
+	poets          poetsProvider
+	readiness      readinessProvider
 }
 
 // RegisterService registers this service with a grpc server instance.
@@ -44,6 +172,33 @@ func (s *SmesherService) RegisterHandlerService(mux *runtime.ServeMux) error {
 	return pb.RegisterSmesherServiceHandlerServer(context.Background(), mux, s)
 }
 
+// RegisterDebugHandlers registers the endpoints of the smesher service that have no counterpart
+// in the api protobufs, they are served only on the debug JSON listener.
+func (s *SmesherService) RegisterDebugHandlers(mux *runtime.ServeMux) error {
+	if err := mux.HandlePath(http.MethodPost, ResumeSmeshingPath, s.resumeSmeshing); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, NIPostErrorsPath, s.nipostErrors); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, AttestationsPath, s.attestations); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, PoetsPath, s.listPoets); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodPost, PoetsPreflightPath, s.preflightPoets); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, PostTooSlowPath, s.postTooSlow); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, ReadinessPath, s.readinessReport); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodPost, ReadinessPath, s.checkReadiness)
+}
+
 // String returns the name of this service.
 func (s *SmesherService) String() string {
 	return "SmesherService"
@@ -73,6 +228,16 @@ func (s *SmesherService) SetPostServiceConfig(cfg activation.PostSupervisorConfi
 	s.cmdCfg = &cfg
 }
 
+// SetPoets sets the provider of the poets used by the node.
+func (s *SmesherService) SetPoets(poets poetsProvider) {
+	s.poets = poets
+}
+
+// SetReadiness sets the checker of the readiness of the identities.
+func (s *SmesherService) SetReadiness(readiness readinessProvider) {
+	s.readiness = readiness
+}
+
 // IsSmeshing reports whether the node is smeshing.
 func (s *SmesherService) IsSmeshing(context.Context, *emptypb.Empty) (*pb.IsSmeshingResponse, error) {
 	if s.sig == nil {
@@ -145,6 +310,214 @@ func (s *SmesherService) postSetupOpts(in *pb.PostSetupOpts) (activation.PostSet
 	return opts, nil
 }
 
+// resumeSmeshing resumes a paused identity.
+func (s *SmesherService) resumeSmeshing(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	raw, err := hex.DecodeString(params["id"])
+	if err != nil || len(raw) != types.NodeIDSize {
+		http.Error(w, fmt.Sprintf("failed to parse node id `%s`", params["id"]), http.StatusBadRequest)
+		return
+	}
+	id := types.BytesToNodeID(raw)
+	switch err := s.smeshingProvider.ResumeSmeshing(id); {
+	case errors.Is(err, activation.ErrNotPaused):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	ctxzap.Info(r.Context(), "resumed smeshing", zap.Stringer("id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// nipostErrors returns why building the NIPoST of identities failed recently.
+func (s *SmesherService) nipostErrors(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	errs := s.smeshingProvider.NIPostErrors()
+	resp := make([]NIPostErrorResponse, 0, len(errs))
+	for id, err := range errs {
+		resp = append(resp, NIPostErrorResponse{
+			ID:           hex.EncodeToString(id.Bytes()),
+			Phase:        string(err.Phase),
+			PublishEpoch: err.PublishEpoch.Uint32(),
+			Poet:         err.Poet,
+			Round:        err.Round,
+			Deadline:     err.Deadline,
+			Retryable:    err.Retryable,
+			Error:        err.Err.Error(),
+		})
+	}
+	slices.SortFunc(resp, func(a, b NIPostErrorResponse) int { return strings.Compare(a.ID, b.ID) })
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write nipost errors response", zap.Error(err))
+	}
+}
+
+// attestations returns signed attestations of the smeshing status of all identities.
+func (s *SmesherService) attestations(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	attestations, err := s.smeshingProvider.Attest()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to attest: %s", err), http.StatusInternalServerError)
+		return
+	}
+	resp := make([]AttestationResponse, 0, len(attestations))
+	for _, attestation := range attestations {
+		status := attestation.Status
+		a := AttestationResponse{
+			ID:          hex.EncodeToString(attestation.SmesherID.Bytes()),
+			Epoch:       status.Epoch.Uint32(),
+			Timestamp:   status.Timestamp,
+			NumUnits:    status.NumUnits,
+			PostState:   types.PostState(status.PostState).String(),
+			Attestation: hex.EncodeToString(codec.MustEncode(attestation)),
+		}
+		if status.ATX != types.EmptyATXID {
+			a.ATX = hex.EncodeToString(status.ATX.Bytes())
+		}
+		resp = append(resp, a)
+	}
+	slices.SortFunc(resp, func(a, b AttestationResponse) int { return strings.Compare(a.ID, b.ID) })
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write attestations response", zap.Error(err))
+	}
+}
+
+// listPoets returns the poets used by the node with their effective settings.
+func (s *SmesherService) listPoets(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if s.poets == nil {
+		http.Error(w, "node is not configured for smeshing", http.StatusServiceUnavailable)
+		return
+	}
+	poets := s.poets.Poets()
+	resp := make([]PoetResponse, 0, len(poets))
+	for _, poet := range poets {
+		resp = append(resp, PoetResponse{
+			Address:           poet.Address,
+			Fallback:          poet.Fallback,
+			Transport:         string(poet.Transport),
+			CycleGap:          poet.CycleGap.String(),
+			RequestTimeout:    poet.RequestTimeout.String(),
+			RequestRetryDelay: poet.RequestRetryDelay.String(),
+			MaxRequestRetries: poet.MaxRequestRetries,
+			MinProofJitter:    poet.MinProofJitter.String(),
+			MaxProofJitter:    poet.MaxProofJitter.String(),
+			ProofTimeout:      poet.ProofTimeout.String(),
+			Tier:              string(poet.Tier),
+			Weight:            poet.Weight,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write poets response", zap.Error(err))
+	}
+}
+
+// preflightPoets checks whether the identity can register with the poets, so that operators can
+// validate the configuration before the round opens. Certificates obtained by the checks are kept.
+func (s *SmesherService) preflightPoets(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if s.poets == nil {
+		http.Error(w, "node is not configured for smeshing", http.StatusServiceUnavailable)
+		return
+	}
+	id, ok := s.managedIdentity(w, params["id"])
+	if !ok {
+		return
+	}
+	results := s.poets.Preflight(r.Context(), id)
+	resp := make([]PoetPreflightResponse, 0, len(results))
+	for _, rst := range results {
+		poet := PoetPreflightResponse{
+			Address:        rst.Address,
+			Fallback:       rst.Fallback,
+			Auth:           string(rst.Auth),
+			CertExpiration: rst.CertExpiration,
+			PowDifficulty:  rst.PowDifficulty,
+			RoundStart:     rst.RoundStart,
+		}
+		if rst.Err != nil {
+			poet.Error = rst.Err.Error()
+		}
+		if rst.CertErr != nil {
+			poet.CertError = rst.CertErr.Error()
+		}
+		resp = append(resp, poet)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write poets preflight response", zap.Error(err))
+	}
+}
+
+// managedIdentity parses the hex encoded node ID of an identity managed by the node.
+// It writes the error response and returns false if the ID is invalid or the identity is not managed.
+func (s *SmesherService) managedIdentity(w http.ResponseWriter, param string) (types.NodeID, bool) {
+	raw, err := hex.DecodeString(param)
+	if err != nil || len(raw) != types.NodeIDSize {
+		http.Error(w, fmt.Sprintf("failed to parse node id `%s`", param), http.StatusBadRequest)
+		return types.EmptyNodeID, false
+	}
+	id := types.BytesToNodeID(raw)
+	if !slices.Contains(s.smeshingProvider.SmesherIDs(), id) {
+		http.Error(w, fmt.Sprintf("identity %s is not managed by the node", id), http.StatusNotFound)
+		return types.EmptyNodeID, false
+	}
+	return id, true
+}
+
+// readinessReport returns the report of the last readiness check of the identity, that runs every epoch
+// before the poet registration window opens.
+func (s *SmesherService) readinessReport(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if s.readiness == nil {
+		http.Error(w, "node is not configured for smeshing", http.StatusServiceUnavailable)
+		return
+	}
+	id, ok := s.managedIdentity(w, params["id"])
+	if !ok {
+		return
+	}
+	report, ok := s.readiness.Report(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("readiness of identity %s wasn't checked yet", id), http.StatusNotFound)
+		return
+	}
+	writeReadiness(w, r, report)
+}
+
+// checkReadiness checks the readiness of the identity now, the report replaces the last one.
+func (s *SmesherService) checkReadiness(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if s.readiness == nil {
+		http.Error(w, "node is not configured for smeshing", http.StatusServiceUnavailable)
+		return
+	}
+	id, ok := s.managedIdentity(w, params["id"])
+	if !ok {
+		return
+	}
+	writeReadiness(w, r, s.readiness.Check(r.Context(), id))
+}
+
+func writeReadiness(w http.ResponseWriter, r *http.Request, report *activation.ReadinessReport) {
+	resp := ReadinessResponse{
+		ID:           hex.EncodeToString(report.NodeID.Bytes()),
+		PublishEpoch: report.PublishEpoch.Uint32(),
+		Time:         report.Time,
+		Score:        report.Score(),
+		Checks:       make([]ReadinessCheckResponse, 0, len(report.Checks)),
+	}
+	for _, check := range report.Checks {
+		rc := ReadinessCheckResponse{Name: check.Name, Skipped: check.Skipped}
+		if check.Err != nil {
+			rc.Error = check.Err.Error()
+		}
+		resp.Checks = append(resp.Checks, rc)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write readiness response", zap.Error(err))
+	}
+}
+
 // StopSmeshing requests that the node stop smeshing.
 func (s *SmesherService) StopSmeshing(
 	ctx context.Context,
@@ -316,3 +689,15 @@ func statusToPbStatus(status *activation.PostSetupStatus) *pb.PostSetupStatus {
 
 	return pbStatus
 }
+
+// postTooSlow streams reports of PoST generation that is too slow for the poet cycle gap.
+func (s *SmesherService) postTooSlow(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	streamJSON(w, r, events.SubscribePostTooSlow(), func(ev events.EventPostTooSlow) any {
+		return PostTooSlowResponse{
+			ID:       hex.EncodeToString(ev.Smesher.Bytes()),
+			Duration: ev.Duration.String(),
+			CycleGap: ev.CycleGap.String(),
+			Fallback: ev.Fallback,
+		}
+	})
+}
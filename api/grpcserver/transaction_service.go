@@ -3,9 +3,14 @@ package grpcserver
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -23,9 +28,62 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/core"
 	"github.com/spacemeshos/go-spacemesh/p2p/pubsub"
 	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/txlatency"
 	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+	"github.com/spacemeshos/go-spacemesh/txs"
 )
 
+// TransactionLatencyPath is the JSON API path that returns how long it took the transaction
+// to be included in a proposal and to be applied, see TransactionLatencyResponse.
+const TransactionLatencyPath = "/v1/transactions/{id}/latency"
+
+// TransactionStatusPath is the JSON API path that returns whether the transaction is applied,
+// and whether it returned to pending after the layer it was applied in was reverted,
+// see TransactionStatusResponse. Reverts are reported only if the latency of transactions is recorded.
+const TransactionStatusPath = "/v1/transactions/{id}/status"
+
+// TransactionReasonPath is the JSON API path that returns the reason for the current state
+// of the transaction, see TransactionReasonResponse.
+const TransactionReasonPath = "/v1/transactions/{id}/reason"
+
+// MempoolDiffsPath is the JSON API path that streams the mempool diffs of the proposals built by the node,
+// as newline delimited MempoolDiffResponse.
+const MempoolDiffsPath = "/v1/transactions/mempool/diffs"
+
+// MempoolDiffResponse describes the mempool at the time transactions were selected for a proposal,
+// and how it changed since the previous proposal. Transactions are hex encoded ids.
+type MempoolDiffResponse struct {
+	Layer      uint32   `json:"layer"`
+	Candidates int      `json:"candidates"`
+	Included   []string `json:"included"`
+	Missed     []string `json:"missed"`
+	Added      []string `json:"added"`
+	Removed    []string `json:"removed"`
+}
+
+// CertifiedTxsPath is the JSON API path that streams the transactions of the certified blocks,
+// as newline delimited CertifiedTxsResponse. Transactions are streamed before their layer is applied.
+const CertifiedTxsPath = "/v1/transactions/certified"
+
+// CertifiedTxsResponse describes the transactions included in a certified block.
+// Block and transactions are hex encoded ids.
+type CertifiedTxsResponse struct {
+	Layer uint32   `json:"layer"`
+	Block string   `json:"block"`
+	Txs   []string `json:"txs"`
+}
+
+// RevertedTxsPath is the JSON API path that streams the transactions that returned to pending
+// after the layers they were applied in were reverted, as newline delimited RevertedTxsResponse.
+const RevertedTxsPath = "/v1/transactions/reverted"
+
+// RevertedTxsResponse describes the transactions that returned to pending when the state was
+// reverted to the layer RevertTo. Transactions are hex encoded ids.
+type RevertedTxsResponse struct {
+	RevertTo uint32   `json:"revert_to"`
+	Txs      []string `json:"txs"`
+}
+
 // TransactionService exposes transaction data, and a submit tx endpoint.
 type TransactionService struct {
 	db        sql.StateDatabase
@@ -34,6 +92,19 @@ type TransactionService struct {
 	conState  conservativeState
 	syncer    syncer
 	txHandler txValidator
+	// localDB is nil unless the latency and the reverts of transactions are recorded.
+	localDB sql.LocalDatabase
+}
+
+// TransactionServiceOpt configures the transaction service.
+type TransactionServiceOpt func(*TransactionService)
+
+// WithTransactionLatency enables the latency endpoint and the reverted status, the latency
+// and the reverts of transactions are recorded in the local database.
+func WithTransactionLatency(db sql.LocalDatabase) TransactionServiceOpt {
+	return func(s *TransactionService) {
+		s.localDB = db
+	}
 }
 
 // RegisterService registers this service with a grpc server instance.
@@ -45,6 +116,27 @@ func (s *TransactionService) RegisterHandlerService(mux *runtime.ServeMux) error
 	return pb.RegisterTransactionServiceHandlerServer(context.Background(), mux, s)
 }
 
+// RegisterDebugHandlers registers the endpoints of the transaction service that have no counterpart
+// in the api protobufs, they are served only on the debug JSON listener.
+func (s *TransactionService) RegisterDebugHandlers(mux *runtime.ServeMux) error {
+	if err := mux.HandlePath(http.MethodGet, TransactionLatencyPath, s.latency); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, TransactionStatusPath, s.status); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, TransactionReasonPath, s.reason); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, MempoolDiffsPath, s.mempoolDiffs); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, CertifiedTxsPath, s.certifiedTxs); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodGet, RevertedTxsPath, s.revertedTxs)
+}
+
 // String returns the name of this service.
 func (s *TransactionService) String() string {
 	return "TransactionService"
@@ -58,8 +150,9 @@ func NewTransactionService(
 	conState conservativeState,
 	syncer syncer,
 	txHandler txValidator,
+	opts ...TransactionServiceOpt,
 ) *TransactionService {
-	return &TransactionService{
+	s := &TransactionService{
 		db:        db,
 		publisher: publisher,
 		mesh:      msh,
@@ -67,6 +160,163 @@ func NewTransactionService(
 		syncer:    syncer,
 		txHandler: txHandler,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// TransactionLatencyResponse is returned by the latency endpoint of the transaction service.
+// Latencies are measured from the time the node received the transaction and are omitted
+// until the transaction is included in a proposal or applied.
+type TransactionLatencyResponse struct {
+	Received                  time.Time `json:"received"`
+	ProposalLatencySeconds    *float64  `json:"proposal_latency_seconds,omitempty"`
+	ApplicationLatencySeconds *float64  `json:"application_latency_seconds,omitempty"`
+	Layer                     uint32    `json:"layer,omitempty"`
+}
+
+// latency gives a concrete measure of the mempool to chain latency of the transaction.
+func (s *TransactionService) latency(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if s.localDB == nil {
+		http.Error(w, "latency of transactions is not recorded", http.StatusServiceUnavailable)
+		return
+	}
+	tid, ok := parseTransactionID(w, params)
+	if !ok {
+		return
+	}
+	received, err := transactions.GetReceived(s.db, tid)
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		http.Error(w, fmt.Sprintf("transaction %s not found", tid), http.StatusNotFound)
+		return
+	case err != nil:
+		ctxzap.Error(r.Context(), "unable to fetch transaction", zap.Stringer("tx_id", tid), zap.Error(err))
+		http.Error(w, "error fetching transaction latency", http.StatusInternalServerError)
+		return
+	}
+	// the latency is recorded once the transaction is included in a proposal or applied
+	latency, err := txlatency.Get(s.localDB, tid)
+	if err != nil && !errors.Is(err, sql.ErrNotFound) {
+		ctxzap.Error(r.Context(), "unable to fetch transaction latency", zap.Stringer("tx_id", tid), zap.Error(err))
+		http.Error(w, "error fetching transaction latency", http.StatusInternalServerError)
+		return
+	}
+	resp := TransactionLatencyResponse{Received: received}
+	if !latency.Proposed.IsZero() {
+		seconds := latency.Proposed.Sub(received).Seconds()
+		resp.ProposalLatencySeconds = &seconds
+	}
+	if !latency.Applied.IsZero() {
+		seconds := latency.Applied.Sub(received).Seconds()
+		resp.ApplicationLatencySeconds = &seconds
+		resp.Layer = latency.Layer.Uint32()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write transaction latency response", zap.Error(err))
+	}
+}
+
+// Statuses of TransactionStatusResponse.
+const (
+	TransactionStatusPending  = "pending"
+	TransactionStatusApplied  = "applied"
+	TransactionStatusReverted = "reverted"
+)
+
+// TransactionStatusResponse is returned by the status endpoint of the transaction service.
+// The status is "reverted" if the transaction is pending because the layer it was applied in
+// was reverted. RevertedLayer is that layer, it is kept after the transaction is applied again.
+type TransactionStatusResponse struct {
+	Status        string `json:"status"`
+	Layer         uint32 `json:"layer,omitempty"`
+	RevertedLayer uint32 `json:"reverted_layer,omitempty"`
+}
+
+// status reports the status of the transaction in the state, including whether it was reverted.
+func (s *TransactionService) status(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	tid, ok := parseTransactionID(w, params)
+	if !ok {
+		return
+	}
+	mtx, err := transactions.Get(s.db, tid)
+	var reverted types.LayerID
+	if err == nil && s.localDB != nil {
+		var latency txlatency.Latency
+		latency, err = txlatency.Get(s.localDB, tid)
+		switch {
+		case errors.Is(err, sql.ErrNotFound):
+			// the node didn't see the transaction proposed or applied
+			err = nil
+		case err == nil:
+			reverted = latency.Reverted
+		}
+	}
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		http.Error(w, fmt.Sprintf("transaction %s not found", tid), http.StatusNotFound)
+		return
+	case err != nil:
+		ctxzap.Error(r.Context(), "unable to fetch transaction status", zap.Stringer("tx_id", tid), zap.Error(err))
+		http.Error(w, "error fetching transaction status", http.StatusInternalServerError)
+		return
+	}
+	resp := TransactionStatusResponse{Status: TransactionStatusPending, RevertedLayer: reverted.Uint32()}
+	switch {
+	case mtx.State == types.APPLIED:
+		resp.Status = TransactionStatusApplied
+		resp.Layer = mtx.LayerID.Uint32()
+	case reverted != 0:
+		resp.Status = TransactionStatusReverted
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write transaction status response", zap.Error(err))
+	}
+}
+
+// TransactionReasonResponse is returned by the reason endpoint of the transaction service.
+// Reason is one of the values of types.TXStatusReason, Layer is the layer the transaction
+// is applied in, or the layer of the proposal that includes it if the reason is "in-proposal".
+type TransactionReasonResponse struct {
+	Reason string `json:"reason"`
+	Layer  uint32 `json:"layer,omitempty"`
+}
+
+// reason explains the current state of the transaction, for example why it is not selected into proposals.
+func (s *TransactionService) reason(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	tid, ok := parseTransactionID(w, params)
+	if !ok {
+		return
+	}
+	txStatus, err := s.conState.GetTxStatus(tid)
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		http.Error(w, fmt.Sprintf("transaction %s not found", tid), http.StatusNotFound)
+		return
+	case err != nil:
+		ctxzap.Error(r.Context(), "unable to fetch transaction reason", zap.Stringer("tx_id", tid), zap.Error(err))
+		http.Error(w, "error fetching transaction reason", http.StatusInternalServerError)
+		return
+	}
+	resp := TransactionReasonResponse{Reason: txStatus.Reason.String(), Layer: txStatus.Layer.Uint32()}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		ctxzap.Warn(r.Context(), "failed to write transaction reason response", zap.Error(err))
+	}
+}
+
+// parseTransactionID parses the transaction id of a JSON API path and responds with
+// StatusBadRequest if it is malformed.
+func parseTransactionID(w http.ResponseWriter, params map[string]string) (types.TransactionID, bool) {
+	buf, err := hex.DecodeString(strings.TrimPrefix(params["id"], "0x"))
+	if err != nil || len(buf) != len(types.TransactionID{}) {
+		http.Error(w, fmt.Sprintf("failed to parse transaction id `%s`", params["id"]), http.StatusBadRequest)
+		return types.TransactionID{}, false
+	}
+	return types.TransactionID(buf), true
 }
 
 func (s *TransactionService) ParseTransaction(
@@ -110,6 +360,13 @@ func (s *TransactionService) SubmitTransaction(
 	}
 
 	if err := s.txHandler.VerifyAndCacheTx(ctx, in.Transaction); err != nil {
+		var feeErr *txs.FeeTooLowError
+		if errors.As(err, &feeErr) {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf(
+				"Transaction fee %d is below the current floor of %d for %d bytes",
+				feeErr.Fee, feeErr.Required, feeErr.Size,
+			))
+		}
 		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("Failed to verify transaction: %s", err.Error()))
 	}
 
@@ -482,3 +739,46 @@ func (m resultsMatcher) match(rst *types.TransactionWithResult) bool {
 	}
 	return true
 }
+
+func hexTxIDs(ids []types.TransactionID) []string {
+	rst := make([]string, 0, len(ids))
+	for _, id := range ids {
+		rst = append(rst, hex.EncodeToString(id.Bytes()))
+	}
+	return rst
+}
+
+// mempoolDiffs streams the mempool diffs of the proposals built by the node.
+func (s *TransactionService) mempoolDiffs(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	streamJSON(w, r, events.SubscribeMempoolDiffs(), func(ev events.EventMempoolDiff) any {
+		return MempoolDiffResponse{
+			Layer:      ev.Layer.Uint32(),
+			Candidates: ev.Candidates,
+			Included:   hexTxIDs(ev.Included),
+			Missed:     hexTxIDs(ev.Missed),
+			Added:      hexTxIDs(ev.Added),
+			Removed:    hexTxIDs(ev.Removed),
+		}
+	})
+}
+
+// certifiedTxs streams the transactions of the certified blocks.
+func (s *TransactionService) certifiedTxs(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	streamJSON(w, r, events.SubscribeTxsCertified(), func(ev events.EventTxsCertified) any {
+		return CertifiedTxsResponse{
+			Layer: ev.Layer.Uint32(),
+			Block: hex.EncodeToString(ev.Block.Bytes()),
+			Txs:   hexTxIDs(ev.Txs),
+		}
+	})
+}
+
+// revertedTxs streams the transactions that returned to pending after a revert.
+func (s *TransactionService) revertedTxs(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	streamJSON(w, r, events.SubscribeTxsReverted(), func(ev events.EventTxsReverted) any {
+		return RevertedTxsResponse{
+			RevertTo: ev.RevertTo.Uint32(),
+			Txs:      hexTxIDs(ev.Txs),
+		}
+	})
+}
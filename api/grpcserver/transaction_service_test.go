@@ -2,10 +2,15 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"math/rand"
+	"net/http"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +18,7 @@ import (
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -23,6 +29,8 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
 	"github.com/spacemeshos/go-spacemesh/signing"
 	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/txlatency"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 	"github.com/spacemeshos/go-spacemesh/sql/transactions"
 	"github.com/spacemeshos/go-spacemesh/txs"
@@ -298,3 +306,251 @@ func TestParseTransactions(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionService_Latency(t *testing.T) {
+	db := statesql.InMemory()
+	tx := fixture.NewTransactionResultGenerator().Next()
+	received := time.Unix(0, time.Now().UnixNano())
+	require.NoError(t, transactions.Add(db, &tx.Transaction, received))
+	localDB := localsql.InMemoryTest(t)
+	_, err := txlatency.SetProposed(localDB, tx.ID, received.Add(2*time.Second))
+	require.NoError(t, err)
+
+	svc := NewTransactionService(db, nil, nil, nil, nil, nil, WithTransactionLatency(localDB))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, id string) (*TransactionLatencyResponse, int) {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener,
+			strings.Replace(TransactionLatencyPath, "{id}", id, 1)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, resp.StatusCode
+		}
+		var got TransactionLatencyResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		return &got, resp.StatusCode
+	}
+
+	t.Run("proposed", func(t *testing.T) {
+		got, status := get(t, tx.ID.String())
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, received.Equal(got.Received))
+		require.NotNil(t, got.ProposalLatencySeconds)
+		require.InDelta(t, 2, *got.ProposalLatencySeconds, 0.001)
+		require.Nil(t, got.ApplicationLatencySeconds)
+	})
+	t.Run("applied", func(t *testing.T) {
+		require.NoError(t, txlatency.SetApplied(localDB, tx.ID, tx.Layer, received.Add(time.Minute)))
+		got, status := get(t, tx.ID.String())
+		require.Equal(t, http.StatusOK, status)
+		require.NotNil(t, got.ApplicationLatencySeconds)
+		require.InDelta(t, 60, *got.ApplicationLatencySeconds, 0.001)
+		require.Equal(t, tx.Layer.Uint32(), got.Layer)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, status := get(t, types.RandomTransactionID().String())
+		require.Equal(t, http.StatusNotFound, status)
+	})
+	t.Run("bad id", func(t *testing.T) {
+		_, status := get(t, "bad")
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestTransactionService_Status(t *testing.T) {
+	db := statesql.InMemory()
+	tx := fixture.NewTransactionResultGenerator().Next()
+	require.NoError(t, transactions.Add(db, &tx.Transaction, time.Now()))
+	localDB := localsql.InMemoryTest(t)
+
+	svc := NewTransactionService(db, nil, nil, nil, nil, nil, WithTransactionLatency(localDB))
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, id string) (*TransactionStatusResponse, int) {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener,
+			strings.Replace(TransactionStatusPath, "{id}", id, 1)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, resp.StatusCode
+		}
+		var got TransactionStatusResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		return &got, resp.StatusCode
+	}
+	apply := func(t *testing.T) {
+		require.NoError(t, db.WithTx(context.Background(), func(dtx sql.Transaction) error {
+			return transactions.AddResult(dtx, tx.ID, &tx.TransactionResult)
+		}))
+		require.NoError(t, txlatency.SetApplied(localDB, tx.ID, tx.Layer, time.Now()))
+	}
+
+	got, status := get(t, tx.ID.String())
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, &TransactionStatusResponse{Status: TransactionStatusPending}, got)
+
+	apply(t)
+	got, status = get(t, tx.ID.String())
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, &TransactionStatusResponse{Status: TransactionStatusApplied, Layer: tx.Layer.Uint32()}, got)
+
+	require.NoError(t, db.WithTx(context.Background(), func(dtx sql.Transaction) error {
+		return transactions.UndoLayers(dtx, tx.Layer)
+	}))
+	require.NoError(t, txlatency.UndoLayers(localDB, tx.Layer))
+	got, status = get(t, tx.ID.String())
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, &TransactionStatusResponse{
+		Status:        TransactionStatusReverted,
+		RevertedLayer: tx.Layer.Uint32(),
+	}, got)
+
+	// applied again after the revert
+	apply(t)
+	got, status = get(t, tx.ID.String())
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, &TransactionStatusResponse{
+		Status:        TransactionStatusApplied,
+		Layer:         tx.Layer.Uint32(),
+		RevertedLayer: tx.Layer.Uint32(),
+	}, got)
+
+	_, status = get(t, types.RandomTransactionID().String())
+	require.Equal(t, http.StatusNotFound, status)
+	_, status = get(t, "bad")
+	require.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestTransactionService_Reason(t *testing.T) {
+	conState := NewMockconservativeState(gomock.NewController(t))
+	svc := NewTransactionService(statesql.InMemoryTest(t), nil, nil, conState, nil, nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	get := func(t *testing.T, id types.TransactionID) (*TransactionReasonResponse, int) {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener,
+			strings.Replace(TransactionReasonPath, "{id}", id.String(), 1)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, resp.StatusCode
+		}
+		var got TransactionReasonResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		return &got, resp.StatusCode
+	}
+
+	proposed := types.RandomTransactionID()
+	conState.EXPECT().GetTxStatus(proposed).Return(&types.TXStatus{
+		State:  types.MEMPOOL,
+		Reason: types.TXReasonInProposal,
+		Layer:  types.LayerID(11),
+	}, nil)
+	got, status := get(t, proposed)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, &TransactionReasonResponse{Reason: "in-proposal", Layer: 11}, got)
+
+	gapped := types.RandomTransactionID()
+	conState.EXPECT().GetTxStatus(gapped).Return(&types.TXStatus{
+		State:  types.MEMPOOL,
+		Reason: types.TXReasonNonceGapped,
+	}, nil)
+	got, status = get(t, gapped)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, &TransactionReasonResponse{Reason: "nonce-gapped"}, got)
+
+	unknown := types.RandomTransactionID()
+	conState.EXPECT().GetTxStatus(unknown).Return(nil, sql.ErrNotFound)
+	_, status = get(t, unknown)
+	require.Equal(t, http.StatusNotFound, status)
+}
+
+func TestTransactionService_MempoolDiffs(t *testing.T) {
+	events.CloseEventReporter()
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+
+	svc := NewTransactionService(statesql.InMemoryTest(t), nil, nil, nil, nil, nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, MempoolDiffsPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	included, missed := types.RandomTransactionID(), types.RandomTransactionID()
+	events.ReportMempoolDiff(events.EventMempoolDiff{
+		Layer:      7,
+		Candidates: 2,
+		Included:   []types.TransactionID{included},
+		Missed:     []types.TransactionID{missed},
+		Added:      []types.TransactionID{included, missed},
+	})
+	var got MempoolDiffResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, MempoolDiffResponse{
+		Layer:      7,
+		Candidates: 2,
+		Included:   []string{hex.EncodeToString(included.Bytes())},
+		Missed:     []string{hex.EncodeToString(missed.Bytes())},
+		Added:      []string{hex.EncodeToString(included.Bytes()), hex.EncodeToString(missed.Bytes())},
+		Removed:    []string{},
+	}, got)
+}
+
+func TestTransactionService_CertifiedTxs(t *testing.T) {
+	events.CloseEventReporter()
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+
+	svc := NewTransactionService(statesql.InMemoryTest(t), nil, nil, nil, nil, nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, CertifiedTxsPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	block, tx := types.RandomBlockID(), types.RandomTransactionID()
+	events.ReportTxsCertified(events.EventTxsCertified{
+		Layer: 9,
+		Block: block,
+		Txs:   []types.TransactionID{tx},
+	})
+	var got CertifiedTxsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, CertifiedTxsResponse{
+		Layer: 9,
+		Block: hex.EncodeToString(block.Bytes()),
+		Txs:   []string{hex.EncodeToString(tx.Bytes())},
+	}, got)
+}
+
+func TestTransactionService_RevertedTxs(t *testing.T) {
+	events.CloseEventReporter()
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+
+	svc := NewTransactionService(statesql.InMemoryTest(t), nil, nil, nil, nil, nil)
+	cfg, cleanup := launchDebugJSONServer(t, svc)
+	t.Cleanup(cleanup)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", cfg.DebugJSONListener, RevertedTxsPath))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	tx := types.RandomTransactionID()
+	events.ReportTxsReverted(events.EventTxsReverted{RevertTo: 9, Txs: []types.TransactionID{tx}})
+	var got RevertedTxsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	require.Equal(t, RevertedTxsResponse{
+		RevertTo: 9,
+		Txs:      []string{hex.EncodeToString(tx.Bytes())},
+	}, got)
+}
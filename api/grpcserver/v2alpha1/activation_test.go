@@ -16,6 +16,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/common/fixture"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 )
@@ -193,7 +194,7 @@ func TestActivationStreamService_Stream(t *testing.T) {
 
 				var expect []*types.ActivationTx
 				for _, rst := range streamed {
-					require.NoError(t, events.ReportNewActivation(rst.ActivationTx))
+					require.NoError(t, events.ReportNewActivation(rst.ActivationTx, p2p.NoPeer))
 					matcher := atxsMatcher{tc.request, ctx}
 					if matcher.match(rst) {
 						expect = append(expect, rst.ActivationTx)
@@ -8,3 +8,10 @@ var atxsCounter = metrics.NewCounter(
 	"number of atxs",
 	[]string{"epoch"},
 )
+
+var discrepancies = metrics.NewCounter(
+	"discrepancies",
+	"consensus_cache",
+	"number of discrepancies between the cache and the database found by verification",
+	[]string{"kind"},
+)
@@ -0,0 +1,135 @@
+package atxsdata
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/identities"
+)
+
+const (
+	// DiscrepancyCount is reported when the number of atxs in the epoch differs.
+	DiscrepancyCount = "count"
+	// DiscrepancyMissing is reported when an atx from the database is not in the cache.
+	DiscrepancyMissing = "missing"
+	// DiscrepancyMismatch is reported when atx fields in the cache differ from the database.
+	DiscrepancyMismatch = "mismatch"
+	// DiscrepancyMalicious is reported when the malicious flag of an identity differs.
+	DiscrepancyMalicious = "malicious"
+)
+
+// Discrepancy between the cache and the atxs table.
+type Discrepancy struct {
+	Kind string
+	// Epoch is the target epoch of atxs in the cache.
+	Epoch types.EpochID
+	// ATX is empty for count discrepancies.
+	ATX types.ATXID
+}
+
+func (d Discrepancy) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("kind", d.Kind)
+	encoder.AddUint32("epoch", d.Epoch.Uint32())
+	encoder.AddString("atx", d.ATX.ShortString())
+	return nil
+}
+
+type sampledAtx struct {
+	id    types.ATXID
+	epoch types.EpochID
+	atx   ATX
+}
+
+// Verify compares the cache with the atxs table for every epoch that is not evicted.
+// The number of atxs is compared for each epoch and up to samples randomly selected atxs
+// from the database are checked field by field together with the malicious flag of their identities.
+//
+// Atxs that are written concurrently with the verification may be reported as discrepancies,
+// so it should be executed when atxs are not added, e.g. on startup.
+func Verify(db sql.Executor, cache *Data, samples int, logger *zap.Logger) ([]Discrepancy, error) {
+	latest, err := atxs.LatestEpoch(db)
+	if err != nil {
+		return nil, err
+	}
+	samples = max(samples, 0)
+	var rst []Discrepancy
+	report := func(d Discrepancy) {
+		discrepancies.WithLabelValues(d.Kind).Inc()
+		logger.Warn("atxs cache diverged from database", zap.Inline(d))
+		rst = append(rst, d)
+	}
+	for publish := cache.Evicted(); publish <= latest; publish++ {
+		var (
+			count   int
+			sampled = make([]sampledAtx, 0, samples)
+		)
+		err := atxs.IterateAtxsData(db, publish, publish,
+			func(
+				id types.ATXID,
+				node types.NodeID,
+				epoch types.EpochID,
+				coinbase types.Address,
+				weight, base, height uint64,
+				nonce types.VRFPostIndex,
+			) bool {
+				count++
+				// reservoir sampling to select uniformly without knowing the number of rows in advance
+				i := count - 1
+				if i >= samples {
+					i = rand.IntN(count)
+				}
+				if i < samples {
+					atx := sampledAtx{id: id, epoch: epoch + 1, atx: ATX{
+						Node:       node,
+						Coinbase:   coinbase,
+						Weight:     weight,
+						BaseHeight: base,
+						Height:     height,
+						Nonce:      nonce,
+					}}
+					if len(sampled) < samples {
+						sampled = append(sampled, atx)
+					} else {
+						sampled[i] = atx
+					}
+				}
+				return true
+			})
+		if err != nil {
+			return nil, err
+		}
+		target := publish + 1
+		if size := cache.Size(target); size != count {
+			logger.Debug("atxs count mismatch",
+				zap.Uint32("epoch", target.Uint32()),
+				zap.Int("cache", size),
+				zap.Int("db", count),
+			)
+			report(Discrepancy{Kind: DiscrepancyCount, Epoch: target})
+		}
+		for _, s := range sampled {
+			cached := cache.Get(s.epoch, s.id)
+			switch {
+			case cached == nil:
+				report(Discrepancy{Kind: DiscrepancyMissing, Epoch: s.epoch, ATX: s.id})
+				continue
+			case *cached != s.atx:
+				report(Discrepancy{Kind: DiscrepancyMismatch, Epoch: s.epoch, ATX: s.id})
+			}
+			malicious, err := identities.IsMalicious(db, s.atx.Node)
+			if err != nil {
+				return nil, fmt.Errorf("is malicious %s: %w", s.atx.Node.ShortString(), err)
+			}
+			if malicious != cache.IsMalicious(s.atx.Node) {
+				report(Discrepancy{Kind: DiscrepancyMalicious, Epoch: s.epoch, ATX: s.id})
+			}
+		}
+	}
+	return rst, nil
+}
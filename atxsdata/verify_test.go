@@ -0,0 +1,91 @@
+package atxsdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestVerify(t *testing.T) {
+	types.SetLayersPerEpoch(3)
+	setup := func(t *testing.T) (sql.StateDatabase, *Data, []types.ActivationTx) {
+		db := statesql.InMemoryTest(t)
+		nonce := types.VRFPostIndex(1)
+		data := []types.ActivationTx{
+			gatx(types.ATXID{1, 1}, 1, types.NodeID{1}, nonce),
+			gatx(types.ATXID{1, 2}, 1, types.NodeID{2}, nonce),
+			gatx(types.ATXID{2, 1}, 2, types.NodeID{1}, nonce),
+			gatx(types.ATXID{2, 2}, 2, types.NodeID{2}, nonce),
+		}
+		for i := range data {
+			require.NoError(t, atxs.Add(db, &data[i], types.AtxBlob{}))
+		}
+		require.NoError(t, layers.SetApplied(db, types.LayerID(6), types.BlockID{1}))
+		c, err := Warm(db, 1, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		return db, c, data
+	}
+	t.Run("consistent", func(t *testing.T) {
+		db, c, _ := setup(t)
+		rst, err := Verify(db, c, 10, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		require.Empty(t, rst)
+	})
+	t.Run("missing", func(t *testing.T) {
+		db, c, data := setup(t)
+		extra := gatx(types.ATXID{2, 3}, 2, types.NodeID{3}, 1)
+		require.NoError(t, atxs.Add(db, &extra, types.AtxBlob{}))
+
+		rst, err := Verify(db, c, 10, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []Discrepancy{
+			{Kind: DiscrepancyCount, Epoch: data[2].TargetEpoch()},
+			{Kind: DiscrepancyMissing, Epoch: extra.TargetEpoch(), ATX: extra.ID()},
+		}, rst)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		db, _, data := setup(t)
+		c := New()
+		for i := range data {
+			atx := data[i]
+			atx.Weight = uint64(atx.NumUnits) * atx.TickCount
+			if i == 0 {
+				atx.Coinbase = types.Address{1}
+			}
+			c.AddFromAtx(&atx, false)
+		}
+		rst, err := Verify(db, c, 10, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		require.Equal(t, []Discrepancy{
+			{Kind: DiscrepancyMismatch, Epoch: data[0].TargetEpoch(), ATX: data[0].ID()},
+		}, rst)
+	})
+	t.Run("malicious", func(t *testing.T) {
+		db, c, _ := setup(t)
+		require.NoError(t, identities.SetMalicious(db, types.NodeID{2}, []byte("proof"), time.Now()))
+
+		rst, err := Verify(db, c, 10, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		require.Len(t, rst, 2)
+		for _, d := range rst {
+			require.Equal(t, DiscrepancyMalicious, d.Kind)
+		}
+	})
+	t.Run("sampled", func(t *testing.T) {
+		db, c, _ := setup(t)
+		require.NoError(t, identities.SetMalicious(db, types.NodeID{2}, []byte("proof"), time.Now()))
+
+		rst, err := Verify(db, c, 0, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		require.Empty(t, rst)
+	})
+}
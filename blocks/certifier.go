@@ -13,12 +13,14 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/p2p/pubsub"
 	"github.com/spacemeshos/go-spacemesh/signing"
 	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/blocks"
 	"github.com/spacemeshos/go-spacemesh/sql/certificates"
 	"github.com/spacemeshos/go-spacemesh/system"
 )
@@ -544,9 +546,35 @@ func (c *Certifier) checkAndSave(
 		return errMultipleCerts
 	}
 	c.addCertCount(lid.GetEpoch())
+	c.reportCertified(logger, lid, cert.BlockID)
 	return nil
 }
 
+// reportCertified reports the transactions of the certified block, before the layer is applied.
+// Certificates from sync may precede the block, transactions of such blocks are not reported.
+func (c *Certifier) reportCertified(logger *zap.Logger, lid types.LayerID, bid types.BlockID) {
+	if bid == types.EmptyBlockID {
+		return
+	}
+	block, err := blocks.Get(c.db, bid)
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		logger.Debug("certified block not available, not reporting its transactions")
+		return
+	case err != nil:
+		logger.Warn("failed to load certified block", zap.Error(err))
+		return
+	}
+	if len(block.TxIDs) == 0 {
+		return
+	}
+	events.ReportTxsCertified(events.EventTxsCertified{
+		Layer: lid,
+		Block: bid,
+		Txs:   block.TxIDs,
+	})
+}
+
 func (c *Certifier) addCertCount(epoch types.EpochID) {
 	c.certCount[epoch]++
 	delete(c.certCount, epoch-2)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -13,6 +14,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/blocks/mocks"
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
 	"github.com/spacemeshos/go-spacemesh/p2p/pubsub"
 	pubsubmock "github.com/spacemeshos/go-spacemesh/p2p/pubsub/mocks"
@@ -184,6 +186,47 @@ func Test_HandleSyncedCertificate(t *testing.T) {
 	require.Equal(t, map[types.EpochID]int{b.LayerIndex.GetEpoch(): 1}, tc.CertCount())
 }
 
+func Test_HandleSyncedCertificate_ReportsTxs(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub := events.SubscribeTxsCertified()
+
+	tc := newTestCertifier(t, 1)
+	numMsgs := tc.cfg.CertifyThreshold / int(defaultCnt)
+	b := types.NewExistingBlock(
+		types.RandomBlockID(),
+		types.InnerBlock{
+			LayerIndex: types.LayerID(11),
+			TxIDs:      []types.TransactionID{types.RandomTransactionID(), types.RandomTransactionID()},
+		},
+	)
+	require.NoError(t, blocks.Add(tc.db, b))
+	sigs := make([]types.CertifyMessage, numMsgs)
+	for i := 0; i < numMsgs; i++ {
+		nid, msg, _ := genEncodedMsg(t, b.LayerIndex, b.ID())
+		tc.mOracle.EXPECT().Validate(
+			gomock.Any(), b.LayerIndex, eligibility.CertifyRound, tc.cfg.CommitteeSize, nid, msg.Proof, defaultCnt).
+			Return(true, nil)
+		sigs[i] = *msg
+	}
+	cert := &types.Certificate{
+		BlockID:    b.ID(),
+		Signatures: sigs,
+	}
+	require.NoError(t, tc.HandleSyncedCertificate(context.Background(), b.LayerIndex, cert))
+
+	select {
+	case ev := <-sub.Out():
+		require.Equal(t, events.EventTxsCertified{
+			Layer: b.LayerIndex,
+			Block: b.ID(),
+			Txs:   b.TxIDs,
+		}, ev)
+	case <-time.After(time.Second):
+		require.Fail(t, "certified txs are not reported")
+	}
+}
+
 func Test_HandleSyncedCertificate_HareOutputTrumped(t *testing.T) {
 	tc := newTestCertifier(t, 1)
 	numMsgs := tc.cfg.CertifyThreshold / int(defaultCnt)
@@ -13,9 +13,11 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/atxsdata"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
 	"github.com/spacemeshos/go-spacemesh/hare4"
 	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/p2p/server"
 	"github.com/spacemeshos/go-spacemesh/proposals/store"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/layers"
@@ -48,13 +50,17 @@ type Config struct {
 	GenBlockInterval   time.Duration
 	BlockGasLimit      uint64
 	OptFilterThreshold int
+	// ProposalFetchTimeout bounds the time spent fetching proposals of the hare output that are
+	// not available locally. If zero, fetching is bounded only by the fetcher.
+	ProposalFetchTimeout time.Duration
 }
 
 func defaultConfig() Config {
 	return Config{
-		GenBlockInterval:   time.Second,
-		BlockGasLimit:      math.MaxUint64,
-		OptFilterThreshold: 90,
+		GenBlockInterval:     time.Second,
+		BlockGasLimit:        math.MaxUint64,
+		OptFilterThreshold:   90,
+		ProposalFetchTimeout: time.Minute,
 	}
 }
 
@@ -153,7 +159,13 @@ func (g *Generator) run(ctx context.Context) error {
 			maxLayer = max(maxLayer, out.Layer)
 			_, err := g.processHareOutput(ctx, out)
 			if err != nil {
-				if errors.Is(err, errNodeHasBadMeshHash) {
+				if errors.Is(err, errProposalsUnavailable) {
+					g.logger.Warn("proposals from hare output are unavailable, layer is left to tortoise",
+						log.ZContext(ctx),
+						zap.Uint32("layer_id", out.Layer.Uint32()),
+						zap.Error(err),
+					)
+				} else if errors.Is(err, errNodeHasBadMeshHash) {
 					g.logger.Debug("node has different mesh hash from majority, will download block instead",
 						log.ZContext(ctx),
 						zap.Uint32("layer_id", out.Layer.Uint32()),
@@ -182,10 +194,8 @@ func (g *Generator) processHareOutput(ctx context.Context, out hare4.ConsensusOu
 	var md *proposalMetadata
 	if len(out.Proposals) > 0 {
 		getMetadata := func() error {
-			// fetch proposals from peers if not locally available
-			if err := g.fetcher.GetProposals(ctx, out.Proposals); err != nil {
-				failFetchCnt.Inc()
-				return fmt.Errorf("preprocess fetch layer %d proposals: %w", out.Layer, err)
+			if err := g.fetchProposals(ctx, out.Layer, out.Proposals); err != nil {
+				return err
 			}
 			// now all proposals should be in the local store
 			props := g.proposals.GetMany(out.Layer, out.Proposals...)
@@ -226,6 +236,7 @@ func (g *Generator) processHareOutput(ctx context.Context, out hare4.ConsensusOu
 		g.logger.Debug("generated block",
 			zap.Uint32("layer_id", out.Layer.Uint32()),
 			zap.Stringer("block_id", block.ID()),
+			zap.Stringer("proposals_hash", out.Hash),
 		)
 	}
 	if err := g.saveAndCertify(ctx, out.Layer, block); err != nil {
@@ -237,6 +248,42 @@ func (g *Generator) processHareOutput(ctx context.Context, out hare4.ConsensusOu
 	return block, nil
 }
 
+// fetchProposals fetches proposals from peers if they are not locally available.
+//
+// If some proposals are still unavailable after ProposalFetchTimeout the block for the layer
+// can't be generated. In this case EventUnavailableProposals is reported and errProposalsUnavailable
+// is returned, so that the layer is completed by tortoise instead of waiting for hare output.
+func (g *Generator) fetchProposals(ctx context.Context, lid types.LayerID, pids []types.ProposalID) error {
+	// proposals are fetched for the layer that hare just terminated, they are critical for consensus
+	fetchCtx := server.WithPriority(ctx)
+	if g.cfg.ProposalFetchTimeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(fetchCtx, g.cfg.ProposalFetchTimeout)
+		defer cancel()
+	}
+	err := g.fetcher.GetProposals(fetchCtx, pids)
+	if err == nil {
+		return nil
+	}
+	var missing []types.ProposalID
+	for _, pid := range pids {
+		if !g.proposals.Has(pid) {
+			missing = append(missing, pid)
+		}
+	}
+	if len(missing) == 0 {
+		// all proposals were fetched before the error, for example from different peers
+		return nil
+	}
+	failFetchCnt.Inc()
+	if ctx.Err() != nil {
+		return fmt.Errorf("preprocess fetch layer %d proposals: %w", lid, err)
+	}
+	unavailableCnt.Inc()
+	events.ReportUnavailableProposals(lid, missing)
+	return fmt.Errorf("%w: layer %d missing %d/%d: %w", errProposalsUnavailable, lid, len(missing), len(pids), err)
+}
+
 func (g *Generator) processOptimisticLayers(max types.LayerID) {
 	lastApplied, err := layers.GetLastApplied(g.db)
 	if err != nil {
@@ -19,9 +19,11 @@ import (
 	"github.com/spacemeshos/go-spacemesh/atxsdata"
 	"github.com/spacemeshos/go-spacemesh/blocks/mocks"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
 	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
 	"github.com/spacemeshos/go-spacemesh/hare4"
+	"github.com/spacemeshos/go-spacemesh/p2p/server"
 	"github.com/spacemeshos/go-spacemesh/proposals/store"
 	"github.com/spacemeshos/go-spacemesh/signing"
 	"github.com/spacemeshos/go-spacemesh/sql"
@@ -471,6 +473,10 @@ func Test_processHareOutput_EmptyOutput(t *testing.T) {
 }
 
 func Test_run_FetchFailed(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub := events.SubscribeUnavailableProposals()
+
 	tg := createTestGenerator(t)
 	layerID := types.GetEffectiveGenesis().Add(100)
 	require.NoError(t, layers.SetApplied(tg.db, layerID-1, types.EmptyBlockID))
@@ -482,10 +488,64 @@ func Test_run_FetchFailed(t *testing.T) {
 		})
 	tg.mockPatrol.EXPECT().CompleteHare(layerID)
 	tg.hareCh <- hare4.ConsensusOutput{Layer: layerID, Proposals: pids}
+	select {
+	case ev := <-sub.Out():
+		require.Equal(t, events.EventUnavailableProposals{Layer: layerID, Proposals: pids}, ev)
+	case <-time.After(time.Second):
+		require.Fail(t, "timed out waiting for unavailable proposals event")
+	}
 	require.Eventually(t, func() bool { return len(tg.hareCh) == 0 }, time.Second, 100*time.Millisecond)
 	tg.Stop()
 }
 
+func Test_fetchProposals(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		tg := createTestGenerator(t)
+		tg.cfg.ProposalFetchTimeout = 10 * time.Millisecond
+		pids := []types.ProposalID{{1}, {2}}
+		tg.mockFetch.EXPECT().GetProposals(gomock.Any(), pids).DoAndReturn(
+			func(ctx context.Context, _ []types.ProposalID) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		err := tg.fetchProposals(context.Background(), types.LayerID(10), pids)
+		require.ErrorIs(t, err, errProposalsUnavailable)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("priority with timeout", func(t *testing.T) {
+		tg := createTestGenerator(t)
+		tg.cfg.ProposalFetchTimeout = time.Minute
+		pids := []types.ProposalID{{1}}
+		tg.mockFetch.EXPECT().GetProposals(gomock.Any(), pids).DoAndReturn(
+			func(ctx context.Context, _ []types.ProposalID) error {
+				require.True(t, server.IsPriority(ctx))
+				_, ok := ctx.Deadline()
+				require.True(t, ok)
+				return nil
+			})
+		require.NoError(t, tg.fetchProposals(context.Background(), types.LayerID(10), pids))
+	})
+	t.Run("available despite error", func(t *testing.T) {
+		tg := createTestGenerator(t)
+		layerID := types.GetEffectiveGenesis().Add(100)
+		signers, atxes := createATXs(t, tg.atxs, (layerID.GetEpoch() - 1).FirstLayer(), 2)
+		plist := createProposals(t, tg.db, tg.proposals, layerID, types.Hash32{}, signers, types.ToATXIDs(atxes), nil)
+		pids := types.ToProposalIDs(plist)
+		tg.mockFetch.EXPECT().GetProposals(gomock.Any(), pids).Return(errors.New("unknown"))
+		require.NoError(t, tg.fetchProposals(context.Background(), layerID, pids))
+	})
+	t.Run("canceled", func(t *testing.T) {
+		tg := createTestGenerator(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pids := []types.ProposalID{{1}}
+		tg.mockFetch.EXPECT().GetProposals(gomock.Any(), pids).Return(context.Canceled)
+		err := tg.fetchProposals(ctx, types.LayerID(10), pids)
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, errProposalsUnavailable)
+	})
+}
+
 func Test_run_DiffHasFromConsensus(t *testing.T) {
 	tg := createTestGenerator(t)
 	layerID := types.GetEffectiveGenesis().Add(100)
@@ -678,7 +738,11 @@ func Test_processHareOutput_UnequalHeight(t *testing.T) {
 		Layer:     layerID,
 		Proposals: types.ToProposalIDs(pList),
 	}
-	tg.mockFetch.EXPECT().GetProposals(ctx, ho.Proposals)
+	tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho.Proposals).DoAndReturn(
+		func(ctx context.Context, _ []types.ProposalID) error {
+			require.True(t, server.IsPriority(ctx))
+			return nil
+		})
 	var block *types.Block
 	tg.mockMesh.EXPECT().AddBlockWithTXs(ctx, gomock.Any()).DoAndReturn(
 		func(_ context.Context, b *types.Block) error {
@@ -764,7 +828,7 @@ func Test_processHareOutput_bad_state(t *testing.T) {
 			Layer:     layerID,
 			Proposals: types.ToProposalIDs([]*types.Proposal{p}),
 		}
-		tg.mockFetch.EXPECT().GetProposals(ctx, ho.Proposals)
+		tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho.Proposals)
 		tg.mockPatrol.EXPECT().CompleteHare(layerID)
 		got, err := tg.processHareOutput(ctx, ho)
 		require.ErrorIs(t, err, errProposalTxHdrMissing)
@@ -788,7 +852,7 @@ func Test_processHareOutput_EmptyProposals(t *testing.T) {
 		Layer:     lid,
 		Proposals: types.ToProposalIDs(plist),
 	}
-	tg.mockFetch.EXPECT().GetProposals(ctx, ho.Proposals)
+	tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho.Proposals)
 	var block *types.Block
 	tg.mockMesh.EXPECT().AddBlockWithTXs(ctx, gomock.Any()).DoAndReturn(
 		func(_ context.Context, b *types.Block) error {
@@ -837,7 +901,7 @@ func Test_processHareOutput_StableBlockID(t *testing.T) {
 		Layer:     layerID,
 		Proposals: types.ToProposalIDs(plist),
 	}
-	tg.mockFetch.EXPECT().GetProposals(ctx, ho1.Proposals)
+	tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho1.Proposals)
 	tg.mockMesh.EXPECT().AddBlockWithTXs(ctx, gomock.Any())
 	tg.mockCert.EXPECT().RegisterForCert(ctx, layerID, gomock.Any())
 	tg.mockCert.EXPECT().CertifyIfEligible(ctx, layerID, gomock.Any()).Return(eligibility.ErrNotActive)
@@ -857,7 +921,7 @@ func Test_processHareOutput_StableBlockID(t *testing.T) {
 		Layer:     layerID,
 		Proposals: types.ToProposalIDs(ordered),
 	}
-	tg.mockFetch.EXPECT().GetProposals(ctx, ho2.Proposals)
+	tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho2.Proposals)
 	tg.mockMesh.EXPECT().AddBlockWithTXs(ctx, gomock.Any())
 	tg.mockCert.EXPECT().RegisterForCert(ctx, layerID, gomock.Any())
 	tg.mockCert.EXPECT().CertifyIfEligible(ctx, layerID, gomock.Any()).Return(eligibility.ErrNotActive)
@@ -886,7 +950,7 @@ func Test_processHareOutput_SameATX(t *testing.T) {
 		Layer:     layerID,
 		Proposals: types.ToProposalIDs(plist),
 	}
-	tg.mockFetch.EXPECT().GetProposals(context.Background(), ho.Proposals)
+	tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho.Proposals)
 	tg.mockPatrol.EXPECT().CompleteHare(layerID)
 	got, err := tg.processHareOutput(context.Background(), ho)
 	require.ErrorIs(t, err, errDuplicateATX)
@@ -910,7 +974,7 @@ func Test_processHareOutput_EmptyATXID(t *testing.T) {
 		Layer:     layerID,
 		Proposals: types.ToProposalIDs(plist),
 	}
-	tg.mockFetch.EXPECT().GetProposals(context.Background(), ho.Proposals)
+	tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho.Proposals)
 	tg.mockPatrol.EXPECT().CompleteHare(layerID)
 	got, err := tg.processHareOutput(context.Background(), ho)
 	require.Error(t, err)
@@ -933,7 +997,7 @@ func Test_processHareOutput_MultipleEligibilities(t *testing.T) {
 		Layer:     layerID,
 		Proposals: types.ToProposalIDs(plist),
 	}
-	tg.mockFetch.EXPECT().GetProposals(ctx, ho.Proposals)
+	tg.mockFetch.EXPECT().GetProposals(gomock.Any(), ho.Proposals)
 	tg.mockMesh.EXPECT().AddBlockWithTXs(ctx, gomock.Any())
 	tg.mockCert.EXPECT().RegisterForCert(ctx, layerID, gomock.Any())
 	tg.mockCert.EXPECT().CertifyIfEligible(ctx, layerID, gomock.Any()).Return(eligibility.ErrNotActive)
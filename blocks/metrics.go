@@ -11,6 +11,7 @@ const (
 
 	// labels for block generation.
 	failFetch   = "fail_proposal"
+	unavailable = "unavailable_proposal"
 	failGen     = "fail_block"
 	internalErr = "fail_error"
 	genBlock    = "block"
@@ -28,6 +29,7 @@ var (
 	blockOkCnt     = blockGenCount.WithLabelValues(genBlock)
 	emptyOutputCnt = blockGenCount.WithLabelValues(empty)
 	failFetchCnt   = blockGenCount.WithLabelValues(failFetch)
+	unavailableCnt = blockGenCount.WithLabelValues(unavailable)
 	failGenCnt     = blockGenCount.WithLabelValues(failGen)
 	failErrCnt     = blockGenCount.WithLabelValues(internalErr)
 )
@@ -30,6 +30,7 @@ var (
 	errProposalTxMissing    = errors.New("proposal tx not found")
 	errProposalTxHdrMissing = errors.New("proposal tx missing header")
 	errDuplicateATX         = errors.New("multiple proposals with same ATX")
+	errProposalsUnavailable = errors.New("proposals unavailable")
 )
 
 type meshState struct {
@@ -47,6 +47,11 @@ func AddFlags(flagSet *pflag.FlagSet, cfg *config.Config) (configPath *string) {
 		"poet-servers",
 		"JSON-encoded list of poet servers (address and pubkey)",
 	)
+	flagSet.Var(
+		&flags.JSONFlag{Value: &cfg.FallbackPoetServers},
+		"fallback-poet-servers",
+		"JSON-encoded list of poet servers with a longer cycle gap, used if PoST doesn't fit into the cycle gap",
+	)
 	flagSet.StringVar(&cfg.Genesis.GenesisTime, "genesis-time",
 		cfg.Genesis.GenesisTime, "Time of the genesis layer in 2019-13-02T17:02:00+00:00 format")
 	flagSet.StringVar(&cfg.Genesis.ExtraData, "genesis-extra-data",
@@ -61,6 +66,8 @@ func AddFlags(flagSet *pflag.FlagSet, cfg *config.Config) (configPath *string) {
 	flagSet.BoolVar(&cfg.PprofBlockProfile, "pprof-block-profile", false, "enable pprof block profile")
 	flagSet.StringVar(&cfg.PprofHTTPServerListener, "pprof-listener", cfg.PprofHTTPServerListener,
 		"Listen address for pprof server, not safe to expose publicly")
+	flagSet.BoolVar(&cfg.FaultInjection, "fault-injection", cfg.FaultInjection,
+		"enable the admin API that injects failures into poet and post clients, for tests only")
 	flagSet.Uint64Var(&cfg.TickSize, "tick-size", cfg.TickSize, "number of poet leaves in a single tick")
 	flagSet.StringVar(&cfg.ProfilerURL, "profiler-url", cfg.ProfilerURL,
 		"send profiler data to certain url, if no url no profiling will be sent, format: http://<IP>:<PORT>")
@@ -71,15 +78,51 @@ func AddFlags(flagSet *pflag.FlagSet, cfg *config.Config) (configPath *string) {
 		cfg.TxsPerProposal, "the number of transactions to select per proposal")
 	flagSet.Uint64Var(&cfg.BlockGasLimit, "block-gas-limit",
 		cfg.BlockGasLimit, "max gas allowed per block")
+	flagSet.Uint64Var(&cfg.MinGasPrice, "min-gas-price",
+		cfg.MinGasPrice, "min gas price of transactions accepted from the network")
+	flagSet.Uint64Var(&cfg.MinFeePerByte, "min-fee-per-byte",
+		cfg.MinFeePerByte, "min fee per encoded byte of transactions admitted to the mempool")
+	flagSet.Uint64Var(&cfg.MaxFeePerByte, "max-fee-per-byte",
+		cfg.MaxFeePerByte, "upper bound of the adjusted fee floor per byte")
+	flagSet.BoolVar(&cfg.FeeFloorAdjust, "fee-floor-adjust",
+		cfg.FeeFloorAdjust, "adjust the fee floor per byte from the fullness of recent blocks")
+	flagSet.StringSliceVar(&cfg.PriorityPrincipals, "priority-principals",
+		cfg.PriorityPrincipals, "addresses whose transactions are always selected first into proposals")
+	flagSet.StringVar(&cfg.MempoolExport, "mempool-export",
+		cfg.MempoolExport, "file to write pending transactions to when the node shuts down")
+	flagSet.StringVar(&cfg.MempoolImport, "mempool-import",
+		cfg.MempoolImport, "file with pending transactions exported by another node to import on start")
+	flagSet.DurationVar(&cfg.MempoolCheckInterval, "mempool-check-interval",
+		cfg.MempoolCheckInterval, "interval of checking the mempool cache against the database (debug only)")
+	flagSet.BoolVar(&cfg.MempoolExpectRewards, "mempool-expect-rewards",
+		cfg.MempoolExpectRewards, "count rewards of the coinbase in blocks that are not applied yet in the mempool")
+	flagSet.Uint32Var(&cfg.MempoolDormantLayers, "mempool-dormant-layers",
+		cfg.MempoolDormantLayers, "layers after which accounts with only infeasible transactions leave the mempool cache")
+	flagSet.IntVar(&cfg.ATXsDataVerifySamples, "atxsdata-verify-samples",
+		cfg.ATXsDataVerifySamples, "number of atxs per epoch to verify in the consensus cache on startup")
+	flagSet.BoolVar(&cfg.ATXsDataRebuild, "atxsdata-rebuild",
+		cfg.ATXsDataRebuild, "rebuild the consensus cache if verification found discrepancies")
 	flagSet.IntVar(&cfg.OptFilterThreshold, "optimistic-filtering-threshold",
 		cfg.OptFilterThreshold, "threshold for optimistic filtering in percentage")
+	flagSet.DurationVar(&cfg.ProposalFetchTimeout, "proposal-fetch-timeout",
+		cfg.ProposalFetchTimeout, "time spent fetching proposals of the hare output before the block is skipped")
 
 	flagSet.IntVar(&cfg.DatabaseConnections, "db-connections",
 		cfg.DatabaseConnections, "configure number of active connections to enable parallel read requests")
 	flagSet.BoolVar(&cfg.DatabaseLatencyMetering, "db-latency-metering",
 		cfg.DatabaseLatencyMetering, "if enabled collect latency histogram for every database query")
+	flagSet.BoolVar(&cfg.DatabaseCountRows, "db-count-rows",
+		cfg.DatabaseCountRows, "if enabled count rows of every table when collecting database metrics")
 	flagSet.DurationVar(&cfg.DatabasePruneInterval, "db-prune-interval",
 		cfg.DatabasePruneInterval, "configure interval for database pruning")
+	flagSet.Uint32Var(&cfg.PrunePoetProofsAfter, "prune-poet-proofs-after",
+		cfg.PrunePoetProofsAfter, "epochs after which poet proofs not referenced by any atx are pruned (0 keeps all)")
+	flagSet.DurationVar(&cfg.DatabaseBackup.Interval, "db-backup-interval",
+		cfg.DatabaseBackup.Interval, "interval of verified database backups (0 disables backups)")
+	flagSet.IntVar(&cfg.DatabaseBackup.Retention, "db-backup-retention",
+		cfg.DatabaseBackup.Retention, "number of database backups to keep")
+	flagSet.StringVar(&cfg.DatabaseBackup.Dir, "db-backup-dir",
+		cfg.DatabaseBackup.Dir, "directory for database backups (defaults to the backups directory in the data dir)")
 
 	flagSet.BoolVar(&cfg.NoMainOverride, "no-main-override",
 		cfg.NoMainOverride, "force 'nomain' builds to run on the mainnet")
@@ -179,6 +222,9 @@ func AddFlags(flagSet *pflag.FlagSet, cfg *config.Config) (configPath *string) {
 		cfg.API.GrpcSendMsgSize, "GRPC api send message size")
 	flagSet.StringVar(&cfg.API.JSONListener, "grpc-json-listener",
 		cfg.API.JSONListener, "(Optional) endpoint to expose public grpc services via HTTP/JSON.")
+	flagSet.StringVar(&cfg.API.DebugJSONListener, "grpc-debug-json-listener",
+		cfg.API.DebugJSONListener, "(Optional) endpoint to expose debug endpoints of grpc services via HTTP/JSON. "+
+			"It is not authenticated, enable it only on a loopback address.")
 
 	flagSet.StringSliceVar(&cfg.API.JSONCorsAllowedOrigins, "grpc-cors-allowed-origin",
 		cfg.API.JSONCorsAllowedOrigins, "(Optional) CORS Allowed Origin, can be specified multiple times")
@@ -188,6 +234,9 @@ func AddFlags(flagSet *pflag.FlagSet, cfg *config.Config) (configPath *string) {
 	flagSet.Uint32Var(&cfg.HareEligibility.ConfidenceParam, "eligibility-confidence-param",
 		cfg.HareEligibility.ConfidenceParam,
 		"The relative layer (with respect to the current layer) we are confident to have consensus about")
+	flagSet.Uint32Var(&cfg.HareEligibility.WarmupLayers, "eligibility-warmup-layers",
+		cfg.HareEligibility.WarmupLayers,
+		"Number of layers before the next epoch active set is used when it is precomputed (0 disables)")
 
 	/**======================== Beacon Flags ========================== **/
 
@@ -322,10 +371,24 @@ func AddFlags(flagSet *pflag.FlagSet, cfg *config.Config) (configPath *string) {
 		cfg.POET.PhaseShift, "phase shift of poet server: duration after epoch start, at which poet round starts")
 	flagSet.DurationVar(&cfg.POET.CycleGap, "cycle-gap",
 		cfg.POET.CycleGap, "cycle gap of poet server: gap between poet rounds")
+	flagSet.DurationVar(&cfg.POET.FallbackCycleGap, "fallback-cycle-gap",
+		cfg.POET.FallbackCycleGap, "cycle gap of fallback poet servers")
 	flagSet.DurationVar(&cfg.POET.GracePeriod, "grace-period",
 		cfg.POET.GracePeriod, "time before poet round starts, when the node builds and submits a challenge")
+	flagSet.DurationVar(&cfg.POET.LatePublishWindow, "late-publish-window",
+		cfg.POET.LatePublishWindow, "time after the end of the publish epoch, during which ATX is still built and published")
 	flagSet.DurationVar(&cfg.POET.RequestTimeout, "poet-request-timeout",
 		cfg.POET.RequestTimeout, "default timeout for poet requests")
+	flagSet.IntVar(&cfg.POET.SubmitQuorum, "poet-submit-quorum",
+		cfg.POET.SubmitQuorum, "stop waiting for poet submissions after that many registrations, 0 waits for all")
+	flagSet.StringSliceVar(&cfg.POET.PreferredPoets, "preferred-poets",
+		cfg.POET.PreferredPoets, "stop waiting for poet submissions after registering with any of these poets")
+	flagSet.IntVar(&cfg.POET.ProofQuorum, "poet-proof-quorum",
+		cfg.POET.ProofQuorum, "stop waiting for poet proofs after that many proofs are received, 0 waits for all")
+	flagSet.DurationVar(&cfg.POET.ProofTimeout, "poet-proof-timeout",
+		cfg.POET.ProofTimeout, "retry fetching the proof of a poet for that long after its round ended, 0 tries once")
+	flagSet.IntVar(&cfg.POET.SecondaryAfter, "poet-secondary-after",
+		cfg.POET.SecondaryAfter, "submit to secondary poets after that many attempts with all primary poets unstable")
 
 	/**======================== bootstrap data updater Flags ========================== **/
 
@@ -19,6 +19,20 @@ const (
 	PostStateIdle PostState = iota
 	// PostStateProving is the state of a PoST service that is currently proving.
 	PostStateProving
+	// PostStateInvalid is the state of an identity whose initial PoST couldn't be regenerated
+	// from its PoST data or failed verification. Smeshing is refused for such an identity.
+	PostStateInvalid
+	// PostStatePoetUnsupported is the state of an identity that can't register in any poet,
+	// because they serve an api version that the node doesn't support.
+	PostStatePoetUnsupported
+	// PostStatePublishing is the state of an identity whose ATX is built and waits to be broadcast.
+	PostStatePublishing
+	// PostStateDegraded is the state of an idle identity that failed to publish an ATX
+	// in consecutive epochs and keeps retrying.
+	PostStateDegraded
+	// PostStatePaused is the state of an identity whose attempts to publish an ATX are paused
+	// after repeated failures until it is resumed.
+	PostStatePaused
 )
 
 func (s PostState) String() string {
@@ -27,6 +41,16 @@ func (s PostState) String() string {
 		return "idle"
 	case PostStateProving:
 		return "proving"
+	case PostStateInvalid:
+		return "invalid"
+	case PostStatePoetUnsupported:
+		return "poet unsupported"
+	case PostStatePublishing:
+		return "publishing"
+	case PostStateDegraded:
+		return "degraded"
+	case PostStatePaused:
+		return "paused"
 	default:
 		panic(fmt.Sprintf("unknown post state %d", s))
 	}
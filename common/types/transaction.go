@@ -112,6 +112,112 @@ const (
 	APPLIED
 )
 
+// TXStatusReason explains why a transaction is in its current state.
+type TXStatusReason uint8
+
+const (
+	// TXReasonUnknown is used when the reason can't be derived.
+	TXReasonUnknown TXStatusReason = iota
+	// TXReasonInMempool is used when a transaction is in mempool and can be included in the next proposal.
+	TXReasonInMempool
+	// TXReasonNonceGapped is used when a transaction can't be included until transactions with
+	// lower nonces from the same principal are received.
+	TXReasonNonceGapped
+	// TXReasonInsufficientBalance is used when the projected balance of the principal can't cover
+	// the maximal spending of a transaction.
+	TXReasonInsufficientBalance
+	// TXReasonInProposal is used when a transaction is included in a proposal or block, but not applied yet.
+	TXReasonInProposal
+	// TXReasonApplied is used when a transaction is applied to the state.
+	TXReasonApplied
+	// TXReasonIneffective is used when the nonce of a transaction was consumed by another transaction.
+	TXReasonIneffective
+	// TXReasonEvicted is used when a transaction is feasible, but was evicted from mempool,
+	// for example in favor of a transaction with the same nonce and higher fee.
+	TXReasonEvicted
+)
+
+func (r TXStatusReason) String() string {
+	switch r {
+	case TXReasonInMempool:
+		return "in-mempool"
+	case TXReasonNonceGapped:
+		return "nonce-gapped"
+	case TXReasonInsufficientBalance:
+		return "insufficient-balance"
+	case TXReasonInProposal:
+		return "in-proposal"
+	case TXReasonApplied:
+		return "applied"
+	case TXReasonIneffective:
+		return "ineffective"
+	case TXReasonEvicted:
+		return "evicted"
+	default:
+		return "unknown"
+	}
+}
+
+// TXStatus is a state of a transaction with the reason for it.
+type TXStatus struct {
+	State  TXState
+	Reason TXStatusReason
+	// Layer is the layer where transaction is applied, or the layer of the proposal
+	// that includes the transaction if Reason is TXReasonInProposal.
+	Layer LayerID
+}
+
+// ProjectionCertainty selects which transactions that are not applied to the state yet
+// are accounted for in the projected nonce and balance of an account.
+type ProjectionCertainty uint8
+
+const (
+	// ProjectMempool accounts for all pending transactions in the mempool.
+	ProjectMempool ProjectionCertainty = iota
+	// ProjectPacked accounts only for transactions that are packed into proposals or blocks.
+	ProjectPacked
+	// ProjectApplied doesn't account for pending transactions, the projection is the current state.
+	ProjectApplied
+)
+
+func (c ProjectionCertainty) String() string {
+	switch c {
+	case ProjectMempool:
+		return "mempool"
+	case ProjectPacked:
+		return "packed"
+	case ProjectApplied:
+		return "applied"
+	default:
+		return "unknown"
+	}
+}
+
+// PendingNonce is a nonce of an account used by a pending transaction.
+type PendingNonce struct {
+	Nonce uint64        `json:"nonce"`
+	ID    TransactionID `json:"id"`
+	// Balance is the projected balance of the account after the transaction.
+	Balance uint64 `json:"balance"`
+	// Mempool is false if the transaction is only in the database, e.g. because the account
+	// has too many pending transactions or the balance was insufficient when it was received.
+	Mempool bool `json:"mempool"`
+}
+
+// NonceProjection describes which nonces of an account are used by pending transactions.
+type NonceProjection struct {
+	// Applied and AppliedBalance are the nonce and balance of the account in the applied state.
+	Applied        uint64 `json:"applied"`
+	AppliedBalance uint64 `json:"applied_balance"`
+	// Pending transactions ordered by nonce, one per nonce.
+	Pending []PendingNonce `json:"pending"`
+	// Next is the lowest nonce that is not used by a pending transaction. If there is a hole
+	// in the pending nonces, Next is the first nonce of the hole. Balance is the projected balance
+	// before a transaction with Next nonce.
+	Next    uint64 `json:"next"`
+	Balance uint64 `json:"balance"`
+}
+
 // MeshTransaction is stored in the mesh and included in the block.
 type MeshTransaction struct {
 	Transaction
@@ -25,6 +25,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/hare4"
 	"github.com/spacemeshos/go-spacemesh/miner"
 	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/sql/backup"
 	"github.com/spacemeshos/go-spacemesh/syncer"
 	timeConfig "github.com/spacemeshos/go-spacemesh/timesync/config"
 	"github.com/spacemeshos/go-spacemesh/tortoise"
@@ -100,30 +101,77 @@ type BaseConfig struct {
 
 	PoETServers DeprecatedPoETServers `mapstructure:"poet-server"`
 	PoetServers []types.PoetServer    `mapstructure:"poet-servers"`
+	// FallbackPoetServers are used for new registrations if PoST generation doesn't fit into
+	// the cycle gap of PoetServers. Their cycle gap is set with poet.fallback-cycle-gap.
+	FallbackPoetServers []types.PoetServer `mapstructure:"fallback-poet-servers"`
 
 	PprofHTTPServer         bool   `mapstructure:"pprof-server"`
 	PprofHTTPServerListener string `mapstructure:"pprof-listener"`
 	PprofMutexProfile       bool   `mapstructure:"pprof-mutex-profile"`
 	PprofBlockProfile       bool   `mapstructure:"pprof-block-profile"`
+	// FaultInjection enables the admin API that injects failures into poet and post clients,
+	// it requires a node built with the `faultinjection` tag.
+	FaultInjection bool `mapstructure:"fault-injection"`
 
 	TxsPerProposal int    `mapstructure:"txs-per-proposal"`
 	BlockGasLimit  uint64 `mapstructure:"block-gas-limit"`
+	// MinGasPrice is the lowest gas price of transactions accepted from gossip and proposals.
+	MinGasPrice uint64 `mapstructure:"min-gas-price"`
+	// MinFeePerByte is the lowest fee per encoded byte of transactions admitted to the mempool.
+	MinFeePerByte uint64 `mapstructure:"min-fee-per-byte"`
+	// MaxFeePerByte is the upper bound of the fee floor when FeeFloorAdjust is enabled.
+	MaxFeePerByte uint64 `mapstructure:"max-fee-per-byte"`
+	// FeeFloorAdjust adjusts the fee floor from the gas used by recently applied blocks.
+	FeeFloorAdjust bool `mapstructure:"fee-floor-adjust"`
+	// PriorityPrincipals are addresses whose feasible transactions are ordered before all other
+	// transactions in the mempool and always selected into proposals.
+	PriorityPrincipals []string `mapstructure:"priority-principals"`
+	// MempoolExport is a file where pending transactions are written when the node shuts down.
+	MempoolExport string `mapstructure:"mempool-export"`
+	// MempoolImport is a file with pending transactions, written by MempoolExport on another node,
+	// that are added to the mempool when the node starts.
+	MempoolImport string `mapstructure:"mempool-import"`
+	// MempoolCheckInterval is the interval of checking the conservative cache against the database
+	// and logging accounts that drifted. Meant for debugging, zero disables the check.
+	MempoolCheckInterval time.Duration `mapstructure:"mempool-check-interval"`
+	// MempoolExpectRewards adds rewards that the smeshing coinbase expects from blocks that are not
+	// applied yet to its projected balance in the mempool.
+	MempoolExpectRewards bool `mapstructure:"mempool-expect-rewards"`
+	// MempoolDormantLayers is the number of layers after which accounts whose pending transactions are
+	// all infeasible are evicted from the mempool cache. Evicted accounts are re-admitted when their state
+	// changes. Zero disables the eviction.
+	MempoolDormantLayers uint32 `mapstructure:"mempool-dormant-layers"`
+
+	// ATXsDataVerifySamples is the number of atxs per epoch that are compared with the database
+	// after the consensus cache is warmed up on startup. Zero disables the verification.
+	ATXsDataVerifySamples int `mapstructure:"atxsdata-verify-samples"`
+	// ATXsDataRebuild rebuilds the consensus cache if the verification found discrepancies.
+	ATXsDataRebuild bool `mapstructure:"atxsdata-rebuild"`
 	// if the number of proposals with the same mesh state crosses this threshold (in percentage),
 	// then we optimistically filter out infeasible transactions before constructing the block.
-	OptFilterThreshold int    `mapstructure:"optimistic-filtering-threshold"`
-	TickSize           uint64 `mapstructure:"tick-size"`
+	OptFilterThreshold int `mapstructure:"optimistic-filtering-threshold"`
+	// ProposalFetchTimeout bounds the time spent fetching proposals of the hare output before
+	// the block for the layer is skipped. Zero leaves fetching bounded only by the fetcher.
+	ProposalFetchTimeout time.Duration `mapstructure:"proposal-fetch-timeout"`
+	TickSize             uint64        `mapstructure:"tick-size"`
 
 	DatabaseConnections          int                     `mapstructure:"db-connections"`
 	DatabaseLatencyMetering      bool                    `mapstructure:"db-latency-metering"`
 	DatabaseSizeMeteringInterval time.Duration           `mapstructure:"db-size-metering-interval"`
+	DatabaseCountRows            bool                    `mapstructure:"db-count-rows"`
 	DatabasePruneInterval        time.Duration           `mapstructure:"db-prune-interval"`
 	DatabaseVacuumState          int                     `mapstructure:"db-vacuum-state"`
 	DatabaseSkipMigrations       []int                   `mapstructure:"db-skip-migrations"`
 	DatabaseQueryCache           bool                    `mapstructure:"db-query-cache"`
 	DatabaseQueryCacheSizes      DatabaseQueryCacheSizes `mapstructure:"db-query-cache-sizes"`
 	DatabaseSchemaAllowDrift     bool                    `mapstructure:"db-allow-schema-drift"`
+	// DatabaseBackup configures periodic verified backups of the state and local databases.
+	DatabaseBackup backup.Config `mapstructure:"db-backup"`
 
 	PruneActivesetsFrom types.EpochID `mapstructure:"prune-activesets-from"`
+	// PrunePoetProofsAfter is the number of epochs after which poet proofs that are not referenced
+	// by any atx are pruned. Zero keeps all proofs.
+	PrunePoetProofsAfter uint32 `mapstructure:"prune-poet-proofs-after"`
 
 	NetworkHRP string `mapstructure:"network-hrp"`
 
@@ -132,6 +180,18 @@ type BaseConfig struct {
 	MinerGoodAtxsPercent int `mapstructure:"miner-good-atxs-percent"`
 
 	RegossipAtxInterval time.Duration `mapstructure:"regossip-atx-interval"`
+	// AtxPublishInterval spreads broadcasts of ATXs of different identities managed by the node
+	// at least that far apart. Zero broadcasts them as soon as they are ready.
+	AtxPublishInterval time.Duration `mapstructure:"atx-publish-interval"`
+	// AtxAutoPauseEpochs pauses attempts of an identity to publish ATXs after they failed in that many
+	// consecutive epochs, until the identity is resumed over the API. Zero never pauses.
+	AtxAutoPauseEpochs int `mapstructure:"atx-auto-pause-epochs"`
+	// ReadinessCheckLead checks the readiness of the identities managed by the node that long before
+	// the registration window of every poet round opens. Zero disables the checks.
+	ReadinessCheckLead time.Duration `mapstructure:"readiness-check-lead"`
+	// ReadinessMinFreeSpace is the space in bytes that must be available in the data directory
+	// for the identities to be ready. Zero skips the check.
+	ReadinessMinFreeSpace uint64 `mapstructure:"readiness-min-free-space"`
 
 	// ATXGradeDelay is used to grade ATXs for selection in tortoise active set.
 	// See grading function in miner/proposals_builder.go
@@ -237,12 +297,17 @@ func defaultBaseConfig() BaseConfig {
 		LayerDuration:                30 * time.Second,
 		LayersPerEpoch:               3,
 		TxsPerProposal:               100,
+		MempoolDormantLayers:         288,
 		BlockGasLimit:                math.MaxUint64,
+		MinGasPrice:                  1,
 		OptFilterThreshold:           90,
+		ProposalFetchTimeout:         time.Minute,
 		TickSize:                     100,
 		DatabaseConnections:          16,
 		DatabaseSizeMeteringInterval: 10 * time.Minute,
 		DatabasePruneInterval:        30 * time.Minute,
+		PrunePoetProofsAfter:         4,
+		DatabaseBackup:               backup.DefaultConfig(),
 		DatabaseQueryCacheSizes: DatabaseQueryCacheSizes{
 			EpochATXs:     20,
 			ATXBlob:       10000,
@@ -24,6 +24,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/hare4"
 	"github.com/spacemeshos/go-spacemesh/miner"
 	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/sql/backup"
 	"github.com/spacemeshos/go-spacemesh/syncer"
 	"github.com/spacemeshos/go-spacemesh/syncer/atxsync"
 	"github.com/spacemeshos/go-spacemesh/syncer/malsync"
@@ -80,7 +81,9 @@ func MainnetConfig() Config {
 			DatabaseConnections:   16,
 			DatabasePruneInterval: 30 * time.Minute,
 			DatabaseVacuumState:   21,
+			DatabaseBackup:        backup.DefaultConfig(),
 			PruneActivesetsFrom:   12, // starting from epoch 13 activesets below 12 will be pruned
+			PrunePoetProofsAfter:  4,
 			NetworkHRP:            "sm",
 
 			LayerDuration:  5 * time.Minute,
@@ -89,8 +92,12 @@ func MainnetConfig() Config {
 
 			TxsPerProposal: 700,       // https://github.com/spacemeshos/go-spacemesh/issues/4559
 			BlockGasLimit:  100107000, // 3000 of spends
+			MinGasPrice:    1,
 
-			OptFilterThreshold: 90,
+			MempoolDormantLayers: 288, // a day
+
+			OptFilterThreshold:   90,
+			ProposalFetchTimeout: time.Minute,
 
 			TickSize: 9331200,
 			PoetServers: []types.PoetServer{
@@ -117,6 +124,7 @@ func MainnetConfig() Config {
 			},
 			RegossipAtxInterval:     2 * time.Hour,
 			ATXGradeDelay:           30 * time.Minute,
+			ReadinessCheckLead:      2 * time.Hour,
 			PostValidDelay:          time.Duration(math.MaxInt64),
 			PprofHTTPServerListener: "localhost:6060",
 		},
@@ -142,6 +150,7 @@ func MainnetConfig() Config {
 		HARE4: hare4conf,
 		HareEligibility: eligibility.Config{
 			ConfidenceParam: 200,
+			WarmupLayers:    10,
 		},
 		Certificate: blocks.CertConfig{
 			// NOTE(dshulyak) this is intentional. we increased committee size with hare3 upgrade
@@ -25,6 +25,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/hare4"
 	"github.com/spacemeshos/go-spacemesh/miner"
 	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/sql/backup"
 	"github.com/spacemeshos/go-spacemesh/syncer"
 	"github.com/spacemeshos/go-spacemesh/syncer/atxsync"
 	"github.com/spacemeshos/go-spacemesh/syncer/malsync"
@@ -69,6 +70,7 @@ func testnet() config.Config {
 			DatabaseConnections:          16,
 			DatabaseSizeMeteringInterval: 10 * time.Minute,
 			DatabasePruneInterval:        30 * time.Minute,
+			DatabaseBackup:               backup.DefaultConfig(),
 			NetworkHRP:                   "stest",
 
 			LayerDuration:  5 * time.Minute,
@@ -77,8 +79,12 @@ func testnet() config.Config {
 
 			TxsPerProposal: 700,       // https://github.com/spacemeshos/go-spacemesh/issues/4559
 			BlockGasLimit:  100107000, // 3000 of spends
+			MinGasPrice:    1,
 
-			OptFilterThreshold: 90,
+			MempoolDormantLayers: 288, // a day
+
+			OptFilterThreshold:   90,
+			ProposalFetchTimeout: time.Minute,
 
 			TickSize:            666514,
 			RegossipAtxInterval: time.Hour,
@@ -102,6 +108,7 @@ func testnet() config.Config {
 		HARE4: hare4conf,
 		HareEligibility: eligibility.Config{
 			ConfidenceParam: 20,
+			WarmupLayers:    5,
 		},
 		Beacon: beacon.Config{
 			Kappa:                    40,
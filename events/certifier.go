@@ -0,0 +1,61 @@
+package events
+
+import (
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// CertificateStatus is the outcome of a request for a poet certificate.
+type CertificateStatus string
+
+const (
+	// CertificateStored is reported when a valid certificate was found in the local database.
+	CertificateStored CertificateStatus = "stored"
+	// CertificateExpired is reported when a stored certificate expired and was deleted.
+	CertificateExpired CertificateStatus = "expired"
+	// CertificateCertified is reported when a new certificate was obtained from a certifier.
+	CertificateCertified CertificateStatus = "certified"
+	// CertificateFailed is reported when a certifier failed to certify the identity.
+	CertificateFailed CertificateStatus = "failed"
+)
+
+// EventPoetCertificate describes an interaction with a certifier on behalf of an identity.
+type EventPoetCertificate struct {
+	NodeID types.NodeID
+	// Poet is the address of the poet that the certificate is requested for.
+	Poet      string
+	Certifier string
+	Status    CertificateStatus
+	// Expiration of the certificate, nil if the certificate doesn't expire or there is no certificate.
+	Expiration *time.Time
+	// Duration of the call to the certifier, zero for stored certificates.
+	Duration time.Duration
+	Error    string
+}
+
+// ReportPoetCertificate reports an interaction with a certifier.
+func ReportPoetCertificate(ev EventPoetCertificate) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.certificateEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit poet certificate", log.Err(err))
+		}
+	}
+}
+
+// SubscribePoetCertificates subscribes to interactions with certifiers.
+func SubscribePoetCertificates() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventPoetCertificate))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to poet certificates")
+		}
+		return sub
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// EventMempoolDiff describes the mempool at the time transactions were selected for a proposal,
+// and how it changed since the previous proposal.
+type EventMempoolDiff struct {
+	Layer types.LayerID
+	// Candidates is the number of transactions in the mempool that were eligible for the proposal.
+	Candidates int
+	// Included are the candidates selected for the proposal.
+	Included []types.TransactionID
+	// Missed are the candidates that were not selected for the proposal.
+	Missed []types.TransactionID
+	// Added are the candidates that were not candidates for the previous proposal.
+	Added []types.TransactionID
+	// Removed are the candidates for the previous proposal that are no longer candidates.
+	Removed []types.TransactionID
+}
+
+// ReportMempoolDiff reports the mempool diff for a proposal.
+func ReportMempoolDiff(ev EventMempoolDiff) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.mempoolEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit mempool diff", log.Err(err))
+		}
+	}
+}
+
+// SubscribeMempoolDiffs subscribes to the mempool diffs of built proposals.
+func SubscribeMempoolDiffs() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventMempoolDiff))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to mempool diffs")
+		}
+		return sub
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// EventPostTooSlow is reported when PoST generation of an identity leaves less than the warning margin
+// of the poet cycle gap. If PoST doesn't fit into the cycle gap the node misses poet rounds and rewards.
+type EventPostTooSlow struct {
+	Smesher  types.NodeID
+	Duration time.Duration
+	// CycleGap is the cycle gap of the poets the identity uses for new registrations.
+	CycleGap time.Duration
+	// Fallback is true if the identity uses the fallback poets.
+	Fallback bool
+}
+
+// ReportPostTooSlow reports that PoST generation barely fits or doesn't fit into the poet cycle gap.
+func ReportPostTooSlow(ev EventPostTooSlow) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.postTooSlowEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit post too slow", log.Err(err))
+		}
+	}
+}
+
+// SubscribePostTooSlow subscribes to reports of PoST generation that is too slow for the poet cycle gap.
+func SubscribePostTooSlow() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventPostTooSlow))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to post too slow")
+		}
+		return sub
+	}
+	return nil
+}
@@ -57,3 +57,75 @@ func SubscribeProposals() Subscription {
 	}
 	return nil
 }
+
+// EventUnavailableProposals is reported when hare output includes proposals that are not
+// available locally and couldn't be fetched from peers. Block for the layer can't be generated
+// and the layer is left to tortoise.
+type EventUnavailableProposals struct {
+	Layer     types.LayerID
+	Proposals []types.ProposalID
+}
+
+// ReportUnavailableProposals reports proposals from hare output that are unavailable.
+func ReportUnavailableProposals(layer types.LayerID, proposals []types.ProposalID) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		err := reporter.unavailableEmitter.Emit(EventUnavailableProposals{Layer: layer, Proposals: proposals})
+		if err != nil {
+			log.With().Error("failed to emit unavailable proposals", log.Err(err))
+		}
+	}
+}
+
+// SubscribeUnavailableProposals subscribes to the proposals from hare output that are unavailable.
+func SubscribeUnavailableProposals() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventUnavailableProposals))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to unavailable proposals")
+		}
+		return sub
+	}
+	return nil
+}
+
+// EventHareDivergence is reported when hare output for a layer disagrees with the block
+// that was later verified by tortoise beyond the configured threshold.
+type EventHareDivergence struct {
+	Layer types.LayerID
+	// Proposals is the hare output for the layer.
+	Proposals []types.ProposalID
+	// Block is the block that was applied after tortoise verified the layer.
+	Block types.BlockID
+	// Divergence is a fraction of eligibilities that are present either in hare output
+	// or in the verified block, but not in both.
+	Divergence float64
+}
+
+// ReportHareDivergence reports divergence between hare output and tortoise.
+func ReportHareDivergence(ev EventHareDivergence) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.divergenceEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit hare divergence", log.Err(err))
+		}
+	}
+}
+
+// SubscribeHareDivergence subscribes to divergences between hare output and tortoise.
+func SubscribeHareDivergence() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventHareDivergence))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to hare divergence")
+		}
+		return sub
+	}
+	return nil
+}
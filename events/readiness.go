@@ -0,0 +1,50 @@
+package events
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// ReadinessCheck is the outcome of a single readiness check of an identity.
+type ReadinessCheck struct {
+	Name string
+	// Skipped is true if the check is not configured, it doesn't count towards the score.
+	Skipped bool
+	// Error is why the check failed, empty if it passed.
+	Error string
+}
+
+// EventReadiness is reported when the readiness of an identity to register for the poet round,
+// that the ATX published in PublishEpoch is built for, is evaluated.
+type EventReadiness struct {
+	NodeID       types.NodeID
+	PublishEpoch types.EpochID
+	// Score is the share of the checks that passed, 1 if the identity is ready.
+	Score  float64
+	Checks []ReadinessCheck
+}
+
+// ReportReadiness reports the readiness of an identity.
+func ReportReadiness(ev EventReadiness) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.readinessEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit readiness", log.Err(err))
+		}
+	}
+}
+
+// SubscribeReadiness subscribes to the readiness reports of identities.
+func SubscribeReadiness() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventReadiness))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to readiness")
+		}
+		return sub
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/p2p"
 )
 
 // Subscription is a subscription to events.
@@ -69,12 +70,12 @@ func ReportNewTx(layerID types.LayerID, tx *types.Transaction) error {
 	return nil
 }
 
-// ReportNewActivation reports a new activation.
-func ReportNewActivation(activation *types.ActivationTx) error {
+// ReportNewActivation reports a new activation received from the peer.
+func ReportNewActivation(activation *types.ActivationTx, peer p2p.Peer) error {
 	mu.RLock()
 	defer mu.RUnlock()
 
-	activationTxEvent := ActivationTx{activation}
+	activationTxEvent := ActivationTx{ActivationTx: activation, Peer: peer}
 	if reporter != nil {
 		return reporter.activationEmitter.Emit(activationTxEvent)
 	}
@@ -331,6 +332,9 @@ type Transaction struct {
 // ActivationTx wraps *types.ActivationTx.
 type ActivationTx struct {
 	*types.ActivationTx
+	// Peer is the peer the activation was received from, it is empty if the activation
+	// wasn't received from another node.
+	Peer p2p.Peer
 }
 
 // Status indicates status change event.
@@ -354,6 +358,14 @@ type EventReporter struct {
 	resultsEmitter     event.Emitter
 	proposalsEmitter   event.Emitter
 	malfeasanceEmitter event.Emitter
+	unavailableEmitter event.Emitter
+	divergenceEmitter  event.Emitter
+	mempoolEmitter     event.Emitter
+	certifiedEmitter   event.Emitter
+	revertedEmitter    event.Emitter
+	certificateEmitter event.Emitter
+	readinessEmitter   event.Emitter
+	postTooSlowEmitter event.Emitter
 	events             struct {
 		sync.Mutex
 		buf     *Ring[UserEvent]
@@ -426,6 +438,38 @@ func newEventReporter() *EventReporter {
 	if err != nil {
 		log.With().Panic("failed to create malfeasance emitter", log.Err(err))
 	}
+	unavailableEmitter, err := bus.Emitter(new(EventUnavailableProposals))
+	if err != nil {
+		log.With().Panic("failed to create unavailable proposals emitter", log.Err(err))
+	}
+	divergenceEmitter, err := bus.Emitter(new(EventHareDivergence))
+	if err != nil {
+		log.With().Panic("failed to create hare divergence emitter", log.Err(err))
+	}
+	mempoolEmitter, err := bus.Emitter(new(EventMempoolDiff))
+	if err != nil {
+		log.With().Panic("failed to create mempool diff emitter", log.Err(err))
+	}
+	certifiedEmitter, err := bus.Emitter(new(EventTxsCertified))
+	if err != nil {
+		log.With().Panic("failed to create certified txs emitter", log.Err(err))
+	}
+	revertedEmitter, err := bus.Emitter(new(EventTxsReverted))
+	if err != nil {
+		log.With().Panic("failed to create reverted txs emitter", log.Err(err))
+	}
+	certificateEmitter, err := bus.Emitter(new(EventPoetCertificate))
+	if err != nil {
+		log.With().Panic("failed to create poet certificate emitter", log.Err(err))
+	}
+	readinessEmitter, err := bus.Emitter(new(EventReadiness))
+	if err != nil {
+		log.With().Panic("failed to create readiness emitter", log.Err(err))
+	}
+	postTooSlowEmitter, err := bus.Emitter(new(EventPostTooSlow))
+	if err != nil {
+		log.With().Panic("failed to create post too slow emitter", log.Err(err))
+	}
 
 	reporter := &EventReporter{
 		bus:                bus,
@@ -439,6 +483,14 @@ func newEventReporter() *EventReporter {
 		errorEmitter:       errorEmitter,
 		proposalsEmitter:   proposalsEmitter,
 		malfeasanceEmitter: malfeasanceEmitter,
+		unavailableEmitter: unavailableEmitter,
+		divergenceEmitter:  divergenceEmitter,
+		mempoolEmitter:     mempoolEmitter,
+		certifiedEmitter:   certifiedEmitter,
+		revertedEmitter:    revertedEmitter,
+		certificateEmitter: certificateEmitter,
+		readinessEmitter:   readinessEmitter,
+		postTooSlowEmitter: postTooSlowEmitter,
 		stopChan:           make(chan struct{}),
 	}
 	reporter.events.buf = newRing[UserEvent](100)
@@ -481,6 +533,30 @@ func CloseEventReporter() {
 		if err := reporter.malfeasanceEmitter.Close(); err != nil {
 			log.With().Panic("failed to close malfeasanceEmitter", log.Err(err))
 		}
+		if err := reporter.unavailableEmitter.Close(); err != nil {
+			log.With().Panic("failed to close unavailableEmitter", log.Err(err))
+		}
+		if err := reporter.divergenceEmitter.Close(); err != nil {
+			log.With().Panic("failed to close divergenceEmitter", log.Err(err))
+		}
+		if err := reporter.mempoolEmitter.Close(); err != nil {
+			log.With().Panic("failed to close mempoolEmitter", log.Err(err))
+		}
+		if err := reporter.certifiedEmitter.Close(); err != nil {
+			log.With().Panic("failed to close certifiedEmitter", log.Err(err))
+		}
+		if err := reporter.revertedEmitter.Close(); err != nil {
+			log.With().Panic("failed to close revertedEmitter", log.Err(err))
+		}
+		if err := reporter.certificateEmitter.Close(); err != nil {
+			log.With().Panic("failed to close certificateEmitter", log.Err(err))
+		}
+		if err := reporter.readinessEmitter.Close(); err != nil {
+			log.With().Panic("failed to close readinessEmitter", log.Err(err))
+		}
+		if err := reporter.postTooSlowEmitter.Close(); err != nil {
+			log.With().Panic("failed to close postTooSlowEmitter", log.Err(err))
+		}
 
 		close(reporter.stopChan)
 		reporter = nil
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// EventTxsCertified is reported when the block that includes the transactions is certified,
+// i.e. it is the hare output for the layer agreed upon by the certifying committee.
+// The transactions are not executed yet, their results are reported with ReportResult
+// once the layer is applied.
+type EventTxsCertified struct {
+	Layer types.LayerID
+	Block types.BlockID
+	Txs   []types.TransactionID
+}
+
+// ReportTxsCertified reports the transactions included in a certified block.
+func ReportTxsCertified(ev EventTxsCertified) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.certifiedEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit certified txs", log.Err(err))
+		}
+	}
+}
+
+// SubscribeTxsCertified subscribes to the transactions included in certified blocks.
+func SubscribeTxsCertified() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventTxsCertified))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to certified txs")
+		}
+		return sub
+	}
+	return nil
+}
+
+// EventTxsReverted is reported when layers are reverted and the transactions applied in them
+// return to pending. They are applied again, and reported with ReportResult, once they are
+// included in a block of a layer that is applied after the revert.
+type EventTxsReverted struct {
+	// RevertTo is the last layer that remains applied.
+	RevertTo types.LayerID
+	Txs      []types.TransactionID
+}
+
+// ReportTxsReverted reports the transactions that returned to pending after a revert.
+func ReportTxsReverted(ev EventTxsReverted) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.revertedEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit reverted txs", log.Err(err))
+		}
+	}
+}
+
+// SubscribeTxsReverted subscribes to the transactions that returned to pending after a revert.
+func SubscribeTxsReverted() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventTxsReverted))
+		if err != nil {
+			log.With().Panic("Failed to subscribe to reverted txs")
+		}
+		return sub
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
@@ -33,12 +34,27 @@ const (
 	meshHashProtocol  = "mh/1"
 	malProtocol       = "ml/1"
 	OpnProtocol       = "lp/2"
+	// layerProtocol serves the requests of lyrDataProtocol and OpnProtocol with a server.Router.
+	layerProtocol = "ly/1"
 
 	cacheSize = 1000
 
 	RedundantPeers = 5
 )
 
+// request kinds of layerProtocol.
+const (
+	lyrDataKind server.RequestKind = iota
+	opnKind
+)
+
+// layerRequestKinds maps the protocols of the layer requests to their kinds on layerProtocol.
+// The protocols are still served for peers that don't support layerProtocol.
+var layerRequestKinds = map[string]server.RequestKind{
+	lyrDataProtocol: lyrDataKind,
+	OpnProtocol:     opnKind,
+}
+
 var (
 	// ErrExceedMaxRetries is returned when MaxRetriesForRequest attempts has been made to fetch
 	// data for a hash and failed.
@@ -71,6 +87,8 @@ type batchInfo struct {
 	RequestBatch
 	protocol string
 	peer     p2p.Peer
+	// priority is true if the batch contains requests marked with server.WithPriority.
+	priority bool
 }
 
 // setID calculates the hash of all requests and sets it as this batches ID.
@@ -112,6 +130,12 @@ type ServerConfig struct {
 	Queue    int           `mapstructure:"queue"`
 	Requests int           `mapstructure:"requests"`
 	Interval time.Duration `mapstructure:"interval"`
+	// StreamIdle enables reuse of streams for consecutive requests, see server.WithStreamReuse.
+	StreamIdle time.Duration `mapstructure:"stream-idle"`
+	// PriorityQueue and PriorityRequests enable the priority lane for requests with the priority hint
+	// from priority peers, see server.WithPriorityLane.
+	PriorityQueue    int `mapstructure:"priority-queue"`
+	PriorityRequests int `mapstructure:"priority-requests"`
 }
 
 func (s ServerConfig) toOpts() []server.Opt {
@@ -122,6 +146,12 @@ func (s ServerConfig) toOpts() []server.Opt {
 	if s.Requests != 0 && s.Interval != 0 {
 		opts = append(opts, server.WithRequestsPerInterval(s.Requests, s.Interval))
 	}
+	if s.StreamIdle != 0 {
+		opts = append(opts, server.WithStreamReuse(s.StreamIdle))
+	}
+	if s.PriorityQueue != 0 && s.PriorityRequests != 0 {
+		opts = append(opts, server.WithPriorityLane(s.PriorityQueue, s.PriorityRequests))
+	}
 	return opts
 }
 
@@ -140,6 +170,12 @@ type Config struct {
 	GetAtxsConcurrency   int64                  `mapstructure:"getatxsconcurrency"`
 	DecayingTag          server.DecayingTagSpec `mapstructure:"decaying-tag"`
 	LogPeerStatsInterval time.Duration          `mapstructure:"log-peer-stats-interval"`
+	// EchoInterval is how often connected peers are checked with the echo protocol, zero disables the checks.
+	EchoInterval time.Duration `mapstructure:"echo-interval"`
+	Push         PushConfig    `mapstructure:"push"`
+	// PriorityPeers are ids of peers whose requests with the priority hint are served in the priority lane
+	// of protocols that have it enabled (see ServerConfig.PriorityQueue).
+	PriorityPeers []string `mapstructure:"priority-peers"`
 }
 
 func (c Config) getServerConfig(protocol string) ServerConfig {
@@ -172,7 +208,11 @@ func DefaultConfig() Config {
 			// atx - 1 KB
 			// ballots > 300 bytes
 			// often queried after receiving gossip message
-			hashProtocol: {Queue: 2000, Requests: 200, Interval: time.Second},
+			// proposals for the current layer are requested in the priority lane
+			hashProtocol: {
+				Queue: 2000, Requests: 200, Interval: time.Second,
+				PriorityQueue: 50, PriorityRequests: 20,
+			},
 			// active sets (can get quite large)
 			activeSetProtocol: {Queue: 10, Requests: 1, Interval: time.Second},
 			// serves at most 100 hashes - 3KB
@@ -180,7 +220,19 @@ func DefaultConfig() Config {
 			// serves all malicious ids (id - 32 byte) - 10KB
 			malProtocol: {Queue: 100, Requests: 10, Interval: time.Second},
 			// 64 bytes
-			OpnProtocol: {Queue: 10000, Requests: 1000, Interval: time.Second},
+			// block certificates are requested in the priority lane
+			OpnProtocol: {
+				Queue: 10000, Requests: 1000, Interval: time.Second,
+				PriorityQueue: 50, PriorityRequests: 50,
+			},
+			// layer data and opinions, served by a single queue
+			// certificates are requested in the priority lane
+			layerProtocol: {
+				Queue: 11000, Requests: 1100, Interval: time.Second,
+				PriorityQueue: 50, PriorityRequests: 50,
+			},
+			// pushed objects, validated on arrival
+			pushProtocol: {Queue: 100, Requests: 50, Interval: time.Second},
 		},
 		Streaming:          true,
 		GetAtxsConcurrency: 100,
@@ -191,6 +243,7 @@ func DefaultConfig() Config {
 			Cap:      10000,
 		},
 		LogPeerStatsInterval: 20 * time.Minute,
+		Push:                 DefaultPushConfig(),
 	}
 }
 
@@ -244,7 +297,9 @@ type Fetch struct {
 	peers  *peers.Peers
 
 	servers    map[string]requester
+	echo       *server.Echo // nil if there is no host
 	validators *dataValidators
+	pusher     *pusher // nil if the push is disabled
 
 	// unprocessed contains requests that are not processed
 	unprocessed map[types.Hash32]*request
@@ -260,6 +315,7 @@ type Fetch struct {
 	eg          errgroup.Group
 
 	getAtxsLimiter limiter
+	priorityPeers  []p2p.Peer
 }
 
 // NewFetch creates a new Fetch struct.
@@ -285,6 +341,17 @@ func NewFetch(
 		opt(f)
 	}
 	f.getAtxsLimiter = semaphore.NewWeighted(f.cfg.GetAtxsConcurrency)
+	for _, id := range f.cfg.PriorityPeers {
+		pid, err := peer.Decode(id)
+		if err != nil {
+			f.logger.Warn("ignoring invalid priority peer", zap.String("id", id), zap.Error(err))
+			continue
+		}
+		f.priorityPeers = append(f.priorityPeers, pid)
+	}
+	if f.cfg.Push.Enable {
+		f.pusher = newPusher(f.logger.Named("push"), f.cfg.Push)
+	}
 	f.peers = peers.New()
 	// NOTE(dshulyak) this is to avoid tests refactoring.
 	// there is one test that covers this part.
@@ -312,11 +379,20 @@ func NewFetch(
 				connectedf(peer)
 			}
 		}
+		f.echo = server.NewEcho(host,
+			server.WithEchoInterval(f.cfg.EchoInterval),
+			server.WithEchoLog(f.logger.Named("echo")),
+		)
 	}
 
 	f.batchTimeout = time.NewTicker(f.cfg.BatchTimeout)
 	if len(f.servers) == 0 {
 		h := newHandler(cdb, bs, f.logger.Named("handler"))
+		if f.pusher != nil {
+			h.served = f.pusher.served
+			f.registerServer(host, pushProtocol, server.WrapHandler(f.handlePush),
+				server.WithRequestSizeLimit(maxPushSize+1024))
+		}
 		if f.cfg.Streaming {
 			f.registerServer(host, atxProtocol, h.handleEpochInfoReqStream)
 			f.registerServer(host, hashProtocol, h.handleHashReqStream)
@@ -338,8 +414,14 @@ func NewFetch(
 			f.registerServer(host, meshHashProtocol, server.WrapHandler(h.handleMeshHashReq))
 			f.registerServer(host, malProtocol, server.WrapHandler(h.handleMaliciousIDsReq))
 		}
-		f.registerServer(host, lyrDataProtocol, server.WrapHandler(h.handleLayerDataReq))
-		f.registerServer(host, OpnProtocol, server.WrapHandler(h.handleLayerOpinionsReq2))
+		lyrData := server.WrapHandler(h.handleLayerDataReq)
+		opn := server.WrapHandler(h.handleLayerOpinionsReq2)
+		router := server.NewRouter(layerProtocol)
+		router.Register(lyrDataKind, lyrData)
+		router.Register(opnKind, opn)
+		f.registerServer(host, layerProtocol, router.Handle)
+		f.registerServer(host, lyrDataProtocol, lyrData)
+		f.registerServer(host, OpnProtocol, opn)
 	}
 	return f
 }
@@ -348,6 +430,7 @@ func (f *Fetch) registerServer(
 	host *p2p.Host,
 	protocol string,
 	handler server.StreamHandler,
+	extra ...server.Opt,
 ) {
 	opts := []server.Opt{
 		server.WithTimeout(f.cfg.RequestTimeout),
@@ -359,6 +442,10 @@ func (f *Fetch) registerServer(
 		opts = append(opts, server.WithMetrics())
 	}
 	opts = append(opts, f.cfg.getServerConfig(protocol).toOpts()...)
+	if len(f.priorityPeers) > 0 {
+		opts = append(opts, server.WithPriorityPeers(f.priorityPeers...))
+	}
+	opts = append(opts, extra...)
 	f.servers[protocol] = server.New(host, protocol, handler, opts...)
 }
 
@@ -414,6 +501,17 @@ func (f *Fetch) Start() error {
 				return srv.Run(f.shutdownCtx)
 			})
 		}
+		if f.echo != nil {
+			f.eg.Go(func() error {
+				return f.echo.Run(f.shutdownCtx)
+			})
+		}
+		if f.pusher != nil {
+			f.eg.Go(func() error {
+				f.runPusher(f.shutdownCtx)
+				return nil
+			})
+		}
 		f.eg.Go(func() error {
 			for {
 				select {
@@ -491,6 +589,16 @@ func (f *Fetch) meteredRequest(
 	return resp, err
 }
 
+// layerRequest sends the request of a layer protocol on layerProtocol if the peer is known to support it,
+// and on the protocol itself otherwise.
+func (f *Fetch) layerRequest(ctx context.Context, protocol string, peer p2p.Peer, req []byte) ([]byte, error) {
+	supported, err := f.host.Peerstore().SupportsProtocols(peer, layerProtocol)
+	if err == nil && len(supported) > 0 {
+		return f.meteredRequest(ctx, layerProtocol, peer, server.RoutedRequest(layerRequestKinds[protocol], req))
+	}
+	return f.meteredRequest(ctx, protocol, peer, req)
+}
+
 func (f *Fetch) meteredStreamRequest(
 	ctx context.Context,
 	protocol string,
@@ -734,37 +842,66 @@ func (f *Fetch) organizeRequests(requests []RequestMessage) map[p2p.Peer][]*batc
 	result := make(map[p2p.Peer][]*batchInfo)
 	for peer, reqs := range peer2requests {
 		j := 0
+		var priority []RequestMessage
 		for i, req := range reqs {
 			// Use batches of size 1 for hashes with specific protocol.
 			// This is currently used for active sets which are too large
 			// to be batched.
 			protocol, found := protocolMap[req.Hint]
-			if !found {
-				reqs[j] = reqs[i]
-				j++
-			} else {
+			switch {
+			case found:
 				b := makeBatch(peer, []RequestMessage{reqs[i]})
 				b.protocol = protocol
 				result[peer] = append(result[peer], b)
+			case f.isPriority(req.Hash):
+				// priority requests are batched separately, so that they are not delayed by others
+				priority = append(priority, req)
+			default:
+				reqs[j] = reqs[i]
+				j++
 			}
 		}
 		reqs = reqs[:j]
+		for _, batch := range f.makeBatches(peer, priority) {
+			batch.priority = true
+			result[peer] = append(result[peer], batch)
+		}
 		if len(reqs) < f.cfg.BatchSize {
 			result[peer] = append(result[peer], makeBatch(peer, reqs))
 			continue
 		}
-		for i := 0; i < len(reqs); i += f.cfg.BatchSize {
-			j := i + f.cfg.BatchSize
-			if j > len(reqs) {
-				j = len(reqs)
-			}
-			result[peer] = append(result[peer], makeBatch(peer, reqs[i:j]))
-		}
+		result[peer] = append(result[peer], f.makeBatches(peer, reqs)...)
 	}
 
 	return result
 }
 
+// makeBatches splits requests into batches of f.cfg.BatchSize each.
+func (f *Fetch) makeBatches(peer p2p.Peer, reqs []RequestMessage) []*batchInfo {
+	var batches []*batchInfo
+	for i := 0; i < len(reqs); i += f.cfg.BatchSize {
+		j := min(i+f.cfg.BatchSize, len(reqs))
+		batches = append(batches, makeBatch(peer, reqs[i:j]))
+	}
+	return batches
+}
+
+// isPriority returns true if the ongoing request for the hash is marked with server.WithPriority.
+func (f *Fetch) isPriority(hash types.Hash32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	req, ok := f.ongoing[hash]
+	return ok && server.IsPriority(req.ctx)
+}
+
+// batchContext returns the context of requests of the batch.
+func (f *Fetch) batchContext(batch *batchInfo) context.Context {
+	if batch.priority {
+		return server.WithPriority(f.shutdownCtx)
+	}
+	return f.shutdownCtx
+}
+
 // streamBatch dispatches batched request messages to provided peer and
 // receives the response in streaming mode.
 func (f *Fetch) streamBatch(peer p2p.Peer, batch *batchInfo) error {
@@ -781,7 +918,7 @@ func (f *Fetch) streamBatch(peer p2p.Peer, batch *batchInfo) error {
 	// is large or target peer is not connected
 	req := codec.MustEncode(&batch.RequestBatch)
 	err := f.meteredStreamRequest(
-		f.shutdownCtx, hashProtocol, peer, req,
+		f.batchContext(batch), hashProtocol, peer, req,
 		func(ctx context.Context, s io.ReadWriter) (int, error) {
 			batchMap := batch.toMap()
 
@@ -903,7 +1040,7 @@ func (f *Fetch) sendBatch(peer p2p.Peer, batch *batchInfo) ([]byte, error) {
 	// it will return errors only if size of the bytes buffer is large
 	// or target peer is not connected
 	req := codec.MustEncode(&batch.RequestBatch)
-	return f.meteredRequest(f.shutdownCtx, hashProtocol, peer, req, batch.extraProtocols()...)
+	return f.meteredRequest(f.batchContext(batch), hashProtocol, peer, req, batch.extraProtocols()...)
 }
 
 // handleHashError is called when an error occurred processing batches of the following hashes.
@@ -942,6 +1079,9 @@ func (f *Fetch) getHash(
 	if has, err := f.bs.Has(h, hash.Bytes()); err == nil && has {
 		return nil, nil
 	}
+	if f.pusher != nil {
+		f.pusher.request(h)
+	}
 
 	f.mu.Lock()
 	defer f.mu.Unlock()
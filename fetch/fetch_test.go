@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoremem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -33,6 +35,8 @@ type testFetch struct {
 	mHashS  *mocks.Mockrequester
 	mMHashS *mocks.Mockrequester
 	mOpn2S  *mocks.Mockrequester
+	mLayerS *mocks.Mockrequester
+	ps      peerstore.Peerstore
 
 	mMalH        *mocks.MockSyncValidator
 	mAtxH        *mocks.MockSyncValidator
@@ -56,6 +60,7 @@ func createFetch(tb testing.TB) *testFetch {
 		mHashS:       mocks.NewMockrequester(ctrl),
 		mMHashS:      mocks.NewMockrequester(ctrl),
 		mOpn2S:       mocks.NewMockrequester(ctrl),
+		mLayerS:      mocks.NewMockrequester(ctrl),
 		mMalH:        mocks.NewMockSyncValidator(ctrl),
 		mAtxH:        mocks.NewMockSyncValidator(ctrl),
 		mBallotH:     mocks.NewMockSyncValidator(ctrl),
@@ -66,9 +71,14 @@ func createFetch(tb testing.TB) *testFetch {
 		mTxProposalH: mocks.NewMockSyncValidator(ctrl),
 		mPoetH:       mocks.NewMockSyncValidator(ctrl),
 	}
-	for _, srv := range []*mocks.Mockrequester{tf.mMalS, tf.mAtxS, tf.mLyrS, tf.mHashS, tf.mMHashS, tf.mOpn2S} {
+	for _, srv := range []*mocks.Mockrequester{tf.mMalS, tf.mAtxS, tf.mLyrS, tf.mHashS, tf.mMHashS, tf.mOpn2S, tf.mLayerS} {
 		srv.EXPECT().Run(gomock.Any()).AnyTimes()
 	}
+	ps, err := pstoremem.NewPeerstore()
+	require.NoError(tb, err)
+	tb.Cleanup(func() { ps.Close() })
+	tf.ps = ps
+	tf.mh.EXPECT().Peerstore().Return(ps).AnyTimes()
 	cfg := Config{
 		BatchTimeout:         2 * time.Second, // make sure we never hit the batch timeout
 		BatchSize:            3,
@@ -91,6 +101,7 @@ func createFetch(tb testing.TB) *testFetch {
 			hashProtocol:     tf.mHashS,
 			meshHashProtocol: tf.mMHashS,
 			OpnProtocol:      tf.mOpn2S,
+			layerProtocol:    tf.mLayerS,
 		}),
 		withHost(tf.mh))
 	tf.Fetch.SetValidators(
@@ -233,6 +244,39 @@ func TestFetch_RequestHashBatchFromPeers(t *testing.T) {
 	}
 }
 
+func TestFetch_RequestHashBatchPriority(t *testing.T) {
+	f := createFetch(t)
+	f.cfg.MaxRetriesForRequest = 0
+	peer := p2p.Peer("buddy")
+	f.peers.Add(peer)
+
+	hshPriority := types.RandomHash()
+	hsh := types.RandomHash()
+	f.mHashS.EXPECT().
+		Request(gomock.Any(), peer, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ p2p.Peer, req []byte, _ ...string) ([]byte, error) {
+			var rb RequestBatch
+			require.NoError(t, codec.Decode(req, &rb))
+			require.Len(t, rb.Requests, 1)
+			require.Equal(t, rb.Requests[0].Hash == hshPriority, server.IsPriority(ctx))
+			return codec.MustEncode(&ResponseBatch{
+				ID:        rb.ID,
+				Responses: []ResponseMessage{{Hash: rb.Requests[0].Hash, Data: []byte("a")}},
+			}), nil
+		}).Times(2)
+
+	p0, err := f.getHash(server.WithPriority(context.Background()), hshPriority, datastore.ProposalDB, goodReceiver)
+	require.NoError(t, err)
+	p1, err := f.getHash(context.Background(), hsh, datastore.ProposalDB, goodReceiver)
+	require.NoError(t, err)
+
+	f.requestHashBatchFromPeers()
+	for _, p := range []*promise{p0, p1} {
+		<-p.completed
+		require.NoError(t, p.err)
+	}
+}
+
 func TestFetch_GetHash_StartStopSanity(t *testing.T) {
 	f := createFetch(t)
 	require.NoError(t, f.Start())
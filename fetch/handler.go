@@ -30,6 +30,8 @@ type handler struct {
 	logger *zap.Logger
 	cdb    *datastore.CachedDB
 	bs     *datastore.BlobStore
+	// served is notified about hash requests of peers, nil if the push is disabled.
+	served func(context.Context, datastore.Hint)
 }
 
 func newHandler(
@@ -286,6 +288,9 @@ func (h *handler) doHandleHashReq(ctx context.Context, data []byte, hint datasto
 			return nil, fmt.Errorf("bad hint: %q (expected %q)", r.Hint, hint)
 		}
 		totalHashReqs.WithLabelValues(string(r.Hint)).Add(1)
+		if h.served != nil {
+			h.served(ctx, r.Hint)
+		}
 		var blob sql.Blob
 		if err := h.bs.LoadBlob(ctx, r.Hint, r.Hash.Bytes(), &blob); err != nil {
 			if !errors.Is(err, datastore.ErrNotFound) {
@@ -356,6 +361,9 @@ func (h *handler) doHandleHashReqStream(
 			return fmt.Errorf("bad hint: %q (expected %q)", r.Hint, hint)
 		}
 		idsByHint[r.Hint] = append(idsByHint[r.Hint], r.Hash.Bytes())
+		if h.served != nil {
+			h.served(ctx, r.Hint)
+		}
 	}
 
 	totalSize := uint32(types.Hash32Length)
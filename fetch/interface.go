@@ -3,6 +3,8 @@ package fetch
 import (
 	"context"
 
+	"github.com/libp2p/go-libp2p/core/peerstore"
+
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/p2p/pubsub"
@@ -44,4 +46,5 @@ type PoetValidator interface {
 
 type host interface {
 	ID() p2p.Peer
+	Peerstore() peerstore.Peerstore
 }
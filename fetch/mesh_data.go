@@ -257,14 +257,14 @@ func (f *Fetch) GetMaliciousIDs(ctx context.Context, peer p2p.Peer) ([]types.Nod
 // GetLayerData get layer data from peers.
 func (f *Fetch) GetLayerData(ctx context.Context, peer p2p.Peer, lid types.LayerID) ([]byte, error) {
 	lidBytes := codec.MustEncode(&lid)
-	return f.meteredRequest(ctx, lyrDataProtocol, peer, lidBytes)
+	return f.layerRequest(ctx, lyrDataProtocol, peer, lidBytes)
 }
 
 func (f *Fetch) GetLayerOpinions(ctx context.Context, peer p2p.Peer, lid types.LayerID) ([]byte, error) {
 	reqData := codec.MustEncode(&OpinionRequest{
 		Layer: lid,
 	})
-	return f.meteredRequest(ctx, OpnProtocol, peer, reqData)
+	return f.layerRequest(ctx, OpnProtocol, peer, reqData)
 }
 
 func (f *Fetch) peerEpochInfoStreamed(ctx context.Context, peer p2p.Peer, epochBytes []byte) (*EpochData, error) {
@@ -372,7 +372,7 @@ func (f *Fetch) GetCert(
 	reqData := codec.MustEncode(req)
 
 	for _, peer := range peers {
-		data, err := f.meteredRequest(ctx, OpnProtocol, peer, reqData)
+		data, err := f.layerRequest(ctx, OpnProtocol, peer, reqData)
 		if err != nil {
 			f.logger.With().Debug("failed to get cert", zap.Stringer("peer", peer), zap.Error(err))
 			continue
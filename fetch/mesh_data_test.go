@@ -681,6 +681,17 @@ func TestFetch_GetLayerOpinions(t *testing.T) {
 		require.ErrorIs(t, err, errUnknown)
 		require.Nil(t, res)
 	})
+	t.Run("layer protocol", func(t *testing.T) {
+		t.Parallel()
+		f := createFetch(t)
+		require.NoError(t, f.ps.AddProtocols("p0", layerProtocol))
+		expected := generateLayerContent(t)
+		req := server.RoutedRequest(opnKind, codec.MustEncode(&OpinionRequest{Layer: 7}))
+		f.mLayerS.EXPECT().Request(gomock.Any(), p2p.Peer("p0"), req).Return(expected, nil)
+		res, err := f.GetLayerOpinions(context.Background(), "p0", 7)
+		require.NoError(t, err)
+		require.Equal(t, expected, res)
+	})
 }
 
 func TestFetch_GetLayerData(t *testing.T) {
@@ -702,6 +713,18 @@ func TestFetch_GetLayerData(t *testing.T) {
 		require.ErrorIs(t, err, errUnknown)
 		require.Nil(t, res)
 	})
+	t.Run("layer protocol", func(t *testing.T) {
+		t.Parallel()
+		f := createFetch(t)
+		require.NoError(t, f.ps.AddProtocols("p0", layerProtocol))
+		expected := generateLayerContent(t)
+		lid := types.LayerID(7)
+		req := server.RoutedRequest(lyrDataKind, codec.MustEncode(&lid))
+		f.mLayerS.EXPECT().Request(gomock.Any(), p2p.Peer("p0"), req).Return(expected, nil)
+		res, err := f.GetLayerData(context.Background(), "p0", lid)
+		require.NoError(t, err)
+		require.Equal(t, expected, res)
+	})
 }
 
 func generateEpochData(t *testing.T) (*EpochData, []byte) {
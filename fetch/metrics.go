@@ -66,6 +66,18 @@ var (
 		"total layer opinion requests received",
 		[]string{"version"},
 	).WithLabelValues("v2")
+
+	pushesSent = metrics.NewCounter(
+		"pushes_sent",
+		subsystem,
+		"total objects pushed to peers that recently requested objects of the same kind",
+		[]string{hint, "outcome"})
+
+	pushesReceived = metrics.NewCounter(
+		"pushes_received",
+		subsystem,
+		"total objects pushed by peers",
+		[]string{hint, "outcome"})
 )
 
 // logCacheHit logs cache hit.
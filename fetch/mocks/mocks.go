@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	peerstore "github.com/libp2p/go-libp2p/core/peerstore"
 	types "github.com/spacemeshos/go-spacemesh/common/types"
 	p2p "github.com/spacemeshos/go-spacemesh/p2p"
 	server "github.com/spacemeshos/go-spacemesh/p2p/server"
@@ -349,3 +350,41 @@ func (c *MockhostIDCall) DoAndReturn(f func() p2p.Peer) *MockhostIDCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Peerstore mocks base method.
+func (m *Mockhost) Peerstore() peerstore.Peerstore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peerstore")
+	ret0, _ := ret[0].(peerstore.Peerstore)
+	return ret0
+}
+
+// Peerstore indicates an expected call of Peerstore.
+func (mr *MockhostMockRecorder) Peerstore() *MockhostPeerstoreCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peerstore", reflect.TypeOf((*Mockhost)(nil).Peerstore))
+	return &MockhostPeerstoreCall{Call: call}
+}
+
+// MockhostPeerstoreCall wrap *gomock.Call
+type MockhostPeerstoreCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockhostPeerstoreCall) Return(arg0 peerstore.Peerstore) *MockhostPeerstoreCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockhostPeerstoreCall) Do(f func() peerstore.Peerstore) *MockhostPeerstoreCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockhostPeerstoreCall) DoAndReturn(f func() peerstore.Peerstore) *MockhostPeerstoreCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -322,6 +322,33 @@ func TestP2PPeerMeshHashes(t *testing.T) {
 		})
 }
 
+func TestP2PLayerRequests(t *testing.T) {
+	tpf, ctx := createP2PFetch(t, false, false, false)
+	// the protocols of the server are learned by identify after connecting
+	require.Eventually(t, func() bool {
+		supported, err := tpf.clientFetch.host.Peerstore().SupportsProtocols(tpf.serverID, layerProtocol)
+		return err == nil && len(supported) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	data, err := tpf.clientFetch.GetLayerData(ctx, tpf.serverID, 7)
+	require.NoError(t, err)
+	var ld LayerData
+	require.NoError(t, codec.Decode(data, &ld))
+	require.Empty(t, ld.Ballots)
+
+	data, err = tpf.clientFetch.GetLayerOpinions(ctx, tpf.serverID, 7)
+	require.NoError(t, err)
+	var lo LayerOpinion
+	require.NoError(t, codec.Decode(data, &lo))
+	require.Nil(t, lo.Certified)
+
+	// both requests are served by the queue and limiter of the layer protocol
+	servers := tpf.serverFetch.servers
+	require.Equal(t, 2, servers[layerProtocol].(*server.Server).NumAcceptedRequests())
+	require.Zero(t, servers[lyrDataProtocol].(*server.Server).NumAcceptedRequests())
+	require.Zero(t, servers[OpnProtocol].(*server.Server).NumAcceptedRequests())
+}
+
 func TestP2PMaliciousIDs(t *testing.T) {
 	forStreaming(
 		t, "database closed", false,
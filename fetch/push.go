@@ -0,0 +1,253 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/datastore"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/p2p/server"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+const (
+	pushProtocol = "ps/1"
+
+	// maxPushSize is the maximal size of a pushed object, larger objects are left to gossip and fetch.
+	maxPushSize = 1 << 20
+)
+
+var (
+	errPushRejected = errors.New("push not accepted")
+	// pushAck is the response to an accepted push, as the server doesn't allow empty responses.
+	pushAck = []byte{1}
+)
+
+// PushConfig configures the push of newly arrived objects to peers that recently requested objects
+// with the same hint, which saves such peers a round of gossip and fetch.
+type PushConfig struct {
+	// Enable pushes objects to peers and accepts objects pushed by peers.
+	Enable bool             `mapstructure:"enable"`
+	Hints  []datastore.Hint `mapstructure:"hints"`
+	// TTL is for how long objects are pushed to a peer after it requested an object with the same hint.
+	TTL time.Duration `mapstructure:"ttl"`
+	// Window is for how long pushed objects are accepted after the node requested an object with the same hint.
+	Window time.Duration `mapstructure:"window"`
+	// Peers is the maximal number of peers a single object is pushed to.
+	Peers int `mapstructure:"peers"`
+	// Queue is the maximal number of pushes waiting to be sent. Pushes over it are dropped.
+	Queue int `mapstructure:"queue"`
+}
+
+// DefaultPushConfig returns the default push config, the push is disabled.
+func DefaultPushConfig() PushConfig {
+	return PushConfig{
+		Hints:  []datastore.Hint{datastore.ATXDB},
+		TTL:    10 * time.Minute,
+		Window: 10 * time.Minute,
+		Peers:  8,
+		Queue:  256,
+	}
+}
+
+type push struct {
+	peer p2p.Peer
+	msg  []byte
+	hint datastore.Hint
+}
+
+type pusher struct {
+	logger *zap.Logger
+	cfg    PushConfig
+	queue  chan push
+
+	mu sync.Mutex
+	// requesters are peers that requested objects with the hint and when they did it last.
+	requesters map[datastore.Hint]map[p2p.Peer]time.Time
+	// requested is when the node requested an object with the hint last.
+	requested map[datastore.Hint]time.Time
+}
+
+func newPusher(logger *zap.Logger, cfg PushConfig) *pusher {
+	return &pusher{
+		logger:     logger,
+		cfg:        cfg,
+		queue:      make(chan push, cfg.Queue),
+		requesters: make(map[datastore.Hint]map[p2p.Peer]time.Time),
+		requested:  make(map[datastore.Hint]time.Time),
+	}
+}
+
+func (p *pusher) enabled(hint datastore.Hint) bool {
+	for _, enabled := range p.cfg.Hints {
+		if enabled == hint {
+			return true
+		}
+	}
+	return false
+}
+
+// served records that the peer of the request requested an object with the hint.
+func (p *pusher) served(ctx context.Context, hint datastore.Hint) {
+	peer, ok := server.PeerFromContext(ctx)
+	if !ok || !p.enabled(hint) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.requesters[hint] == nil {
+		p.requesters[hint] = make(map[p2p.Peer]time.Time)
+	}
+	p.requesters[hint][peer] = time.Now()
+}
+
+// request records that the node requested an object with the hint.
+func (p *pusher) request(hint datastore.Hint) {
+	if !p.enabled(hint) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requested[hint] = time.Now()
+}
+
+func (p *pusher) accepts(hint datastore.Hint) bool {
+	if !p.enabled(hint) {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	requested, ok := p.requested[hint]
+	return ok && time.Since(requested) <= p.cfg.Window
+}
+
+// targets returns up to cfg.Peers peers that requested objects with the hint within the TTL.
+func (p *pusher) targets(hint datastore.Hint, except p2p.Peer) []p2p.Peer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var rst []p2p.Peer
+	for peer, requested := range p.requesters[hint] {
+		if time.Since(requested) > p.cfg.TTL {
+			delete(p.requesters[hint], peer)
+			continue
+		}
+		if peer != except && len(rst) < p.cfg.Peers {
+			rst = append(rst, peer)
+		}
+	}
+	return rst
+}
+
+// Push sends the object to peers that recently requested objects with the same hint. The object is
+// loaded from the local database, it's a no-op if the push is disabled for the hint.
+// The peer the object was received from, if any, is skipped.
+func (f *Fetch) Push(ctx context.Context, hint datastore.Hint, hash types.Hash32, from p2p.Peer) {
+	if f.pusher == nil || !f.pusher.enabled(hint) {
+		return
+	}
+	targets := f.pusher.targets(hint, from)
+	if len(targets) == 0 {
+		return
+	}
+	var blob sql.Blob
+	if err := f.bs.LoadBlob(ctx, hint, hash.Bytes(), &blob); err != nil {
+		f.logger.Debug("failed to load object to push",
+			log.ZContext(ctx),
+			zap.String("hint", string(hint)),
+			zap.Stringer("hash", hash),
+			zap.Error(err),
+		)
+		return
+	}
+	if len(blob.Bytes) == 0 || len(blob.Bytes) > maxPushSize {
+		return
+	}
+	msg := codec.MustEncode(&PushMessage{Hint: hint, Hash: hash, Data: blob.Bytes})
+	for _, peer := range targets {
+		select {
+		case f.pusher.queue <- push{peer: peer, msg: msg, hint: hint}:
+		default:
+			pushesSent.WithLabelValues(string(hint), "dropped").Inc()
+		}
+	}
+}
+
+func (f *Fetch) runPusher(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case push := <-f.pusher.queue:
+			if _, err := f.servers[pushProtocol].Request(ctx, push.peer, push.msg); err != nil {
+				pushesSent.WithLabelValues(string(push.hint), "failed").Inc()
+				f.logger.Debug("failed to push object",
+					zap.Stringer("peer", push.peer),
+					zap.String("hint", string(push.hint)),
+					zap.Error(err),
+				)
+				continue
+			}
+			pushesSent.WithLabelValues(string(push.hint), "sent").Inc()
+		}
+	}
+}
+
+// pushValidator returns the validator for the objects that can be pushed, nil for other hints.
+func (f *Fetch) pushValidator(hint datastore.Hint) SyncValidator {
+	if f.validators == nil {
+		return nil
+	}
+	switch hint {
+	case datastore.ATXDB:
+		return f.validators.atx
+	case datastore.POETDB:
+		return f.validators.poet
+	case datastore.BallotDB:
+		return f.validators.ballot
+	case datastore.BlockDB:
+		return f.validators.block
+	case datastore.ProposalDB:
+		return f.validators.proposal
+	case datastore.Malfeasance:
+		return f.validators.malfeasance
+	}
+	return nil
+}
+
+// handlePush validates and stores an object pushed by a peer. Objects are accepted only within
+// the window after the node requested objects with the same hint.
+func (f *Fetch) handlePush(ctx context.Context, data []byte) ([]byte, error) {
+	var msg PushMessage
+	if err := codec.Decode(data, &msg); err != nil {
+		return nil, fmt.Errorf("%w: decoding push: %w", errBadRequest, err)
+	}
+	validator := f.pushValidator(msg.Hint)
+	if validator == nil {
+		// the hint is set by the peer, don't use it as a label
+		pushesReceived.WithLabelValues("unknown", "rejected").Inc()
+		return nil, errPushRejected
+	}
+	if !f.pusher.accepts(msg.Hint) {
+		pushesReceived.WithLabelValues(string(msg.Hint), "rejected").Inc()
+		return nil, errPushRejected
+	}
+	if has, err := f.bs.Has(msg.Hint, msg.Hash.Bytes()); err == nil && has {
+		pushesReceived.WithLabelValues(string(msg.Hint), "duplicate").Inc()
+		return pushAck, nil
+	}
+	peer, _ := server.PeerFromContext(ctx)
+	if err := validator.HandleMessage(ctx, msg.Hash, peer, msg.Data); err != nil {
+		pushesReceived.WithLabelValues(string(msg.Hint), "invalid").Inc()
+		return nil, fmt.Errorf("validate pushed %s: %w", msg.Hash, err)
+	}
+	pushesReceived.WithLabelValues(string(msg.Hint), "accepted").Inc()
+	return pushAck, nil
+}
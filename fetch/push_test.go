@@ -0,0 +1,145 @@
+package fetch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/datastore"
+	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/p2p/server"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
+)
+
+func createPushFetch(t *testing.T, cfg PushConfig) *testFetch {
+	tf := createFetch(t)
+	tf.pusher = newPusher(zaptest.NewLogger(t), cfg)
+	return tf
+}
+
+func pushCtx(peer p2p.Peer) context.Context {
+	return server.ContextWithPeer(context.Background(), peer)
+}
+
+func TestPusher_Targets(t *testing.T) {
+	cfg := DefaultPushConfig()
+	cfg.TTL = time.Minute
+	cfg.Peers = 2
+	p := newPusher(zaptest.NewLogger(t), cfg)
+
+	p.served(context.Background(), datastore.ATXDB) // no peer in the context
+	p.served(pushCtx("a"), datastore.BallotDB)      // push is not enabled for the hint
+	require.Empty(t, p.targets(datastore.ATXDB, p2p.NoPeer))
+	require.Empty(t, p.targets(datastore.BallotDB, p2p.NoPeer))
+
+	for _, peer := range []p2p.Peer{"a", "b", "c"} {
+		p.served(pushCtx(peer), datastore.ATXDB)
+	}
+	require.Len(t, p.targets(datastore.ATXDB, p2p.NoPeer), 2)
+	require.NotContains(t, p.targets(datastore.ATXDB, "a"), p2p.Peer("a"))
+
+	p.requesters[datastore.ATXDB]["a"] = time.Now().Add(-2 * cfg.TTL)
+	p.requesters[datastore.ATXDB]["b"] = time.Now().Add(-2 * cfg.TTL)
+	require.Equal(t, []p2p.Peer{"c"}, p.targets(datastore.ATXDB, p2p.NoPeer))
+	require.Len(t, p.requesters[datastore.ATXDB], 1)
+}
+
+func TestPusher_Accepts(t *testing.T) {
+	cfg := DefaultPushConfig()
+	cfg.Window = time.Minute
+	p := newPusher(zaptest.NewLogger(t), cfg)
+
+	require.False(t, p.accepts(datastore.ATXDB))
+	p.request(datastore.ATXDB)
+	p.request(datastore.BallotDB)
+	require.True(t, p.accepts(datastore.ATXDB))
+	require.False(t, p.accepts(datastore.BallotDB))
+
+	p.requested[datastore.ATXDB] = time.Now().Add(-2 * cfg.Window)
+	require.False(t, p.accepts(datastore.ATXDB))
+}
+
+func TestFetch_Push(t *testing.T) {
+	cfg := DefaultPushConfig()
+	cfg.Hints = []datastore.Hint{datastore.POETDB}
+	cfg.Queue = 1
+	tf := createPushFetch(t, cfg)
+
+	ref := types.PoetProofRef{1}
+	proof := []byte("proof")
+	require.NoError(t, poets.Add(tf.bs.DB, ref, proof, []byte("service"), "1"))
+
+	// nobody requested poet proofs
+	tf.Push(context.Background(), datastore.POETDB, types.Hash32(ref), p2p.NoPeer)
+	require.Empty(t, tf.pusher.queue)
+
+	tf.pusher.served(pushCtx("a"), datastore.POETDB)
+	tf.pusher.served(pushCtx("b"), datastore.POETDB)
+	// the peer the proof was received from is skipped
+	tf.Push(context.Background(), datastore.POETDB, types.Hash32(ref), "b")
+	require.Len(t, tf.pusher.queue, 1)
+	queued := <-tf.pusher.queue
+	require.Equal(t, p2p.Peer("a"), queued.peer)
+	var msg PushMessage
+	require.NoError(t, codec.Decode(queued.msg, &msg))
+	require.Equal(t, PushMessage{Hint: datastore.POETDB, Hash: types.Hash32(ref), Data: proof}, msg)
+
+	// pushes over the queue are dropped
+	tf.Push(context.Background(), datastore.POETDB, types.Hash32(ref), p2p.NoPeer)
+	require.Len(t, tf.pusher.queue, 1)
+}
+
+func TestFetch_HandlePush(t *testing.T) {
+	cfg := DefaultPushConfig()
+	cfg.Hints = []datastore.Hint{datastore.POETDB}
+	tf := createPushFetch(t, cfg)
+
+	ref := types.PoetProofRef{1}
+	msg := codec.MustEncode(&PushMessage{Hint: datastore.POETDB, Hash: types.Hash32(ref), Data: []byte("proof")})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := tf.handlePush(pushCtx("a"), []byte{1, 2, 3})
+		require.ErrorIs(t, err, errBadRequest)
+	})
+	t.Run("not requested", func(t *testing.T) {
+		_, err := tf.handlePush(pushCtx("a"), msg)
+		require.ErrorIs(t, err, errPushRejected)
+	})
+	t.Run("unknown hint", func(t *testing.T) {
+		tf.pusher.request(datastore.POETDB)
+		unknown := codec.MustEncode(&PushMessage{Hint: "unknown", Data: []byte("data")})
+		_, err := tf.handlePush(pushCtx("a"), unknown)
+		require.ErrorIs(t, err, errPushRejected)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		tf.pusher.request(datastore.POETDB)
+		tf.mPoetH.EXPECT().
+			HandleMessage(gomock.Any(), types.Hash32(ref), p2p.Peer("a"), []byte("proof")).
+			Return(errBadRequest)
+		_, err := tf.handlePush(pushCtx("a"), msg)
+		require.Error(t, err)
+	})
+	t.Run("accepted", func(t *testing.T) {
+		tf.pusher.request(datastore.POETDB)
+		tf.mPoetH.EXPECT().
+			HandleMessage(gomock.Any(), types.Hash32(ref), p2p.Peer("a"), []byte("proof")).
+			DoAndReturn(func(context.Context, types.Hash32, p2p.Peer, []byte) error {
+				return poets.Add(tf.bs.DB, ref, []byte("proof"), []byte("service"), "1")
+			})
+		resp, err := tf.handlePush(pushCtx("a"), msg)
+		require.NoError(t, err)
+		require.Equal(t, pushAck, resp)
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		tf.pusher.request(datastore.POETDB)
+		resp, err := tf.handlePush(pushCtx("b"), msg)
+		require.NoError(t, err)
+		require.Equal(t, pushAck, resp)
+	})
+}
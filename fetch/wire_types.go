@@ -40,6 +40,14 @@ type ResponseMessage struct {
 	Data []byte `scale:"max=272629760"` // 260 MiB > 8.0 mio ATX * 32 bytes per ID
 }
 
+// PushMessage is an object pushed to a peer that recently requested objects with the same hint.
+type PushMessage struct {
+	Hint datastore.Hint `scale:"max=256"`
+	Hash types.Hash32
+	// keep in line with maxPushSize
+	Data []byte `scale:"max=1048576"`
+}
+
 // RequestBatch is a batch of requests and a hash of all requests as ID.
 type RequestBatch struct {
 	ID types.Hash32
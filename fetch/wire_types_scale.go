@@ -376,3 +376,55 @@ func (t *LayerOpinion) DecodeScale(dec *scale.Decoder) (total int, err error) {
 	}
 	return total, nil
 }
+
+func (t *PushMessage) EncodeScale(enc *scale.Encoder) (total int, err error) {
+	{
+		n, err := scale.EncodeStringWithLimit(enc, string(t.Hint), 256)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	{
+		n, err := scale.EncodeByteArray(enc, t.Hash[:])
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	{
+		n, err := scale.EncodeByteSliceWithLimit(enc, t.Data, 1048576)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (t *PushMessage) DecodeScale(dec *scale.Decoder) (total int, err error) {
+	{
+		field, n, err := scale.DecodeStringWithLimit(dec, 256)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.Hint = datastore.Hint(field)
+	}
+	{
+		n, err := scale.DecodeByteArray(dec, t.Hash[:])
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	{
+		field, n, err := scale.DecodeByteSliceWithLimit(dec, 1048576)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.Data = field
+	}
+	return total, nil
+}
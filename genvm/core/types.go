@@ -56,6 +56,14 @@ type Handler interface {
 	Load([]byte) (Template, error)
 }
 
+// SpendingEstimator is an optional interface for a Handler of the template with gas rules
+// that don't match the default estimate of spending (MaxSpend + MaxGas * GasPrice).
+// It is consulted by the mempool to check that the transaction is feasible.
+type SpendingEstimator interface {
+	// MaxSpending returns the maximal amount that a transaction with the header can spend.
+	MaxSpending(*Header) uint64
+}
+
 //go:generate mockgen -typed -package=mocks -destination=./mocks/template.go github.com/spacemeshos/go-spacemesh/genvm/core Template
 
 // Template is a concrete Template type initialized with mutable and immutable state.
@@ -171,6 +171,21 @@ func (v *VM) GetBalance(address types.Address) (uint64, error) {
 	return account.Balance, nil
 }
 
+// MaxSpending returns the maximal amount that a transaction with the header can spend,
+// if the handler of its template implements core.SpendingEstimator.
+// Otherwise it returns false and the default estimate should be used.
+func (v *VM) MaxSpending(header *types.TxHeader) (uint64, bool) {
+	handler := v.registry.Get(header.TemplateAddress)
+	if handler == nil {
+		return 0, false
+	}
+	estimator, ok := handler.(core.SpendingEstimator)
+	if !ok {
+		return 0, false
+	}
+	return estimator.MaxSpending(header), true
+}
+
 // ApplyGenesis saves list of accounts for genesis.
 func (v *VM) ApplyGenesis(genesis []types.Account) error {
 	tx, err := v.db.Tx(context.Background())
@@ -475,6 +490,22 @@ func (r *Request) Verify() bool {
 	return rst
 }
 
+// VerifyGenesis verifies transaction as if it was signed for the network with the genesis id.
+// Will panic if called without Parse completing successfully.
+func (r *Request) VerifyGenesis(genesis types.Hash20) bool {
+	if r.ctx == nil {
+		panic("VerifyGenesis should be called after successful Parse")
+	}
+	cfg := r.vm.cfg
+	cfg.GenesisID = genesis
+	decoder := scale.NewDecoder(bytes.NewReader(r.raw.Raw))
+	_, ctx, _, err := parse(r.vm.logger, r.lid, r.vm.registry, r.cache, cfg, r.raw.Raw, decoder)
+	if err != nil {
+		return false
+	}
+	return verify(ctx, r.raw.Raw, decoder)
+}
+
 func parse(
 	logger *zap.Logger,
 	lid types.LayerID,
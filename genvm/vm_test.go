@@ -1570,6 +1570,7 @@ func testValidation(t *testing.T, tt *tester, template core.Address) {
 		header   *core.Header
 		err      error
 		verified bool
+		foreign  bool
 	}{
 		{
 			desc: "Spawn",
@@ -1584,8 +1585,9 @@ func testValidation(t *testing.T, tt *tester, template core.Address) {
 			verified: true,
 		},
 		{
-			desc: "SpawnGenesisIdMismatch",
-			tx:   tt.selfSpawn(1, sdk.WithGenesisID(types.Hash20{1})),
+			desc:    "SpawnGenesisIdMismatch",
+			tx:      tt.selfSpawn(1, sdk.WithGenesisID(types.Hash20{1})),
+			foreign: true,
 		},
 		{
 			desc: "Spend",
@@ -1602,8 +1604,9 @@ func testValidation(t *testing.T, tt *tester, template core.Address) {
 			verified: true,
 		},
 		{
-			desc: "SpendGenesisIdMismatch",
-			tx:   tt.spend(0, 1, 100, sdk.WithGenesisID(types.Hash20{1})),
+			desc:    "SpendGenesisIdMismatch",
+			tx:      tt.spend(0, 1, 100, sdk.WithGenesisID(types.Hash20{1})),
+			foreign: true,
 		},
 		{
 			desc: "WrongVersion",
@@ -1653,6 +1656,7 @@ func testValidation(t *testing.T, tt *tester, template core.Address) {
 				require.ErrorIs(t, err, tc.err)
 			} else {
 				require.Equal(t, tc.verified, req.Verify())
+				require.Equal(t, tc.foreign, req.VerifyGenesis(types.Hash20{1}))
 				if tc.verified {
 					require.Equal(t, tc.header, header)
 				}
@@ -2731,3 +2735,32 @@ func TestMain(m *testing.M) {
 	types.SetLayersPerEpoch(2)
 	os.Exit(m.Run())
 }
+
+// estimatingHandler is the wallet template with a custom estimate of the maximal spending.
+type estimatingHandler struct {
+	core.Handler
+	spending uint64
+}
+
+func (h *estimatingHandler) MaxSpending(*core.Header) uint64 {
+	return h.spending
+}
+
+func TestMaxSpending(t *testing.T) {
+	tt := newTester(t)
+	estimating := core.Address{1}
+	tt.registry.Register(estimating, &estimatingHandler{
+		Handler:  tt.registry.Get(wallet.TemplateAddress),
+		spending: 7,
+	})
+
+	spending, ok := tt.MaxSpending(&types.TxHeader{TemplateAddress: estimating, MaxSpend: 100})
+	require.True(t, ok)
+	require.EqualValues(t, 7, spending)
+
+	// templates without an estimator use the default estimate
+	_, ok = tt.MaxSpending(&types.TxHeader{TemplateAddress: wallet.TemplateAddress, MaxSpend: 100})
+	require.False(t, ok)
+	_, ok = tt.MaxSpending(&types.TxHeader{TemplateAddress: core.Address{2}, MaxSpend: 100})
+	require.False(t, ok)
+}
@@ -0,0 +1,134 @@
+package hare3
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/haremsgs"
+)
+
+// ArchiveConfig configures the archive of validated hare messages.
+type ArchiveConfig struct {
+	// Layers is the number of the most recent layers for which messages are kept.
+	// Zero disables the archive.
+	Layers uint32 `mapstructure:"layers"`
+}
+
+const (
+	// archiveQueueSize is the number of messages that are buffered before they are written to the database.
+	// Messages received while the queue is full are not archived, so that validation is never blocked.
+	archiveQueueSize = 4096
+	// archiveBatchSize is the max number of messages written to the database in one transaction.
+	archiveBatchSize = 256
+)
+
+// Archive persists validated hare messages of the recent layers in the local database,
+// so that they can be inspected after the fact.
+type Archive struct {
+	logger *zap.Logger
+	config ArchiveConfig
+	db     sql.LocalDatabase
+	queue  chan *haremsgs.Message
+}
+
+func newArchive(logger *zap.Logger, config ArchiveConfig, db sql.LocalDatabase) *Archive {
+	return &Archive{
+		logger: logger,
+		config: config,
+		db:     db,
+		queue:  make(chan *haremsgs.Message, archiveQueueSize),
+	}
+}
+
+// add queues the message to be written by run. It doesn't block, as it is called in the validation path.
+func (a *Archive) add(msg *Message, hash types.Hash32, raw []byte, received time.Time) {
+	select {
+	case a.queue <- &haremsgs.Message{
+		Layer:    msg.Layer,
+		Iter:     msg.Iter,
+		Round:    uint8(msg.Round),
+		Sender:   msg.Sender,
+		ID:       hash,
+		Received: received,
+		Blob:     raw,
+	}:
+	default:
+		archiveDropped.Inc()
+		a.logger.Debug("archive queue is full, message is not archived", zap.Inline(msg))
+	}
+}
+
+// run writes queued messages to the database in batches until the context is canceled.
+// Messages that are queued when the context is canceled are written before it returns.
+func (a *Archive) run(ctx context.Context) error {
+	batch := make([]*haremsgs.Message, 0, archiveBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for batch = a.drain(batch[:0]); len(batch) > 0; batch = a.drain(batch[:0]) {
+				a.write(batch)
+			}
+			return nil
+		case msg := <-a.queue:
+			a.write(a.drain(append(batch[:0], msg)))
+		}
+	}
+}
+
+// drain appends queued messages to the batch, up to archiveBatchSize, without blocking.
+func (a *Archive) drain(batch []*haremsgs.Message) []*haremsgs.Message {
+	for len(batch) < archiveBatchSize {
+		select {
+		case msg := <-a.queue:
+			batch = append(batch, msg)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (a *Archive) write(batch []*haremsgs.Message) {
+	if err := a.db.WithTx(context.Background(), func(tx sql.Transaction) error {
+		for _, msg := range batch {
+			if err := haremsgs.Add(tx, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		a.logger.Warn("failed to archive messages", zap.Int("count", len(batch)), zap.Error(err))
+	}
+}
+
+func (a *Archive) onLayer(lid types.LayerID) {
+	if lid.Uint32() <= a.config.Layers {
+		return
+	}
+	if err := haremsgs.DeleteBefore(a.db, lid.Sub(a.config.Layers)); err != nil {
+		a.logger.Warn("failed to prune archived messages", zap.Uint32("lid", lid.Uint32()), zap.Error(err))
+	}
+}
+
+// Messages returns archived messages that match the filter.
+func (a *Archive) Messages(filter haremsgs.Filter) ([]*Message, error) {
+	archived, err := haremsgs.Get(a.db, filter)
+	if err != nil {
+		return nil, err
+	}
+	rst := make([]*Message, 0, len(archived))
+	for _, archived := range archived {
+		msg := &Message{}
+		if err := codec.Decode(archived.Blob, msg); err != nil {
+			return nil, fmt.Errorf("decode archived message %s: %w", archived.ID.ShortString(), err)
+		}
+		rst = append(rst, msg)
+	}
+	return rst, nil
+}
@@ -0,0 +1,93 @@
+package hare3
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/haremsgs"
+)
+
+func TestArchive(t *testing.T) {
+	t.Parallel()
+	tst := &tester{
+		TB:            t,
+		rng:           rand.New(rand.NewSource(1001)),
+		start:         time.Now(),
+		cfg:           DefaultConfig(),
+		layerDuration: 5 * time.Minute,
+		beacon:        types.Beacon{1, 1, 1, 1},
+		genesis:       types.GetEffectiveGenesis(),
+	}
+	tst.cfg.Archive.Layers = 2
+	cluster := newLockstepCluster(tst).addActive(2)
+
+	layer := tst.genesis + 1
+	cluster.setup()
+	cluster.genProposals(layer)
+	cluster.movePreround(layer)
+	for i := 0; i < 2*int(notify); i++ {
+		cluster.moveRound()
+	}
+	cluster.waitStopped()
+
+	archive := cluster.nodes[0].hare.Archive()
+	require.NotNil(t, archive)
+
+	// messages are written asynchronously
+	iter, round := uint8(0), uint8(preround)
+	for _, n := range cluster.nodes {
+		sender := n.signer.NodeID()
+		var msgs []*Message
+		require.Eventually(t, func() bool {
+			var err error
+			msgs, err = archive.Messages(haremsgs.Filter{Layer: layer, Iter: &iter, Round: &round, Sender: &sender})
+			require.NoError(t, err)
+			return len(msgs) > 0
+		}, time.Second, 10*time.Millisecond)
+		require.Len(t, msgs, 1)
+		require.Equal(t, sender, msgs[0].Sender)
+		require.Equal(t, preround, msgs[0].Round)
+		require.Equal(t, layer, msgs[0].Layer)
+	}
+
+	archive.onLayer(layer + 2)
+	msgs, err := archive.Messages(haremsgs.Filter{Layer: layer})
+	require.NoError(t, err)
+	require.NotEmpty(t, msgs)
+
+	archive.onLayer(layer + 3)
+	msgs, err = archive.Messages(haremsgs.Filter{Layer: layer})
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+}
+
+func TestArchiveDisabled(t *testing.T) {
+	hr := New(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.Nil(t, hr.Archive())
+}
+
+func TestArchiveQueue(t *testing.T) {
+	archive := newArchive(zaptest.NewLogger(t), ArchiveConfig{Layers: 1}, localsql.InMemoryTest(t))
+	layer := types.LayerID(10)
+	for i := range archiveQueueSize + 1 {
+		msg := &Message{Body: Body{Layer: layer, IterRound: IterRound{Round: preround}}}
+		msg.Sender = types.RandomNodeID()
+		archive.add(msg, types.Hash32{byte(i), byte(i >> 8)}, codec.MustEncode(msg), time.Now())
+	}
+
+	// queued messages are written when the archive stops, the message that overflowed the queue is dropped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, archive.run(ctx))
+	msgs, err := archive.Messages(haremsgs.Filter{Layer: layer})
+	require.NoError(t, err)
+	require.Len(t, msgs, archiveQueueSize)
+}
@@ -0,0 +1,253 @@
+package hare3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/exp/maps"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/hare4"
+	"github.com/spacemeshos/go-spacemesh/proposals/store"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/blocks"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+)
+
+// auditPendingLayers bounds the number of hare outputs that wait for tortoise verification.
+const auditPendingLayers = 1000
+
+// AuditConfig configures the auditor that cross-checks hare output against tortoise.
+type AuditConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Threshold is the fraction of eligibilities that may disagree between hare output
+	// and the block verified by tortoise before divergence is reported.
+	Threshold float64 `mapstructure:"threshold"`
+	// Samples is the number of the most recent divergent layers kept in memory for debugging.
+	Samples int `mapstructure:"samples"`
+}
+
+func DefaultAuditConfig() AuditConfig {
+	return AuditConfig{
+		Threshold: 0,
+		Samples:   100,
+	}
+}
+
+// DivergenceSample describes a layer where hare output diverged from tortoise.
+type DivergenceSample struct {
+	Layer     types.LayerID
+	Proposals []types.ProposalID
+	// Unresolved are proposals from hare output that were not available locally
+	// and were excluded from the comparison.
+	Unresolved []types.ProposalID
+	HareAtxs   []types.ATXID
+	Block      types.BlockID
+	BlockAtxs  []types.ATXID
+	Divergence float64
+}
+
+func (s *DivergenceSample) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddUint32("lid", s.Layer.Uint32())
+	encoder.AddString("block", s.Block.String())
+	encoder.AddFloat64("divergence", s.Divergence)
+	encoder.AddInt("proposals", len(s.Proposals))
+	encoder.AddInt("unresolved", len(s.Unresolved))
+	encoder.AddArray("hare atxs", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, id := range s.HareAtxs {
+			enc.AppendString(id.ShortString())
+		}
+		return nil
+	}))
+	encoder.AddArray("block atxs", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, id := range s.BlockAtxs {
+			enc.AppendString(id.ShortString())
+		}
+		return nil
+	}))
+	return nil
+}
+
+type hareOutput struct {
+	proposals  []types.ProposalID
+	atxs       map[types.ATXID]struct{}
+	unresolved []types.ProposalID
+}
+
+// Auditor compares hare output for each layer with the block that was applied
+// once the layer was verified by tortoise.
+//
+// Eligibilities are compared by the atx of the proposal, as the block doesn't reference
+// proposals but rewards every atx that had a proposal in hare output.
+type Auditor struct {
+	logger    *zap.Logger
+	config    AuditConfig
+	db        sql.Executor
+	proposals *store.Store
+
+	mu      sync.Mutex
+	pending map[types.LayerID]*hareOutput
+	samples []DivergenceSample
+}
+
+func newAuditor(logger *zap.Logger, config AuditConfig, db sql.Executor, proposals *store.Store) *Auditor {
+	return &Auditor{
+		logger:    logger,
+		config:    config,
+		db:        db,
+		proposals: proposals,
+		pending:   map[types.LayerID]*hareOutput{},
+	}
+}
+
+// Samples returns the most recent divergent layers, oldest first.
+func (a *Auditor) Samples() []DivergenceSample {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]DivergenceSample(nil), a.samples...)
+}
+
+// onOutput is called concurrently by hare sessions of different layers.
+func (a *Auditor) onOutput(out hare4.ConsensusOutput) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	output := &hareOutput{
+		proposals: out.Proposals,
+		atxs:      make(map[types.ATXID]struct{}, len(out.Proposals)),
+	}
+	for _, id := range out.Proposals {
+		if p := a.proposals.Get(out.Layer, id); p != nil {
+			output.atxs[p.AtxID] = struct{}{}
+		} else {
+			output.unresolved = append(output.unresolved, id)
+		}
+	}
+	a.pending[out.Layer] = output
+	for lid := range a.pending {
+		if lid+auditPendingLayers < out.Layer {
+			delete(a.pending, lid)
+		}
+	}
+}
+
+func (a *Auditor) onVerified(lid types.LayerID) error {
+	a.mu.Lock()
+	output, exists := a.pending[lid]
+	delete(a.pending, lid)
+	a.mu.Unlock()
+	if !exists {
+		return nil
+	}
+	bid, err := layers.GetApplied(a.db, lid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("get applied %d: %w", lid, err)
+	}
+	block := map[types.ATXID]struct{}{}
+	if bid != types.EmptyBlockID {
+		b, err := blocks.Get(a.db, bid)
+		if err != nil {
+			return fmt.Errorf("get block %s: %w", bid, err)
+		}
+		for _, reward := range b.Rewards {
+			block[reward.AtxID] = struct{}{}
+		}
+	}
+	var unresolved []types.ProposalID
+	for _, id := range output.unresolved {
+		// proposal may have been fetched by block generator after hare terminated
+		if p := a.proposals.Get(lid, id); p != nil {
+			output.atxs[p.AtxID] = struct{}{}
+		} else {
+			unresolved = append(unresolved, id)
+		}
+	}
+
+	divergence := symmetricDivergence(output.atxs, block)
+	auditDivergence.Observe(divergence)
+	if divergence <= a.config.Threshold {
+		return nil
+	}
+	auditDivergentLayers.Inc()
+	sample := DivergenceSample{
+		Layer:      lid,
+		Proposals:  output.proposals,
+		Unresolved: unresolved,
+		HareAtxs:   maps.Keys(output.atxs),
+		Block:      bid,
+		BlockAtxs:  maps.Keys(block),
+		Divergence: divergence,
+	}
+	a.logger.Warn("hare output diverged from tortoise", zap.Inline(&sample))
+	events.ReportHareDivergence(events.EventHareDivergence{
+		Layer:      lid,
+		Proposals:  output.proposals,
+		Block:      bid,
+		Divergence: divergence,
+	})
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.samples = append(a.samples, sample)
+	if len(a.samples) > a.config.Samples {
+		a.samples = a.samples[len(a.samples)-a.config.Samples:]
+	}
+	return nil
+}
+
+// run audits layers as they are verified by tortoise until ctx is canceled.
+func (a *Auditor) run(ctx context.Context) error {
+	sub, err := events.SubscribeLayers()
+	if err != nil {
+		return fmt.Errorf("subscribe to layers: %w", err)
+	}
+	if sub == nil {
+		a.logger.Warn("events reporter is not initialized, hare audit is disabled")
+		return nil
+	}
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, open := <-sub.Out():
+			if !open {
+				return nil
+			}
+			update, ok := ev.(events.LayerUpdate)
+			if !ok || update.Status != events.LayerStatusTypeApplied {
+				continue
+			}
+			if err := a.onVerified(update.LayerID); err != nil {
+				a.logger.Warn("failed to audit hare output",
+					zap.Uint32("lid", update.LayerID.Uint32()),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// symmetricDivergence returns the size of the symmetric difference relative to the size of the union.
+func symmetricDivergence(hare, block map[types.ATXID]struct{}) float64 {
+	union := len(block)
+	differ := len(block)
+	for id := range hare {
+		if _, ok := block[id]; ok {
+			differ--
+		} else {
+			union++
+			differ++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(differ) / float64(union)
+}
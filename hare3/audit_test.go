@@ -0,0 +1,221 @@
+package hare3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/hare4"
+	"github.com/spacemeshos/go-spacemesh/proposals/store"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/blocks"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestSymmetricDivergence(t *testing.T) {
+	set := func(ids ...types.ATXID) map[types.ATXID]struct{} {
+		rst := map[types.ATXID]struct{}{}
+		for _, id := range ids {
+			rst[id] = struct{}{}
+		}
+		return rst
+	}
+	a, b, c, d := types.ATXID{1}, types.ATXID{2}, types.ATXID{3}, types.ATXID{4}
+	for _, tc := range []struct {
+		desc        string
+		hare, block map[types.ATXID]struct{}
+		expect      float64
+	}{
+		{"empty", set(), set(), 0},
+		{"same", set(a, b), set(a, b), 0},
+		{"empty block", set(a, b), set(), 1},
+		{"empty hare", set(), set(a), 1},
+		{"disjoint", set(a, b), set(c, d), 1},
+		{"partial", set(a, b, c), set(b, c, d), 0.5},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expect, symmetricDivergence(tc.hare, tc.block))
+		})
+	}
+}
+
+type auditTester struct {
+	*Auditor
+	t         *testing.T
+	db        sql.StateDatabase
+	proposals *store.Store
+}
+
+func newAuditTester(t *testing.T, config AuditConfig) *auditTester {
+	db := statesql.InMemoryTest(t)
+	proposals := store.New()
+	return &auditTester{
+		Auditor:   newAuditor(zaptest.NewLogger(t), config, db, proposals),
+		t:         t,
+		db:        db,
+		proposals: proposals,
+	}
+}
+
+func (at *auditTester) proposal(lid types.LayerID) *types.Proposal {
+	p := &types.Proposal{}
+	p.Layer = lid
+	p.AtxID = types.RandomATXID()
+	p.SetID(types.RandomProposalID())
+	require.NoError(at.t, at.proposals.Add(p))
+	return p
+}
+
+func (at *auditTester) apply(lid types.LayerID, atxs ...types.ATXID) types.BlockID {
+	bid := types.EmptyBlockID
+	if len(atxs) > 0 {
+		block := &types.Block{InnerBlock: types.InnerBlock{LayerIndex: lid}}
+		for _, id := range atxs {
+			block.Rewards = append(block.Rewards, types.AnyReward{AtxID: id})
+		}
+		block.Initialize()
+		require.NoError(at.t, blocks.Add(at.db, block))
+		bid = block.ID()
+	}
+	require.NoError(at.t, layers.SetApplied(at.db, lid, bid))
+	return bid
+}
+
+func TestAuditor(t *testing.T) {
+	lid := types.LayerID(10)
+	t.Run("consistent", func(t *testing.T) {
+		at := newAuditTester(t, DefaultAuditConfig())
+		p1, p2 := at.proposal(lid), at.proposal(lid)
+		at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{p1.ID(), p2.ID()}})
+		at.apply(lid, p1.AtxID, p2.AtxID)
+		require.NoError(t, at.onVerified(lid))
+		require.Empty(t, at.Samples())
+		require.Empty(t, at.pending)
+	})
+	t.Run("diverged", func(t *testing.T) {
+		at := newAuditTester(t, DefaultAuditConfig())
+		p1, p2 := at.proposal(lid), at.proposal(lid)
+		at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{p1.ID(), p2.ID()}})
+		other := types.RandomATXID()
+		bid := at.apply(lid, p1.AtxID, other)
+		require.NoError(t, at.onVerified(lid))
+		samples := at.Samples()
+		require.Len(t, samples, 1)
+		require.Equal(t, lid, samples[0].Layer)
+		require.Equal(t, bid, samples[0].Block)
+		require.InDelta(t, 2.0/3, samples[0].Divergence, 1e-9)
+		require.ElementsMatch(t, []types.ATXID{p1.AtxID, p2.AtxID}, samples[0].HareAtxs)
+		require.ElementsMatch(t, []types.ATXID{p1.AtxID, other}, samples[0].BlockAtxs)
+	})
+	t.Run("below threshold", func(t *testing.T) {
+		at := newAuditTester(t, AuditConfig{Threshold: 0.5, Samples: 10})
+		p1, p2, p3 := at.proposal(lid), at.proposal(lid), at.proposal(lid)
+		at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{p1.ID(), p2.ID(), p3.ID()}})
+		at.apply(lid, p1.AtxID, p2.AtxID)
+		require.NoError(t, at.onVerified(lid))
+		require.Empty(t, at.Samples())
+	})
+	t.Run("empty block", func(t *testing.T) {
+		at := newAuditTester(t, DefaultAuditConfig())
+		p1 := at.proposal(lid)
+		at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{p1.ID()}})
+		at.apply(lid)
+		require.NoError(t, at.onVerified(lid))
+		samples := at.Samples()
+		require.Len(t, samples, 1)
+		require.Equal(t, types.EmptyBlockID, samples[0].Block)
+		require.Equal(t, 1.0, samples[0].Divergence)
+	})
+	t.Run("unresolved", func(t *testing.T) {
+		at := newAuditTester(t, DefaultAuditConfig())
+		p1 := at.proposal(lid)
+		missing := types.RandomProposalID()
+		at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{p1.ID(), missing}})
+		at.apply(lid, p1.AtxID)
+		require.NoError(t, at.onVerified(lid))
+		require.Empty(t, at.Samples())
+	})
+	t.Run("samples bounded", func(t *testing.T) {
+		at := newAuditTester(t, AuditConfig{Samples: 2})
+		for i := 0; i < 3; i++ {
+			lid := lid + types.LayerID(i)
+			at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{at.proposal(lid).ID()}})
+			at.apply(lid)
+			require.NoError(t, at.onVerified(lid))
+		}
+		samples := at.Samples()
+		require.Len(t, samples, 2)
+		require.Equal(t, lid+1, samples[0].Layer)
+		require.Equal(t, lid+2, samples[1].Layer)
+	})
+	t.Run("not applied", func(t *testing.T) {
+		at := newAuditTester(t, DefaultAuditConfig())
+		at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{at.proposal(lid).ID()}})
+		require.NoError(t, at.onVerified(lid))
+		require.Empty(t, at.Samples())
+	})
+}
+
+func TestAuditor_Run(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	divergences := events.SubscribeHareDivergence()
+
+	at := newAuditTester(t, DefaultAuditConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- at.run(ctx) }()
+
+	lid := types.LayerID(10)
+	p1 := at.proposal(lid)
+	at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{p1.ID()}})
+	at.apply(lid)
+	// subscription is created asynchronously, keep reporting until the auditor picks it up
+	update := events.LayerUpdate{LayerID: lid, Status: events.LayerStatusTypeApplied}
+	require.Eventually(t, func() bool {
+		return events.ReportLayerUpdate(update) == nil && len(at.Samples()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case ev := <-divergences.Out():
+		divergence := ev.(events.EventHareDivergence)
+		require.Equal(t, lid, divergence.Layer)
+		require.Equal(t, []types.ProposalID{p1.ID()}, divergence.Proposals)
+		require.Equal(t, 1.0, divergence.Divergence)
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for divergence event")
+	}
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestAuditor_Concurrent(t *testing.T) {
+	at := newAuditTester(t, DefaultAuditConfig())
+	const layers = 50
+	var eg errgroup.Group
+	for i := 1; i <= layers; i++ {
+		lid := types.LayerID(i)
+		p := at.proposal(lid)
+		at.apply(lid)
+		eg.Go(func() error {
+			at.onOutput(hare4.ConsensusOutput{Layer: lid, Proposals: []types.ProposalID{p.ID()}})
+			return nil
+		})
+		eg.Go(func() error {
+			return at.onVerified(lid)
+		})
+	}
+	require.NoError(t, eg.Wait())
+	for i := 1; i <= layers; i++ {
+		require.NoError(t, at.onVerified(types.LayerID(i)))
+	}
+	require.Len(t, at.Samples(), min(layers, at.config.Samples))
+	require.Empty(t, at.pending)
+}
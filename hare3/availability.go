@@ -0,0 +1,87 @@
+package hare3
+
+import (
+	"context"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// prefetch starts fetching proposals that were gossiped in the session but are no longer
+// available in the proposals store. It doesn't wait for the fetch, so that messages of the
+// node are never delayed by it. Proposals fetched by the commit round are checked by checkAvailable.
+func (h *Hare) prefetch(session *session, ids []types.ProposalID) {
+	if h.fetcher == nil {
+		return
+	}
+	missing := h.missingProposals(session.lid, ids)
+	if len(missing) == 0 {
+		return
+	}
+	h.log.Debug("fetching unavailable proposals",
+		zap.Uint32("lid", session.lid.Uint32()),
+		zap.Int("missing", len(missing)),
+	)
+	h.eg.Go(func() error {
+		// proposals are needed before the commit round of the iteration
+		ctx, cancel := context.WithTimeout(session.ctx, 3*h.config.RoundDuration)
+		defer cancel()
+		if err := h.fetcher.GetProposals(ctx, missing); err != nil {
+			refetchFailed.Inc()
+			h.log.Debug("failed to fetch proposals", zap.Uint32("lid", session.lid.Uint32()), zap.Error(err))
+			return nil
+		}
+		refetchSucceeded.Inc()
+		return nil
+	})
+}
+
+// checkAvailable verifies that proposals referenced by the commit message are still available
+// in the proposals store, so that the node doesn't commit to a set it can't execute later.
+// If some of them are missing the commit message is not sent, and fetching them is started
+// for the following iterations.
+//
+// Without fetcher missing proposals are only reported, as they are expected to be fetched
+// after hare terminates.
+func (h *Hare) checkAvailable(session *session, out *output) {
+	if !slices.ContainsFunc(session.vrfs, func(vrf *types.HareEligibility) bool { return vrf != nil }) {
+		return
+	}
+	ref := out.message.Value.Reference
+	values, exist := session.proto.ValidProposals(*ref)
+	if !exist {
+		return
+	}
+	missing := h.missingProposals(session.lid, values)
+	if len(missing) == 0 {
+		return
+	}
+	h.log.Debug("proposals in commit are not available",
+		zap.Uint32("lid", session.lid.Uint32()),
+		zap.Stringer("ref", ref),
+		zap.Int("missing", len(missing)),
+	)
+	if h.fetcher == nil {
+		return
+	}
+	h.log.Warn("not committing to a set with unavailable proposals",
+		zap.Uint32("lid", session.lid.Uint32()),
+		zap.Stringer("ref", ref),
+		zap.Int("missing", len(missing)),
+	)
+	commitSkipped.Inc()
+	out.message = nil
+	h.prefetch(session, missing)
+}
+
+func (h *Hare) missingProposals(lid types.LayerID, ids []types.ProposalID) []types.ProposalID {
+	var missing []types.ProposalID
+	for _, id := range ids {
+		if h.proposals.Get(lid, id) == nil {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,95 @@
+package hare3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/proposals/store"
+	smocks "github.com/spacemeshos/go-spacemesh/system/mocks"
+)
+
+func TestHare_CheckAvailable(t *testing.T) {
+	t.Parallel()
+	const lid = types.LayerID(10)
+	beacon := types.RandomBeacon()
+	available := gproposal(types.RandomProposalID(), types.RandomATXID(), types.RandomNodeID(), lid, beacon)
+	missing := gproposal(types.RandomProposalID(), types.RandomATXID(), types.RandomNodeID(), lid, beacon)
+	values := []types.ProposalID{available.ID(), missing.ID()}
+	ref := toHash(values)
+
+	setup := func(t *testing.T, opts ...Opt) (*Hare, *session, *output) {
+		proposals := store.New()
+		require.NoError(t, proposals.Add(available))
+		opts = append(opts, WithLogger(zaptest.NewLogger(t)))
+		hare := New(nil, nil, nil, nil, proposals, nil, nil, nil, nil, opts...)
+		proto := newProtocol(1)
+		proto.validProposals[ref] = values
+		s := &session{ctx: context.Background(), lid: lid, proto: proto, vrfs: []*types.HareEligibility{{}}}
+		out := &output{message: &Message{Body: Body{
+			IterRound: IterRound{Round: commit},
+			Value:     Value{Reference: &ref},
+		}}}
+		return hare, s, out
+	}
+
+	t.Run("without fetcher", func(t *testing.T) {
+		t.Parallel()
+		hare, s, out := setup(t)
+		hare.checkAvailable(s, out)
+		require.NotNil(t, out.message)
+	})
+	t.Run("available", func(t *testing.T) {
+		t.Parallel()
+		fetcher := smocks.NewMockProposalFetcher(gomock.NewController(t))
+		hare, s, out := setup(t, WithProposalFetcher(fetcher))
+		require.NoError(t, hare.OnProposal(missing))
+		hare.checkAvailable(s, out)
+		require.NotNil(t, out.message)
+	})
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		fetcher := smocks.NewMockProposalFetcher(gomock.NewController(t))
+		hare, s, out := setup(t, WithProposalFetcher(fetcher))
+		unblock := make(chan struct{})
+		fetched := make(chan struct{})
+		fetcher.EXPECT().GetProposals(gomock.Any(), []types.ProposalID{missing.ID()}).DoAndReturn(
+			func(context.Context, []types.ProposalID) error {
+				defer close(fetched)
+				<-unblock
+				return errors.New("test")
+			})
+		// the commit message is dropped without waiting for the fetch
+		hare.checkAvailable(s, out)
+		require.Nil(t, out.message)
+		close(unblock)
+		<-fetched
+	})
+	t.Run("prefetch", func(t *testing.T) {
+		t.Parallel()
+		fetcher := smocks.NewMockProposalFetcher(gomock.NewController(t))
+		hare, s, _ := setup(t, WithProposalFetcher(fetcher))
+		fetched := make(chan struct{})
+		fetcher.EXPECT().GetProposals(gomock.Any(), []types.ProposalID{missing.ID()}).DoAndReturn(
+			func(context.Context, []types.ProposalID) error {
+				defer close(fetched)
+				return hare.OnProposal(missing)
+			})
+		hare.prefetch(s, values)
+		<-fetched
+		require.Empty(t, hare.missingProposals(lid, values))
+	})
+	t.Run("not eligible", func(t *testing.T) {
+		t.Parallel()
+		fetcher := smocks.NewMockProposalFetcher(gomock.NewController(t))
+		hare, s, out := setup(t, WithProposalFetcher(fetcher))
+		s.vrfs = []*types.HareEligibility{nil}
+		hare.checkAvailable(s, out)
+		require.NotNil(t, out.message)
+	})
+}
@@ -0,0 +1,126 @@
+package hare3
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/p2p/server"
+)
+
+// ConfigProtocol serves the hash of the hare config of the node, see Config.Hash.
+const ConfigProtocol = "hc/1"
+
+const configExchangeTimeout = 10 * time.Second
+
+type configHost interface {
+	server.Host
+	EventBus() event.Bus
+}
+
+// ConfigExchange compares the hash of the hare config with every peer that serves ConfigProtocol
+// once the peer is identified. Nodes with a different config split consensus silently, so a peer
+// with a different hash is logged and counted.
+type ConfigExchange struct {
+	logger *zap.Logger
+	h      configHost
+	hash   types.Hash32
+	srv    *server.Server
+}
+
+// NewConfigExchange registers the ConfigProtocol handler on the host.
+func NewConfigExchange(logger *zap.Logger, h configHost, hash types.Hash32) *ConfigExchange {
+	c := &ConfigExchange{
+		logger: logger,
+		h:      h,
+		hash:   hash,
+	}
+	c.srv = server.New(h, ConfigProtocol,
+		server.WrapHandler(func(context.Context, []byte) ([]byte, error) {
+			return c.hash.Bytes(), nil
+		}),
+		server.WithLog(logger),
+		server.WithTimeout(configExchangeTimeout),
+		server.WithHardTimeout(configExchangeTimeout),
+	)
+	return c
+}
+
+// Run serves the hash and checks identified peers until the context is canceled.
+// A peer is checked once while it is connected.
+func (c *ConfigExchange) Run(ctx context.Context) error {
+	sub, err := c.h.EventBus().Subscribe([]any{
+		new(event.EvtPeerIdentificationCompleted),
+		new(event.EvtPeerConnectednessChanged),
+	})
+	if err != nil {
+		return fmt.Errorf("subscribe to peer events: %w", err)
+	}
+	defer sub.Close()
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return c.srv.Run(ctx)
+	})
+	defer eg.Wait()
+	checked := make(map[peer.ID]struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-sub.Out():
+			switch ev := ev.(type) {
+			case event.EvtPeerConnectednessChanged:
+				if ev.Connectedness == network.NotConnected {
+					delete(checked, ev.Peer)
+				}
+			case event.EvtPeerIdentificationCompleted:
+				// peers running older versions don't serve the protocol
+				if !slices.Contains(ev.Protocols, ConfigProtocol) {
+					continue
+				}
+				// identification completes for every connection to the peer
+				if _, exist := checked[ev.Peer]; exist {
+					continue
+				}
+				checked[ev.Peer] = struct{}{}
+				eg.Go(func() error {
+					if err := c.Check(ctx, ev.Peer); err != nil && ctx.Err() == nil {
+						c.logger.Debug("hare config check failed", zap.Stringer("peer", ev.Peer), zap.Error(err))
+					}
+					return nil
+				})
+			}
+		}
+	}
+}
+
+// Check requests the hash of the hare config of the peer and compares it with the local one.
+func (c *ConfigExchange) Check(ctx context.Context, pid peer.ID) error {
+	ctx, cancel := context.WithTimeout(ctx, configExchangeTimeout)
+	defer cancel()
+	resp, err := c.srv.Request(ctx, pid, nil)
+	if err != nil {
+		return err
+	}
+	if len(resp) != len(c.hash) {
+		return fmt.Errorf("invalid hare config hash size %d", len(resp))
+	}
+	remote := types.BytesToHash(resp)
+	if remote != c.hash {
+		configMismatches.Inc()
+		c.logger.Warn("peer has a different hare config",
+			zap.Stringer("peer", pid),
+			zap.String("local", c.hash.ShortString()),
+			zap.String("remote", remote.ShortString()),
+		)
+	}
+	return nil
+}
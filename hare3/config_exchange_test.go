@@ -0,0 +1,61 @@
+package hare3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
+	"github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
+)
+
+type configTestHost struct {
+	host.Host
+}
+
+func (configTestHost) PeerInfo() peerinfo.PeerInfo { return nil }
+
+func TestConfigExchange(t *testing.T) {
+	// hosts are connected after the exchanges are running, so that identification is observed
+	mesh, err := mocknet.FullMeshLinked(3)
+	require.NoError(t, err)
+
+	elig := eligibility.DefaultConfig()
+	cfg := DefaultConfig()
+	upgraded := DefaultConfig()
+	upgraded.Committee++
+	// the second node is upgraded, the others are not
+	hashes := []types.Hash32{cfg.Hash(&elig), upgraded.Hash(&elig), cfg.Hash(&elig)}
+	require.NotEqual(t, hashes[0], hashes[1])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, eg.Wait())
+	})
+	exchanges := make([]*ConfigExchange, len(hashes))
+	for i, h := range mesh.Hosts() {
+		exchanges[i] = NewConfigExchange(zaptest.NewLogger(t), configTestHost{h}, hashes[i])
+		eg.Go(func() error { return exchanges[i].Run(ctx) })
+	}
+
+	before := testutil.ToFloat64(configMismatches)
+	require.NoError(t, mesh.ConnectAllButSelf())
+	// the upgraded node and both of its peers report each other
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(configMismatches) == before+4
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, exchanges[0].Check(ctx, mesh.Hosts()[2].ID()))
+	require.NoError(t, exchanges[0].Check(ctx, mesh.Hosts()[1].ID()))
+	require.Equal(t, before+5, testutil.ToFloat64(configMismatches))
+}
@@ -14,13 +14,20 @@ type activeSetCache interface {
 	Get(key types.EpochID) (value *cachedActiveSet, ok bool)
 }
 
+type layerClock interface {
+	AwaitLayer(types.LayerID) <-chan struct{}
+	CurrentLayer() types.LayerID
+}
+
 type vrfVerifier interface {
 	Verify(nodeID types.NodeID, msg []byte, sig types.VrfSignature) bool
 }
 
-// Rolacle is the roles oracle provider.
+// Rolacle is the roles oracle provider. Oracle is the default implementation, hare and the certifier
+// depend on this interface only, so that alternative implementations can be plugged in.
 type Rolacle interface {
 	Validate(context.Context, types.LayerID, uint32, int, types.NodeID, types.VrfSignature, uint16) (bool, error)
 	CalcEligibility(context.Context, types.LayerID, uint32, int, types.NodeID, types.VrfSignature) (uint16, error)
 	Proof(context.Context, *signing.VRFSigner, types.LayerID, uint32) (types.VrfSignature, error)
+	ActiveSet(context.Context, types.EpochID) ([]types.ATXID, error)
 }
@@ -0,0 +1,19 @@
+package eligibility
+
+import (
+	"github.com/spacemeshos/go-spacemesh/metrics"
+)
+
+const namespace = "eligibility"
+
+var shadowDecisions = metrics.NewCounter(
+	"shadow_decisions",
+	namespace,
+	"decisions of the shadow oracle compared with the authoritative one",
+	[]string{"method", "outcome"},
+)
+
+const (
+	shadowAgree    = "agree"
+	shadowDisagree = "disagree"
+)
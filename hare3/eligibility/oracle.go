@@ -5,13 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/bits"
+	"strconv"
 	"sync"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/spacemeshos/fixed"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/exp/maps"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/spacemeshos/go-spacemesh/atxsdata"
 	"github.com/spacemeshos/go-spacemesh/codec"
@@ -33,6 +37,11 @@ const (
 const (
 	activesCacheSize = 5                       // we don't expect to handle more than two layers concurrently
 	maxSupportedN    = (math.MaxInt32 / 2) + 1 // higher values result in an overflow when calculating CDF
+	// activesTimeout bounds the computation of the active set, that is shared by concurrent callers.
+	activesTimeout = time.Minute
+
+	// WeightCapScale is the denominator of WeightCap.PerMillion.
+	WeightCapScale = 1_000_000
 )
 
 var (
@@ -60,6 +69,50 @@ func (c *cachedActiveSet) atxs() []types.ATXID {
 	return atxs
 }
 
+// ConfidenceUpgrade changes ConfidenceParam starting from the epoch that begins with Layer.
+type ConfidenceUpgrade struct {
+	Layer types.LayerID `mapstructure:"layer"`
+	Param uint32        `mapstructure:"param"`
+}
+
+// WeightCap limits the weight of a single identity to a fraction of the total weight of the active set,
+// so that extremely large identities can't dominate hare committees.
+type WeightCap struct {
+	// Layer is the first layer where the cap is applied. It must be the first layer of an epoch.
+	// The cap is applied to active sets of epochs that start at or after this layer.
+	Layer types.LayerID `mapstructure:"layer"`
+	// PerMillion is the largest fraction of the total weight, in millionths, that is counted
+	// for a single identity. Zero disables the cap.
+	PerMillion uint32 `mapstructure:"per-million"`
+}
+
+// enabled returns true if the cap applies to the active set of the epoch.
+func (c WeightCap) enabled(epoch types.EpochID) bool {
+	return c.PerMillion > 0 && epoch.FirstLayer() >= c.Layer
+}
+
+// apply caps weights of identities in place and returns the total of the capped weights.
+// The cap is computed from the total of the uncapped weights using integer arithmetic only,
+// so that all nodes compute the same weights.
+func (c WeightCap) apply(identities map[types.NodeID]identityWeight) uint64 {
+	var total uint64
+	for _, identity := range identities {
+		total += identity.weight
+	}
+	hi, lo := bits.Mul64(total, uint64(c.PerMillion))
+	limit, _ := bits.Div64(hi, lo, WeightCapScale)
+	limit = max(limit, 1)
+	total = 0
+	for id, identity := range identities {
+		if identity.weight > limit {
+			identity.weight = limit
+			identities[id] = identity
+		}
+		total += identity.weight
+	}
+	return total
+}
+
 // Config is the configuration of the oracle package.
 type Config struct {
 	// ConfidenceParam specifies how many layers into the epoch hare uses active set generated in the previous epoch.
@@ -69,10 +122,77 @@ type Config struct {
 	// This was done like that so that we have higher `confidence` that hare will succeed atleast
 	// once during this interval. If it doesn't we have to provide centralized fallback.
 	ConfidenceParam uint32 `mapstructure:"eligibility-confidence-param"`
+	// ConfidenceUpgrades is a schedule of changes to ConfidenceParam, ordered by layer.
+	// Every layer in the schedule must be the first layer of an epoch, so that all nodes
+	// use the same param for the whole epoch.
+	ConfidenceUpgrades []ConfidenceUpgrade `mapstructure:"eligibility-confidence-upgrades"`
+	// WeightCap limits the weight of a single identity when computing eligibilities.
+	WeightCap WeightCap `mapstructure:"eligibility-weight-cap"`
+	// WarmupLayers is the number of layers before the oracle switches to the active set of the next epoch
+	// when the active set is computed in the background, so that the first layer using it doesn't
+	// pay the cost of the computation. Zero disables the warm-up.
+	WarmupLayers uint32 `mapstructure:"eligibility-warmup-layers"`
+}
+
+// ConfidenceParamFor returns the confidence param that is used in the epoch of the layer.
+func (c *Config) ConfidenceParamFor(layer types.LayerID) uint32 {
+	param := c.ConfidenceParam
+	for _, upgrade := range c.ConfidenceUpgrades {
+		if layer < upgrade.Layer {
+			break
+		}
+		param = upgrade.Param
+	}
+	return param
+}
+
+// Validate checks that every confidence param is smaller than layers per epoch
+// and that upgrades are ordered and aligned with epoch boundaries.
+func (c *Config) Validate(layersPerEpoch uint32) error {
+	// we can't have an epoch offset which is greater/equal than the number of layers in an epoch
+	if c.ConfidenceParam >= layersPerEpoch {
+		return fmt.Errorf(
+			"confidence param should be smaller than layers per epoch. eligibility-confidence-param: %d. "+
+				"layers-per-epoch: %d",
+			c.ConfidenceParam,
+			layersPerEpoch,
+		)
+	}
+	for i, upgrade := range c.ConfidenceUpgrades {
+		if upgrade.Param >= layersPerEpoch {
+			return fmt.Errorf("confidence upgrade at layer %d: param %d should be smaller than layers per epoch %d",
+				upgrade.Layer, upgrade.Param, layersPerEpoch)
+		}
+		if upgrade.Layer.Uint32()%layersPerEpoch != 0 {
+			return fmt.Errorf("confidence upgrade at layer %d: layer is not the first layer of an epoch", upgrade.Layer)
+		}
+		if i > 0 && upgrade.Layer <= c.ConfidenceUpgrades[i-1].Layer {
+			return fmt.Errorf("confidence upgrade at layer %d: layer is not after previous upgrade at layer %d",
+				upgrade.Layer, c.ConfidenceUpgrades[i-1].Layer)
+		}
+	}
+	if c.WarmupLayers >= layersPerEpoch {
+		return fmt.Errorf("warmup layers %d should be smaller than layers per epoch %d", c.WarmupLayers, layersPerEpoch)
+	}
+	if c.WeightCap.PerMillion > WeightCapScale {
+		return fmt.Errorf("weight cap %d should not be larger than %d", c.WeightCap.PerMillion, WeightCapScale)
+	}
+	if c.WeightCap.PerMillion > 0 && c.WeightCap.Layer.Uint32()%layersPerEpoch != 0 {
+		return fmt.Errorf("weight cap at layer %d: layer is not the first layer of an epoch", c.WeightCap.Layer)
+	}
+	return nil
 }
 
 func (c *Config) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	encoder.AddUint32("confidence param", c.ConfidenceParam)
+	for _, upgrade := range c.ConfidenceUpgrades {
+		encoder.AddUint32(fmt.Sprintf("confidence param from layer %d", upgrade.Layer), upgrade.Param)
+	}
+	if c.WeightCap.PerMillion > 0 {
+		encoder.AddUint32(fmt.Sprintf("weight cap per million from layer %d", c.WeightCap.Layer),
+			c.WeightCap.PerMillion)
+	}
+	encoder.AddUint32("warmup layers", c.WarmupLayers)
 	return nil
 }
 
@@ -81,6 +201,8 @@ func DefaultConfig() Config {
 	return Config{ConfidenceParam: 1}
 }
 
+var _ Rolacle = &Oracle{}
+
 // Oracle is the hare eligibility oracle.
 type Oracle struct {
 	mu           sync.Mutex
@@ -91,6 +213,16 @@ type Oracle struct {
 	// to cope with https://github.com/spacemeshos/go-spacemesh/issues/4552
 	// until graded oracle is implemented
 	synced bool
+	// pinned keeps active sets for the two most recent target epochs, so that around the
+	// epoch boundary lookups for layers on either side are not affected by lru evictions.
+	pinned map[types.EpochID]*cachedActiveSet
+	// generation is incremented whenever the cache is reset, so that active sets computed
+	// before the reset are not added to the new cache.
+	generation uint64
+	// inflight deduplicates concurrent computations of the active set for the same epoch.
+	inflight singleflight.Group
+	// warmedUp is the latest epoch which active set was precomputed. Accessed only by RunWarmup.
+	warmedUp types.EpochID
 
 	beacons        system.BeaconGetter
 	atxsdata       *atxsdata.Data
@@ -135,6 +267,7 @@ func New(
 		vrfVerifier:    vrfVerifier,
 		layersPerEpoch: layersPerEpoch,
 		activesCache:   activesCache,
+		pinned:         map[types.EpochID]*cachedActiveSet{},
 		fallback:       map[types.EpochID][]types.ATXID{},
 		cfg:            DefaultConfig(),
 		log:            zap.NewNop(),
@@ -174,6 +307,23 @@ func (o *Oracle) resetCacheOnSynced(ctx context.Context) {
 			o.log.Fatal("failed to create lru cache for active set", zap.Error(err))
 		}
 		o.activesCache = ac
+		o.pinned = map[types.EpochID]*cachedActiveSet{}
+		o.generation++
+	}
+}
+
+// pin keeps the active set for the epoch and for the preceding epoch, if it is pinned already.
+// Active sets for older epochs are unpinned.
+func (o *Oracle) pin(epoch types.EpochID, aset *cachedActiveSet) {
+	o.pinned[epoch] = aset
+	latest := epoch
+	for pinned := range o.pinned {
+		latest = max(latest, pinned)
+	}
+	for pinned := range o.pinned {
+		if pinned+1 < latest {
+			delete(o.pinned, pinned)
+		}
 	}
 }
 
@@ -207,6 +357,53 @@ func (o *Oracle) minerWeight(ctx context.Context, layer types.LayerID, id types.
 	return w.weight, nil
 }
 
+// Weights returns the weight of the identity and the total weight of the active set used for the layer.
+func (o *Oracle) Weights(ctx context.Context, layer types.LayerID, id types.NodeID) (uint64, uint64, error) {
+	actives, err := o.actives(ctx, layer)
+	if err != nil {
+		return 0, 0, err
+	}
+	return actives.set[id].weight, actives.total, nil
+}
+
+// Participation is the share of an identity in the hare committees of an epoch.
+type Participation struct {
+	// Active is true if the identity is in the active set of the epoch.
+	Active bool
+	// Weight is the weight of the identity used for eligibilities, after the weight cap is applied.
+	Weight      uint64
+	TotalWeight uint64
+	// ExpectedSeats is the expected number of eligibilities of the identity in a round,
+	// the mean of the binomial distribution the eligibility count is drawn from.
+	ExpectedSeats float64
+}
+
+// Participation returns the participation of the identity in the hare committees of the epoch,
+// for rounds with the given committee size.
+func (o *Oracle) Participation(
+	ctx context.Context,
+	epoch types.EpochID,
+	id types.NodeID,
+	committeeSize int,
+) (Participation, error) {
+	actives, err := o.actives(ctx, o.switchLayer(epoch))
+	if err != nil {
+		return Participation{}, err
+	}
+	if actives.total == 0 {
+		return Participation{}, errZeroTotalWeight
+	}
+	identity, ok := actives.set[id]
+	return Participation{
+		Active:      ok,
+		Weight:      identity.weight,
+		TotalWeight: actives.total,
+		// scaling both the weight and the total when the committee is larger than the total weight,
+		// as in prepareEligibilityCheck, doesn't change the mean
+		ExpectedSeats: float64(committeeSize) * float64(identity.weight) / float64(actives.total),
+	}, nil
+}
+
 func calcVrfFrac(vrfSig types.VrfSignature) fixed.Fixed {
 	return fixed.FracFromBytes(vrfSig[:8])
 }
@@ -414,7 +611,7 @@ func (o *Oracle) actives(ctx context.Context, targetLayer types.LayerID) (*cache
 	// the first bootstrap data targets first epoch after genesis (epoch 2)
 	// and the epoch where checkpoint recovery happens
 	if targetEpoch > types.GetEffectiveGenesis().Add(1).GetEpoch() &&
-		targetLayer.Difference(targetEpoch.FirstLayer()) < o.cfg.ConfidenceParam {
+		targetLayer.Difference(targetEpoch.FirstLayer()) < o.cfg.ConfidenceParamFor(targetEpoch.FirstLayer()) {
 		targetEpoch -= 1
 	}
 	o.log.Debug("hare oracle getting active set",
@@ -425,12 +622,46 @@ func (o *Oracle) actives(ctx context.Context, targetLayer types.LayerID) (*cache
 	)
 
 	o.mu.Lock()
-	defer o.mu.Unlock()
 	o.resetCacheOnSynced(ctx)
-	if value, exists := o.activesCache.Get(targetEpoch); exists {
+	value, exists := o.activesCache.Get(targetEpoch)
+	if !exists {
+		value, exists = o.pinned[targetEpoch]
+	}
+	o.mu.Unlock()
+	if exists {
 		return value, nil
 	}
-	activeSet, err := o.computeActiveSet(ctx, targetEpoch)
+	results := o.inflight.DoChan(strconv.FormatUint(uint64(targetEpoch), 10), func() (any, error) {
+		// the computation is shared, it must not be canceled together with the caller that started it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), activesTimeout)
+		defer cancel()
+		return o.computeActives(ctx, targetEpoch)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case rst := <-results:
+		if rst.Err != nil {
+			return nil, rst.Err
+		}
+		return rst.Val.(*cachedActiveSet), nil
+	}
+}
+
+// computeActives computes the active set for the target epoch without holding the lock,
+// so that lookups for other epochs are not blocked by the computation.
+func (o *Oracle) computeActives(ctx context.Context, targetEpoch types.EpochID) (*cachedActiveSet, error) {
+	o.mu.Lock()
+	if value, exists := o.pinned[targetEpoch]; exists {
+		// computed by the previous flight
+		o.mu.Unlock()
+		return value, nil
+	}
+	generation := o.generation
+	fallback, hasFallback := o.fallback[targetEpoch]
+	o.mu.Unlock()
+
+	activeSet, err := o.computeActiveSet(ctx, targetEpoch, fallback, hasFallback)
 	if err != nil {
 		return nil, err
 	}
@@ -443,31 +674,110 @@ func (o *Oracle) actives(ctx context.Context, targetLayer types.LayerID) (*cache
 	}
 
 	aset := &cachedActiveSet{set: activeWeights}
-	for _, aweight := range activeWeights {
-		aset.total += aweight.weight
+	if o.cfg.WeightCap.enabled(targetEpoch) {
+		aset.total = o.cfg.WeightCap.apply(activeWeights)
+	} else {
+		for _, aweight := range activeWeights {
+			aset.total += aweight.weight
+		}
 	}
 	o.log.Debug("got hare active set", log.ZContext(ctx), zap.Int("count", len(activeWeights)))
-	o.activesCache.Add(targetEpoch, aset)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.generation == generation {
+		o.activesCache.Add(targetEpoch, aset)
+		o.pin(targetEpoch, aset)
+	}
 	return aset, nil
 }
 
+// switchLayer returns the first layer that uses the active set of the epoch.
+func (o *Oracle) switchLayer(epoch types.EpochID) types.LayerID {
+	return epoch.FirstLayer().Add(o.cfg.ConfidenceParamFor(epoch.FirstLayer()))
+}
+
+// RunWarmup precomputes the active set of the next epoch in the background, WarmupLayers before
+// the oracle switches to it, so that the computation is not in the hot path of the first layer.
+func (o *Oracle) RunWarmup(ctx context.Context, clock layerClock) {
+	if o.cfg.WarmupLayers == 0 {
+		return
+	}
+	for layer := clock.CurrentLayer(); ; layer++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.AwaitLayer(layer):
+			o.warmup(ctx, layer)
+		}
+	}
+}
+
+// warmup precomputes the active set that is used next, if the switch to it is at most WarmupLayers away.
+// The active set is precomputed only from data that doesn't change anymore: the fallback active set
+// or the active set of the first block in the epoch. Otherwise it is left to be computed when needed,
+// as the set of reference ballots in the epoch may still grow.
+func (o *Oracle) warmup(ctx context.Context, layer types.LayerID) {
+	target := layer.GetEpoch()
+	if layer >= o.switchLayer(target) {
+		target++
+	}
+	switchLayer := o.switchLayer(target)
+	if target <= o.warmedUp || switchLayer.Difference(layer) > o.cfg.WarmupLayers {
+		return
+	}
+	o.mu.Lock()
+	_, final := o.fallback[target]
+	o.mu.Unlock()
+	if !final {
+		activeSet, err := miner.ActiveSetFromEpochFirstBlock(o.db, target)
+		switch {
+		case errors.Is(err, sql.ErrNotFound) || err == nil && len(activeSet) == 0:
+			o.log.Debug("active set for warm-up is not available yet",
+				zap.Uint32("lid", layer.Uint32()),
+				zap.Uint32("target_epoch", target.Uint32()),
+			)
+			return
+		case err != nil:
+			o.log.Warn("failed to load active set for warm-up", zap.Uint32("target_epoch", target.Uint32()), zap.Error(err))
+			return
+		}
+	}
+	start := time.Now()
+	aset, err := o.actives(ctx, switchLayer)
+	if err != nil {
+		o.log.Warn("failed to warm up active set", zap.Uint32("target_epoch", target.Uint32()), zap.Error(err))
+		return
+	}
+	o.warmedUp = target
+	o.log.Info("active set warmed up",
+		zap.Uint32("lid", layer.Uint32()),
+		zap.Uint32("target_epoch", target.Uint32()),
+		zap.Int("size", len(aset.set)),
+		zap.Duration("duration", time.Since(start)),
+	)
+}
+
 func (o *Oracle) ActiveSet(ctx context.Context, targetEpoch types.EpochID) ([]types.ATXID, error) {
-	aset, err := o.actives(ctx, targetEpoch.FirstLayer().Add(o.cfg.ConfidenceParam))
+	aset, err := o.actives(ctx, targetEpoch.FirstLayer().Add(o.cfg.ConfidenceParamFor(targetEpoch.FirstLayer())))
 	if err != nil {
 		return nil, err
 	}
 	return aset.atxs(), nil
 }
 
-func (o *Oracle) computeActiveSet(ctx context.Context, targetEpoch types.EpochID) ([]types.ATXID, error) {
-	activeSet, ok := o.fallback[targetEpoch]
-	if ok {
+func (o *Oracle) computeActiveSet(
+	ctx context.Context,
+	targetEpoch types.EpochID,
+	fallback []types.ATXID,
+	hasFallback bool,
+) ([]types.ATXID, error) {
+	if hasFallback {
 		o.log.Debug("using fallback active set",
 			log.ZContext(ctx),
 			zap.Uint32("target_epoch", targetEpoch.Uint32()),
-			zap.Int("size", len(activeSet)),
+			zap.Int("size", len(fallback)),
 		)
-		return activeSet, nil
+		return fallback, nil
 	}
 
 	activeSet, err := miner.ActiveSetFromEpochFirstBlock(o.db, targetEpoch)
@@ -475,7 +785,7 @@ func (o *Oracle) computeActiveSet(ctx context.Context, targetEpoch types.EpochID
 		return nil, err
 	}
 	if len(activeSet) == 0 {
-		return o.activeSetFromRefBallots(targetEpoch)
+		return o.activeSetFromRefBallots(ctx, targetEpoch)
 	}
 	return activeSet, nil
 }
@@ -495,7 +805,7 @@ func (o *Oracle) computeActiveWeights(
 	return identities, nil
 }
 
-func (o *Oracle) activeSetFromRefBallots(epoch types.EpochID) ([]types.ATXID, error) {
+func (o *Oracle) activeSetFromRefBallots(ctx context.Context, epoch types.EpochID) ([]types.ATXID, error) {
 	beacon, err := o.beacons.GetBeacon(epoch)
 	if err != nil {
 		return nil, fmt.Errorf("get beacon: %w", err)
@@ -506,6 +816,9 @@ func (o *Oracle) activeSetFromRefBallots(epoch types.EpochID) ([]types.ATXID, er
 	}
 	activeMap := make(map[types.ATXID]struct{}, len(ballotsrst))
 	for _, ballot := range ballotsrst {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if ballot.EpochData == nil {
 			o.log.Error("invalid data. first ballot doesn't have epoch data", zap.Inline(ballot))
 			continue
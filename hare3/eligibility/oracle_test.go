@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"math"
 	"math/rand"
 	"os"
 	"strconv"
@@ -18,6 +19,7 @@ import (
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap/zaptest"
 	"golang.org/x/exp/maps"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/spacemeshos/go-spacemesh/atxsdata"
 	"github.com/spacemeshos/go-spacemesh/common/types"
@@ -532,6 +534,27 @@ func TestActiveSet(t *testing.T) {
 	}
 }
 
+func TestParticipation(t *testing.T) {
+	numMiners := 5
+	o := defaultOracle(t)
+	targetEpoch := types.EpochID(5)
+	miners := o.createLayerData(targetEpoch.FirstLayer(), numMiners)
+
+	// weights are 1..5, the total is 15
+	rst, err := o.Participation(context.Background(), targetEpoch, miners[2], 30)
+	require.NoError(t, err)
+	require.Equal(t, Participation{Active: true, Weight: 3, TotalWeight: 15, ExpectedSeats: 6}, rst)
+
+	// the expectation is the same when the committee is larger than the total weight
+	rst, err = o.Participation(context.Background(), targetEpoch, miners[4], 150)
+	require.NoError(t, err)
+	require.Equal(t, 50.0, rst.ExpectedSeats)
+
+	rst, err = o.Participation(context.Background(), targetEpoch, types.RandomNodeID(), 30)
+	require.NoError(t, err)
+	require.Equal(t, Participation{TotalWeight: 15}, rst)
+}
+
 func TestActives(t *testing.T) {
 	numMiners := 5
 	t.Run("genesis bootstrap", func(t *testing.T) {
@@ -635,6 +658,30 @@ func TestActives(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, activeSet, activeSet2)
 	})
+	t.Run("confidence upgrade", func(t *testing.T) {
+		numMiners++
+		o := defaultOracle(t)
+		o.mBeacon.EXPECT().GetBeacon(gomock.Any()).AnyTimes()
+		layer := types.EpochID(4).FirstLayer()
+		o.createLayerData(layer, numMiners)
+		upgrade := ConfidenceUpgrade{Layer: types.EpochID(5).FirstLayer(), Param: confidenceParam + 2}
+		o.cfg.ConfidenceUpgrades = []ConfidenceUpgrade{upgrade}
+
+		end := upgrade.Layer.Add(upgrade.Param)
+		for lid := layer.Add(confidenceParam); lid.Before(end); lid = lid.Add(1) {
+			got, err := o.actives(context.Background(), lid)
+			require.NoError(t, err)
+			require.ElementsMatch(
+				t,
+				maps.Keys(createIdentities(numMiners)),
+				maps.Keys(got.set),
+				"assertion relies on the enumeration of identities",
+			)
+		}
+		got, err := o.actives(context.Background(), end)
+		require.ErrorIs(t, err, errEmptyActiveSet)
+		require.Nil(t, got)
+	})
 }
 
 func TestActives_ConcurrentCalls(t *testing.T) {
@@ -679,6 +726,100 @@ func TestActives_ConcurrentCalls(t *testing.T) {
 	wg.Wait()
 }
 
+func TestActives_ConcurrentMisses(t *testing.T) {
+	o := defaultOracle(t)
+	layer := types.LayerID(100)
+	o.createLayerData(layer.Sub(defLayersPerEpoch), 5)
+
+	// lru evicts everything, but the active set is computed only once
+	mc := NewMockactiveSetCache(gomock.NewController(t))
+	mc.EXPECT().Get(layer.GetEpoch()-1).Return(nil, false).AnyTimes()
+	mc.EXPECT().Add(layer.GetEpoch()-1, gomock.Any()).Times(1)
+	o.activesCache = mc
+
+	var eg errgroup.Group
+	results := make([]*cachedActiveSet, 100)
+	for i := range results {
+		eg.Go(func() error {
+			var err error
+			results[i], err = o.actives(context.Background(), layer)
+			return err
+		})
+	}
+	require.NoError(t, eg.Wait())
+	for _, rst := range results {
+		require.Same(t, results[0], rst)
+	}
+}
+
+func TestActives_CanceledCaller(t *testing.T) {
+	o := defaultOracle(t)
+	layer := types.LayerID(100)
+	o.createLayerData(layer.Sub(defLayersPerEpoch), 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	mc := NewMockactiveSetCache(gomock.NewController(t))
+	mc.EXPECT().Get(layer.GetEpoch()-1).Return(nil, false).AnyTimes()
+	mc.EXPECT().Add(layer.GetEpoch()-1, gomock.Any()).DoAndReturn(func(types.EpochID, *cachedActiveSet) bool {
+		once.Do(func() {
+			close(started)
+			<-release
+		})
+		return false
+	}).MinTimes(1)
+	o.activesCache = mc
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := o.actives(ctx, layer)
+		first <- err
+	}()
+	<-started
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+
+	second := make(chan error, 1)
+	go func() {
+		rst, err := o.actives(context.Background(), layer)
+		if err == nil && rst == nil {
+			err = errors.New("empty active set")
+		}
+		second <- err
+	}()
+	close(release)
+	require.NoError(t, <-second)
+}
+
+func TestOracle_Pin(t *testing.T) {
+	o := defaultOracle(t)
+	sets := map[types.EpochID]*cachedActiveSet{}
+	for epoch := types.EpochID(3); epoch <= 5; epoch++ {
+		sets[epoch] = &cachedActiveSet{set: createIdentities(1)}
+	}
+
+	o.pin(3, sets[3])
+	o.pin(4, sets[4])
+	require.Equal(t, map[types.EpochID]*cachedActiveSet{3: sets[3], 4: sets[4]}, o.pinned)
+
+	o.pin(5, sets[5])
+	require.Equal(t, map[types.EpochID]*cachedActiveSet{4: sets[4], 5: sets[5]}, o.pinned)
+
+	// previous epoch computed late doesn't unpin the latest
+	o.pin(4, sets[4])
+	require.Equal(t, map[types.EpochID]*cachedActiveSet{4: sets[4], 5: sets[5]}, o.pinned)
+
+	// pinned active sets are used when lru evicted them
+	mc := NewMockactiveSetCache(gomock.NewController(t))
+	mc.EXPECT().Get(types.EpochID(4)).Return(nil, false)
+	o.activesCache = mc
+	got, err := o.actives(context.Background(), types.EpochID(4).FirstLayer().Add(o.cfg.ConfidenceParam))
+	require.NoError(t, err)
+	require.Same(t, sets[4], got)
+}
+
 func TestMaxSupportedN(t *testing.T) {
 	n := maxSupportedN
 	p := fixed.DivUint64(800, uint64(n*100))
@@ -695,6 +836,65 @@ func TestMaxSupportedN(t *testing.T) {
 	})
 }
 
+func TestWarmup(t *testing.T) {
+	const warmupLayers = 2
+	target := types.EpochID(5)
+
+	t.Run("first block", func(t *testing.T) {
+		o := defaultOracle(t)
+		o.cfg.WarmupLayers = warmupLayers
+		switchLayer := o.switchLayer(target)
+		require.Equal(t, target.FirstLayer().Add(confidenceParam), switchLayer)
+
+		// first block of the epoch is not available yet
+		o.warmup(context.Background(), switchLayer.Sub(warmupLayers))
+		require.Zero(t, o.warmedUp)
+
+		o.createLayerData(target.FirstLayer(), 5)
+		o.warmup(context.Background(), switchLayer.Sub(warmupLayers+1))
+		require.Zero(t, o.warmedUp)
+		require.NotContains(t, o.pinned, target)
+
+		o.warmup(context.Background(), switchLayer.Sub(warmupLayers))
+		require.Equal(t, target, o.warmedUp)
+		require.Contains(t, o.pinned, target)
+		require.Len(t, o.pinned[target].set, 5)
+	})
+	t.Run("fallback", func(t *testing.T) {
+		o := defaultOracle(t)
+		o.cfg.WarmupLayers = warmupLayers
+		activeSet := types.RandomActiveSet(3)
+		o.createActiveSet(target.FirstLayer().Sub(1), activeSet)
+		o.UpdateActiveSet(target, activeSet)
+
+		o.warmup(context.Background(), o.switchLayer(target).Sub(1))
+		require.Equal(t, target, o.warmedUp)
+		require.Len(t, o.pinned[target].set, 3)
+	})
+	t.Run("run", func(t *testing.T) {
+		o := defaultOracle(t)
+		o.cfg.WarmupLayers = warmupLayers
+		o.createLayerData(target.FirstLayer(), 5)
+		start := o.switchLayer(target).Sub(warmupLayers)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		clock := NewMocklayerClock(gomock.NewController(t))
+		clock.EXPECT().CurrentLayer().Return(start)
+		clock.EXPECT().AwaitLayer(gomock.Any()).DoAndReturn(func(lid types.LayerID) <-chan struct{} {
+			if lid > start {
+				cancel()
+				return make(chan struct{})
+			}
+			ch := make(chan struct{})
+			close(ch)
+			return ch
+		}).Times(2)
+		o.RunWarmup(ctx, clock)
+		require.Equal(t, target, o.warmedUp)
+	})
+}
+
 func TestActiveSetMatrix(t *testing.T) {
 	t.Parallel()
 
@@ -964,3 +1164,214 @@ func FuzzVrfMessageConsistency(f *testing.F) {
 func FuzzVrfMessageSafety(f *testing.F) {
 	tester.FuzzSafety[VrfMessage](f)
 }
+
+func TestConfig_ConfidenceParamFor(t *testing.T) {
+	cfg := Config{
+		ConfidenceParam: 1,
+		ConfidenceUpgrades: []ConfidenceUpgrade{
+			{Layer: 20, Param: 3},
+			{Layer: 40, Param: 2},
+		},
+	}
+	for _, tc := range []struct {
+		layer types.LayerID
+		param uint32
+	}{
+		{0, 1},
+		{19, 1},
+		{20, 3},
+		{39, 3},
+		{40, 2},
+		{100, 2},
+	} {
+		require.Equal(t, tc.param, cfg.ConfidenceParamFor(tc.layer), "layer %d", tc.layer)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		cfg  Config
+		err  string
+	}{
+		{
+			desc: "default",
+			cfg:  DefaultConfig(),
+		},
+		{
+			desc: "upgrades",
+			cfg: Config{
+				ConfidenceParam:    1,
+				ConfidenceUpgrades: []ConfidenceUpgrade{{Layer: 20, Param: 3}, {Layer: 40, Param: 9}},
+			},
+		},
+		{
+			desc: "param too large",
+			cfg:  Config{ConfidenceParam: defLayersPerEpoch},
+			err:  "confidence param should be smaller than layers per epoch",
+		},
+		{
+			desc: "upgrade param too large",
+			cfg: Config{
+				ConfidenceParam:    1,
+				ConfidenceUpgrades: []ConfidenceUpgrade{{Layer: 20, Param: defLayersPerEpoch}},
+			},
+			err: "should be smaller than layers per epoch",
+		},
+		{
+			desc: "upgrade not at epoch start",
+			cfg: Config{
+				ConfidenceParam:    1,
+				ConfidenceUpgrades: []ConfidenceUpgrade{{Layer: 21, Param: 3}},
+			},
+			err: "not the first layer of an epoch",
+		},
+		{
+			desc: "upgrades not ordered",
+			cfg: Config{
+				ConfidenceParam:    1,
+				ConfidenceUpgrades: []ConfidenceUpgrade{{Layer: 40, Param: 3}, {Layer: 20, Param: 2}},
+			},
+			err: "not after previous upgrade",
+		},
+		{
+			desc: "weight cap",
+			cfg: Config{
+				ConfidenceParam: 1,
+				WeightCap:       WeightCap{Layer: 20, PerMillion: 1000},
+			},
+		},
+		{
+			desc: "weight cap too large",
+			cfg: Config{
+				ConfidenceParam: 1,
+				WeightCap:       WeightCap{Layer: 20, PerMillion: WeightCapScale + 1},
+			},
+			err: "should not be larger than",
+		},
+		{
+			desc: "weight cap not at epoch start",
+			cfg: Config{
+				ConfidenceParam: 1,
+				WeightCap:       WeightCap{Layer: 21, PerMillion: 1000},
+			},
+			err: "not the first layer of an epoch",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.cfg.Validate(defLayersPerEpoch)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestWeightCap_Apply(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		cap     uint32
+		weights []uint64
+		capped  []uint64
+		total   uint64
+	}{
+		{
+			desc:    "no outsized identities",
+			cap:     WeightCapScale / 2,
+			weights: []uint64{10, 20, 30},
+			capped:  []uint64{10, 20, 30},
+			total:   60,
+		},
+		{
+			desc:    "outsized identity",
+			cap:     WeightCapScale / 10,
+			weights: []uint64{1, 2, 3, 4, 90},
+			capped:  []uint64{1, 2, 3, 4, 10},
+			total:   20,
+		},
+		{
+			desc:    "rounded down",
+			cap:     WeightCapScale / 3,
+			weights: []uint64{1, 1, 8},
+			capped:  []uint64{1, 1, 3},
+			total:   5,
+		},
+		{
+			desc:    "at least one",
+			cap:     1,
+			weights: []uint64{1, 2, 3},
+			capped:  []uint64{1, 1, 1},
+			total:   3,
+		},
+		{
+			desc:    "no overflow",
+			cap:     WeightCapScale / 2,
+			weights: []uint64{math.MaxUint64 / 4, math.MaxUint64 / 2},
+			capped:  []uint64{math.MaxUint64 / 4, (math.MaxUint64/4 + math.MaxUint64/2) / 2},
+			total:   math.MaxUint64/4 + (math.MaxUint64/4+math.MaxUint64/2)/2,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			identities := map[types.NodeID]identityWeight{}
+			for i, weight := range tc.weights {
+				identities[types.NodeID{byte(i)}] = identityWeight{weight: weight}
+			}
+			total := WeightCap{PerMillion: tc.cap}.apply(identities)
+			require.Equal(t, tc.total, total)
+			for i, weight := range tc.capped {
+				require.Equal(t, weight, identities[types.NodeID{byte(i)}].weight, "identity %d", i)
+			}
+		})
+	}
+}
+
+func TestActives_WeightCap(t *testing.T) {
+	const numMiners = 10
+	capEpoch := types.EpochID(5)
+	cfg := Config{
+		ConfidenceParam: confidenceParam,
+		WeightCap:       WeightCap{Layer: capEpoch.FirstLayer(), PerMillion: WeightCapScale / 10},
+	}
+	o := defaultOracle(t)
+	o.cfg = cfg
+	// weights of identities are 1,2,...,10 with total 55, so the cap is 5
+	for _, epoch := range []types.EpochID{capEpoch - 1, capEpoch} {
+		activeSet := types.RandomActiveSet(numMiners)
+		o.createActiveSet((epoch - 1).FirstLayer(), activeSet)
+		o.UpdateActiveSet(epoch, activeSet)
+	}
+	miners := maps.Keys(createIdentities(numMiners))
+
+	before := capEpoch.FirstLayer().Sub(1)
+	total, err := o.totalWeight(context.Background(), before)
+	require.NoError(t, err)
+	require.EqualValues(t, 55, total)
+
+	start := capEpoch.FirstLayer().Add(confidenceParam)
+	total, err = o.totalWeight(context.Background(), start)
+	require.NoError(t, err)
+	require.EqualValues(t, 1+2+3+4+5*6, total)
+	for _, id := range miners {
+		weight, err := o.minerWeight(context.Background(), start, id)
+		require.NoError(t, err)
+		require.LessOrEqual(t, weight, uint64(5))
+	}
+
+	// other nodes with the same data compute the same capped weights
+	for range 5 {
+		other := New(o.beacons, o.db, o.atxsdata, o.vrfVerifier, defLayersPerEpoch,
+			WithConfig(cfg), WithLogger(zaptest.NewLogger(t)))
+		for _, epoch := range []types.EpochID{capEpoch - 1, capEpoch} {
+			other.UpdateActiveSet(epoch, o.fallback[epoch])
+		}
+		for _, lid := range []types.LayerID{before, start} {
+			expected, err := o.actives(context.Background(), lid)
+			require.NoError(t, err)
+			got, err := other.actives(context.Background(), lid)
+			require.NoError(t, err)
+			require.Equal(t, expected, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package eligibility
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/signing"
+)
+
+type weighter interface {
+	Weights(context.Context, types.LayerID, types.NodeID) (uint64, uint64, error)
+}
+
+var _ Rolacle = &Shadow{}
+
+// Shadow runs a candidate oracle next to the authoritative one, so that a replacement can be
+// rolled out safely. Decisions of the authoritative oracle are returned, the candidate is only
+// consulted to log and count the decisions where it disagrees.
+type Shadow struct {
+	logger    *zap.Logger
+	oracle    Rolacle
+	candidate Rolacle
+}
+
+// NewShadow creates an oracle that returns decisions of oracle and compares them with candidate.
+func NewShadow(oracle, candidate Rolacle, logger *zap.Logger) *Shadow {
+	return &Shadow{
+		logger:    logger,
+		oracle:    oracle,
+		candidate: candidate,
+	}
+}
+
+// Validate returns the decision of the authoritative oracle.
+func (s *Shadow) Validate(
+	ctx context.Context,
+	layer types.LayerID,
+	round uint32,
+	committeeSize int,
+	id types.NodeID,
+	sig types.VrfSignature,
+	eligibilityCount uint16,
+) (bool, error) {
+	valid, err := s.oracle.Validate(ctx, layer, round, committeeSize, id, sig, eligibilityCount)
+	shadow, shadowErr := s.candidate.Validate(ctx, layer, round, committeeSize, id, sig, eligibilityCount)
+	if valid == shadow && (err == nil) == (shadowErr == nil) {
+		shadowDecisions.WithLabelValues("validate", shadowAgree).Inc()
+		return valid, err
+	}
+	shadowDecisions.WithLabelValues("validate", shadowDisagree).Inc()
+	s.logger.Warn("shadow oracle disagrees on validation",
+		s.fields(ctx, layer, round, committeeSize, id,
+			zap.Uint16("count", eligibilityCount),
+			zap.Bool("valid", valid),
+			zap.NamedError("error", err),
+			zap.Bool("shadow_valid", shadow),
+			zap.NamedError("shadow_error", shadowErr),
+		)...,
+	)
+	return valid, err
+}
+
+// CalcEligibility returns the number of eligibilities computed by the authoritative oracle.
+func (s *Shadow) CalcEligibility(
+	ctx context.Context,
+	layer types.LayerID,
+	round uint32,
+	committeeSize int,
+	id types.NodeID,
+	sig types.VrfSignature,
+) (uint16, error) {
+	count, err := s.oracle.CalcEligibility(ctx, layer, round, committeeSize, id, sig)
+	shadow, shadowErr := s.candidate.CalcEligibility(ctx, layer, round, committeeSize, id, sig)
+	if count == shadow && errors.Is(shadowErr, ErrNotActive) == errors.Is(err, ErrNotActive) &&
+		(err == nil) == (shadowErr == nil) {
+		shadowDecisions.WithLabelValues("eligibility", shadowAgree).Inc()
+		return count, err
+	}
+	shadowDecisions.WithLabelValues("eligibility", shadowDisagree).Inc()
+	s.logger.Warn("shadow oracle disagrees on eligibility",
+		s.fields(ctx, layer, round, committeeSize, id,
+			zap.Uint16("count", count),
+			zap.NamedError("error", err),
+			zap.Uint16("shadow_count", shadow),
+			zap.NamedError("shadow_error", shadowErr),
+		)...,
+	)
+	return count, err
+}
+
+func (s *Shadow) fields(
+	ctx context.Context,
+	layer types.LayerID,
+	round uint32,
+	committeeSize int,
+	id types.NodeID,
+	extra ...zap.Field,
+) []zap.Field {
+	fields := []zap.Field{
+		log.ZContext(ctx),
+		zap.Uint32("layer", layer.Uint32()),
+		zap.Uint32("round", round),
+		zap.Int("committee_size", committeeSize),
+		zap.Stringer("smesher", id),
+	}
+	if w, ok := s.oracle.(weighter); ok {
+		weight, total, err := w.Weights(ctx, layer, id)
+		if err != nil {
+			fields = append(fields, zap.NamedError("weights_error", err))
+		} else {
+			fields = append(fields, zap.Uint64("weight", weight), zap.Uint64("total_weight", total))
+		}
+	}
+	return append(fields, extra...)
+}
+
+// Proof returns the proof of the authoritative oracle.
+func (s *Shadow) Proof(
+	ctx context.Context,
+	signer *signing.VRFSigner,
+	layer types.LayerID,
+	round uint32,
+) (types.VrfSignature, error) {
+	return s.oracle.Proof(ctx, signer, layer, round)
+}
+
+// ActiveSet returns the active set of the authoritative oracle.
+func (s *Shadow) ActiveSet(ctx context.Context, targetEpoch types.EpochID) ([]types.ATXID, error) {
+	return s.oracle.ActiveSet(ctx, targetEpoch)
+}
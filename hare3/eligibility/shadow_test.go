@@ -0,0 +1,55 @@
+package eligibility
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestShadow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	oracle := NewMockRolacle(ctrl)
+	candidate := NewMockRolacle(ctrl)
+	core, logs := observer.New(zap.WarnLevel)
+	shadow := NewShadow(oracle, candidate, zap.New(core))
+
+	ctx := context.Background()
+	layer := types.LayerID(11)
+	id := types.RandomNodeID()
+	sig := types.RandomVrfSignature()
+
+	t.Run("validate agrees", func(t *testing.T) {
+		oracle.EXPECT().Validate(ctx, layer, uint32(1), 10, id, sig, uint16(2)).Return(true, nil)
+		candidate.EXPECT().Validate(ctx, layer, uint32(1), 10, id, sig, uint16(2)).Return(true, nil)
+		valid, err := shadow.Validate(ctx, layer, 1, 10, id, sig, 2)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Zero(t, logs.Len())
+	})
+	t.Run("validate disagrees", func(t *testing.T) {
+		oracle.EXPECT().Validate(ctx, layer, uint32(1), 10, id, sig, uint16(2)).Return(true, nil)
+		candidate.EXPECT().Validate(ctx, layer, uint32(1), 10, id, sig, uint16(2)).Return(false, nil)
+		valid, err := shadow.Validate(ctx, layer, 1, 10, id, sig, 2)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Equal(t, 1, logs.FilterMessage("shadow oracle disagrees on validation").Len())
+	})
+	t.Run("eligibility disagrees", func(t *testing.T) {
+		oracle.EXPECT().CalcEligibility(ctx, layer, uint32(2), 10, id, sig).Return(uint16(0), ErrNotActive)
+		candidate.EXPECT().CalcEligibility(ctx, layer, uint32(2), 10, id, sig).Return(uint16(1), nil)
+		count, err := shadow.CalcEligibility(ctx, layer, 2, 10, id, sig)
+		require.ErrorIs(t, err, ErrNotActive)
+		require.Zero(t, count)
+		entries := logs.FilterMessage("shadow oracle disagrees on eligibility").AllUntimed()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		require.Equal(t, uint32(2), fields["round"])
+		require.EqualValues(t, 1, fields["shadow_count"])
+	})
+}
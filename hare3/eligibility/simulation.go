@@ -0,0 +1,81 @@
+package eligibility
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/spacemeshos/fixed"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// Seats describes the committee membership that is expected from an identity in a single round.
+type Seats struct {
+	ID     types.NodeID
+	Weight uint64
+	// Expected is the mean number of eligibilities of the identity.
+	Expected float64
+	// Eligible is the probability that the identity gets at least one eligibility.
+	Eligible float64
+}
+
+// ExpectedSeats computes the expected committee membership of every identity in the active set,
+// given the weights of identities and the committee size. The result is sorted by node ID.
+//
+// The number of eligibilities of an identity follows the same binomial distribution that is used
+// by CalcEligibility, the VRF signature only selects a sample from it. Therefore the expectation
+// doesn't depend on the beacon, the layer or the round, and can be computed without private keys.
+func ExpectedSeats(weights map[types.NodeID]uint64, committeeSize int) ([]Seats, error) {
+	if committeeSize < 1 {
+		return nil, errZeroCommitteeSize
+	}
+	if len(weights) == 0 {
+		return nil, errEmptyActiveSet
+	}
+	var total uint64
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return nil, errZeroTotalWeight
+	}
+	scale := uint64(1)
+	if uint64(committeeSize) > total {
+		scale = uint64(committeeSize)
+	}
+	p := fixed.DivUint64(uint64(committeeSize), total*scale)
+	rst := make([]Seats, 0, len(weights))
+	for id, weight := range weights {
+		n := weight * scale
+		if n > maxSupportedN {
+			return nil, fmt.Errorf("miner weight exceeds supported maximum (id: %v, weight: %d, max: %d)",
+				id, weight, maxSupportedN)
+		}
+		seats := Seats{ID: id, Weight: weight}
+		if n > 0 {
+			seats.Expected = float64(n) * p.Float()
+			seats.Eligible = 1 - fixed.BinCDF(int(n), p, 0).Float()
+		}
+		rst = append(rst, seats)
+	}
+	slices.SortFunc(rst, func(a, b Seats) int {
+		return bytes.Compare(a.ID.Bytes(), b.ID.Bytes())
+	})
+	return rst, nil
+}
+
+// ExpectedCommittee computes the expected committee membership of every identity in the active set
+// that the oracle uses for the layer. See ExpectedSeats.
+func (o *Oracle) ExpectedCommittee(ctx context.Context, layer types.LayerID, committeeSize int) ([]Seats, error) {
+	actives, err := o.actives(ctx, layer)
+	if err != nil {
+		return nil, err
+	}
+	weights := make(map[types.NodeID]uint64, len(actives.set))
+	for id, identity := range actives.set {
+		weights[id] = identity.weight
+	}
+	return ExpectedSeats(weights, committeeSize)
+}
@@ -0,0 +1,73 @@
+package eligibility
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestExpectedSeats(t *testing.T) {
+	t.Run("errors", func(t *testing.T) {
+		_, err := ExpectedSeats(map[types.NodeID]uint64{{1}: 1}, 0)
+		require.ErrorIs(t, err, errZeroCommitteeSize)
+		_, err = ExpectedSeats(nil, 10)
+		require.ErrorIs(t, err, errEmptyActiveSet)
+		_, err = ExpectedSeats(map[types.NodeID]uint64{{1}: 0}, 10)
+		require.ErrorIs(t, err, errZeroTotalWeight)
+		_, err = ExpectedSeats(map[types.NodeID]uint64{{1}: maxSupportedN + 1}, 10)
+		require.ErrorContains(t, err, "exceeds supported maximum")
+	})
+	t.Run("proportional to weight", func(t *testing.T) {
+		seats, err := ExpectedSeats(map[types.NodeID]uint64{
+			{3}: 5000,
+			{1}: 1000,
+			{2}: 4000,
+			{4}: 0,
+		}, 50)
+		require.NoError(t, err)
+		require.Len(t, seats, 4)
+		expected := []float64{5, 20, 25, 0}
+		var sum float64
+		for i, s := range seats {
+			require.Equal(t, types.NodeID{byte(i + 1)}, s.ID)
+			require.InDelta(t, expected[i], s.Expected, 0.001)
+			sum += s.Expected
+		}
+		require.InDelta(t, 50, sum, 0.001)
+		require.Greater(t, seats[2].Eligible, seats[0].Eligible)
+		require.InDelta(t, 0.99, seats[0].Eligible, 0.01)
+		require.Zero(t, seats[3].Eligible)
+	})
+	t.Run("committee larger than total weight", func(t *testing.T) {
+		seats, err := ExpectedSeats(map[types.NodeID]uint64{{1}: 1, {2}: 3}, 800)
+		require.NoError(t, err)
+		require.InDelta(t, 200, seats[0].Expected, 0.01)
+		require.InDelta(t, 600, seats[1].Expected, 0.01)
+		require.InDelta(t, 1, seats[0].Eligible, 0.001)
+	})
+}
+
+func TestExpectedCommittee(t *testing.T) {
+	o := defaultOracle(t)
+	first := types.GetEffectiveGenesis().Add(1)
+	bootstrap := types.RandomActiveSet(5)
+	miners := o.createActiveSet(types.EpochID(1).FirstLayer(), bootstrap)
+	o.UpdateActiveSet(types.GetEffectiveGenesis().GetEpoch()+1, bootstrap)
+
+	seats, err := o.ExpectedCommittee(context.Background(), first, 10)
+	require.NoError(t, err)
+	require.Len(t, seats, len(miners))
+	var sum float64
+	for _, s := range seats {
+		require.Contains(t, miners, s.ID)
+		require.InDelta(t, 10*float64(s.Weight)/15, s.Expected, 0.001)
+		sum += s.Expected
+	}
+	require.InDelta(t, 10, sum, 0.001)
+
+	_, err = o.ExpectedCommittee(context.Background(), types.GetEffectiveGenesis(), 10)
+	require.ErrorIs(t, err, errEmptyActiveSet)
+}
@@ -2,9 +2,11 @@ package hare3
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sync"
 	"time"
 
@@ -17,7 +19,9 @@ import (
 	"github.com/spacemeshos/go-spacemesh/atxsdata"
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
 	"github.com/spacemeshos/go-spacemesh/hare4"
+	"github.com/spacemeshos/go-spacemesh/hash"
 	"github.com/spacemeshos/go-spacemesh/layerpatrol"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/metrics"
@@ -29,14 +33,47 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/beacons"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/harestats"
 	"github.com/spacemeshos/go-spacemesh/system"
 )
 
+// penalties applied to the score of the peer that relayed an invalid message.
+// only malformed and badly signed messages are penalized, as they can't be relayed by an honest peer.
+// messages with zero grade or dropped by graded gossip are routinely relayed by honest peers,
+// due to differences in local timing or when equivocations are relayed. the application score
+// applies to the peer on every topic, so penalizing them would prune honest peers from all meshes.
+const (
+	malformedPenalty = 1000
+	signaturePenalty = 500
+)
+
 type CommitteeUpgrade struct {
 	Layer types.LayerID
 	Size  uint16
 }
 
+// BeaconTolerance configures how proposals with the beacon of the previous epoch are treated
+// in the first layers of an epoch, when some honest smeshers may not have switched yet.
+type BeaconTolerance struct {
+	// Layers is the number of layers at the start of an epoch in which such proposals
+	// are tolerated. Zero disables tolerance.
+	Layers uint32 `mapstructure:"layers"`
+	// Accept adds such proposals to the candidate set, otherwise they are only logged.
+	Accept bool `mapstructure:"accept"`
+}
+
+// tolerates returns true if proposals with the previous epoch beacon are tolerated in the layer.
+func (bt BeaconTolerance) tolerates(layer types.LayerID) bool {
+	return layer.GetEpoch() > 0 && layer.Difference(layer.GetEpoch().FirstLayer()) < bt.Layers
+}
+
+// FeatureActivation activates a protocol feature starting from a layer.
+type FeatureActivation struct {
+	// Bit is the index of the feature in Features, it must be smaller than 64.
+	Bit   uint8         `mapstructure:"bit"`
+	Layer types.LayerID `mapstructure:"layer"`
+}
+
 type Config struct {
 	Enable           bool          `mapstructure:"enable"`
 	EnableLayer      types.LayerID `mapstructure:"enable-layer"`
@@ -51,6 +88,34 @@ type Config struct {
 	// This requires additional computation and should be used for debugging only.
 	LogStats     bool   `mapstructure:"log-stats"`
 	ProtocolName string `mapstructure:"protocolname"`
+	// Audit cross-checks hare output against tortoise.
+	Audit AuditConfig `mapstructure:"audit"`
+	// Archive keeps validated messages of the recent layers in the local database.
+	Archive ArchiveConfig `mapstructure:"archive"`
+	// Stats keeps a summary of iterations of the recent layers in the local database.
+	Stats StatsConfig `mapstructure:"stats"`
+	// Outputs keeps the agreed proposal set of the recent layers in the local database.
+	Outputs OutputsConfig `mapstructure:"outputs"`
+	// WatchdogSlack is how long a session may run after the last round of the last iteration
+	// before it is terminated by the watchdog. Zero disables the watchdog.
+	WatchdogSlack time.Duration `mapstructure:"watchdog-slack"`
+	// BeaconTolerance of proposals with the previous epoch beacon at the start of an epoch.
+	BeaconTolerance BeaconTolerance `mapstructure:"beacon-tolerance"`
+	// Features is a schedule of protocol features. Messages are sent with the features that are active
+	// in their layer, and messages without them are not accepted.
+	Features []FeatureActivation `mapstructure:"features"`
+	Handler  HandlerConfig       `mapstructure:"handler"`
+}
+
+// FeaturesFor returns the features that are active in the layer.
+func (cfg *Config) FeaturesFor(layer types.LayerID) Features {
+	var features Features
+	for _, feature := range cfg.Features {
+		if layer >= feature.Layer {
+			features |= 1 << feature.Bit
+		}
+	}
+	return features
 }
 
 func (cfg *Config) CommitteeFor(layer types.LayerID) uint16 {
@@ -60,6 +125,45 @@ func (cfg *Config) CommitteeFor(layer types.LayerID) uint16 {
 	return cfg.Committee
 }
 
+// Hash returns a digest of the parameters that must be the same on all nodes
+// in the network, otherwise nodes disagree on the committee and consensus splits.
+// Eligibility params are included, as they change the eligibilities of the committee members.
+func (cfg *Config) Hash(elig *eligibility.Config) types.Hash32 {
+	buf := binary.LittleEndian.AppendUint32(nil, cfg.EnableLayer.Uint32())
+	buf = binary.LittleEndian.AppendUint32(buf, cfg.DisableLayer.Uint32())
+	buf = binary.LittleEndian.AppendUint16(buf, cfg.Committee)
+	if cfg.CommitteeUpgrade != nil {
+		buf = binary.LittleEndian.AppendUint32(buf, cfg.CommitteeUpgrade.Layer.Uint32())
+		buf = binary.LittleEndian.AppendUint16(buf, cfg.CommitteeUpgrade.Size)
+	}
+	buf = binary.LittleEndian.AppendUint16(buf, cfg.Leaders)
+	buf = append(buf, cfg.IterationsLimit)
+	if cfg.BeaconTolerance.Accept && cfg.BeaconTolerance.Layers > 0 {
+		// accepted proposals change the candidate set
+		buf = binary.LittleEndian.AppendUint32(buf, cfg.BeaconTolerance.Layers)
+	}
+	for _, feature := range cfg.Features {
+		buf = append(buf, feature.Bit)
+		buf = binary.LittleEndian.AppendUint32(buf, feature.Layer.Uint32())
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, elig.ConfidenceParam)
+	for _, upgrade := range elig.ConfidenceUpgrades {
+		buf = binary.LittleEndian.AppendUint32(buf, upgrade.Layer.Uint32())
+		buf = binary.LittleEndian.AppendUint32(buf, upgrade.Param)
+	}
+	if elig.WeightCap.PerMillion > 0 {
+		buf = binary.LittleEndian.AppendUint32(buf, elig.WeightCap.Layer.Uint32())
+		buf = binary.LittleEndian.AppendUint32(buf, elig.WeightCap.PerMillion)
+	}
+	return hash.Sum(buf)
+}
+
+// ReportConfigHash exports the hash of the config as a metric, so that nodes with a different
+// config can be spotted by comparing it across the network.
+func ReportConfigHash(hash types.Hash32) {
+	configHash.WithLabelValues(hash.ShortString()).Set(1)
+}
+
 func (cfg *Config) Validate(zdist time.Duration) error {
 	terminates := cfg.roundStart(IterRound{Iter: cfg.IterationsLimit, Round: hardlock})
 	if terminates > zdist {
@@ -69,6 +173,16 @@ func (cfg *Config) Validate(zdist time.Duration) error {
 		return fmt.Errorf("disabled layer (%d) must be larger than enabled (%d)",
 			cfg.DisableLayer, cfg.EnableLayer)
 	}
+	var bits Features
+	for _, feature := range cfg.Features {
+		if feature.Bit >= 64 {
+			return fmt.Errorf("feature bit %d must be smaller than 64", feature.Bit)
+		}
+		if bits.Has(1 << feature.Bit) {
+			return fmt.Errorf("feature bit %d is activated more than once", feature.Bit)
+		}
+		bits |= 1 << feature.Bit
+	}
 	return nil
 }
 
@@ -87,6 +201,17 @@ func (cfg *Config) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	encoder.AddDuration("round duration", cfg.RoundDuration)
 	encoder.AddBool("log stats", cfg.LogStats)
 	encoder.AddString("p2p protocol", cfg.ProtocolName)
+	encoder.AddBool("audit", cfg.Audit.Enable)
+	encoder.AddUint32("archive layers", cfg.Archive.Layers)
+	encoder.AddUint32("stats layers", cfg.Stats.Layers)
+	encoder.AddUint32("outputs layers", cfg.Outputs.Layers)
+	encoder.AddDuration("watchdog slack", cfg.WatchdogSlack)
+	encoder.AddInt("handler workers", cfg.Handler.Workers)
+	encoder.AddUint32("beacon tolerance layers", cfg.BeaconTolerance.Layers)
+	encoder.AddBool("beacon tolerance accept", cfg.BeaconTolerance.Accept)
+	for _, feature := range cfg.Features {
+		encoder.AddUint32(fmt.Sprintf("feature %d from layer", feature.Bit), feature.Layer.Uint32())
+	}
 	return nil
 }
 
@@ -112,14 +237,21 @@ func DefaultConfig() Config {
 		PreroundDelay:   25 * time.Second,
 		RoundDuration:   12 * time.Second,
 		// can be bumped to 3.1 when oracle upgrades
-		ProtocolName: "/h/3.0",
-		DisableLayer: math.MaxUint32,
+		ProtocolName:  "/h/3.0",
+		DisableLayer:  math.MaxUint32,
+		Audit:         DefaultAuditConfig(),
+		WatchdogSlack: time.Minute,
+		// roughly a month with 5 minute layers
+		Outputs: OutputsConfig{Layers: 10000},
+		Handler: DefaultHandlerConfig(),
 	}
 }
 
 type ConsensusOutput struct {
 	Layer     types.LayerID
 	Proposals []types.ProposalID
+	// Hash of the sorted proposals, the reference that hare agreed on.
+	Hash types.Hash32
 }
 
 type WeakCoinOutput struct {
@@ -149,6 +281,13 @@ func WithLogger(logger *zap.Logger) Opt {
 	}
 }
 
+// WithOracle overrides the eligibility oracle passed to New, e.g. with an experimental implementation.
+func WithOracle(oracle eligibility.Rolacle) Opt {
+	return func(hr *Hare) {
+		hr.oracle.oracle = oracle
+	}
+}
+
 func WithTracer(tracer Tracer) Opt {
 	return func(hr *Hare) {
 		hr.tracer = tracer
@@ -163,6 +302,38 @@ func WithResultsChan(c chan hare4.ConsensusOutput) Opt {
 	}
 }
 
+// WithArchiveDB sets the local database for the archive of hare messages.
+// The archive is enabled only if Archive.Layers is not zero in the config.
+func WithArchiveDB(db sql.LocalDatabase) Opt {
+	return func(hr *Hare) {
+		hr.archiveDB = db
+	}
+}
+
+// WithOutputsDB sets the local database for the record of agreed proposal sets.
+// The record is enabled only if Outputs.Layers is not zero in the config.
+func WithOutputsDB(db sql.LocalDatabase) Opt {
+	return func(hr *Hare) {
+		hr.outputsDB = db
+	}
+}
+
+// WithProposalFetcher sets the fetcher used to re-fetch proposals that are referenced by the commit
+// message but are no longer available in the proposals store.
+func WithProposalFetcher(fetcher system.ProposalFetcher) Opt {
+	return func(hr *Hare) {
+		hr.fetcher = fetcher
+	}
+}
+
+// WithStatsDB sets the local database for the per-layer iteration stats.
+// Stats are enabled only if Stats.Layers is not zero in the config.
+func WithStatsDB(db sql.LocalDatabase) Opt {
+	return func(hr *Hare) {
+		hr.statsDB = db
+	}
+}
+
 type nodeClock interface {
 	AwaitLayer(types.LayerID) <-chan struct{}
 	CurrentLayer() types.LayerID
@@ -176,7 +347,7 @@ func New(
 	atxsdata *atxsdata.Data,
 	proposals *store.Store,
 	verifier *signing.EdVerifier,
-	oracle oracle,
+	oracle eligibility.Rolacle,
 	sync system.SyncStateProvider,
 	patrol *layerpatrol.LayerPatrol,
 	opts ...Opt,
@@ -189,6 +360,7 @@ func New(
 		coins:    make(chan hare4.WeakCoinOutput, 32),
 		signers:  map[string]*signing.EdSigner{},
 		sessions: map[types.LayerID]*protocol{},
+		running:  map[types.LayerID]*session{},
 
 		config:    DefaultConfig(),
 		log:       zap.NewNop(),
@@ -212,6 +384,24 @@ func New(
 	for _, opt := range opts {
 		opt(hr)
 	}
+	if hr.config.Audit.Enable {
+		hr.auditor = newAuditor(hr.log.Named("audit"), hr.config.Audit, db, proposals)
+	}
+	if hr.config.Archive.Layers > 0 && hr.archiveDB != nil {
+		hr.archive = newArchive(hr.log.Named("archive"), hr.config.Archive, hr.archiveDB)
+	}
+	if hr.config.Stats.Layers > 0 && hr.statsDB != nil {
+		hr.stats = newLayerStats(hr.log.Named("stats"), hr.config.Stats, hr.statsDB)
+	}
+	if hr.config.Outputs.Layers > 0 && hr.outputsDB != nil {
+		hr.outputs = newOutputs(hr.log.Named("outputs"), hr.config.Outputs, hr.outputsDB)
+	}
+	if hr.config.Handler.Workers > 0 {
+		hr.pool = newHandlerPool(hr.config.Handler)
+		hr.eg.Go(func() error {
+			return hr.pool.run(hr.ctx)
+		})
+	}
 	return hr
 }
 
@@ -225,6 +415,7 @@ type Hare struct {
 	mu       sync.Mutex
 	signers  map[string]*signing.EdSigner
 	sessions map[types.LayerID]*protocol
+	running  map[types.LayerID]*session
 
 	// options
 	config    Config
@@ -237,11 +428,23 @@ type Hare struct {
 	db        sql.StateDatabase
 	atxsdata  *atxsdata.Data
 	proposals *store.Store
+	fetcher   system.ProposalFetcher
 	verifier  *signing.EdVerifier
 	oracle    *legacyOracle
 	sync      system.SyncStateProvider
 	patrol    *layerpatrol.LayerPatrol
 	tracer    Tracer
+	// auditor, archive, stats and outputs are set only in New, as they are read
+	// by sessions without synchronization.
+	auditor   *Auditor
+	archiveDB sql.LocalDatabase
+	archive   *Archive
+	statsDB   sql.LocalDatabase
+	stats     *LayerStats
+	outputsDB sql.LocalDatabase
+	outputs   *Outputs
+	// pool is nil if messages are validated inline.
+	pool *handlerPool
 }
 
 func (h *Hare) Register(sig *signing.EdSigner) {
@@ -249,6 +452,46 @@ func (h *Hare) Register(sig *signing.EdSigner) {
 	defer h.mu.Unlock()
 	h.log.Info("registered signing key", log.ZShortStringer("id", sig.NodeID()))
 	h.signers[string(sig.NodeID().Bytes())] = sig
+	// signer joins sessions that are still waiting for preround delay
+	for _, s := range h.running {
+		// registering again cancels the pending removal from the session
+		delete(s.leaving, sig.NodeID())
+		if s.joinable && !s.hasSigner(sig.NodeID()) {
+			s.joining = append(s.joining, sig)
+		}
+	}
+}
+
+// Unregister removes the signing key of the identity. The signer doesn't join future sessions,
+// and running sessions stop producing messages for it at the next round boundary.
+// Returns false if the key is not registered.
+func (h *Hare) Unregister(id types.NodeID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, exists := h.signers[string(id.Bytes())]; !exists {
+		h.log.Warn("unregistering unknown signing key", log.ZShortStringer("id", id))
+		return false
+	}
+	delete(h.signers, string(id.Bytes()))
+	var leaving []uint32
+	for lid, s := range h.running {
+		s.joining = slices.DeleteFunc(s.joining, func(signer *signing.EdSigner) bool {
+			return signer.NodeID() == id
+		})
+		if slices.ContainsFunc(s.signers, func(signer *signing.EdSigner) bool { return signer.NodeID() == id }) {
+			if s.leaving == nil {
+				s.leaving = make(map[types.NodeID]struct{})
+			}
+			s.leaving[id] = struct{}{}
+			leaving = append(leaving, lid.Uint32())
+		}
+	}
+	slices.Sort(leaving)
+	h.log.Info("unregistered signing key",
+		log.ZShortStringer("id", id),
+		zap.Uint32s("leaving_sessions", leaving),
+	)
+	return true
 }
 
 func (h *Hare) Results() <-chan hare4.ConsensusOutput {
@@ -259,8 +502,29 @@ func (h *Hare) Coins() <-chan hare4.WeakCoinOutput {
 	return h.coins
 }
 
+// Auditor returns the auditor of hare output, or nil if audit is disabled.
+func (h *Hare) Auditor() *Auditor {
+	return h.auditor
+}
+
+// Archive returns the archive of hare messages, or nil if it is disabled.
+func (h *Hare) Archive() *Archive {
+	return h.archive
+}
+
+// LayerStats returns the per-layer iteration stats, or nil if they are disabled.
+func (h *Hare) LayerStats() *LayerStats {
+	return h.stats
+}
+
+// Outputs returns the record of agreed proposal sets, or nil if it is disabled.
+func (h *Hare) Outputs() *Outputs {
+	return h.outputs
+}
+
 func (h *Hare) Start() {
-	h.pubsub.Register(h.config.ProtocolName, h.Handler, pubsub.WithValidatorInline(true))
+	// with the pool, validators run concurrently and wait for the pool instead of blocking gossip
+	h.pubsub.Register(h.config.ProtocolName, h.Handler, pubsub.WithValidatorInline(h.pool == nil))
 	current := h.nodeClock.CurrentLayer() + 1
 	enabled := max(current, h.config.EnableLayer, types.GetEffectiveGenesis()+1)
 	disabled := types.LayerID(math.MaxUint32)
@@ -272,6 +536,19 @@ func (h *Hare) Start() {
 		zap.Uint32("enabled layer", enabled.Uint32()),
 		zap.Uint32("disabled layer", disabled.Uint32()),
 	)
+	if h.auditor != nil {
+		h.eg.Go(func() error {
+			return h.auditor.run(h.ctx)
+		})
+	}
+	if h.archive != nil {
+		h.eg.Go(func() error {
+			return h.archive.run(h.ctx)
+		})
+	}
+	if h.config.WatchdogSlack > 0 {
+		h.eg.Go(h.watchdog)
+	}
 	h.eg.Go(func() error {
 		for next := enabled; next < disabled; next++ {
 			select {
@@ -296,11 +573,23 @@ func (h *Hare) Handler(ctx context.Context, peer p2p.Peer, buf []byte) error {
 	msg := &Message{}
 	if err := codec.Decode(buf, msg); err != nil {
 		malformedError.Inc()
-		return fmt.Errorf("%w: decoding error %s", pubsub.ErrValidationReject, err.Error())
+		return pubsub.WithPenalty(
+			fmt.Errorf("%w: decoding error %s", pubsub.ErrValidationReject, err.Error()),
+			malformedPenalty,
+		)
 	}
 	if err := msg.Validate(); err != nil {
 		malformedError.Inc()
-		return fmt.Errorf("%w: validation %s", pubsub.ErrValidationReject, err.Error())
+		return pubsub.WithPenalty(
+			fmt.Errorf("%w: validation %s", pubsub.ErrValidationReject, err.Error()),
+			malformedPenalty,
+		)
+	}
+	if required := h.config.FeaturesFor(msg.Layer); !msg.Features.Has(required) {
+		// the sender didn't upgrade to a version that supports features active in the layer,
+		// such messages are ignored but peers that relay them are not penalized.
+		featuresError.Inc()
+		return fmt.Errorf("message is missing features %b active in layer %d", required&^msg.Features, msg.Layer)
 	}
 	h.tracer.OnMessageReceived(msg)
 	h.mu.Lock()
@@ -310,9 +599,23 @@ func (h *Hare) Handler(ctx context.Context, peer p2p.Peer, buf []byte) error {
 		notRegisteredError.Inc()
 		return fmt.Errorf("layer %d is not registered", msg.Layer)
 	}
+	if h.pool == nil {
+		return h.process(msg, session, buf)
+	}
+	err := h.pool.submit(ctx, msg.Layer, func() error {
+		return h.process(msg, session, buf)
+	})
+	if errors.Is(err, errOverloaded) {
+		overloadedError.Inc()
+	}
+	return err
+}
+
+// process does the expensive part of message validation and submits the message to the session.
+func (h *Hare) process(msg *Message, session *protocol, buf []byte) error {
 	if !h.verifier.Verify(signing.HARE, msg.Sender, msg.ToMetadata().ToBytes(), msg.Signature) {
 		signatureError.Inc()
-		return fmt.Errorf("%w: invalid signature", pubsub.ErrValidationReject)
+		return pubsub.WithPenalty(fmt.Errorf("%w: invalid signature", pubsub.ErrValidationReject), signaturePenalty)
 	}
 	malicious := h.atxsdata.IsMalicious(msg.Sender)
 
@@ -330,6 +633,9 @@ func (h *Hare) Handler(ctx context.Context, peer p2p.Peer, buf []byte) error {
 		malicious: malicious,
 		atxgrade:  g,
 	}
+	if h.archive != nil {
+		h.archive.add(msg, input.msgHash, buf, start)
+	}
 	h.log.Debug("on message", zap.Inline(input))
 	gossip, equivocation := session.OnInput(input)
 	h.log.Debug("after on message", log.ZShortStringer("hash", input.msgHash), zap.Bool("gossip", gossip))
@@ -356,8 +662,18 @@ func (h *Hare) Handler(ctx context.Context, peer p2p.Peer, buf []byte) error {
 
 func (h *Hare) onLayer(layer types.LayerID) {
 	h.proposals.OnLayer(layer)
+	if h.archive != nil {
+		h.archive.onLayer(layer)
+	}
+	if h.stats != nil {
+		h.stats.onLayer(layer)
+	}
+	if h.outputs != nil {
+		h.outputs.onLayer(layer)
+	}
 	if !h.sync.IsSynced(h.ctx) {
 		h.log.Debug("not synced", zap.Uint32("lid", layer.Uint32()))
+		h.patrol.SetWaiting(layer, layerpatrol.ReasonNotSynced)
 		return
 	}
 	beacon, err := beacons.Get(h.db, layer.GetEpoch())
@@ -367,49 +683,90 @@ func (h *Hare) onLayer(layer types.LayerID) {
 			zap.Uint32("lid", layer.Uint32()),
 			zap.Error(err),
 		)
+		h.patrol.SetWaiting(layer, layerpatrol.ReasonBeaconMissing)
 		return
 	}
+	var prevBeacon types.Beacon
+	if h.config.BeaconTolerance.tolerates(layer) {
+		prevBeacon, err = beacons.Get(h.db, layer.GetEpoch()-1)
+		if err != nil {
+			h.log.Debug("no beacon for previous epoch",
+				zap.Uint32("epoch", layer.GetEpoch().Uint32()-1),
+				zap.Uint32("lid", layer.Uint32()),
+				zap.Error(err),
+			)
+		}
+	}
 	h.patrol.SetHareInCharge(layer)
 
+	ctx, cancel := context.WithCancel(h.ctx)
 	h.mu.Lock()
-	// signer can't join mid session
+	// signer can't join mid session, only before preround delay passes
 	s := &session{
-		lid:     layer,
-		beacon:  beacon,
-		signers: maps.Values(h.signers),
-		vrfs:    make([]*types.HareEligibility, len(h.signers)),
-		proto:   newProtocol(h.config.CommitteeFor(layer)/2 + 1),
+		ctx:        ctx,
+		cancel:     cancel,
+		lid:        layer,
+		beacon:     beacon,
+		prevBeacon: prevBeacon,
+		signers:    maps.Values(h.signers),
+		vrfs:       make([]*types.HareEligibility, len(h.signers)),
+		proto:      newProtocol(h.config.CommitteeFor(layer)/2 + 1),
+		joinable:   true,
 	}
 	h.sessions[layer] = s.proto
+	h.running[layer] = s
 	h.mu.Unlock()
 
 	sessionStart.Inc()
 	h.tracer.OnStart(layer)
 	h.log.Debug("registered layer", zap.Uint32("lid", layer.Uint32()))
 	h.eg.Go(func() error {
-		if err := h.run(s); err != nil {
-			h.log.Warn("failed",
-				zap.Uint32("lid", layer.Uint32()),
-				zap.Error(err),
-			)
-			exitErrors.Inc()
-			// if terminated successfully it will notify block generator
-			// and it will have to CompleteHare
-			h.patrol.CompleteHare(layer)
-		} else {
-			h.log.Debug("terminated",
-				zap.Uint32("lid", layer.Uint32()),
-			)
-		}
-		h.mu.Lock()
-		delete(h.sessions, layer)
-		h.mu.Unlock()
-		sessionTerminated.Inc()
-		h.tracer.OnStop(layer)
+		h.exitSession(s, h.run(s))
 		return nil
 	})
 }
 
+// exitSession removes the session after its goroutine exits, unless the watchdog already did.
+func (h *Hare) exitSession(s *session, err error) {
+	s.cancel()
+	h.mu.Lock()
+	expired := s.expired
+	if !expired {
+		delete(h.sessions, s.lid)
+		delete(h.running, s.lid)
+	}
+	h.mu.Unlock()
+	if expired {
+		// the watchdog already accounted for the session
+		h.log.Warn("expired session exited", zap.Uint32("lid", s.lid.Uint32()), zap.Error(err))
+		return
+	}
+	if err != nil {
+		h.log.Warn("failed",
+			zap.Uint32("lid", s.lid.Uint32()),
+			zap.Error(err),
+		)
+		exitErrors.Inc()
+	} else {
+		h.log.Debug("terminated",
+			zap.Uint32("lid", s.lid.Uint32()),
+		)
+	}
+	h.onStopped(s.lid, s.proto.Iter, err)
+}
+
+// onStopped accounts for a session that was removed from hare. It is called once per session,
+// either when the session exits or when the watchdog expires it.
+func (h *Hare) onStopped(lid types.LayerID, iter uint8, err error) {
+	if err != nil {
+		// if terminated successfully it will notify block generator
+		// and it will have to CompleteHare
+		h.patrol.HareFailed(lid, iter, err)
+	}
+	sessionTerminated.Inc()
+	h.tracer.OnStop(lid)
+}
+
 func (h *Hare) run(session *session) error {
 	// oracle may load non-negligible amount of data from disk
 	// we do it before preround starts, so that load can have some slack time
@@ -427,14 +784,23 @@ func (h *Hare) run(session *session) error {
 	activeLatency.Observe(time.Since(start).Seconds())
 
 	walltime := h.nodeClock.LayerToTime(session.lid).Add(h.config.PreroundDelay)
+	h.log.Debug("waiting for preround delay",
+		zap.Uint32("lid", session.lid.Uint32()),
+		zap.Bool("active", active),
+	)
+	select {
+	case <-h.wallClock.After(walltime.Sub(h.wallClock.Now())):
+	case <-session.ctx.Done():
+		return session.ctx.Err()
+	}
+	if h.join(session, current) {
+		active = true
+	}
+	if h.leave(session) {
+		active = slices.ContainsFunc(session.vrfs, func(vrf *types.HareEligibility) bool { return vrf != nil })
+	}
+	// initial set is not needed if node is not active in preround
 	if active {
-		h.log.Debug("active in preround. waiting for preround delay", zap.Uint32("lid", session.lid.Uint32()))
-		// initial set is not needed if node is not active in preround
-		select {
-		case <-h.wallClock.After(walltime.Sub(h.wallClock.Now())):
-		case <-h.ctx.Done():
-			return h.ctx.Err()
-		}
 		start := time.Now()
 		session.proto.OnInitial(h.selectProposals(session))
 		proposalsLatency.Observe(time.Since(start).Seconds())
@@ -442,6 +808,7 @@ func (h *Hare) run(session *session) error {
 	if err := h.onOutput(session, current, session.proto.Next()); err != nil {
 		return err
 	}
+	h.prefetch(session, session.proto.Candidates())
 	result := false
 	for {
 		walltime = walltime.Add(h.config.RoundDuration)
@@ -460,6 +827,7 @@ func (h *Hare) run(session *session) error {
 
 		select {
 		case <-h.wallClock.After(walltime.Sub(h.wallClock.Now())):
+			h.leave(session)
 			h.log.Debug("execute round",
 				zap.Uint32("lid", session.lid.Uint32()),
 				zap.Uint8("iter", session.proto.Iter), zap.Stringer("round", session.proto.Round),
@@ -469,13 +837,25 @@ func (h *Hare) run(session *session) error {
 			if out.result != nil {
 				result = true
 			}
+			if current.Round == commit && out.message != nil {
+				h.checkAvailable(session, &out)
+			}
 			if err := h.onOutput(session, current, out); err != nil {
 				return err
 			}
+			if current.Round == propose {
+				h.prefetch(session, session.proto.Candidates())
+			}
 			// we are logginng stats 1 network delay after new iteration start
 			// so that we can receive notify messages from previous iteration
-			if session.proto.Round == softlock && h.config.LogStats {
-				h.log.Debug("stats", zap.Uint32("lid", session.lid.Uint32()), zap.Inline(session.proto.Stats()))
+			if session.proto.Round == softlock && (h.config.LogStats || h.stats != nil) {
+				stats := session.proto.Stats()
+				if h.config.LogStats {
+					h.log.Debug("stats", zap.Uint32("lid", session.lid.Uint32()), zap.Inline(stats))
+				}
+				if h.stats != nil {
+					session.summary = h.stats.record(session.lid, stats, session.summary)
+				}
 			}
 			if out.terminated {
 				if !result {
@@ -486,7 +866,7 @@ func (h *Hare) run(session *session) error {
 			if current.Iter == h.config.IterationsLimit {
 				return fmt.Errorf("hare failed to reach consensus in %d iterations", h.config.IterationsLimit)
 			}
-		case <-h.ctx.Done():
+		case <-session.ctx.Done():
 			return nil
 		}
 	}
@@ -501,8 +881,9 @@ func (h *Hare) onOutput(session *session, ir IterRound, out output) error {
 		msg.Layer = session.lid
 		msg.Eligibility = *vrf
 		msg.Sender = session.signers[i].NodeID()
+		msg.Features = h.config.FeaturesFor(session.lid)
 		msg.Signature = session.signers[i].Sign(signing.HARE, msg.ToMetadata().ToBytes())
-		if err := h.pubsub.Publish(h.ctx, h.config.ProtocolName, msg.ToBytes()); err != nil {
+		if err := h.pubsub.Publish(session.ctx, h.config.ProtocolName, msg.ToBytes()); err != nil {
 			h.log.Error("failed to publish", zap.Inline(&msg), zap.Error(err))
 		}
 	}
@@ -514,19 +895,30 @@ func (h *Hare) onOutput(session *session, ir IterRound, out output) error {
 	)
 	if out.coin != nil {
 		select {
-		case <-h.ctx.Done():
-			return h.ctx.Err()
+		case <-session.ctx.Done():
+			return session.ctx.Err()
 		case h.coins <- hare4.WeakCoinOutput{Layer: session.lid, Coin: *out.coin}:
 		}
 		sessionCoin.Inc()
 	}
 	if out.result != nil {
+		result := hare4.ConsensusOutput{
+			Layer:     session.lid,
+			Proposals: out.result,
+			Hash:      toHash(out.result),
+		}
+		if h.outputs != nil {
+			h.outputs.record(result.Layer, result.Hash, result.Proposals)
+		}
 		select {
-		case <-h.ctx.Done():
-			return h.ctx.Err()
-		case h.results <- hare4.ConsensusOutput{Layer: session.lid, Proposals: out.result}:
+		case <-session.ctx.Done():
+			return session.ctx.Err()
+		case h.results <- result:
 		}
 		sessionResult.Inc()
+		if h.auditor != nil {
+			h.auditor.onOutput(result)
+		}
 	}
 	return nil
 }
@@ -601,9 +993,21 @@ func (h *Hare) selectProposals(session *session) []types.ProposalID {
 			continue
 		}
 
-		if p.Beacon() == session.beacon {
+		switch {
+		case p.Beacon() == session.beacon:
 			result = append(result, p.ID())
-		} else {
+		case session.prevBeacon != types.EmptyBeacon && p.Beacon() == session.prevBeacon:
+			h.log.Warn("proposal has beacon value of the previous epoch",
+				zap.Uint32("lid", session.lid.Uint32()),
+				zap.Stringer("id", p.ID()),
+				zap.Stringer("proposal_beacon", p.Beacon()),
+				zap.Stringer("epoch_beacon", session.beacon),
+				zap.Bool("accepted", h.config.BeaconTolerance.Accept),
+			)
+			if h.config.BeaconTolerance.Accept {
+				result = append(result, p.ID())
+			}
+		default:
 			h.log.Warn("proposal has different beacon value",
 				zap.Uint32("lid", session.lid.Uint32()),
 				zap.Stringer("id", p.ID()),
@@ -631,9 +1035,39 @@ func (h *Hare) Stop() {
 }
 
 type session struct {
-	proto   *protocol
-	lid     types.LayerID
-	beacon  types.Beacon
-	signers []*signing.EdSigner
-	vrfs    []*types.HareEligibility
+	// ctx is canceled when hare stops or the session is terminated by the watchdog.
+	ctx    context.Context
+	cancel context.CancelFunc
+	proto  *protocol
+	lid    types.LayerID
+	beacon types.Beacon
+	// prevBeacon is the beacon of the previous epoch, set only if it is tolerated in the layer.
+	prevBeacon types.Beacon
+	signers    []*signing.EdSigner
+	vrfs       []*types.HareEligibility
+	summary    *harestats.Stats
+
+	// joinable is true until preround delay passes, signers registered
+	// in the meantime are added to joining. guarded by Hare.mu.
+	joinable bool
+	joining  []*signing.EdSigner
+	// leaving are signers that were unregistered while the session was running, they are removed
+	// at the next round boundary. guarded by Hare.mu.
+	leaving map[types.NodeID]struct{}
+	// expired is set by the watchdog when it terminates the session. guarded by Hare.mu.
+	expired bool
+}
+
+func (s *session) hasSigner(id types.NodeID) bool {
+	for _, signer := range s.signers {
+		if signer.NodeID() == id {
+			return true
+		}
+	}
+	for _, signer := range s.joining {
+		if signer.NodeID() == id {
+			return true
+		}
+	}
+	return false
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql/ballots"
 	"github.com/spacemeshos/go-spacemesh/sql/beacons"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 	smocks "github.com/spacemeshos/go-spacemesh/system/mocks"
 )
@@ -234,6 +235,9 @@ func (n *node) withHare() *node {
 		WithLogger(logger),
 		WithWallClock(n.clock),
 		WithTracer(tracer),
+		WithArchiveDB(localsql.InMemoryTest(n.t)),
+		WithStatsDB(localsql.InMemoryTest(n.t)),
+		WithOutputsDB(localsql.InMemoryTest(n.t)),
 	)
 	n.register(n.signer)
 	return n
@@ -607,6 +611,7 @@ func testHare(t *testing.T, active, inactive, equivocators int, opts ...clusterO
 		case rst := <-n.hare.Results():
 			require.Equal(t, rst.Layer, layer)
 			require.NotEmpty(t, rst.Proposals)
+			require.Equal(t, types.CalcProposalsHash32(rst.Proposals, nil), rst.Hash)
 			if consistent == nil {
 				consistent = rst.Proposals
 			} else {
@@ -655,6 +660,11 @@ func TestIterationLimit(t *testing.T) {
 	cluster.waitStopped()
 	require.Empty(t, cluster.nodes[0].hare.Running())
 	require.False(t, cluster.nodes[0].patrol.IsHareInCharge(layer))
+	status, ok := cluster.nodes[0].patrol.Status(layer)
+	require.True(t, ok)
+	require.Equal(t, layerpatrol.ReasonHareFailed, status.Reason)
+	require.Equal(t, tst.cfg.IterationsLimit, status.Iteration)
+	require.NotEmpty(t, status.Error)
 }
 
 func TestConfigMarshal(t *testing.T) {
@@ -663,13 +673,32 @@ func TestConfigMarshal(t *testing.T) {
 	require.NoError(t, cfg.MarshalLogObject(enc))
 }
 
+func TestConfigFeatures(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Features = []FeatureActivation{{Bit: 0, Layer: 10}, {Bit: 3, Layer: 20}}
+	require.NoError(t, cfg.Validate(time.Hour))
+	require.Zero(t, cfg.FeaturesFor(9))
+	require.Equal(t, Features(1), cfg.FeaturesFor(10))
+	require.Equal(t, Features(1|1<<3), cfg.FeaturesFor(20))
+	def := DefaultConfig()
+	elig := eligibility.DefaultConfig()
+	require.NotEqual(t, def.Hash(&elig), cfg.Hash(&elig))
+
+	cfg.Features = append(cfg.Features, FeatureActivation{Bit: 3, Layer: 30})
+	require.ErrorContains(t, cfg.Validate(time.Hour), "more than once")
+	cfg.Features = []FeatureActivation{{Bit: 64}}
+	require.ErrorContains(t, cfg.Validate(time.Hour), "smaller than 64")
+}
+
 func TestHandler(t *testing.T) {
 	t.Parallel()
+	cfg := DefaultConfig()
+	cfg.Features = []FeatureActivation{{Bit: 2, Layer: types.GetEffectiveGenesis() + 2}}
 	tst := &tester{
 		TB:            t,
 		rng:           rand.New(rand.NewSource(1001)),
 		start:         time.Now(),
-		cfg:           DefaultConfig(),
+		cfg:           cfg,
 		layerDuration: 5 * time.Minute,
 		beacon:        types.Beacon{1, 1, 1, 1},
 		genesis:       types.GetEffectiveGenesis(),
@@ -696,6 +725,8 @@ func TestHandler(t *testing.T) {
 			pubsub.ErrValidationReject)
 		require.ErrorContains(t, n.hare.Handler(context.Background(), "", []byte("malformed")),
 			"decoding")
+		require.Equal(t, float64(malformedPenalty),
+			pubsub.Penalty(n.hare.Handler(context.Background(), "", []byte("malformed"))))
 	})
 	t.Run("invalidated", func(t *testing.T) {
 		msg := &Message{}
@@ -709,6 +740,18 @@ func TestHandler(t *testing.T) {
 		msg := &Message{}
 		require.ErrorContains(t, n.hare.Handler(context.Background(), "", codec.MustEncode(msg)),
 			"is not registered")
+		require.Zero(t, pubsub.Penalty(n.hare.Handler(context.Background(), "", codec.MustEncode(msg))))
+	})
+	t.Run("missing features", func(t *testing.T) {
+		msg := &Message{}
+		msg.Layer = layer + 1
+		require.ErrorContains(t, n.hare.Handler(context.Background(), "", codec.MustEncode(msg)),
+			"missing features")
+		require.Zero(t, pubsub.Penalty(n.hare.Handler(context.Background(), "", codec.MustEncode(msg))))
+
+		msg.Features = 1<<2 | 1<<5
+		require.ErrorContains(t, n.hare.Handler(context.Background(), "", codec.MustEncode(msg)),
+			"is not registered")
 	})
 	t.Run("invalid signature", func(t *testing.T) {
 		msg := &Message{}
@@ -719,6 +762,8 @@ func TestHandler(t *testing.T) {
 			pubsub.ErrValidationReject)
 		require.ErrorContains(t, n.hare.Handler(context.Background(), "", codec.MustEncode(msg)),
 			"invalid signature")
+		require.Equal(t, float64(signaturePenalty),
+			pubsub.Penalty(n.hare.Handler(context.Background(), "", codec.MustEncode(msg))))
 	})
 	t.Run("zero grade", func(t *testing.T) {
 		signer, err := signing.NewEdSigner()
@@ -729,6 +774,8 @@ func TestHandler(t *testing.T) {
 		msg.Signature = signer.Sign(signing.HARE, msg.ToMetadata().ToBytes())
 		require.ErrorContains(t, n.hare.Handler(context.Background(), "", codec.MustEncode(msg)),
 			"zero grade")
+		// honest peers relay messages with zero grade due to a different local view
+		require.Zero(t, pubsub.Penalty(n.hare.Handler(context.Background(), "", codec.MustEncode(msg))))
 	})
 	t.Run("equivocation", func(t *testing.T) {
 		msg1 := &Message{}
@@ -756,6 +803,8 @@ func TestHandler(t *testing.T) {
 			n.hare.Handler(context.Background(), "", codec.MustEncode(msg2)),
 			"dropped by graded",
 		)
+		// honest peers relay messages of equivocating identities
+		require.Zero(t, pubsub.Penalty(n.hare.Handler(context.Background(), "", codec.MustEncode(msg2))))
 	})
 }
 
@@ -811,13 +860,15 @@ func TestProposals(t *testing.T) {
 	signer, err := signing.NewEdSigner()
 	require.NoError(t, err)
 	for _, tc := range []struct {
-		desc      string
-		atxs      []types.ActivationTx
-		proposals []*types.Proposal
-		malicious []types.NodeID
-		layer     types.LayerID
-		beacon    types.Beacon
-		expect    []types.ProposalID
+		desc       string
+		atxs       []types.ActivationTx
+		proposals  []*types.Proposal
+		malicious  []types.NodeID
+		layer      types.LayerID
+		beacon     types.Beacon
+		prevBeacon types.Beacon
+		tolerance  BeaconTolerance
+		expect     []types.ProposalID
 	}{
 		{
 			desc:   "sanity",
@@ -849,6 +900,40 @@ func TestProposals(t *testing.T) {
 			},
 			expect: []types.ProposalID{pids[0]},
 		},
+		{
+			desc:       "previous epoch beacon logged",
+			layer:      layer,
+			beacon:     goodBeacon,
+			prevBeacon: badBeacon,
+			tolerance:  BeaconTolerance{Layers: 1},
+			atxs: []types.ActivationTx{
+				gatx(atxids[0], publish, ids[0], 10, 100),
+				gatx(atxids[1], publish, ids[1], 10, 100),
+				gatx(atxids[2], publish, signer.NodeID(), 10, 100),
+			},
+			proposals: []*types.Proposal{
+				gproposal(pids[0], atxids[0], ids[0], layer, goodBeacon),
+				gproposal(pids[1], atxids[1], ids[1], layer, badBeacon),
+			},
+			expect: []types.ProposalID{pids[0]},
+		},
+		{
+			desc:       "previous epoch beacon accepted",
+			layer:      layer,
+			beacon:     goodBeacon,
+			prevBeacon: badBeacon,
+			tolerance:  BeaconTolerance{Layers: 1, Accept: true},
+			atxs: []types.ActivationTx{
+				gatx(atxids[0], publish, ids[0], 10, 100),
+				gatx(atxids[1], publish, ids[1], 10, 100),
+				gatx(atxids[2], publish, signer.NodeID(), 10, 100),
+			},
+			proposals: []*types.Proposal{
+				gproposal(pids[0], atxids[0], ids[0], layer, goodBeacon),
+				gproposal(pids[1], atxids[1], ids[1], layer, badBeacon),
+			},
+			expect: []types.ProposalID{pids[0], pids[1]},
+		},
 		{
 			desc:   "multiproposals",
 			layer:  layer,
@@ -900,6 +985,8 @@ func TestProposals(t *testing.T) {
 			db := statesql.InMemory()
 			atxsdata := atxsdata.New()
 			proposals := store.New()
+			cfg := DefaultConfig()
+			cfg.BeaconTolerance = tc.tolerance
 			hare := New(
 				nil,
 				nil,
@@ -911,6 +998,7 @@ func TestProposals(t *testing.T) {
 				nil,
 				layerpatrol.New(),
 				WithLogger(zaptest.NewLogger(t)),
+				WithConfig(cfg),
 			)
 			for _, atx := range tc.atxs {
 				require.NoError(t, atxs.Add(db, &atx, types.AtxBlob{}))
@@ -924,14 +1012,25 @@ func TestProposals(t *testing.T) {
 				atxsdata.SetMalicious(id)
 			}
 			require.ElementsMatch(t, tc.expect, hare.selectProposals(&session{
-				lid:     tc.layer,
-				beacon:  tc.beacon,
-				signers: []*signing.EdSigner{signer},
+				lid:        tc.layer,
+				beacon:     tc.beacon,
+				prevBeacon: tc.prevBeacon,
+				signers:    []*signing.EdSigner{signer},
 			}))
 		})
 	}
 }
 
+func TestBeaconTolerance(t *testing.T) {
+	epoch := types.EpochID(3)
+	bt := BeaconTolerance{Layers: 2}
+	require.True(t, bt.tolerates(epoch.FirstLayer()))
+	require.True(t, bt.tolerates(epoch.FirstLayer()+1))
+	require.False(t, bt.tolerates(epoch.FirstLayer()+2))
+	require.False(t, bt.tolerates(epoch.FirstLayer()-1))
+	require.False(t, BeaconTolerance{}.tolerates(epoch.FirstLayer()))
+}
+
 func TestHare_AddProposal(t *testing.T) {
 	t.Parallel()
 	proposals := store.New()
@@ -952,6 +1051,33 @@ func TestHare_AddProposal(t *testing.T) {
 	require.ErrorIs(t, hare.OnProposal(p), store.ErrProposalExists)
 }
 
+func TestHare_WithOracle(t *testing.T) {
+	t.Parallel()
+	oracle := eligibility.NewMockRolacle(gomock.NewController(t))
+	cfg := DefaultConfig()
+	hare := New(nil, nil, nil, nil, nil, nil, nil, nil, nil, WithConfig(cfg), WithOracle(oracle))
+
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	layer := types.LayerID(10)
+	ir := IterRound{Round: preround}
+	oracle.EXPECT().
+		CalcEligibility(gomock.Any(), layer, ir.Absolute(), int(cfg.CommitteeFor(layer)), signer.NodeID(), gomock.Any()).
+		Return(uint16(2), nil)
+	active := hare.oracle.active(signer, types.RandomBeacon(), layer, ir)
+	require.NotNil(t, active)
+	require.EqualValues(t, 2, active.Count)
+
+	msg := &Message{
+		Body:   Body{Layer: layer, IterRound: ir, Eligibility: *active},
+		Sender: signer.NodeID(),
+	}
+	oracle.EXPECT().
+		Validate(gomock.Any(), layer, ir.Absolute(), int(cfg.CommitteeFor(layer)), signer.NodeID(), active.Proof, active.Count).
+		Return(false, nil)
+	require.Equal(t, grade0, hare.oracle.validate(msg))
+}
+
 func TestHareConfig_CommitteeUpgrade(t *testing.T) {
 	t.Parallel()
 	t.Run("no upgrade", func(t *testing.T) {
@@ -975,3 +1101,40 @@ func TestHareConfig_CommitteeUpgrade(t *testing.T) {
 		require.EqualValues(t, 50, cfg.CommitteeFor(100))
 	})
 }
+
+func TestHareConfig_Hash(t *testing.T) {
+	t.Parallel()
+	cfg := DefaultConfig()
+	def := DefaultConfig()
+	elig := eligibility.DefaultConfig()
+	require.Equal(t, cfg.Hash(&elig), def.Hash(&elig))
+
+	upgraded := DefaultConfig()
+	upgraded.CommitteeUpgrade = &CommitteeUpgrade{Layer: 16, Size: 50}
+	require.NotEqual(t, cfg.Hash(&elig), upgraded.Hash(&elig))
+
+	other := DefaultConfig()
+	other.CommitteeUpgrade = &CommitteeUpgrade{Layer: 17, Size: 50}
+	require.NotEqual(t, upgraded.Hash(&elig), other.Hash(&elig))
+
+	local := DefaultConfig()
+	local.LogStats = true
+	local.Audit.Enable = true
+	require.Equal(t, cfg.Hash(&elig), local.Hash(&elig), "local settings must not affect the hash")
+
+	confidence := eligibility.DefaultConfig()
+	confidence.ConfidenceParam++
+	require.NotEqual(t, cfg.Hash(&elig), cfg.Hash(&confidence))
+
+	upgrades := eligibility.DefaultConfig()
+	upgrades.ConfidenceUpgrades = []eligibility.ConfidenceUpgrade{{Layer: 16, Param: 1}}
+	require.NotEqual(t, cfg.Hash(&elig), cfg.Hash(&upgrades))
+
+	capped := eligibility.DefaultConfig()
+	capped.WeightCap = eligibility.WeightCap{Layer: 16, PerMillion: 10_000}
+	require.NotEqual(t, cfg.Hash(&elig), cfg.Hash(&capped))
+
+	disabled := eligibility.DefaultConfig()
+	disabled.WeightCap.Layer = 16
+	require.Equal(t, cfg.Hash(&elig), cfg.Hash(&disabled), "disabled cap must not affect the hash")
+}
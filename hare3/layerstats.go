@@ -0,0 +1,92 @@
+package hare3
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/harestats"
+)
+
+// StatsConfig configures the per-layer iteration statistics.
+type StatsConfig struct {
+	// Layers is the number of the most recent layers for which statistics are kept.
+	// Zero disables statistics.
+	Layers uint32 `mapstructure:"layers"`
+}
+
+// LayerStats persists a compact summary of every hare iteration in the local database,
+// so that it can be queried without enabling debug logs.
+type LayerStats struct {
+	logger *zap.Logger
+	config StatsConfig
+	db     sql.LocalDatabase
+}
+
+func newLayerStats(logger *zap.Logger, config StatsConfig, db sql.LocalDatabase) *LayerStats {
+	return &LayerStats{logger: logger, config: config, db: db}
+}
+
+// record summarizes stats of the last completed iteration. Preround stats are only collected
+// in the first iteration, so they are carried over from the previous summary.
+func (l *LayerStats) record(lid types.LayerID, s *stats, prev *harestats.Stats) *harestats.Stats {
+	summary := &harestats.Stats{
+		Layer:      lid,
+		Iterations: s.iter + 1,
+		Threshold:  s.threshold,
+	}
+	for _, commit := range s.commit {
+		if commit.grade == grade4 {
+			summary.CommitMargin = margin(s.threshold, commit.tallies)
+		}
+	}
+	for _, notify := range s.notify {
+		if notify.grade == grade5 {
+			summary.NotifyMargin = margin(s.threshold, notify.tallies)
+		}
+	}
+	if prev != nil {
+		summary.Preround = prev.Preround
+	}
+	for _, pre := range s.preround {
+		count := uint16(0)
+		for _, tally := range pre.tallies {
+			if tally.total >= s.threshold && tally.valid > 0 {
+				count++
+			}
+		}
+		summary.Preround[pre.grade-grade1] = count
+	}
+	if err := harestats.Set(l.db, summary); err != nil {
+		l.logger.Warn("failed to save stats", zap.Uint32("lid", lid.Uint32()), zap.Error(err))
+	}
+	return summary
+}
+
+func (l *LayerStats) onLayer(lid types.LayerID) {
+	if lid.Uint32() <= l.config.Layers {
+		return
+	}
+	if err := harestats.DeleteBefore(l.db, lid.Sub(l.config.Layers)); err != nil {
+		l.logger.Warn("failed to prune stats", zap.Uint32("lid", lid.Uint32()), zap.Error(err))
+	}
+}
+
+// Get returns stats of the layer. Returns sql.ErrNotFound if the layer has no stats.
+func (l *LayerStats) Get(lid types.LayerID) (*harestats.Stats, error) {
+	return harestats.Get(l.db, lid)
+}
+
+// Range returns stats of layers in [from, to], ordered by layer.
+func (l *LayerStats) Range(from, to types.LayerID) ([]*harestats.Stats, error) {
+	return harestats.Range(l.db, from, to)
+}
+
+// margin returns the largest total of votes for a single reference minus threshold.
+func margin(threshold uint16, tallies []refTally) int32 {
+	best := int32(0)
+	for _, tally := range tallies {
+		best = max(best, int32(tally.total))
+	}
+	return best - int32(threshold)
+}
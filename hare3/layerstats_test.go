@@ -0,0 +1,61 @@
+package hare3
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+func TestLayerStats(t *testing.T) {
+	t.Parallel()
+	tst := &tester{
+		TB:            t,
+		rng:           rand.New(rand.NewSource(1001)),
+		start:         time.Now(),
+		cfg:           DefaultConfig(),
+		layerDuration: 5 * time.Minute,
+		beacon:        types.Beacon{1, 1, 1, 1},
+		genesis:       types.GetEffectiveGenesis(),
+	}
+	tst.cfg.Stats.Layers = 2
+	cluster := newLockstepCluster(tst).addActive(2)
+
+	layer := tst.genesis + 1
+	cluster.setup()
+	cluster.genProposals(layer)
+	cluster.movePreround(layer)
+	for i := 0; i < 2*int(notify); i++ {
+		cluster.moveRound()
+	}
+	cluster.waitStopped()
+
+	stats := cluster.nodes[0].hare.LayerStats()
+	require.NotNil(t, stats)
+	got, err := stats.Get(layer)
+	require.NoError(t, err)
+	require.Equal(t, layer, got.Layer)
+	require.Equal(t, uint8(2), got.Iterations)
+	require.NotZero(t, got.Threshold)
+	require.NotZero(t, got.Preround[grade1-grade1])
+	require.GreaterOrEqual(t, got.CommitMargin, int32(0))
+	require.GreaterOrEqual(t, got.NotifyMargin, int32(0))
+
+	stats.onLayer(layer + 2)
+	all, err := stats.Range(layer, layer)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+
+	stats.onLayer(layer + 3)
+	_, err = stats.Get(layer)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+}
+
+func TestLayerStatsDisabled(t *testing.T) {
+	hr := New(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.Nil(t, hr.LayerStats())
+}
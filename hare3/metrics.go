@@ -9,6 +9,19 @@ import (
 const namespace = "hare"
 
 var (
+	configHash = metrics.NewGauge(
+		"config_hash",
+		namespace,
+		"set to 1 for the hash of the hare config that must be the same on all nodes, see Config.Hash",
+		[]string{"hash"},
+	)
+	configMismatches = metrics.NewCounter(
+		"config_mismatches",
+		namespace,
+		"number of peers with a different hash of the hare config, see ConfigExchange",
+		[]string{},
+	).WithLabelValues()
+
 	processCounter = metrics.NewCounter(
 		"session",
 		namespace,
@@ -19,6 +32,7 @@ var (
 	sessionTerminated = processCounter.WithLabelValues("terminated")
 	sessionCoin       = processCounter.WithLabelValues("weakcoin")
 	sessionResult     = processCounter.WithLabelValues("result")
+	sessionExpired    = processCounter.WithLabelValues("expired")
 
 	exitErrors = metrics.NewCounter(
 		"exit_errors",
@@ -36,6 +50,46 @@ var (
 	malformedError     = validationError.WithLabelValues("malformed")
 	signatureError     = validationError.WithLabelValues("signature")
 	oracleError        = validationError.WithLabelValues("oracle")
+	featuresError      = validationError.WithLabelValues("features")
+	overloadedError    = validationError.WithLabelValues("overloaded")
+
+	archiveDropped = metrics.NewCounter(
+		"archive_dropped",
+		namespace,
+		"number of validated messages that were not archived as the archive queue was full",
+		[]string{},
+	).WithLabelValues()
+
+	auditDivergence = metrics.NewHistogramWithBuckets(
+		"audit_divergence",
+		namespace,
+		"fraction of eligibilities in hare output that disagree with the block verified by tortoise",
+		[]string{},
+		prometheus.LinearBuckets(0, 0.1, 11),
+	).WithLabelValues()
+	auditDivergentLayers = metrics.NewCounter(
+		"audit_divergent_layers",
+		namespace,
+		"number of layers where hare output diverged from tortoise beyond threshold",
+		[]string{},
+	).WithLabelValues()
+
+	commitUnavailable = metrics.NewCounter(
+		"commit_unavailable",
+		namespace,
+		"number of commit messages that referenced proposals not available locally",
+		[]string{"outcome"},
+	)
+	commitSkipped = commitUnavailable.WithLabelValues("skipped")
+
+	refetch = metrics.NewCounter(
+		"refetch",
+		namespace,
+		"number of background fetches of gossiped proposals not available locally",
+		[]string{"outcome"},
+	)
+	refetchSucceeded = refetch.WithLabelValues("succeeded")
+	refetchFailed    = refetch.WithLabelValues("failed")
 
 	droppedMessages = metrics.NewCounter(
 		"dropped_msgs",
@@ -53,6 +107,8 @@ var (
 	)
 	oracleLatency = validationLatency.WithLabelValues("oracle")
 	submitLatency = validationLatency.WithLabelValues("submit")
+	// poolWaitLatency is the time messages wait for a worker of the handler pool.
+	poolWaitLatency = validationLatency.WithLabelValues("queued")
 
 	protocolLatency = metrics.NewHistogramWithBuckets(
 		"protocol_seconds",
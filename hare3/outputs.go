@@ -0,0 +1,54 @@
+package hare3
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/hareoutputs"
+)
+
+// OutputsConfig configures the record of agreed proposal sets.
+type OutputsConfig struct {
+	// Layers is the number of the most recent layers for which outputs are kept.
+	// Zero disables the record.
+	Layers uint32 `mapstructure:"layers"`
+}
+
+// Outputs persists the set of proposals agreed in every layer together with its hash in the local database,
+// so that the block generated for the layer can be verified against it after the fact.
+type Outputs struct {
+	logger *zap.Logger
+	config OutputsConfig
+	db     sql.LocalDatabase
+}
+
+func newOutputs(logger *zap.Logger, config OutputsConfig, db sql.LocalDatabase) *Outputs {
+	return &Outputs{logger: logger, config: config, db: db}
+}
+
+func (o *Outputs) record(lid types.LayerID, hash types.Hash32, proposals []types.ProposalID) {
+	err := hareoutputs.Set(o.db, &hareoutputs.Output{
+		Layer:     lid,
+		Hash:      hash,
+		Proposals: proposals,
+	})
+	if err != nil {
+		o.logger.Warn("failed to save output", zap.Uint32("lid", lid.Uint32()), zap.Error(err))
+	}
+}
+
+func (o *Outputs) onLayer(lid types.LayerID) {
+	if lid.Uint32() <= o.config.Layers {
+		return
+	}
+	if err := hareoutputs.DeleteBefore(o.db, lid.Sub(o.config.Layers)); err != nil {
+		o.logger.Warn("failed to prune outputs", zap.Uint32("lid", lid.Uint32()), zap.Error(err))
+	}
+}
+
+// Get returns the output of the layer. Returns sql.ErrNotFound if hare didn't terminate
+// in the layer or the output was already pruned.
+func (o *Outputs) Get(lid types.LayerID) (*hareoutputs.Output, error) {
+	return hareoutputs.Get(o.db, lid)
+}
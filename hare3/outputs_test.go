@@ -0,0 +1,60 @@
+package hare3
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+func TestOutputs(t *testing.T) {
+	t.Parallel()
+	tst := &tester{
+		TB:            t,
+		rng:           rand.New(rand.NewSource(1001)),
+		start:         time.Now(),
+		cfg:           DefaultConfig(),
+		layerDuration: 5 * time.Minute,
+		beacon:        types.Beacon{1, 1, 1, 1},
+		genesis:       types.GetEffectiveGenesis(),
+	}
+	tst.cfg.Outputs.Layers = 2
+	cluster := newLockstepCluster(tst).addActive(2)
+
+	layer := tst.genesis + 1
+	cluster.setup()
+	cluster.genProposals(layer)
+	cluster.movePreround(layer)
+	for i := 0; i < 2*int(notify); i++ {
+		cluster.moveRound()
+	}
+	cluster.waitStopped()
+
+	hr := cluster.nodes[0].hare
+	result := <-hr.Results()
+	outputs := hr.Outputs()
+	require.NotNil(t, outputs)
+	got, err := outputs.Get(layer)
+	require.NoError(t, err)
+	require.Equal(t, layer, got.Layer)
+	require.Equal(t, result.Proposals, got.Proposals)
+	require.Equal(t, result.Hash, got.Hash)
+	require.Equal(t, types.CalcProposalHash32Presorted(got.Proposals, nil), got.Hash)
+
+	outputs.onLayer(layer + 2)
+	_, err = outputs.Get(layer)
+	require.NoError(t, err)
+
+	outputs.onLayer(layer + 3)
+	_, err = outputs.Get(layer)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+}
+
+func TestOutputsDisabled(t *testing.T) {
+	hr := New(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.Nil(t, hr.Outputs())
+}
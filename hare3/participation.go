@@ -0,0 +1,137 @@
+package hare3
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+)
+
+// ParticipationReason explains the first layer in which a registered signer participates.
+type ParticipationReason string
+
+const (
+	// ParticipationRunning is reported for a signer that participates in a running session.
+	ParticipationRunning ParticipationReason = "running"
+	// ParticipationPreround is reported for a signer that was registered after the session
+	// started, but before preround delay passed. It joins preround of that session.
+	ParticipationPreround ParticipationReason = "preround"
+	// ParticipationSessionStarted is reported for a signer that was registered after preround
+	// of the current session. It participates starting from the next layer.
+	ParticipationSessionStarted ParticipationReason = "session started"
+	// ParticipationNextLayer is reported for a signer that was registered when no session was running.
+	ParticipationNextLayer ParticipationReason = "next layer"
+	// ParticipationEligibilityUnknown is reported for a signer without an atx targeting the epoch
+	// of the layer, so it is not known yet whether it will be eligible there.
+	ParticipationEligibilityUnknown ParticipationReason = "eligibility unknown"
+)
+
+// Participation is the first layer in which a registered signer participates in hare.
+type Participation struct {
+	NodeID types.NodeID
+	Layer  types.LayerID
+	Reason ParticipationReason
+}
+
+// Participation reports for every registered signer the first layer in which it participates
+// in hare and why.
+func (h *Hare) Participation() ([]Participation, error) {
+	h.mu.Lock()
+	next := h.nodeClock.CurrentLayer() + 1
+	rst := make([]Participation, 0, len(h.signers))
+	for _, signer := range h.signers {
+		id := signer.NodeID()
+		p := Participation{NodeID: id, Layer: next, Reason: ParticipationNextLayer}
+		joined := false
+		for lid, s := range h.running {
+			switch {
+			case s.hasSigner(id) && (!joined || lid < p.Layer):
+				joined = true
+				p.Layer, p.Reason = lid, ParticipationRunning
+				if s.joinable {
+					p.Reason = ParticipationPreround
+				}
+			case !joined && !s.hasSigner(id):
+				p.Layer, p.Reason = max(p.Layer, lid+1), ParticipationSessionStarted
+			}
+		}
+		rst = append(rst, p)
+	}
+	h.mu.Unlock()
+
+	for i := range rst {
+		p := &rst[i]
+		if p.Reason == ParticipationRunning || p.Reason == ParticipationPreround {
+			continue
+		}
+		_, err := atxs.GetIDByEpochAndNodeID(h.db, p.Layer.GetEpoch()-1, p.NodeID)
+		switch {
+		case errors.Is(err, sql.ErrNotFound):
+			p.Reason = ParticipationEligibilityUnknown
+		case err != nil:
+			return nil, fmt.Errorf("get atx of %s: %w", p.NodeID.ShortString(), err)
+		}
+	}
+	return rst, nil
+}
+
+// join adds signers that were registered while the session was waiting for preround delay.
+// Returns true if any of them is eligible in the round.
+func (h *Hare) join(s *session, ir IterRound) bool {
+	h.mu.Lock()
+	joining := s.joining
+	s.joinable = false
+	s.joining = nil
+	h.mu.Unlock()
+
+	active := false
+	for _, signer := range joining {
+		vrf := h.oracle.active(signer, s.beacon, s.lid, ir)
+		h.mu.Lock()
+		s.signers = append(s.signers, signer)
+		h.mu.Unlock()
+		s.vrfs = append(s.vrfs, vrf)
+		active = active || vrf != nil
+	}
+	if len(joining) > 0 {
+		h.log.Debug("signers joined preround",
+			zap.Uint32("lid", s.lid.Uint32()),
+			zap.Int("joined", len(joining)),
+			zap.Bool("active", active),
+		)
+	}
+	return active
+}
+
+// leave removes signers that were unregistered while the session was running, so that they don't
+// produce messages in the round that is about to be executed. Returns true if any signer left.
+func (h *Hare) leave(s *session) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(s.leaving) == 0 {
+		return false
+	}
+	for i := 0; i < len(s.signers); {
+		id := s.signers[i].NodeID()
+		if _, ok := s.leaving[id]; !ok {
+			i++
+			continue
+		}
+		s.signers = slices.Delete(s.signers, i, i+1)
+		s.vrfs = slices.Delete(s.vrfs, i, i+1)
+		h.log.Info("unregistered signer left session",
+			log.ZShortStringer("id", id),
+			zap.Uint32("lid", s.lid.Uint32()),
+			zap.Uint8("iter", s.proto.Iter),
+			zap.Stringer("round", s.proto.Round),
+		)
+	}
+	s.leaving = nil
+	return true
+}
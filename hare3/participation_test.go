@@ -0,0 +1,141 @@
+package hare3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/spacemeshos/go-spacemesh/atxsdata"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestParticipation(t *testing.T) {
+	db := statesql.InMemoryTest(t)
+	nclock := &testNodeClock{genesis: time.Now(), layerDuration: time.Minute}
+	hr := New(nclock, nil, db, atxsdata.New(), nil, nil, nil, nil, nil)
+
+	layer := types.GetEffectiveGenesis() + 1
+	nclock.StartLayer(layer)
+
+	newSigner := func(atx bool) *signing.EdSigner {
+		signer, err := signing.NewEdSigner()
+		require.NoError(t, err)
+		if atx {
+			atx := &types.ActivationTx{
+				PublishEpoch: layer.GetEpoch() - 1,
+				NumUnits:     1,
+				TickCount:    1,
+				Weight:       1,
+				SmesherID:    signer.NodeID(),
+			}
+			atx.SetID(types.RandomATXID())
+			require.NoError(t, atxs.Add(db, atx, types.AtxBlob{}))
+		}
+		return signer
+	}
+
+	running := newSigner(true)
+	hr.Register(running)
+	started := &session{lid: layer, signers: []*signing.EdSigner{running}, joinable: true}
+	hr.running[layer] = started
+
+	late := newSigner(true)
+	hr.Register(late)
+	require.Equal(t, []*signing.EdSigner{late}, started.joining)
+	rst, err := hr.Participation()
+	require.NoError(t, err)
+	require.Contains(t, rst, Participation{NodeID: late.NodeID(), Layer: layer, Reason: ParticipationPreround})
+
+	// session passed preround delay
+	hr.mu.Lock()
+	started.joinable = false
+	hr.mu.Unlock()
+	tooLate := newSigner(true)
+	hr.Register(tooLate)
+	noAtx := newSigner(false)
+	hr.Register(noAtx)
+	require.Equal(t, []*signing.EdSigner{late}, started.joining)
+
+	rst, err = hr.Participation()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Participation{
+		{NodeID: running.NodeID(), Layer: layer, Reason: ParticipationRunning},
+		{NodeID: late.NodeID(), Layer: layer, Reason: ParticipationRunning},
+		{NodeID: tooLate.NodeID(), Layer: layer + 1, Reason: ParticipationSessionStarted},
+		{NodeID: noAtx.NodeID(), Layer: layer + 1, Reason: ParticipationEligibilityUnknown},
+	}, rst)
+
+	delete(hr.running, layer)
+	rst, err = hr.Participation()
+	require.NoError(t, err)
+	require.Contains(t, rst, Participation{NodeID: running.NodeID(), Layer: layer + 1, Reason: ParticipationNextLayer})
+}
+
+func TestUnregister(t *testing.T) {
+	nclock := &testNodeClock{genesis: time.Now(), layerDuration: time.Minute}
+	core, logs := observer.New(zapcore.InfoLevel)
+	hr := New(nclock, nil, statesql.InMemoryTest(t), atxsdata.New(), nil, nil, nil, nil, nil,
+		WithLogger(zap.New(core)),
+	)
+	layer := types.GetEffectiveGenesis() + 1
+	nclock.StartLayer(layer)
+
+	signers := make([]*signing.EdSigner, 3)
+	for i := range signers {
+		signer, err := signing.NewEdSigner()
+		require.NoError(t, err)
+		signers[i] = signer
+	}
+	hr.Register(signers[0])
+	hr.Register(signers[1])
+	remaining := &types.HareEligibility{Count: 2}
+	started := &session{
+		lid:      layer,
+		signers:  []*signing.EdSigner{signers[0], signers[1]},
+		vrfs:     []*types.HareEligibility{{Count: 1}, remaining},
+		proto:    newProtocol(1),
+		joinable: true,
+	}
+	hr.running[layer] = started
+	hr.Register(signers[2])
+	require.Equal(t, []*signing.EdSigner{signers[2]}, started.joining)
+
+	// signer that didn't join yet is dropped immediately
+	require.True(t, hr.Unregister(signers[2].NodeID()))
+	require.Empty(t, started.joining)
+	require.Empty(t, started.leaving)
+	require.False(t, hr.leave(started))
+
+	// running signer leaves at the next round boundary
+	hr.Unregister(signers[0].NodeID())
+	require.Len(t, started.signers, 2)
+	rst, err := hr.Participation()
+	require.NoError(t, err)
+	require.Len(t, rst, 1)
+	require.Equal(t, signers[1].NodeID(), rst[0].NodeID)
+	audit := logs.FilterMessage("unregistered signing key").All()
+	require.Len(t, audit, 2)
+	require.Equal(t, []any{layer.Uint32()}, audit[1].ContextMap()["leaving_sessions"])
+
+	require.True(t, hr.leave(started))
+	require.Equal(t, []*signing.EdSigner{signers[1]}, started.signers)
+	require.Equal(t, []*types.HareEligibility{remaining}, started.vrfs)
+	require.Equal(t, 1, logs.FilterMessage("unregistered signer left session").Len())
+	require.False(t, hr.leave(started))
+
+	// registering again cancels the removal
+	hr.Unregister(signers[1].NodeID())
+	hr.Register(signers[1])
+	require.False(t, hr.leave(started))
+	require.Equal(t, []*signing.EdSigner{signers[1]}, started.signers)
+
+	require.False(t, hr.Unregister(types.RandomNodeID()))
+	require.Equal(t, 1, logs.FilterMessage("unregistering unknown signing key").Len())
+}
@@ -0,0 +1,162 @@
+package hare3
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+var errOverloaded = errors.New("handler queue is full")
+
+// HandlerConfig configures the pool that runs the expensive part of message validation:
+// signature verification, oracle validation and the input to the session.
+type HandlerConfig struct {
+	// Workers is the number of messages validated concurrently. Zero validates messages inline
+	// in the gossip validator.
+	Workers int `mapstructure:"workers"`
+	// Queue is the maximal number of messages of a single layer waiting for a worker.
+	// Messages over it are ignored without penalizing the peer.
+	Queue int `mapstructure:"queue"`
+}
+
+// DefaultHandlerConfig returns the default handler pool config.
+func DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{
+		Workers: 8,
+		Queue:   1000,
+	}
+}
+
+type task struct {
+	run      func() error
+	queued   time.Time
+	done     chan error
+	canceled bool // guarded by handlerPool.mu
+}
+
+// handlerPool runs tasks with a bounded number of workers. Tasks are queued by layer and
+// workers take them from the layers in turn, so that a flood of messages for one layer
+// doesn't delay messages of other layers.
+type handlerPool struct {
+	cfg HandlerConfig
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queues map[types.LayerID][]*task
+	// layers with queued tasks in the order they are served.
+	layers []types.LayerID
+	next   int
+	closed bool
+}
+
+func newHandlerPool(cfg HandlerConfig) *handlerPool {
+	p := &handlerPool{
+		cfg:    cfg,
+		queues: make(map[types.LayerID][]*task),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// run executes tasks until the context is canceled.
+func (p *handlerPool) run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range p.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work()
+		}()
+	}
+	<-ctx.Done()
+	p.mu.Lock()
+	p.closed = true
+	for _, queue := range p.queues {
+		for _, t := range queue {
+			t.done <- ctx.Err()
+		}
+	}
+	clear(p.queues)
+	p.layers = nil
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	wg.Wait()
+	return nil
+}
+
+func (p *handlerPool) work() {
+	for {
+		t := p.take()
+		if t == nil {
+			return
+		}
+		poolWaitLatency.Observe(time.Since(t.queued).Seconds())
+		t.done <- t.run()
+	}
+}
+
+// take blocks until there is a task to run, it returns nil if the pool is closed.
+func (p *handlerPool) take() *task {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if p.closed {
+			return nil
+		}
+		for len(p.layers) > 0 {
+			if p.next >= len(p.layers) {
+				p.next = 0
+			}
+			layer := p.layers[p.next]
+			queue := p.queues[layer]
+			t := queue[0]
+			queue[0] = nil
+			if len(queue) == 1 {
+				delete(p.queues, layer)
+				p.layers = append(p.layers[:p.next], p.layers[p.next+1:]...)
+			} else {
+				p.queues[layer] = queue[1:]
+				p.next++
+			}
+			if !t.canceled {
+				return t
+			}
+		}
+		p.cond.Wait()
+	}
+}
+
+// submit queues the task for the layer and waits for its result. It fails right away
+// with errOverloaded if the queue of the layer is full.
+func (p *handlerPool) submit(ctx context.Context, layer types.LayerID, run func() error) error {
+	t := &task{run: run, queued: time.Now(), done: make(chan error, 1)}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return context.Canceled
+	}
+	queue := p.queues[layer]
+	if len(queue) >= p.cfg.Queue {
+		p.mu.Unlock()
+		return errOverloaded
+	}
+	if len(queue) == 0 {
+		p.layers = append(p.layers, layer)
+	}
+	p.queues[layer] = append(queue, t)
+	p.cond.Signal()
+	p.mu.Unlock()
+
+	select {
+	case err := <-t.done:
+		return err
+	case <-ctx.Done():
+		p.mu.Lock()
+		t.canceled = true
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+}
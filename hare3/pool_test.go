@@ -0,0 +1,134 @@
+package hare3
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func startPool(t *testing.T, cfg HandlerConfig) (*handlerPool, context.CancelFunc) {
+	pool := newHandlerPool(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return pool.run(ctx)
+	})
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, eg.Wait())
+	})
+	return pool, cancel
+}
+
+func queued(pool *handlerPool) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	n := 0
+	for _, queue := range pool.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+// blockPool occupies the single worker of the pool until the returned function is called.
+func blockPool(t *testing.T, pool *handlerPool, eg *errgroup.Group) func() {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	eg.Go(func() error {
+		return pool.submit(context.Background(), 0, func() error {
+			close(started)
+			<-unblock
+			return nil
+		})
+	})
+	<-started
+	return func() { close(unblock) }
+}
+
+func TestHandlerPool(t *testing.T) {
+	t.Run("result", func(t *testing.T) {
+		pool, _ := startPool(t, HandlerConfig{Workers: 2, Queue: 10})
+		expected := errors.New("test")
+		require.ErrorIs(t, pool.submit(context.Background(), 1, func() error { return expected }), expected)
+		require.NoError(t, pool.submit(context.Background(), 1, func() error { return nil }))
+	})
+	t.Run("fair across layers", func(t *testing.T) {
+		pool, _ := startPool(t, HandlerConfig{Workers: 1, Queue: 10})
+		var eg errgroup.Group
+		unblock := blockPool(t, pool, &eg)
+
+		var mu sync.Mutex
+		var order []types.LayerID
+		submit := func(layer types.LayerID) {
+			n := queued(pool)
+			eg.Go(func() error {
+				return pool.submit(context.Background(), layer, func() error {
+					mu.Lock()
+					defer mu.Unlock()
+					order = append(order, layer)
+					return nil
+				})
+			})
+			require.Eventually(t, func() bool { return queued(pool) == n+1 }, time.Second, time.Millisecond)
+		}
+		for _, layer := range []types.LayerID{1, 1, 1, 2, 3} {
+			submit(layer)
+		}
+		unblock()
+		require.NoError(t, eg.Wait())
+		require.Equal(t, []types.LayerID{1, 2, 3, 1, 1}, order)
+	})
+	t.Run("overloaded", func(t *testing.T) {
+		pool, _ := startPool(t, HandlerConfig{Workers: 1, Queue: 1})
+		var eg errgroup.Group
+		unblock := blockPool(t, pool, &eg)
+		eg.Go(func() error {
+			return pool.submit(context.Background(), 1, func() error { return nil })
+		})
+		require.Eventually(t, func() bool { return queued(pool) == 1 }, time.Second, time.Millisecond)
+		require.ErrorIs(t, pool.submit(context.Background(), 1, func() error { return nil }), errOverloaded)
+		// other layers have their own queue
+		eg.Go(func() error {
+			return pool.submit(context.Background(), 2, func() error { return nil })
+		})
+		require.Eventually(t, func() bool { return queued(pool) == 2 }, time.Second, time.Millisecond)
+		unblock()
+		require.NoError(t, eg.Wait())
+	})
+	t.Run("canceled", func(t *testing.T) {
+		pool, _ := startPool(t, HandlerConfig{Workers: 1, Queue: 10})
+		var eg errgroup.Group
+		unblock := blockPool(t, pool, &eg)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, pool.submit(ctx, 1, func() error {
+			t.Error("canceled task must not run")
+			return nil
+		}), context.Canceled)
+		unblock()
+		require.NoError(t, eg.Wait())
+		require.NoError(t, pool.submit(context.Background(), 1, func() error { return nil }))
+	})
+	t.Run("closed", func(t *testing.T) {
+		pool, stop := startPool(t, HandlerConfig{Workers: 1, Queue: 10})
+		var eg errgroup.Group
+		unblock := blockPool(t, pool, &eg)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- pool.submit(context.Background(), 1, func() error { return nil })
+		}()
+		require.Eventually(t, func() bool { return queued(pool) == 1 }, time.Second, time.Millisecond)
+		stop()
+		require.ErrorIs(t, <-errs, context.Canceled)
+		unblock()
+		require.NoError(t, eg.Wait())
+		require.ErrorIs(t, pool.submit(context.Background(), 1, func() error { return nil }), context.Canceled)
+	})
+}
@@ -262,6 +262,28 @@ func (p *protocol) execution(out *output) {
 	}
 }
 
+// ValidProposals returns the set of proposals with the reference, if it is known.
+func (p *protocol) ValidProposals(ref types.Hash32) ([]types.ProposalID, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	values, exist := p.validProposals[ref]
+	return values, exist
+}
+
+// Candidates returns proposals from preround messages that any committed set must be a subset of.
+func (p *protocol) Candidates() []types.ProposalID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.gossip.thresholdGossip(IterRound{Round: preround}, grade2)
+}
+
+// Current returns the iteration and round that will be executed next.
+func (p *protocol) Current() IterRound {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.IterRound
+}
+
 func (p *protocol) Next() output {
 	p.mu.Lock()
 	defer p.mu.Unlock()
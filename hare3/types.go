@@ -1,9 +1,12 @@
 package hare3
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 
+	"github.com/spacemeshos/go-scale"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/spacemeshos/go-spacemesh/codec"
@@ -20,6 +23,16 @@ func (r Round) String() string {
 	return roundNames[r]
 }
 
+// ParseRound returns the round with the name.
+func ParseRound(name string) (Round, error) {
+	for i, rname := range roundNames {
+		if rname == name {
+			return Round(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown round %q", name)
+}
+
 // NOTE(dshulyak) changes in order is a breaking change.
 const (
 	preround Round = iota
@@ -32,7 +45,7 @@ const (
 	notify
 )
 
-//go:generate scalegen
+//go:generate scalegen -types IterRound,Value,Body
 
 type IterRound struct {
 	Iter  uint8
@@ -100,16 +113,87 @@ type Body struct {
 	Eligibility types.HareEligibility
 }
 
+// Features are bits of protocol features that the sender of a message follows. They coordinate
+// activation of changes in the protocol at a layer, without changing the protocol name.
+type Features uint64
+
+// Has returns true if all features in other are set.
+func (f Features) Has(other Features) bool {
+	return f&other == other
+}
+
 type Message struct {
 	Body
 	Sender    types.NodeID
 	Signature types.EdSignature
+	// Features are encoded after the signature only if any bit is set, so that messages without
+	// features are encoded as before. They are covered by the signature as part of the message hash.
+	Features Features
+}
+
+func (m *Message) EncodeScale(enc *scale.Encoder) (total int, err error) {
+	n, err := m.Body.EncodeScale(enc)
+	if err != nil {
+		return total, err
+	}
+	total += n
+	n, err = scale.EncodeByteArray(enc, m.Sender[:])
+	if err != nil {
+		return total, err
+	}
+	total += n
+	n, err = scale.EncodeByteArray(enc, m.Signature[:])
+	if err != nil {
+		return total, err
+	}
+	total += n
+	if m.Features == 0 {
+		return total, nil
+	}
+	n, err = scale.EncodeCompact64(enc, uint64(m.Features))
+	if err != nil {
+		return total, err
+	}
+	return total + n, nil
+}
+
+func (m *Message) DecodeScale(dec *scale.Decoder) (total int, err error) {
+	n, err := m.Body.DecodeScale(dec)
+	if err != nil {
+		return total, err
+	}
+	total += n
+	n, err = scale.DecodeByteArray(dec, m.Sender[:])
+	if err != nil {
+		return total, err
+	}
+	total += n
+	n, err = scale.DecodeByteArray(dec, m.Signature[:])
+	if err != nil {
+		return total, err
+	}
+	total += n
+	features, n, err := scale.DecodeCompact64(dec)
+	switch {
+	case n == 0 && errors.Is(err, io.EOF):
+		// message without features
+		return total, nil
+	case err != nil:
+		return total, err
+	case features == 0:
+		return total, errors.New("features are encoded but not set")
+	}
+	m.Features = Features(features)
+	return total + n, nil
 }
 
 func (m *Message) ToHash() types.Hash32 {
 	h := hash.GetHasher()
 	defer hash.PutHasher(h)
 	codec.MustEncodeTo(h, &m.Body)
+	if m.Features != 0 {
+		h.Write(binary.LittleEndian.AppendUint64(nil, uint64(m.Features)))
+	}
 	var rst types.Hash32
 	h.Sum(rst[:0])
 	return rst
@@ -167,5 +251,8 @@ func (m *Message) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 		encoder.AddString("ref", m.Value.Reference.ShortString())
 	}
 	encoder.AddUint16("vrf_count", m.Eligibility.Count)
+	if m.Features != 0 {
+		encoder.AddUint64("features", uint64(m.Features))
+	}
 	return nil
 }
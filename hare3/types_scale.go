@@ -148,53 +148,3 @@ func (t *Body) DecodeScale(dec *scale.Decoder) (total int, err error) {
 	}
 	return total, nil
 }
-
-func (t *Message) EncodeScale(enc *scale.Encoder) (total int, err error) {
-	{
-		n, err := t.Body.EncodeScale(enc)
-		if err != nil {
-			return total, err
-		}
-		total += n
-	}
-	{
-		n, err := scale.EncodeByteArray(enc, t.Sender[:])
-		if err != nil {
-			return total, err
-		}
-		total += n
-	}
-	{
-		n, err := scale.EncodeByteArray(enc, t.Signature[:])
-		if err != nil {
-			return total, err
-		}
-		total += n
-	}
-	return total, nil
-}
-
-func (t *Message) DecodeScale(dec *scale.Decoder) (total int, err error) {
-	{
-		n, err := t.Body.DecodeScale(dec)
-		if err != nil {
-			return total, err
-		}
-		total += n
-	}
-	{
-		n, err := scale.DecodeByteArray(dec, t.Sender[:])
-		if err != nil {
-			return total, err
-		}
-		total += n
-	}
-	{
-		n, err := scale.DecodeByteArray(dec, t.Signature[:])
-		if err != nil {
-			return total, err
-		}
-		total += n
-	}
-	return total, nil
-}
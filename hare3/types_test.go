@@ -23,6 +23,31 @@ func TestMessageMarshall(t *testing.T) {
 	require.NoError(t, msg.MarshalLogObject(enc))
 }
 
+func TestMessageFeatures(t *testing.T) {
+	msg := &Message{Body: Body{Layer: 10, Value: Value{Proposals: []types.ProposalID{{1}}}}}
+	msg.Sender = types.RandomNodeID()
+	legacy := codec.MustEncode(&msg.Body)
+	legacy = append(legacy, msg.Sender[:]...)
+	legacy = append(legacy, msg.Signature[:]...)
+	require.Equal(t, legacy, msg.ToBytes(), "messages without features are encoded as before")
+	hash := msg.ToHash()
+
+	var decoded Message
+	require.NoError(t, codec.Decode(legacy, &decoded))
+	require.Equal(t, msg, &decoded)
+
+	msg.Features = 1<<3 | 1<<40
+	require.Equal(t, legacy, msg.ToBytes()[:len(legacy)])
+	require.NotEqual(t, hash, msg.ToHash())
+	decoded = Message{}
+	require.NoError(t, codec.Decode(msg.ToBytes(), &decoded))
+	require.Equal(t, msg, &decoded)
+	require.True(t, decoded.Features.Has(1<<3))
+	require.False(t, decoded.Features.Has(1<<4))
+
+	require.Error(t, codec.Decode(append(legacy, 0), &decoded), "features are set explicitly to zero")
+}
+
 func FuzzMessageDecode(f *testing.F) {
 	for _, buf := range [][]byte{
 		{},
@@ -0,0 +1,72 @@
+package hare3
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+var errSessionExpired = errors.New("session exceeded expected lifetime")
+
+// sessionDeadline returns the time after which the session for the layer is expected to be terminated.
+// Sessions terminate at the latest in the first round of the iteration that reaches the limit.
+func (h *Hare) sessionDeadline(lid types.LayerID) time.Time {
+	last := h.config.roundStart(IterRound{Iter: h.config.IterationsLimit, Round: hardlock})
+	return h.nodeClock.LayerToTime(lid).Add(last + h.config.WatchdogSlack)
+}
+
+// watchdog terminates sessions that live longer than expected every round, until hare is stopped.
+func (h *Hare) watchdog() error {
+	ticker := h.wallClock.NewTicker(h.config.RoundDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.ctx.Done():
+			return nil
+		case <-ticker.Chan():
+			h.expireSessions(h.wallClock.Now())
+		}
+	}
+}
+
+// expireSessions terminates sessions that are past the deadline. Such sessions are removed
+// from hare and layer patrol is notified that hare failed, without waiting for the session
+// goroutine to exit, as it may be hung.
+func (h *Hare) expireSessions(now time.Time) {
+	h.mu.Lock()
+	var (
+		expired []*session
+		signers []int
+	)
+	for lid, s := range h.running {
+		if now.Before(h.sessionDeadline(lid)) {
+			continue
+		}
+		s.expired = true
+		s.cancel()
+		delete(h.sessions, lid)
+		delete(h.running, lid)
+		expired = append(expired, s)
+		// signers of the session are modified under the lock when they join or leave
+		signers = append(signers, len(s.signers))
+	}
+	running := len(h.running)
+	h.mu.Unlock()
+
+	for i, s := range expired {
+		current := s.proto.Current()
+		h.log.Error("terminated session that exceeded expected lifetime",
+			zap.Uint32("lid", s.lid.Uint32()),
+			zap.Uint8("iter", current.Iter),
+			zap.Stringer("round", current.Round),
+			zap.Time("deadline", h.sessionDeadline(s.lid)),
+			zap.Int("signers", signers[i]),
+			zap.Int("running", running),
+		)
+		sessionExpired.Inc()
+		h.onStopped(s.lid, current.Iter, errSessionExpired)
+	}
+}
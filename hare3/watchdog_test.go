@@ -0,0 +1,96 @@
+package hare3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/layerpatrol"
+	"github.com/spacemeshos/go-spacemesh/proposals/store"
+)
+
+func TestWatchdog_ExpireSessions(t *testing.T) {
+	cfg := DefaultConfig()
+	patrol := layerpatrol.New()
+	hare := New(
+		&testNodeClock{genesis: time.Now(), layerDuration: 5 * time.Minute},
+		nil, nil, nil, store.New(), nil, nil, nil,
+		patrol,
+		WithConfig(cfg),
+		WithLogger(zaptest.NewLogger(t)),
+	)
+
+	stale, fresh := types.LayerID(10), types.LayerID(11)
+	sessions := map[types.LayerID]*session{}
+	for _, lid := range []types.LayerID{stale, fresh} {
+		ctx, cancel := context.WithCancel(context.Background())
+		s := &session{ctx: ctx, cancel: cancel, lid: lid, proto: newProtocol(1)}
+		sessions[lid] = s
+		hare.sessions[lid] = s.proto
+		hare.running[lid] = s
+		patrol.SetHareInCharge(lid)
+	}
+
+	deadline := hare.sessionDeadline(stale)
+	hare.expireSessions(deadline.Add(-time.Second))
+	require.Equal(t, 2, hare.Running())
+	require.NoError(t, sessions[stale].ctx.Err())
+
+	hare.expireSessions(deadline)
+	require.Equal(t, 1, hare.Running())
+	require.Contains(t, hare.running, fresh)
+	require.NotContains(t, hare.sessions, stale)
+	require.True(t, sessions[stale].expired)
+	require.ErrorIs(t, sessions[stale].ctx.Err(), context.Canceled)
+	require.NoError(t, sessions[fresh].ctx.Err())
+
+	require.False(t, patrol.IsHareInCharge(stale))
+	require.True(t, patrol.IsHareInCharge(fresh))
+	status, ok := patrol.Status(stale)
+	require.True(t, ok)
+	require.Equal(t, layerpatrol.ReasonHareFailed, status.Reason)
+	require.Equal(t, errSessionExpired.Error(), status.Error)
+}
+
+func TestWatchdog_HungSession(t *testing.T) {
+	patrol := layerpatrol.New()
+	tracer := newTestTracer(t)
+	hare := New(
+		&testNodeClock{genesis: time.Now(), layerDuration: 5 * time.Minute},
+		nil, nil, nil, store.New(), nil, nil, nil,
+		patrol,
+		WithConfig(DefaultConfig()),
+		WithLogger(zaptest.NewLogger(t)),
+		WithTracer(tracer),
+	)
+
+	lid := types.LayerID(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &session{ctx: ctx, cancel: cancel, lid: lid, proto: newProtocol(1)}
+	hare.sessions[lid] = s.proto
+	hare.running[lid] = s
+	patrol.SetHareInCharge(lid)
+
+	terminated := testutil.ToFloat64(sessionTerminated)
+	exits := testutil.ToFloat64(exitErrors)
+	deadline := hare.sessionDeadline(lid)
+	hare.expireSessions(deadline)
+	hare.expireSessions(deadline.Add(time.Minute))
+	require.Zero(t, hare.Running())
+	require.Equal(t, lid, tracer.waitStopped())
+
+	// the hung session exits eventually, after it was expired
+	hare.exitSession(s, context.Canceled)
+	require.Empty(t, tracer.stopped)
+	require.Equal(t, terminated+1, testutil.ToFloat64(sessionTerminated))
+	require.Equal(t, exits, testutil.ToFloat64(exitErrors))
+	status, ok := patrol.Status(lid)
+	require.True(t, ok)
+	require.Equal(t, layerpatrol.ReasonHareFailed, status.Reason)
+	require.Equal(t, errSessionExpired.Error(), status.Error)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/atxsdata"
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
 	"github.com/spacemeshos/go-spacemesh/layerpatrol"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/metrics"
@@ -136,6 +137,9 @@ func DefaultConfig() Config {
 type ConsensusOutput struct {
 	Layer     types.LayerID
 	Proposals []types.ProposalID
+	// Hash of the sorted proposals, the reference that hare agreed on.
+	// It is empty if the output was not produced by hare, e.g. in tests.
+	Hash types.Hash32
 }
 
 type WeakCoinOutput struct {
@@ -171,6 +175,13 @@ func WithLogger(logger *zap.Logger) Opt {
 	}
 }
 
+// WithOracle overrides the eligibility oracle passed to New, e.g. with an experimental implementation.
+func WithOracle(oracle eligibility.Rolacle) Opt {
+	return func(hr *Hare) {
+		hr.oracle.oracle = oracle
+	}
+}
+
 func WithTracer(tracer Tracer) Opt {
 	return func(hr *Hare) {
 		hr.tracer = tracer
@@ -198,7 +209,7 @@ func New(
 	atxsdata *atxsdata.Data,
 	proposals *store.Store,
 	verifier verifier,
-	oracle oracle,
+	oracle eligibility.Rolacle,
 	sync system.SyncStateProvider,
 	patrol *layerpatrol.LayerPatrol,
 	host server.Host,
@@ -281,6 +292,27 @@ func (h *Hare) Register(sig *signing.EdSigner) {
 	h.signers[string(sig.NodeID().Bytes())] = sig
 }
 
+// Unregister removes the signing key of the identity. The signer doesn't join future sessions,
+// and running sessions stop producing messages for it. Returns false if the key is not registered.
+func (h *Hare) Unregister(id types.NodeID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, exists := h.signers[string(id.Bytes())]; !exists {
+		h.log.Warn("unregistering unknown signing key", log.ZShortStringer("id", id))
+		return false
+	}
+	delete(h.signers, string(id.Bytes()))
+	h.log.Info("unregistered signing key", log.ZShortStringer("id", id))
+	return true
+}
+
+func (h *Hare) registered(id types.NodeID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, exists := h.signers[string(id.Bytes())]
+	return exists
+}
+
 func (h *Hare) Results() <-chan ConsensusOutput {
 	return h.results
 }
@@ -569,6 +601,7 @@ func (h *Hare) onLayer(layer types.LayerID) {
 	h.proposals.OnLayer(layer)
 	if !h.sync.IsSynced(h.ctx) {
 		h.log.Debug("not synced", zap.Uint32("lid", layer.Uint32()))
+		h.patrol.SetWaiting(layer, layerpatrol.ReasonNotSynced)
 		return
 	}
 	beacon, err := beacons.Get(h.db, layer.GetEpoch())
@@ -578,6 +611,7 @@ func (h *Hare) onLayer(layer types.LayerID) {
 			zap.Uint32("lid", layer.Uint32()),
 			zap.Error(err),
 		)
+		h.patrol.SetWaiting(layer, layerpatrol.ReasonBeaconMissing)
 		return
 	}
 	h.patrol.SetHareInCharge(layer)
@@ -606,7 +640,7 @@ func (h *Hare) onLayer(layer types.LayerID) {
 			exitErrors.Inc()
 			// if terminated successfully it will notify block generator
 			// and it will have to CompleteHare
-			h.patrol.CompleteHare(layer)
+			h.patrol.HareFailed(layer, s.proto.Iter, err)
 		} else {
 			h.log.Debug("terminated",
 				zap.Uint32("lid", layer.Uint32()),
@@ -709,6 +743,10 @@ func (h *Hare) onOutput(session *session, ir IterRound, out output) error {
 		if vrf == nil || out.message == nil {
 			continue
 		}
+		if !h.registered(session.signers[i].NodeID()) {
+			// signer was unregistered while the session was running
+			continue
+		}
 		msg := *out.message // shallow copy
 		msg.Layer = session.lid
 		msg.Eligibility = *vrf
@@ -753,7 +791,7 @@ func (h *Hare) onOutput(session *session, ir IterRound, out output) error {
 		select {
 		case <-h.ctx.Done():
 			return h.ctx.Err()
-		case h.results <- ConsensusOutput{Layer: session.lid, Proposals: out.result}:
+		case h.results <- ConsensusOutput{Layer: session.lid, Proposals: out.result, Hash: toHash(out.result)}:
 		}
 		sessionResult.Inc()
 	}
@@ -1111,6 +1111,39 @@ func TestHare_AddProposal(t *testing.T) {
 	require.ErrorIs(t, hare.OnProposal(p), store.ErrProposalExists)
 }
 
+func TestHare_Unregister(t *testing.T) {
+	t.Parallel()
+	publisher := pmocks.NewMockPublishSubscriber(gomock.NewController(t))
+	hare := New(nil, publisher, nil, nil, nil, nil, nil, nil, nil, nil)
+	signers := make([]*signing.EdSigner, 2)
+	for i := range signers {
+		signer, err := signing.NewEdSigner()
+		require.NoError(t, err)
+		signers[i] = signer
+		hare.Register(signer)
+	}
+	s := &session{
+		lid:     types.LayerID(10),
+		signers: signers,
+		vrfs:    []*types.HareEligibility{{Count: 1}, {Count: 1}},
+		proto:   newProtocol(1),
+	}
+	require.True(t, hare.Unregister(signers[0].NodeID()))
+	require.False(t, hare.Unregister(signers[0].NodeID()))
+
+	// running session stops producing messages for the unregistered signer
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, buf []byte) error {
+			msg := &Message{}
+			require.NoError(t, codec.Decode(buf, msg))
+			require.Equal(t, signers[1].NodeID(), msg.Sender)
+			return nil
+		})
+	out := output{message: &Message{Body: Body{IterRound: IterRound{Round: commit}}}}
+	out.message.Value.Reference = &types.Hash32{1}
+	require.NoError(t, hare.onOutput(s, IterRound{Round: commit}, out))
+}
+
 func TestHareConfig_CommitteeUpgrade(t *testing.T) {
 	t.Parallel()
 	t.Run("no upgrade", func(t *testing.T) {
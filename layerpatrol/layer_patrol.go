@@ -2,6 +2,7 @@
 package layerpatrol
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
@@ -9,17 +10,57 @@ import (
 
 const bufferSize = uint32(100)
 
+// WaitReason explains why block generation for a layer didn't happen yet.
+type WaitReason uint8
+
+const (
+	// ReasonNotSynced is recorded when hare didn't start because the node is not synced.
+	ReasonNotSynced WaitReason = iota + 1
+	// ReasonBeaconMissing is recorded when hare didn't start because the beacon for the epoch is missing.
+	ReasonBeaconMissing
+	// ReasonHareRunning is recorded while hare is running for the layer.
+	ReasonHareRunning
+	// ReasonHareFailed is recorded when hare failed to produce an output for the layer.
+	ReasonHareFailed
+)
+
+func (r WaitReason) String() string {
+	switch r {
+	case ReasonNotSynced:
+		return "not synced"
+	case ReasonBeaconMissing:
+		return "beacon missing"
+	case ReasonHareRunning:
+		return "hare running"
+	case ReasonHareFailed:
+		return "hare failed"
+	default:
+		return fmt.Sprintf("unknown reason %d", r)
+	}
+}
+
+// LayerStatus describes why block generation is waiting for the layer.
+type LayerStatus struct {
+	Reason WaitReason
+	// Iteration is the hare iteration where hare failed. Set only for ReasonHareFailed.
+	Iteration uint8
+	// Error is the error hare failed with. Set only for ReasonHareFailed.
+	Error string
+}
+
 // LayerPatrol keeps progress of each layer.
 type LayerPatrol struct {
 	mu          sync.Mutex
 	oldestLayer types.LayerID
 	runByHare   map[types.LayerID]struct{}
+	waiting     map[types.LayerID]LayerStatus
 }
 
 // New returns an instance of LayerPatrol.
 func New() *LayerPatrol {
 	return &LayerPatrol{
 		runByHare: make(map[types.LayerID]struct{}),
+		waiting:   make(map[types.LayerID]LayerStatus),
 	}
 }
 
@@ -28,11 +69,9 @@ func (lp *LayerPatrol) SetHareInCharge(layerID types.LayerID) {
 	lp.mu.Lock()
 	defer lp.mu.Unlock()
 
-	if layerID.Uint32() > bufferSize {
-		lp.oldestLayer = layerID.Sub(bufferSize)
-	}
-	delete(lp.runByHare, lp.oldestLayer)
+	lp.prune(layerID)
 	lp.runByHare[layerID] = struct{}{}
+	lp.waiting[layerID] = LayerStatus{Reason: ReasonHareRunning}
 }
 
 // IsHareInCharge returns true if the hare is set to handle the validation of the specified layer.
@@ -48,4 +87,58 @@ func (lp *LayerPatrol) CompleteHare(layerID types.LayerID) {
 	lp.mu.Lock()
 	defer lp.mu.Unlock()
 	delete(lp.runByHare, layerID)
+	delete(lp.waiting, layerID)
+}
+
+// HareFailed is called by hare instance that failed to produce an output for this layer
+// at the specified iteration. The layer is not handled by hare anymore.
+func (lp *LayerPatrol) HareFailed(layerID types.LayerID, iter uint8, err error) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	delete(lp.runByHare, layerID)
+	status := LayerStatus{Reason: ReasonHareFailed, Iteration: iter}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	lp.waiting[layerID] = status
+}
+
+// SetWaiting records the reason why hare didn't start for the layer.
+func (lp *LayerPatrol) SetWaiting(layerID types.LayerID, reason WaitReason) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	lp.prune(layerID)
+	lp.waiting[layerID] = LayerStatus{Reason: reason}
+}
+
+// Status returns the reason why block generation is waiting for the layer.
+// Returns false if the layer is not waiting or it is too old to be tracked.
+func (lp *LayerPatrol) Status(layerID types.LayerID) (LayerStatus, bool) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	status, ok := lp.waiting[layerID]
+	return status, ok
+}
+
+// Waiting returns statuses of all tracked layers that are waiting for block generation.
+func (lp *LayerPatrol) Waiting() map[types.LayerID]LayerStatus {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	rst := make(map[types.LayerID]LayerStatus, len(lp.waiting))
+	for lid, status := range lp.waiting {
+		rst[lid] = status
+	}
+	return rst
+}
+
+func (lp *LayerPatrol) prune(layerID types.LayerID) {
+	if layerID.Uint32() > bufferSize {
+		lp.oldestLayer = layerID.Sub(bufferSize)
+	}
+	delete(lp.runByHare, lp.oldestLayer)
+	for lid := range lp.waiting {
+		if !lid.After(lp.oldestLayer) {
+			delete(lp.waiting, lid)
+		}
+	}
 }
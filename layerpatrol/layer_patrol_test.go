@@ -1,6 +1,7 @@
 package layerpatrol
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,39 @@ func Test_GetAndSetHareInCharge(t *testing.T) {
 	patrol.CompleteHare(exists)
 	assert.False(t, patrol.IsHareInCharge(exists))
 }
+
+func Test_WaitReasons(t *testing.T) {
+	patrol := New()
+	lyr := types.LayerID(10)
+
+	_, ok := patrol.Status(lyr)
+	assert.False(t, ok)
+
+	patrol.SetWaiting(lyr, ReasonNotSynced)
+	status, ok := patrol.Status(lyr)
+	assert.True(t, ok)
+	assert.Equal(t, ReasonNotSynced, status.Reason)
+
+	patrol.SetWaiting(lyr.Add(1), ReasonBeaconMissing)
+	patrol.SetHareInCharge(lyr.Add(2))
+	patrol.SetHareInCharge(lyr.Add(3))
+	patrol.HareFailed(lyr.Add(3), 4, errors.New("terminated without result"))
+	assert.False(t, patrol.IsHareInCharge(lyr.Add(3)))
+	assert.Equal(t, map[types.LayerID]LayerStatus{
+		lyr:        {Reason: ReasonNotSynced},
+		lyr.Add(1): {Reason: ReasonBeaconMissing},
+		lyr.Add(2): {Reason: ReasonHareRunning},
+		lyr.Add(3): {Reason: ReasonHareFailed, Iteration: 4, Error: "terminated without result"},
+	}, patrol.Waiting())
+
+	patrol.CompleteHare(lyr.Add(2))
+	_, ok = patrol.Status(lyr.Add(2))
+	assert.False(t, ok)
+
+	// old layers are not tracked
+	patrol.SetHareInCharge(lyr.Add(bufferSize))
+	_, ok = patrol.Status(lyr)
+	assert.False(t, ok)
+	_, ok = patrol.Status(lyr.Add(1))
+	assert.True(t, ok)
+}
@@ -50,10 +50,16 @@ func (e *Executor) Revert(ctx context.Context, revertTo types.LayerID) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	// rewards of the undone layers are dropped together with the state,
+	// so the accounts affected by them are collected before
+	touched, err := transactions.AddressesAppliedFrom(e.db, revertTo.Add(1))
+	if err != nil {
+		return fmt.Errorf("get affected accounts: %w", err)
+	}
 	if err := e.vm.Revert(revertTo); err != nil {
 		return fmt.Errorf("revert state: %w", err)
 	}
-	if err := e.cs.RevertCache(revertTo); err != nil {
+	if err := e.cs.RevertCache(revertTo, touched); err != nil {
 		return fmt.Errorf("revert cache: %w", err)
 	}
 	root, err := e.vm.GetStateRoot()
@@ -174,9 +180,18 @@ func (e *Executor) Execute(ctx context.Context, lid types.LayerID, block *types.
 }
 
 func (e *Executor) convertRewards(lid types.LayerID, rewards []types.AnyReward) ([]types.CoinbaseReward, error) {
+	return coinbaseRewards(e.atxsdata, lid, rewards)
+}
+
+// coinbaseRewards resolves coinbases of the rewards, the result is ordered by coinbase.
+func coinbaseRewards(
+	atxsdata *atxsdata.Data,
+	lid types.LayerID,
+	rewards []types.AnyReward,
+) ([]types.CoinbaseReward, error) {
 	res := make([]types.CoinbaseReward, 0, len(rewards))
 	for _, r := range rewards {
-		atx := e.atxsdata.Get(lid.GetEpoch(), r.AtxID)
+		atx := atxsdata.Get(lid.GetEpoch(), r.AtxID)
 		if atx == nil {
 			return nil, fmt.Errorf("execute: missing atx %s/%s", lid.GetEpoch(), r.AtxID.ShortString())
 		}
@@ -460,13 +460,13 @@ func TestExecutor_Revert(t *testing.T) {
 
 	t.Run("conservative state failure", func(t *testing.T) {
 		te.mvm.EXPECT().Revert(lid)
-		te.mcs.EXPECT().RevertCache(lid).Return(errInconceivable)
+		te.mcs.EXPECT().RevertCache(lid, gomock.Any()).Return(errInconceivable)
 		require.ErrorIs(t, te.exec.Revert(context.Background(), lid), errInconceivable)
 	})
 
 	t.Run("revert success", func(t *testing.T) {
 		te.mvm.EXPECT().Revert(lid)
-		te.mcs.EXPECT().RevertCache(lid)
+		te.mcs.EXPECT().RevertCache(lid, gomock.Any())
 		te.mvm.EXPECT().GetStateRoot()
 		require.NoError(t, te.exec.Revert(context.Background(), lid))
 	})
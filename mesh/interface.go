@@ -10,9 +10,10 @@ import (
 
 type conservativeState interface {
 	UpdateCache(context.Context, types.LayerID, types.BlockID, []types.TransactionWithResult, []types.Transaction) error
-	RevertCache(types.LayerID) error
+	RevertCache(types.LayerID, []types.Address) error
 	LinkTXsWithProposal(types.LayerID, types.ProposalID, []types.TransactionID) error
 	LinkTXsWithBlock(types.LayerID, types.BlockID, []types.TransactionID) error
+	ExpectRewards(types.LayerID, types.BlockID, []types.CoinbaseReward) error
 }
 
 type vmState interface {
@@ -617,6 +617,20 @@ func (msh *Mesh) AddBlockWithTXs(ctx context.Context, block *types.Block) error
 	if err := msh.conState.LinkTXsWithBlock(block.LayerIndex, block.ID(), block.TxIDs); err != nil {
 		return fmt.Errorf("link block txs: %v/%v: %w", block.LayerIndex, block.ID(), err)
 	}
+	if rewards, err := coinbaseRewards(msh.atxsdata, block.LayerIndex, block.Rewards); err != nil {
+		// the block is still added, the mempool doesn't count on its rewards
+		msh.logger.Debug("not expecting rewards of the block",
+			log.ZContext(ctx),
+			zap.Stringer("block", block.ID()),
+			zap.Error(err),
+		)
+	} else if err := msh.conState.ExpectRewards(block.LayerIndex, block.ID(), rewards); err != nil {
+		msh.logger.Warn("failed to expect block rewards",
+			log.ZContext(ctx),
+			zap.Stringer("block", block.ID()),
+			zap.Error(err),
+		)
+	}
 	msh.setLatestLayer(block.LayerIndex)
 
 	// add block to the tortoise before storing it
@@ -2,6 +2,7 @@ package mesh
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -234,7 +235,7 @@ func TestMesh_WakeUp(t *testing.T) {
 	require.NoError(t, layers.SetApplied(tm.cdb, latestState, types.RandomBlockID()))
 
 	tm.mockVM.EXPECT().Revert(latestState)
-	tm.mockState.EXPECT().RevertCache(latestState)
+	tm.mockState.EXPECT().RevertCache(latestState, gomock.Any())
 	tm.mockVM.EXPECT().GetStateRoot()
 	msh, err := NewMesh(
 		tm.db,
@@ -338,9 +339,49 @@ func TestMesh_AddBlockWithTXs(t *testing.T) {
 	layerID := types.GetEffectiveGenesis().Add(1)
 	block := genLayerBlock(layerID, txIDs)
 	tm.mockState.EXPECT().LinkTXsWithBlock(layerID, block.ID(), txIDs).Return(nil)
+	tm.mockState.EXPECT().ExpectRewards(layerID, block.ID(), []types.CoinbaseReward{}).Return(nil)
 	r.NoError(tm.AddBlockWithTXs(context.Background(), block))
 }
 
+func TestMesh_AddBlockWithTXs_ExpectRewards(t *testing.T) {
+	tm := createTestMesh(t)
+	tm.mockTortoise.EXPECT().OnBlock(gomock.Any()).AnyTimes()
+	tm.mockState.EXPECT().LinkTXsWithBlock(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	layerID := types.GetEffectiveGenesis().Add(1)
+	coinbase := types.GenerateAddress(types.RandomBytes(32))
+	smesher := types.RandomNodeID()
+	atxID := types.RandomATXID()
+	tm.atxsdata.Add(layerID.GetEpoch(), smesher, coinbase, atxID, 10, 0, 1, 0, false)
+
+	block := genLayerBlock(layerID, nil)
+	block.Rewards = []types.AnyReward{{AtxID: atxID, Weight: types.RatNum{Num: 1, Denom: 1}}}
+	block.Initialize()
+	tm.mockState.EXPECT().ExpectRewards(layerID, block.ID(), []types.CoinbaseReward{{
+		SmesherID: smesher,
+		Coinbase:  coinbase,
+		Weight:    types.RatNum{Num: 1, Denom: 1},
+	}})
+	require.NoError(t, tm.AddBlockWithTXs(context.Background(), block))
+
+	// rewards of unknown atxs are not expected, but the block is still added
+	block = genLayerBlock(layerID, types.RandomTXSet(1))
+	block.Rewards = []types.AnyReward{{AtxID: types.RandomATXID(), Weight: types.RatNum{Num: 1, Denom: 1}}}
+	block.Initialize()
+	require.NoError(t, tm.AddBlockWithTXs(context.Background(), block))
+
+	// the block is added even if the mempool fails to expect its rewards
+	block = genLayerBlock(layerID, nil)
+	block.Rewards = []types.AnyReward{{AtxID: atxID, Weight: types.RatNum{Num: 1, Denom: 1}}}
+	block.TickHeight = 1
+	block.Initialize()
+	tm.mockState.EXPECT().ExpectRewards(layerID, block.ID(), gomock.Any()).Return(errors.New("test"))
+	require.NoError(t, tm.AddBlockWithTXs(context.Background(), block))
+	got, err := blocks.Get(tm.cdb, block.ID())
+	require.NoError(t, err)
+	require.Equal(t, block.ID(), got.ID())
+}
+
 func TestMesh_CallOnBlock(t *testing.T) {
 	tm := createTestMesh(t)
 	block := types.Block{}
@@ -349,6 +390,7 @@ func TestMesh_CallOnBlock(t *testing.T) {
 
 	tm.mockTortoise.EXPECT().OnBlock(block.ToVote())
 	tm.mockState.EXPECT().LinkTXsWithBlock(block.LayerIndex, block.ID(), block.TxIDs)
+	tm.mockState.EXPECT().ExpectRewards(block.LayerIndex, block.ID(), gomock.Any())
 	require.NoError(t, tm.AddBlockWithTXs(context.Background(), &block))
 }
 
@@ -727,7 +769,7 @@ func TestProcessLayer(t *testing.T) {
 			tm.mockTortoise.EXPECT().TallyVotes(gomock.Any(), gomock.Any()).AnyTimes()
 			tm.mockVM.EXPECT().GetStateRoot().AnyTimes()
 			tm.mockVM.EXPECT().Revert(gomock.Any()).AnyTimes()
-			tm.mockState.EXPECT().RevertCache(gomock.Any()).AnyTimes()
+			tm.mockState.EXPECT().RevertCache(gomock.Any(), gomock.Any()).AnyTimes()
 
 			lid := start
 			for _, c := range tc.calls {
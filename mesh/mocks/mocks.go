@@ -40,6 +40,44 @@ func (m *MockconservativeState) EXPECT() *MockconservativeStateMockRecorder {
 	return m.recorder
 }
 
+// ExpectRewards mocks base method.
+func (m *MockconservativeState) ExpectRewards(arg0 types.LayerID, arg1 types.BlockID, arg2 []types.CoinbaseReward) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpectRewards", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpectRewards indicates an expected call of ExpectRewards.
+func (mr *MockconservativeStateMockRecorder) ExpectRewards(arg0, arg1, arg2 any) *MockconservativeStateExpectRewardsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpectRewards", reflect.TypeOf((*MockconservativeState)(nil).ExpectRewards), arg0, arg1, arg2)
+	return &MockconservativeStateExpectRewardsCall{Call: call}
+}
+
+// MockconservativeStateExpectRewardsCall wrap *gomock.Call
+type MockconservativeStateExpectRewardsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockconservativeStateExpectRewardsCall) Return(arg0 error) *MockconservativeStateExpectRewardsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockconservativeStateExpectRewardsCall) Do(f func(types.LayerID, types.BlockID, []types.CoinbaseReward) error) *MockconservativeStateExpectRewardsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockconservativeStateExpectRewardsCall) DoAndReturn(f func(types.LayerID, types.BlockID, []types.CoinbaseReward) error) *MockconservativeStateExpectRewardsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // LinkTXsWithBlock mocks base method.
 func (m *MockconservativeState) LinkTXsWithBlock(arg0 types.LayerID, arg1 types.BlockID, arg2 []types.TransactionID) error {
 	m.ctrl.T.Helper()
@@ -117,17 +155,17 @@ func (c *MockconservativeStateLinkTXsWithProposalCall) DoAndReturn(f func(types.
 }
 
 // RevertCache mocks base method.
-func (m *MockconservativeState) RevertCache(arg0 types.LayerID) error {
+func (m *MockconservativeState) RevertCache(arg0 types.LayerID, arg1 []types.Address) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RevertCache", arg0)
+	ret := m.ctrl.Call(m, "RevertCache", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RevertCache indicates an expected call of RevertCache.
-func (mr *MockconservativeStateMockRecorder) RevertCache(arg0 any) *MockconservativeStateRevertCacheCall {
+func (mr *MockconservativeStateMockRecorder) RevertCache(arg0, arg1 any) *MockconservativeStateRevertCacheCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertCache", reflect.TypeOf((*MockconservativeState)(nil).RevertCache), arg0)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertCache", reflect.TypeOf((*MockconservativeState)(nil).RevertCache), arg0, arg1)
 	return &MockconservativeStateRevertCacheCall{Call: call}
 }
 
@@ -143,13 +181,13 @@ func (c *MockconservativeStateRevertCacheCall) Return(arg0 error) *Mockconservat
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockconservativeStateRevertCacheCall) Do(f func(types.LayerID) error) *MockconservativeStateRevertCacheCall {
+func (c *MockconservativeStateRevertCacheCall) Do(f func(types.LayerID, []types.Address) error) *MockconservativeStateRevertCacheCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockconservativeStateRevertCacheCall) DoAndReturn(f func(types.LayerID) error) *MockconservativeStateRevertCacheCall {
+func (c *MockconservativeStateRevertCacheCall) DoAndReturn(f func(types.LayerID, []types.Address) error) *MockconservativeStateRevertCacheCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
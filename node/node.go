@@ -51,6 +51,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/fetch"
 	vm "github.com/spacemeshos/go-spacemesh/genvm"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
 	"github.com/spacemeshos/go-spacemesh/hare3"
 	"github.com/spacemeshos/go-spacemesh/hare3/compat"
 	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
@@ -74,6 +75,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/activesets"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/backup"
 	"github.com/spacemeshos/go-spacemesh/sql/layers"
 	"github.com/spacemeshos/go-spacemesh/sql/localsql"
 	localmigrations "github.com/spacemeshos/go-spacemesh/sql/localsql/migrations"
@@ -343,6 +345,22 @@ func WithConfig(conf *config.Config) Option {
 	}
 }
 
+// WithHareOracle replaces the eligibility oracle used by hare and the certifier, e.g. to test
+// alternative implementations. The default oracle still tracks active sets and serves the debug API.
+func WithHareOracle(oracle eligibility.Rolacle) Option {
+	return func(app *App) {
+		app.hareOracle = oracle
+	}
+}
+
+// WithShadowHareOracle runs the oracle next to the one used by hare and the certifier, and logs
+// and counts their disagreements. Decisions of the oracle in use remain authoritative.
+func WithShadowHareOracle(oracle eligibility.Rolacle) Option {
+	return func(app *App) {
+		app.shadowHareOracle = oracle
+	}
+}
+
 // New creates an instance of the spacemesh app.
 func New(opts ...Option) *App {
 	defaultConfig := config.DefaultConfig()
@@ -382,8 +400,10 @@ type App struct {
 	grpcPostServer    *grpcserver.Server
 	grpcTLSServer     *grpcserver.Server
 	jsonAPIServer     *grpcserver.JSONHTTPServer
+	debugJSONServer   *grpcserver.JSONHTTPServer
 	grpcServices      map[grpcserver.Service]grpcserver.ServiceAPI
 	pprofService      *http.Server
+	faults            *activation.FaultInjector
 	profilerService   *pyroscope.Profiler
 	syncer            *syncer.Syncer
 	proposalListener  *proposals.Handler
@@ -392,15 +412,20 @@ type App struct {
 	atxsdata          *atxsdata.Data
 	clock             *timesync.NodeClock
 	hare3             *hare3.Hare
+	hareConfig        *hare3.ConfigExchange
 	hare4             *hare4.Hare
 	hareResultsChan   chan hare4.ConsensusOutput
 	hOracle           *eligibility.Oracle
+	patrol            *layerpatrol.LayerPatrol
+	hareOracle        eligibility.Rolacle
+	shadowHareOracle  eligibility.Rolacle
 	blockGen          *blocks.Generator
 	certifier         *blocks.Certifier
 	atxBuilder        *activation.Builder
 	nipostBuilder     *activation.NIPostBuilder
 	atxHandler        *activation.Handler
 	txHandler         *txs.TxHandler
+	feeFloor          *txs.FeeFloor
 	validator         *activation.Validator
 	edVerifier        *signing.EdVerifier
 	beaconProtocol    *beacon.ProtocolDriver
@@ -413,6 +438,9 @@ type App struct {
 	tortoise          *tortoise.Tortoise
 	updater           *bootstrap.Updater
 	poetDb            *activation.PoetDb
+	poetResidue       *activation.PoetResidueChecker
+	readiness         *activation.ReadinessChecker
+	poetCertifier     *activation.Certifier
 	postVerifier      activation.PostVerifier
 	postSupervisor    *activation.PostSupervisor
 	errCh             chan error
@@ -615,11 +643,37 @@ func (app *App) initServices(ctx context.Context) error {
 	state := vm.New(app.db,
 		vm.WithConfig(cfg),
 		vm.WithLogger(app.addLogger(VMLogger, lg).Zap()))
+	app.feeFloor = txs.NewFeeFloor(txs.FeeFloorConfig{
+		MinFeePerByte: app.Config.MinFeePerByte,
+		MaxFeePerByte: app.Config.MaxFeePerByte,
+		Adjust:        app.Config.FeeFloorAdjust,
+	})
+	priority := make([]types.Address, 0, len(app.Config.PriorityPrincipals))
+	for _, principal := range app.Config.PriorityPrincipals {
+		addr, err := types.StringToAddress(principal)
+		if err != nil {
+			return fmt.Errorf("parse priority principal %s: %w", principal, err)
+		}
+		priority = append(priority, addr)
+	}
+	var rewardAccounts []types.Address
+	if app.Config.MempoolExpectRewards && app.Config.SMESHING.CoinbaseAccount != "" {
+		coinbase, err := types.StringToAddress(app.Config.SMESHING.CoinbaseAccount)
+		if err != nil {
+			return fmt.Errorf("parse coinbase %s: %w", app.Config.SMESHING.CoinbaseAccount, err)
+		}
+		rewardAccounts = append(rewardAccounts, coinbase)
+	}
 	app.conState = txs.NewConservativeState(state, app.db,
 		txs.WithCSConfig(txs.CSConfig{
 			BlockGasLimit:     app.Config.BlockGasLimit,
 			NumTXsPerProposal: app.Config.TxsPerProposal,
+			Priority:          priority,
+			RewardAccounts:    rewardAccounts,
+			DormantLayers:     app.Config.MempoolDormantLayers,
 		}),
+		txs.WithFeeFloorAdjustment(app.feeFloor),
+		txs.WithLocalDB(app.localDB),
 		txs.WithLogger(app.addLogger(ConStateLogger, lg).Zap()))
 
 	genesisAccts := app.Config.Genesis.ToAccounts()
@@ -709,7 +763,10 @@ func (app *App) initServices(ctx context.Context) error {
 		return fmt.Errorf("create mesh: %w", err)
 	}
 
-	pruner := prune.New(app.db, app.Config.Tortoise.Hdist, app.Config.PruneActivesetsFrom, prune.WithLogger(mlog))
+	pruner := prune.New(app.db, app.Config.Tortoise.Hdist, app.Config.PruneActivesetsFrom,
+		prune.WithLogger(mlog),
+		prune.WithPoetRetention(app.Config.PrunePoetProofsAfter, app.clock),
+	)
 	if err := pruner.Prune(app.clock.CurrentLayer()); err != nil {
 		return fmt.Errorf("pruner %w", err)
 	}
@@ -717,6 +774,17 @@ func (app *App) initServices(ctx context.Context) error {
 		prune.Run(ctx, pruner, app.clock, app.Config.DatabasePruneInterval)
 		return nil
 	})
+	if app.Config.DatabaseBackup.Interval > 0 {
+		backups := backup.New(app.Config.DatabaseBackup, app.Config.DataDir(),
+			backup.WithLogger(app.log.Zap().Named("db-backup")),
+			backup.WithDatabase("state", app.db),
+			backup.WithDatabase("local", app.localDB),
+		)
+		app.eg.Go(func() error {
+			backups.Run(ctx)
+			return nil
+		})
+	}
 
 	fetcherWrapped := &layerFetcher{}
 
@@ -740,15 +808,12 @@ func (app *App) initServices(ctx context.Context) error {
 		atxHandler.Register(sig)
 	}
 
-	// we can't have an epoch offset which is greater/equal than the number of layers in an epoch
-
-	if app.Config.HareEligibility.ConfidenceParam >= app.Config.BaseConfig.LayersPerEpoch {
-		return fmt.Errorf(
-			"confidence param should be smaller than layers per epoch. eligibility-confidence-param: %d. "+
-				"layers-per-epoch: %d",
-			app.Config.HareEligibility.ConfidenceParam,
-			app.Config.BaseConfig.LayersPerEpoch,
-		)
+	if err := app.Config.HareEligibility.Validate(app.Config.BaseConfig.LayersPerEpoch); err != nil {
+		return fmt.Errorf("invalid hare eligibility config: %w", err)
+	}
+	epochDuration := app.Config.LayerDuration * time.Duration(app.Config.LayersPerEpoch)
+	if err := app.Config.POET.Validate(epochDuration); err != nil {
+		return fmt.Errorf("invalid poet config: %w", err)
 	}
 
 	blockHandler := blocks.NewHandler(fetcherWrapped, app.db, trtl, msh,
@@ -758,8 +823,13 @@ func (app *App) initServices(ctx context.Context) error {
 		app.conState,
 		app.host.ID(),
 		app.addLogger(TxHandlerLogger, lg).Zap(),
+		txs.WithMaxTxSize(core.TxSizeLimit),
+		txs.WithMinGasPrice(app.Config.MinGasPrice),
+		txs.WithFeeFloor(app.feeFloor),
+		txs.WithForeignNetworks(app.foreignNetworks()...),
 	)
 
+	oracleLogger := app.addLogger(HareOracleLogger, lg).Zap()
 	app.hOracle = eligibility.New(
 		beaconProtocol,
 		app.db,
@@ -767,8 +837,13 @@ func (app *App) initServices(ctx context.Context) error {
 		vrfVerifier,
 		app.Config.LayersPerEpoch,
 		eligibility.WithConfig(app.Config.HareEligibility),
-		eligibility.WithLogger(app.addLogger(HareOracleLogger, lg).Zap()),
+		eligibility.WithLogger(oracleLogger),
 	)
+	if app.shadowHareOracle != nil {
+		// the oracle in use stays authoritative, the shadow only reports disagreements
+		shadowLogger := oracleLogger.Named("shadow")
+		app.hareOracle = eligibility.NewShadow(app.eligibilityOracle(), app.shadowHareOracle, shadowLogger)
+	}
 	// TODO: genesisMinerWeight is set to app.Config.SpaceToCommit, because PoET ticks are currently hardcoded to 1
 
 	bscfg := app.Config.Bootstrap
@@ -789,7 +864,7 @@ func (app *App) initServices(ctx context.Context) error {
 	app.Config.Certificate.NumLayersToKeep = app.Config.Tortoise.Zdist * 2
 	app.certifier = blocks.NewCertifier(
 		app.db,
-		app.hOracle,
+		app.eligibilityOracle(),
 		app.edVerifier,
 		app.host,
 		app.clock,
@@ -820,6 +895,7 @@ func (app *App) initServices(ctx context.Context) error {
 	})
 
 	patrol := layerpatrol.New()
+	app.patrol = patrol
 	syncerConf := app.Config.Sync
 	syncerConf.HareDelayLayers = app.Config.Tortoise.Zdist
 	syncerConf.SyncCertDistance = app.Config.Tortoise.Hdist
@@ -870,12 +946,16 @@ func (app *App) initServices(ctx context.Context) error {
 			app.atxsdata,
 			proposalsStore,
 			app.edVerifier,
-			app.hOracle,
+			app.eligibilityOracle(),
 			newSyncer,
 			patrol,
 			hare3.WithLogger(logger),
 			hare3.WithConfig(app.Config.HARE3),
 			hare3.WithResultsChan(app.hareResultsChan),
+			hare3.WithArchiveDB(app.localDB),
+			hare3.WithStatsDB(app.localDB),
+			hare3.WithOutputsDB(app.localDB),
+			hare3.WithProposalFetcher(fetcher),
 		)
 		for _, sig := range app.signers {
 			app.hare3.Register(sig)
@@ -900,7 +980,7 @@ func (app *App) initServices(ctx context.Context) error {
 			app.atxsdata,
 			proposalsStore,
 			app.edVerifier,
-			app.hOracle,
+			app.eligibilityOracle(),
 			newSyncer,
 			patrol,
 			app.host,
@@ -963,9 +1043,10 @@ func (app *App) initServices(ctx context.Context) error {
 		app.certifier,
 		patrol,
 		blocks.WithConfig(blocks.Config{
-			BlockGasLimit:      app.Config.BlockGasLimit,
-			OptFilterThreshold: app.Config.OptFilterThreshold,
-			GenBlockInterval:   500 * time.Millisecond,
+			BlockGasLimit:        app.Config.BlockGasLimit,
+			OptFilterThreshold:   app.Config.OptFilterThreshold,
+			GenBlockInterval:     500 * time.Millisecond,
+			ProposalFetchTimeout: app.Config.ProposalFetchTimeout,
 		}),
 		blocks.WithHareOutputChan(app.hareResultsChan),
 		blocks.WithGeneratorLogger(app.addLogger(BlockGenLogger, lg).Zap()),
@@ -1023,7 +1104,14 @@ func (app *App) initServices(ctx context.Context) error {
 		nipostLogger,
 		activation.WithCertifierClientConfig(app.Config.Certifier.Client),
 	)
-	certifier := activation.NewCertifier(app.localDB, nipostLogger, client)
+	certifier := activation.NewCertifier(
+		app.localDB,
+		nipostLogger,
+		client,
+		activation.WithTrustedCertifiers(app.Config.Certifier.Trusted),
+		activation.WithCertificateRefresh(app.Config.Certifier.RefreshInterval, app.Config.Certifier.RefreshBefore),
+	)
+	app.poetCertifier = certifier
 
 	poetClients := make([]activation.PoetService, 0, len(app.Config.PoetServers))
 	for _, server := range app.Config.PoetServers {
@@ -1033,12 +1121,47 @@ func (app *App) initServices(ctx context.Context) error {
 			app.Config.POET,
 			lg.Zap().Named("poet"),
 			activation.WithCertifier(certifier),
+			activation.WithFaultInjector(app.faults),
 		)
 		if err != nil {
 			app.log.Panic("failed to create poet client with address %v: %v", server.Address, err)
 		}
 		poetClients = append(poetClients, client)
 	}
+	fallbackClients := make([]activation.PoetService, 0, len(app.Config.FallbackPoetServers))
+	for _, server := range app.Config.FallbackPoetServers {
+		client, err := activation.NewPoetService(
+			poetDb,
+			server,
+			app.Config.POET.Fallback(),
+			lg.Zap().Named("poet"),
+			activation.WithCertifier(certifier),
+			activation.WithFaultInjector(app.faults),
+		)
+		if err != nil {
+			app.log.Panic("failed to create fallback poet client with address %v: %v", server.Address, err)
+		}
+		fallbackClients = append(fallbackClients, client)
+	}
+
+	poetAddresses := make([]string, 0, len(poetClients)+len(fallbackClients))
+	for _, client := range poetClients {
+		poetAddresses = append(poetAddresses, client.Address())
+	}
+	for _, client := range fallbackClients {
+		poetAddresses = append(poetAddresses, client.Address())
+	}
+	for _, override := range app.Config.POET.Overrides {
+		if !slices.Contains(poetAddresses, override.Address) {
+			app.log.Zap().Warn("poet override doesn't match the address of any configured poet",
+				zap.String("address", override.Address),
+			)
+		}
+	}
+	app.poetResidue = activation.NewPoetResidueChecker(lg.Zap().Named("poet"), app.localDB, poetAddresses)
+	if err := app.poetResidue.Warn(); err != nil {
+		return err
+	}
 
 	nipostBuilder, err := activation.NewNIPostBuilder(
 		app.localDB,
@@ -1049,14 +1172,25 @@ func (app *App) initServices(ctx context.Context) error {
 		app.validator,
 		activation.NipostbuilderWithPostStates(postStates),
 		activation.WithPoetServices(poetClients...),
+		activation.WithFallbackPoetServices(fallbackClients...),
+		activation.NipostbuilderWithFaultInjector(app.faults),
 	)
 	if err != nil {
 		return fmt.Errorf("create nipost builder: %w", err)
 	}
 
+	if app.Config.AtxPublishInterval > epochDuration {
+		app.log.With().Warning("atx publish interval exceeds the publish window, clamping it to the epoch duration",
+			log.Duration("interval", app.Config.AtxPublishInterval),
+			log.Duration("epoch", epochDuration),
+		)
+		app.Config.AtxPublishInterval = epochDuration
+	}
 	builderConfig := activation.Config{
 		GoldenATXID:      goldenATXID,
 		RegossipInterval: app.Config.RegossipAtxInterval,
+		PublishInterval:  app.Config.AtxPublishInterval,
+		AutoPauseEpochs:  app.Config.AtxAutoPauseEpochs,
 	}
 	atxBuilder := activation.NewBuilder(
 		builderConfig,
@@ -1273,12 +1407,53 @@ func (app *App) initServices(ctx context.Context) error {
 			peersync.WithConfig(app.Config.TIME.Peersync),
 		)
 	}
+	var readinessOpts []activation.ReadinessOpt
+	if app.ptimesync != nil {
+		readinessOpts = append(readinessOpts, activation.WithReadinessClockCheck(app.ptimesync))
+	}
+	app.readiness = activation.NewReadinessChecker(
+		app.addLogger(ATXBuilderLogger, lg).Zap().Named("readiness"),
+		activation.ReadinessConfig{
+			Lead:         app.Config.ReadinessCheckLead,
+			DataDir:      app.Config.DataDir(),
+			MinFreeSpace: app.Config.ReadinessMinFreeSpace,
+		},
+		app.Config.POET,
+		app.clock,
+		atxBuilder,
+		grpcPostService.(*grpcserver.PostService),
+		nipostBuilder,
+		readinessOpts...,
+	)
 	if err := app.host.Start(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// eligibilityOracle returns the oracle set with WithHareOracle or the default one.
+func (app *App) eligibilityOracle() eligibility.Rolacle {
+	if app.hareOracle != nil {
+		return app.hareOracle
+	}
+	return app.hOracle
+}
+
+// foreignNetworks returns genesis ids of the public networks other than the one the node is running in.
+func (app *App) foreignNetworks() []types.Hash20 {
+	networks := []config.GenesisConfig{config.MainnetConfig().Genesis}
+	if testnet, err := presets.Get("testnet"); err == nil {
+		networks = append(networks, testnet.Genesis)
+	}
+	var rst []types.Hash20
+	for _, genesis := range networks {
+		if id := genesis.GenesisID(); id != app.Config.Genesis.GenesisID() {
+			rst = append(rst, id)
+		}
+	}
+	return rst
+}
+
 func (app *App) launchStandalone(ctx context.Context) error {
 	if !app.Config.Standalone {
 		return nil
@@ -1404,10 +1579,42 @@ func (app *App) listenToUpdates(ctx context.Context) {
 	})
 }
 
+// pushActivations pushes newly stored activations to peers that recently requested activations.
+func (app *App) pushActivations(ctx context.Context) error {
+	sub, err := events.SubscribeActivations()
+	if err != nil {
+		return fmt.Errorf("subscribe to activations: %w", err)
+	}
+	if sub == nil {
+		return nil
+	}
+	app.eg.Go(func() error {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case ev, ok := <-sub.Out():
+				if !ok {
+					return nil
+				}
+				atx := ev.(events.ActivationTx)
+				app.fetcher.Push(ctx, datastore.ATXDB, atx.ID().Hash32(), atx.Peer)
+			}
+		}
+	})
+	return nil
+}
+
 func (app *App) startServices(ctx context.Context) error {
 	if err := app.fetcher.Start(); err != nil {
 		return fmt.Errorf("start fetcher: %w", err)
 	}
+	if app.Config.FETCH.Push.Enable {
+		if err := app.pushActivations(ctx); err != nil {
+			return err
+		}
+	}
 	app.syncer.Start()
 	app.beaconProtocol.Start(ctx)
 
@@ -1416,6 +1623,13 @@ func (app *App) startServices(ctx context.Context) error {
 	app.eg.Go(func() error {
 		return app.proposalBuilder.Run(ctx)
 	})
+	app.eg.Go(func() error {
+		return app.hareConfig.Run(ctx)
+	})
+	app.eg.Go(func() error {
+		app.hOracle.RunWarmup(ctx, app.clock)
+		return nil
+	})
 
 	if app.Config.SMESHING.CoinbaseAccount != "" {
 		coinbaseAddr, err := types.StringToAddress(app.Config.SMESHING.CoinbaseAccount)
@@ -1434,13 +1648,59 @@ func (app *App) startServices(ctx context.Context) error {
 	if app.ptimesync != nil {
 		app.ptimesync.Start()
 	}
+	app.eg.Go(func() error {
+		return app.readiness.Run(ctx)
+	})
+	app.eg.Go(func() error {
+		return app.poetCertifier.Run(ctx)
+	})
 
 	if app.updater != nil {
 		app.listenToUpdates(ctx)
 	}
+
+	if app.Config.MempoolImport != "" {
+		if err := app.importMempool(ctx, app.Config.MempoolImport); err != nil {
+			return err
+		}
+	}
+	if app.Config.MempoolCheckInterval > 0 {
+		app.eg.Go(func() error {
+			app.conState.RunConsistencyCheck(ctx, app.Config.MempoolCheckInterval)
+			return nil
+		})
+	}
 	return nil
 }
 
+func (app *App) importMempool(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open mempool import: %w", err)
+	}
+	defer f.Close()
+	n, err := app.txHandler.ImportPending(ctx, f)
+	if err != nil {
+		return fmt.Errorf("import mempool from %s: %w", path, err)
+	}
+	app.log.Zap().Info("imported pending transactions", zap.String("path", path), zap.Int("count", n))
+	return nil
+}
+
+func (app *App) exportMempool(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create mempool export: %w", err)
+	}
+	defer f.Close()
+	n, err := txs.ExportPending(app.db, f)
+	if err != nil {
+		return fmt.Errorf("export mempool to %s: %w", path, err)
+	}
+	app.log.Zap().Info("exported pending transactions", zap.String("path", path), zap.Int("count", n))
+	return f.Close()
+}
+
 func (app *App) grpcService(svc grpcserver.Service, lg log.Log) (grpcserver.ServiceAPI, error) {
 	if service, ok := app.grpcServices[svc]; ok {
 		return service, nil
@@ -1448,7 +1708,24 @@ func (app *App) grpcService(svc grpcserver.Service, lg log.Log) (grpcserver.Serv
 
 	switch svc {
 	case grpcserver.Debug:
-		service := grpcserver.NewDebugService(app.db, app.conState, app.host, app.hOracle, app.loggers)
+		opts := []grpcserver.DebugServiceOpt{
+			grpcserver.WithHareCommittee(app.Config.HARE3.CommitteeFor),
+			grpcserver.WithLayerPatrol(app.patrol),
+		}
+		if app.hare3 != nil && app.hare3.Archive() != nil {
+			opts = append(opts, grpcserver.WithHareArchive(app.hare3.Archive()))
+		}
+		if app.hare3 != nil {
+			opts = append(opts, grpcserver.WithHareParticipation(app.hare3))
+		}
+		if app.hare3 != nil && app.hare3.LayerStats() != nil {
+			opts = append(opts, grpcserver.WithHareStats(app.hare3.LayerStats()))
+		}
+		if app.dbMetrics != nil {
+			opts = append(opts, grpcserver.WithDBStats(app.dbMetrics))
+		}
+		service := grpcserver.NewDebugService(app.db, app.localDB, app.conState, app.host, app.hOracle, app.loggers,
+			opts...)
 		app.grpcServices[svc] = service
 		return service, nil
 	case grpcserver.GlobalState:
@@ -1481,7 +1758,23 @@ func (app *App) grpcService(svc grpcserver.Service, lg log.Log) (grpcserver.Serv
 		app.grpcServices[svc] = service
 		return service, nil
 	case grpcserver.Admin:
-		service := grpcserver.NewAdminService(app.db, app.Config.DataDir(), app.host)
+		var opts []grpcserver.AdminServiceOpt
+		if app.poetResidue != nil {
+			opts = append(opts, grpcserver.WithPoetResidue(app.poetResidue))
+		}
+		if app.poetCertifier != nil {
+			opts = append(opts, grpcserver.WithCertificateRefresher(app.poetCertifier))
+		}
+		if app.hare3 != nil {
+			opts = append(opts, grpcserver.WithHareSigners(app.hare3))
+		}
+		if app.hare4 != nil {
+			opts = append(opts, grpcserver.WithHareSigners(app.hare4))
+		}
+		if app.faults != nil {
+			opts = append(opts, grpcserver.WithFaultInjector(app.faults))
+		}
+		service := grpcserver.NewAdminService(app.db, app.Config.DataDir(), app.host, opts...)
 		app.grpcServices[svc] = service
 		return service, nil
 	case grpcserver.Smesher:
@@ -1502,6 +1795,12 @@ func (app *App) grpcService(svc grpcserver.Service, lg log.Log) (grpcserver.Serv
 			app.Config.SMESHING.Opts,
 			sig,
 		)
+		if app.nipostBuilder != nil {
+			service.SetPoets(app.nipostBuilder)
+		}
+		if app.readiness != nil {
+			service.SetReadiness(app.readiness)
+		}
 		app.grpcServices[svc] = service
 		return service, nil
 	case grpcserver.Post:
@@ -1525,6 +1824,7 @@ func (app *App) grpcService(svc grpcserver.Service, lg log.Log) (grpcserver.Serv
 			app.conState,
 			app.syncer,
 			app.txHandler,
+			grpcserver.WithTransactionLatency(app.localDB),
 		)
 		app.grpcServices[svc] = service
 		return service, nil
@@ -1813,6 +2113,31 @@ func (app *App) startAPIServices(ctx context.Context) error {
 			})),
 		)
 	}
+	if len(app.Config.API.DebugJSONListener) > 0 {
+		app.debugJSONServer = grpcserver.NewJSONHTTPServer(
+			logger.Zap().Named("DebugJSON"),
+			app.Config.API.DebugJSONListener,
+			// the listener is not authenticated, no origin is allowed to read its responses
+			[]string{""},
+			app.Config.CollectMetrics,
+			grpcserver.WithMetricsPrefix(metrics.Namespace+"_debug_api"),
+		)
+		// debug endpoints of all enabled services are served, regardless of their grpc listener
+		if err := app.debugJSONServer.StartDebugService(maps.Values(app.grpcServices)...); err != nil {
+			return fmt.Errorf("start debug listen server: %w", err)
+		}
+		logger.With().Info("debug json listener started",
+			log.String("address", app.Config.API.DebugJSONListener),
+			log.Array("services", zapcore.ArrayMarshalerFunc(func(encoder zapcore.ArrayEncoder) error {
+				services := maps.Keys(app.grpcServices)
+				slices.Sort(services)
+				for _, svc := range services {
+					encoder.AppendString(svc)
+				}
+				return nil
+			})),
+		)
+	}
 	return nil
 }
 
@@ -1822,6 +2147,11 @@ func (app *App) stopServices(ctx context.Context) {
 			app.log.With().Error("error stopping json gateway server", log.Err(err))
 		}
 	}
+	if app.debugJSONServer != nil {
+		if err := app.debugJSONServer.Shutdown(ctx); err != nil {
+			app.log.With().Error("error stopping debug json server", log.Err(err))
+		}
+	}
 
 	if app.grpcPublicServer != nil {
 		app.log.Info("stopping public grpc service")
@@ -1905,6 +2235,11 @@ func (app *App) stopServices(ctx context.Context) {
 			app.log.With().Warning("p2p host exited with error", log.Err(err))
 		}
 	}
+	if app.db != nil && app.Config.MempoolExport != "" {
+		if err := app.exportMempool(app.Config.MempoolExport); err != nil {
+			app.log.With().Error("failed to export mempool", log.Err(err))
+		}
+	}
 	if app.db != nil {
 		if err := app.db.Close(); err != nil {
 			app.log.With().Warning("db exited with error", log.Err(err))
@@ -1975,6 +2310,7 @@ func (app *App) setupDBs(ctx context.Context, lg log.Log) error {
 			app.db,
 			dbLog,
 			app.Config.DatabaseSizeMeteringInterval,
+			app.Config.DatabaseCountRows,
 		)
 	}
 	{
@@ -1993,8 +2329,25 @@ func (app *App) setupDBs(ctx context.Context, lg log.Log) error {
 		if err != nil {
 			return err
 		}
-		app.atxsdata = data
 		app.log.With().Info("cache warmup", log.Duration("duration", time.Since(start)))
+		if app.Config.ATXsDataVerifySamples > 0 {
+			discrepancies, err := atxsdata.Verify(app.db, data, app.Config.ATXsDataVerifySamples, warmupLog)
+			if err != nil {
+				return fmt.Errorf("verify atxs cache: %w", err)
+			}
+			if len(discrepancies) > 0 && app.Config.ATXsDataRebuild {
+				warmupLog.Warn("rebuilding atxs cache", zap.Int("discrepancies", len(discrepancies)))
+				data, err = atxsdata.Warm(
+					app.db,
+					app.Config.Tortoise.WindowSizeEpochs(applied),
+					warmupLog,
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		app.atxsdata = data
 	}
 	app.cachedDB = datastore.NewCachedDB(sqlDB, app.addLogger(CachedDBLogger, lg).Zap(),
 		datastore.WithConfig(app.Config.Cache),
@@ -2079,6 +2432,14 @@ func (app *App) startSynchronous(ctx context.Context) (err error) {
 		)
 	}
 
+	if app.Config.FaultInjection {
+		if !activation.FaultInjectionEnabled {
+			return errors.New("fault injection requires a node built with the faultinjection tag")
+		}
+		logger.With().Warning("fault injection is enabled", log.String("path", grpcserver.FaultsPath))
+		app.faults = activation.NewFaultInjector(logger.Zap().Named("faults"))
+	}
+
 	/* Setup monitoring */
 	app.errCh = make(chan error, 100)
 	if app.Config.PprofHTTPServer {
@@ -2151,6 +2512,11 @@ func (app *App) startSynchronous(ctx context.Context) (err error) {
 		app.Config.Genesis.GenesisID(),
 		types.GetEffectiveGenesis(),
 	)
+	// nodes with a different hare config (e.g. committee upgrade) split consensus silently,
+	// the hash is logged, exported and compared with peers so that misconfiguration can be spotted
+	hareHash := app.Config.HARE3.Hash(&app.Config.HareEligibility)
+	logger.With().Info("hare config hash", log.String("hash", hareHash.ShortString()))
+	hare3.ReportConfigHash(hareHash)
 	// Prevent testnet nodes from working on the mainnet, but
 	// don't use the network cookie on mainnet as this technique
 	// may be replaced later
@@ -2164,6 +2530,7 @@ func (app *App) startSynchronous(ctx context.Context) (err error) {
 	if err != nil {
 		return fmt.Errorf("initialize p2p host: %w", err)
 	}
+	app.hareConfig = hare3.NewConfigExchange(p2plog.Zap().Named("hare_config"), app.host, hareHash)
 
 	if err := app.setupDBs(ctx, logger); err != nil {
 		return err
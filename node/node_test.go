@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -38,6 +39,7 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/activation"
 	"github.com/spacemeshos/go-spacemesh/api/grpcserver"
+	"github.com/spacemeshos/go-spacemesh/atxsdata"
 	"github.com/spacemeshos/go-spacemesh/beacon"
 	"github.com/spacemeshos/go-spacemesh/cmd"
 	"github.com/spacemeshos/go-spacemesh/common/types"
@@ -46,10 +48,14 @@ import (
 	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
+	"github.com/spacemeshos/go-spacemesh/hare3/eligibility"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/log/logtest"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/hareoutputs"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 	"github.com/spacemeshos/go-spacemesh/timesync"
 )
 
@@ -340,6 +346,189 @@ func TestSpacemeshApp_JsonService(t *testing.T) {
 	require.Equal(t, message, msg.Msg.Value)
 }
 
+// newIdleBuilder returns an ATX builder without identities for the tests of the smesher service.
+func newIdleBuilder(t *testing.T, app *App) *activation.Builder {
+	return activation.NewBuilder(
+		activation.Config{},
+		app.db,
+		atxsdata.New(),
+		localsql.InMemoryTest(t),
+		nil,
+		nil,
+		nil,
+		nil,
+		zaptest.NewLogger(t),
+	)
+}
+
+// TestSpacemeshApp_DebugJsonService checks that endpoints that have no counterpart in the api
+// protobufs are reachable for private services on the debug JSON listener.
+func TestSpacemeshApp_DebugJsonService(t *testing.T) {
+	id := types.RandomNodeID()
+	for _, tc := range []struct {
+		desc     string
+		services []grpcserver.Service
+		// setup is called before the services are started
+		setup  func(*testing.T, *App)
+		method string
+		path   string
+		status int
+		check  func(t *testing.T, body []byte)
+	}{
+		{
+			desc:     "snapshot",
+			services: []grpcserver.Service{grpcserver.Admin},
+			method:   http.MethodPost,
+			path:     grpcserver.SnapshotPath,
+			status:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp grpcserver.SnapshotResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				require.DirExists(t, resp.Path)
+			},
+		},
+		{
+			desc:     "nipost errors",
+			services: []grpcserver.Service{grpcserver.Smesher},
+			setup: func(t *testing.T, app *App) {
+				app.atxBuilder = newIdleBuilder(t, app)
+			},
+			method: http.MethodGet,
+			path:   grpcserver.NIPostErrorsPath,
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp []grpcserver.NIPostErrorResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				require.Empty(t, resp)
+			},
+		},
+		{
+			desc:     "hare output",
+			services: []grpcserver.Service{grpcserver.Debug},
+			setup: func(t *testing.T, app *App) {
+				app.localDB = localsql.InMemoryTest(t)
+				require.NoError(t, hareoutputs.Set(app.localDB, &hareoutputs.Output{
+					Layer:     types.LayerID(5),
+					Hash:      types.RandomHash(),
+					Proposals: []types.ProposalID{types.RandomProposalID()},
+				}))
+			},
+			method: http.MethodGet,
+			path:   strings.Replace(grpcserver.HareOutputPath, "{layer}", "5", 1),
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp grpcserver.HareOutputResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				require.Equal(t, uint32(5), resp.Layer)
+				require.Len(t, resp.Proposals, 1)
+			},
+		},
+		{
+			desc:     "poets purge",
+			services: []grpcserver.Service{grpcserver.Admin},
+			setup: func(t *testing.T, app *App) {
+				app.poetResidue = activation.NewPoetResidueChecker(zaptest.NewLogger(t), localsql.InMemoryTest(t), nil)
+			},
+			method: http.MethodPost,
+			path:   grpcserver.PoetsPurgePath,
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp grpcserver.PoetsPurgeResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				require.Empty(t, resp.Purged)
+			},
+		},
+		{
+			// the request is rejected by the handler and not by the gateway, so the path is routed
+			desc:     "hare eligibility",
+			services: []grpcserver.Service{grpcserver.Debug},
+			method:   http.MethodGet,
+			path:     strings.NewReplacer("{epoch}", "2", "{id}", "invalid").Replace(grpcserver.HareEligibilityPath),
+			status:   http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				require.Contains(t, string(body), "failed to parse node id `invalid`")
+			},
+		},
+		{
+			// the node has no poets without smeshing, the handler reports it instead of the gateway failing to route
+			desc:     "poets preflight",
+			services: []grpcserver.Service{grpcserver.Smesher},
+			method:   http.MethodPost,
+			path:     strings.Replace(grpcserver.PoetsPreflightPath, "{id}", hex.EncodeToString(id.Bytes()), 1),
+			status:   http.StatusServiceUnavailable,
+			check: func(t *testing.T, body []byte) {
+				require.Contains(t, string(body), "node is not configured for smeshing")
+			},
+		},
+		{
+			desc:     "readiness",
+			services: []grpcserver.Service{grpcserver.Smesher},
+			setup: func(t *testing.T, app *App) {
+				app.atxBuilder = newIdleBuilder(t, app)
+				app.readiness = activation.NewReadinessChecker(
+					zaptest.NewLogger(t),
+					activation.ReadinessConfig{},
+					activation.PoetConfig{},
+					nil,
+					app.atxBuilder,
+					nil,
+					nil,
+				)
+			},
+			method: http.MethodGet,
+			path:   strings.Replace(grpcserver.ReadinessPath, "{id}", hex.EncodeToString(id.Bytes()), 1),
+			status: http.StatusNotFound,
+			check: func(t *testing.T, body []byte) {
+				require.Contains(t, string(body), fmt.Sprintf("identity %s is not managed by the node", id))
+			},
+		},
+		{
+			desc:     "certificates recertify",
+			services: []grpcserver.Service{grpcserver.Admin},
+			setup: func(t *testing.T, app *App) {
+				app.poetCertifier = activation.NewCertifier(localsql.InMemoryTest(t), zaptest.NewLogger(t), nil)
+			},
+			method: http.MethodPost,
+			path:   grpcserver.CertificatesRecertifyPath,
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp grpcserver.CertificatesRecertifyResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				require.Empty(t, resp.Certificates)
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := getTestDefaultConfig(t)
+			cfg.API.DebugJSONListener = "127.0.0.1:0"
+			cfg.API.PublicServices = nil
+			cfg.API.PrivateServices = tc.services
+			app := New(WithConfig(cfg), WithLog(logtest.New(t)))
+			app.db = statesql.InMemoryTest(t)
+			if tc.setup != nil {
+				tc.setup(t, app)
+			}
+
+			require.NoError(t, app.startAPIServices(context.Background()))
+			t.Cleanup(func() { app.stopServices(context.Background()) })
+			require.Nil(t, app.jsonAPIServer)
+			require.NotNil(t, app.debugJSONServer)
+
+			url := fmt.Sprintf("http://%s%s", app.debugJSONServer.BoundAddress, tc.path)
+			req, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tc.status, resp.StatusCode, string(body))
+			tc.check(t, body)
+		})
+	}
+}
+
 type noopHook struct{}
 
 func (f *noopHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
@@ -840,6 +1029,21 @@ func TestConfig_Load(t *testing.T) {
 	})
 }
 
+func TestConfig_ConfidenceUpgrades(t *testing.T) {
+	conf := config.Config{}
+	data := `{"hare-eligibility": {"eligibility-confidence-upgrades": [
+		{"layer": 4032, "param": 100},
+		{"layer": 8064, "param": 200}
+	]}}`
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	require.NoError(t, loadConfig(&conf, "", path))
+	require.Equal(t, []eligibility.ConfidenceUpgrade{
+		{Layer: 4032, Param: 100},
+		{Layer: 8064, Param: 200},
+	}, conf.HareEligibility.ConfidenceUpgrades)
+}
+
 func TestConfig_GenesisAccounts(t *testing.T) {
 	conf := config.Config{
 		Genesis: config.GenesisConfig{
@@ -1301,6 +1505,7 @@ func getTestDefaultConfig(tb testing.TB) *config.Config {
 	cfg.Tortoise.Zdist = 5
 
 	cfg.HareEligibility.ConfidenceParam = 1
+	cfg.HareEligibility.WarmupLayers = 1
 	cfg.Sync.Interval = 2 * time.Second
 
 	cfg.FETCH.RequestTimeout = 10 * time.Second
@@ -30,6 +30,13 @@ var (
 		[]string{"protocol", "result"},
 		prometheus.ExponentialBuckets(1_000_000, 4, 10),
 	)
+	// PeerPenalties is the total application specific score penalty assigned to peers. Labeled by protocol.
+	PeerPenalties = metrics.NewCounter(
+		"peer_penalties",
+		subsystem,
+		"Total application specific score penalty assigned to peers",
+		[]string{"protocol"},
+	)
 	deliveredMessagesBytes = metrics.NewCounter(
 		"delivered_messages_bytes",
 		subsystem,
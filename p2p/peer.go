@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
@@ -18,8 +19,15 @@ type PeerInfo struct {
 	Connections []ConnectionInfo
 	ClientStats PeerRequestStats
 	ServerStats PeerRequestStats
-	DataStats   DataStats
-	Tags        []string
+	// ProtocolStats are ClientStats per protocol.
+	ProtocolStats map[protocol.ID]PeerRequestStats
+	DataStats     DataStats
+	// EchoRTT and ClockOffset are the results of the latest echo health check, zero if there was none.
+	EchoRTT     time.Duration
+	ClockOffset time.Duration
+	// RequestAnomalies is the number of times the peer was flagged for sending requests with anomalous sizes.
+	RequestAnomalies int64
+	Tags             []string
 }
 
 type DataStats struct {
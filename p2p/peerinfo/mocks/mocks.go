@@ -41,6 +41,44 @@ func (m *MockPeerInfo) EXPECT() *MockPeerInfoMockRecorder {
 	return m.recorder
 }
 
+// Capabilities mocks base method.
+func (m *MockPeerInfo) Capabilities() map[protocol.ID][]peer.ID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capabilities")
+	ret0, _ := ret[0].(map[protocol.ID][]peer.ID)
+	return ret0
+}
+
+// Capabilities indicates an expected call of Capabilities.
+func (mr *MockPeerInfoMockRecorder) Capabilities() *MockPeerInfoCapabilitiesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capabilities", reflect.TypeOf((*MockPeerInfo)(nil).Capabilities))
+	return &MockPeerInfoCapabilitiesCall{Call: call}
+}
+
+// MockPeerInfoCapabilitiesCall wrap *gomock.Call
+type MockPeerInfoCapabilitiesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPeerInfoCapabilitiesCall) Return(arg0 map[protocol.ID][]peer.ID) *MockPeerInfoCapabilitiesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPeerInfoCapabilitiesCall) Do(f func() map[protocol.ID][]peer.ID) *MockPeerInfoCapabilitiesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPeerInfoCapabilitiesCall) DoAndReturn(f func() map[protocol.ID][]peer.ID) *MockPeerInfoCapabilitiesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // EnsurePeerInfo mocks base method.
 func (m *MockPeerInfo) EnsurePeerInfo(p peer.ID) *peerinfo.Info {
 	m.ctrl.T.Helper()
@@ -3,6 +3,7 @@ package peerinfo
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jonboulle/clockwork"
@@ -74,6 +75,45 @@ func (ps *PeerRequestStats) RequestDone(took time.Duration, success bool) {
 	ps.duration += took
 }
 
+// EchoStats are the results of the latest echo health check of the peer.
+type EchoStats struct {
+	mtx     sync.Mutex
+	rtt     time.Duration
+	offset  time.Duration
+	updated time.Time
+}
+
+// Record stores the round-trip time and the clock offset of the peer measured at time now.
+// The offset is positive if the clock of the peer is ahead of the local one.
+func (es *EchoStats) Record(rtt, offset time.Duration, now time.Time) {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	es.rtt = rtt
+	es.offset = offset
+	es.updated = now
+}
+
+// RTT returns the latest measured round-trip time, or 0 if the peer wasn't checked yet.
+func (es *EchoStats) RTT() time.Duration {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	return es.rtt
+}
+
+// ClockOffset returns the latest measured clock offset of the peer.
+func (es *EchoStats) ClockOffset() time.Duration {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	return es.offset
+}
+
+// Updated returns the time of the latest successful check, or zero time if there was none.
+func (es *EchoStats) Updated() time.Time {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	return es.updated
+}
+
 type DataStats struct {
 	mtx sync.Mutex
 	// [0] is the current value
@@ -158,6 +198,14 @@ type Info struct {
 	connKinds   sync.Map
 	ClientStats PeerRequestStats
 	ServerStats PeerRequestStats
+	// Echo holds the results of the echo health check of the peer.
+	Echo EchoStats
+	// RequestAnomalies is the number of times the peer was flagged by servers
+	// for sending requests with anomalous sizes.
+	RequestAnomalies atomic.Int64
+	// protoStats holds ClientStats per protocol, i.e. how the peer serves requests
+	// of each protocol.
+	protoStats sync.Map
 }
 
 func (i *Info) Kind(c network.Conn) Kind {
@@ -172,6 +220,27 @@ func (i *Info) SetKind(c network.Conn, k Kind) {
 	i.connKinds.Store(c.ID(), k)
 }
 
+// ProtoStats returns the stats of requests made to the peer via protocol proto,
+// allocating them if they don't exist yet.
+func (i *Info) ProtoStats(proto protocol.ID) *PeerRequestStats {
+	if s, ok := i.protoStats.Load(proto); ok {
+		return s.(*PeerRequestStats)
+	}
+	s, _ := i.protoStats.LoadOrStore(proto, &PeerRequestStats{})
+	return s.(*PeerRequestStats)
+}
+
+// AllProtoStats returns the stats of requests made to the peer for all protocols
+// that were used so far.
+func (i *Info) AllProtoStats() map[protocol.ID]*PeerRequestStats {
+	r := make(map[protocol.ID]*PeerRequestStats)
+	i.protoStats.Range(func(k, v any) bool {
+		r[k.(protocol.ID)] = v.(*PeerRequestStats)
+		return true
+	})
+	return r
+}
+
 //go:generate mockgen -typed -package=peerinfo -destination=./mocks/mocks.go -source=./peerinfo.go
 
 // PeerInfo provides peer-related connection status and statistics.
@@ -191,6 +260,9 @@ type PeerInfo interface {
 	// EnsureProtoStats returns DataStats structure for the specified protocol,
 	// allocating one if it doesn't exist yet.
 	EnsureProtoStats(proto protocol.ID) *DataStats
+	// Capabilities returns the connected peers that have successfully served at
+	// least one request, grouped by protocol.
+	Capabilities() map[protocol.ID][]peer.ID
 }
 
 type PeerInfoTracker struct {
@@ -352,6 +424,20 @@ func (t *PeerInfoTracker) Protocols() []protocol.ID {
 	return maps.Keys(t.protoStats)
 }
 
+func (t *PeerInfoTracker) Capabilities() map[protocol.ID][]peer.ID {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	r := make(map[protocol.ID][]peer.ID)
+	for p, info := range t.info {
+		for proto, stats := range info.AllProtoStats() {
+			if stats.SuccessCount() > 0 {
+				r[proto] = append(r[proto], p)
+			}
+		}
+	}
+	return r
+}
+
 type HolePunchTracer struct {
 	pi   PeerInfo
 	next holepunch.MetricsTracer
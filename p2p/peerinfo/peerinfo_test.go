@@ -6,6 +6,7 @@ import (
 
 	"github.com/jonboulle/clockwork"
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
 	"github.com/libp2p/go-libp2p/p2p/protocol/holepunch"
@@ -119,6 +120,27 @@ func TestPeerInfoTracker(t *testing.T) {
 	}
 }
 
+func TestCapabilities(t *testing.T) {
+	pt := NewPeerInfoTracker()
+	require.Empty(t, pt.Capabilities())
+
+	p1, p2 := peer.ID("p1"), peer.ID("p2")
+	pt.EnsurePeerInfo(p1).ProtoStats("foo").RequestDone(time.Second, true)
+	pt.EnsurePeerInfo(p1).ProtoStats("bar").RequestDone(time.Second, false)
+	pt.EnsurePeerInfo(p2).ProtoStats("foo").RequestDone(time.Second, true)
+	pt.EnsurePeerInfo(p2).ProtoStats("bar").RequestDone(2*time.Second, true)
+
+	caps := pt.Capabilities()
+	require.Len(t, caps, 2)
+	require.ElementsMatch(t, []peer.ID{p1, p2}, caps["foo"])
+	require.Equal(t, []peer.ID{p2}, caps["bar"])
+
+	stats := pt.EnsurePeerInfo(p1).AllProtoStats()
+	require.Len(t, stats, 2)
+	require.Equal(t, 1, stats["foo"].SuccessCount())
+	require.Equal(t, 1, stats["bar"].FailureCount())
+}
+
 type fakeMetricsTracer struct {
 	dialCount      int
 	holePunchCount int
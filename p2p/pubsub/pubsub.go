@@ -101,7 +101,8 @@ type Config struct {
 // New creates PubSub instance.
 func New(ctx context.Context, logger *zap.Logger, h host.Host, cfg Config) (*GossipPubSub, error) {
 	// TODO(dshulyak) refactor code to accept options
-	opts := getOptions(cfg)
+	scores := newAppScore()
+	opts := getOptions(cfg, scores)
 	ps, err := pubsub.NewGossipSub(ctx, h, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize gossipsub instance: %w", err)
@@ -111,6 +112,7 @@ func New(ctx context.Context, logger *zap.Logger, h host.Host, cfg Config) (*Gos
 		pubsub: ps,
 		topics: map[string]*pubsub.Topic{},
 		host:   h,
+		scores: scores,
 	}, nil
 }
 
@@ -208,7 +210,7 @@ func msgID(msg *pubsubpb.Message) string {
 	return string(hasher.Sum(nil))
 }
 
-func getOptions(cfg Config) []pubsub.Option {
+func getOptions(cfg Config, scores *appScore) []pubsub.Option {
 	boots := map[peer.ID]struct{}{}
 	for _, addr := range cfg.Bootnodes {
 		boots[addr.ID] = struct{}{}
@@ -232,7 +234,8 @@ func getOptions(cfg Config) []pubsub.Option {
 					if exist && !cfg.IsBootnode {
 						return 10000
 					}
-					return 0
+					// penalties reported by handlers with WithPenalty
+					return scores.score(p)
 				},
 				AppSpecificWeight: 1,
 
@@ -0,0 +1,116 @@
+package pubsub
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// penaltyHalfLife is the time it takes for an application specific penalty to decay by half.
+	penaltyHalfLife = 10 * time.Minute
+	// penaltyDecayToZero is the value below which a penalty is forgotten.
+	penaltyDecayToZero = 0.1
+	// penaltyThreshold is the decayed penalty that doesn't affect the score of the peer yet.
+	// The application score counts for the peer on every topic and any negative score prunes it
+	// from the meshes, so a peer that occasionally relays an invalid message keeps a neutral score.
+	penaltyThreshold = 100
+)
+
+// penaltyError attaches an application specific score penalty to the error returned by a GossipHandler.
+type penaltyError struct {
+	err     error
+	penalty float64
+}
+
+func (e *penaltyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *penaltyError) Unwrap() error {
+	return e.err
+}
+
+// WithPenalty wraps an error returned by a GossipHandler so that the application specific
+// score of the peer that relayed the message is decreased by penalty.
+// Wrapped errors are still matched by errors.Is, e.g. against ErrValidationReject.
+func WithPenalty(err error, penalty float64) error {
+	if err == nil {
+		return nil
+	}
+	return &penaltyError{err: err, penalty: penalty}
+}
+
+// Penalty returns the penalty attached to the error with WithPenalty or 0.
+func Penalty(err error) float64 {
+	var perr *penaltyError
+	if errors.As(err, &perr) {
+		return perr.penalty
+	}
+	return 0
+}
+
+type penalty struct {
+	value   float64
+	updated time.Time
+}
+
+func (p *penalty) decayed(now time.Time) float64 {
+	return p.value * math.Exp2(-float64(now.Sub(p.updated))/float64(penaltyHalfLife))
+}
+
+// appScore tracks application specific penalties of peers.
+// Penalties decay exponentially over time, so that a peer that stops relaying
+// invalid messages eventually recovers its score.
+type appScore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	penalties map[peer.ID]*penalty
+}
+
+func newAppScore() *appScore {
+	return &appScore{
+		now:       time.Now,
+		penalties: map[peer.ID]*penalty{},
+	}
+}
+
+func (s *appScore) penalize(pid peer.ID, value float64) {
+	if value <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	p, exist := s.penalties[pid]
+	if !exist {
+		s.penalties[pid] = &penalty{value: value, updated: now}
+		return
+	}
+	p.value = p.decayed(now) + value
+	p.updated = now
+}
+
+// score returns the current (non-positive) penalty score of the peer.
+// Only the part of the penalty above penaltyThreshold is subtracted from the score.
+func (s *appScore) score(pid peer.ID) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, exist := s.penalties[pid]
+	if !exist {
+		return 0
+	}
+	value := p.decayed(s.now())
+	if value < penaltyDecayToZero {
+		delete(s.penalties, pid)
+		return 0
+	}
+	if value <= penaltyThreshold {
+		return 0
+	}
+	return penaltyThreshold - value
+}
@@ -0,0 +1,64 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPenalty(t *testing.T) {
+	require.NoError(t, WithPenalty(nil, 10))
+	require.Zero(t, Penalty(errors.New("test")))
+
+	err := WithPenalty(fmt.Errorf("%w: test", ErrValidationReject), 10)
+	require.ErrorIs(t, err, ErrValidationReject)
+	require.Equal(t, "validation reject: test", err.Error())
+	require.Equal(t, 10.0, Penalty(err))
+	require.Equal(t, 10.0, Penalty(fmt.Errorf("wrapped: %w", err)))
+}
+
+func TestAppScore(t *testing.T) {
+	now := time.Now()
+	scores := newAppScore()
+	scores.now = func() time.Time { return now }
+	pid := peer.ID("test")
+
+	require.Zero(t, scores.score(pid))
+	scores.penalize(pid, 0)
+	require.Zero(t, scores.score(pid))
+
+	scores.penalize(pid, 200)
+	scores.penalize(pid, 200)
+	require.Equal(t, -300.0, scores.score(pid))
+
+	now = now.Add(penaltyHalfLife)
+	require.InDelta(t, -100.0, scores.score(pid), 0.001)
+	scores.penalize(pid, 50)
+	require.InDelta(t, -150.0, scores.score(pid), 0.001)
+
+	// the penalty decayed below the threshold
+	now = now.Add(2 * penaltyHalfLife)
+	require.Zero(t, scores.score(pid))
+	require.NotEmpty(t, scores.penalties)
+
+	now = now.Add(20 * penaltyHalfLife)
+	require.Zero(t, scores.score(pid))
+	require.Empty(t, scores.penalties)
+}
+
+func TestAppScore_Threshold(t *testing.T) {
+	scores := newAppScore()
+	pid := peer.ID("test")
+
+	// a single invalid message relayed by an honest peer doesn't make its score negative,
+	// as a negative score prunes the peer from the meshes of all topics
+	scores.penalize(pid, 1)
+	require.Zero(t, scores.score(pid))
+
+	scores.penalize(pid, penaltyThreshold)
+	require.Negative(t, scores.score(pid))
+}
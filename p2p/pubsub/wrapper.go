@@ -50,6 +50,8 @@ type GossipPubSub struct {
 	pubsub *pubsub.PubSub
 	host   host.Host
 
+	scores *appScore
+
 	mu     sync.RWMutex
 	topics map[string]*pubsub.Topic
 }
@@ -74,6 +76,10 @@ func (ps *GossipPubSub) Register(topic string, handler GossipHandler, opts ...Va
 				Observe(float64(time.Since(start)))
 			if err != nil {
 				ps.logger.Debug("topic validation failed", zap.String("topic", topic), zap.Error(err))
+				if penalty := Penalty(err); penalty > 0 {
+					ps.scores.penalize(pid, penalty)
+					metrics.PeerPenalties.WithLabelValues(topic).Add(penalty)
+				}
 			}
 			switch {
 			case errors.Is(err, ErrValidationReject):
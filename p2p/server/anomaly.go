@@ -0,0 +1,99 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// nearLimitRatio is the fraction of the request size limit from which request is near-limit.
+	nearLimitRatio = 0.9
+	// outlierRatio is how many times request must be larger than the average request of
+	// the protocol to be an outlier.
+	outlierRatio = 8
+	// minSamples is the number of requests needed to compute the average request of the protocol.
+	minSamples = 100
+	// anomalyWindow is the number of requests from a peer that are evaluated together.
+	anomalyWindow = 20
+	// maxTrackedPeers bounds the memory used for tracking request sizes,
+	// the peer that sent a request least recently is evicted first.
+	maxTrackedPeers = 10000
+)
+
+type sizeAnomaly string
+
+const (
+	anomalyNone      sizeAnomaly = ""
+	anomalyNearLimit sizeAnomaly = "near_limit"
+	anomalyOutlier   sizeAnomaly = "outlier"
+)
+
+type peerSizes struct {
+	total, nearLimit, outliers int
+}
+
+// sizeTracker flags peers that consistently send requests near the size limit,
+// or requests that are much larger than the average request of the protocol.
+//
+// Requests from every peer are evaluated in windows of anomalyWindow requests, the peer
+// is flagged if at least half of the requests in the window are anomalous. Peers that
+// are idle or disconnected are evicted when more than maxTrackedPeers are tracked.
+type sizeTracker struct {
+	limit int
+
+	mu      sync.Mutex
+	samples int
+	avg     float64
+	peers   *simplelru.LRU[peer.ID, *peerSizes]
+}
+
+func newSizeTracker(limit int) *sizeTracker {
+	peers, err := simplelru.NewLRU[peer.ID, *peerSizes](maxTrackedPeers, nil)
+	if err != nil {
+		panic(err) // fails only if size is not positive
+	}
+	return &sizeTracker{
+		limit: limit,
+		peers: peers,
+	}
+}
+
+// observe records the size of the request from the peer and returns non-empty anomaly
+// if the peer is flagged after this request.
+func (t *sizeTracker) observe(pid peer.ID, size int) sizeAnomaly {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	outlier := t.samples >= minSamples && float64(size) > outlierRatio*t.avg
+	if !outlier {
+		// outliers are excluded so that a peer can't shift the average with large requests.
+		// cumulative moving average until there are enough samples, exponential afterwards
+		t.samples++
+		t.avg += (float64(size) - t.avg) / float64(min(t.samples, minSamples))
+	}
+
+	sizes, exist := t.peers.Get(pid)
+	if !exist {
+		sizes = &peerSizes{}
+		t.peers.Add(pid, sizes)
+	}
+	sizes.total++
+	if float64(size) >= nearLimitRatio*float64(t.limit) {
+		sizes.nearLimit++
+	}
+	if outlier {
+		sizes.outliers++
+	}
+	if sizes.total < anomalyWindow {
+		return anomalyNone
+	}
+	t.peers.Remove(pid)
+	switch {
+	case 2*sizes.nearLimit >= sizes.total:
+		return anomalyNearLimit
+	case 2*sizes.outliers >= sizes.total:
+		return anomalyOutlier
+	}
+	return anomalyNone
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeTracker(t *testing.T) {
+	const limit = 1000
+	t.Run("near limit", func(t *testing.T) {
+		tracker := newSizeTracker(limit)
+		pid := peer.ID("near")
+		for i := 0; i < anomalyWindow-1; i++ {
+			size := limit
+			if i%2 == 0 {
+				size = 10
+			}
+			require.Equal(t, anomalyNone, tracker.observe(pid, size))
+		}
+		require.Equal(t, anomalyNearLimit, tracker.observe(pid, limit))
+		require.Zero(t, tracker.peers.Len())
+	})
+	t.Run("outlier", func(t *testing.T) {
+		tracker := newSizeTracker(limit)
+		for i := 0; i < minSamples; i++ {
+			require.Equal(t, anomalyNone, tracker.observe(peer.ID(rune(i)), 10))
+		}
+		require.InDelta(t, 10.0, tracker.avg, 0.001)
+		pid := peer.ID("outlier")
+		var anomaly sizeAnomaly
+		for i := 0; i < anomalyWindow; i++ {
+			anomaly = tracker.observe(pid, 100)
+		}
+		require.Equal(t, anomalyOutlier, anomaly)
+	})
+	t.Run("normal", func(t *testing.T) {
+		tracker := newSizeTracker(limit)
+		pid := peer.ID("normal")
+		for i := 0; i < 3*anomalyWindow; i++ {
+			require.Equal(t, anomalyNone, tracker.observe(pid, 10+i))
+		}
+	})
+	t.Run("bounded", func(t *testing.T) {
+		tracker := newSizeTracker(limit)
+		active := peer.ID("active")
+		for i := 0; i < maxTrackedPeers+10; i++ {
+			tracker.observe(peer.ID(rune(i)), 10)
+			tracker.observe(active, 10)
+		}
+		require.Equal(t, maxTrackedPeers, tracker.peers.Len())
+		require.False(t, tracker.peers.Contains(peer.ID(rune(0))), "idle peer must be evicted")
+		require.True(t, tracker.peers.Contains(peer.ID(rune(maxTrackedPeers+9))))
+		require.True(t, tracker.peers.Contains(active))
+	})
+}
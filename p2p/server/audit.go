@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-yamux/v4"
+)
+
+// AuditOutcome is the stage at which the handling of a request ended.
+type AuditOutcome string
+
+const (
+	// AuditServed is reported for requests that were handled successfully.
+	AuditServed AuditOutcome = "served"
+	// AuditReadFailed is reported for requests that couldn't be read from the stream.
+	AuditReadFailed AuditOutcome = "read_failed"
+	// AuditWriteFailed is reported for requests whose response couldn't be written to the stream.
+	AuditWriteFailed AuditOutcome = "write_failed"
+	// AuditHandlerFailed is reported for requests for which the handler returned an error.
+	AuditHandlerFailed AuditOutcome = "handler_failed"
+)
+
+// ErrorClass is a coarse classification of the error that ended the handling of a request.
+type ErrorClass string
+
+const (
+	ErrorClassNone     ErrorClass = ""
+	ErrorClassTooLarge ErrorClass = "too_large"
+	ErrorClassTimeout  ErrorClass = "timeout"
+	ErrorClassCanceled ErrorClass = "canceled"
+	ErrorClassStream   ErrorClass = "stream"
+	ErrorClassHandler  ErrorClass = "handler"
+)
+
+func classifyError(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case errors.Is(err, errFrameTooLarge):
+		return ErrorClassTooLarge
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, os.ErrDeadlineExceeded),
+		errors.Is(err, yamux.ErrTimeout):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, network.ErrReset):
+		return ErrorClassStream
+	}
+	return ErrorClassHandler
+}
+
+// AuditRecord describes a single request handled by the server.
+type AuditRecord struct {
+	Peer     peer.ID
+	Protocol string
+	// RequestSize is the size of the request body, zero if the request couldn't be read.
+	RequestSize int
+	// ResponseSize is the number of bytes written to the stream in response to the request.
+	ResponseSize int
+	Duration     time.Duration
+	Outcome      AuditOutcome
+	ErrorClass   ErrorClass
+}
+
+// AuditSink receives a record for every request handled by the server.
+//
+// Record is called synchronously from the goroutine that handles the request,
+// implementations should not block.
+type AuditSink interface {
+	Record(AuditRecord)
+}
+
+// audit submits the record to the audit sink, if it is configured.
+func (s *Server) audit(
+	pid peer.ID,
+	start time.Time,
+	requestSize, responseSize int,
+	outcome AuditOutcome,
+	err error,
+) {
+	if s.auditSink == nil {
+		return
+	}
+	s.auditSink.Record(AuditRecord{
+		Peer:         pid,
+		Protocol:     s.protocol,
+		RequestSize:  requestSize,
+		ResponseSize: responseSize,
+		Duration:     time.Since(start),
+		Outcome:      outcome,
+		ErrorClass:   classifyError(err),
+	})
+}
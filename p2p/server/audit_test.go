@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/libp2p/go-yamux/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+)
+
+type auditRecorder struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (r *auditRecorder) Record(record AuditRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+}
+
+func (r *auditRecorder) get() []AuditRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]AuditRecord(nil), r.records...)
+}
+
+func TestServer_Audit(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	proto := "test"
+	testErr := errors.New("test error")
+	handler := func(_ context.Context, msg []byte) ([]byte, error) {
+		if string(msg) == "fail" {
+			return nil, testErr
+		}
+		return msg, nil
+	}
+	sink := &auditRecorder{}
+	client := New(wrapHost(t, mesh.Hosts()[0]), proto, WrapHandler(handler), WithLog(zaptest.NewLogger(t)))
+	srv := New(
+		wrapHost(t, mesh.Hosts()[1]),
+		proto,
+		WrapHandler(handler),
+		WithLog(zaptest.NewLogger(t)),
+		WithAuditSink(sink),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return srv.Run(ctx)
+	})
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+	require.Eventually(t, func() bool {
+		return len(mesh.Hosts()[1].Mux().Protocols()) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = client.Request(ctx, mesh.Hosts()[1].ID(), []byte("request"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(sink.get()) == 1
+	}, time.Second, 10*time.Millisecond)
+	_, err = client.Request(ctx, mesh.Hosts()[1].ID(), []byte("fail"))
+	require.ErrorContains(t, err, testErr.Error())
+
+	require.Eventually(t, func() bool {
+		return len(sink.get()) == 2
+	}, time.Second, 10*time.Millisecond)
+	records := sink.get()
+	for _, record := range records {
+		require.Equal(t, mesh.Hosts()[0].ID(), record.Peer)
+		require.Equal(t, proto, record.Protocol)
+		require.Positive(t, record.Duration)
+		require.Positive(t, record.ResponseSize)
+	}
+	require.Equal(t, len("request"), records[0].RequestSize)
+	require.Equal(t, AuditServed, records[0].Outcome)
+	require.Equal(t, ErrorClassNone, records[0].ErrorClass)
+	require.Equal(t, len("fail"), records[1].RequestSize)
+	require.Equal(t, AuditHandlerFailed, records[1].Outcome)
+	require.Equal(t, ErrorClassHandler, records[1].ErrorClass)
+}
+
+func TestClassifyError(t *testing.T) {
+	for _, tc := range []struct {
+		err   error
+		class ErrorClass
+	}{
+		{nil, ErrorClassNone},
+		{fmt.Errorf("read: %w", errFrameTooLarge), ErrorClassTooLarge},
+		{&deadlineAdjusterError{innerErr: yamux.ErrTimeout}, ErrorClassTimeout},
+		{context.DeadlineExceeded, ErrorClassTimeout},
+		{context.Canceled, ErrorClassCanceled},
+		{io.ErrUnexpectedEOF, ErrorClassStream},
+		{errors.New("test"), ErrorClassHandler},
+	} {
+		require.Equal(t, tc.class, classifyError(tc.err), "%v", tc.err)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+//go:generate scalegen -types Deprecation,ResponseMetadata
+
+// deprecatedPeersWindow is how long a peer is counted as a user of a deprecated protocol
+// after its last request.
+const deprecatedPeersWindow = time.Hour
+
+// Deprecation describes when a deprecated protocol stops being served.
+type Deprecation struct {
+	// SunsetLayer is the first layer in which the protocol is no longer served, zero if not scheduled.
+	SunsetLayer uint32
+	// SunsetTime is the unix time in seconds after which the protocol is no longer served,
+	// zero if not scheduled.
+	SunsetTime uint64
+	// Replacement is the protocol that should be used instead, if any.
+	Replacement string `scale:"max=256"`
+}
+
+// ResponseMetadata is written after a successful Response on single request streams of
+// deprecated protocols served with WrapHandler. Clients that don't expect it ignore it, as
+// they stop reading after the Response. It never follows the output of other StreamHandlers,
+// as their clients may read the stream until EOF. On pipelined streams it follows every
+// successful Response, even if the protocol is not deprecated, as the stream carries further
+// responses.
+type ResponseMetadata struct {
+	Deprecation *Deprecation
+}
+
+// WithDeprecation marks the protocol as deprecated. Successful responses of handlers wrapped with
+// WrapHandler carry the deprecation notice and the peers that still use the protocol are counted
+// in metrics.
+func WithDeprecation(deprecation Deprecation) Opt {
+	return func(s *Server) {
+		s.deprecation = &deprecation
+	}
+}
+
+// deprecationTracker counts peers that sent requests over a deprecated protocol within the window.
+type deprecationTracker struct {
+	protocol string
+	window   time.Duration
+
+	mu   sync.Mutex
+	seen map[peer.ID]time.Time
+	// pruned is the time when peers outside of the window were removed last time.
+	pruned time.Time
+}
+
+func newDeprecationTracker(protocol string, window time.Duration) *deprecationTracker {
+	return &deprecationTracker{
+		protocol: protocol,
+		window:   window,
+		seen:     make(map[peer.ID]time.Time),
+	}
+}
+
+func (d *deprecationTracker) observe(pid peer.ID, now time.Time) {
+	deprecatedRequests.WithLabelValues(d.protocol).Inc()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seen[pid] = now
+	if now.Sub(d.pruned) > d.window/60 {
+		for pid, last := range d.seen {
+			if now.Sub(last) > d.window {
+				delete(d.seen, pid)
+			}
+		}
+		d.pruned = now
+	}
+	deprecatedPeers.WithLabelValues(d.protocol).Set(float64(len(d.seen)))
+}
+
+// peers returns the number of peers that used the protocol within the window.
+func (d *deprecationTracker) peers() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.seen)
+}
+
+// DeprecationNotice returns the latest deprecation notice received in a response
+// of the protocol, or nil if peers didn't report it as deprecated.
+func (s *Server) DeprecationNotice() *Deprecation {
+	return s.notice.Load()
+}
+
+type deprecationKey struct{}
+
+// withDeprecation returns the context that makes a handler wrapped with WrapHandler
+// write the deprecation notice after a successful Response.
+func (s *Server) withDeprecation(ctx context.Context) context.Context {
+	if s.deprecation == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, deprecationKey{}, s)
+}
+
+// writeDeprecation writes the metadata with the deprecation notice if the context was
+// prepared by the server of a deprecated protocol.
+func writeDeprecation(ctx context.Context, w io.Writer) {
+	s, ok := ctx.Value(deprecationKey{}).(*Server)
+	if !ok {
+		return
+	}
+	if err := s.writeMetadata(w); err != nil {
+		s.logger.Debug("error writing response metadata",
+			zap.String("protocol", s.protocol),
+			log.ZContext(ctx),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *Server) writeMetadata(w io.Writer) error {
+	wr := getWriter(w)
+	defer putWriter(wr)
+	if _, err := codec.EncodeTo(wr, &ResponseMetadata{Deprecation: s.deprecation}); err != nil {
+		return err
+	}
+	return wr.Flush()
+}
+
+// readMetadata reads the metadata that follows the response on a single request stream.
+// Servers that don't send it close the stream after the response.
+func (s *Server) readMetadata(pid peer.ID, rd io.Reader) {
+	var md ResponseMetadata
+	if _, err := codec.DecodeFrom(rd, &md); err != nil {
+		return
+	}
+	s.onMetadata(pid, &md)
+}
+
+// onMetadata records the deprecation notice from the metadata of the response, if there is one.
+func (s *Server) onMetadata(pid peer.ID, md *ResponseMetadata) {
+	if md.Deprecation == nil {
+		return
+	}
+	deprecatedResponses.WithLabelValues(s.protocol).Inc()
+	if s.notice.Swap(md.Deprecation) == nil {
+		s.logger.Warn("peer reports protocol as deprecated",
+			zap.String("protocol", s.protocol),
+			zap.Stringer("peer", pid),
+			zap.Uint32("sunset layer", md.Deprecation.SunsetLayer),
+			zap.Time("sunset time", time.Unix(int64(md.Deprecation.SunsetTime), 0)),
+			zap.String("replacement", md.Deprecation.Replacement),
+		)
+	}
+}
@@ -0,0 +1,84 @@
+// Code generated by github.com/spacemeshos/go-scale/scalegen. DO NOT EDIT.
+
+// nolint
+package server
+
+import (
+	"github.com/spacemeshos/go-scale"
+)
+
+func (t *Deprecation) EncodeScale(enc *scale.Encoder) (total int, err error) {
+	{
+		n, err := scale.EncodeCompact32(enc, uint32(t.SunsetLayer))
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	{
+		n, err := scale.EncodeCompact64(enc, uint64(t.SunsetTime))
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	{
+		n, err := scale.EncodeStringWithLimit(enc, string(t.Replacement), 256)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (t *Deprecation) DecodeScale(dec *scale.Decoder) (total int, err error) {
+	{
+		field, n, err := scale.DecodeCompact32(dec)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.SunsetLayer = uint32(field)
+	}
+	{
+		field, n, err := scale.DecodeCompact64(dec)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.SunsetTime = uint64(field)
+	}
+	{
+		field, n, err := scale.DecodeStringWithLimit(dec, 256)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.Replacement = string(field)
+	}
+	return total, nil
+}
+
+func (t *ResponseMetadata) EncodeScale(enc *scale.Encoder) (total int, err error) {
+	{
+		n, err := scale.EncodeOption(enc, t.Deprecation)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (t *ResponseMetadata) DecodeScale(dec *scale.Decoder) (total int, err error) {
+	{
+		field, n, err := scale.DecodeOption[Deprecation](dec)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.Deprecation = field
+	}
+	return total, nil
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestDeprecation(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(5)
+	require.NoError(t, err)
+	proto := "test"
+	request := []byte("test request")
+	handler := WrapHandler(func(_ context.Context, msg []byte) ([]byte, error) {
+		return msg, nil
+	})
+	deprecation := Deprecation{SunsetLayer: 1000, SunsetTime: 1_700_000_000, Replacement: "test/2"}
+	opts := []Opt{WithLog(zaptest.NewLogger(t)), WithTimeout(time.Second)}
+
+	srv := New(wrapHost(t, mesh.Hosts()[0]), proto, handler, append(opts,
+		WithDeprecation(deprecation),
+		WithStreamReuse(time.Second),
+	)...)
+	current := New(wrapHost(t, mesh.Hosts()[1]), proto, handler, opts...)
+	client := New(wrapHost(t, mesh.Hosts()[2]), proto, handler, opts...)
+	pipelined := New(wrapHost(t, mesh.Hosts()[3]), proto, handler, append(opts, WithStreamReuse(time.Second))...)
+	streaming := New(wrapHost(t, mesh.Hosts()[4]), proto,
+		func(_ context.Context, msg []byte, stream io.ReadWriter) error {
+			_, err := stream.Write(msg)
+			return err
+		},
+		append(opts, WithDeprecation(deprecation))...,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error { return srv.Run(ctx) })
+	eg.Go(func() error { return current.Run(ctx) })
+	eg.Go(func() error { return streaming.Run(ctx) })
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+
+	t.Run("not deprecated", func(t *testing.T) {
+		response, err := client.Request(ctx, mesh.Hosts()[1].ID(), request)
+		require.NoError(t, err)
+		require.Equal(t, request, response)
+		require.Nil(t, client.DeprecationNotice())
+	})
+	t.Run("client without metadata support", func(t *testing.T) {
+		var response []byte
+		require.NoError(t, client.StreamRequest(ctx, mesh.Hosts()[0].ID(), request,
+			func(ctx context.Context, stream io.ReadWriter) (err error) {
+				response, err = readResponse(ctx, mesh.Hosts()[0].ID(), stream)
+				return err
+			},
+		))
+		require.Equal(t, request, response)
+		require.Nil(t, client.DeprecationNotice())
+	})
+	t.Run("deprecated", func(t *testing.T) {
+		response, err := client.Request(ctx, mesh.Hosts()[0].ID(), request)
+		require.NoError(t, err)
+		require.Equal(t, request, response)
+		require.Equal(t, &deprecation, client.DeprecationNotice())
+	})
+	t.Run("pipelined", func(t *testing.T) {
+		for range 2 {
+			response, err := pipelined.Request(ctx, mesh.Hosts()[0].ID(), request)
+			require.NoError(t, err)
+			require.Equal(t, request, response)
+		}
+		require.Equal(t, &deprecation, pipelined.DeprecationNotice())
+	})
+	t.Run("stream handler", func(t *testing.T) {
+		var response []byte
+		require.NoError(t, client.StreamRequest(ctx, mesh.Hosts()[4].ID(), request,
+			func(_ context.Context, stream io.ReadWriter) (err error) {
+				response, err = io.ReadAll(stream)
+				return err
+			},
+		))
+		require.Equal(t, request, response)
+		require.Equal(t, 1, streaming.deprecated.peers())
+	})
+	require.Equal(t, 2, srv.deprecated.peers())
+	require.Nil(t, current.deprecated)
+}
+
+func TestDeprecationTracker(t *testing.T) {
+	tracker := newDeprecationTracker("test", time.Hour)
+	now := time.Now()
+	tracker.observe("a", now)
+	tracker.observe("b", now)
+	tracker.observe("a", now.Add(30*time.Minute))
+	require.Equal(t, 2, tracker.peers())
+
+	// b is outside of the window, a was seen within it
+	tracker.observe("c", now.Add(61*time.Minute))
+	require.Equal(t, 2, tracker.peers())
+	tracker.observe("c", now.Add(92*time.Minute))
+	require.Equal(t, 1, tracker.peers())
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// EchoProtocol is the protocol of the built-in health check. The server responds to any request
+	// with its wall clock time, which lets the client measure the round-trip time and the clock offset.
+	EchoProtocol = "/echo/1"
+
+	// echoConcurrency is the maximal number of peers checked at the same time.
+	echoConcurrency = 16
+	// echoTimeSize is the size of the response, the time of the server in nanoseconds since unix epoch.
+	echoTimeSize = 8
+)
+
+// EchoResult is the result of a single echo health check.
+type EchoResult struct {
+	RTT time.Duration
+	// ClockOffset is positive if the clock of the peer is ahead of the local one.
+	ClockOffset time.Duration
+}
+
+type EchoOpt func(e *Echo)
+
+// WithEchoInterval sets how often connected peers are checked. Zero, the default, disables the checks,
+// the echo requests of other peers are still served.
+func WithEchoInterval(interval time.Duration) EchoOpt {
+	return func(e *Echo) {
+		e.interval = interval
+	}
+}
+
+// WithEchoTimeout sets the timeout of a single check.
+func WithEchoTimeout(timeout time.Duration) EchoOpt {
+	return func(e *Echo) {
+		e.timeout = timeout
+	}
+}
+
+func WithEchoLog(log *zap.Logger) EchoOpt {
+	return func(e *Echo) {
+		e.logger = log
+	}
+}
+
+// Echo serves EchoProtocol and periodically checks connected peers with it.
+// Results are stored in the peer info of the host and exposed as metrics.
+type Echo struct {
+	logger   *zap.Logger
+	h        Host
+	srv      *Server
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// NewEcho registers the EchoProtocol handler on the host.
+func NewEcho(h Host, opts ...EchoOpt) *Echo {
+	e := &Echo{
+		logger:  zap.NewNop(),
+		h:       h,
+		timeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.srv = New(h, EchoProtocol, WrapHandler(echoHandler),
+		WithLog(e.logger),
+		WithTimeout(e.timeout),
+		WithHardTimeout(e.timeout),
+		WithRequestSizeLimit(echoTimeSize),
+	)
+	return e
+}
+
+func echoHandler(context.Context, []byte) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(time.Now().UnixNano())), nil
+}
+
+// Run serves echo requests and checks connected peers every interval until the context is canceled.
+func (e *Echo) Run(ctx context.Context) error {
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return e.srv.Run(ctx)
+	})
+	if e.interval > 0 {
+		eg.Go(func() error {
+			ticker := time.NewTicker(e.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+					e.probeConnected(ctx)
+				}
+			}
+		})
+	}
+	return eg.Wait()
+}
+
+func (e *Echo) probeConnected(ctx context.Context) {
+	var eg errgroup.Group
+	eg.SetLimit(echoConcurrency)
+	for _, pid := range e.h.Network().Peers() {
+		if e.h.Network().Connectedness(pid) != network.Connected {
+			continue
+		}
+		// peers running older versions don't serve the echo protocol, checking them would only fail
+		if ok, err := e.h.Network().Peerstore().SupportsProtocols(pid, EchoProtocol); err != nil || len(ok) == 0 {
+			continue
+		}
+		eg.Go(func() error {
+			if _, err := e.Probe(ctx, pid); err != nil && ctx.Err() == nil {
+				e.logger.Debug("echo check failed", zap.Stringer("peer", pid), zap.Error(err))
+			}
+			return nil
+		})
+	}
+	eg.Wait()
+}
+
+// Probe checks the peer with a single echo request. The clock offset is estimated
+// assuming that the request and the response took the same time.
+func (e *Echo) Probe(ctx context.Context, pid peer.ID) (EchoResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	start := time.Now()
+	resp, err := e.srv.Request(ctx, pid, nil)
+	rtt := time.Since(start)
+	if err != nil {
+		echoChecks.WithLabelValues("failed").Inc()
+		return EchoResult{}, err
+	}
+	if len(resp) != echoTimeSize {
+		echoChecks.WithLabelValues("invalid").Inc()
+		return EchoResult{}, fmt.Errorf("invalid echo response size %d", len(resp))
+	}
+	remote := time.Unix(0, int64(binary.BigEndian.Uint64(resp)))
+	result := EchoResult{
+		RTT:         rtt,
+		ClockOffset: remote.Sub(start.Add(rtt / 2)),
+	}
+	echoChecks.WithLabelValues("succeeded").Inc()
+	echoRTT.Observe(result.RTT.Seconds())
+	echoClockOffset.Observe(result.ClockOffset.Abs().Seconds())
+	if e.h.PeerInfo() != nil {
+		e.h.PeerInfo().EnsurePeerInfo(pid).Echo.Record(result.RTT, result.ClockOffset, time.Now())
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestEcho(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(3)
+	require.NoError(t, err)
+	client := wrapHost(t, mesh.Hosts()[0])
+	echo := NewEcho(client,
+		WithEchoInterval(10*time.Millisecond),
+		WithEchoLog(zaptest.NewLogger(t)),
+	)
+	// the third host doesn't serve the echo protocol
+	srv := NewEcho(wrapHost(t, mesh.Hosts()[1]), WithEchoInterval(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error { return echo.Run(ctx) })
+	eg.Go(func() error { return srv.Run(ctx) })
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, eg.Wait())
+	})
+
+	t.Run("probe", func(t *testing.T) {
+		result, err := echo.Probe(ctx, mesh.Hosts()[1].ID())
+		require.NoError(t, err)
+		require.Positive(t, result.RTT)
+		// both hosts share the clock
+		require.Less(t, result.ClockOffset.Abs(), result.RTT+time.Millisecond)
+	})
+	t.Run("not supported", func(t *testing.T) {
+		_, err := echo.Probe(ctx, mesh.Hosts()[2].ID())
+		require.Error(t, err)
+	})
+	t.Run("periodic", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			info := client.PeerInfo().EnsurePeerInfo(mesh.Hosts()[1].ID())
+			return !info.Echo.Updated().IsZero() && info.Echo.RTT() > 0
+		}, time.Second, 10*time.Millisecond)
+		info := client.PeerInfo().EnsurePeerInfo(mesh.Hosts()[2].ID())
+		require.True(t, info.Echo.Updated().IsZero())
+		// the peer without the echo protocol is not checked
+		failed := testutil.ToFloat64(echoChecks.WithLabelValues("failed"))
+		time.Sleep(50 * time.Millisecond)
+		require.Equal(t, failed, testutil.ToFloat64(echoChecks.WithLabelValues("failed")))
+	})
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/multiformats/go-varint"
+)
+
+const (
+	// maxResponseSize is the limit of Response.Data, keep in line with its scale tag.
+	maxResponseSize = 272629760
+	// maxErrorSize is the limit of Response.Error, keep in line with its scale tag.
+	maxErrorSize = 1024
+)
+
+var (
+	errFrameTooLarge    = errors.New("frame is larger than limit")
+	errResponseTooLarge = errors.New("response is larger than limit")
+)
+
+// frameCodec encodes requests as a varint size followed by the body. All request paths
+// of the server (plain, priority and pipelined streams) use it, so that the same limits
+// apply to all of them.
+type frameCodec struct {
+	limit int
+}
+
+// check returns an error if a request of the size doesn't fit into a frame.
+func (f frameCodec) check(size uint64) error {
+	if size > uint64(f.limit) {
+		return fmt.Errorf("%w: request length %d, limit %d", errFrameTooLarge, size, f.limit)
+	}
+	return nil
+}
+
+// write writes the request frame to w.
+func (f frameCodec) write(w io.Writer, body []byte) error {
+	if err := f.check(uint64(len(body))); err != nil {
+		return err
+	}
+	if _, err := w.Write(varint.ToUvarint(uint64(len(body)))); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// read reads the request frame from rd. The size is checked against the limit
+// before the body is allocated. The body is taken from the buffer pool, the caller
+// returns it with buffers.put once it's done with the request.
+func (f frameCodec) read(rd *bufio.Reader) ([]byte, error) {
+	size, err := varint.ReadUvarint(rd)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.check(size); err != nil {
+		return nil, err
+	}
+	buf := buffers.get(int(size))
+	if _, err := io.ReadFull(rd, buf); err != nil {
+		buffers.put(buf)
+		return nil, err
+	}
+	return buf, nil
+}
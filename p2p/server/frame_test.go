@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+)
+
+func TestFrameCodec(t *testing.T) {
+	frames := frameCodec{limit: 10}
+	var buf bytes.Buffer
+	require.NoError(t, frames.write(&buf, []byte("request")))
+	got, err := frames.read(bufio.NewReader(&buf))
+	require.NoError(t, err)
+	require.Equal(t, []byte("request"), got)
+
+	require.ErrorIs(t, frames.write(&buf, make([]byte, 11)), errFrameTooLarge)
+
+	large := frameCodec{limit: 11}
+	buf.Reset()
+	require.NoError(t, large.write(&buf, make([]byte, 11)))
+	_, err = frames.read(bufio.NewReader(&buf))
+	require.ErrorIs(t, err, errFrameTooLarge)
+}
+
+func TestReadResponseLimit(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := codec.EncodeLen(&buf, maxResponseSize+1)
+	require.NoError(t, err)
+	_, err = ReadResponse(&buf, func(uint32) (int, error) {
+		require.FailNow(t, "response larger than limit must not be read")
+		return 0, nil
+	})
+	require.ErrorIs(t, err, errResponseTooLarge)
+}
+
+func FuzzFrameRead(f *testing.F) {
+	frames := frameCodec{limit: 1024}
+	var buf bytes.Buffer
+	require.NoError(f, frames.write(&buf, []byte("request")))
+	f.Add(buf.Bytes())
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		body, err := frames.read(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return
+		}
+		require.LessOrEqual(t, len(body), frames.limit)
+		var again bytes.Buffer
+		require.NoError(t, frames.write(&again, body))
+		require.True(t, bytes.HasPrefix(data, again.Bytes()))
+	})
+}
+
+func FuzzReadResponse(f *testing.F) {
+	var buf bytes.Buffer
+	require.NoError(f, writeResponse(&buf, &Response{Data: []byte("response")}))
+	f.Add(buf.Bytes())
+	buf.Reset()
+	require.NoError(f, writeResponse(&buf, &Response{Error: "error"}))
+	f.Add(buf.Bytes())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rd := bytes.NewReader(data)
+		_, _ = ReadResponse(rd, func(respLen uint32) (int, error) {
+			require.LessOrEqual(t, respLen, uint32(maxResponseSize))
+			n, err := rd.Read(make([]byte, min(int(respLen), rd.Len())))
+			return n, err
+		})
+	})
+}
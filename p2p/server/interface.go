@@ -19,6 +19,7 @@ import (
 type Host interface {
 	SetStreamHandler(protocol.ID, network.StreamHandler)
 	NewStream(context.Context, peer.ID, ...protocol.ID) (network.Stream, error)
+	Connect(context.Context, peer.AddrInfo) error
 	Network() network.Network
 	ConnManager() connmgr.ConnManager
 	PeerInfo() peerinfo.PeerInfo
@@ -62,6 +62,105 @@ var (
 		[]string{protoLabel},
 		prometheus.ExponentialBuckets(0.01, 2, 20),
 	)
+	requestSize = metrics.NewHistogramWithBuckets(
+		"request_size_bytes",
+		namespace,
+		"size of received requests",
+		[]string{protoLabel},
+		prometheus.ExponentialBuckets(16, 4, 10),
+	)
+	anomalousPeers = metrics.NewCounter(
+		"anomalous_peers",
+		namespace,
+		"peers flagged for anomalous request sizes",
+		[]string{protoLabel, "anomaly"},
+	)
+	prewarmPeers = metrics.NewCounter(
+		"prewarm_peers",
+		namespace,
+		"peers declared for prewarming",
+		[]string{protoLabel, "result"},
+	)
+	routedRequests = metrics.NewCounter(
+		"routed_requests",
+		namespace,
+		"requests dispatched by the router",
+		[]string{protoLabel, "kind"},
+	)
+	deprecatedRequests = metrics.NewCounter(
+		"deprecated_requests",
+		namespace,
+		"requests served over deprecated protocols",
+		[]string{protoLabel},
+	)
+	deprecatedPeers = metrics.NewGauge(
+		"deprecated_peers",
+		namespace,
+		"peers that used deprecated protocols within the last hour",
+		[]string{protoLabel},
+	)
+	deprecatedResponses = metrics.NewCounter(
+		"deprecated_responses",
+		namespace,
+		"responses of peers that report the protocol as deprecated",
+		[]string{protoLabel},
+	)
+	rcmgrStreams = metrics.NewGauge(
+		"rcmgr_streams",
+		namespace,
+		"streams of the protocol in the libp2p resource manager",
+		[]string{protoLabel, "direction"},
+	)
+	rcmgrStreamLimit = metrics.NewGauge(
+		"rcmgr_stream_limit",
+		namespace,
+		"stream limit of the protocol in the libp2p resource manager, zero if not limited",
+		[]string{protoLabel, "direction"},
+	)
+	rcmgrMemory = metrics.NewGauge(
+		"rcmgr_memory_bytes",
+		namespace,
+		"memory reserved by the protocol in the libp2p resource manager",
+		[]string{protoLabel},
+	)
+	rcmgrMemoryLimit = metrics.NewGauge(
+		"rcmgr_memory_limit_bytes",
+		namespace,
+		"memory limit of the protocol in the libp2p resource manager, zero if not limited",
+		[]string{protoLabel},
+	)
+	rcmgrSaturated = metrics.NewCounter(
+		"rcmgr_saturated",
+		namespace,
+		"reports of resources of the protocol at the limit of the libp2p resource manager",
+		[]string{protoLabel, "resource"},
+	)
+	rcmgrRejected = metrics.NewCounter(
+		"rcmgr_rejected",
+		namespace,
+		"requests that failed as the libp2p resource manager rejected the stream",
+		[]string{protoLabel},
+	)
+	echoChecks = metrics.NewCounter(
+		"echo_checks",
+		namespace,
+		"echo health checks of connected peers",
+		[]string{"result"},
+	)
+	echoRTT = metrics.NewHistogramWithBuckets(
+		"echo_rtt_seconds",
+		namespace,
+		"round-trip time measured by echo health checks",
+		[]string{},
+		prometheus.ExponentialBuckets(0.001, 2, 16),
+	).WithLabelValues()
+	echoClockOffset = metrics.NewHistogramWithBuckets(
+		"echo_clock_offset_seconds",
+		namespace,
+		"absolute clock offset of peers measured by echo health checks",
+		[]string{},
+		prometheus.ExponentialBuckets(0.001, 2, 16),
+	).WithLabelValues()
 )
 
 func newTracker(protocol string) *tracker {
@@ -73,13 +172,24 @@ func newTracker(protocol string) *tracker {
 		failed:               requests.WithLabelValues(protocol, "failed"),
 		accepted:             requests.WithLabelValues(protocol, "accepted"),
 		dropped:              requests.WithLabelValues(protocol, "dropped"),
+		priorityAccepted:     requests.WithLabelValues(protocol, "priority_accepted"),
+		priorityDenied:       requests.WithLabelValues(protocol, "priority_denied"),
+		pipelined:            requests.WithLabelValues(protocol, "pipelined"),
 		clientSucceeded:      clientRequests.WithLabelValues(protocol, "succeeded"),
 		clientFailed:         clientRequests.WithLabelValues(protocol, "failed"),
 		clientServerError:    clientRequests.WithLabelValues(protocol, "server_error"),
+		clientReused:         clientRequests.WithLabelValues(protocol, "reused"),
 		inQueueLatency:       inQueueLatency.WithLabelValues(protocol),
 		serverLatency:        serverLatency.WithLabelValues(protocol),
 		clientLatency:        clientLatency.WithLabelValues(protocol, "success"),
 		clientLatencyFailure: clientLatency.WithLabelValues(protocol, "failure"),
+		requestSize:          requestSize.WithLabelValues(protocol),
+		nearLimitPeers:       anomalousPeers.WithLabelValues(protocol, string(anomalyNearLimit)),
+		outlierPeers:         anomalousPeers.WithLabelValues(protocol, string(anomalyOutlier)),
+		prewarmDialed:        prewarmPeers.WithLabelValues(protocol, "dialed"),
+		prewarmFailed:        prewarmPeers.WithLabelValues(protocol, "failed"),
+		prewarmOverBudget:    prewarmPeers.WithLabelValues(protocol, "over_budget"),
+		rcmgrRejected:        rcmgrRejected.WithLabelValues(protocol),
 	}
 }
 
@@ -91,10 +201,32 @@ type tracker struct {
 	failed                              prometheus.Counter
 	accepted                            prometheus.Counter
 	dropped                             prometheus.Counter
+	priorityAccepted                    prometheus.Counter
+	priorityDenied                      prometheus.Counter
+	pipelined                           prometheus.Counter
 	clientSucceeded                     prometheus.Counter
 	clientFailed                        prometheus.Counter
 	clientServerError                   prometheus.Counter
+	clientReused                        prometheus.Counter
 	inQueueLatency                      prometheus.Observer
 	serverLatency                       prometheus.Observer
 	clientLatency, clientLatencyFailure prometheus.Observer
+	requestSize                         prometheus.Observer
+	nearLimitPeers                      prometheus.Counter
+	outlierPeers                        prometheus.Counter
+	prewarmDialed                       prometheus.Counter
+	prewarmFailed                       prometheus.Counter
+	prewarmOverBudget                   prometheus.Counter
+	rcmgrRejected                       prometheus.Counter
+}
+
+// observeResources exposes the usage of the protocol, which is the protocol of the server
+// or one of its variants, in the resource manager.
+func observeResources(proto string, usage resourceUsage) {
+	rcmgrStreams.WithLabelValues(proto, "inbound").Set(float64(usage.streamsIn))
+	rcmgrStreams.WithLabelValues(proto, "outbound").Set(float64(usage.streamsOut))
+	rcmgrStreamLimit.WithLabelValues(proto, "inbound").Set(float64(usage.streamsInLimit))
+	rcmgrStreamLimit.WithLabelValues(proto, "outbound").Set(float64(usage.streamsOutLimit))
+	rcmgrMemory.WithLabelValues(proto).Set(float64(usage.memory))
+	rcmgrMemoryLimit.WithLabelValues(proto).Set(float64(usage.memoryLimit))
 }
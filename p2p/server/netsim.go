@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// NetConditions are simulated conditions of the link to a peer.
+type NetConditions struct {
+	// Latency is added to every write.
+	Latency time.Duration
+	// Jitter is the upper bound of a random delay added to Latency.
+	Jitter time.Duration
+	// Bandwidth in bytes per second, zero is unlimited.
+	Bandwidth int
+	// DropRate is the probability that a write is lost. The stream is reset, as a real
+	// connection would eventually be after losing data.
+	DropRate float64
+}
+
+// delay returns how long a write of n bytes takes.
+func (c NetConditions) delay(n int) time.Duration {
+	d := c.Latency
+	if c.Jitter > 0 {
+		d += rand.N(c.Jitter)
+	}
+	if c.Bandwidth > 0 {
+		d += time.Duration(n) * time.Second / time.Duration(c.Bandwidth)
+	}
+	return d
+}
+
+func (c NetConditions) drop() bool {
+	return c.DropRate > 0 && rand.Float64() < c.DropRate
+}
+
+// SimHost wraps a Host and simulates network conditions on the streams it opens and accepts,
+// so that timeouts and retries can be tested in-process. Conditions apply to writes, wrap hosts
+// on both ends of a link to shape requests and responses.
+//
+// SimHost is meant for tests only.
+type SimHost struct {
+	Host
+
+	mu       sync.Mutex
+	defaults NetConditions
+	peers    map[peer.ID]NetConditions
+}
+
+var _ Host = &SimHost{}
+
+// NewSimHost wraps the host, defaults apply to peers without their own conditions.
+func NewSimHost(h Host, defaults NetConditions) *SimHost {
+	return &SimHost{
+		Host:     h,
+		defaults: defaults,
+		peers:    make(map[peer.ID]NetConditions),
+	}
+}
+
+// SetConditions sets conditions of the link to the peer, they apply to streams opened afterwards.
+func (h *SimHost) SetConditions(pid peer.ID, c NetConditions) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.peers[pid] = c
+}
+
+func (h *SimHost) conditions(pid peer.ID) NetConditions {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if c, ok := h.peers[pid]; ok {
+		return c
+	}
+	return h.defaults
+}
+
+func (h *SimHost) NewStream(ctx context.Context, pid peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	stream, err := h.Host.NewStream(ctx, pid, pids...)
+	if err != nil {
+		return nil, err
+	}
+	return &simStream{Stream: stream, cond: h.conditions(pid)}, nil
+}
+
+func (h *SimHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		handler(&simStream{Stream: stream, cond: h.conditions(stream.Conn().RemotePeer())})
+	})
+}
+
+// simStream delays writes according to the link conditions. The write deadline
+// is respected, a write that doesn't complete before it fails as it would on a slow link.
+type simStream struct {
+	network.Stream
+	cond NetConditions
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func (s *simStream) SetDeadline(t time.Time) error {
+	s.setWriteDeadline(t)
+	return s.Stream.SetDeadline(t)
+}
+
+func (s *simStream) SetWriteDeadline(t time.Time) error {
+	s.setWriteDeadline(t)
+	return s.Stream.SetWriteDeadline(t)
+}
+
+func (s *simStream) setWriteDeadline(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadline = t
+}
+
+func (s *simStream) Write(p []byte) (int, error) {
+	if s.cond.drop() {
+		s.Stream.Reset()
+		return 0, network.ErrReset
+	}
+	delay := s.cond.delay(len(p))
+	s.mu.Lock()
+	deadline := s.deadline
+	s.mu.Unlock()
+	if !deadline.IsZero() && time.Until(deadline) < delay {
+		time.Sleep(max(time.Until(deadline), 0))
+		return 0, os.ErrDeadlineExceeded
+	}
+	time.Sleep(delay)
+	return s.Stream.Write(p)
+}
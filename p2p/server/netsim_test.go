@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestNetConditions_Delay(t *testing.T) {
+	require.Zero(t, NetConditions{}.delay(1000))
+	require.Equal(t, 10*time.Millisecond, NetConditions{Latency: 10 * time.Millisecond}.delay(1000))
+	require.Equal(t, 2*time.Second, NetConditions{Bandwidth: 500}.delay(1000))
+	for range 100 {
+		d := NetConditions{Latency: time.Millisecond, Jitter: time.Millisecond}.delay(0)
+		require.GreaterOrEqual(t, d, time.Millisecond)
+		require.Less(t, d, 2*time.Millisecond)
+	}
+	require.False(t, NetConditions{}.drop())
+	require.True(t, NetConditions{DropRate: 1}.drop())
+}
+
+func TestSimHost(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	proto := "test"
+	handler := func(_ context.Context, msg []byte) ([]byte, error) {
+		return msg, nil
+	}
+	const timeout = 200 * time.Millisecond
+	sim := NewSimHost(wrapHost(t, mesh.Hosts()[0]), NetConditions{})
+	client := New(sim, proto, WrapHandler(handler), WithTimeout(timeout), WithLog(zaptest.NewLogger(t)))
+	srv := New(
+		wrapHost(t, mesh.Hosts()[1]),
+		proto,
+		WrapHandler(handler),
+		WithTimeout(timeout),
+		WithLog(zaptest.NewLogger(t)),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return srv.Run(ctx)
+	})
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+	require.Eventually(t, func() bool {
+		return len(mesh.Hosts()[1].Mux().Protocols()) > 0
+	}, time.Second, 10*time.Millisecond)
+	srvID := mesh.Hosts()[1].ID()
+
+	t.Run("latency", func(t *testing.T) {
+		sim.SetConditions(srvID, NetConditions{Latency: 20 * time.Millisecond})
+		start := time.Now()
+		resp, err := client.Request(ctx, srvID, []byte("request"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("request"), resp)
+		require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		sim.SetConditions(srvID, NetConditions{Latency: 2 * timeout})
+		_, err := client.Request(ctx, srvID, []byte("request"))
+		require.Error(t, err)
+	})
+	t.Run("drop", func(t *testing.T) {
+		sim.SetConditions(srvID, NetConditions{DropRate: 1})
+		_, err := client.Request(ctx, srvID, []byte("request"))
+		require.Error(t, err)
+	})
+	t.Run("recovered", func(t *testing.T) {
+		sim.SetConditions(srvID, NetConditions{})
+		_, err := client.Request(ctx, srvID, []byte("request"))
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"math/bits"
+	"sync"
+)
+
+const (
+	// minPooledSize is the capacity of the smallest size class of pooled buffers.
+	minPooledSize = 256
+	// maxPooledSize is the capacity of the largest size class, larger buffers are not pooled.
+	maxPooledSize = 1 << 20
+	// sizeClassShift is log2 of the ratio between capacities of consecutive size classes.
+	sizeClassShift = 2
+)
+
+// bufferPool is a pool of byte buffers split into size classes, so that a buffer for a small
+// request doesn't pin the memory of a large one. Capacities of the classes grow by a factor of 4
+// from minPooledSize to maxPooledSize.
+type bufferPool struct {
+	classes []sync.Pool
+}
+
+func newBufferPool() *bufferPool {
+	n := (bits.Len(maxPooledSize)-bits.Len(minPooledSize))/sizeClassShift + 1
+	p := &bufferPool{classes: make([]sync.Pool, n)}
+	for i := range p.classes {
+		size := minPooledSize << (i * sizeClassShift)
+		p.classes[i].New = func() any {
+			buf := make([]byte, size)
+			return &buf
+		}
+	}
+	return p
+}
+
+// class returns the index of the smallest size class that fits size, or -1 if the size
+// is larger than maxPooledSize.
+func (p *bufferPool) class(size int) int {
+	if size > maxPooledSize {
+		return -1
+	}
+	if size <= minPooledSize {
+		return 0
+	}
+	shift := bits.Len(uint(size-1)) - bits.Len(minPooledSize) + 1
+	return (shift + sizeClassShift - 1) / sizeClassShift
+}
+
+// get returns a buffer of length size. The contents of the buffer are undefined.
+func (p *bufferPool) get(size int) []byte {
+	class := p.class(size)
+	if class < 0 {
+		return make([]byte, size)
+	}
+	buf := p.classes[class].Get().(*[]byte)
+	return (*buf)[:size]
+}
+
+// put returns the buffer obtained with get to the pool. The buffer must not be used
+// by the caller after it was returned. Buffers which capacity doesn't match a size class,
+// e.g. the ones that were allocated for sizes larger than maxPooledSize, are dropped.
+func (p *bufferPool) put(buf []byte) {
+	class := p.class(cap(buf))
+	if class < 0 || cap(buf) != minPooledSize<<(class*sizeClassShift) {
+		return
+	}
+	buf = buf[:cap(buf)]
+	p.classes[class].Put(&buf)
+}
+
+// buffers is the pool of request buffers shared by all servers.
+var buffers = newBufferPool()
+
+var (
+	readers = sync.Pool{New: func() any { return bufio.NewReader(nil) }}
+	writers = sync.Pool{New: func() any { return bufio.NewWriter(nil) }}
+)
+
+func getReader(r io.Reader) *bufio.Reader {
+	rd := readers.Get().(*bufio.Reader)
+	rd.Reset(r)
+	return rd
+}
+
+// putReader returns the reader to the pool. Data buffered by the reader is discarded.
+func putReader(rd *bufio.Reader) {
+	rd.Reset(nil)
+	readers.Put(rd)
+}
+
+func getWriter(w io.Writer) *bufio.Writer {
+	wr := writers.Get().(*bufio.Writer)
+	wr.Reset(w)
+	return wr
+}
+
+// putWriter returns the writer to the pool. Data that wasn't flushed is discarded.
+func putWriter(wr *bufio.Writer) {
+	wr.Reset(nil)
+	writers.Put(wr)
+}
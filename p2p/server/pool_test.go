@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestBufferPool(t *testing.T) {
+	p := newBufferPool()
+	for _, tc := range []struct {
+		size     int
+		capacity int
+	}{
+		{0, minPooledSize},
+		{1, minPooledSize},
+		{minPooledSize, minPooledSize},
+		{minPooledSize + 1, minPooledSize << sizeClassShift},
+		{1024, 1024},
+		{1025, 4096},
+		{10240, 16384},
+		{maxPooledSize, maxPooledSize},
+		{maxPooledSize + 1, maxPooledSize + 1},
+	} {
+		buf := p.get(tc.size)
+		require.Len(t, buf, tc.size)
+		require.Equal(t, tc.capacity, cap(buf), "size %d", tc.size)
+		p.put(buf)
+	}
+
+	t.Run("foreign buffers are dropped", func(t *testing.T) {
+		p := newBufferPool()
+		p.put(make([]byte, 1000))
+		p.put(make([]byte, maxPooledSize+1))
+		for range 10 {
+			require.Equal(t, minPooledSize<<sizeClassShift, cap(p.get(1000)))
+		}
+	})
+	t.Run("resliced buffer is returned with full capacity", func(t *testing.T) {
+		p := newBufferPool()
+		p.put(p.get(100)[:10])
+		require.Len(t, p.get(200), 200)
+	})
+}
+
+func TestFrameReadReturnsBuffer(t *testing.T) {
+	frames := frameCodec{limit: 1024}
+	var buf bytes.Buffer
+	require.NoError(t, frames.write(&buf, make([]byte, 100)))
+	// truncated body
+	_, err := frames.read(bufio.NewReader(bytes.NewReader(buf.Bytes()[:50])))
+	require.Error(t, err)
+
+	body, err := frames.read(bufio.NewReader(&buf))
+	require.NoError(t, err)
+	require.Len(t, body, 100)
+	require.Equal(t, minPooledSize, cap(body))
+	buffers.put(body)
+}
+
+func BenchmarkFrameRead(b *testing.B) {
+	frames := frameCodec{limit: 10240}
+	var encoded bytes.Buffer
+	require.NoError(b, frames.write(&encoded, make([]byte, 4000)))
+	data := encoded.Bytes()
+	src := bytes.NewReader(data)
+	rd := bufio.NewReader(src)
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			src.Reset(data)
+			rd.Reset(src)
+			body, err := frames.read(rd)
+			if err != nil {
+				b.Fatal(err)
+			}
+			buffers.put(body)
+		}
+	})
+	// buffers that are not returned have to be allocated for every frame
+	b.Run("dropped", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			src.Reset(data)
+			rd.Reset(src)
+			body, err := frames.read(rd)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = body
+		}
+	})
+}
+
+func BenchmarkRequest(b *testing.B) {
+	for _, tc := range []struct {
+		desc string
+		opts []Opt
+	}{
+		{desc: "plain"},
+		{desc: "pipelined", opts: []Opt{WithStreamReuse(time.Minute)}},
+	} {
+		b.Run(tc.desc, func(b *testing.B) {
+			mesh, err := mocknet.FullMeshConnected(2)
+			require.NoError(b, err)
+			opts := append([]Opt{WithLog(zap.NewNop()), WithRequestSizeLimit(10240)}, tc.opts...)
+			handler := WrapHandler(func(_ context.Context, msg []byte) ([]byte, error) {
+				return msg[:10], nil
+			})
+			client := New(wrapHost(b, mesh.Hosts()[0]), "bench", handler, opts...)
+			srv := New(wrapHost(b, mesh.Hosts()[1]), "bench", handler, opts...)
+			ctx, cancel := context.WithCancel(context.Background())
+			var eg errgroup.Group
+			eg.Go(func() error { return srv.Run(ctx) })
+			eg.Go(func() error { return client.Run(ctx) })
+			b.Cleanup(func() {
+				cancel()
+				eg.Wait()
+			})
+			require.Eventually(b, func() bool {
+				for _, h := range mesh.Hosts() {
+					if len(h.Mux().Protocols()) == 0 {
+						return false
+					}
+				}
+				return true
+			}, time.Second, 10*time.Millisecond)
+
+			req := make([]byte, 4000)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				if _, err := client.Request(ctx, mesh.Hosts()[1].ID(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
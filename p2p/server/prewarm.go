@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// prewarmConcurrency is the maximal number of concurrent dials started by Prewarm.
+const prewarmConcurrency = 16
+
+type prewarmed struct {
+	expires time.Time
+	timer   *time.Timer
+}
+
+// prewarmer keeps connections to the peers that were declared by the protocol as
+// the ones it is going to request soon. Such peers are protected from being trimmed
+// by the connection manager until their TTL expires.
+type prewarmer struct {
+	tag    string
+	budget int
+	maxTTL time.Duration
+	h      Host
+
+	mu    sync.Mutex
+	peers map[peer.ID]*prewarmed
+}
+
+func newPrewarmer(h Host, proto string, budget int, maxTTL time.Duration) *prewarmer {
+	return &prewarmer{
+		tag:    "prewarm:" + proto,
+		budget: budget,
+		maxTTL: maxTTL,
+		h:      h,
+		peers:  make(map[peer.ID]*prewarmed),
+	}
+}
+
+// reserve protects peers for ttl within the budget and returns the reserved peers.
+// Peers that are already reserved have their TTL extended and don't consume the budget.
+func (p *prewarmer) reserve(peers []peer.ID, ttl time.Duration) []peer.ID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	expires := time.Now().Add(ttl)
+	reserved := make([]peer.ID, 0, len(peers))
+	for _, pid := range peers {
+		if pw, exists := p.peers[pid]; exists {
+			if expires.After(pw.expires) {
+				pw.expires = expires
+				pw.timer.Reset(ttl)
+			}
+			reserved = append(reserved, pid)
+			continue
+		}
+		if len(p.peers) >= p.budget {
+			continue
+		}
+		pw := &prewarmed{expires: expires}
+		pw.timer = time.AfterFunc(ttl, func() { p.expire(pid, pw) })
+		p.peers[pid] = pw
+		p.h.ConnManager().Protect(pid, p.tag)
+		reserved = append(reserved, pid)
+	}
+	return reserved
+}
+
+func (p *prewarmer) expire(pid peer.ID, pw *prewarmed) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers[pid] != pw || time.Now().Before(pw.expires) {
+		// peer was released or its ttl was extended
+		return
+	}
+	p.releaseLocked(pid)
+}
+
+func (p *prewarmer) release(pid peer.ID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.releaseLocked(pid)
+}
+
+func (p *prewarmer) releaseLocked(pid peer.ID) {
+	pw, exists := p.peers[pid]
+	if !exists {
+		return
+	}
+	pw.timer.Stop()
+	delete(p.peers, pid)
+	p.h.ConnManager().Unprotect(pid, p.tag)
+}
+
+func (p *prewarmer) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for pid := range p.peers {
+		p.releaseLocked(pid)
+	}
+}
+
+func (p *prewarmer) isPrewarmed(pid peer.ID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, exists := p.peers[pid]
+	return exists
+}
+
+// Prewarm declares peers that are going to be requested within ttl, e.g. at the start of
+// the epoch or during the poet proof fetch window. The server dials the peers that are not
+// connected yet, so that requests don't pay for the connection setup, and protects the
+// connections from being trimmed by the connection manager until ttl expires.
+//
+// ttl is capped by the value configured with WithPrewarmMaxTTL, and the number of peers
+// prewarmed at the same time is limited by WithPrewarmBudget. Peers over the budget are skipped.
+// Returns the number of declared peers that are prewarmed and connected.
+func (s *Server) Prewarm(ctx context.Context, peers []peer.ID, ttl time.Duration) int {
+	ttl = min(ttl, s.prewarm.maxTTL)
+	if ttl <= 0 {
+		return 0
+	}
+	reserved := s.prewarm.reserve(peers, ttl)
+	if s.metrics != nil {
+		s.metrics.prewarmOverBudget.Add(float64(len(peers) - len(reserved)))
+	}
+	if len(reserved) < len(peers) {
+		s.logger.Debug("prewarm budget exhausted",
+			zap.String("protocol", s.protocol),
+			zap.Int("declared", len(peers)),
+			zap.Int("reserved", len(reserved)),
+			zap.Int("budget", s.prewarm.budget),
+		)
+	}
+
+	var (
+		eg        errgroup.Group
+		mu        sync.Mutex
+		connected int
+	)
+	eg.SetLimit(prewarmConcurrency)
+	for _, pid := range reserved {
+		if s.h.Network().Connectedness(pid) == network.Connected {
+			mu.Lock()
+			connected++
+			mu.Unlock()
+			continue
+		}
+		eg.Go(func() error {
+			if err := s.h.Connect(ctx, peer.AddrInfo{ID: pid}); err != nil {
+				s.logger.Debug("failed to prewarm connection",
+					zap.String("protocol", s.protocol),
+					zap.Stringer("peer", pid),
+					zap.Error(err),
+				)
+				s.prewarm.release(pid)
+				if s.metrics != nil {
+					s.metrics.prewarmFailed.Inc()
+				}
+				return nil
+			}
+			if s.metrics != nil {
+				s.metrics.prewarmDialed.Inc()
+			}
+			mu.Lock()
+			connected++
+			mu.Unlock()
+			return nil
+		})
+	}
+	eg.Wait()
+	return connected
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/connmgr"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	p2pconnmgr "github.com/libp2p/go-libp2p/p2p/net/connmgr"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type connMgrHost struct {
+	hostWrapper
+	cm connmgr.ConnManager
+}
+
+func (h *connMgrHost) ConnManager() connmgr.ConnManager {
+	return h.cm
+}
+
+func newPrewarmServer(t *testing.T, h host.Host, opts ...Opt) *Server {
+	cm, err := p2pconnmgr.NewConnManager(1, 10)
+	require.NoError(t, err)
+	t.Cleanup(func() { cm.Close() })
+	wrapped := &connMgrHost{hostWrapper: hostWrapper{Host: h}, cm: cm}
+	opts = append([]Opt{WithLog(zaptest.NewLogger(t)), WithMetrics()}, opts...)
+	return New(wrapped, "test", WrapHandler(func(context.Context, []byte) ([]byte, error) {
+		return nil, nil
+	}), opts...)
+}
+
+func TestPrewarm(t *testing.T) {
+	t.Run("connects and protects", func(t *testing.T) {
+		mesh, err := mocknet.FullMeshLinked(3)
+		require.NoError(t, err)
+		srv := newPrewarmServer(t, mesh.Hosts()[0])
+		peers := []peer.ID{mesh.Hosts()[1].ID(), mesh.Hosts()[2].ID()}
+		for _, pid := range peers {
+			require.NotEqual(t, network.Connected, mesh.Hosts()[0].Network().Connectedness(pid))
+		}
+
+		require.Equal(t, 2, srv.Prewarm(context.Background(), peers, time.Minute))
+		for _, pid := range peers {
+			require.Equal(t, network.Connected, mesh.Hosts()[0].Network().Connectedness(pid))
+			require.True(t, srv.h.ConnManager().IsProtected(pid, srv.prewarm.tag))
+			require.True(t, srv.prewarm.isPrewarmed(pid))
+		}
+		srv.prewarm.stop()
+		for _, pid := range peers {
+			require.False(t, srv.h.ConnManager().IsProtected(pid, srv.prewarm.tag))
+		}
+	})
+	t.Run("ttl expires", func(t *testing.T) {
+		mesh, err := mocknet.FullMeshLinked(2)
+		require.NoError(t, err)
+		srv := newPrewarmServer(t, mesh.Hosts()[0], WithPrewarmMaxTTL(50*time.Millisecond))
+		pid := mesh.Hosts()[1].ID()
+
+		// ttl is capped by max ttl
+		require.Equal(t, 1, srv.Prewarm(context.Background(), []peer.ID{pid}, time.Hour))
+		require.True(t, srv.h.ConnManager().IsProtected(pid, srv.prewarm.tag))
+		require.Eventually(t, func() bool {
+			return !srv.h.ConnManager().IsProtected(pid, srv.prewarm.tag)
+		}, time.Second, 10*time.Millisecond)
+		require.False(t, srv.prewarm.isPrewarmed(pid))
+	})
+	t.Run("ttl extended", func(t *testing.T) {
+		mesh, err := mocknet.FullMeshLinked(2)
+		require.NoError(t, err)
+		srv := newPrewarmServer(t, mesh.Hosts()[0])
+		pid := mesh.Hosts()[1].ID()
+
+		require.Equal(t, 1, srv.Prewarm(context.Background(), []peer.ID{pid}, 20*time.Millisecond))
+		require.Equal(t, 1, srv.Prewarm(context.Background(), []peer.ID{pid}, time.Minute))
+		time.Sleep(50 * time.Millisecond)
+		require.True(t, srv.prewarm.isPrewarmed(pid))
+		require.True(t, srv.h.ConnManager().IsProtected(pid, srv.prewarm.tag))
+	})
+	t.Run("budget", func(t *testing.T) {
+		mesh, err := mocknet.FullMeshLinked(4)
+		require.NoError(t, err)
+		srv := newPrewarmServer(t, mesh.Hosts()[0], WithPrewarmBudget(2))
+		peers := []peer.ID{mesh.Hosts()[1].ID(), mesh.Hosts()[2].ID(), mesh.Hosts()[3].ID()}
+
+		require.Equal(t, 2, srv.Prewarm(context.Background(), peers, time.Minute))
+		require.True(t, srv.prewarm.isPrewarmed(peers[0]))
+		require.True(t, srv.prewarm.isPrewarmed(peers[1]))
+		require.False(t, srv.prewarm.isPrewarmed(peers[2]))
+		require.NotEqual(t, network.Connected, mesh.Hosts()[0].Network().Connectedness(peers[2]))
+
+		// prewarmed peers don't consume budget again
+		require.Equal(t, 1, srv.Prewarm(context.Background(), peers[1:2], time.Minute))
+	})
+	t.Run("dial failure releases peer", func(t *testing.T) {
+		mesh, err := mocknet.FullMeshLinked(2)
+		require.NoError(t, err)
+		srv := newPrewarmServer(t, mesh.Hosts()[0])
+		pid := mesh.Hosts()[1].ID()
+		require.NoError(t, mesh.UnlinkPeers(mesh.Hosts()[0].ID(), pid))
+
+		require.Zero(t, srv.Prewarm(context.Background(), []peer.ID{pid}, time.Minute))
+		require.False(t, srv.prewarm.isPrewarmed(pid))
+		require.False(t, srv.h.ConnManager().IsProtected(pid, srv.prewarm.tag))
+	})
+}
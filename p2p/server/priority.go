@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// PriorityProtocol returns the protocol that is used to send requests with the priority hint.
+// Servers with a priority lane register a handler for it in addition to the protocol itself.
+func PriorityProtocol(proto string) string {
+	return proto + "/priority"
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context that marks requests as critical, e.g. certifier queries
+// or fetching proposals for the current layer. Such requests are sent with the priority hint,
+// which is honored by cooperating peers that have this node in their list of priority peers.
+// Peers that don't support the hint serve the request as usual.
+//
+// The hint is not sent for requests with extra protocols.
+func WithPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, priorityKey{}, struct{}{})
+}
+
+// IsPriority returns true if the context is marked with WithPriority.
+func IsPriority(ctx context.Context) bool {
+	return ctx.Value(priorityKey{}) != nil
+}
+
+// lane is a queue of requests with its own size and rate limit.
+type lane struct {
+	size  int
+	queue chan request
+	sem   *semaphore.Weighted
+	limit *rate.Limiter
+}
+
+func newLane(size, requestsPerInterval int, interval time.Duration) *lane {
+	return &lane{
+		size:  size,
+		queue: make(chan request),
+		sem:   semaphore.NewWeighted(int64(size)),
+		limit: rate.NewLimiter(
+			rate.Every(interval/time.Duration(requestsPerInterval)),
+			requestsPerInterval,
+		),
+	}
+}
+
+// SetPriorityPeers replaces the set of peers whose priority hint is honored by the server.
+func (s *Server) SetPriorityPeers(peers []peer.ID) {
+	allowed := make(map[peer.ID]struct{}, len(peers))
+	for _, pid := range peers {
+		allowed[pid] = struct{}{}
+	}
+	s.priorityMu.Lock()
+	defer s.priorityMu.Unlock()
+	s.priorityPeers = allowed
+}
+
+func (s *Server) isPriorityPeer(pid peer.ID) bool {
+	s.priorityMu.Lock()
+	defer s.priorityMu.Unlock()
+	_, exists := s.priorityPeers[pid]
+	return exists
+}
+
+// handlePriorityStream admits requests with the priority hint from priority peers into the
+// priority lane. Requests from other peers, or over the capacity of the lane, are queued as usual.
+func (s *Server) handlePriorityStream(stream network.Stream) {
+	if s.isPriorityPeer(stream.Conn().RemotePeer()) && s.enqueue(s.priority, request{stream: stream}) {
+		if s.metrics != nil {
+			s.metrics.priorityAccepted.Inc()
+		}
+		return
+	}
+	if s.metrics != nil {
+		s.metrics.priorityDenied.Inc()
+	}
+	s.handleStream(stream)
+}
+
+func (s *Server) handleStream(stream network.Stream) {
+	if !s.enqueue(s.lane, request{stream: stream}) {
+		if s.metrics != nil {
+			s.metrics.dropped.Inc()
+		}
+		stream.Close()
+	}
+}
+
+// full returns true if the lane has no capacity for another request.
+func (l *lane) full() bool {
+	if !l.sem.TryAcquire(1) {
+		return true
+	}
+	l.sem.Release(1)
+	return false
+}
+
+// enqueue returns false if the lane is full.
+func (s *Server) enqueue(l *lane, req request) bool {
+	if !l.sem.TryAcquire(1) {
+		return false
+	}
+	req.received = time.Now()
+	select {
+	case <-s.stopped:
+		l.sem.Release(1)
+		req.stream.Close()
+	case l.queue <- req:
+		// at most l.size requests block here, the others are rejected with the semaphore
+	}
+	return true
+}
+
+// serve executes requests from the lane until ctx is canceled. Requests on pipelined streams
+// are queued per frame, so that every frame is accounted in latency metrics and peer stats.
+func (s *Server) serve(ctx context.Context, eg *errgroup.Group, l *lane) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-l.queue:
+			if s.metrics != nil {
+				s.metrics.queue.Set(float64(s.queueSize))
+				s.metrics.accepted.Inc()
+			}
+			if s.metrics != nil {
+				s.metrics.inQueueLatency.Observe(time.Since(req.received).Seconds())
+			}
+			if err := l.limit.Wait(ctx); err != nil {
+				l.sem.Release(1)
+				req.stream.Close()
+				return
+			}
+			reqCtx, cancel := context.WithCancel(ctx)
+			eg.Go(func() error {
+				<-reqCtx.Done()
+				l.sem.Release(1)
+				// a pipelined stream is closed by its handler after the last frame, or when the server stops
+				if req.frame == nil || ctx.Err() != nil {
+					req.stream.Close()
+				}
+				return nil
+			})
+			eg.Go(func() error {
+				defer cancel()
+				conn := req.stream.Conn()
+				if s.decayingTag != nil {
+					s.decayingTag.Bump(conn.RemotePeer(), s.decayingTagSpec.Inc)
+				}
+				var ok bool
+				if req.frame != nil {
+					ok = s.serveFrame(reqCtx, req.stream, req.frame)
+					req.frame.done <- ok
+				} else {
+					ok = s.queueHandler(reqCtx, req.stream)
+				}
+				duration := time.Since(req.received)
+				if s.h.PeerInfo() != nil {
+					info := s.h.PeerInfo().EnsurePeerInfo(conn.RemotePeer())
+					info.ServerStats.RequestDone(duration, ok)
+				}
+				if s.metrics != nil {
+					s.metrics.serverLatency.Observe(duration.Seconds())
+					if ok {
+						s.metrics.completed.Inc()
+					} else {
+						s.metrics.failed.Inc()
+					}
+				}
+				return nil
+			})
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestPriorityLane(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(4)
+	require.NoError(t, err)
+	proto := "test"
+	request := []byte("test request")
+	blocking := []byte("blocking request")
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := WrapHandler(func(ctx context.Context, msg []byte) ([]byte, error) {
+		if bytes.Equal(msg, blocking) {
+			close(started)
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+		}
+		return msg, nil
+	})
+	opts := []Opt{WithLog(zaptest.NewLogger(t)), WithMetrics()}
+
+	allowed := New(wrapHost(t, mesh.Hosts()[0]), proto, handler, opts...)
+	other := New(wrapHost(t, mesh.Hosts()[1]), proto, handler, opts...)
+	srv := New(wrapHost(t, mesh.Hosts()[2]), proto, handler, append(opts,
+		// regular lane serves a single request at a time
+		WithQueueSize(1),
+		WithPriorityLane(1, 10),
+		WithPriorityPeers(mesh.Hosts()[0].ID()),
+	)...)
+	noPriority := New(wrapHost(t, mesh.Hosts()[3]), proto, handler, opts...)
+	require.Contains(t, mesh.Hosts()[2].Mux().Protocols(), protocol.ID(PriorityProtocol(proto)))
+	require.NotContains(t, mesh.Hosts()[3].Mux().Protocols(), protocol.ID(PriorityProtocol(proto)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error { return srv.Run(ctx) })
+	eg.Go(func() error { return noPriority.Run(ctx) })
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+	srvID := mesh.Hosts()[2].ID()
+
+	// occupy the regular lane
+	var blocked errgroup.Group
+	blocked.Go(func() error {
+		_, err := other.Request(ctx, srvID, blocking)
+		return err
+	})
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for blocking request")
+	}
+
+	t.Run("regular request is dropped", func(t *testing.T) {
+		_, err := allowed.Request(ctx, srvID, request)
+		require.Error(t, err)
+	})
+	t.Run("priority hint from priority peer", func(t *testing.T) {
+		response, err := allowed.Request(WithPriority(ctx), srvID, request)
+		require.NoError(t, err)
+		require.Equal(t, request, response)
+	})
+	t.Run("priority hint from other peer", func(t *testing.T) {
+		_, err := other.Request(WithPriority(ctx), srvID, request)
+		require.Error(t, err)
+	})
+	t.Run("peer without priority lane", func(t *testing.T) {
+		response, err := allowed.Request(WithPriority(ctx), mesh.Hosts()[3].ID(), request)
+		require.NoError(t, err)
+		require.Equal(t, request, response)
+	})
+	t.Run("priority peers updated", func(t *testing.T) {
+		srv.SetPriorityPeers(nil)
+		require.False(t, srv.isPriorityPeer(mesh.Hosts()[0].ID()))
+		_, err := allowed.Request(WithPriority(ctx), srvID, request)
+		require.Error(t, err)
+	})
+
+	close(release)
+	require.NoError(t, blocked.Wait())
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"go.uber.org/zap"
+)
+
+// WithResourceReportInterval sets how often the usage of the protocols of the server is read from
+// the libp2p resource manager. Zero disables the reports.
+func WithResourceReportInterval(interval time.Duration) Opt {
+	return func(s *Server) {
+		s.resourceInterval = interval
+	}
+}
+
+// resourceUsage is the usage of a protocol as seen by the libp2p resource manager.
+// Limits are zero if the resource manager doesn't expose them or doesn't limit the protocol.
+type resourceUsage struct {
+	streamsIn, streamsOut           int
+	streamsInLimit, streamsOutLimit int
+	memory, memoryLimit             int64
+}
+
+// saturated returns the resources that reached their limit.
+func (u resourceUsage) saturated() []string {
+	var rst []string
+	if u.streamsInLimit > 0 && u.streamsIn >= u.streamsInLimit {
+		rst = append(rst, "streams_inbound")
+	}
+	if u.streamsOutLimit > 0 && u.streamsOut >= u.streamsOutLimit {
+		rst = append(rst, "streams_outbound")
+	}
+	if u.memoryLimit > 0 && u.memory >= u.memoryLimit {
+		rst = append(rst, "memory")
+	}
+	return rst
+}
+
+// noLimit drops the limits of resources that the resource manager doesn't limit.
+func noLimit[T int | int64](limit T) T {
+	if int64(limit) >= math.MaxInt32 {
+		return 0
+	}
+	return limit
+}
+
+// lanes returns the protocols served by the server with the lanes their requests are queued in.
+func (s *Server) lanes() map[string]*lane {
+	rst := map[string]*lane{s.protocol: s.lane}
+	if s.priority != nil {
+		rst[PriorityProtocol(s.protocol)] = s.priority
+	}
+	if s.streams != nil {
+		rst[PipelinedProtocol(s.protocol)] = s.lane
+	}
+	return rst
+}
+
+func (s *Server) resourceUsage(proto string) (resourceUsage, error) {
+	var usage resourceUsage
+	err := s.h.Network().ResourceManager().ViewProtocol(protocol.ID(proto), func(scope network.ProtocolScope) error {
+		stat := scope.Stat()
+		usage.streamsIn = stat.NumStreamsInbound
+		usage.streamsOut = stat.NumStreamsOutbound
+		usage.memory = stat.Memory
+		if limiter, ok := scope.(rcmgr.ResourceScopeLimiter); ok {
+			limit := limiter.Limit()
+			usage.streamsInLimit = noLimit(limit.GetStreamLimit(network.DirInbound))
+			usage.streamsOutLimit = noLimit(limit.GetStreamLimit(network.DirOutbound))
+			usage.memoryLimit = noLimit(limit.GetMemoryLimit())
+		}
+		return nil
+	})
+	return usage, err
+}
+
+// reportResources periodically exposes the usage of the protocols in the resource manager.
+// Streams rejected by the resource manager never reach the server, so that the saturation of
+// the resource manager is reported as a warning unless the server queue is full as well.
+func (s *Server) reportResources(ctx context.Context) {
+	ticker := time.NewTicker(s.resourceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkResources()
+		}
+	}
+}
+
+func (s *Server) checkResources() {
+	if rejected := s.rcmgrRejected.Swap(0); rejected > 0 {
+		s.logger.Warn("resource manager rejected outbound streams of the protocol",
+			zap.String("protocol", s.protocol),
+			zap.Int64("rejected", rejected),
+		)
+	}
+	for proto, l := range s.lanes() {
+		usage, err := s.resourceUsage(proto)
+		if err != nil {
+			s.logger.Debug("failed to view protocol resources", zap.String("protocol", proto), zap.Error(err))
+			continue
+		}
+		if s.metrics != nil {
+			observeResources(proto, usage)
+		}
+		saturated := usage.saturated()
+		if len(saturated) == 0 {
+			continue
+		}
+		if s.metrics != nil {
+			for _, resource := range saturated {
+				rcmgrSaturated.WithLabelValues(proto, resource).Inc()
+			}
+		}
+		if l.full() {
+			// the server limits are reached as well and reported by the dropped requests
+			continue
+		}
+		s.logger.Warn("resource manager limits the protocol before the server queue is full",
+			zap.String("protocol", proto),
+			zap.Strings("saturated", saturated),
+			zap.Int("streams_inbound", usage.streamsIn),
+			zap.Int("streams_inbound_limit", usage.streamsInLimit),
+			zap.Int("streams_outbound", usage.streamsOut),
+			zap.Int("streams_outbound_limit", usage.streamsOutLimit),
+			zap.Int64("memory", usage.memory),
+			zap.Int64("memory_limit", usage.memoryLimit),
+		)
+	}
+}
+
+// observeOpenError counts requests that failed because the resource manager rejected the stream.
+func (s *Server) observeOpenError(err error) {
+	if !errors.Is(err, network.ErrResourceLimitExceeded) {
+		return
+	}
+	s.rcmgrRejected.Add(1)
+	if s.metrics != nil {
+		s.metrics.rcmgrRejected.Inc()
+	}
+	s.logger.Debug("resource manager rejected stream", zap.String("protocol", s.protocol), zap.Error(err))
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// rcmgrHost replaces the resource manager of the network, mocknet doesn't support one.
+type rcmgrHost struct {
+	Host
+	rm network.ResourceManager
+}
+
+func (h *rcmgrHost) Network() network.Network {
+	return &rcmgrNetwork{Network: h.Host.Network(), rm: h.rm}
+}
+
+type rcmgrNetwork struct {
+	network.Network
+	rm network.ResourceManager
+}
+
+func (n *rcmgrNetwork) ResourceManager() network.ResourceManager {
+	return n.rm
+}
+
+func TestServer_ReportsResourceManager(t *testing.T) {
+	const proto = "rcmgr-test"
+	limits := rcmgr.PartialLimitConfig{
+		Protocol: map[protocol.ID]rcmgr.ResourceLimits{proto: {StreamsInbound: 1}},
+	}.Build(rcmgr.InfiniteLimits)
+	rm, err := rcmgr.NewResourceManager(rcmgr.NewFixedLimiter(limits))
+	require.NoError(t, err)
+	t.Cleanup(func() { rm.Close() })
+
+	mesh, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	core, logs := observer.New(zapcore.WarnLevel)
+	srv := New(
+		&rcmgrHost{Host: wrapHost(t, mesh.Hosts()[0]), rm: rm},
+		proto,
+		WrapHandler(nil),
+		WithLog(zap.New(core)),
+		WithMetrics(),
+		WithQueueSize(1),
+	)
+
+	srv.checkResources()
+	require.Zero(t, logs.Len())
+	require.Zero(t, testutil.ToFloat64(rcmgrStreams.WithLabelValues(proto, "inbound")))
+	require.Equal(t, 1.0, testutil.ToFloat64(rcmgrStreamLimit.WithLabelValues(proto, "inbound")))
+	require.Zero(t, testutil.ToFloat64(rcmgrStreamLimit.WithLabelValues(proto, "outbound")))
+
+	// the inbound stream limit is reached while the server queue is empty
+	scope, err := rm.OpenStream(mesh.Hosts()[1].ID(), network.DirInbound)
+	require.NoError(t, err)
+	t.Cleanup(scope.Done)
+	require.NoError(t, scope.SetProtocol(proto))
+	srv.checkResources()
+	require.Equal(t, 1.0, testutil.ToFloat64(rcmgrStreams.WithLabelValues(proto, "inbound")))
+	require.Equal(t, 1.0, testutil.ToFloat64(rcmgrSaturated.WithLabelValues(proto, "streams_inbound")))
+	warnings := logs.FilterMessage("resource manager limits the protocol before the server queue is full")
+	require.Equal(t, 1, warnings.Len())
+	require.Equal(t, []any{"streams_inbound"}, warnings.All()[0].ContextMap()["saturated"])
+
+	// the server queue is full as well, which is reported by the dropped requests
+	require.True(t, srv.lane.sem.TryAcquire(1))
+	srv.checkResources()
+	require.Equal(t, 2.0, testutil.ToFloat64(rcmgrSaturated.WithLabelValues(proto, "streams_inbound")))
+	require.Equal(t, 1, logs.Len())
+	srv.lane.sem.Release(1)
+
+	// rejected outbound streams are reported once per check
+	srv.observeOpenError(fmt.Errorf("open stream: %w", network.ErrResourceLimitExceeded))
+	srv.observeOpenError(fmt.Errorf("open stream: %w", network.ErrResourceLimitExceeded))
+	srv.observeOpenError(fmt.Errorf("no addresses"))
+	require.Equal(t, 2.0, testutil.ToFloat64(rcmgrRejected.WithLabelValues(proto)))
+	scope.Done()
+	srv.checkResources()
+	rejections := logs.FilterMessage("resource manager rejected outbound streams of the protocol")
+	require.Equal(t, 1, rejections.Len())
+	require.EqualValues(t, 2, rejections.All()[0].ContextMap()["rejected"])
+	srv.checkResources()
+	require.Equal(t, 1, logs.FilterMessage("resource manager rejected outbound streams of the protocol").Len())
+}
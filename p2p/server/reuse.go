@@ -0,0 +1,345 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/multiformats/go-varint"
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/p2p/peerinfo"
+)
+
+// maxIdleStreams is the maximal number of idle streams kept open to the same peer.
+const maxIdleStreams = 4
+
+var errSequenceMismatch = errors.New("response sequence mismatch")
+
+// PipelinedProtocol returns the protocol that is used to send consecutive requests over the same stream.
+// Every request on such stream is prefixed with its sequence number, and the response is prefixed
+// with the sequence number of the request it belongs to and followed by the ResponseMetadata.
+func PipelinedProtocol(proto string) string {
+	return proto + "/pipe"
+}
+
+// pooledStream is a stream that can be used for consecutive requests.
+type pooledStream struct {
+	network.Stream
+	seq   uint64
+	timer *time.Timer
+}
+
+// streamPool keeps idle streams to peers until they are reused or stay idle for too long.
+type streamPool struct {
+	idle time.Duration
+
+	mu      sync.Mutex
+	streams map[peer.ID][]*pooledStream
+}
+
+func newStreamPool(idle time.Duration) *streamPool {
+	return &streamPool{
+		idle:    idle,
+		streams: make(map[peer.ID][]*pooledStream),
+	}
+}
+
+// get returns the most recently used idle stream to the peer, or nil if there is none.
+func (p *streamPool) get(pid peer.ID) *pooledStream {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	streams := p.streams[pid]
+	if len(streams) == 0 {
+		return nil
+	}
+	ps := streams[len(streams)-1]
+	p.removeLocked(pid, len(streams)-1)
+	ps.timer.Stop()
+	return ps
+}
+
+// put returns the stream to the pool. The stream is closed if the pool for the peer is full.
+func (p *streamPool) put(pid peer.ID, ps *pooledStream) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.streams[pid]) >= maxIdleStreams {
+		ps.Close()
+		return
+	}
+	_ = ps.SetDeadline(time.Time{})
+	ps.timer = time.AfterFunc(p.idle, func() { p.expire(pid, ps) })
+	p.streams[pid] = append(p.streams[pid], ps)
+}
+
+func (p *streamPool) expire(pid peer.ID, ps *pooledStream) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, other := range p.streams[pid] {
+		if other == ps {
+			// stream is still idle, otherwise it was taken by get and is in use
+			p.removeLocked(pid, i)
+			ps.Close()
+			return
+		}
+	}
+}
+
+func (p *streamPool) removeLocked(pid peer.ID, i int) {
+	streams := p.streams[pid]
+	streams = append(streams[:i], streams[i+1:]...)
+	if len(streams) == 0 {
+		delete(p.streams, pid)
+	} else {
+		p.streams[pid] = streams
+	}
+}
+
+func (p *streamPool) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for pid, streams := range p.streams {
+		for _, ps := range streams {
+			ps.timer.Stop()
+			ps.Close()
+		}
+		delete(p.streams, pid)
+	}
+}
+
+// pipelinedRequest sends the request over an idle stream to the peer, or opens a new one.
+// Streams are returned to the pool only after a successful response, so that a stream never
+// carries leftovers of a failed request.
+func (s *Server) pipelinedRequest(ctx context.Context, pid peer.ID, req []byte) ([]byte, error) {
+	start := time.Now()
+	if err := s.frames.check(uint64(len(req))); err != nil {
+		return nil, err
+	}
+	if s.h.Network().Connectedness(pid) != network.Connected {
+		return nil, fmt.Errorf("%w: %s", ErrNotConnected, pid)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.hardTimeout)
+	defer cancel()
+
+	var info *peerinfo.Info
+	if s.h.PeerInfo() != nil {
+		info = s.h.PeerInfo().EnsurePeerInfo(pid)
+	}
+	data, err := s.pipelined(ctx, pid, req)
+	s.logger.Debug("request execution time",
+		zap.String("protocol", s.protocol),
+		zap.Duration("duration", time.Since(start)),
+		zap.Error(err),
+		log.ZContext(ctx),
+	)
+	s.requestDone(info, time.Since(start), err)
+	return data, err
+}
+
+func (s *Server) pipelined(ctx context.Context, pid peer.ID, req []byte) ([]byte, error) {
+	for {
+		ps := s.streams.get(pid)
+		reused := ps != nil
+		if !reused {
+			stream, err := s.h.NewStream(
+				network.WithNoDial(ctx, "existing connection"),
+				pid,
+				protocol.ID(PipelinedProtocol(s.protocol)),
+				protocol.ID(s.protocol),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if stream.Protocol() != protocol.ID(PipelinedProtocol(s.protocol)) {
+				// peer doesn't support stream reuse
+				return s.plainRequest(ctx, pid, stream, req)
+			}
+			ps = &pooledStream{Stream: stream}
+		} else if s.metrics != nil {
+			s.metrics.clientReused.Inc()
+		}
+		data, stale, err := s.roundTrip(ctx, ps, req)
+		if err == nil {
+			s.streams.put(pid, ps)
+			return data, nil
+		}
+		ps.Close()
+		if reused && stale && ctx.Err() == nil {
+			// the stream was closed by the peer while it was idle, retry on a new one
+			continue
+		}
+		return nil, err
+	}
+}
+
+func (s *Server) plainRequest(ctx context.Context, pid peer.ID, stream network.Stream, req []byte) ([]byte, error) {
+	stop := context.AfterFunc(ctx, func() { stream.Close() })
+	defer stop()
+	stm, err := s.writeRequest(stream, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stm.Close()
+	rd := getReader(stm)
+	defer putReader(rd)
+	return readResponse(ctx, pid, rd)
+}
+
+// roundTrip sends the request over the stream and reads the response.
+// stale is true if the stream failed before any response data was received.
+func (s *Server) roundTrip(ctx context.Context, ps *pooledStream, req []byte) (data []byte, stale bool, err error) {
+	stop := context.AfterFunc(ctx, func() { ps.Close() })
+	defer func() {
+		if !stop() && err == nil {
+			// the stream was closed by the context
+			data, err = nil, ctx.Err()
+		}
+	}()
+	ps.seq++
+	dadj := newDeadlineAdjuster(ps.Stream, s.timeout, s.hardTimeout)
+	wr := getWriter(dadj)
+	defer putWriter(wr)
+	if _, err := wr.Write(varint.ToUvarint(ps.seq)); err != nil {
+		return nil, true, fmt.Errorf("peer %s: %w", ps.Conn().RemotePeer(), err)
+	}
+	if err := s.frames.write(wr, req); err != nil {
+		return nil, true, fmt.Errorf("peer %s: %w", ps.Conn().RemotePeer(), err)
+	}
+	if err := wr.Flush(); err != nil {
+		return nil, true, fmt.Errorf("peer %s: %w", ps.Conn().RemotePeer(), err)
+	}
+
+	rd := getReader(dadj)
+	defer putReader(rd)
+	seq, err := varint.ReadUvarint(rd)
+	if err != nil {
+		return nil, dadj.totalRead == 0, fmt.Errorf("peer %s: %w", ps.Conn().RemotePeer(), err)
+	}
+	if seq != ps.seq {
+		return nil, false, fmt.Errorf("%w: peer %s: expected %d, got %d",
+			errSequenceMismatch, ps.Conn().RemotePeer(), ps.seq, seq)
+	}
+	data, err = readResponse(ctx, ps.Conn().RemotePeer(), rd)
+	if err != nil {
+		return nil, false, err
+	}
+	var md ResponseMetadata
+	if _, err := codec.DecodeFrom(rd, &md); err != nil {
+		return nil, false, fmt.Errorf("peer %s: read metadata: %w", ps.Conn().RemotePeer(), err)
+	}
+	s.onMetadata(ps.Conn().RemotePeer(), &md)
+	if rd.Buffered() > 0 {
+		return nil, false, fmt.Errorf("peer %s: unexpected data after response", ps.Conn().RemotePeer())
+	}
+	return data, false, nil
+}
+
+// handlePipelinedStream reads consecutive requests from the stream until the peer closes it,
+// the stream stays idle for the timeout or a request fails. Every request is queued as a separate
+// frame, so that it takes a slot in the lane and waits for the rate limit only while it is served,
+// and an idle stream doesn't hold any server resources.
+func (s *Server) handlePipelinedStream(stream network.Stream) {
+	defer stream.Close()
+	// the reader is not pooled, as it may be still used by the frame that is served
+	// when the server stops
+	rd := bufio.NewReader(stream)
+	for served := 0; ; served++ {
+		_ = stream.SetDeadline(time.Now().Add(s.timeout))
+		seq, err := varint.ReadUvarint(rd)
+		if err != nil {
+			if served > 0 {
+				// the stream was closed by the peer or stayed idle for too long
+				return
+			}
+			s.logger.Debug("initial read failed",
+				zap.String("protocol", s.protocol),
+				zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
+				zap.Stringer("remoteMultiaddr", stream.Conn().RemoteMultiaddr()),
+				zap.Error(err),
+			)
+			s.audit(stream.Conn().RemotePeer(), time.Now(), 0, 0, AuditReadFailed, err)
+			return
+		}
+		fr := &frame{rd: rd, seq: seq, done: make(chan bool, 1)}
+		if !s.enqueue(s.lane, request{stream: stream, frame: fr}) {
+			if s.metrics != nil {
+				s.metrics.dropped.Inc()
+			}
+			return
+		}
+		select {
+		case <-s.stopped:
+			return
+		case ok := <-fr.done:
+			if !ok {
+				return
+			}
+		}
+		if s.metrics != nil && served > 0 {
+			s.metrics.pipelined.Inc()
+		}
+	}
+}
+
+// frame is a request on a pipelined stream.
+type frame struct {
+	rd   *bufio.Reader
+	seq  uint64
+	done chan bool
+}
+
+// serveFrame reads the request with the sequence number seq and writes the response
+// prefixed with the same sequence number, followed by the response metadata.
+func (s *Server) serveFrame(ctx context.Context, stream network.Stream, fr *frame) bool {
+	start := time.Now()
+	// the deadline for the idle stream is extended, as the frame could wait in the queue
+	_ = stream.SetDeadline(start.Add(s.timeout))
+	buf, err := s.readFrame(stream, fr.rd)
+	if err != nil {
+		s.audit(stream.Conn().RemotePeer(), start, 0, 0, AuditReadFailed, err)
+		return false
+	}
+	defer buffers.put(buf)
+	if s.deprecated != nil {
+		s.deprecated.observe(stream.Conn().RemotePeer(), start)
+	}
+	dadj := newDeadlineAdjuster(stream, s.timeout, s.hardTimeout)
+	if _, err := dadj.Write(varint.ToUvarint(fr.seq)); err != nil {
+		s.logger.Debug("error writing response",
+			zap.String("protocol", s.protocol),
+			zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
+			zap.Error(err),
+		)
+		s.audit(stream.Conn().RemotePeer(), start, len(buf), dadj.totalWritten, AuditWriteFailed, err)
+		return false
+	}
+	if err := s.handler(ContextWithPeer(log.WithNewRequestID(ctx), stream.Conn().RemotePeer()), buf, dadj); err != nil {
+		// the client doesn't reuse the stream after an error, as it may be left in an unknown state
+		s.logger.Debug("handler reported error",
+			zap.String("protocol", s.protocol),
+			zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
+			zap.Error(err),
+		)
+		s.audit(stream.Conn().RemotePeer(), start, len(buf), dadj.totalWritten, AuditHandlerFailed, err)
+		return false
+	}
+	if err := s.writeMetadata(dadj); err != nil {
+		s.logger.Debug("error writing response metadata",
+			zap.String("protocol", s.protocol),
+			zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
+			zap.Error(err),
+		)
+		s.audit(stream.Conn().RemotePeer(), start, len(buf), dadj.totalWritten, AuditWriteFailed, err)
+		return false
+	}
+	s.audit(stream.Conn().RemotePeer(), start, len(buf), dadj.totalWritten, AuditServed, nil)
+	return true
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/multiformats/go-varint"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+)
+
+func idleStreams(s *Server, pid peer.ID) []*pooledStream {
+	s.streams.mu.Lock()
+	defer s.streams.mu.Unlock()
+	return append([]*pooledStream(nil), s.streams.streams[pid]...)
+}
+
+func TestStreamReuse(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(5)
+	require.NoError(t, err)
+	proto := "test"
+	testErr := errors.New("test error")
+	handler := WrapHandler(func(_ context.Context, msg []byte) ([]byte, error) {
+		if string(msg) == "fail" {
+			return nil, testErr
+		}
+		return msg, nil
+	})
+	opts := []Opt{WithLog(zaptest.NewLogger(t)), WithMetrics()}
+
+	client := New(wrapHost(t, mesh.Hosts()[0]), proto, handler,
+		append(opts, WithTimeout(10*time.Second), WithStreamReuse(time.Minute))...)
+	srv := New(wrapHost(t, mesh.Hosts()[1]), proto, handler, append(opts, WithStreamReuse(time.Minute))...)
+	plain := New(wrapHost(t, mesh.Hosts()[2]), proto, handler, opts...)
+	// peer that serves a single request per stream, and resets the stream on the next one
+	mesh.Hosts()[3].SetStreamHandler(protocol.ID(PipelinedProtocol(proto)), func(stream network.Stream) {
+		rd := bufio.NewReader(stream)
+		seq, _ := varint.ReadUvarint(rd)
+		size, _ := varint.ReadUvarint(rd)
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			stream.Reset()
+			return
+		}
+		stream.Write(varint.ToUvarint(seq))
+		writeResponse(stream, &Response{Data: buf})
+		codec.EncodeTo(stream, &ResponseMetadata{})
+		rd.ReadByte()
+		stream.Reset()
+	})
+	idle := New(wrapHost(t, mesh.Hosts()[4]), proto, handler,
+		append(opts, WithTimeout(200*time.Millisecond), WithStreamReuse(time.Minute))...)
+	require.Contains(t, mesh.Hosts()[1].Mux().Protocols(), protocol.ID(PipelinedProtocol(proto)))
+	require.NotContains(t, mesh.Hosts()[2].Mux().Protocols(), protocol.ID(PipelinedProtocol(proto)))
+	require.Equal(t, 5*time.Second, client.streams.idle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	for _, s := range []*Server{srv, plain, idle} {
+		eg.Go(func() error { return s.Run(ctx) })
+	}
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+
+	t.Run("consecutive requests", func(t *testing.T) {
+		pid := mesh.Hosts()[1].ID()
+		for i := range 5 {
+			msg := []byte{byte(i)}
+			resp, err := client.Request(ctx, pid, msg)
+			require.NoError(t, err)
+			require.Equal(t, msg, resp)
+			streams := idleStreams(client, pid)
+			require.Len(t, streams, 1)
+			require.EqualValues(t, i+1, streams[0].seq)
+		}
+		require.Equal(t, 5, client.h.PeerInfo().EnsurePeerInfo(pid).ClientStats.SuccessCount())
+	})
+	t.Run("server error", func(t *testing.T) {
+		pid := mesh.Hosts()[1].ID()
+		_, err := client.Request(ctx, pid, []byte("fail"))
+		var srvErr *ServerError
+		require.ErrorAs(t, err, &srvErr)
+		require.ErrorContains(t, err, testErr.Error())
+		require.Empty(t, idleStreams(client, pid))
+
+		resp, err := client.Request(ctx, pid, []byte("ok"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("ok"), resp)
+		require.Len(t, idleStreams(client, pid), 1)
+	})
+	t.Run("peer without support", func(t *testing.T) {
+		pid := mesh.Hosts()[2].ID()
+		for range 2 {
+			resp, err := client.Request(ctx, pid, []byte("plain"))
+			require.NoError(t, err)
+			require.Equal(t, []byte("plain"), resp)
+			require.Empty(t, idleStreams(client, pid))
+		}
+	})
+	t.Run("stream closed by peer", func(t *testing.T) {
+		pid := mesh.Hosts()[3].ID()
+		_, err := client.Request(ctx, pid, []byte("first"))
+		require.NoError(t, err)
+		require.Len(t, idleStreams(client, pid), 1)
+
+		resp, err := client.Request(ctx, pid, []byte("second"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("second"), resp)
+		streams := idleStreams(client, pid)
+		require.Len(t, streams, 1)
+		require.EqualValues(t, 1, streams[0].seq, "request must be retried on a new stream")
+	})
+	t.Run("idle stream expires", func(t *testing.T) {
+		pid := mesh.Hosts()[1].ID()
+		_, err := idle.Request(ctx, pid, []byte("idle"))
+		require.NoError(t, err)
+		require.Len(t, idleStreams(idle, pid), 1)
+		require.Eventually(t, func() bool {
+			return len(idleStreams(idle, pid)) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestStreamReuse_IdleStreamFreesLane(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(3)
+	require.NoError(t, err)
+	proto := "test"
+	handler := WrapHandler(func(_ context.Context, msg []byte) ([]byte, error) {
+		return msg, nil
+	})
+	opts := []Opt{WithLog(zaptest.NewLogger(t)), WithStreamReuse(time.Minute)}
+	srv := New(wrapHost(t, mesh.Hosts()[0]), proto, handler, append(opts, WithQueueSize(1))...)
+	pipelined := New(wrapHost(t, mesh.Hosts()[1]), proto, handler, opts...)
+	plain := New(wrapHost(t, mesh.Hosts()[2]), proto, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error { return srv.Run(ctx) })
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+
+	pid := mesh.Hosts()[0].ID()
+	for range 3 {
+		_, err := pipelined.Request(ctx, pid, []byte("pipelined"))
+		require.NoError(t, err)
+	}
+	require.Len(t, idleStreams(pipelined, pid), 1)
+
+	// the idle stream doesn't occupy the only slot in the queue
+	resp, err := plain.Request(ctx, pid, []byte("plain"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("plain"), resp)
+
+	// every frame is accounted separately
+	stats := &srv.h.PeerInfo().EnsurePeerInfo(mesh.Hosts()[1].ID()).ServerStats
+	require.Eventually(t, func() bool {
+		return stats.SuccessCount() == 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamPool(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	mesh.Hosts()[1].SetStreamHandler("test", func(s network.Stream) {})
+	pid := mesh.Hosts()[1].ID()
+
+	pool := newStreamPool(time.Minute)
+	var streams []*pooledStream
+	for range maxIdleStreams + 1 {
+		stream, err := mesh.Hosts()[0].NewStream(context.Background(), pid, "test")
+		require.NoError(t, err)
+		streams = append(streams, &pooledStream{Stream: stream})
+	}
+	for _, ps := range streams {
+		pool.put(pid, ps)
+	}
+	require.Len(t, pool.streams[pid], maxIdleStreams)
+	require.Equal(t, streams[maxIdleStreams-1], pool.get(pid))
+	require.Len(t, pool.streams[pid], maxIdleStreams-1)
+	pool.stop()
+	require.Empty(t, pool.streams)
+	require.Nil(t, pool.get(pid))
+}
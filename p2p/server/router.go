@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// ErrUnknownRequestKind is returned by the Router if no handler is registered for the request kind.
+var ErrUnknownRequestKind = errors.New("unknown request kind")
+
+// RequestKind identifies a sub-protocol within a protocol family served by a Router.
+type RequestKind byte
+
+// Router dispatches requests of a protocol family to the sub-handlers registered for
+// each RequestKind. The kind is encoded as the first byte of the request.
+//
+// Router is a StreamHandler, so that all sub-protocols are served by a single Server
+// and share its queue, rate limiter and metrics.
+type Router struct {
+	protocol string
+
+	mu       sync.RWMutex
+	handlers map[RequestKind]StreamHandler
+}
+
+// NewRouter creates a Router for the protocol family.
+func NewRouter(protocol string) *Router {
+	return &Router{
+		protocol: protocol,
+		handlers: make(map[RequestKind]StreamHandler),
+	}
+}
+
+// Register handler for the request kind. It panics if handler for the kind is already registered.
+func (r *Router) Register(kind RequestKind, handler StreamHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exist := r.handlers[kind]; exist {
+		panic(fmt.Sprintf("handler for request kind %d is already registered", kind))
+	}
+	r.handlers[kind] = handler
+}
+
+// Handle dispatches the request to the handler registered for its kind.
+func (r *Router) Handle(ctx context.Context, req []byte, stream io.ReadWriter) error {
+	if len(req) == 0 {
+		routedRequests.WithLabelValues(r.protocol, "unknown").Inc()
+		err := fmt.Errorf("%w: empty request", ErrUnknownRequestKind)
+		if werr := WriteErrorResponse(stream, err); werr != nil {
+			return werr
+		}
+		return err
+	}
+	kind := RequestKind(req[0])
+	r.mu.RLock()
+	handler, exist := r.handlers[kind]
+	r.mu.RUnlock()
+	if !exist {
+		routedRequests.WithLabelValues(r.protocol, "unknown").Inc()
+		err := fmt.Errorf("%w: %d", ErrUnknownRequestKind, kind)
+		if werr := WriteErrorResponse(stream, err); werr != nil {
+			return werr
+		}
+		return err
+	}
+	routedRequests.WithLabelValues(r.protocol, strconv.Itoa(int(kind))).Inc()
+	return handler(ctx, req[1:], stream)
+}
+
+// RoutedRequest prefixes the request with the kind so that it is dispatched by the Router
+// to the handler registered for the kind.
+func RoutedRequest(kind RequestKind, req []byte) []byte {
+	buf := make([]byte, 0, len(req)+1)
+	buf = append(buf, byte(kind))
+	return append(buf, req...)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestRouter(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	proto := "test"
+	const (
+		echo RequestKind = iota
+		fail
+		unknown
+	)
+	testErr := errors.New("test error")
+
+	router := NewRouter(proto)
+	router.Register(echo, WrapHandler(func(_ context.Context, msg []byte) ([]byte, error) {
+		return msg, nil
+	}))
+	router.Register(fail, WrapHandler(func(_ context.Context, _ []byte) ([]byte, error) {
+		return nil, testErr
+	}))
+	require.Panics(t, func() { router.Register(echo, nil) })
+
+	opts := []Opt{
+		WithTimeout(100 * time.Millisecond),
+		WithLog(zaptest.NewLogger(t)),
+	}
+	client := New(wrapHost(t, mesh.Hosts()[0]), proto, nil, opts...)
+	srv := New(wrapHost(t, mesh.Hosts()[1]), proto, router.Handle, opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return srv.Run(ctx)
+	})
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+	require.Eventually(t, func() bool {
+		return len(mesh.Hosts()[1].Mux().Protocols()) > 0
+	}, time.Second, 10*time.Millisecond)
+	srvID := mesh.Hosts()[1].ID()
+
+	t.Run("dispatched", func(t *testing.T) {
+		request := []byte("test request")
+		response, err := client.Request(ctx, srvID, RoutedRequest(echo, request))
+		require.NoError(t, err)
+		require.Equal(t, request, response)
+	})
+	t.Run("handler error", func(t *testing.T) {
+		_, err := client.Request(ctx, srvID, RoutedRequest(fail, []byte("test request")))
+		var srvErr *ServerError
+		require.ErrorAs(t, err, &srvErr)
+		require.ErrorContains(t, err, testErr.Error())
+	})
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := client.Request(ctx, srvID, RoutedRequest(unknown, []byte("test request")))
+		var srvErr *ServerError
+		require.ErrorAs(t, err, &srvErr)
+		require.ErrorContains(t, err, ErrUnknownRequestKind.Error())
+	})
+	t.Run("empty request", func(t *testing.T) {
+		_, err := client.Request(ctx, srvID, nil)
+		var srvErr *ServerError
+		require.ErrorAs(t, err, &srvErr)
+		require.ErrorContains(t, err, ErrUnknownRequestKind.Error())
+	})
+}
@@ -3,22 +3,20 @@ package server
 import (
 	"bufio"
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/connmgr"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
-	"github.com/multiformats/go-varint"
 	dto "github.com/prometheus/client_model/go"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
-	"golang.org/x/sync/semaphore"
-	"golang.org/x/time/rate"
 
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/log"
@@ -104,6 +102,65 @@ func WithRequestsPerInterval(n int, interval time.Duration) Opt {
 	}
 }
 
+// WithPrewarmBudget configures the maximal number of peers that can be prewarmed at the same time.
+func WithPrewarmBudget(peers int) Opt {
+	return func(s *Server) {
+		s.prewarmBudget = peers
+	}
+}
+
+// WithPrewarmMaxTTL configures the maximal time a peer stays prewarmed after a call to Prewarm.
+func WithPrewarmMaxTTL(ttl time.Duration) Opt {
+	return func(s *Server) {
+		s.prewarmMaxTTL = ttl
+	}
+}
+
+// WithPriorityLane reserves a queue of the specified size for requests with the priority hint
+// from priority peers. Requests in the lane are rate limited separately, up to requestsPerInterval
+// in the interval configured with WithRequestsPerInterval. Requests over the capacity of the lane
+// are queued as usual.
+//
+// Disabled by default.
+func WithPriorityLane(size, requestsPerInterval int) Opt {
+	return func(s *Server) {
+		s.priorityQueueSize = size
+		s.priorityRequestsPerInterval = requestsPerInterval
+	}
+}
+
+// WithPriorityPeers configures the peers whose priority hint is honored by the server.
+func WithPriorityPeers(peers ...peer.ID) Opt {
+	return func(s *Server) {
+		s.SetPriorityPeers(peers)
+	}
+}
+
+// WithStreamReuse enables serving consecutive requests over the same stream, and reusing
+// streams to the same peer for requests sent with Request. Streams stay open for at most
+// idle time without requests, capped at a half of the timeout configured with WithTimeout,
+// so that they are closed by the client before the remote server closes them.
+//
+// Requests to peers that don't support stream reuse are sent over a new stream as usual.
+// A request is retried once on a new stream if the idle stream turns out to be closed by the peer,
+// so it must be safe to execute the request twice.
+//
+// Disabled by default.
+func WithStreamReuse(idle time.Duration) Opt {
+	return func(s *Server) {
+		s.streamIdle = idle
+	}
+}
+
+// WithAuditSink configures the sink that receives a record for every request handled by the server.
+//
+// Disabled by default.
+func WithAuditSink(sink AuditSink) Opt {
+	return func(s *Server) {
+		s.auditSink = sink
+	}
+}
+
 func WithDecayingTag(tag DecayingTagSpec) Opt {
 	return func(s *Server) {
 		s.decayingTagSpec = &tag
@@ -111,10 +168,13 @@ func WithDecayingTag(tag DecayingTagSpec) Opt {
 }
 
 // Handler is a handler to be defined by the application.
+// As with StreamHandler, the request must not be retained after the handler returns.
 type Handler func(context.Context, []byte) ([]byte, error)
 
 // StreamHandler is a handler that writes the response to the stream directly instead of
 // buffering the serialized representation.
+// The request buffer is reused by the server after the handler returns, so the handler
+// must copy any part of the request it retains.
 type StreamHandler func(context.Context, []byte, io.ReadWriter) error
 
 // StreamRequestCallback is a function that executes a streamed request.
@@ -156,13 +216,34 @@ type Server struct {
 	interval            time.Duration
 	decayingTagSpec     *DecayingTagSpec
 	decayingTag         connmgr.DecayingTag
+	prewarmBudget       int
+	prewarmMaxTTL       time.Duration
+
+	priorityQueueSize           int
+	priorityRequestsPerInterval int
+	streamIdle                  time.Duration
+	resourceInterval            time.Duration
+
+	// rcmgrRejected counts the streams rejected by the resource manager since the last report
+	rcmgrRejected atomic.Int64
+
+	lane     *lane
+	priority *lane // nil if priority lane is disabled
+	stopped  chan struct{}
 
-	limit   *rate.Limiter
-	sem     *semaphore.Weighted
-	queue   chan request
-	stopped chan struct{}
+	priorityMu    sync.Mutex
+	priorityPeers map[peer.ID]struct{}
 
-	metrics *tracker // metrics can be nil
+	metrics   *tracker  // metrics can be nil
+	auditSink AuditSink // auditSink can be nil
+	frames    frameCodec
+	sizes     *sizeTracker
+	prewarm   *prewarmer
+	streams   *streamPool // nil if stream reuse is disabled
+
+	deprecation *Deprecation        // nil if the protocol is not deprecated
+	deprecated  *deprecationTracker // nil if the protocol is not deprecated
+	notice      atomic.Pointer[Deprecation]
 
 	h Host
 }
@@ -180,8 +261,10 @@ func New(h Host, proto string, handler StreamHandler, opts ...Opt) *Server {
 		queueSize:           1000,
 		requestsPerInterval: 100,
 		interval:            time.Second,
+		prewarmBudget:       100,
+		prewarmMaxTTL:       10 * time.Minute,
+		resourceInterval:    30 * time.Second,
 
-		queue:   make(chan request),
 		stopped: make(chan struct{}),
 	}
 	for _, opt := range opts {
@@ -204,30 +287,25 @@ func New(h Host, proto string, handler StreamHandler, opts ...Opt) *Server {
 		}
 	}
 
-	srv.limit = rate.NewLimiter(
-		rate.Every(srv.interval/time.Duration(srv.requestsPerInterval)),
-		srv.requestsPerInterval,
-	)
-	srv.sem = semaphore.NewWeighted(int64(srv.queueSize))
-	srv.h.SetStreamHandler(protocol.ID(srv.protocol), func(stream network.Stream) {
-		if !srv.sem.TryAcquire(1) {
-			if srv.metrics != nil {
-				srv.metrics.dropped.Inc()
-			}
-			stream.Close()
-			return
-		}
-		select {
-		case <-srv.stopped:
-			srv.sem.Release(1)
-			stream.Close()
-		case srv.queue <- request{stream: stream, received: time.Now()}:
-			// at most s.queueSize requests block here, the others are dropped with the semaphore
-		}
-	})
+	srv.frames = frameCodec{limit: srv.requestLimit}
+	srv.sizes = newSizeTracker(srv.requestLimit)
+	srv.prewarm = newPrewarmer(h, proto, srv.prewarmBudget, srv.prewarmMaxTTL)
+	srv.lane = newLane(srv.queueSize, srv.requestsPerInterval, srv.interval)
+	srv.h.SetStreamHandler(protocol.ID(srv.protocol), srv.handleStream)
+	if srv.priorityQueueSize > 0 && srv.priorityRequestsPerInterval > 0 {
+		srv.priority = newLane(srv.priorityQueueSize, srv.priorityRequestsPerInterval, srv.interval)
+		srv.h.SetStreamHandler(protocol.ID(PriorityProtocol(srv.protocol)), srv.handlePriorityStream)
+	}
+	if srv.streamIdle > 0 {
+		srv.streams = newStreamPool(min(srv.streamIdle, srv.timeout/2))
+		srv.h.SetStreamHandler(protocol.ID(PipelinedProtocol(srv.protocol)), srv.handlePipelinedStream)
+	}
+	if srv.deprecation != nil {
+		srv.deprecated = newDeprecationTracker(srv.protocol, deprecatedPeersWindow)
+	}
 	if srv.metrics != nil {
 		srv.metrics.targetQueue.Set(float64(srv.queueSize))
-		srv.metrics.targetRps.Set(float64(srv.limit.Limit()))
+		srv.metrics.targetRps.Set(float64(srv.lane.limit.Limit()))
 	}
 	return srv
 }
@@ -235,135 +313,158 @@ func New(h Host, proto string, handler StreamHandler, opts ...Opt) *Server {
 type request struct {
 	stream   network.Stream
 	received time.Time
+	frame    *frame // nil if the request is not on a pipelined stream
 }
 
 func (s *Server) Run(ctx context.Context) error {
 	var eg errgroup.Group
-	for {
-		select {
-		case <-ctx.Done():
-			close(s.stopped)
-			eg.Wait()
+	if s.resourceInterval > 0 {
+		eg.Go(func() error {
+			s.reportResources(ctx)
 			return nil
-		case req := <-s.queue:
-			if s.metrics != nil {
-				s.metrics.queue.Set(float64(s.queueSize))
-				s.metrics.accepted.Inc()
-			}
-			if s.metrics != nil {
-				s.metrics.inQueueLatency.Observe(time.Since(req.received).Seconds())
-			}
-			if err := s.limit.Wait(ctx); err != nil {
-				eg.Wait()
-				return nil
-			}
-			ctx, cancel := context.WithCancel(ctx)
-			eg.Go(func() error {
-				<-ctx.Done()
-				s.sem.Release(1)
-				req.stream.Close()
-				return nil
-			})
-			eg.Go(func() error {
-				defer cancel()
-				conn := req.stream.Conn()
-				if s.decayingTag != nil {
-					s.decayingTag.Bump(conn.RemotePeer(), s.decayingTagSpec.Inc)
-				}
-				ok := s.queueHandler(ctx, req.stream)
-				duration := time.Since(req.received)
-				if s.h.PeerInfo() != nil {
-					info := s.h.PeerInfo().EnsurePeerInfo(conn.RemotePeer())
-					info.ServerStats.RequestDone(duration, ok)
-				}
-				if s.metrics != nil {
-					s.metrics.serverLatency.Observe(duration.Seconds())
-					if ok {
-						s.metrics.completed.Inc()
-					} else {
-						s.metrics.failed.Inc()
-					}
-				}
-				return nil
-			})
-		}
+		})
+	}
+	if s.priority != nil {
+		eg.Go(func() error {
+			s.serve(ctx, &eg, s.priority)
+			return nil
+		})
 	}
+	s.serve(ctx, &eg, s.lane)
+	close(s.stopped)
+	s.prewarm.stop()
+	if s.streams != nil {
+		s.streams.stop()
+	}
+	eg.Wait()
+	return nil
 }
 
 func (s *Server) queueHandler(ctx context.Context, stream network.Stream) bool {
 	dadj := newDeadlineAdjuster(stream, s.timeout, s.hardTimeout)
 	defer dadj.Close()
-	rd := bufio.NewReader(dadj)
-	size, err := varint.ReadUvarint(rd)
+	start := time.Now()
+	rd := getReader(dadj)
+	buf, err := s.readFrame(stream, rd)
+	putReader(rd)
 	if err != nil {
-		s.logger.Debug("initial read failed",
+		s.audit(stream.Conn().RemotePeer(), start, 0, 0, AuditReadFailed, err)
+		return false
+	}
+	defer buffers.put(buf)
+	if s.deprecated != nil {
+		s.deprecated.observe(stream.Conn().RemotePeer(), start)
+	}
+	hctx := s.withDeprecation(ContextWithPeer(log.WithNewRequestID(ctx), stream.Conn().RemotePeer()))
+	if err := s.handler(hctx, buf, dadj); err != nil {
+		s.logger.Debug("handler reported error",
 			zap.String("protocol", s.protocol),
 			zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
 			zap.Stringer("remoteMultiaddr", stream.Conn().RemoteMultiaddr()),
 			zap.Error(err),
 		)
+		s.audit(stream.Conn().RemotePeer(), start, len(buf), dadj.totalWritten, AuditHandlerFailed, err)
 		return false
 	}
-	if size > uint64(s.requestLimit) {
+	s.audit(stream.Conn().RemotePeer(), start, len(buf), dadj.totalWritten, AuditServed, nil)
+	s.logger.Debug("protocol handler execution time",
+		zap.String("protocol", s.protocol),
+		zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
+		zap.Stringer("remoteMultiaddr", stream.Conn().RemoteMultiaddr()),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return true
+}
+
+// readFrame reads the request from rd. If the request is larger than the limit
+// the connection to the peer is closed.
+func (s *Server) readFrame(stream network.Stream, rd *bufio.Reader) ([]byte, error) {
+	buf, err := s.frames.read(rd)
+	switch {
+	case errors.Is(err, errFrameTooLarge):
 		s.logger.Warn("request limit overflow",
 			zap.String("protocol", s.protocol),
 			zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
 			zap.Stringer("remoteMultiaddr", stream.Conn().RemoteMultiaddr()),
-			zap.Int("limit", s.requestLimit),
-			zap.Uint64("request", size),
+			zap.Error(err),
 		)
 		stream.Conn().Close()
-		return false
-	}
-	buf := make([]byte, size)
-	_, err = io.ReadFull(rd, buf)
-	if err != nil {
+		return nil, err
+	case err != nil:
 		s.logger.Debug("error reading request",
 			zap.String("protocol", s.protocol),
 			zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
 			zap.Stringer("remoteMultiaddr", stream.Conn().RemoteMultiaddr()),
 			zap.Error(err),
 		)
-		return false
+		return nil, err
 	}
-	start := time.Now()
-	if err = s.handler(log.WithNewRequestID(ctx), buf, dadj); err != nil {
-		s.logger.Debug("handler reported error",
-			zap.String("protocol", s.protocol),
-			zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
-			zap.Stringer("remoteMultiaddr", stream.Conn().RemoteMultiaddr()),
-			zap.Error(err),
-		)
-		return false
+	s.observeSize(stream.Conn().RemotePeer(), len(buf))
+	return buf, nil
+}
+
+func (s *Server) observeSize(pid peer.ID, size int) {
+	if s.metrics != nil {
+		s.metrics.requestSize.Observe(float64(size))
 	}
-	s.logger.Debug("protocol handler execution time",
+	anomaly := s.sizes.observe(pid, size)
+	if anomaly == anomalyNone {
+		return
+	}
+	s.logger.Warn("peer sends requests with anomalous size",
 		zap.String("protocol", s.protocol),
-		zap.Stringer("remotePeer", stream.Conn().RemotePeer()),
-		zap.Stringer("remoteMultiaddr", stream.Conn().RemoteMultiaddr()),
-		zap.Duration("duration", time.Since(start)),
+		zap.Stringer("remotePeer", pid),
+		zap.String("anomaly", string(anomaly)),
+		zap.Int("limit", s.requestLimit),
 	)
-	return true
+	if s.metrics != nil {
+		switch anomaly {
+		case anomalyNearLimit:
+			s.metrics.nearLimitPeers.Inc()
+		case anomalyOutlier:
+			s.metrics.outlierPeers.Inc()
+		}
+	}
+	if s.h.PeerInfo() != nil {
+		s.h.PeerInfo().EnsurePeerInfo(pid).RequestAnomalies.Add(1)
+	}
 }
 
 // Request sends a binary request to the peer.
+//
+// If stream reuse is enabled with WithStreamReuse, the request is sent over an idle stream
+// to the peer if there is one.
 func (s *Server) Request(ctx context.Context, pid peer.ID, req []byte, extraProtocols ...string) ([]byte, error) {
-	var r Response
-	if err := s.StreamRequest(ctx, pid, req, func(ctx context.Context, stream io.ReadWriter) error {
-		rd := bufio.NewReader(stream)
-		if _, err := codec.DecodeFrom(rd, &r); err != nil {
-			if errors.Is(err, io.ErrClosedPipe) && ctx.Err() != nil {
-				// ensure that a canceled context is returned as the right error
-				return ctx.Err()
-			}
-			return fmt.Errorf("peer %s: %w", pid, err)
-		}
-		if r.Error != "" {
-			return &ServerError{msg: r.Error}
+	if s.streams != nil && len(extraProtocols) == 0 && !IsPriority(ctx) {
+		return s.pipelinedRequest(ctx, pid, req)
+	}
+	var data []byte
+	if err := s.StreamRequest(ctx, pid, req, func(ctx context.Context, stream io.ReadWriter) (err error) {
+		rd := getReader(stream)
+		defer putReader(rd)
+		data, err = readResponse(ctx, pid, rd)
+		if err == nil {
+			s.readMetadata(pid, rd)
 		}
-		return nil
+		return err
 	}, extraProtocols...); err != nil {
 		return nil, err
 	}
+	return data, nil
+}
+
+func readResponse(ctx context.Context, pid peer.ID, rd io.Reader) ([]byte, error) {
+	var r Response
+	if _, err := codec.DecodeFrom(rd, &r); err != nil {
+		if errors.Is(err, io.ErrClosedPipe) && ctx.Err() != nil {
+			// ensure that a canceled context is returned as the right error
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("peer %s: %w", pid, err)
+	}
+	if r.Error != "" {
+		return nil, &ServerError{msg: r.Error}
+	}
 	return r.Data, nil
 }
 
@@ -377,8 +478,8 @@ func (s *Server) StreamRequest(
 	extraProtocols ...string,
 ) error {
 	start := time.Now()
-	if len(req) > s.requestLimit {
-		return fmt.Errorf("request length (%d) is longer than limit %d", len(req), s.requestLimit)
+	if err := s.frames.check(uint64(len(req))); err != nil {
+		return err
 	}
 	if s.h.Network().Connectedness(pid) != network.Connected {
 		return fmt.Errorf("%w: %s", ErrNotConnected, pid)
@@ -404,11 +505,15 @@ func (s *Server) StreamRequest(
 		cancel()
 		eg.Wait()
 	}
+	s.requestDone(info, time.Since(start), err)
+	return err
+}
 
+func (s *Server) requestDone(info *peerinfo.Info, duration time.Duration, err error) {
 	var srvError *ServerError
-	duration := time.Since(start)
 	if info != nil {
 		info.ClientStats.RequestDone(duration, err == nil)
+		info.ProtoStats(protocol.ID(s.protocol)).RequestDone(duration, err == nil)
 	}
 	switch {
 	case s.metrics == nil:
@@ -422,7 +527,6 @@ func (s *Server) StreamRequest(
 		s.metrics.clientSucceeded.Inc()
 		s.metrics.clientLatency.Observe(duration.Seconds())
 	}
-	return err
 }
 
 func (s *Server) streamRequest(
@@ -435,44 +539,48 @@ func (s *Server) streamRequest(
 	info *peerinfo.Info,
 	err error,
 ) {
-	protoIDs := make([]protocol.ID, len(extraProtocols)+1)
-	for n, p := range extraProtocols {
-		protoIDs[n] = protocol.ID(p)
+	protoIDs := make([]protocol.ID, 0, len(extraProtocols)+2)
+	if IsPriority(ctx) && len(extraProtocols) == 0 {
+		protoIDs = append(protoIDs, protocol.ID(PriorityProtocol(s.protocol)))
 	}
-	protoIDs[len(extraProtocols)] = protocol.ID(s.protocol)
+	for _, p := range extraProtocols {
+		protoIDs = append(protoIDs, protocol.ID(p))
+	}
+	protoIDs = append(protoIDs, protocol.ID(s.protocol))
 	stream, err := s.h.NewStream(
 		network.WithNoDial(ctx, "existing connection"),
 		pid,
 		protoIDs...,
 	)
 	if err != nil {
+		s.observeOpenError(err)
 		return nil, nil, err
 	}
 	if s.h.PeerInfo() != nil {
 		info = s.h.PeerInfo().EnsurePeerInfo(stream.Conn().RemotePeer())
 	}
+	stm, err = s.writeRequest(stream, req)
+	return stm, info, err
+}
+
+func (s *Server) writeRequest(stream network.Stream, req []byte) (stm io.ReadWriteCloser, err error) {
 	dadj := newDeadlineAdjuster(stream, s.timeout, s.hardTimeout)
 	defer func() {
 		if err != nil {
 			dadj.Close()
 		}
 	}()
-	wr := bufio.NewWriter(dadj)
-	sz := make([]byte, binary.MaxVarintLen64)
-	n := binary.PutUvarint(sz, uint64(len(req)))
-	if _, err := wr.Write(sz[:n]); err != nil {
-		return nil, info, fmt.Errorf("peer %s address %s: %w",
-			pid, stream.Conn().RemoteMultiaddr(), err)
-	}
-	if _, err := wr.Write(req); err != nil {
-		return nil, info, fmt.Errorf("peer %s address %s: %w",
-			pid, stream.Conn().RemoteMultiaddr(), err)
+	wr := getWriter(dadj)
+	defer putWriter(wr)
+	if err := s.frames.write(wr, req); err != nil {
+		return nil, fmt.Errorf("peer %s address %s: %w",
+			stream.Conn().RemotePeer(), stream.Conn().RemoteMultiaddr(), err)
 	}
 	if err := wr.Flush(); err != nil {
-		return nil, info, fmt.Errorf("peer %s address %s: %w",
-			pid, stream.Conn().RemoteMultiaddr(), err)
+		return nil, fmt.Errorf("peer %s address %s: %w",
+			stream.Conn().RemotePeer(), stream.Conn().RemoteMultiaddr(), err)
 	}
-	return dadj, info, nil
+	return dadj, nil
 }
 
 // NumAcceptedRequests returns the number of accepted requests for this server.
@@ -489,7 +597,8 @@ func (s *Server) NumAcceptedRequests() int {
 }
 
 func writeResponse(w io.Writer, resp *Response) error {
-	wr := bufio.NewWriter(w)
+	wr := getWriter(w)
+	defer putWriter(wr)
 	if _, err := codec.EncodeTo(wr, resp); err != nil {
 		return fmt.Errorf("failed to write response (len %d err len %d): %w",
 			len(resp.Data), len(resp.Error), err)
@@ -512,6 +621,9 @@ func ReadResponse(r io.Reader, toCall func(resLen uint32) (int, error)) (int, er
 	if err != nil {
 		return nBytes, err
 	}
+	if respLen > maxResponseSize {
+		return nBytes, fmt.Errorf("%w: response length %d, limit %d", errResponseTooLarge, respLen, maxResponseSize)
+	}
 	if respLen != 0 {
 		n, err := toCall(respLen)
 		nBytes += n
@@ -522,7 +634,7 @@ func ReadResponse(r io.Reader, toCall func(resLen uint32) (int, error)) (int, er
 			return nBytes, errors.New("malformed server response")
 		}
 	}
-	errStr, n, err := codec.DecodeStringWithLimit(r, 1024)
+	errStr, n, err := codec.DecodeStringWithLimit(r, maxErrorSize)
 	nBytes += n
 	switch {
 	case err != nil:
@@ -535,6 +647,20 @@ func ReadResponse(r io.Reader, toCall func(resLen uint32) (int, error)) (int, er
 	return nBytes, nil
 }
 
+type peerKey struct{}
+
+// ContextWithPeer returns the context with the peer whose request is served, it's set by the server
+// for every handler.
+func ContextWithPeer(ctx context.Context, pid peer.ID) context.Context {
+	return context.WithValue(ctx, peerKey{}, pid)
+}
+
+// PeerFromContext returns the peer whose request is served with the context passed to a handler.
+func PeerFromContext(ctx context.Context) (peer.ID, bool) {
+	pid, ok := ctx.Value(peerKey{}).(peer.ID)
+	return pid, ok
+}
+
 func WrapHandler(handler Handler) StreamHandler {
 	return func(ctx context.Context, req []byte, stream io.ReadWriter) error {
 		buf, hErr := handler(ctx, req)
@@ -547,6 +673,9 @@ func WrapHandler(handler Handler) StreamHandler {
 		if err := writeResponse(stream, &resp); err != nil {
 			return err
 		}
+		if hErr == nil {
+			writeDeprecation(ctx, stream)
+		}
 		return hErr
 	}
 }
@@ -29,7 +29,7 @@ func (hw *hostWrapper) PeerInfo() peerinfo.PeerInfo {
 	return hw.pi
 }
 
-func wrapHost(t *testing.T, h host.Host) Host {
+func wrapHost(t testing.TB, h host.Host) Host {
 	pt := peerinfo.NewPeerInfoTracker()
 	pt.Start(h.Network())
 	t.Cleanup(pt.Stop)
@@ -280,3 +280,36 @@ func FuzzResponseConsistency(f *testing.F) {
 func FuzzResponseSafety(f *testing.F) {
 	tester.FuzzSafety[Response](f)
 }
+
+func TestServer_PeerFromContext(t *testing.T) {
+	mesh, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	handler := func(ctx context.Context, _ []byte) ([]byte, error) {
+		pid, ok := PeerFromContext(ctx)
+		if !ok {
+			return nil, errors.New("no peer in context")
+		}
+		return []byte(pid), nil
+	}
+	client := New(wrapHost(t, mesh.Hosts()[0]), "test", WrapHandler(handler))
+	srv := New(wrapHost(t, mesh.Hosts()[1]), "test", WrapHandler(handler))
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error {
+		return srv.Run(ctx)
+	})
+	t.Cleanup(func() {
+		cancel()
+		eg.Wait()
+	})
+	require.Eventually(t, func() bool {
+		return len(mesh.Hosts()[1].Mux().Protocols()) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	response, err := client.Request(ctx, mesh.Hosts()[1].ID(), []byte("test"))
+	require.NoError(t, err)
+	require.Equal(t, []byte(mesh.Hosts()[0].ID()), response)
+
+	_, ok := PeerFromContext(context.Background())
+	require.False(t, ok)
+}
@@ -311,11 +311,16 @@ func (fh *Host) ConnectedPeerInfo(id peer.ID) *PeerInfo {
 	if _, ok := fh.bootnode[id]; ok {
 		tags = append(tags, "bootnode")
 	}
+	protoStats := make(map[protocol.ID]PeerRequestStats)
+	for proto, stats := range pi.AllProtoStats() {
+		protoStats[proto] = grabPeerConnStats(stats)
+	}
 	return &PeerInfo{
-		ID:          id,
-		Connections: connections,
-		ClientStats: grabPeerConnStats(&pi.ClientStats),
-		ServerStats: grabPeerConnStats(&pi.ServerStats),
+		ID:            id,
+		Connections:   connections,
+		ClientStats:   grabPeerConnStats(&pi.ClientStats),
+		ServerStats:   grabPeerConnStats(&pi.ServerStats),
+		ProtocolStats: protoStats,
 		DataStats: DataStats{
 			BytesSent:     pi.BytesSent(),
 			BytesReceived: pi.BytesReceived(),
@@ -328,7 +333,10 @@ func (fh *Host) ConnectedPeerInfo(id peer.ID) *PeerInfo {
 				pi.RecvRate(2),
 			},
 		},
-		Tags: tags,
+		EchoRTT:          pi.Echo.RTT(),
+		ClockOffset:      pi.Echo.ClockOffset(),
+		RequestAnomalies: pi.RequestAnomalies.Load(),
+		Tags:             tags,
 	}
 }
 
@@ -512,3 +520,9 @@ func (fh *Host) trackNetEvents() error {
 func (fh *Host) PeerInfo() peerinfo.PeerInfo {
 	return fh.peerInfo
 }
+
+// Capabilities returns the connected peers that have successfully served at
+// least one request, grouped by protocol.
+func (fh *Host) Capabilities() map[protocol.ID][]Peer {
+	return fh.peerInfo.Capabilities()
+}
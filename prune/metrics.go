@@ -20,4 +20,18 @@ var (
 	certLatency      = pruneLatency.WithLabelValues("cert")
 	propTxLatency    = pruneLatency.WithLabelValues("proptxs")
 	activeSetLatency = pruneLatency.WithLabelValues("activeset")
+	poetLatency      = pruneLatency.WithLabelValues("poets")
+
+	poetProofsStored = metrics.NewGauge(
+		"poet_proofs",
+		namespace,
+		"number of stored poet proofs",
+		[]string{},
+	).WithLabelValues()
+	poetProofsPruned = metrics.NewCounter(
+		"poet_proofs_pruned",
+		namespace,
+		"number of pruned poet proofs that were not referenced by any atx",
+		[]string{},
+	).WithLabelValues()
 )
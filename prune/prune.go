@@ -10,10 +10,20 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/activesets"
 	"github.com/spacemeshos/go-spacemesh/sql/certificates"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
 	"github.com/spacemeshos/go-spacemesh/sql/transactions"
 	"github.com/spacemeshos/go-spacemesh/timesync"
 )
 
+// minPoetRetention is the minimal number of epochs for which PoET proofs are retained.
+// A proof is referenced by ATXs published in the epoch after it was received, and nodes
+// that recover from a checkpoint fetch the proofs of ATXs from the last epochs before it.
+const minPoetRetention = 2
+
+type layerClock interface {
+	LayerToTime(types.LayerID) time.Time
+}
+
 type Opt func(*Pruner)
 
 func WithLogger(logger *zap.Logger) Opt {
@@ -22,6 +32,18 @@ func WithLogger(logger *zap.Logger) Opt {
 	}
 }
 
+// WithPoetRetention prunes PoET proofs received more than the given number of epochs ago
+// that are not referenced by any stored ATX. Zero keeps all proofs.
+func WithPoetRetention(epochs uint32, clock layerClock) Opt {
+	return func(p *Pruner) {
+		if epochs > 0 {
+			epochs = max(epochs, minPoetRetention)
+		}
+		p.poetRetention = epochs
+		p.clock = clock
+	}
+}
+
 func New(db sql.StateDatabase, safeDist uint32, activesetEpoch types.EpochID, opts ...Opt) *Pruner {
 	p := &Pruner{
 		logger:         zap.NewNop(),
@@ -40,12 +62,15 @@ type Pruner struct {
 	db             sql.StateDatabase
 	safeDist       uint32
 	activesetEpoch types.EpochID
+	poetRetention  uint32
+	clock          layerClock
 }
 
 func Run(ctx context.Context, p *Pruner, clock *timesync.NodeClock, interval time.Duration) {
 	p.logger.With().Info("db pruning launched",
 		zap.Uint32("dist", p.safeDist),
 		zap.Uint32("active set epoch", p.activesetEpoch.Uint32()),
+		zap.Uint32("poet retention", p.poetRetention),
 		zap.Duration("interval", interval),
 	)
 	for {
@@ -94,5 +119,20 @@ func (p *Pruner) Prune(current types.LayerID) error {
 		}
 		activeSetLatency.Observe(time.Since(start).Seconds())
 	}
+	if p.poetRetention > 0 && current.GetEpoch() > types.EpochID(p.poetRetention) {
+		start = time.Now()
+		oldest := (current.GetEpoch() - types.EpochID(p.poetRetention)).FirstLayer()
+		pruned, err := poets.DeleteUnreferencedBefore(p.db, p.clock.LayerToTime(oldest))
+		if err != nil {
+			return err
+		}
+		poetLatency.Observe(time.Since(start).Seconds())
+		poetProofsPruned.Add(float64(pruned))
+		stored, err := poets.Count(p.db)
+		if err != nil {
+			return err
+		}
+		poetProofsStored.Set(float64(stored))
+	}
 	return nil
 }
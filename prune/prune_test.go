@@ -2,6 +2,7 @@ package prune
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -11,6 +12,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql/activesets"
 	"github.com/spacemeshos/go-spacemesh/sql/ballots"
 	"github.com/spacemeshos/go-spacemesh/sql/certificates"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 	"github.com/spacemeshos/go-spacemesh/sql/transactions"
 )
@@ -85,3 +87,36 @@ func TestPrune(t *testing.T) {
 		}
 	}
 }
+
+type staticClock time.Time
+
+func (c staticClock) LayerToTime(types.LayerID) time.Time {
+	return time.Time(c)
+}
+
+func TestPrunePoets(t *testing.T) {
+	types.SetLayersPerEpoch(3)
+
+	db := statesql.InMemory()
+	referenced := types.PoetProofRef{1}
+	unreferenced := types.PoetProofRef{2}
+	require.NoError(t, poets.Add(db, referenced, []byte("proof"), []byte("sid"), "1"))
+	require.NoError(t, poets.Add(db, unreferenced, []byte("proof"), []byte("sid"), "2"))
+	require.NoError(t, poets.AddReference(db, referenced, types.RandomATXID()))
+
+	// the retention is raised to minPoetRetention, proofs are kept until then
+	clock := staticClock(time.Now().Add(time.Hour))
+	pruner := New(db, 3, 0, WithLogger(zaptest.NewLogger(t)), WithPoetRetention(1, clock))
+	require.NoError(t, pruner.Prune(types.EpochID(minPoetRetention).FirstLayer()))
+	count, err := poets.Count(db)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	require.NoError(t, pruner.Prune(types.EpochID(minPoetRetention+1).FirstLayer()))
+	exists, err := poets.Has(db, referenced)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = poets.Has(db, unreferenced)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
@@ -25,6 +25,8 @@ const (
 	HARE     = 3
 	POET     = 4
 	MARRIAGE = 5
+	// ATTESTATION is used for off-chain statements about the state of the identity.
+	ATTESTATION = 6
 
 	BEACON_FIRST_MSG    = 10
 	BEACON_FOLLOWUP_MSG = 11
@@ -43,6 +45,8 @@ func (d Domain) String() string {
 		return "HARE"
 	case POET:
 		return "POET"
+	case ATTESTATION:
+		return "ATTESTATION"
 	case BEACON_FIRST_MSG:
 		return "BEACON_FIRST_MSG"
 	case BEACON_FOLLOWUP_MSG:
@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+)
+
+// Backup writes a point-in-time copy of the database into a new file at path, using
+// the SQLite online backup API. The copy is made in a single step, so it is consistent
+// even if the database is written to concurrently.
+//
+// https://www.sqlite.org/backup.html
+func (db *sqliteDatabase) Backup(ctx context.Context, path string) error {
+	if db.closed {
+		return ErrClosed
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup %s: %w", path, os.ErrExist)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("backup %s: %w", path, err)
+	}
+	conn := db.getConn(ctx)
+	if conn == nil {
+		return ErrNoConnection
+	}
+	defer db.pool.Put(conn)
+	dst, err := conn.BackupToDB("", path)
+	if err != nil {
+		return fmt.Errorf("backup %s: %w", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("close backup %s: %w", path, err)
+	}
+	return nil
+}
+
+// ErrIntegrity is returned by CheckIntegrity if the database is corrupted.
+var ErrIntegrity = errors.New("database integrity check failed")
+
+// CheckIntegrity opens the database at path read-only and runs the full SQLite integrity check
+// on it. The error wraps ErrIntegrity if the check reports problems or the file is malformed.
+//
+// https://www.sqlite.org/pragma.html#pragma_integrity_check
+func CheckIntegrity(path string) error {
+	pool, err := sqlitex.Open(path, sqlite.SQLITE_OPEN_READONLY, 1)
+	if err != nil {
+		return fmt.Errorf("open db %s: %w", path, err)
+	}
+	db := &sqliteDatabase{pool: pool}
+	defer db.Close()
+	var problems []string
+	if _, err := db.Exec("PRAGMA integrity_check", nil, func(stmt *Statement) bool {
+		if msg := stmt.ColumnText(0); msg != "ok" {
+			problems = append(problems, msg)
+		}
+		return true
+	}); err != nil {
+		if sqlite.ErrCode(err) == sqlite.SQLITE_CORRUPT {
+			return fmt.Errorf("%w: %s: %w", ErrIntegrity, path, err)
+		}
+		return fmt.Errorf("integrity check %s: %w", path, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s: %s (%d problems)", ErrIntegrity, path, problems[0], len(problems))
+	}
+	return nil
+}
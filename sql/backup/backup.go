@@ -0,0 +1,221 @@
+// Package backup periodically writes verified online copies of the node databases and
+// rotates them, so that an operator can restore a node after the database got corrupted.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+const (
+	backupsDir = "backups"
+	fileExt    = ".sql"
+	tmpExt     = ".tmp"
+	timeFormat = "20060102T150405.000Z"
+)
+
+// Config configures scheduled backups of the databases.
+type Config struct {
+	// Interval between backups. Zero disables backups.
+	Interval time.Duration `mapstructure:"interval"`
+	// Retention is the number of verified backups kept for every database, the oldest ones
+	// are deleted after a new backup is made.
+	Retention int `mapstructure:"retention"`
+	// Dir is the directory for the backups. If empty backups are stored in the "backups"
+	// directory in the data directory of the node.
+	Dir string `mapstructure:"dir"`
+}
+
+// DefaultConfig returns the default backup config, backups are disabled by default.
+func DefaultConfig() Config {
+	return Config{
+		Interval:  0,
+		Retention: 3,
+	}
+}
+
+type Opt func(*Scheduler)
+
+func WithLogger(logger *zap.Logger) Opt {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// WithDatabase adds a database to back up. Backups of the database are stored in a
+// subdirectory with the given name.
+func WithDatabase(name string, db sql.Database) Opt {
+	return func(s *Scheduler) {
+		s.dbs = append(s.dbs, database{name: name, db: db})
+	}
+}
+
+type database struct {
+	name string
+	db   sql.Database
+}
+
+// Scheduler backs up the databases in the configured interval.
+type Scheduler struct {
+	logger *zap.Logger
+	cfg    Config
+	dir    string
+	dbs    []database
+}
+
+// New creates a scheduler that stores backups under dataDir, unless Config.Dir is set.
+func New(cfg Config, dataDir string, opts ...Opt) *Scheduler {
+	s := &Scheduler{
+		logger: zap.NewNop(),
+		cfg:    cfg,
+		dir:    cfg.Dir,
+	}
+	if s.dir == "" {
+		s.dir = filepath.Join(dataDir, backupsDir)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run backs up the databases every Config.Interval until the context is canceled.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.logger.Info("db backups launched",
+		zap.String("dir", s.dir),
+		zap.Duration("interval", s.cfg.Interval),
+		zap.Int("retention", s.cfg.Retention),
+	)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// failures are logged and counted by Backup, the next attempt is made in the next interval
+			_ = s.Backup(ctx, time.Now())
+		}
+	}
+}
+
+// Backup backs up every database and deletes backups over the retention. A failure of one
+// database doesn't prevent backing up the others, the returned error joins all failures.
+func (s *Scheduler) Backup(ctx context.Context, now time.Time) error {
+	var errs []error
+	for _, db := range s.dbs {
+		if err := s.backup(ctx, db, now); err != nil {
+			failures.WithLabelValues(db.name).Inc()
+			s.logger.Error("db backup failed", zap.String("db", db.name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("backup %s: %w", db.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Path returns the path of the backup of the database created at the given time.
+func (s *Scheduler) Path(name string, created time.Time) string {
+	return filepath.Join(s.dir, name, created.UTC().Format(timeFormat)+fileExt)
+}
+
+// List returns the paths of the verified backups of the database, from the oldest.
+func (s *Scheduler) List(name string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var rst []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), fileExt) {
+			continue
+		}
+		rst = append(rst, filepath.Join(s.dir, name, entry.Name()))
+	}
+	// the timestamps in the names sort in the order of creation
+	slices.Sort(rst)
+	return rst, nil
+}
+
+func (s *Scheduler) backup(ctx context.Context, db database, now time.Time) error {
+	start := time.Now()
+	path := s.Path(db.name, now)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create backup dir: %w", err)
+	}
+	// the copy is verified under a temporary name, so that only verified backups
+	// have the name of a backup
+	tmp := path + tmpExt
+	if err := db.db.Backup(ctx, tmp); err != nil {
+		removeTmp(tmp)
+		return err
+	}
+	if err := sql.CheckIntegrity(tmp); err != nil {
+		removeTmp(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		removeTmp(tmp)
+		return fmt.Errorf("rename backup: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat backup: %w", err)
+	}
+	elapsed := time.Since(start)
+	lastSuccess.WithLabelValues(db.name).Set(float64(now.Unix()))
+	lastDuration.WithLabelValues(db.name).Set(elapsed.Seconds())
+	lastSize.WithLabelValues(db.name).Set(float64(info.Size()))
+	s.logger.Info("db backup created",
+		zap.String("db", db.name),
+		zap.String("path", path),
+		zap.Int64("size", info.Size()),
+		zap.Duration("duration", elapsed),
+	)
+	return s.rotate(db.name)
+}
+
+// rotate deletes the oldest backups over the retention and leftovers of interrupted backups.
+func (s *Scheduler) rotate(name string) error {
+	backups, err := s.List(name)
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+	if s.cfg.Retention > 0 && len(backups) > s.cfg.Retention {
+		for _, path := range backups[:len(backups)-s.cfg.Retention] {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("delete backup: %w", err)
+			}
+			s.logger.Debug("deleted db backup over retention", zap.String("db", name), zap.String("path", path))
+		}
+		backups = backups[len(backups)-s.cfg.Retention:]
+	}
+	retained.WithLabelValues(name).Set(float64(len(backups)))
+
+	tmps, err := filepath.Glob(filepath.Join(s.dir, name, "*"+fileExt+tmpExt))
+	if err != nil {
+		return err
+	}
+	for _, tmp := range tmps {
+		removeTmp(tmp)
+	}
+	return nil
+}
+
+// removeTmp removes an unverified copy together with the files sqlite may have left next to it.
+func removeTmp(path string) {
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		os.Remove(path + suffix)
+	}
+}
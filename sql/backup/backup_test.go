@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestScheduler_Backup(t *testing.T) {
+	state := statesql.InMemory()
+	t.Cleanup(func() { state.Close() })
+	require.NoError(t, layers.SetProcessed(state, 10))
+	local := localsql.InMemory()
+	t.Cleanup(func() { local.Close() })
+
+	dataDir := t.TempDir()
+	s := New(Config{Interval: time.Hour, Retention: 2}, dataDir,
+		WithLogger(zaptest.NewLogger(t)),
+		WithDatabase("state", state),
+		WithDatabase("local", local),
+	)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		require.NoError(t, s.Backup(context.Background(), start.Add(time.Duration(i)*time.Hour)))
+	}
+
+	for _, name := range []string{"state", "local"} {
+		backups, err := s.List(name)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			s.Path(name, start.Add(time.Hour)),
+			s.Path(name, start.Add(2*time.Hour)),
+		}, backups)
+		require.Equal(t, filepath.Join(dataDir, backupsDir, name), filepath.Dir(backups[0]))
+		require.Equal(t, float64(2), testutil.ToFloat64(retained.WithLabelValues(name)))
+		require.Equal(t, float64(start.Add(2*time.Hour).Unix()), testutil.ToFloat64(lastSuccess.WithLabelValues(name)))
+		require.NotZero(t, testutil.ToFloat64(lastSize.WithLabelValues(name)))
+	}
+
+	backups, err := s.List("state")
+	require.NoError(t, err)
+	db, err := statesql.Open("file:"+backups[1], sql.WithMigrationsDisabled(), sql.WithNoCheckSchemaDrift())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	processed, err := layers.GetProcessed(db)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, processed)
+}
+
+func TestScheduler_Failure(t *testing.T) {
+	state := statesql.InMemory()
+	t.Cleanup(func() { state.Close() })
+	local := localsql.InMemory()
+	require.NoError(t, local.Close())
+
+	s := New(Config{Interval: time.Hour, Retention: 2, Dir: t.TempDir()}, "",
+		WithLogger(zaptest.NewLogger(t)),
+		WithDatabase("local", local),
+		WithDatabase("state", state),
+	)
+	before := testutil.ToFloat64(failures.WithLabelValues("local"))
+	now := time.Now()
+	require.ErrorIs(t, s.Backup(context.Background(), now), sql.ErrClosed)
+	require.Equal(t, before+1, testutil.ToFloat64(failures.WithLabelValues("local")))
+
+	// the failure of one database doesn't prevent backups of the others
+	backups, err := s.List("state")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backups, err = s.List("local")
+	require.NoError(t, err)
+	require.Empty(t, backups)
+}
+
+func TestScheduler_RemovesUnverified(t *testing.T) {
+	state := statesql.InMemory()
+	t.Cleanup(func() { state.Close() })
+	s := New(Config{Interval: time.Hour, Retention: 2}, t.TempDir(), WithDatabase("state", state))
+
+	stale := s.Path("state", time.Now().Add(-time.Hour)) + tmpExt
+	require.NoError(t, os.MkdirAll(filepath.Dir(stale), 0o700))
+	require.NoError(t, os.WriteFile(stale, []byte("partial"), 0o600))
+	require.NoError(t, s.Backup(context.Background(), time.Now()))
+	require.NoFileExists(t, stale)
+	backups, err := s.List("state")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+}
@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"github.com/spacemeshos/go-spacemesh/metrics"
+)
+
+const namespace = "db_backup"
+
+var (
+	lastSuccess = metrics.NewGauge(
+		"last_success_timestamp_seconds",
+		namespace,
+		"unix time of the last verified backup",
+		[]string{"db"},
+	)
+	lastDuration = metrics.NewGauge(
+		"last_duration_seconds",
+		namespace,
+		"time to create and verify the last backup",
+		[]string{"db"},
+	)
+	lastSize = metrics.NewGauge(
+		"last_size_bytes",
+		namespace,
+		"size of the last verified backup",
+		[]string{"db"},
+	)
+	retained = metrics.NewGauge(
+		"retained",
+		namespace,
+		"number of retained backups",
+		[]string{"db"},
+	)
+	failures = metrics.NewCounter(
+		"failures",
+		namespace,
+		"number of failed backups",
+		[]string{"db"},
+	)
+)
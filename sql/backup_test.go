@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup(t *testing.T) {
+	db := InMemory(
+		WithDatabaseSchema(&Schema{
+			Script: "PRAGMA user_version = 3;\ncreate table testing1 (id int primary key);",
+		}),
+		WithNoCheckSchemaDrift(),
+	)
+	t.Cleanup(func() { db.Close() })
+	_, err := db.Exec("insert into testing1 (id) values (1), (2)", nil, nil)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "backup.sql")
+	require.NoError(t, db.Backup(context.Background(), path))
+	require.ErrorIs(t, db.Backup(context.Background(), path), os.ErrExist)
+
+	version, err := Version(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+
+	backup, err := Open("file:"+path, WithNoCheckSchemaDrift())
+	require.NoError(t, err)
+	t.Cleanup(func() { backup.Close() })
+	rows, err := backup.Exec("select id from testing1", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows)
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	db := InMemory(
+		WithDatabaseSchema(&Schema{
+			Script: "create table testing1 (id int primary key, value blob);",
+		}),
+		WithNoCheckSchemaDrift(),
+	)
+	t.Cleanup(func() { db.Close() })
+	for i := range 100 {
+		_, err := db.Exec("insert into testing1 (id, value) values (?1, zeroblob(100))", func(stmt *Statement) {
+			stmt.BindInt64(1, int64(i))
+		}, nil)
+		require.NoError(t, err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.sql")
+	require.NoError(t, db.Backup(context.Background(), path))
+	require.NoError(t, CheckIntegrity(path))
+
+	// corrupt the pages of the table, leaving the header and the schema page intact
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	pageSize := 4096
+	require.Greater(t, len(data), 3*pageSize)
+	for i := 2 * pageSize; i < len(data); i++ {
+		data[i] = 0xff
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.ErrorIs(t, CheckIntegrity(path), ErrIntegrity)
+}
@@ -563,6 +563,7 @@ type Database interface {
 	WithTxImmediate(ctx context.Context, exec func(Transaction) error) error
 	Intercept(key string, fn Interceptor)
 	RemoveInterceptor(key string)
+	Backup(ctx context.Context, path string) error
 }
 
 // Transaction represents a transaction.
@@ -2,67 +2,197 @@ package certifier
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
 )
 
+// AnyPoet is the poet address under which certificates usable with every poet are stored.
+const AnyPoet = ""
+
 type PoetCert struct {
 	Data      []byte
 	Signature []byte
+	// Expiration is nil if the certificate doesn't expire.
+	Expiration *time.Time
+	// Issued is when the certificate was obtained, zero if unknown.
+	Issued time.Time
+	// Certifier is the address of the certifier that issued the certificate, empty if unknown.
+	Certifier string
+}
+
+// StoredCertificate is a certificate with the key it is stored under.
+type StoredCertificate struct {
+	NodeID      types.NodeID
+	Poet        string
+	CertifierID []byte
+	PoetCert
 }
 
-func AddCertificate(db sql.Executor, nodeID types.NodeID, cert PoetCert, cerifierID []byte) error {
+func AddCertificate(db sql.Executor, nodeID types.NodeID, cert PoetCert, poet string, cerifierID []byte) error {
 	enc := func(stmt *sql.Statement) {
 		stmt.BindBytes(1, nodeID.Bytes())
-		stmt.BindBytes(2, cerifierID)
-		stmt.BindBytes(3, cert.Data)
-		stmt.BindBytes(4, cert.Signature)
+		stmt.BindText(2, poet)
+		stmt.BindBytes(3, cerifierID)
+		stmt.BindBytes(4, cert.Data)
+		stmt.BindBytes(5, cert.Signature)
+		if cert.Expiration != nil {
+			stmt.BindInt64(6, cert.Expiration.UnixNano())
+		} else {
+			stmt.BindNull(6)
+		}
+		if !cert.Issued.IsZero() {
+			stmt.BindInt64(7, cert.Issued.UnixNano())
+		} else {
+			stmt.BindNull(7)
+		}
+		if cert.Certifier != "" {
+			stmt.BindText(8, cert.Certifier)
+		} else {
+			stmt.BindNull(8)
+		}
 	}
 	if _, err := db.Exec(`
-		REPLACE INTO poet_certificates (node_id, certifier_id, certificate, signature)
-		VALUES (?1, ?2, ?3, ?4);`, enc, nil,
+		REPLACE INTO poet_certificates
+			(node_id, poet, certifier_id, certificate, signature, expiration, issued, certifier_url)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8);`, enc, nil,
 	); err != nil {
-		return fmt.Errorf("storing poet certificate for (%s; %x): %w", nodeID.ShortString(), cerifierID, err)
+		return fmt.Errorf("storing poet certificate for (%s; %s; %x): %w",
+			nodeID.ShortString(), poet, cerifierID, err)
 	}
 	return nil
 }
 
-func DeleteCertificate(db sql.Executor, nodeID types.NodeID, certifierID []byte) error {
+func DeleteCertificate(db sql.Executor, nodeID types.NodeID, poet string, certifierID []byte) error {
 	enc := func(stmt *sql.Statement) {
 		stmt.BindBytes(1, nodeID.Bytes())
-		stmt.BindBytes(2, certifierID)
+		stmt.BindText(2, poet)
+		stmt.BindBytes(3, certifierID)
 	}
 	if _, err := db.Exec(`
-		DELETE FROM poet_certificates WHERE node_id = ?1 AND certifier_id = ?2;`, enc, nil,
+		DELETE FROM poet_certificates WHERE node_id = ?1 AND poet = ?2 AND certifier_id = ?3;`, enc, nil,
 	); err != nil {
-		return fmt.Errorf("deleting poet certificate for (%s; %x): %w", nodeID.ShortString(), certifierID, err)
+		return fmt.Errorf("deleting poet certificate for (%s; %s; %x): %w",
+			nodeID.ShortString(), poet, certifierID, err)
 	}
 	return nil
 }
 
-func Certificate(db sql.Executor, nodeID types.NodeID, certifierID []byte) (*PoetCert, error) {
+func Certificate(db sql.Executor, nodeID types.NodeID, poet string, certifierID []byte) (*PoetCert, error) {
 	enc := func(stmt *sql.Statement) {
 		stmt.BindBytes(1, nodeID.Bytes())
-		stmt.BindBytes(2, certifierID)
+		stmt.BindText(2, poet)
+		stmt.BindBytes(3, certifierID)
 	}
 	var cert PoetCert
 	dec := func(stmt *sql.Statement) bool {
-		cert.Data = make([]byte, stmt.ColumnLen(0))
-		cert.Signature = make([]byte, stmt.ColumnLen(1))
-		stmt.ColumnBytes(0, cert.Data)
-		stmt.ColumnBytes(1, cert.Signature)
+		cert = decodeCert(stmt, 0)
 		return true
 	}
 	rows, err := db.Exec(`
-		select certificate, signature
-		from poet_certificates where node_id = ?1 and certifier_id = ?2 limit 1;`, enc, dec,
+		select certificate, signature, expiration, issued, certifier_url
+		from poet_certificates where node_id = ?1 and poet = ?2 and certifier_id = ?3 limit 1;`, enc, dec,
 	)
 	switch {
 	case err != nil:
-		return nil, fmt.Errorf("getting poet certificate for (%s; %s): %w", nodeID.ShortString(), certifierID, err)
+		return nil, fmt.Errorf("getting poet certificate for (%s; %s; %x): %w",
+			nodeID.ShortString(), poet, certifierID, err)
 	case rows == 0:
 		return nil, sql.ErrNotFound
 	}
 	return &cert, nil
 }
+
+// decodeCert decodes the certificate, signature, expiration, issued and certifier_url columns
+// starting at column col.
+func decodeCert(stmt *sql.Statement, col int) PoetCert {
+	var cert PoetCert
+	cert.Data = make([]byte, stmt.ColumnLen(col))
+	cert.Signature = make([]byte, stmt.ColumnLen(col+1))
+	stmt.ColumnBytes(col, cert.Data)
+	stmt.ColumnBytes(col+1, cert.Signature)
+	if !sql.IsNull(stmt, col+2) {
+		expiration := time.Unix(0, stmt.ColumnInt64(col+2))
+		cert.Expiration = &expiration
+	}
+	if !sql.IsNull(stmt, col+3) {
+		cert.Issued = time.Unix(0, stmt.ColumnInt64(col+3))
+	}
+	cert.Certifier = stmt.ColumnText(col + 4)
+	return cert
+}
+
+// Certificates returns the certificates of all identities.
+func Certificates(db sql.Executor) ([]StoredCertificate, error) {
+	var certs []StoredCertificate
+	if _, err := db.Exec(`
+		select node_id, poet, certifier_id, certificate, signature, expiration, issued, certifier_url
+		from poet_certificates;`, nil, decodeStored(&certs),
+	); err != nil {
+		return nil, fmt.Errorf("getting poet certificates: %w", err)
+	}
+	return certs, nil
+}
+
+// ExpiringCertificates returns the certificates of all identities that expire before the time,
+// including the ones that expired already.
+func ExpiringCertificates(db sql.Executor, before time.Time) ([]StoredCertificate, error) {
+	var certs []StoredCertificate
+	enc := func(stmt *sql.Statement) {
+		stmt.BindInt64(1, before.UnixNano())
+	}
+	if _, err := db.Exec(`
+		select node_id, poet, certifier_id, certificate, signature, expiration, issued, certifier_url
+		from poet_certificates where expiration < ?1;`, enc, decodeStored(&certs),
+	); err != nil {
+		return nil, fmt.Errorf("getting poet certificates expiring before %s: %w", before, err)
+	}
+	return certs, nil
+}
+
+func decodeStored(certs *[]StoredCertificate) func(stmt *sql.Statement) bool {
+	return func(stmt *sql.Statement) bool {
+		stored := StoredCertificate{
+			Poet:        stmt.ColumnText(1),
+			CertifierID: make([]byte, stmt.ColumnLen(2)),
+			PoetCert:    decodeCert(stmt, 3),
+		}
+		stmt.ColumnBytes(0, stored.NodeID[:])
+		stmt.ColumnBytes(2, stored.CertifierID)
+		*certs = append(*certs, stored)
+		return true
+	}
+}
+
+// CountCertificates returns the number of certificates of all identities by poet address.
+// Certificates stored for AnyPoet are not counted.
+func CountCertificates(db sql.Executor) (map[string]int, error) {
+	counts := make(map[string]int)
+	enc := func(stmt *sql.Statement) {
+		stmt.BindText(1, AnyPoet)
+	}
+	dec := func(stmt *sql.Statement) bool {
+		counts[stmt.ColumnText(0)] = int(stmt.ColumnInt64(1))
+		return true
+	}
+	if _, err := db.Exec(`
+		select poet, count(*) from poet_certificates where poet != ?1 group by poet;`, enc, dec,
+	); err != nil {
+		return nil, fmt.Errorf("count poet certificates: %w", err)
+	}
+	return counts, nil
+}
+
+// DeleteCertificatesByPoet deletes the certificates of all identities for the poet
+// and returns the number of deleted certificates.
+func DeleteCertificatesByPoet(db sql.Executor, poet string) (int, error) {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindText(1, poet)
+	}
+	rows, err := db.Exec(`delete from poet_certificates where poet = ?1 returning node_id;`, enc, nil)
+	if err != nil {
+		return 0, fmt.Errorf("deleting poet certificates for %s: %w", poet, err)
+	}
+	return rows, nil
+}
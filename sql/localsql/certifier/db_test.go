@@ -2,10 +2,12 @@ package certifier_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/localsql"
 	"github.com/spacemeshos/go-spacemesh/sql/localsql/certifier"
 )
@@ -16,18 +18,18 @@ func TestAddingCertificates(t *testing.T) {
 
 	expCert := certifier.PoetCert{Data: []byte("data"), Signature: []byte("sig")}
 
-	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert, []byte("certifier-0")))
-	cert, err := certifier.Certificate(db, nodeId, []byte("certifier-0"))
+	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert, certifier.AnyPoet, []byte("certifier-0")))
+	cert, err := certifier.Certificate(db, nodeId, certifier.AnyPoet, []byte("certifier-0"))
 	require.NoError(t, err)
 	require.Equal(t, &expCert, cert)
 
 	expCert2 := certifier.PoetCert{Data: []byte("data2"), Signature: []byte("sig2")}
-	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert2, []byte("certifier-1")))
+	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert2, certifier.AnyPoet, []byte("certifier-1")))
 
-	cert, err = certifier.Certificate(db, nodeId, []byte("certifier-1"))
+	cert, err = certifier.Certificate(db, nodeId, certifier.AnyPoet, []byte("certifier-1"))
 	require.NoError(t, err)
 	require.Equal(t, &expCert2, cert)
-	cert, err = certifier.Certificate(db, nodeId, []byte("certifier-0"))
+	cert, err = certifier.Certificate(db, nodeId, certifier.AnyPoet, []byte("certifier-0"))
 	require.NoError(t, err)
 	require.Equal(t, &expCert, cert)
 }
@@ -37,14 +39,101 @@ func TestOverwritingCertificates(t *testing.T) {
 	nodeId := types.RandomNodeID()
 
 	expCert := certifier.PoetCert{Data: []byte("data"), Signature: []byte("sig")}
-	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert, []byte("certifier-0")))
-	cert, err := certifier.Certificate(db, nodeId, []byte("certifier-0"))
+	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert, certifier.AnyPoet, []byte("certifier-0")))
+	cert, err := certifier.Certificate(db, nodeId, certifier.AnyPoet, []byte("certifier-0"))
 	require.NoError(t, err)
 	require.Equal(t, &expCert, cert)
 
 	expCert2 := certifier.PoetCert{Data: []byte("data2"), Signature: []byte("sig2")}
-	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert2, []byte("certifier-0")))
-	cert, err = certifier.Certificate(db, nodeId, []byte("certifier-0"))
+	require.NoError(t, certifier.AddCertificate(db, nodeId, expCert2, certifier.AnyPoet, []byte("certifier-0")))
+	cert, err = certifier.Certificate(db, nodeId, certifier.AnyPoet, []byte("certifier-0"))
 	require.NoError(t, err)
 	require.Equal(t, &expCert2, cert)
 }
+
+func TestCertificatesPerPoet(t *testing.T) {
+	db := localsql.InMemory()
+	nodeId := types.RandomNodeID()
+	certifierID := []byte("certifier-0")
+	expiration := time.Unix(0, time.Now().Add(time.Hour).UnixNano())
+
+	global := certifier.PoetCert{Data: []byte("data"), Signature: []byte("sig")}
+	perPoet := certifier.PoetCert{Data: []byte("data2"), Signature: []byte("sig2"), Expiration: &expiration}
+	require.NoError(t, certifier.AddCertificate(db, nodeId, global, certifier.AnyPoet, certifierID))
+	require.NoError(t, certifier.AddCertificate(db, nodeId, perPoet, "https://poet-1", certifierID))
+
+	cert, err := certifier.Certificate(db, nodeId, "https://poet-1", certifierID)
+	require.NoError(t, err)
+	require.Equal(t, &perPoet, cert)
+	cert, err = certifier.Certificate(db, nodeId, certifier.AnyPoet, certifierID)
+	require.NoError(t, err)
+	require.Equal(t, &global, cert)
+	_, err = certifier.Certificate(db, nodeId, "https://poet-2", certifierID)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	require.NoError(t, certifier.DeleteCertificate(db, nodeId, "https://poet-1", certifierID))
+	_, err = certifier.Certificate(db, nodeId, "https://poet-1", certifierID)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+	cert, err = certifier.Certificate(db, nodeId, certifier.AnyPoet, certifierID)
+	require.NoError(t, err)
+	require.Equal(t, &global, cert)
+}
+
+func TestDeleteCertificatesByPoet(t *testing.T) {
+	db := localsql.InMemory()
+	cert := certifier.PoetCert{Data: []byte("data"), Signature: []byte("sig")}
+	for _, nodeID := range []types.NodeID{types.RandomNodeID(), types.RandomNodeID()} {
+		for _, poet := range []string{certifier.AnyPoet, "poet1", "poet2"} {
+			require.NoError(t, certifier.AddCertificate(db, nodeID, cert, poet, []byte("certifier-0")))
+		}
+	}
+	counts, err := certifier.CountCertificates(db)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"poet1": 2, "poet2": 2}, counts)
+
+	deleted, err := certifier.DeleteCertificatesByPoet(db, "poet1")
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	counts, err = certifier.CountCertificates(db)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"poet2": 2}, counts)
+}
+
+func TestExpiringCertificates(t *testing.T) {
+	db := localsql.InMemory()
+	now := time.Unix(0, time.Now().UnixNano())
+	soon := now.Add(time.Minute)
+	later := now.Add(time.Hour)
+	nodeID := types.RandomNodeID()
+
+	expiring := certifier.PoetCert{
+		Data:       []byte("data"),
+		Signature:  []byte("sig"),
+		Expiration: &soon,
+		Issued:     now,
+		Certifier:  "http://certifier",
+	}
+	valid := certifier.PoetCert{Data: []byte("data2"), Signature: []byte("sig2"), Expiration: &later}
+	forever := certifier.PoetCert{Data: []byte("data3"), Signature: []byte("sig3")}
+	require.NoError(t, certifier.AddCertificate(db, nodeID, expiring, "poet1", []byte("certifier-0")))
+	require.NoError(t, certifier.AddCertificate(db, nodeID, valid, "poet2", []byte("certifier-0")))
+	require.NoError(t, certifier.AddCertificate(db, nodeID, forever, "poet3", []byte("certifier-0")))
+
+	cert, err := certifier.Certificate(db, nodeID, "poet1", []byte("certifier-0"))
+	require.NoError(t, err)
+	require.Equal(t, &expiring, cert)
+
+	certs, err := certifier.ExpiringCertificates(db, now.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []certifier.StoredCertificate{{
+		NodeID:      nodeID,
+		Poet:        "poet1",
+		CertifierID: []byte("certifier-0"),
+		PoetCert:    expiring,
+	}}, certs)
+
+	certs, err = certifier.Certificates(db)
+	require.NoError(t, err)
+	require.Len(t, certs, 3)
+}
@@ -0,0 +1,109 @@
+package haremsgs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// Message is an encoded hare message with the fields it is indexed by.
+type Message struct {
+	Layer    types.LayerID
+	Iter     uint8
+	Round    uint8
+	Sender   types.NodeID
+	ID       types.Hash32
+	Received time.Time
+	Blob     []byte
+}
+
+// Filter selects archived messages of a layer. Nil fields match any value.
+type Filter struct {
+	Layer  types.LayerID
+	Iter   *uint8
+	Round  *uint8
+	Sender *types.NodeID
+}
+
+// Add archives a message. Adding the same message again is a no-op.
+func Add(db sql.Executor, msg *Message) error {
+	if _, err := db.Exec(`
+		insert into hare_messages (layer, iter, round, sender, id, received, msg)
+		values (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+		on conflict do nothing;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(msg.Layer))
+			stmt.BindInt64(2, int64(msg.Iter))
+			stmt.BindInt64(3, int64(msg.Round))
+			stmt.BindBytes(4, msg.Sender.Bytes())
+			stmt.BindBytes(5, msg.ID.Bytes())
+			stmt.BindInt64(6, msg.Received.UnixNano())
+			stmt.BindBytes(7, msg.Blob)
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("add hare message %s in layer %d: %w", msg.ID.ShortString(), msg.Layer, err)
+	}
+	return nil
+}
+
+// Get returns archived messages matching the filter, ordered by iteration, round and sender.
+func Get(db sql.Executor, filter Filter) ([]*Message, error) {
+	var rst []*Message
+	if _, err := db.Exec(`
+		select layer, iter, round, sender, id, received, msg from hare_messages
+		where layer = ?1
+		and (?2 is null or iter = ?2)
+		and (?3 is null or round = ?3)
+		and (?4 is null or sender = ?4)
+		order by iter, round, sender, id;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(filter.Layer))
+			if filter.Iter != nil {
+				stmt.BindInt64(2, int64(*filter.Iter))
+			} else {
+				stmt.BindNull(2)
+			}
+			if filter.Round != nil {
+				stmt.BindInt64(3, int64(*filter.Round))
+			} else {
+				stmt.BindNull(3)
+			}
+			if filter.Sender != nil {
+				stmt.BindBytes(4, filter.Sender.Bytes())
+			} else {
+				stmt.BindNull(4)
+			}
+		},
+		func(stmt *sql.Statement) bool {
+			msg := &Message{
+				Layer:    types.LayerID(stmt.ColumnInt64(0)),
+				Iter:     uint8(stmt.ColumnInt64(1)),
+				Round:    uint8(stmt.ColumnInt64(2)),
+				Received: time.Unix(0, stmt.ColumnInt64(5)),
+				Blob:     make([]byte, stmt.ColumnLen(6)),
+			}
+			stmt.ColumnBytes(3, msg.Sender[:])
+			stmt.ColumnBytes(4, msg.ID[:])
+			stmt.ColumnBytes(6, msg.Blob)
+			rst = append(rst, msg)
+			return true
+		},
+	); err != nil {
+		return nil, fmt.Errorf("get hare messages in layer %d: %w", filter.Layer, err)
+	}
+	return rst, nil
+}
+
+// DeleteBefore deletes messages from layers before the given one.
+func DeleteBefore(db sql.Executor, lid types.LayerID) error {
+	if _, err := db.Exec(`delete from hare_messages where layer < ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(lid))
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("delete hare messages before layer %d: %w", lid, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package haremsgs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+)
+
+func TestAddGet(t *testing.T) {
+	db := localsql.InMemoryTest(t)
+	now := time.Unix(0, time.Now().UnixNano())
+	msgs := []*Message{
+		{Layer: 10, Iter: 0, Round: 1, Sender: types.NodeID{1}, ID: types.Hash32{1}, Received: now, Blob: []byte{1}},
+		{Layer: 10, Iter: 0, Round: 1, Sender: types.NodeID{2}, ID: types.Hash32{2}, Received: now, Blob: []byte{2}},
+		{Layer: 10, Iter: 0, Round: 2, Sender: types.NodeID{1}, ID: types.Hash32{3}, Received: now, Blob: []byte{3}},
+		{Layer: 10, Iter: 1, Round: 1, Sender: types.NodeID{1}, ID: types.Hash32{4}, Received: now, Blob: []byte{4}},
+		{Layer: 11, Iter: 0, Round: 1, Sender: types.NodeID{1}, ID: types.Hash32{5}, Received: now, Blob: []byte{5}},
+	}
+	for _, msg := range msgs {
+		require.NoError(t, Add(db, msg))
+	}
+	require.NoError(t, Add(db, msgs[0]))
+
+	iter, round, sender := uint8(0), uint8(1), types.NodeID{1}
+	for _, tc := range []struct {
+		desc   string
+		filter Filter
+		expect []*Message
+	}{
+		{"layer", Filter{Layer: 10}, msgs[:4]},
+		{"iter", Filter{Layer: 10, Iter: &iter}, msgs[:3]},
+		{"round", Filter{Layer: 10, Iter: &iter, Round: &round}, msgs[:2]},
+		{"sender", Filter{Layer: 10, Sender: &sender}, []*Message{msgs[0], msgs[2], msgs[3]}},
+		{"empty", Filter{Layer: 12}, nil},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := Get(db, tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestDeleteBefore(t *testing.T) {
+	db := localsql.InMemoryTest(t)
+	for lid := types.LayerID(1); lid <= 5; lid++ {
+		require.NoError(t, Add(db, &Message{Layer: lid, ID: types.Hash32{byte(lid)}, Blob: []byte{1}}))
+	}
+	require.NoError(t, DeleteBefore(db, 4))
+	for lid := types.LayerID(1); lid <= 5; lid++ {
+		got, err := Get(db, Filter{Layer: lid})
+		require.NoError(t, err)
+		if lid < 4 {
+			require.Empty(t, got)
+		} else {
+			require.Len(t, got, 1)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package hareoutputs
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// Output is the set of proposals that hare agreed on in a layer.
+type Output struct {
+	Layer types.LayerID
+	// Hash of the sorted proposal ids, see types.CalcProposalHash32Presorted.
+	Hash      types.Hash32
+	Proposals []types.ProposalID
+}
+
+// Set stores the output of a layer, replacing the output stored before.
+func Set(db sql.Executor, output *Output) error {
+	proposals, err := codec.EncodeSlice(output.Proposals)
+	if err != nil {
+		return fmt.Errorf("encode proposals: %w", err)
+	}
+	if _, err := db.Exec(`
+		insert into hare_outputs (layer, hash, proposals) values (?1, ?2, ?3)
+		on conflict (layer) do update set hash = ?2, proposals = ?3;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(output.Layer))
+			stmt.BindBytes(2, output.Hash[:])
+			stmt.BindBytes(3, proposals)
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("set hare output in layer %d: %w", output.Layer, err)
+	}
+	return nil
+}
+
+// Get returns the output of the layer.
+func Get(db sql.Executor, lid types.LayerID) (*Output, error) {
+	var (
+		rst    *Output
+		decErr error
+	)
+	if _, err := db.Exec(`select hash, proposals from hare_outputs where layer = ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(lid))
+		},
+		func(stmt *sql.Statement) bool {
+			rst = &Output{Layer: lid}
+			stmt.ColumnBytes(0, rst.Hash[:])
+			buf := make([]byte, stmt.ColumnLen(1))
+			stmt.ColumnBytes(1, buf)
+			rst.Proposals, decErr = codec.DecodeSlice[types.ProposalID](buf)
+			return false
+		},
+	); err != nil {
+		return nil, fmt.Errorf("get hare output in layer %d: %w", lid, err)
+	}
+	if decErr != nil {
+		return nil, fmt.Errorf("decode hare output in layer %d: %w", lid, decErr)
+	}
+	if rst == nil {
+		return nil, fmt.Errorf("%w: hare output in layer %d", sql.ErrNotFound, lid)
+	}
+	return rst, nil
+}
+
+// DeleteBefore deletes outputs of layers before the given one.
+func DeleteBefore(db sql.Executor, lid types.LayerID) error {
+	if _, err := db.Exec(`delete from hare_outputs where layer < ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(lid))
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("delete hare outputs before layer %d: %w", lid, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package hareoutputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+)
+
+func TestSetGet(t *testing.T) {
+	db := localsql.InMemoryTest(t)
+	_, err := Get(db, 10)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	proposals := types.SortProposalIDs([]types.ProposalID{
+		types.RandomProposalID(), types.RandomProposalID(), types.RandomProposalID(),
+	})
+	outputs := []*Output{
+		{Layer: 10, Hash: types.CalcProposalHash32Presorted(proposals, nil), Proposals: proposals},
+		{Layer: 11, Hash: types.CalcProposalHash32Presorted(nil, nil), Proposals: nil},
+		{Layer: 12, Hash: types.CalcProposalHash32Presorted(proposals[:1], nil), Proposals: proposals[:1]},
+	}
+	for _, output := range outputs {
+		require.NoError(t, Set(db, output))
+	}
+	for _, output := range outputs {
+		got, err := Get(db, output.Layer)
+		require.NoError(t, err)
+		require.Equal(t, output, got)
+	}
+
+	updated := &Output{Layer: 11, Hash: outputs[2].Hash, Proposals: outputs[2].Proposals}
+	require.NoError(t, Set(db, updated))
+	got, err := Get(db, 11)
+	require.NoError(t, err)
+	require.Equal(t, updated, got)
+
+	require.NoError(t, DeleteBefore(db, 12))
+	_, err = Get(db, 11)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+	got, err = Get(db, 12)
+	require.NoError(t, err)
+	require.Equal(t, outputs[2], got)
+}
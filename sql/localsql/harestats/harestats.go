@@ -0,0 +1,111 @@
+package harestats
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// Stats is a compact summary of the hare execution in a layer.
+type Stats struct {
+	Layer types.LayerID
+	// Iterations is the number of iterations that were completed.
+	Iterations uint8
+	Threshold  uint16
+	// Preround is the number of proposals that crossed the threshold in preround with each grade,
+	// starting with grade1.
+	Preround [5]uint16
+	// CommitMargin is the largest number of commit votes with grade4 for a single reference
+	// minus the threshold in the last completed iteration. It is negative if no reference
+	// crossed the threshold.
+	CommitMargin int32
+	// NotifyMargin is the same as CommitMargin for notify votes with grade5.
+	NotifyMargin int32
+}
+
+// Set stores the stats of a layer, replacing stats stored before.
+func Set(db sql.Executor, stats *Stats) error {
+	preround := make([]byte, 0, 2*len(stats.Preround))
+	for _, count := range stats.Preround {
+		preround = binary.LittleEndian.AppendUint16(preround, count)
+	}
+	if _, err := db.Exec(`
+		insert into hare_stats (layer, iterations, threshold, preround, commit_margin, notify_margin)
+		values (?1, ?2, ?3, ?4, ?5, ?6)
+		on conflict (layer) do update set
+			iterations = ?2, threshold = ?3, preround = ?4, commit_margin = ?5, notify_margin = ?6;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(stats.Layer))
+			stmt.BindInt64(2, int64(stats.Iterations))
+			stmt.BindInt64(3, int64(stats.Threshold))
+			stmt.BindBytes(4, preround)
+			stmt.BindInt64(5, int64(stats.CommitMargin))
+			stmt.BindInt64(6, int64(stats.NotifyMargin))
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("set hare stats in layer %d: %w", stats.Layer, err)
+	}
+	return nil
+}
+
+// Range returns stats of layers in [from, to], ordered by layer.
+func Range(db sql.Executor, from, to types.LayerID) ([]*Stats, error) {
+	var rst []*Stats
+	if _, err := db.Exec(`
+		select layer, iterations, threshold, preround, commit_margin, notify_margin from hare_stats
+		where layer between ?1 and ?2
+		order by layer;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(from))
+			stmt.BindInt64(2, int64(to))
+		},
+		func(stmt *sql.Statement) bool {
+			stats := &Stats{
+				Layer:        types.LayerID(stmt.ColumnInt64(0)),
+				Iterations:   uint8(stmt.ColumnInt64(1)),
+				Threshold:    uint16(stmt.ColumnInt64(2)),
+				CommitMargin: int32(stmt.ColumnInt64(4)),
+				NotifyMargin: int32(stmt.ColumnInt64(5)),
+			}
+			preround := make([]byte, stmt.ColumnLen(3))
+			stmt.ColumnBytes(3, preround)
+			for i := range stats.Preround {
+				if len(preround) < 2*(i+1) {
+					break
+				}
+				stats.Preround[i] = binary.LittleEndian.Uint16(preround[2*i:])
+			}
+			rst = append(rst, stats)
+			return true
+		},
+	); err != nil {
+		return nil, fmt.Errorf("get hare stats in layers %d-%d: %w", from, to, err)
+	}
+	return rst, nil
+}
+
+// Get returns stats of the layer.
+func Get(db sql.Executor, lid types.LayerID) (*Stats, error) {
+	rst, err := Range(db, lid, lid)
+	if err != nil {
+		return nil, err
+	}
+	if len(rst) == 0 {
+		return nil, fmt.Errorf("%w: hare stats in layer %d", sql.ErrNotFound, lid)
+	}
+	return rst[0], nil
+}
+
+// DeleteBefore deletes stats of layers before the given one.
+func DeleteBefore(db sql.Executor, lid types.LayerID) error {
+	if _, err := db.Exec(`delete from hare_stats where layer < ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(lid))
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("delete hare stats before layer %d: %w", lid, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package harestats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+)
+
+func TestSetGet(t *testing.T) {
+	db := localsql.InMemoryTest(t)
+	_, err := Get(db, 10)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	stats := []*Stats{
+		{Layer: 10, Iterations: 1, Threshold: 201, Preround: [5]uint16{3, 3, 2, 2, 1}, CommitMargin: 150, NotifyMargin: 120},
+		{Layer: 11, Iterations: 2, Threshold: 201, CommitMargin: -201, NotifyMargin: -50},
+		{Layer: 13, Iterations: 1, Threshold: 26, Preround: [5]uint16{1, 1, 1, 1, 1}, CommitMargin: 10, NotifyMargin: 8},
+	}
+	for _, s := range stats {
+		require.NoError(t, Set(db, s))
+	}
+	got, err := Get(db, 11)
+	require.NoError(t, err)
+	require.Equal(t, stats[1], got)
+
+	updated := *stats[1]
+	updated.Iterations = 3
+	updated.CommitMargin = 5
+	require.NoError(t, Set(db, &updated))
+	got, err = Get(db, 11)
+	require.NoError(t, err)
+	require.Equal(t, &updated, got)
+
+	all, err := Range(db, 10, 12)
+	require.NoError(t, err)
+	require.Equal(t, []*Stats{stats[0], &updated}, all)
+
+	require.NoError(t, DeleteBefore(db, 11))
+	all, err = Range(db, 0, types.LayerID(100))
+	require.NoError(t, err)
+	require.Equal(t, []*Stats{&updated, stats[2]}, all)
+}
@@ -0,0 +1,80 @@
+package localsql
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/poet/shared"
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// migration0016 backfills the expiration of poet certificates that were stored before
+// the expiration was recorded. The expiration is decoded from the certificate, certificates
+// that can't be decoded are marked as expired, so that they are refreshed right away.
+type migration0016 struct{}
+
+var _ sql.Migration = &migration0016{}
+
+func new0016Migration() *migration0016 {
+	return &migration0016{}
+}
+
+func (*migration0016) Name() string {
+	return "backfill expiration of poet certificates"
+}
+
+func (*migration0016) Order() int {
+	return 16
+}
+
+func (*migration0016) Rollback() error {
+	return nil
+}
+
+type storedCert struct {
+	rowid int64
+	data  []byte
+}
+
+func (m *migration0016) Apply(db sql.Executor, logger *zap.Logger) error {
+	var certs []storedCert
+	_, err := db.Exec("SELECT rowid, certificate FROM poet_certificates WHERE expiration IS NULL", nil,
+		func(stmt *sql.Statement) bool {
+			cert := storedCert{rowid: stmt.ColumnInt64(0), data: make([]byte, stmt.ColumnLen(1))}
+			stmt.ColumnBytes(1, cert.data)
+			certs = append(certs, cert)
+			return true
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("selecting poet certificates without expiration: %w", err)
+	}
+	for _, cert := range certs {
+		var expiration int64
+		decoded, err := shared.DecodeCert(cert.data)
+		switch {
+		case err != nil:
+			logger.Warn("failed to decode poet certificate, it will be refreshed",
+				zap.Int64("rowid", cert.rowid),
+				zap.Error(err),
+			)
+		case decoded.Expiration == nil:
+			continue
+		default:
+			expiration = decoded.Expiration.UnixNano()
+		}
+		if _, err := db.Exec("UPDATE poet_certificates SET expiration = ?1 WHERE rowid = ?2",
+			func(stmt *sql.Statement) {
+				stmt.BindInt64(1, expiration)
+				stmt.BindInt64(2, cert.rowid)
+			}, nil,
+		); err != nil {
+			return fmt.Errorf("setting expiration of poet certificate %d: %w", cert.rowid, err)
+		}
+	}
+	if len(certs) > 0 {
+		logger.Info("backfilled expiration of poet certificates", zap.Int("total", len(certs)))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package localsql
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spacemeshos/poet/shared"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/certifier"
+)
+
+func Test0016Migration(t *testing.T) {
+	schema, err := Schema()
+	require.NoError(t, err)
+	schema.Migrations = slices.DeleteFunc(schema.Migrations, func(m sql.Migration) bool {
+		return m.Order() >= 16
+	})
+
+	db := sql.InMemory(
+		sql.WithLogger(zaptest.NewLogger(t)),
+		sql.WithDatabaseSchema(schema),
+		sql.WithNoCheckSchemaDrift(),
+		sql.WithForceMigrations(true),
+	)
+
+	expiration := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	expiring, err := shared.EncodeCert(&shared.Cert{Pubkey: types.RandomBytes(32), Expiration: &expiration})
+	require.NoError(t, err)
+	eternal, err := shared.EncodeCert(&shared.Cert{Pubkey: types.RandomBytes(32)})
+	require.NoError(t, err)
+
+	certs := map[types.NodeID][]byte{
+		{1}: expiring,
+		{2}: eternal,
+		{3}: []byte("malformed"),
+	}
+	for id, data := range certs {
+		// certificates stored before the expiration was recorded
+		_, err := db.Exec(`
+			insert into poet_certificates (node_id, poet, certifier_id, certificate, signature)
+			values (?1, '', ?2, ?3, ?4);`,
+			func(stmt *sql.Statement) {
+				stmt.BindBytes(1, id.Bytes())
+				stmt.BindBytes(2, []byte("certifier"))
+				stmt.BindBytes(3, data)
+				stmt.BindBytes(4, []byte("signature"))
+			}, nil,
+		)
+		require.NoError(t, err)
+	}
+
+	m := new0016Migration()
+	require.Equal(t, 16, m.Order())
+	require.NoError(t, m.Apply(db, zaptest.NewLogger(t)))
+
+	cert, err := certifier.Certificate(db, types.NodeID{1}, certifier.AnyPoet, []byte("certifier"))
+	require.NoError(t, err)
+	require.NotNil(t, cert.Expiration)
+	require.Equal(t, expiration, *cert.Expiration)
+
+	cert, err = certifier.Certificate(db, types.NodeID{2}, certifier.AnyPoet, []byte("certifier"))
+	require.NoError(t, err)
+	require.Nil(t, cert.Expiration)
+
+	cert, err = certifier.Certificate(db, types.NodeID{3}, certifier.AnyPoet, []byte("certifier"))
+	require.NoError(t, err)
+	require.NotNil(t, cert.Expiration)
+	require.Equal(t, time.Unix(0, 0), *cert.Expiration)
+}
@@ -34,7 +34,7 @@ func Schema() (*sql.Schema, error) {
 	// They can be a part of this localsql package
 	return &sql.Schema{
 		Script:     strings.ReplaceAll(schemaScript, "\r", ""),
-		Migrations: sqlMigrations,
+		Migrations: sqlMigrations.AddMigration(new0016Migration()),
 	}, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
 )
@@ -13,6 +14,16 @@ type PoETRegistration struct {
 	Address       string
 	RoundID       string
 	RoundEnd      time.Time
+	// Proof is the proof fetched from the poet for this registration, nil if it wasn't fetched yet.
+	Proof *PoETRegistrationProof
+}
+
+// PoETRegistrationProof is the progress of fetching the proof of a registration.
+type PoETRegistrationProof struct {
+	Ref       types.PoetProofRef
+	LeafCount uint64
+	// Membership is nil if the challenge is not a member of the proof.
+	Membership *types.MerkleProof
 }
 
 func AddPoetRegistration(
@@ -37,6 +48,46 @@ func AddPoetRegistration(
 	return nil
 }
 
+// SetPoetRegistrationProof records that the proof of the registration with the poet was fetched
+// and whether the challenge is a member of it.
+func SetPoetRegistrationProof(
+	db sql.Executor,
+	nodeID types.NodeID,
+	address string,
+	proof PoETRegistrationProof,
+) error {
+	var membership []byte
+	if proof.Membership != nil {
+		buf, err := codec.Encode(proof.Membership)
+		if err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+		membership = buf
+	}
+	enc := func(stmt *sql.Statement) {
+		stmt.BindBytes(1, nodeID.Bytes())
+		stmt.BindText(2, address)
+		stmt.BindBytes(3, proof.Ref[:])
+		stmt.BindInt64(4, int64(proof.LeafCount))
+		if membership != nil {
+			stmt.BindBytes(5, membership)
+		} else {
+			stmt.BindNull(5)
+		}
+	}
+	rows, err := db.Exec(`
+		update poet_registration set proof_ref = ?3, leaf_count = ?4, membership = ?5
+		where id = ?1 and address = ?2 returning id;`, enc, nil)
+	if err != nil {
+		return fmt.Errorf("set poet registration proof for %s/%s: %w", nodeID.ShortString(), address, err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("set poet registration proof for %s/%s: %w",
+			nodeID.ShortString(), address, sql.ErrNotFound)
+	}
+	return nil
+}
+
 func ClearPoetRegistrations(db sql.Executor, nodeID types.NodeID) error {
 	enc := func(stmt *sql.Statement) {
 		stmt.BindBytes(1, nodeID.Bytes())
@@ -54,6 +105,7 @@ func PoetRegistrations(db sql.Executor, nodeID types.NodeID) ([]PoETRegistration
 		stmt.BindBytes(1, nodeID.Bytes())
 	}
 
+	var decodeErr error
 	dec := func(stmt *sql.Statement) bool {
 		registration := PoETRegistration{
 			Address:  stmt.ColumnText(1),
@@ -61,16 +113,54 @@ func PoetRegistrations(db sql.Executor, nodeID types.NodeID) ([]PoETRegistration
 			RoundEnd: time.Unix(stmt.ColumnInt64(3), 0),
 		}
 		stmt.ColumnBytes(0, registration.ChallengeHash[:])
+		if !sql.IsNull(stmt, 4) {
+			registration.Proof = &PoETRegistrationProof{LeafCount: uint64(stmt.ColumnInt64(5))}
+			stmt.ColumnBytes(4, registration.Proof.Ref[:])
+			if stmt.ColumnLen(6) > 0 {
+				registration.Proof.Membership = &types.MerkleProof{}
+				_, decodeErr = codec.DecodeFrom(stmt.ColumnReader(6), registration.Proof.Membership)
+			}
+		}
 		registrations = append(registrations, registration)
-		return true
+		return decodeErr == nil
 	}
 
-	query := `SELECT hash, address, round_id, round_end FROM poet_registration WHERE id = ?1;`
+	query := `SELECT hash, address, round_id, round_end, proof_ref, leaf_count, membership
+		FROM poet_registration WHERE id = ?1;`
 
 	_, err := db.Exec(query, enc, dec)
 	if err != nil {
 		return nil, fmt.Errorf("get poet registrations for node id %s: %w", nodeID.ShortString(), err)
 	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("decode proof membership for node id %s: %w", nodeID.ShortString(), decodeErr)
+	}
 
 	return registrations, nil
 }
+
+// CountPoetRegistrations returns the number of registrations of all identities by poet address.
+func CountPoetRegistrations(db sql.Executor) (map[string]int, error) {
+	counts := make(map[string]int)
+	dec := func(stmt *sql.Statement) bool {
+		counts[stmt.ColumnText(0)] = int(stmt.ColumnInt64(1))
+		return true
+	}
+	if _, err := db.Exec(`select address, count(*) from poet_registration group by address;`, nil, dec); err != nil {
+		return nil, fmt.Errorf("count poet registrations: %w", err)
+	}
+	return counts, nil
+}
+
+// DeletePoetRegistrationsByAddress deletes the registrations of all identities with the poet
+// and returns the number of deleted registrations.
+func DeletePoetRegistrationsByAddress(db sql.Executor, address string) (int, error) {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindText(1, address)
+	}
+	rows, err := db.Exec(`delete from poet_registration where address = ?1 returning id;`, enc, nil)
+	if err != nil {
+		return 0, fmt.Errorf("delete poet registrations with %s: %w", address, err)
+	}
+	return rows, nil
+}
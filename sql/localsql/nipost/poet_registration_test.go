@@ -77,3 +77,71 @@ func Test_AddPoetRegistration_NoDuplicates(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, registrations, 1)
 }
+
+func Test_SetPoetRegistrationProof(t *testing.T) {
+	db := localsql.InMemory()
+
+	nodeID := types.RandomNodeID()
+	reg1 := PoETRegistration{
+		ChallengeHash: types.RandomHash(),
+		Address:       "address1",
+		RoundID:       "round1",
+		RoundEnd:      time.Now().Round(time.Second),
+	}
+	reg2 := PoETRegistration{
+		ChallengeHash: types.RandomHash(),
+		Address:       "address2",
+		RoundID:       "round2",
+		RoundEnd:      time.Now().Round(time.Second),
+	}
+	require.NoError(t, AddPoetRegistration(db, nodeID, reg1))
+	require.NoError(t, AddPoetRegistration(db, nodeID, reg2))
+
+	reg1.Proof = &PoETRegistrationProof{
+		Ref:       types.PoetProofRef(types.RandomHash()),
+		LeafCount: 1234,
+		Membership: &types.MerkleProof{
+			LeafIndex: 7,
+			Nodes:     []types.Hash32{types.RandomHash(), types.RandomHash()},
+		},
+	}
+	require.NoError(t, SetPoetRegistrationProof(db, nodeID, reg1.Address, *reg1.Proof))
+	reg2.Proof = &PoETRegistrationProof{Ref: types.PoetProofRef(types.RandomHash()), LeafCount: 10}
+	require.NoError(t, SetPoetRegistrationProof(db, nodeID, reg2.Address, *reg2.Proof))
+
+	registrations, err := PoetRegistrations(db, nodeID)
+	require.NoError(t, err)
+	require.Equal(t, []PoETRegistration{reg1, reg2}, registrations)
+
+	err = SetPoetRegistrationProof(db, nodeID, "address3", *reg1.Proof)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+}
+
+func Test_DeletePoetRegistrationsByAddress(t *testing.T) {
+	db := localsql.InMemory()
+
+	for _, nodeID := range []types.NodeID{types.RandomNodeID(), types.RandomNodeID()} {
+		for _, address := range []string{"address1", "address2"} {
+			require.NoError(t, AddPoetRegistration(db, nodeID, PoETRegistration{
+				ChallengeHash: types.RandomHash(),
+				Address:       address,
+				RoundID:       "round",
+				RoundEnd:      time.Now().Round(time.Second),
+			}))
+		}
+	}
+	counts, err := CountPoetRegistrations(db)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"address1": 2, "address2": 2}, counts)
+
+	deleted, err := DeletePoetRegistrationsByAddress(db, "address1")
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+	deleted, err = DeletePoetRegistrationsByAddress(db, "address1")
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	counts, err = CountPoetRegistrations(db)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"address2": 2}, counts)
+}
@@ -0,0 +1,11 @@
+CREATE TABLE hare_messages
+(
+    layer     INT NOT NULL,
+    iter      INT NOT NULL,
+    round     INT NOT NULL,
+    sender    CHAR(32) NOT NULL,
+    id        CHAR(32) NOT NULL,
+    received  INT NOT NULL,
+    msg       BLOB NOT NULL,
+    PRIMARY KEY (layer, iter, round, sender, id)
+) WITHOUT ROWID;
@@ -0,0 +1,9 @@
+CREATE TABLE hare_stats
+(
+    layer          INT PRIMARY KEY,
+    iterations     INT NOT NULL,
+    threshold      INT NOT NULL,
+    preround       BLOB NOT NULL,
+    commit_margin  INT NOT NULL,
+    notify_margin  INT NOT NULL
+) WITHOUT ROWID;
@@ -0,0 +1,19 @@
+ALTER TABLE poet_certificates RENAME TO poet_certificates_old;
+DROP INDEX idx_poet_certificates;
+
+CREATE TABLE poet_certificates
+(
+    node_id      BLOB NOT NULL,
+    poet         VARCHAR NOT NULL,
+    certifier_id BLOB NOT NULL,
+    certificate  BLOB NOT NULL,
+    signature    BLOB NOT NULL,
+    expiration   INT
+);
+
+CREATE UNIQUE INDEX idx_poet_certificates ON poet_certificates (node_id, poet, certifier_id);
+
+INSERT INTO poet_certificates (node_id, poet, certifier_id, certificate, signature)
+SELECT node_id, '', certifier_id, certificate, signature FROM poet_certificates_old;
+
+DROP TABLE poet_certificates_old;
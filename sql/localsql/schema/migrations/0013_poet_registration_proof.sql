@@ -0,0 +1,3 @@
+ALTER TABLE poet_registration ADD COLUMN proof_ref CHAR(32);
+ALTER TABLE poet_registration ADD COLUMN leaf_count INT;
+ALTER TABLE poet_registration ADD COLUMN membership BLOB;
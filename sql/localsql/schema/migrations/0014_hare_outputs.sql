@@ -0,0 +1,6 @@
+CREATE TABLE hare_outputs
+(
+    layer      INT PRIMARY KEY,
+    hash       CHAR(32) NOT NULL,
+    proposals  BLOB NOT NULL
+) WITHOUT ROWID;
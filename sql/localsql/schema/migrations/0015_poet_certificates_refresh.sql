@@ -0,0 +1,2 @@
+ALTER TABLE poet_certificates ADD COLUMN issued INT;
+ALTER TABLE poet_certificates ADD COLUMN certifier_url VARCHAR;
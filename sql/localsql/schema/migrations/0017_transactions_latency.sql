@@ -0,0 +1,11 @@
+-- progress of transactions from the mempool to the chain as observed by the node.
+-- times in nanoseconds since epoch, when the transaction was included in a proposal first
+-- and when it was applied in the layer.
+CREATE TABLE transactions_latency
+(
+    id        CHAR(32) PRIMARY KEY,
+    proposed  INT,
+    applied   INT,
+    layer     INT
+) WITHOUT ROWID;
+CREATE INDEX transactions_latency_by_layer ON transactions_latency (layer);
@@ -0,0 +1,3 @@
+-- the layer in which the transaction was applied before the layer was reverted,
+-- null if the transaction was never reverted.
+ALTER TABLE transactions_latency ADD COLUMN reverted INT;
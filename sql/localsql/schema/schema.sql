@@ -1,4 +1,4 @@
-PRAGMA user_version = 9;
+PRAGMA user_version = 18;
 CREATE TABLE atx_sync_requests 
 (
     epoch     INT NOT NULL,
@@ -24,6 +24,32 @@ CREATE TABLE "challenge"
     post_indices  VARCHAR,
     post_pow      UNSIGNED LONG INT
 , poet_proof_ref        CHAR(32), poet_proof_membership VARCHAR) WITHOUT ROWID;
+CREATE TABLE hare_messages
+(
+    layer     INT NOT NULL,
+    iter      INT NOT NULL,
+    round     INT NOT NULL,
+    sender    CHAR(32) NOT NULL,
+    id        CHAR(32) NOT NULL,
+    received  INT NOT NULL,
+    msg       BLOB NOT NULL,
+    PRIMARY KEY (layer, iter, round, sender, id)
+) WITHOUT ROWID;
+CREATE TABLE hare_outputs
+(
+    layer      INT PRIMARY KEY,
+    hash       CHAR(32) NOT NULL,
+    proposals  BLOB NOT NULL
+) WITHOUT ROWID;
+CREATE TABLE hare_stats
+(
+    layer          INT PRIMARY KEY,
+    iterations     INT NOT NULL,
+    threshold      INT NOT NULL,
+    preround       BLOB NOT NULL,
+    commit_margin  INT NOT NULL,
+    notify_margin  INT NOT NULL
+) WITHOUT ROWID;
 CREATE TABLE malfeasance_sync_state
 (
   id INT NOT NULL PRIMARY KEY,
@@ -46,18 +72,20 @@ CREATE TABLE nipost
 CREATE TABLE poet_certificates
 (
     node_id      BLOB NOT NULL,
+    poet         VARCHAR NOT NULL,
     certifier_id BLOB NOT NULL,
     certificate  BLOB NOT NULL,
-    signature    BLOB NOT NULL
-);
-CREATE UNIQUE INDEX idx_poet_certificates ON poet_certificates (node_id, certifier_id);
+    signature    BLOB NOT NULL,
+    expiration   INT
+, issued INT, certifier_url VARCHAR);
+CREATE UNIQUE INDEX idx_poet_certificates ON poet_certificates (node_id, poet, certifier_id);
 CREATE TABLE poet_registration
 (
     id            CHAR(32) NOT NULL,
     hash          CHAR(32) NOT NULL,
     address       VARCHAR NOT NULL,
     round_id      VARCHAR NOT NULL,
-    round_end     INT NOT NULL,
+    round_end     INT NOT NULL, proof_ref CHAR(32), leaf_count INT, membership BLOB,
 
     PRIMARY KEY (id, address)
 ) WITHOUT ROWID;
@@ -81,3 +109,11 @@ CREATE TABLE prepared_activeset
     data          BLOB NOT NULL,
     PRIMARY KEY (kind, epoch)
 ) WITHOUT ROWID;
+CREATE TABLE transactions_latency
+(
+    id        CHAR(32) PRIMARY KEY,
+    proposed  INT,
+    applied   INT,
+    layer     INT
+, reverted INT) WITHOUT ROWID;
+CREATE INDEX transactions_latency_by_layer ON transactions_latency (layer);
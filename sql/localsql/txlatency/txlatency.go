@@ -0,0 +1,97 @@
+package txlatency
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// Latency is the progress of a transaction from the mempool to the chain.
+type Latency struct {
+	// Proposed is when the transaction was included in a proposal first, zero if it wasn't yet.
+	Proposed time.Time
+	// Applied is when the transaction was applied in Layer, zero if it wasn't yet.
+	Applied time.Time
+	Layer   types.LayerID
+	// Reverted is the layer in which the transaction was applied before the layer was reverted,
+	// zero if it was never reverted. It is kept after the transaction is applied again.
+	Reverted types.LayerID
+}
+
+// SetProposed records the time when the transaction was included in a proposal, if it wasn't recorded yet.
+// It returns true if the time was recorded.
+func SetProposed(db sql.Executor, id types.TransactionID, proposed time.Time) (bool, error) {
+	rows, err := db.Exec(`
+		insert into transactions_latency (id, proposed) values (?1, ?2)
+		on conflict (id) do update set proposed = ?2 where proposed is null
+		returning id;`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, id[:])
+			stmt.BindInt64(2, proposed.UnixNano())
+		}, nil,
+	)
+	if err != nil {
+		return false, fmt.Errorf("set proposed %s: %w", id, err)
+	}
+	return rows > 0, nil
+}
+
+// SetApplied records the time when the transaction was applied in the layer.
+func SetApplied(db sql.Executor, id types.TransactionID, lid types.LayerID, applied time.Time) error {
+	if _, err := db.Exec(`
+		insert into transactions_latency (id, applied, layer) values (?1, ?2, ?3)
+		on conflict (id) do update set applied = ?2, layer = ?3;`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, id[:])
+			stmt.BindInt64(2, applied.UnixNano())
+			stmt.BindInt64(3, int64(lid))
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("set applied %s: %w", id, err)
+	}
+	return nil
+}
+
+// UndoLayers forgets the application of transactions applied in `from` layer and later,
+// and records the layers they were applied in as reverted.
+func UndoLayers(db sql.Executor, from types.LayerID) error {
+	if _, err := db.Exec(`update transactions_latency
+		set reverted = layer, applied = null, layer = null
+		where layer >= ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(from))
+		}, nil,
+	); err != nil {
+		return fmt.Errorf("undo latency from layer %d: %w", from, err)
+	}
+	return nil
+}
+
+// Get returns the latency of the transaction.
+func Get(db sql.Executor, id types.TransactionID) (Latency, error) {
+	var latency Latency
+	rows, err := db.Exec(`select proposed, applied, layer, reverted from transactions_latency where id = ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, id[:])
+		},
+		func(stmt *sql.Statement) bool {
+			if !sql.IsNull(stmt, 0) {
+				latency.Proposed = time.Unix(0, stmt.ColumnInt64(0))
+			}
+			if !sql.IsNull(stmt, 1) {
+				latency.Applied = time.Unix(0, stmt.ColumnInt64(1))
+				latency.Layer = types.LayerID(uint32(stmt.ColumnInt64(2)))
+			}
+			latency.Reverted = types.LayerID(uint32(stmt.ColumnInt64(3)))
+			return false
+		},
+	)
+	if err != nil {
+		return Latency{}, fmt.Errorf("get latency %s: %w", id, err)
+	} else if rows == 0 {
+		return Latency{}, fmt.Errorf("%w: latency of tx %s", sql.ErrNotFound, id)
+	}
+	return latency, nil
+}
@@ -0,0 +1,62 @@
+package txlatency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+)
+
+func TestLatency(t *testing.T) {
+	db := localsql.InMemoryTest(t)
+	id := types.RandomTransactionID()
+	_, err := Get(db, id)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	proposed := time.Unix(0, time.Now().UnixNano())
+	first, err := SetProposed(db, id, proposed)
+	require.NoError(t, err)
+	require.True(t, first)
+	// only the first inclusion is recorded
+	first, err = SetProposed(db, id, proposed.Add(time.Second))
+	require.NoError(t, err)
+	require.False(t, first)
+
+	lid := types.LayerID(10)
+	applied := proposed.Add(time.Minute)
+	require.NoError(t, SetApplied(db, id, lid, applied))
+	latency, err := Get(db, id)
+	require.NoError(t, err)
+	require.Equal(t, Latency{Proposed: proposed, Applied: applied, Layer: lid}, latency)
+
+	require.NoError(t, UndoLayers(db, lid+1))
+	latency, err = Get(db, id)
+	require.NoError(t, err)
+	require.Equal(t, Latency{Proposed: proposed, Applied: applied, Layer: lid}, latency)
+
+	require.NoError(t, UndoLayers(db, lid))
+	latency, err = Get(db, id)
+	require.NoError(t, err)
+	require.Equal(t, Latency{Proposed: proposed, Reverted: lid}, latency)
+
+	// applied again after the revert
+	require.NoError(t, SetApplied(db, id, lid+1, applied))
+	latency, err = Get(db, id)
+	require.NoError(t, err)
+	require.Equal(t, Latency{Proposed: proposed, Applied: applied, Layer: lid + 1, Reverted: lid}, latency)
+}
+
+func TestLatency_AppliedNotProposed(t *testing.T) {
+	db := localsql.InMemoryTest(t)
+	id := types.RandomTransactionID()
+	applied := time.Unix(0, time.Now().UnixNano())
+	require.NoError(t, SetApplied(db, id, 3, applied))
+
+	latency, err := Get(db, id)
+	require.NoError(t, err)
+	require.Equal(t, Latency{Applied: applied, Layer: 3}, latency)
+}
@@ -3,6 +3,8 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -18,45 +20,71 @@ const (
 	subsystem     = "database" // subsystem shared by all metrics exposed by this package.
 )
 
+// TableStats is the size and number of rows of a table at the time of the last collection.
+// Size is zero if sqlite is compiled without dbstat, Rows is zero if counting rows is disabled.
+type TableStats struct {
+	Name string
+	Rows int64
+	Size int64
+}
+
+// Stats is a snapshot of the database statistics.
+type Stats struct {
+	Collected time.Time
+	Tables    []TableStats
+	TotalSize int64
+}
+
 // DBMetricsCollector collects metrics from db.
 type DBMetricsCollector struct {
 	logger        *zap.Logger
 	checkInterval time.Duration
 	db            sql.StateDatabase
 	tablesList    map[string]struct{}
+	dbstat        bool
+	countRows     bool
 	eg            errgroup.Group
 	cancel        context.CancelFunc
 
 	tableSize *prometheus.GaugeVec
+	tableRows *prometheus.GaugeVec
 	indexSize *prometheus.GaugeVec
 	totalSize *prometheus.GaugeVec
+
+	mu    sync.Mutex
+	stats Stats
 }
 
 // NewDBMetricsCollector creates new DBMetricsCollector.
+// If countRows is true the rows of every table are counted on each collection, which requires
+// a full scan of the table and is expensive on large databases.
 func NewDBMetricsCollector(
 	ctx context.Context,
 	db sql.StateDatabase,
 	logger *zap.Logger,
 	checkInterval time.Duration,
+	countRows bool,
 ) *DBMetricsCollector {
 	ctx, cancel := context.WithCancel(ctx)
 	collector := &DBMetricsCollector{
 		checkInterval: checkInterval,
+		countRows:     countRows,
 		logger:        logger.Named("db_metrics"),
 		db:            db,
 		cancel:        cancel,
 		tableSize:     metrics.NewGauge("table_size", subsystem, "Size of table in bytes", []string{"name"}),
+		tableRows:     metrics.NewGauge("table_rows", subsystem, "Number of rows in table", []string{"name"}),
 		indexSize:     metrics.NewGauge("index_size", subsystem, "Size of index in bytes", []string{"name"}),
 		totalSize:     metrics.NewGauge("total_size", subsystem, "Total size of db in bytes", nil),
 	}
-	statEnabled, err := collector.checkCompiledWithDBStat()
+	var err error
+	collector.dbstat, err = collector.checkCompiledWithDBStat()
 	if err != nil {
 		collector.logger.Error("error check compile options", zap.Error(err))
 		return nil
 	}
-	if !statEnabled {
-		collector.logger.Info("sqlite compiled without `SQLITE_ENABLE_DBSTAT_VTAB`. Metrics will not collected")
-		return nil
+	if !collector.dbstat {
+		collector.logger.Info("sqlite compiled without `SQLITE_ENABLE_DBSTAT_VTAB`. Size metrics will not be collected")
 	}
 
 	collector.tablesList, err = collector.getListOfTables()
@@ -80,6 +108,16 @@ func (d *DBMetricsCollector) Close() {
 	}
 }
 
+// Stats returns the statistics from the last collection.
+// Zero value is returned if nothing was collected yet.
+func (d *DBMetricsCollector) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := d.stats
+	stats.Tables = append([]TableStats(nil), d.stats.Tables...)
+	return stats
+}
+
 // CollectMetrics collects metrics from db.
 func (d *DBMetricsCollector) CollectMetrics(ctx context.Context) {
 	ticker := time.NewTicker(d.checkInterval)
@@ -99,20 +137,25 @@ func (d *DBMetricsCollector) CollectMetrics(ctx context.Context) {
 
 func (d *DBMetricsCollector) collect() error {
 	sizes := make(map[string]int64, 30)
-	_, err := d.db.Exec(
-		"SELECT name, sum(pgsize) as sum FROM dbstat GROUP BY name",
-		nil,
-		func(stmt *sql.Statement) bool {
-			sizes[stmt.ColumnText(0)] = stmt.ColumnInt64(1)
-			return true
-		},
-	)
-	if err != nil {
-		return fmt.Errorf("error execute stat metrics: %w", err)
+	if d.dbstat {
+		_, err := d.db.Exec(
+			"SELECT name, sum(pgsize) as sum FROM dbstat GROUP BY name",
+			nil,
+			func(stmt *sql.Statement) bool {
+				sizes[stmt.ColumnText(0)] = stmt.ColumnInt64(1)
+				return true
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("error execute stat metrics: %w", err)
+		}
+	}
+	stats := Stats{
+		Collected: time.Now(),
+		Tables:    make([]TableStats, 0, len(d.tablesList)),
 	}
-	var totalSize int64
 	for name, size := range sizes {
-		totalSize += size
+		stats.TotalSize += size
 		_, ok := d.tablesList[name]
 		if ok {
 			d.tableSize.WithLabelValues(name).Set(float64(size))
@@ -120,10 +163,45 @@ func (d *DBMetricsCollector) collect() error {
 		}
 		d.indexSize.WithLabelValues(name).Set(float64(size))
 	}
-	d.totalSize.WithLabelValues().Set(float64(totalSize))
+	if d.dbstat {
+		d.totalSize.WithLabelValues().Set(float64(stats.TotalSize))
+	}
+
+	for name := range d.tablesList {
+		var rows int64
+		if d.countRows {
+			var err error
+			rows, err = d.rows(name)
+			if err != nil {
+				return err
+			}
+			d.tableRows.WithLabelValues(name).Set(float64(rows))
+		}
+		stats.Tables = append(stats.Tables, TableStats{Name: name, Rows: rows, Size: sizes[name]})
+	}
+	sort.Slice(stats.Tables, func(i, j int) bool {
+		return stats.Tables[i].Name < stats.Tables[j].Name
+	})
+
+	d.mu.Lock()
+	d.stats = stats
+	d.mu.Unlock()
 	return nil
 }
 
+func (d *DBMetricsCollector) rows(table string) (int64, error) {
+	var rows int64
+	// table names come from sqlite_master, quoting guards against unusual names
+	_, err := d.db.Exec(fmt.Sprintf(`SELECT count(*) FROM "%s"`, table), nil, func(stmt *sql.Statement) bool {
+		rows = stmt.ColumnInt64(0)
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count rows in %s: %w", table, err)
+	}
+	return rows, nil
+}
+
 func (d *DBMetricsCollector) checkCompiledWithDBStat() (bool, error) {
 	var options []string
 	_, err := d.db.Exec("PRAGMA compile_options", nil, func(stmt *sql.Statement) bool {
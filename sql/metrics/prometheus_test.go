@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestDBMetricsCollector_Stats(t *testing.T) {
+	db := statesql.InMemoryTest(t)
+	for lid := types.LayerID(1); lid <= 3; lid++ {
+		require.NoError(t, layers.SetMeshHash(db, lid, types.RandomHash()))
+	}
+
+	collector := NewDBMetricsCollector(context.Background(), db, zaptest.NewLogger(t), time.Hour, true)
+	require.NotNil(t, collector)
+	t.Cleanup(collector.Close)
+	require.Empty(t, collector.Stats().Tables)
+
+	require.NoError(t, collector.collect())
+	stats := collector.Stats()
+	require.NotZero(t, stats.Collected)
+	var found bool
+	for i, table := range stats.Tables {
+		if i > 0 {
+			require.Less(t, stats.Tables[i-1].Name, table.Name)
+		}
+		if table.Name == "layers" {
+			found = true
+			require.EqualValues(t, 3, table.Rows)
+			if collector.dbstat {
+				require.NotZero(t, table.Size)
+			}
+		}
+	}
+	require.True(t, found)
+
+	collector.countRows = false
+	require.NoError(t, collector.collect())
+	for _, table := range collector.Stats().Tables {
+		require.Zero(t, table.Rows, table.Name)
+	}
+}
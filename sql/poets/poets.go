@@ -3,6 +3,7 @@ package poets
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
@@ -47,10 +48,11 @@ func Add(db sql.Executor, ref types.PoetProofRef, poet, serviceID []byte, roundI
 		stmt.BindBytes(2, poet)
 		stmt.BindBytes(3, serviceID)
 		stmt.BindBytes(4, []byte(roundID))
+		stmt.BindInt64(5, time.Now().UnixNano())
 	}
 	_, err := db.Exec(`
-		insert into poets (ref, poet, service_id, round_id)
-		values (?1, ?2, ?3, ?4);`, enc, nil)
+		insert into poets (ref, poet, service_id, round_id, received)
+		values (?1, ?2, ?3, ?4, ?5);`, enc, nil)
 	if err != nil {
 		return fmt.Errorf("exec: %w", err)
 	}
@@ -81,3 +83,47 @@ func GetRef(db sql.Executor, poetID []byte, roundID string) (ref types.PoetProof
 
 	return ref, nil
 }
+
+// AddReference records that the ATX references the PoET proof. Referenced proofs are not pruned.
+func AddReference(db sql.Executor, ref types.PoetProofRef, atx types.ATXID) error {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindBytes(1, atx[:])
+		stmt.BindBytes(2, ref[:])
+	}
+	_, err := db.Exec(`
+		insert into atx_poets (atxid, ref) values (?1, ?2)
+		on conflict do nothing;`, enc, nil)
+	if err != nil {
+		return fmt.Errorf("add reference %s: %w", atx, err)
+	}
+	return nil
+}
+
+// Count returns the number of stored PoET proofs.
+func Count(db sql.Executor) (int, error) {
+	var count int
+	_, err := db.Exec("select count(*) from poets;", nil, func(stmt *sql.Statement) bool {
+		count = stmt.ColumnInt(0)
+		return false
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteUnreferencedBefore deletes PoET proofs received before the given time that are not referenced
+// by any ATX. Proofs without received time are kept. Returns the number of deleted proofs.
+func DeleteUnreferencedBefore(db sql.Executor, received time.Time) (int, error) {
+	rows, err := db.Exec(`
+		delete from poets
+		where received < ?1 and not exists (select 1 from atx_poets where atx_poets.ref = poets.ref)
+		returning ref;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, received.UnixNano())
+		}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("delete unreferenced before %v: %w", received, err)
+	}
+	return rows, nil
+}
@@ -1,7 +1,9 @@
 package poets
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -161,3 +163,40 @@ func TestGetRef(t *testing.T) {
 	_, err := GetRef(db, []byte("sid0"), "rid0")
 	require.ErrorIs(t, err, sql.ErrNotFound)
 }
+
+func TestDeleteUnreferencedBefore(t *testing.T) {
+	db := statesql.InMemory()
+
+	refs := []types.PoetProofRef{
+		{0xca, 0xfe},
+		{0xde, 0xad},
+		{0xbe, 0xef},
+	}
+	for i, ref := range refs {
+		require.NoError(t, Add(db, ref, []byte("proof"), []byte("sid"), fmt.Sprintf("rid%d", i)))
+	}
+	atx := types.RandomATXID()
+	require.NoError(t, AddReference(db, refs[0], atx))
+	require.NoError(t, AddReference(db, refs[0], atx))
+	// proofs stored before the retention policy was introduced have no received time
+	_, err := db.Exec("update poets set received = null where ref = ?1;",
+		func(stmt *sql.Statement) { stmt.BindBytes(1, refs[1][:]) }, nil)
+	require.NoError(t, err)
+
+	deleted, err := DeleteUnreferencedBefore(db, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	deleted, err = DeleteUnreferencedBefore(db, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	for i, ref := range refs {
+		exists, err := Has(db, ref)
+		require.NoError(t, err)
+		require.Equal(t, i != 2, exists)
+	}
+	count, err := Count(db)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
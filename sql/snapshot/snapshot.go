@@ -0,0 +1,141 @@
+// Package snapshot creates point-in-time copies of the state database, so that operators can
+// share the exact state of their node with developers investigating consensus issues.
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+const (
+	// DatabaseFile is the name of the database copy in the snapshot directory.
+	DatabaseFile = "state.sql"
+	// ManifestFile is the name of the manifest in the snapshot directory.
+	ManifestFile = "manifest.json"
+
+	snapshotsDir = "snapshots"
+)
+
+// Manifest describes the state captured by a snapshot. All values except Created are
+// read from the copy, so they always match its content.
+type Manifest struct {
+	Created       time.Time `json:"created"`
+	SchemaVersion int       `json:"schema_version"`
+	// Processed is the highest layer processed by the mesh.
+	Processed types.LayerID `json:"processed"`
+	// Applied is the highest layer with an applied block.
+	Applied types.LayerID `json:"applied"`
+	// AggregatedHash is the aggregated mesh hash of the Processed layer.
+	AggregatedHash types.Hash32 `json:"aggregated_hash"`
+	// StateHash is the state hash after the Applied layer.
+	StateHash types.Hash32 `json:"state_hash"`
+	// Checksum is the hex encoded sha256 of the database copy.
+	Checksum string `json:"checksum"`
+}
+
+// Dir returns the directory of the snapshot created at the given time.
+func Dir(dataDir string, created time.Time) string {
+	return filepath.Join(dataDir, snapshotsDir, created.UTC().Format("20060102T150405.000Z"))
+}
+
+// Create copies the database into a new snapshot directory under dataDir and writes the
+// manifest next to it. It returns the directory of the snapshot and its manifest.
+func Create(
+	ctx context.Context,
+	db sql.StateDatabase,
+	dataDir string,
+	created time.Time,
+) (string, *Manifest, error) {
+	dir := Dir(dataDir, created)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", nil, fmt.Errorf("create snapshot dir: %w", err)
+	}
+	path := filepath.Join(dir, DatabaseFile)
+	if err := db.Backup(ctx, path); err != nil {
+		return "", nil, err
+	}
+	manifest, err := readManifest(path)
+	if err != nil {
+		return "", nil, err
+	}
+	manifest.Created = created.UTC()
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), data, 0o600); err != nil {
+		return "", nil, fmt.Errorf("write manifest: %w", err)
+	}
+	return dir, manifest, nil
+}
+
+func readManifest(path string) (*Manifest, error) {
+	version, err := sql.Version(path)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot schema version: %w", err)
+	}
+	manifest := &Manifest{SchemaVersion: version}
+
+	db, err := statesql.Open("file:"+path,
+		sql.WithConnections(1),
+		sql.WithMigrationsDisabled(),
+		sql.WithNoCheckSchemaDrift(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	if err := fillManifest(db, manifest); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := db.Close(); err != nil {
+		return nil, fmt.Errorf("close snapshot: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot file: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("checksum snapshot: %w", err)
+	}
+	manifest.Checksum = hex.EncodeToString(h.Sum(nil))
+	return manifest, nil
+}
+
+func fillManifest(db sql.Executor, manifest *Manifest) error {
+	var err error
+	manifest.Processed, err = layers.GetProcessed(db)
+	if err != nil {
+		return err
+	}
+	manifest.Applied, err = layers.GetLastApplied(db)
+	if err != nil {
+		return err
+	}
+	manifest.AggregatedHash, err = layers.GetAggregatedHash(db, manifest.Processed)
+	if err != nil && !errors.Is(err, sql.ErrNotFound) {
+		return err
+	}
+	manifest.StateHash, err = layers.GetStateHash(db, manifest.Applied)
+	if err != nil && !errors.Is(err, sql.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestCreate(t *testing.T) {
+	db := statesql.InMemoryTest(t)
+	aggHash := types.RandomHash()
+	stateHash := types.RandomHash()
+	require.NoError(t, layers.SetProcessed(db, 11))
+	require.NoError(t, layers.SetMeshHash(db, 11, aggHash))
+	require.NoError(t, layers.SetApplied(db, 10, types.RandomBlockID()))
+	require.NoError(t, layers.UpdateStateHash(db, 10, stateHash))
+
+	dataDir := t.TempDir()
+	created := time.Now()
+	dir, manifest, err := Create(context.Background(), db, dataDir, created)
+	require.NoError(t, err)
+	require.Equal(t, Dir(dataDir, created), dir)
+
+	require.Equal(t, types.LayerID(11), manifest.Processed)
+	require.Equal(t, types.LayerID(10), manifest.Applied)
+	require.Equal(t, aggHash, manifest.AggregatedHash)
+	require.Equal(t, stateHash, manifest.StateHash)
+	require.NotEmpty(t, manifest.Checksum)
+	require.True(t, created.UTC().Equal(manifest.Created))
+
+	version, err := sql.Version(filepath.Join(dir, DatabaseFile))
+	require.NoError(t, err)
+	require.Equal(t, version, manifest.SchemaVersion)
+	require.NotZero(t, manifest.SchemaVersion)
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+	var stored Manifest
+	require.NoError(t, json.Unmarshal(data, &stored))
+	require.Equal(t, *manifest, stored)
+
+	// writes after the snapshot don't change it
+	require.NoError(t, layers.SetProcessed(db, 12))
+	snapshot, err := statesql.Open("file:" + filepath.Join(dir, DatabaseFile))
+	require.NoError(t, err)
+	defer snapshot.Close()
+	processed, err := layers.GetProcessed(snapshot)
+	require.NoError(t, err)
+	require.Equal(t, types.LayerID(11), processed)
+
+	_, _, err = Create(context.Background(), db, dataDir, created)
+	require.ErrorIs(t, err, os.ErrExist)
+}
+
+func TestCreate_Empty(t *testing.T) {
+	db := statesql.InMemoryTest(t)
+	_, manifest, err := Create(context.Background(), db, t.TempDir(), time.Now())
+	require.NoError(t, err)
+	require.Zero(t, manifest.Processed)
+	require.Zero(t, manifest.Applied)
+	require.Equal(t, types.Hash32{}, manifest.StateHash)
+}
@@ -0,0 +1,11 @@
+-- proofs stored before this migration have no received time and are never pruned
+ALTER TABLE poets ADD COLUMN received INT;
+CREATE INDEX poets_by_received ON poets (received) WHERE received IS NOT NULL;
+
+CREATE TABLE atx_poets
+(
+    atxid CHAR(32) NOT NULL,
+    ref   VARCHAR NOT NULL,
+    PRIMARY KEY (atxid, ref)
+) WITHOUT ROWID;
+CREATE INDEX atx_poets_by_ref ON atx_poets (ref);
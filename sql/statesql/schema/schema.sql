@@ -1,4 +1,4 @@
-PRAGMA user_version = 23;
+PRAGMA user_version = 24;
 CREATE TABLE accounts
 (
     address        CHAR(24),
@@ -22,6 +22,13 @@ CREATE TABLE atx_blobs
     atx BLOB
 , version INTEGER);
 CREATE UNIQUE INDEX atx_blobs_id ON atx_blobs (id);
+CREATE TABLE atx_poets
+(
+    atxid CHAR(32) NOT NULL,
+    ref   VARCHAR NOT NULL,
+    PRIMARY KEY (atxid, ref)
+) WITHOUT ROWID;
+CREATE INDEX atx_poets_by_ref ON atx_poets (ref);
 CREATE TABLE atxs
 (
     id                  CHAR(32),
@@ -102,7 +109,8 @@ CREATE TABLE poets
     poet       BLOB,
     service_id VARCHAR,
     round_id   VARCHAR
-);
+, received INT);
+CREATE INDEX poets_by_received ON poets (received) WHERE received IS NOT NULL;
 CREATE INDEX poets_by_service_id_by_round_id ON poets (service_id, round_id);
 CREATE TABLE posts (
     atxid CHAR(32) NOT NULL,
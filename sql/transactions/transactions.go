@@ -157,6 +157,49 @@ func UndoLayers(tx sql.Transaction, from types.LayerID) error {
 	return nil
 }
 
+// AppliedFrom returns transactions applied in `from` layer and later.
+func AppliedFrom(db sql.Executor, from types.LayerID) ([]types.TransactionID, error) {
+	var rst []types.TransactionID
+	if _, err := db.Exec(`select id from transactions where layer >= ?1 and result is not null;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(from))
+		},
+		func(stmt *sql.Statement) bool {
+			var id types.TransactionID
+			stmt.ColumnBytes(0, id[:])
+			rst = append(rst, id)
+			return true
+		}); err != nil {
+		return nil, fmt.Errorf("transactions applied from %s: %w", from, err)
+	}
+	return rst, nil
+}
+
+// AddressesAppliedFrom returns addresses affected by layers applied from `from` layer and later.
+// It includes principals and all addresses recorded in the transaction results, as well as
+// coinbases that were rewarded in these layers.
+func AddressesAppliedFrom(db sql.Executor, from types.LayerID) ([]types.Address, error) {
+	var rst []types.Address
+	if _, err := db.Exec(`select principal from transactions where layer >= ?1 and result is not null
+		union
+		select address from transactions_results_addresses
+		where tid in (select id from transactions where layer >= ?1)
+		union
+		select coinbase from rewards where layer >= ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(from))
+		},
+		func(stmt *sql.Statement) bool {
+			var addr types.Address
+			stmt.ColumnBytes(0, addr[:])
+			rst = append(rst, addr)
+			return true
+		}); err != nil {
+		return nil, fmt.Errorf("addresses applied from %s: %w", from, err)
+	}
+	return rst, nil
+}
+
 // tx, header, layer, block, timestamp.
 func decodeTransaction(id types.TransactionID, stmt *sql.Statement) (*types.MeshTransaction, error) {
 	var parsed types.Transaction
@@ -358,6 +401,25 @@ func AddResult(tx sql.Transaction, id types.TransactionID, rst *types.Transactio
 	return nil
 }
 
+// GetReceived returns the time when the transaction was received.
+func GetReceived(db sql.Executor, id types.TransactionID) (time.Time, error) {
+	var received time.Time
+	rows, err := db.Exec(`select timestamp from transactions where id = ?1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, id[:])
+		},
+		func(stmt *sql.Statement) bool {
+			received = time.Unix(0, stmt.ColumnInt64(0))
+			return false
+		})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get received %s: %w", id, err)
+	} else if rows == 0 {
+		return time.Time{}, fmt.Errorf("%w: tx %s", sql.ErrNotFound, id)
+	}
+	return received, nil
+}
+
 // TransactionInProposal returns lowest layer of the proposal where tx is included after the specified layer.
 func TransactionInProposal(db sql.Executor, id types.TransactionID, after types.LayerID) (types.LayerID, error) {
 	var rst types.LayerID
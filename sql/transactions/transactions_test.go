@@ -14,6 +14,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
 	"github.com/spacemeshos/go-spacemesh/signing"
 	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/rewards"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 	"github.com/spacemeshos/go-spacemesh/sql/transactions"
 )
@@ -284,6 +285,10 @@ func TestApplyAndUndoLayers(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, types.APPLIED, mtx.State)
 	}
+	appliedFrom, err := transactions.AppliedFrom(db, firstLayer.Add(1))
+	require.NoError(t, err)
+	require.ElementsMatch(t, applied[1:], appliedFrom)
+
 	// revert to firstLayer
 	require.NoError(t, db.WithTx(context.Background(), func(dtx sql.Transaction) error {
 		return transactions.UndoLayers(dtx, firstLayer.Add(1))
@@ -298,6 +303,58 @@ func TestApplyAndUndoLayers(t *testing.T) {
 			require.Equal(t, types.MEMPOOL, mtx.State)
 		}
 	}
+	appliedFrom, err = transactions.AppliedFrom(db, firstLayer.Add(1))
+	require.NoError(t, err)
+	require.Empty(t, appliedFrom)
+}
+
+func TestAddressesAppliedFrom(t *testing.T) {
+	db := statesql.InMemory()
+
+	rng := rand.New(rand.NewSource(1001))
+	firstLayer := types.LayerID(10)
+	numLayers := uint32(5)
+	principals := make([]types.Address, 0, numLayers)
+	recipients := make([]types.Address, 0, numLayers)
+	coinbases := make([]types.Address, 0, numLayers)
+	for lid := firstLayer; lid.Before(firstLayer.Add(numLayers)); lid = lid.Add(1) {
+		signer, err := signing.NewEdSigner(signing.WithKeyFromRand(rng))
+		require.NoError(t, err)
+		tx := createTX(t, signer, types.Address{1}, uint64(lid), 191, 2)
+		require.NoError(t, transactions.Add(db, tx, time.Now()))
+		recipient := types.GenerateAddress(signer.PublicKey().Bytes()[:8])
+		require.NoError(t, db.WithTx(context.Background(), func(dtx sql.Transaction) error {
+			return transactions.AddResult(dtx, tx.ID, &types.TransactionResult{
+				Layer:     lid,
+				Block:     types.RandomBlockID(),
+				Addresses: []types.Address{recipient},
+			})
+		}))
+		coinbase := types.GenerateAddress(signer.PublicKey().Bytes()[8:16])
+		require.NoError(t, rewards.Add(db, &types.Reward{
+			Layer:       lid,
+			Coinbase:    coinbase,
+			SmesherID:   types.BytesToNodeID(signer.PublicKey().Bytes()),
+			TotalReward: 100,
+			LayerReward: 50,
+		}))
+		principals = append(principals, tx.Principal)
+		recipients = append(recipients, recipient)
+		coinbases = append(coinbases, coinbase)
+	}
+	// pending transactions are not included
+	signer, err := signing.NewEdSigner(signing.WithKeyFromRand(rng))
+	require.NoError(t, err)
+	require.NoError(t, transactions.Add(db, createTX(t, signer, types.Address{1}, 1, 191, 2), time.Now()))
+
+	got, err := transactions.AddressesAppliedFrom(db, firstLayer.Add(3))
+	require.NoError(t, err)
+	expected := append(append(principals[3:], recipients[3:]...), coinbases[3:]...)
+	require.ElementsMatch(t, expected, got)
+
+	got, err = transactions.AddressesAppliedFrom(db, firstLayer.Add(numLayers))
+	require.NoError(t, err)
+	require.Empty(t, got)
 }
 
 func TestGetBlob(t *testing.T) {
@@ -562,3 +619,21 @@ func TestTransactionInBlock(t *testing.T) {
 	_, _, err = transactions.TransactionInBlock(db, tid, lids[2])
 	require.ErrorIs(t, err, sql.ErrNotFound)
 }
+
+func TestGetReceived(t *testing.T) {
+	db := statesql.InMemory()
+
+	rng := rand.New(rand.NewSource(1001))
+	signer, err := signing.NewEdSigner(signing.WithKeyFromRand(rng))
+	require.NoError(t, err)
+	tx := createTX(t, signer, types.Address{1}, 1, 191, 1)
+	received := time.Unix(0, time.Now().UnixNano())
+	require.NoError(t, transactions.Add(db, tx, received))
+
+	got, err := transactions.GetReceived(db, tx.ID)
+	require.NoError(t, err)
+	require.Equal(t, received, got)
+
+	_, err = transactions.GetReceived(db, types.RandomTransactionID())
+	require.ErrorIs(t, err, sql.ErrNotFound)
+}
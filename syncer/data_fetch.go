@@ -14,6 +14,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/fetch"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/p2p/server"
 	"github.com/spacemeshos/go-spacemesh/system"
 )
 
@@ -166,7 +167,7 @@ func (d *DataFetch) PollLayerOpinions(
 			}
 		}
 		for bid, bidPeers := range peerCerts {
-			cert, err := d.fetcher.GetCert(ctx, lid, bid, bidPeers)
+			cert, err := d.fetcher.GetCert(server.WithPriority(ctx), lid, bid, bidPeers)
 			if err != nil {
 				certPeerError.Inc()
 				continue
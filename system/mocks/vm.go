@@ -115,3 +115,41 @@ func (c *MockValidationRequestVerifyCall) DoAndReturn(f func() bool) *MockValida
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// VerifyGenesis mocks base method.
+func (m *MockValidationRequest) VerifyGenesis(arg0 types.Hash20) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyGenesis", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// VerifyGenesis indicates an expected call of VerifyGenesis.
+func (mr *MockValidationRequestMockRecorder) VerifyGenesis(arg0 any) *MockValidationRequestVerifyGenesisCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyGenesis", reflect.TypeOf((*MockValidationRequest)(nil).VerifyGenesis), arg0)
+	return &MockValidationRequestVerifyGenesisCall{Call: call}
+}
+
+// MockValidationRequestVerifyGenesisCall wrap *gomock.Call
+type MockValidationRequestVerifyGenesisCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockValidationRequestVerifyGenesisCall) Return(arg0 bool) *MockValidationRequestVerifyGenesisCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockValidationRequestVerifyGenesisCall) Do(f func(types.Hash20) bool) *MockValidationRequestVerifyGenesisCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockValidationRequestVerifyGenesisCall) DoAndReturn(f func(types.Hash20) bool) *MockValidationRequestVerifyGenesisCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
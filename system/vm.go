@@ -8,4 +8,7 @@ import "github.com/spacemeshos/go-spacemesh/common/types"
 type ValidationRequest interface {
 	Parse() (*types.TxHeader, error)
 	Verify() bool
+	// VerifyGenesis verifies transaction as if it was signed for the network with the genesis id.
+	// It is used to recognize transactions replayed from other networks.
+	VerifyGenesis(types.Hash20) bool
 }
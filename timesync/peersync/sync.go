@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
@@ -137,6 +138,9 @@ type Sync struct {
 	h      host.Host
 	peers  getPeers
 
+	// offset is the offset measured in the last successful round.
+	offset atomic.Pointer[time.Duration]
+
 	eg     errgroup.Group
 	ctx    context.Context
 	cancel func()
@@ -183,6 +187,20 @@ func (s *Sync) Wait() error {
 	return fmt.Errorf("taskgroup: %w", err)
 }
 
+// ClockOffset returns the offset of the system clock to the clocks of peers, measured in the last
+// successful round. It fails if the offset exceeds the max allowed clock difference or if no round
+// succeeded yet.
+func (s *Sync) ClockOffset() (time.Duration, error) {
+	offset := s.offset.Load()
+	if offset == nil {
+		return 0, fmt.Errorf("%w: offset not measured yet", errTimesyncFailed)
+	}
+	if *offset > s.config.MaxClockOffset || -*offset > s.config.MaxClockOffset {
+		return *offset, fmt.Errorf("%w: drift = %v", errPeersNotSynced, *offset)
+	}
+	return *offset, nil
+}
+
 func (s *Sync) run() error {
 	var (
 		timer    *time.Timer
@@ -204,6 +222,7 @@ func (s *Sync) run() error {
 			offset, err := s.GetOffset(ctx, round, prs)
 			cancel()
 			if err == nil {
+				s.offset.Store(&offset)
 				if offset > s.config.MaxClockOffset || (offset < 0 && -offset > s.config.MaxClockOffset) {
 					failures += 1
 					s.log.Warn("peers offset is larger than max allowed clock difference",
@@ -110,6 +110,9 @@ func TestSyncTerminateOnError(t *testing.T) {
 	}
 	getter.EXPECT().GetPeers().Return(peers)
 
+	_, err = sync.ClockOffset()
+	require.ErrorIs(t, err, errTimesyncFailed)
+
 	sync.Start()
 	t.Cleanup(sync.Stop)
 	errors := make(chan error, 1)
@@ -122,6 +125,9 @@ func TestSyncTerminateOnError(t *testing.T) {
 	case <-time.After(100 * time.Millisecond):
 		require.FailNow(t, "timed out waiting for sync to fail")
 	}
+	offset, err := sync.ClockOffset()
+	require.ErrorIs(t, err, errPeersNotSynced)
+	require.Equal(t, 10*time.Second, offset)
 }
 
 func TestSyncSimulateMultiple(t *testing.T) {
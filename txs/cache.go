@@ -19,12 +19,16 @@ import (
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/txlatency"
 	"github.com/spacemeshos/go-spacemesh/sql/transactions"
 )
 
 const (
 	maxTXsPerAcct  = 100
 	maxTXsPerNonce = 100
+	// maxIncrementalRevert is the max number of undone layers for which the cache
+	// is reverted incrementally. deeper reverts rebuild the cache from scratch.
+	maxIncrementalRevert = 100
 )
 
 var (
@@ -73,11 +77,14 @@ type accountCache struct {
 	//   (that may contain incoming funds for that account)
 	// - a better tx arrived (higher fee) and made higher nonce txs infeasible due to insufficient balance
 	//   deemed by conservative state.
-	// TODO: evict accounts that only has DB-only txs
-	// https://github.com/spacemeshos/go-spacemesh/issues/3668
+	// Accounts that only have DB-only txs are evicted after Cache.dormantLayers, see compactDormant.
 	moreInDB bool
+	// dormantSince is the layer since which the account only has DB-only txs, 0 if it has feasible txs.
+	dormantSince types.LayerID
 
-	cachedTXs map[types.TransactionID]*NanoTX // shared with the cache instance
+	cachedTXs *txIndex          // shared with the cache instance
+	estimator SpendingEstimator // shared with the cache instance
+	rewards   *expectedRewards  // shared with the cache instance
 }
 
 func (ac *accountCache) nextNonce() uint64 {
@@ -87,19 +94,40 @@ func (ac *accountCache) nextNonce() uint64 {
 	return ac.txsByNonce.Back().Value.(*candidate).nonce() + 1
 }
 
+// spendable returns the balance available to the first pending transaction, that is the applied
+// balance and the rewards that own accounts expect in layers that are not applied yet.
+func (ac *accountCache) spendable() uint64 {
+	return ac.startBalance + ac.rewards.incoming(ac.addr)
+}
+
 func (ac *accountCache) availBalance() uint64 {
 	if ac.txsByNonce.Len() == 0 {
-		return ac.startBalance
+		return ac.spendable()
 	}
 	return ac.txsByNonce.Back().Value.(*candidate).postBalance
 }
 
+// packedProjection returns the next nonce and balance after the transactions that are
+// packed into proposals or blocks. Transactions with higher nonces than the first transaction
+// that is not packed are not accounted for, even if they are packed.
+func (ac *accountCache) packedProjection() (uint64, uint64) {
+	nonce, balance := ac.startNonce, ac.spendable()
+	for e := ac.txsByNonce.Front(); e != nil; e = e.Next() {
+		cand := e.Value.(*candidate)
+		if cand.layer() == 0 {
+			break
+		}
+		nonce, balance = cand.nonce()+1, cand.postBalance
+	}
+	return nonce, balance
+}
+
 func (ac *accountCache) precheck(logger *zap.Logger, ntx *NanoTX) (*list.Element, *candidate, error) {
 	if ac.txsByNonce.Len() >= maxTXsPerAcct {
 		ac.moreInDB = true
 		return nil, nil, fmt.Errorf("%w: len %d", errTooManyNonce, ac.txsByNonce.Len())
 	}
-	balance := ac.startBalance
+	balance := ac.spendable()
 	var prev *list.Element
 	for e := ac.txsByNonce.Back(); e != nil; e = e.Prev() {
 		cand := e.Value.(*candidate)
@@ -142,11 +170,11 @@ func (ac *accountCache) accept(logger *zap.Logger, ntx *NanoTX, blockSeed []byte
 		}
 		added = prev
 		replaced = prevCand.best
-		delete(ac.cachedTXs, prevCand.best.ID)
+		ac.cachedTXs.remove(prevCand.best.ID)
 		prevCand.best = ntx
 		prevCand.postBalance = cand.postBalance
 	}
-	ac.cachedTXs[ntx.ID] = ntx
+	ac.cachedTXs.set(ntx)
 
 	if replaced != nil {
 		logger.Debug("better transaction replaced for nonce",
@@ -190,7 +218,7 @@ func (ac *accountCache) accept(logger *zap.Logger, ntx *NanoTX, blockSeed []byte
 		rm := next
 		next = next.Next()
 		removed := ac.txsByNonce.Remove(rm).(*candidate)
-		delete(ac.cachedTXs, removed.id())
+		ac.cachedTXs.remove(removed.id())
 		logger.Debug("tx made infeasible by new/better transaction",
 			zap.Stringer("address", ac.addr),
 			zap.Stringer("tx_id", removed.id()),
@@ -273,12 +301,12 @@ func (ac *accountCache) add(logger *zap.Logger, tx *types.Transaction, received
 		return errBadNonce
 	}
 
-	ntx := NewNanoTX(&types.MeshTransaction{
+	ntx := newNanoTX(&types.MeshTransaction{
 		Transaction: *tx,
 		Received:    received,
 		LayerID:     0,
 		BlockID:     types.EmptyBlockID,
-	})
+	}, ac.estimator)
 
 	err := ac.accept(logger, ntx, nil)
 	if err != nil {
@@ -330,7 +358,7 @@ func (ac *accountCache) addPendingFromNonce(
 		}
 	}
 
-	byPrincipal := groupTXsByPrincipal(logger, mtxs)
+	byPrincipal := groupTXsByPrincipal(logger, mtxs, ac.estimator)
 	if _, ok := byPrincipal[ac.addr]; !ok {
 		logger.Panic("no txs for account after grouping", zap.Stringer("address", ac.addr))
 	}
@@ -391,7 +419,7 @@ func (ac *accountCache) resetAfterApply(
 		zap.Uint64("nonce", nextNonce),
 	)
 	for e := ac.txsByNonce.Front(); e != nil; e = e.Next() {
-		delete(ac.cachedTXs, e.Value.(*candidate).id())
+		ac.cachedTXs.remove(e.Value.(*candidate).id())
 	}
 	ac.txsByNonce = list.New()
 	ac.startNonce = nextNonce
@@ -409,21 +437,39 @@ type Cache struct {
 	logger *zap.Logger
 	stateF stateFunc
 
-	mu        sync.Mutex
-	pending   map[types.Address]*accountCache
-	cachedTXs map[types.TransactionID]*NanoTX // shared with accountCache instances
+	// mu is held for reading by operations on a single account, that also lock the shard of the account.
+	// It is held exclusively by operations that span accounts, such as applying a layer, so that they
+	// observe and update all accounts consistently.
+	mu        sync.RWMutex
+	shards    [cacheShards]accountShard
+	cachedTXs *txIndex          // shared with accountCache instances
+	estimator SpendingEstimator // shared with accountCache instances
+	// rewards is nil unless expected rewards of own accounts are modeled, see expectedRewards.
+	rewards *expectedRewards // shared with accountCache instances
+	// dormantLayers is the number of layers after which accounts that only have DB-only txs
+	// are evicted from pending. Zero disables the eviction.
+	dormantLayers uint32
+	// localDB is nil unless the latency of transactions is recorded, see recordProposed and recordApplied.
+	localDB sql.LocalDatabase
 }
 
 func NewCache(s stateFunc, logger *zap.Logger) *Cache {
-	return &Cache{
+	c := &Cache{
 		logger:    logger,
 		stateF:    s,
-		pending:   make(map[types.Address]*accountCache),
-		cachedTXs: make(map[types.TransactionID]*NanoTX),
+		cachedTXs: newTXIndex(),
+	}
+	for i := range c.shards {
+		c.shards[i].reset()
 	}
+	return c
 }
 
-func groupTXsByPrincipal(logger *zap.Logger, mtxs []*types.MeshTransaction) map[types.Address]map[uint64][]*NanoTX {
+func groupTXsByPrincipal(
+	logger *zap.Logger,
+	mtxs []*types.MeshTransaction,
+	estimator SpendingEstimator,
+) map[types.Address]map[uint64][]*NanoTX {
 	byPrincipal := make(map[types.Address]map[uint64][]*NanoTX)
 	for _, mtx := range mtxs {
 		principal := mtx.Principal
@@ -434,7 +480,7 @@ func groupTXsByPrincipal(logger *zap.Logger, mtxs []*types.MeshTransaction) map[
 			byPrincipal[principal][mtx.Nonce] = make([]*NanoTX, 0, maxTXsPerNonce)
 		}
 		if len(byPrincipal[principal][mtx.Nonce]) < maxTXsPerNonce {
-			byPrincipal[principal][mtx.Nonce] = append(byPrincipal[principal][mtx.Nonce], NewNanoTX(mtx))
+			byPrincipal[principal][mtx.Nonce] = append(byPrincipal[principal][mtx.Nonce], newNanoTX(mtx, estimator))
 		} else {
 			logger.Debug("too many txs in same nonce. ignoring tx",
 				zap.Stringer("tx_id", mtx.ID),
@@ -484,21 +530,23 @@ func (c *Cache) BuildFromTXs(rst []*types.MeshTransaction, blockSeed []byte) err
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.pending = make(map[types.Address]*accountCache)
+	for i := range c.shards {
+		c.shards[i].reset()
+	}
 	toCleanup := make(map[types.Address]struct{})
 	for _, tx := range rst {
 		toCleanup[tx.Principal] = struct{}{}
 	}
 	defer c.cleanupAccounts(maps.Keys(toCleanup)...)
 
-	byPrincipal := groupTXsByPrincipal(c.logger, rst)
+	byPrincipal := groupTXsByPrincipal(c.logger, rst, c.estimator)
 	acctsAdded := 0
 	for principal, nonce2TXs := range byPrincipal {
-		c.createAcctIfNotPresent(principal)
-		if err := c.pending[principal].addBatch(c.logger, nonce2TXs, blockSeed); err != nil {
+		acct := c.createAcctIfNotPresent(principal)
+		if err := acct.addBatch(c.logger, nonce2TXs, blockSeed); err != nil {
 			return err
 		}
-		if c.pending[principal].shouldEvict() {
+		if acct.shouldEvict() {
 			c.logger.Debug("account has pending txs but none feasible",
 				zap.Stringer("address", principal),
 				zap.Array("batch", zapcore.ArrayMarshalerFunc(func(encoder zapcore.ArrayEncoder) error {
@@ -518,26 +566,35 @@ func (c *Cache) BuildFromTXs(rst []*types.MeshTransaction, blockSeed []byte) err
 	return nil
 }
 
-func (c *Cache) createAcctIfNotPresent(addr types.Address) {
-	if _, ok := c.pending[addr]; !ok {
+func (c *Cache) createAcctIfNotPresent(addr types.Address) *accountCache {
+	s := c.shard(addr)
+	if _, ok := s.pending[addr]; !ok {
+		_, wasDormant := s.dormant[addr]
+		delete(s.dormant, addr)
 		nextNonce, balance := c.stateF(addr)
 		c.logger.Debug("created account with nonce/balance",
 			zap.Stringer("address", addr),
 			zap.Uint64("nonce", nextNonce),
 			zap.Uint64("balance", balance),
 		)
-		c.pending[addr] = &accountCache{
+		s.pending[addr] = &accountCache{
 			addr:         addr,
 			startNonce:   nextNonce,
 			startBalance: balance,
 			txsByNonce:   list.New(),
 			cachedTXs:    c.cachedTXs,
+			estimator:    c.estimator,
+			rewards:      c.rewards,
+			// txs of an evicted account are reconsidered after the next layer is applied
+			moreInDB: wasDormant,
 		}
 	}
+	return s.pending[addr]
 }
 
 func (c *Cache) MoreInDB(addr types.Address) bool {
-	acct, ok := c.pending[addr]
+	defer c.lockAccount(addr)()
+	acct, ok := c.account(addr)
 	if !ok {
 		return false
 	}
@@ -546,8 +603,8 @@ func (c *Cache) MoreInDB(addr types.Address) bool {
 
 func (c *Cache) cleanupAccounts(accounts ...types.Address) {
 	for _, addr := range accounts {
-		if _, ok := c.pending[addr]; ok && c.pending[addr].shouldEvict() {
-			delete(c.pending, addr)
+		if acct, ok := c.account(addr); ok && acct.shouldEvict() {
+			delete(c.shard(addr).pending, addr)
 		}
 	}
 }
@@ -559,13 +616,12 @@ func acceptable(err error) bool {
 }
 
 func (c *Cache) Add(ctx context.Context, db sql.StateDatabase, tx *types.Transaction, received time.Time) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
 	principal := tx.Principal
-	c.createAcctIfNotPresent(principal)
+	defer c.lockAccount(principal)()
+	acct := c.createAcctIfNotPresent(principal)
 	defer c.cleanupAccounts(principal)
 	logger := c.logger.With(log.ZContext(ctx), zap.Stringer("address", principal))
-	if err := c.pending[principal].add(logger, tx, received); !acceptable(err) {
+	if err := acct.add(logger, tx, received); !acceptable(err) {
 		return err
 	}
 	mempoolTxCount.WithLabelValues(accepted).Inc()
@@ -574,20 +630,43 @@ func (c *Cache) Add(ctx context.Context, db sql.StateDatabase, tx *types.Transac
 
 // Get gets a transaction from the cache.
 func (c *Cache) Get(tid types.TransactionID) *NanoTX {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.cachedTXs[tid]
+	return c.cachedTXs.get(tid)
 }
 
-// Has returns true if transaction exists in the cache.
-func (c *Cache) Has(tid types.TransactionID) bool {
+// getWithLayer gets a transaction from the cache with the layer of the proposal or block
+// that includes it, the layer is read under the cache lock as it's updated concurrently.
+func (c *Cache) getWithLayer(tid types.TransactionID) (*NanoTX, types.LayerID) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.has(tid)
+	ntx := c.cachedTXs.get(tid)
+	if ntx == nil {
+		return nil, 0
+	}
+	return ntx, ntx.Layer
 }
 
-func (c *Cache) has(tid types.TransactionID) bool {
-	return c.cachedTXs[tid] != nil
+// IsNonceGapped returns true if the cached transaction can't be selected into a proposal
+// until transactions with lower nonces from the same principal are received.
+func (c *Cache) IsNonceGapped(ntx *NanoTX) bool {
+	defer c.lockAccount(ntx.Principal)()
+	acct, ok := c.account(ntx.Principal)
+	if !ok {
+		return false
+	}
+	expected := acct.startNonce
+	for e := acct.txsByNonce.Front(); e != nil; e = e.Next() {
+		nonce := e.Value.(*candidate).nonce()
+		if nonce >= ntx.Nonce || nonce != expected {
+			break
+		}
+		expected++
+	}
+	return expected != ntx.Nonce
+}
+
+// Has returns true if transaction exists in the cache.
+func (c *Cache) Has(tid types.TransactionID) bool {
+	return c.cachedTXs.get(tid) != nil
 }
 
 // LinkTXsWithProposal associates the transactions to a proposal.
@@ -603,6 +682,7 @@ func (c *Cache) LinkTXsWithProposal(
 	if err := addToProposal(db, lid, pid, tids); err != nil {
 		return fmt.Errorf("linking txs to proposal: %w", err)
 	}
+	c.recordProposed(db, tids)
 	c.updateLayer(lid, types.EmptyBlockID, tids)
 	return nil
 }
@@ -633,11 +713,12 @@ func (c *Cache) updateLayer(lid types.LayerID, bid types.BlockID, tids []types.T
 	defer c.mu.Unlock()
 
 	for _, ID := range tids {
-		if _, ok := c.cachedTXs[ID]; !ok {
+		ntx := c.cachedTXs.get(ID)
+		if ntx == nil {
 			// transaction is not considered best in its nonce group
 			return
 		}
-		c.cachedTXs[ID].UpdateLayerMaybe(lid, bid)
+		ntx.UpdateLayerMaybe(lid, bid)
 	}
 }
 
@@ -645,7 +726,7 @@ func (c *Cache) applyEmptyLayer(db sql.StateDatabase, lid types.LayerID) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for tid, ntx := range c.cachedTXs {
+	for tid, ntx := range c.cachedTXs.all() {
 		if ntx.Layer == lid {
 			nbid, nlid, err := getNextIncluded(db, tid, lid)
 			if err != nil {
@@ -654,6 +735,30 @@ func (c *Cache) applyEmptyLayer(db sql.StateDatabase, lid types.LayerID) error {
 			ntx.UpdateLayer(nbid, nlid)
 		}
 	}
+	// rewards expected from the blocks of the layer will never arrive
+	c.rewards.prune(lid)
+	if err := c.resetRewardAccounts(db, lid, nil); err != nil {
+		return err
+	}
+	return c.compactDormant(db, lid, nil)
+}
+
+// resetRewardAccounts reconsiders pending transactions of the own accounts after their expected
+// rewards changed, except for the skipped accounts that were reset already.
+func (c *Cache) resetRewardAccounts(db sql.StateDatabase, lid types.LayerID, skip map[types.Address]struct{}) error {
+	if c.rewards == nil {
+		return nil
+	}
+	for addr := range c.rewards.accounts {
+		acct, ok := c.account(addr)
+		if _, skipped := skip[addr]; !ok || skipped {
+			continue
+		}
+		nextNonce, balance := c.stateF(addr)
+		if err := acct.resetAfterApply(c.logger, db, nextNonce, balance, lid); err != nil {
+			return fmt.Errorf("reset account %s: %w", addr, err)
+		}
+	}
 	return nil
 }
 
@@ -682,6 +787,9 @@ func (c *Cache) ApplyLayer(
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// rewards of the layer move from the expectation into the applied balance
+	c.rewards.prune(lid)
+
 	toCleanup := make(map[types.Address]struct{})
 	toReset := make(map[types.Address]struct{})
 	byPrincipal := make(map[types.Address]struct{})
@@ -703,7 +811,7 @@ func (c *Cache) ApplyLayer(
 	for _, rst := range results {
 		byPrincipal[rst.Principal] = struct{}{}
 		toCleanup[rst.Principal] = struct{}{}
-		if !c.has(rst.ID) {
+		if !c.Has(rst.ID) {
 			RawTxCount.WithLabelValues(updated).Inc()
 			if err := transactions.Add(db, &rst.Transaction, time.Now()); err != nil {
 				return err
@@ -713,13 +821,14 @@ func (c *Cache) ApplyLayer(
 			c.logger.Error("Failed to emit tx results", zap.Stringer("tx_id", rst.ID), zap.Error(err))
 		}
 	}
+	c.recordApplied(db, lid, results)
 
 	for _, tx := range ineffective {
 		if tx.TxHeader == nil {
 			logger.Warn("tx header not parsed", zap.Stringer("tx_id", tx.ID))
 			continue
 		}
-		if !c.has(tx.ID) {
+		if !c.Has(tx.ID) {
 			RawTxCount.WithLabelValues(updated).Inc()
 			if err := transactions.Add(db, &tx, time.Now()); err != nil {
 				return err
@@ -730,7 +839,7 @@ func (c *Cache) ApplyLayer(
 		if _, ok := byPrincipal[tx.Principal]; ok {
 			continue
 		}
-		if _, ok := c.pending[tx.Principal]; !ok {
+		if _, ok := c.account(tx.Principal); !ok {
 			continue
 		}
 		toReset[tx.Principal] = struct{}{}
@@ -738,7 +847,7 @@ func (c *Cache) ApplyLayer(
 	defer c.cleanupAccounts(maps.Keys(toCleanup)...)
 
 	for principal := range byPrincipal {
-		c.createAcctIfNotPresent(principal)
+		acct := c.createAcctIfNotPresent(principal)
 		nextNonce, balance := c.stateF(principal)
 		logger.Debug("new account nonce/balance",
 			zap.Stringer("address", principal),
@@ -746,7 +855,7 @@ func (c *Cache) ApplyLayer(
 			zap.Uint64("balance", balance),
 		)
 		t0 := time.Now()
-		if err := c.pending[principal].resetAfterApply(logger, db, nextNonce, balance, lid); err != nil {
+		if err := acct.resetAfterApply(logger, db, nextNonce, balance, lid); err != nil {
 			logger.Error("failed to reset cache for principal",
 				zap.Stringer("address", principal),
 				zap.Error(err),
@@ -756,18 +865,21 @@ func (c *Cache) ApplyLayer(
 		acctResetDuration.Observe(float64(time.Since(t0)))
 	}
 
-	for principal, accCache := range c.pending {
-		if _, ok := toCleanup[principal]; ok {
-			continue
-		}
-		if accCache.moreInDB {
-			toReset[principal] = struct{}{}
+	for i := range c.shards {
+		for principal, accCache := range c.shards[i].pending {
+			if _, ok := toCleanup[principal]; ok {
+				continue
+			}
+			if accCache.moreInDB {
+				toReset[principal] = struct{}{}
+			}
 		}
 	}
 	for principal := range toReset {
+		acct, _ := c.account(principal)
 		nextNonce, balance := c.stateF(principal)
 		t2 := time.Now()
-		if err := c.pending[principal].resetAfterApply(logger, db, nextNonce, balance, lid); err != nil {
+		if err := acct.resetAfterApply(logger, db, nextNonce, balance, lid); err != nil {
 			logger.Error("failed to reset cache for principal",
 				zap.Stringer("address", principal),
 				zap.Error(err),
@@ -776,16 +888,105 @@ func (c *Cache) ApplyLayer(
 		}
 		acctResetDuration.Observe(float64(time.Since(t2)))
 	}
-	return nil
+	for principal := range toReset {
+		byPrincipal[principal] = struct{}{}
+	}
+	if err := c.resetRewardAccounts(db, lid, byPrincipal); err != nil {
+		return err
+	}
+	touched := make(map[types.Address]struct{})
+	for _, rst := range results {
+		for _, addr := range rst.Addresses {
+			touched[addr] = struct{}{}
+		}
+	}
+	return c.compactDormant(db, lid, touched)
 }
 
-func (c *Cache) RevertToLayer(db sql.StateDatabase, revertTo types.LayerID) error {
-	if err := undoLayers(db, revertTo.Add(1)); err != nil {
+// RevertToLayer reverts the cache to the state after applying `revertTo` layer.
+// If the number of undone layers is within maxIncrementalRevert, only accounts affected by
+// the undone layers are rebuilt. Otherwise the whole cache is rebuilt from database.
+// Touched must be collected before the state is reverted, as it includes recipients of the reverted rewards.
+func (c *Cache) RevertToLayer(db sql.StateDatabase, revertTo types.LayerID, touched []types.Address) error {
+	lastApplied, err := layers.GetLastApplied(db)
+	if err != nil {
+		return fmt.Errorf("cache: get last applied %w", err)
+	}
+	if lastApplied > revertTo && lastApplied.Difference(revertTo) > maxIncrementalRevert {
+		reverted, err := undoLayers(db, revertTo.Add(1))
+		if err != nil {
+			return err
+		}
+		if err := c.buildFromScratch(db); err != nil {
+			return fmt.Errorf("building from scratch after revert: %w", err)
+		}
+		c.undoApplied(revertTo.Add(1))
+		c.reportReverted(revertTo, reverted)
+		return nil
+	}
+
+	reverted, err := undoLayers(db, revertTo.Add(1))
+	if err != nil {
 		return err
 	}
+	if err := c.revertAccounts(db, revertTo, touched); err != nil {
+		return fmt.Errorf("incremental revert: %w", err)
+	}
+	c.undoApplied(revertTo.Add(1))
+	c.reportReverted(revertTo, reverted)
+	return nil
+}
+
+// reportReverted reports the transactions that returned to pending, so that wallets can tell
+// them apart from transactions that were never applied.
+func (c *Cache) reportReverted(revertTo types.LayerID, reverted []types.TransactionID) {
+	if len(reverted) == 0 {
+		return
+	}
+	revertedTxs.Add(float64(len(reverted)))
+	c.logger.Info("transactions returned to pending after revert",
+		zap.Uint32("revert_to", revertTo.Uint32()),
+		zap.Int("num_txs", len(reverted)),
+	)
+	events.ReportTxsReverted(events.EventTxsReverted{RevertTo: revertTo, Txs: reverted})
+}
+
+// revertAccounts rebuilds accounts affected by the undone layers and refreshes the layers
+// in which the remaining cached transactions are included.
+func (c *Cache) revertAccounts(db sql.StateDatabase, revertTo types.LayerID, touched []types.Address) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	logger := c.logger.With(zap.Uint32("revert_to", revertTo.Uint32()))
+	logger.Debug("reverting accounts", zap.Int("num_acct", len(touched)))
+	toReset := make(map[types.Address]struct{}, len(touched))
+	for _, addr := range touched {
+		toReset[addr] = struct{}{}
+	}
+	defer c.cleanupAccounts(touched...)
 
-	if err := c.buildFromScratch(db); err != nil {
-		return fmt.Errorf("building from scratch after revert: %w", err)
+	for tid, ntx := range c.cachedTXs.all() {
+		if _, ok := toReset[ntx.Principal]; ok {
+			continue
+		}
+		nlid, nbid, err := getNextIncluded(db, tid, revertTo)
+		if err != nil {
+			return err
+		}
+		ntx.UpdateLayer(nlid, nbid)
+	}
+	for _, addr := range touched {
+		acct := c.createAcctIfNotPresent(addr)
+		nextNonce, balance := c.stateF(addr)
+		t0 := time.Now()
+		if err := acct.resetAfterApply(logger, db, nextNonce, balance, revertTo); err != nil {
+			logger.Error("failed to reset cache for principal",
+				zap.Stringer("address", addr),
+				zap.Error(err),
+			)
+			return err
+		}
+		acctResetDuration.Observe(float64(time.Since(t0)))
 	}
 	return nil
 }
@@ -793,13 +994,88 @@ func (c *Cache) RevertToLayer(db sql.StateDatabase, revertTo types.LayerID) erro
 // GetProjection returns the projected nonce and balance for an account, including
 // pending transactions that are paced in proposals/blocks but not yet applied to the state.
 func (c *Cache) GetProjection(addr types.Address) (uint64, uint64) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.lockAccount(addr)()
+
+	acct, ok := c.account(addr)
+	if !ok {
+		nonce, balance := c.stateF(addr)
+		return nonce, balance + c.rewards.incoming(addr)
+	}
+	return acct.nextNonce(), acct.availBalance()
+}
+
+// GetProjectionWithCertainty returns the projected nonce and balance for an account, including
+// only pending transactions that match the certainty.
+func (c *Cache) GetProjectionWithCertainty(addr types.Address, certainty types.ProjectionCertainty) (uint64, uint64) {
+	if certainty == types.ProjectMempool {
+		return c.GetProjection(addr)
+	}
+	defer c.lockAccount(addr)()
 
-	if _, ok := c.pending[addr]; !ok {
+	acct, ok := c.account(addr)
+	switch {
+	case !ok:
 		return c.stateF(addr)
+	case certainty == types.ProjectApplied:
+		return acct.startNonce, acct.startBalance
+	}
+	return acct.packedProjection()
+}
+
+// GetNonceProjection returns the nonces of an account used by pending transactions and the next
+// usable nonce. Unlike GetProjection it also accounts for pending transactions that are only in
+// the database, for those the earliest received transaction of a nonce is used.
+func (c *Cache) GetNonceProjection(db sql.Executor, addr types.Address) (*types.NonceProjection, error) {
+	defer c.lockAccount(addr)()
+
+	rst := &types.NonceProjection{}
+	acct, ok := c.account(addr)
+	if ok {
+		rst.Applied, rst.AppliedBalance = acct.startNonce, acct.startBalance
+	} else {
+		rst.Applied, rst.AppliedBalance = c.stateF(addr)
+	}
+	rst.Next, rst.Balance = rst.Applied, rst.AppliedBalance+c.rewards.incoming(addr)
+	if ok {
+		for e := acct.txsByNonce.Front(); e != nil; e = e.Next() {
+			cand := e.Value.(*candidate)
+			rst.Pending = append(rst.Pending, types.PendingNonce{
+				Nonce:   cand.nonce(),
+				ID:      cand.id(),
+				Balance: cand.postBalance,
+				Mempool: true,
+			})
+			rst.Next, rst.Balance = cand.nonce()+1, cand.postBalance
+		}
+	}
+
+	mtxs, err := transactions.GetAcctPendingFromNonce(db, addr, rst.Next)
+	if err != nil {
+		return nil, fmt.Errorf("account pending txs: %w", err)
+	}
+	next, balance := rst.Next, rst.Balance
+	for _, mtx := range mtxs {
+		if mtx.Nonce < next {
+			// transactions are ordered by nonce and received time, keep the earliest one.
+			continue
+		}
+		spending := newNanoTX(mtx, c.estimator).MaxSpending()
+		if spending > balance {
+			balance = 0
+		} else {
+			balance -= spending
+		}
+		next = mtx.Nonce + 1
+		rst.Pending = append(rst.Pending, types.PendingNonce{
+			Nonce:   mtx.Nonce,
+			ID:      mtx.ID,
+			Balance: balance,
+		})
+		if rst.Next == mtx.Nonce {
+			rst.Next, rst.Balance = next, balance
+		}
 	}
-	return c.pending[addr].nextNonce(), c.pending[addr].availBalance()
+	return rst, nil
 }
 
 // GetMempool returns all the transactions that eligible for a proposal/block.
@@ -808,11 +1084,13 @@ func (c *Cache) GetMempool() map[types.Address][]*NanoTX {
 	defer c.mu.Unlock()
 
 	all := make(map[types.Address][]*NanoTX)
-	c.logger.Debug("cache has pending accounts", zap.Int("num_acct", len(c.pending)))
-	for addr, accCache := range c.pending {
-		txs := accCache.getMempool(c.logger.With(zap.Stringer("address", addr)))
-		if len(txs) > 0 {
-			all[addr] = txs
+	c.logger.Debug("cache has pending accounts", zap.Int("num_acct", c.numPending()))
+	for i := range c.shards {
+		for addr, accCache := range c.shards[i].pending {
+			txs := accCache.getMempool(c.logger.With(zap.Stringer("address", addr)))
+			if len(txs) > 0 {
+				all[addr] = txs
+			}
 		}
 	}
 	return all
@@ -845,6 +1123,78 @@ func addToProposal(db sql.StateDatabase, lid types.LayerID, pid types.ProposalID
 	})
 }
 
+// recordProposed records in the local database when the transactions were included in a proposal first.
+// The latency is a local diagnostic, failing to record it is logged and doesn't fail the proposal.
+func (c *Cache) recordProposed(db sql.StateDatabase, tids []types.TransactionID) {
+	if c.localDB == nil {
+		return
+	}
+	proposed := time.Now()
+	var first []types.TransactionID
+	if err := c.localDB.WithTx(context.Background(), func(tx sql.Transaction) error {
+		for _, tid := range tids {
+			recorded, err := txlatency.SetProposed(tx, tid, proposed)
+			if err != nil {
+				return err
+			}
+			if recorded {
+				first = append(first, tid)
+			}
+		}
+		return nil
+	}); err != nil {
+		c.logger.Warn("failed to record proposal inclusion of transactions", zap.Error(err))
+		return
+	}
+	for _, tid := range first {
+		if received, err := transactions.GetReceived(db, tid); err == nil {
+			proposalInclusionLatency.Observe(proposed.Sub(received).Seconds())
+		}
+	}
+}
+
+// recordApplied records in the local database when the transactions were applied in the layer.
+// The latency is a local diagnostic, failing to record it is logged and doesn't fail the application.
+func (c *Cache) recordApplied(db sql.StateDatabase, lid types.LayerID, results []types.TransactionWithResult) {
+	if c.localDB == nil || len(results) == 0 {
+		return
+	}
+	applied := time.Now()
+	if err := c.localDB.WithTx(context.Background(), func(tx sql.Transaction) error {
+		for _, rst := range results {
+			if err := txlatency.SetApplied(tx, rst.ID, lid, applied); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		c.logger.Warn("failed to record application of transactions",
+			zap.Uint32("layer_id", lid.Uint32()),
+			zap.Error(err),
+		)
+		return
+	}
+	for _, rst := range results {
+		if received, err := transactions.GetReceived(db, rst.ID); err == nil {
+			applicationLatency.Observe(applied.Sub(received).Seconds())
+		}
+	}
+}
+
+// undoApplied forgets the application of transactions in the undone layers, starting with `from`,
+// and records them as reverted.
+func (c *Cache) undoApplied(from types.LayerID) {
+	if c.localDB == nil {
+		return
+	}
+	if err := txlatency.UndoLayers(c.localDB, from); err != nil {
+		c.logger.Warn("failed to undo application of transactions",
+			zap.Uint32("from", from.Uint32()),
+			zap.Error(err),
+		)
+	}
+}
+
 func addToBlock(db sql.StateDatabase, lid types.LayerID, bid types.BlockID, tids []types.TransactionID) error {
 	return db.WithTx(context.Background(), func(dbtx sql.Transaction) error {
 		for _, tid := range tids {
@@ -856,14 +1206,21 @@ func addToBlock(db sql.StateDatabase, lid types.LayerID, bid types.BlockID, tids
 	})
 }
 
-func undoLayers(db sql.StateDatabase, from types.LayerID) error {
-	return db.WithTx(context.Background(), func(dbtx sql.Transaction) error {
-		err := transactions.UndoLayers(dbtx, from)
+// undoLayers undoes the layers starting with `from` and returns the transactions applied in them.
+func undoLayers(db sql.StateDatabase, from types.LayerID) ([]types.TransactionID, error) {
+	var reverted []types.TransactionID
+	err := db.WithTx(context.Background(), func(dbtx sql.Transaction) error {
+		var err error
+		reverted, err = transactions.AppliedFrom(dbtx, from)
 		if err != nil {
+			return err
+		}
+		if err := transactions.UndoLayers(dbtx, from); err != nil {
 			return fmt.Errorf("undo %w", err)
 		}
 		return nil
 	})
+	return reverted, err
 }
 
 func getNextIncluded(
@@ -6,14 +6,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/signing"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql"
+	"github.com/spacemeshos/go-spacemesh/sql/localsql/txlatency"
+	"github.com/spacemeshos/go-spacemesh/sql/rewards"
 	"github.com/spacemeshos/go-spacemesh/sql/statesql"
 	"github.com/spacemeshos/go-spacemesh/sql/transactions"
 )
@@ -23,6 +28,15 @@ type testCache struct {
 	db sql.StateDatabase
 }
 
+// revertToLayer reverts the cache like the executor does, with the accounts affected by the undone layers.
+func (tc *testCache) revertToLayer(revertTo types.LayerID) error {
+	touched, err := transactions.AddressesAppliedFrom(tc.db, revertTo.Add(1))
+	if err != nil {
+		return err
+	}
+	return tc.RevertToLayer(tc.db, revertTo, touched)
+}
+
 type testAcct struct {
 	signer         *signing.EdSigner
 	principal      types.Address
@@ -344,7 +358,7 @@ func TestCache_Account_HappyFlow(t *testing.T) {
 	revertTo := lid.Sub(1)
 	ta.nonce -= 2
 	ta.balance = defaultBalance
-	require.NoError(t, tc.RevertToLayer(tc.db, revertTo))
+	require.NoError(t, tc.revertToLayer(revertTo))
 	for _, tid := range addedToBlock {
 		checkTX(t, tc.Cache, tid, lid, bid)
 	}
@@ -1241,8 +1255,48 @@ func TestCache_LinkTXsWithBlock_MultipleLayers(t *testing.T) {
 	checkMempoolSize(t, tc.Cache, totalNumTXs-len(tids0))
 }
 
+func TestCache_Latency(t *testing.T) {
+	tc, ta := createSingleAccountTestCache(t)
+	tc.localDB = localsql.InMemoryTest(t)
+	mtxs := genAndSaveTXs(t, tc.db, ta.signer, ta.nonce, ta.nonce+1, time.Now())
+	buildSingleAccountCache(t, tc, ta, mtxs)
+	lid := types.LayerID(97)
+	require.NoError(t, layers.SetApplied(tc.db, lid.Sub(1), types.RandomBlockID()))
+
+	require.NoError(t, tc.LinkTXsWithProposal(tc.db, lid, types.ProposalID{1}, []types.TransactionID{mtxs[0].ID}))
+	latency, err := txlatency.Get(tc.localDB, mtxs[0].ID)
+	require.NoError(t, err)
+	require.False(t, latency.Proposed.IsZero())
+	require.True(t, latency.Applied.IsZero())
+	_, err = txlatency.Get(tc.localDB, mtxs[1].ID)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	bid := types.BlockID{1, 2, 3}
+	ta.nonce++
+	ta.balance -= mtxs[0].Spending()
+	require.NoError(t, tc.ApplyLayer(context.Background(), tc.db, lid, bid,
+		makeResults(lid, bid, mtxs[0].Transaction), nil))
+	applied, err := txlatency.Get(tc.localDB, mtxs[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, latency.Proposed, applied.Proposed)
+	require.False(t, applied.Applied.IsZero())
+	require.Equal(t, lid, applied.Layer)
+
+	ta.nonce--
+	ta.balance += mtxs[0].Spending()
+	require.NoError(t, tc.revertToLayer(lid.Sub(1)))
+	reverted, err := txlatency.Get(tc.localDB, mtxs[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, txlatency.Latency{Proposed: latency.Proposed, Reverted: lid}, reverted)
+}
+
 func TestCache_ApplyLayerAndRevert(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub := events.SubscribeTxsReverted()
+
 	tc, accounts := createCache(t, 100)
+	tc.localDB = localsql.InMemoryTest(t)
 	mtxsByAccount := buildSmallCache(t, tc, accounts, 10)
 	lid := types.LayerID(97)
 	require.NoError(t, layers.SetApplied(tc.db, lid.Sub(1), types.RandomBlockID()))
@@ -1288,9 +1342,114 @@ func TestCache_ApplyLayerAndRevert(t *testing.T) {
 			accounts[principal].balance += mtxs[1].Spending()
 		}
 	}
-	require.NoError(t, tc.RevertToLayer(tc.db, lid.Sub(1)))
+	before := testutil.ToFloat64(revertedTxs)
+	require.NoError(t, tc.revertToLayer(lid.Sub(1)))
 	checkMempool(t, tc.Cache, expectedMempool)
 	checkTXStateFromDB(t, tc.db, allPending, types.MEMPOOL)
+	require.Equal(t, before+float64(len(appliedMTXs)), testutil.ToFloat64(revertedTxs))
+
+	select {
+	case ev := <-sub.Out():
+		reverted := ev.(events.EventTxsReverted)
+		require.Equal(t, lid.Sub(1), reverted.RevertTo)
+		expected := make([]types.TransactionID, 0, len(appliedMTXs))
+		for _, mtx := range appliedMTXs {
+			expected = append(expected, mtx.ID)
+			latency, err := txlatency.Get(tc.localDB, mtx.ID)
+			require.NoError(t, err)
+			require.Equal(t, lid, latency.Reverted)
+		}
+		require.ElementsMatch(t, expected, reverted.Txs)
+	case <-time.After(time.Second):
+		require.Fail(t, "reverted txs are not reported")
+	}
+}
+
+func TestCache_RevertOnlyTouchedAccounts(t *testing.T) {
+	for _, tc := range []struct {
+		desc  string
+		depth uint32
+	}{
+		{desc: "incremental", depth: 1},
+		{desc: "from scratch", depth: maxIncrementalRevert + 1},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, accounts := createCache(t, 10)
+			mtxsByAccount := buildSmallCache(t, c, accounts, 10)
+			lid := types.LayerID(97)
+			require.NoError(t, layers.SetApplied(c.db, lid.Sub(1), types.RandomBlockID()))
+			later := lid.Add(tc.depth)
+			bid := types.BlockID{1, 2, 3}
+
+			var (
+				applied   []types.TransactionWithResult
+				untouched []types.TransactionID
+			)
+			expectedMempool := make(map[types.Address][]*types.MeshTransaction)
+			count := 0
+			for principal, mtxs := range mtxsByAccount {
+				expectedMempool[principal] = mtxs
+				count++
+				if count%2 == 0 {
+					// untouched accounts have the first tx in a block of a later layer
+					require.NoError(t, c.LinkTXsWithBlock(c.db, later, bid, []types.TransactionID{mtxs[0].ID}))
+					untouched = append(untouched, mtxs[0].ID)
+					delete(expectedMempool, principal)
+					if len(mtxs) > 1 {
+						expectedMempool[principal] = mtxs[1:]
+					}
+					continue
+				}
+				applied = append(applied, makeResults(lid, bid, mtxs[0].Transaction)...)
+				accounts[principal].nonce++
+				accounts[principal].balance -= mtxs[0].Spending()
+			}
+			require.NoError(t, c.ApplyLayer(context.Background(), c.db, lid, bid, applied, nil))
+			require.NoError(t, layers.SetApplied(c.db, lid.Add(tc.depth-1), bid))
+
+			for _, rst := range applied {
+				accounts[rst.Principal].nonce--
+				accounts[rst.Principal].balance += rst.Spending()
+			}
+			require.NoError(t, c.revertToLayer(lid.Sub(1)))
+			checkMempool(t, c.Cache, expectedMempool)
+			for _, tid := range untouched {
+				checkTX(t, c.Cache, tid, later, bid)
+			}
+			for _, rst := range applied {
+				checkTX(t, c.Cache, rst.ID, 0, types.EmptyBlockID)
+			}
+		})
+	}
+}
+
+func TestCache_RevertRewardRecipients(t *testing.T) {
+	c, accounts := createCache(t, 10)
+	mtxsByAccount := buildSmallCache(t, c, accounts, 10)
+	lid := types.LayerID(97)
+	require.NoError(t, layers.SetApplied(c.db, lid, types.RandomBlockID()))
+
+	expectedMempool := make(map[types.Address][]*types.MeshTransaction)
+	var recipient types.Address
+	for principal, mtxs := range mtxsByAccount {
+		expectedMempool[principal] = mtxs
+		recipient = principal
+	}
+	// the recipient has no transactions in the undone layer, only a reward.
+	// once the reward is reverted its pending txs are not feasible anymore
+	require.NoError(t, rewards.Add(c.db, &types.Reward{
+		Layer:       lid,
+		Coinbase:    recipient,
+		SmesherID:   types.RandomNodeID(),
+		TotalReward: accounts[recipient].balance,
+		LayerReward: accounts[recipient].balance,
+	}))
+	accounts[recipient].balance = 0
+	delete(expectedMempool, recipient)
+
+	require.NoError(t, c.revertToLayer(lid.Sub(1)))
+	checkProjection(t, c.Cache, recipient, accounts[recipient].nonce, 0)
+	checkMempool(t, c.Cache, expectedMempool)
 }
 
 func TestCache_ApplyLayerWithSkippedTXs(t *testing.T) {
@@ -1405,3 +1564,20 @@ func TestCache_GetProjection(t *testing.T) {
 		require.Equal(t, expectedBalance, balance)
 	}
 }
+
+func TestCache_Account_Add_SpendingEstimator(t *testing.T) {
+	tc, ta := createSingleAccountTestCache(t)
+	tc.estimator = spendingEstimatorFunc(func(header *types.TxHeader) (uint64, bool) {
+		return header.MaxSpend, true
+	})
+	buildSingleAccountCache(t, tc, ta, nil)
+
+	// by default the transaction is infeasible as it can't pay the fee on top of max spend
+	mtx := &types.MeshTransaction{
+		Transaction: *newTx(t, ta.nonce, ta.balance, defaultFee, ta.signer),
+		Received:    time.Now(),
+	}
+	require.NoError(t, tc.Add(context.Background(), tc.db, &mtx.Transaction, mtx.Received))
+	checkTX(t, tc.Cache, mtx.ID, 0, types.EmptyBlockID)
+	checkProjection(t, tc.Cache, ta.principal, ta.nonce+1, 0)
+}
@@ -0,0 +1,251 @@
+package cachebench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+var workloads = []struct {
+	desc string
+	opts []Opt
+}{
+	{
+		desc: "sequential",
+		opts: []Opt{WithAccounts(100), WithTXsPerAccount(10)},
+	},
+	{
+		desc: "many accounts",
+		opts: []Opt{WithAccounts(1000), WithTXsPerAccount(2), WithFees(UniformFee(1, 100))},
+	},
+	{
+		desc: "shuffled",
+		opts: []Opt{WithAccounts(100), WithTXsPerAccount(10), WithNonces(ShuffledNonces())},
+	},
+	{
+		desc: "gapped",
+		opts: []Opt{WithAccounts(100), WithTXsPerAccount(10), WithNonces(GappedNonces(0.1))},
+	},
+	{
+		desc: "duplicates",
+		opts: []Opt{
+			WithAccounts(100),
+			WithTXsPerAccount(10),
+			WithNonces(DuplicateNonces(0.2)),
+			WithFees(UniformFee(1, 100)),
+		},
+	},
+}
+
+func newHarness(tb testing.TB, w *Workload) *Harness {
+	tb.Helper()
+	h := NewHarness(w, zap.NewNop())
+	tb.Cleanup(func() { require.NoError(tb, h.Close()) })
+	return h
+}
+
+func TestHarness(t *testing.T) {
+	for _, tc := range workloads {
+		t.Run(tc.desc, func(t *testing.T) {
+			w := Generate(tc.opts...)
+			h := newHarness(t, w)
+			require.NoError(t, h.Load(context.Background()))
+			require.NotEmpty(t, h.Cache.GetMempool())
+			n, err := h.ApplyLayer(context.Background())
+			require.NoError(t, err)
+			require.NotZero(t, n)
+			require.LessOrEqual(t, n, len(w.TXs))
+		})
+	}
+}
+
+func mempoolIDs(h *Harness) map[types.Address][]types.TransactionID {
+	ids := make(map[types.Address][]types.TransactionID)
+	for addr, ntxs := range h.Cache.GetMempool() {
+		for _, ntx := range ntxs {
+			ids[addr] = append(ids[addr], ntx.ID)
+		}
+	}
+	return ids
+}
+
+func TestHarness_Concurrent(t *testing.T) {
+	for _, tc := range workloads {
+		t.Run(tc.desc, func(t *testing.T) {
+			w := Generate(tc.opts...)
+			sequential := newHarness(t, w)
+			require.NoError(t, sequential.Load(context.Background()))
+
+			h := newHarness(t, w)
+			var eg errgroup.Group
+			// readers do a bounded amount of work, so that they don't starve the writers
+			// when there are fewer cpus than goroutines
+			for i := range 4 {
+				eg.Go(func() error {
+					for range len(w.TXs) {
+						tx := w.TXs[rand.Intn(len(w.TXs))]
+						h.Cache.Has(tx.ID)
+						h.Cache.GetProjection(tx.Principal)
+						if i == 0 {
+							h.Cache.GetMempool()
+						}
+					}
+					return nil
+				})
+			}
+			require.NoError(t, h.LoadParallel(context.Background(), 8))
+			require.NoError(t, eg.Wait())
+			require.Equal(t, mempoolIDs(sequential), mempoolIDs(h))
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	w := Generate(WithAccounts(10), WithTXsPerAccount(5), WithSeed(7))
+	require.Len(t, w.Accounts, 10)
+	require.Len(t, w.TXs, 50)
+	require.Equal(t, w, Generate(WithAccounts(10), WithTXsPerAccount(5), WithSeed(7)))
+	for i, tx := range w.TXs[:10] {
+		require.Equal(t, w.Accounts[i].Address, tx.Principal)
+		require.Equal(t, w.Accounts[i].Nonce, tx.Nonce)
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	for _, tc := range workloads {
+		b.Run(tc.desc, func(b *testing.B) {
+			w := Generate(tc.opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				h := NewHarness(w, zap.NewNop())
+				b.StartTimer()
+				if err := h.Load(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+				h.Close()
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(w.TXs)), "ns/tx")
+		})
+	}
+}
+
+func BenchmarkAddParallel(b *testing.B) {
+	w := Generate(WithAccounts(1000), WithTXsPerAccount(10), WithFees(UniformFee(1, 100)))
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				h := NewHarness(w, zap.NewNop())
+				b.StartTimer()
+				if err := h.LoadParallel(context.Background(), workers); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+				h.Close()
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(w.TXs)), "ns/tx")
+		})
+	}
+}
+
+// BenchmarkReadContention measures reads of the cache while the mempool is built concurrently,
+// which holds the whole cache the same way applying a layer does.
+func BenchmarkReadContention(b *testing.B) {
+	w := Generate(WithAccounts(1000), WithTXsPerAccount(10))
+	h := newHarness(b, w)
+	require.NoError(b, h.Load(context.Background()))
+	for _, barrier := range []bool{false, true} {
+		b.Run(fmt.Sprintf("barrier=%v", barrier), func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			var eg errgroup.Group
+			if barrier {
+				eg.Go(func() error {
+					for ctx.Err() == nil {
+						h.Cache.GetMempool()
+					}
+					return nil
+				})
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				rng := rand.New(rand.NewSource(rand.Int63()))
+				for pb.Next() {
+					tx := w.TXs[rng.Intn(len(w.TXs))]
+					h.Cache.Has(tx.ID)
+					h.Cache.GetProjection(tx.Principal)
+				}
+			})
+			b.StopTimer()
+			cancel()
+			require.NoError(b, eg.Wait())
+		})
+	}
+}
+
+func BenchmarkGetMempool(b *testing.B) {
+	for _, tc := range workloads {
+		b.Run(tc.desc, func(b *testing.B) {
+			h := newHarness(b, Generate(tc.opts...))
+			require.NoError(b, h.Load(context.Background()))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.Cache.GetMempool()
+			}
+		})
+	}
+}
+
+func BenchmarkApplyLayer(b *testing.B) {
+	for _, tc := range workloads {
+		b.Run(tc.desc, func(b *testing.B) {
+			w := Generate(tc.opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				h := NewHarness(w, zap.NewNop())
+				if err := h.Load(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				if _, err := h.ApplyLayer(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+				h.Close()
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func ExampleHarness() {
+	w := Generate(WithAccounts(10), WithTXsPerAccount(3))
+	h := NewHarness(w, zap.NewNop())
+	defer h.Close()
+	if err := h.Load(context.Background()); err != nil {
+		panic(err)
+	}
+	n, err := h.ApplyLayer(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(n)
+	// Output: 30
+}
@@ -0,0 +1,129 @@
+package cachebench
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+	"github.com/spacemeshos/go-spacemesh/txs"
+)
+
+// Harness loads a Workload into a txs.Cache backed by an in-memory state database.
+type Harness struct {
+	DB    sql.StateDatabase
+	Cache *txs.Cache
+
+	workload *Workload
+	byID     map[types.TransactionID]*types.Transaction
+	accounts map[types.Address]*Account
+	applied  types.LayerID
+}
+
+// NewHarness creates a Harness with an empty cache and the initial account state
+// from the workload.
+func NewHarness(w *Workload, logger *zap.Logger) *Harness {
+	h := &Harness{
+		DB:       statesql.InMemory(),
+		workload: w,
+		byID:     make(map[types.TransactionID]*types.Transaction, len(w.TXs)),
+		accounts: make(map[types.Address]*Account, len(w.Accounts)),
+	}
+	for i := range w.Accounts {
+		acct := w.Accounts[i]
+		h.accounts[acct.Address] = &acct
+	}
+	for _, tx := range w.TXs {
+		h.byID[tx.ID] = tx
+	}
+	h.Cache = txs.NewCache(h.state, logger)
+	return h
+}
+
+// Close closes the underlying database.
+func (h *Harness) Close() error {
+	return h.DB.Close()
+}
+
+func (h *Harness) state(addr types.Address) (uint64, uint64) {
+	acct, ok := h.accounts[addr]
+	if !ok {
+		return 0, 0
+	}
+	return acct.Nonce, acct.Balance
+}
+
+// Load adds every transaction of the workload to the cache.
+func (h *Harness) Load(ctx context.Context) error {
+	received := time.Now()
+	for _, tx := range h.workload.TXs {
+		if err := h.Cache.Add(ctx, h.DB, tx, received); err != nil {
+			return fmt.Errorf("add tx %s: %w", tx.ID, err)
+		}
+	}
+	return nil
+}
+
+// LoadParallel adds every transaction of the workload to the cache from the given number
+// of workers. Transactions of an account are added by the same worker in the workload order.
+func (h *Harness) LoadParallel(ctx context.Context, workers int) error {
+	byWorker := make([][]*types.Transaction, workers)
+	for _, tx := range h.workload.TXs {
+		i := int(binary.BigEndian.Uint32(tx.Principal[types.AddressLength-4:])) % workers
+		byWorker[i] = append(byWorker[i], tx)
+	}
+	var eg errgroup.Group
+	received := time.Now()
+	for _, txs := range byWorker {
+		eg.Go(func() error {
+			for _, tx := range txs {
+				if err := h.Cache.Add(ctx, h.DB, tx, received); err != nil {
+					return fmt.Errorf("add tx %s: %w", tx.ID, err)
+				}
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// ApplyLayer applies the next layer with a block that includes all transactions
+// currently in the mempool. Returns the number of applied transactions.
+func (h *Harness) ApplyLayer(ctx context.Context) (int, error) {
+	lid := h.applied.Add(1)
+	bid := types.RandomBlockID()
+	var results []types.TransactionWithResult
+	for addr, ntxs := range h.Cache.GetMempool() {
+		acct := h.accounts[addr]
+		for _, ntx := range ntxs {
+			tx, ok := h.byID[ntx.ID]
+			if !ok {
+				return 0, fmt.Errorf("unknown tx %s in mempool", ntx.ID)
+			}
+			results = append(results, types.TransactionWithResult{
+				Transaction: *tx,
+				TransactionResult: types.TransactionResult{
+					Layer: lid,
+					Block: bid,
+				},
+			})
+			acct.Nonce = ntx.Nonce + 1
+			acct.Balance -= ntx.MaxSpending()
+		}
+	}
+	if err := h.Cache.ApplyLayer(ctx, h.DB, lid, bid, results, nil); err != nil {
+		return 0, fmt.Errorf("apply layer %s: %w", lid, err)
+	}
+	if err := layers.SetApplied(h.DB, lid, bid); err != nil {
+		return 0, fmt.Errorf("set applied %s: %w", lid, err)
+	}
+	h.applied = lid
+	return len(results), nil
+}
@@ -0,0 +1,231 @@
+// Package cachebench generates synthetic workloads for the transactions cache
+// and provides a harness to measure performance of the cache operations.
+package cachebench
+
+import (
+	"math/rand"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
+	"github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
+	"github.com/spacemeshos/go-spacemesh/signing"
+)
+
+const (
+	defaultGas     = 100
+	defaultAmount  = 10
+	defaultBalance = 1_000_000_000_000
+)
+
+// FeeDistribution returns a gas price for the next generated transaction.
+type FeeDistribution func(rng *rand.Rand) uint64
+
+// ConstantFee uses the same gas price for every transaction.
+func ConstantFee(fee uint64) FeeDistribution {
+	return func(*rand.Rand) uint64 {
+		return fee
+	}
+}
+
+// UniformFee picks gas price uniformly from [min, max].
+func UniformFee(min, max uint64) FeeDistribution {
+	if max < min {
+		panic("max fee is lower than min fee")
+	}
+	return func(rng *rand.Rand) uint64 {
+		return min + uint64(rng.Int63n(int64(max-min+1)))
+	}
+}
+
+// NonceDistribution returns nonces for n transactions of a single account,
+// starting from the next expected nonce. Nonces are returned in the order
+// transactions are added to the cache.
+type NonceDistribution func(rng *rand.Rand, next uint64, n int) []uint64
+
+// SequentialNonces generates consecutive nonces received in order.
+func SequentialNonces() NonceDistribution {
+	return func(_ *rand.Rand, next uint64, n int) []uint64 {
+		nonces := make([]uint64, n)
+		for i := range nonces {
+			nonces[i] = next + uint64(i)
+		}
+		return nonces
+	}
+}
+
+// ShuffledNonces generates consecutive nonces received in random order.
+func ShuffledNonces() NonceDistribution {
+	return func(rng *rand.Rand, next uint64, n int) []uint64 {
+		nonces := SequentialNonces()(rng, next, n)
+		rng.Shuffle(len(nonces), func(i, j int) {
+			nonces[i], nonces[j] = nonces[j], nonces[i]
+		})
+		return nonces
+	}
+}
+
+// GappedNonces skips a nonce with probability p, leaving a nonce gap
+// in the account.
+func GappedNonces(p float64) NonceDistribution {
+	return func(rng *rand.Rand, next uint64, n int) []uint64 {
+		nonces := make([]uint64, n)
+		for i := range nonces {
+			if rng.Float64() < p {
+				next++
+			}
+			nonces[i] = next
+			next++
+		}
+		return nonces
+	}
+}
+
+// DuplicateNonces reuses the previous nonce with probability p, so that the
+// cache has to choose between competing transactions with the same nonce.
+func DuplicateNonces(p float64) NonceDistribution {
+	return func(rng *rand.Rand, next uint64, n int) []uint64 {
+		nonces := make([]uint64, n)
+		for i := range nonces {
+			if i > 0 && rng.Float64() < p {
+				nonces[i] = nonces[i-1]
+				continue
+			}
+			nonces[i] = next
+			next++
+		}
+		return nonces
+	}
+}
+
+// Opt for configuring Workload.
+type Opt func(*config)
+
+// WithSeed configures seed for the random generator. By default 0 is used.
+func WithSeed(seed int64) Opt {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// WithAccounts configures the number of accounts.
+func WithAccounts(n int) Opt {
+	return func(c *config) {
+		c.accounts = n
+	}
+}
+
+// WithTXsPerAccount configures the number of transactions per account.
+func WithTXsPerAccount(n int) Opt {
+	return func(c *config) {
+		c.txsPerAccount = n
+	}
+}
+
+// WithFees configures distribution of gas prices.
+func WithFees(fees FeeDistribution) Opt {
+	return func(c *config) {
+		c.fees = fees
+	}
+}
+
+// WithNonces configures distribution of nonces.
+func WithNonces(nonces NonceDistribution) Opt {
+	return func(c *config) {
+		c.nonces = nonces
+	}
+}
+
+// WithBalance configures initial balance of every account.
+func WithBalance(balance uint64) Opt {
+	return func(c *config) {
+		c.balance = balance
+	}
+}
+
+type config struct {
+	seed          int64
+	accounts      int
+	txsPerAccount int
+	balance       uint64
+	fees          FeeDistribution
+	nonces        NonceDistribution
+}
+
+func defaults() config {
+	return config{
+		accounts:      100,
+		txsPerAccount: 10,
+		balance:       defaultBalance,
+		fees:          ConstantFee(1),
+		nonces:        SequentialNonces(),
+	}
+}
+
+// Account is a principal with its initial state.
+type Account struct {
+	Address types.Address
+	Nonce   uint64
+	Balance uint64
+}
+
+// Workload is a set of accounts and signed transactions spending from them.
+type Workload struct {
+	Accounts []Account
+	// TXs are ordered by the time they should be added to the cache.
+	// Transactions from different accounts are interleaved.
+	TXs []*types.Transaction
+}
+
+// Generate creates a new Workload.
+func Generate(opts ...Opt) *Workload {
+	conf := defaults()
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	rng := rand.New(rand.NewSource(conf.seed))
+	w := &Workload{
+		Accounts: make([]Account, 0, conf.accounts),
+		TXs:      make([]*types.Transaction, 0, conf.accounts*conf.txsPerAccount),
+	}
+	perAccount := make([][]*types.Transaction, conf.accounts)
+	for i := range perAccount {
+		signer, err := signing.NewEdSigner(signing.WithKeyFromRand(rng))
+		if err != nil {
+			panic(err)
+		}
+		acct := Account{
+			Address: types.GenerateAddress(signer.PublicKey().Bytes()),
+			Nonce:   uint64(rng.Intn(1000)),
+			Balance: conf.balance,
+		}
+		w.Accounts = append(w.Accounts, acct)
+		for _, nonce := range conf.nonces(rng, acct.Nonce, conf.txsPerAccount) {
+			perAccount[i] = append(perAccount[i], newTx(rng, signer, acct.Address, nonce, conf.fees(rng)))
+		}
+	}
+	for j := 0; j < conf.txsPerAccount; j++ {
+		for i := range perAccount {
+			if j < len(perAccount[i]) {
+				w.TXs = append(w.TXs, perAccount[i][j])
+			}
+		}
+	}
+	return w
+}
+
+func newTx(rng *rand.Rand, signer *signing.EdSigner, principal types.Address, nonce, fee uint64) *types.Transaction {
+	var dest types.Address
+	rng.Read(dest[:])
+	raw := wallet.Spend(signer.PrivateKey(), dest, defaultAmount, nonce, sdk.WithGasPrice(fee))
+	tx := &types.Transaction{
+		RawTx: types.NewRawTx(raw),
+		TxHeader: &types.TxHeader{
+			Principal: principal,
+			Nonce:     nonce,
+			MaxGas:    defaultGas,
+			GasPrice:  fee,
+			MaxSpend:  defaultAmount,
+		},
+	}
+	return tx
+}
@@ -22,6 +22,17 @@ import (
 type CSConfig struct {
 	BlockGasLimit     uint64
 	NumTXsPerProposal int
+	// Priority principals have their feasible txs ordered before all other txs in the mempool
+	// and always selected into proposals, up to NumTXsPerProposal.
+	Priority []types.Address
+	// RewardAccounts are the node's own coinbase accounts. Rewards that they expect from blocks
+	// that are not applied yet are added to their projected balance, so that their transactions
+	// don't flip feasibility until the rewards are applied. It is a local estimate, validity of
+	// transactions in blocks is still decided by the applied state.
+	RewardAccounts []types.Address
+	// DormantLayers is the number of layers after which accounts whose pending txs are all
+	// infeasible are evicted from the cache, until their state changes. Zero disables the eviction.
+	DormantLayers uint32
 }
 
 func defaultCSConfig() CSConfig {
@@ -48,6 +59,20 @@ func WithLogger(logger *zap.Logger) ConservativeStateOpt {
 	}
 }
 
+// WithLocalDB records the latency of transactions from the mempool to the chain in the local database.
+func WithLocalDB(db sql.LocalDatabase) ConservativeStateOpt {
+	return func(cs *ConservativeState) {
+		cs.localDB = db
+	}
+}
+
+// WithFeeFloorAdjustment adjusts the fee floor with the gas used by every applied layer.
+func WithFeeFloorAdjustment(floor *FeeFloor) ConservativeStateOpt {
+	return func(cs *ConservativeState) {
+		cs.feeFloor = floor
+	}
+}
+
 // ConservativeState provides the conservative version of the VM state by taking into accounts of
 // nonce and balances for pending transactions in un-applied blocks and mempool.
 type ConservativeState struct {
@@ -57,6 +82,12 @@ type ConservativeState struct {
 	cfg    CSConfig
 	db     sql.StateDatabase
 	cache  *Cache
+	// localDB is nil unless the latency of transactions is recorded.
+	localDB sql.LocalDatabase
+
+	feeFloor *FeeFloor
+	diffs    mempoolDiffs
+	priority map[types.Address]struct{}
 }
 
 // NewConservativeState returns a ConservativeState.
@@ -70,7 +101,19 @@ func NewConservativeState(state vmState, db sql.StateDatabase, opts ...Conservat
 	for _, opt := range opts {
 		opt(cs)
 	}
+	cs.priority = make(map[types.Address]struct{}, len(cs.cfg.Priority))
+	for _, addr := range cs.cfg.Priority {
+		cs.priority[addr] = struct{}{}
+	}
 	cs.cache = NewCache(cs.getState, cs.logger)
+	cs.cache.dormantLayers = cs.cfg.DormantLayers
+	cs.cache.localDB = cs.localDB
+	if len(cs.cfg.RewardAccounts) > 0 {
+		cs.cache.rewards = newExpectedRewards(cs.cfg.RewardAccounts)
+	}
+	if estimator, ok := state.(SpendingEstimator); ok {
+		cs.cache.estimator = estimator
+	}
 	return cs
 }
 
@@ -87,12 +130,21 @@ func (cs *ConservativeState) getState(addr types.Address) (uint64, uint64) {
 }
 
 // SelectProposalTXs picks a specific number of random txs for miner to pack in a proposal.
+// The difference between the mempool and the selected txs is reported with events.ReportMempoolDiff.
 func (cs *ConservativeState) SelectProposalTXs(lid types.LayerID, numEligibility int) []types.TransactionID {
 	logger := cs.logger.With(zap.Uint32("layer_id", lid.Uint32()))
-	mi := newMempoolIterator(logger, cs.cache, cs.cfg.BlockGasLimit)
+	mempool := cs.cache.GetMempool()
+	// the iterator consumes the mempool, keep a copy for the diff
+	snapshot := make(mempoolSnapshot, len(mempool))
+	for addr, ntxs := range mempool {
+		snapshot[addr] = ntxs
+	}
+	mi := newMempoolIterator(logger, snapshot, cs.cfg.BlockGasLimit, cs.priority)
 	predictedBlock, byAddrAndNonce := mi.PopAll()
 	numTXs := numEligibility * cs.cfg.NumTXsPerProposal
-	return getProposalTXs(logger, numTXs, predictedBlock, byAddrAndNonce)
+	selected := getProposalTXs(logger, numTXs, predictedBlock, byAddrAndNonce, cs.priority)
+	events.ReportMempoolDiff(cs.diffs.diff(lid, mempool, selected))
+	return selected
 }
 
 func getProposalTXs(
@@ -100,9 +152,28 @@ func getProposalTXs(
 	numTXs int,
 	predictedBlock []*NanoTX,
 	byAddrAndNonce map[types.Address][]*NanoTX,
+	priority map[types.Address]struct{},
 ) []types.TransactionID {
-	if len(predictedBlock) <= numTXs {
-		result := make([]types.TransactionID, 0, len(predictedBlock))
+	var (
+		result = make([]types.TransactionID, 0, min(numTXs, len(predictedBlock)))
+		gas    uint64
+	)
+	// txs of priority principals are ordered first in the predicted block
+	// and are selected before the random selection from the rest.
+	for len(predictedBlock) > 0 && len(result) < numTXs {
+		ntx := predictedBlock[0]
+		if _, ok := priority[ntx.Principal]; !ok {
+			break
+		}
+		result = append(result, ntx.ID)
+		gas += ntx.MaxGas
+		predictedBlock = predictedBlock[1:]
+	}
+	if len(result) > 0 {
+		proposalPriorityTxs.Add(float64(len(result)))
+		proposalPriorityGas.Add(float64(gas))
+	}
+	if len(predictedBlock) <= numTXs-len(result) {
 		for _, ntx := range predictedBlock {
 			result = append(result, ntx.ID)
 		}
@@ -110,7 +181,7 @@ func getProposalTXs(
 	}
 	// randomly select transactions from the predicted block.
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return ShuffleWithNonceOrder(logger, rng, numTXs, predictedBlock, byAddrAndNonce)
+	return append(result, ShuffleWithNonceOrder(logger, rng, numTXs-len(result), predictedBlock, byAddrAndNonce)...)
 }
 
 // Validation initializes validation request.
@@ -139,8 +210,9 @@ func (cs *ConservativeState) AddToCache(ctx context.Context, tx *types.Transacti
 }
 
 // RevertCache reverts the conservative cache to the given layer.
-func (cs *ConservativeState) RevertCache(revertTo types.LayerID) error {
-	return cs.cache.RevertToLayer(cs.db, revertTo)
+// Touched are the accounts affected by the undone layers, see transactions.AddressesAppliedFrom.
+func (cs *ConservativeState) RevertCache(revertTo types.LayerID, touched []types.Address) error {
+	return cs.cache.RevertToLayer(cs.db, revertTo, touched)
 }
 
 func (cs *ConservativeState) UpdateCache(
@@ -155,6 +227,13 @@ func (cs *ConservativeState) UpdateCache(
 		return err
 	}
 	cacheApplyDuration.Observe(float64(time.Since(t0)))
+	if cs.feeFloor != nil {
+		var used uint64
+		for _, rst := range results {
+			used += rst.Gas
+		}
+		cs.feeFloor.OnLayer(used, cs.cfg.BlockGasLimit)
+	}
 	return nil
 }
 
@@ -164,6 +243,22 @@ func (cs *ConservativeState) GetProjection(addr types.Address) (uint64, uint64)
 	return cs.cache.GetProjection(addr)
 }
 
+// GetProjectionWithCertainty returns the projected nonce and balance for an account, including
+// only pending transactions that match the certainty. For example with types.ProjectPacked
+// transactions that are only in the mempool are not accounted for.
+func (cs *ConservativeState) GetProjectionWithCertainty(
+	addr types.Address,
+	certainty types.ProjectionCertainty,
+) (uint64, uint64) {
+	return cs.cache.GetProjectionWithCertainty(addr, certainty)
+}
+
+// GetNonceProjection returns the nonces of an account used by pending transactions, both in the mempool
+// and in the database only, and the lowest nonce that is not used yet.
+func (cs *ConservativeState) GetNonceProjection(addr types.Address) (*types.NonceProjection, error) {
+	return cs.cache.GetNonceProjection(cs.db, addr)
+}
+
 // LinkTXsWithProposal associates the transactions to a proposal.
 func (cs *ConservativeState) LinkTXsWithProposal(
 	lid types.LayerID,
@@ -178,6 +273,12 @@ func (cs *ConservativeState) LinkTXsWithBlock(lid types.LayerID, bid types.Block
 	return cs.cache.LinkTXsWithBlock(cs.db, lid, bid, tids)
 }
 
+// ExpectRewards records rewards of a block that is not applied yet for the own reward accounts.
+// It does nothing unless CSConfig.RewardAccounts are set.
+func (cs *ConservativeState) ExpectRewards(lid types.LayerID, bid types.BlockID, rewards []types.CoinbaseReward) error {
+	return cs.cache.ExpectRewards(cs.db, lid, bid, rewards)
+}
+
 // AddToDB adds a transaction to the database.
 func (cs *ConservativeState) AddToDB(tx *types.Transaction) error {
 	return transactions.Add(cs.db, tx, time.Now())
@@ -202,6 +303,50 @@ func (cs *ConservativeState) GetMeshTransaction(tid types.TransactionID) (*types
 	return transactions.Get(cs.db, tid)
 }
 
+// GetTxStatus derives the status of a tx and the reason for it from the cache and database state.
+func (cs *ConservativeState) GetTxStatus(tid types.TransactionID) (*types.TXStatus, error) {
+	mtx, err := transactions.Get(cs.db, tid)
+	if err != nil {
+		return nil, err
+	}
+	status := &types.TXStatus{State: mtx.State}
+	if mtx.State == types.APPLIED {
+		status.Reason = types.TXReasonApplied
+		status.Layer = mtx.LayerID
+		return status, nil
+	}
+	if ntx, layer := cs.cache.getWithLayer(tid); ntx != nil {
+		switch {
+		case layer != 0:
+			status.Reason = types.TXReasonInProposal
+			status.Layer = layer
+		case cs.cache.IsNonceGapped(ntx):
+			status.Reason = types.TXReasonNonceGapped
+		default:
+			status.Reason = types.TXReasonInMempool
+		}
+		return status, nil
+	}
+	if mtx.TxHeader == nil {
+		return status, nil
+	}
+	stateNonce, _ := cs.getState(mtx.Principal)
+	if mtx.Nonce < stateNonce {
+		status.Reason = types.TXReasonIneffective
+		return status, nil
+	}
+	nonce, balance := cs.cache.GetProjection(mtx.Principal)
+	switch {
+	case mtx.Nonce > nonce:
+		status.Reason = types.TXReasonNonceGapped
+	case balance < newNanoTX(mtx, cs.cache.estimator).MaxSpending():
+		status.Reason = types.TXReasonInsufficientBalance
+	default:
+		status.Reason = types.TXReasonEvicted
+	}
+	return status, nil
+}
+
 // GetMeshTransactions retrieves a list of txs by their id's.
 func (cs *ConservativeState) GetMeshTransactions(
 	ids []types.TransactionID,
@@ -18,6 +18,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
 	"github.com/spacemeshos/go-spacemesh/p2p"
@@ -169,6 +170,80 @@ func TestSelectProposalTXs(t *testing.T) {
 	}, 100*time.Millisecond, 20*time.Millisecond)
 }
 
+func TestSelectProposalTXs_Priority(t *testing.T) {
+	tcs := createTestState(t, math.MaxUint64)
+	lid := types.LayerID(97)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	principal := types.GenerateAddress(signer.PublicKey().Bytes())
+	tcs.priority = map[types.Address]struct{}{principal: {}}
+
+	tcs.mvm.EXPECT().GetBalance(principal).Return(defaultBalance, nil).Times(1)
+	tcs.mvm.EXPECT().GetNonce(principal).Return(uint64(0), nil).Times(1)
+	var expected []types.TransactionID
+	for n := range uint64(3) {
+		// lower fee than all other txs
+		tx := newTx(t, n, defaultAmount, 1, signer)
+		require.NoError(t, tcs.AddToCache(context.Background(), tx, time.Now()))
+		expected = append(expected, tx.ID)
+	}
+	addBatch(t, tcs, 2*numTXsInProposal)
+
+	got := tcs.SelectProposalTXs(lid, 1)
+	require.Len(t, got, numTXsInProposal)
+	require.Equal(t, expected, got[:len(expected)])
+}
+
+func TestSelectProposalTXs_MempoolDiff(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub := events.SubscribeMempoolDiffs()
+
+	tcs := createTestState(t, math.MaxUint64)
+	lid := types.LayerID(97)
+	addTX := func() types.TransactionID {
+		signer, err := signing.NewEdSigner()
+		require.NoError(t, err)
+		addr := types.GenerateAddress(signer.PublicKey().Bytes())
+		tcs.mvm.EXPECT().GetBalance(addr).Return(defaultBalance, nil).Times(1)
+		tcs.mvm.EXPECT().GetNonce(addr).Return(uint64(0), nil).Times(1)
+		tx := newTx(t, 0, defaultAmount, defaultFee, signer)
+		require.NoError(t, tcs.AddToCache(context.Background(), tx, time.Now()))
+		return tx.ID
+	}
+	next := func() events.EventMempoolDiff {
+		select {
+		case ev := <-sub.Out():
+			return ev.(events.EventMempoolDiff)
+		case <-time.After(time.Second):
+			require.FailNow(t, "timed out waiting for mempool diff")
+		}
+		return events.EventMempoolDiff{}
+	}
+
+	var first []types.TransactionID
+	for range numTXsInProposal + 3 {
+		first = append(first, addTX())
+	}
+	got := tcs.SelectProposalTXs(lid, 1)
+	diff := next()
+	require.Equal(t, lid, diff.Layer)
+	require.Equal(t, numTXsInProposal+3, diff.Candidates)
+	require.Equal(t, got, diff.Included)
+	require.Len(t, diff.Missed, 3)
+	require.ElementsMatch(t, first, diff.Added)
+	require.Empty(t, diff.Removed)
+
+	second := addTX()
+	got = tcs.SelectProposalTXs(lid.Add(1), 1)
+	diff = next()
+	require.Equal(t, numTXsInProposal+4, diff.Candidates)
+	require.Equal(t, got, diff.Included)
+	require.Len(t, diff.Missed, 4)
+	require.Equal(t, []types.TransactionID{second}, diff.Added)
+	require.Empty(t, diff.Removed)
+}
+
 func TestSelectProposalTXs_ExhaustGas(t *testing.T) {
 	numTXs := 2 * numTXsInProposal
 	lid := types.LayerID(97)
@@ -223,8 +298,8 @@ func TestSelectProposalTXs_ExhaustMemPool(t *testing.T) {
 	}
 
 	sort.Slice(expected, func(i, j int) bool {
-		first := tcs.cache.cachedTXs[expected[i]]
-		second := tcs.cache.cachedTXs[expected[j]]
+		first := tcs.cache.cachedTXs.get(expected[i])
+		second := tcs.cache.cachedTXs.get(expected[j])
 
 		if first.Fee() != second.Fee() {
 			return first.Fee() > second.Fee()
@@ -347,6 +422,92 @@ func TestGetProjection(t *testing.T) {
 	require.EqualValues(t, defaultBalance-2*(defaultAmount+defaultFee*defaultGas), balance)
 }
 
+func TestGetProjectionWithCertainty(t *testing.T) {
+	tcs := createTestState(t, math.MaxUint64)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	addr := types.GenerateAddress(signer.PublicKey().Bytes())
+	tcs.mvm.EXPECT().GetBalance(addr).Return(defaultBalance, nil).Times(1)
+	tcs.mvm.EXPECT().GetNonce(addr).Return(nonce, nil).Times(1)
+	tx1 := newTx(t, nonce, defaultAmount, defaultFee, signer)
+	require.NoError(t, tcs.AddToCache(context.Background(), tx1, time.Now()))
+	require.NoError(t, tcs.LinkTXsWithProposal(types.LayerID(10), types.ProposalID{1}, []types.TransactionID{tx1.ID}))
+	tx2 := newTx(t, nonce+1, defaultAmount, defaultFee, signer)
+	require.NoError(t, tcs.AddToCache(context.Background(), tx2, time.Now()))
+	spending := defaultAmount + defaultFee*defaultGas
+
+	for _, tc := range []struct {
+		certainty types.ProjectionCertainty
+		nonce     uint64
+		balance   uint64
+	}{
+		{types.ProjectMempool, nonce + 2, defaultBalance - 2*spending},
+		{types.ProjectPacked, nonce + 1, defaultBalance - spending},
+		{types.ProjectApplied, nonce, defaultBalance},
+	} {
+		t.Run(tc.certainty.String(), func(t *testing.T) {
+			got, balance := tcs.GetProjectionWithCertainty(addr, tc.certainty)
+			require.EqualValues(t, tc.nonce, got)
+			require.EqualValues(t, tc.balance, balance)
+		})
+	}
+
+	t.Run("unknown account", func(t *testing.T) {
+		other := types.GenerateAddress(types.RandomBytes(32))
+		tcs.mvm.EXPECT().GetBalance(other).Return(uint64(100), nil)
+		tcs.mvm.EXPECT().GetNonce(other).Return(uint64(3), nil)
+		got, balance := tcs.GetProjectionWithCertainty(other, types.ProjectPacked)
+		require.EqualValues(t, 3, got)
+		require.EqualValues(t, 100, balance)
+	})
+}
+
+func TestGetNonceProjection(t *testing.T) {
+	tcs := createTestState(t, math.MaxUint64)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	addr := types.GenerateAddress(signer.PublicKey().Bytes())
+	tcs.mvm.EXPECT().GetBalance(addr).Return(defaultBalance, nil).Times(1)
+	tcs.mvm.EXPECT().GetNonce(addr).Return(nonce, nil).Times(1)
+	spending := defaultAmount + defaultFee*defaultGas
+
+	tx1 := newTx(t, nonce, defaultAmount, defaultFee, signer)
+	require.NoError(t, tcs.AddToCache(context.Background(), tx1, time.Now()))
+	// transactions that are in the database but not in the mempool
+	tx2 := newTx(t, nonce+1, defaultAmount, defaultFee, signer)
+	require.NoError(t, tcs.AddToDB(tx2))
+	tx4 := newTx(t, nonce+3, defaultAmount, defaultFee, signer)
+	require.NoError(t, tcs.AddToDB(tx4))
+
+	got, err := tcs.GetNonceProjection(addr)
+	require.NoError(t, err)
+	require.Equal(t, &types.NonceProjection{
+		Applied:        nonce,
+		AppliedBalance: defaultBalance,
+		Pending: []types.PendingNonce{
+			{Nonce: nonce, ID: tx1.ID, Balance: defaultBalance - spending, Mempool: true},
+			{Nonce: nonce + 1, ID: tx2.ID, Balance: defaultBalance - 2*spending},
+			{Nonce: nonce + 3, ID: tx4.ID, Balance: defaultBalance - 3*spending},
+		},
+		Next:    nonce + 2,
+		Balance: defaultBalance - 2*spending,
+	}, got)
+
+	t.Run("unknown account", func(t *testing.T) {
+		other := types.GenerateAddress(types.RandomBytes(32))
+		tcs.mvm.EXPECT().GetBalance(other).Return(uint64(100), nil)
+		tcs.mvm.EXPECT().GetNonce(other).Return(uint64(3), nil)
+		got, err := tcs.GetNonceProjection(other)
+		require.NoError(t, err)
+		require.Equal(t, &types.NonceProjection{
+			Applied:        3,
+			AppliedBalance: 100,
+			Next:           3,
+			Balance:        100,
+		}, got)
+	})
+}
+
 func TestAddToCache(t *testing.T) {
 	tcs := createTestState(t, math.MaxUint64)
 	signer, err := signing.NewEdSigner()
@@ -426,6 +587,45 @@ func TestAddToCache_TooManyForOneAccount(t *testing.T) {
 	checkTXStateFromDB(t, tcs.db, mtxs, types.MEMPOOL)
 }
 
+// estimatingState is a vm state with templates that estimate the maximal spending of transactions.
+type estimatingState struct {
+	*MockvmState
+	spendingEstimatorFunc
+}
+
+func TestAddToCache_SpendingEstimator(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		estimated bool
+		err       error
+	}{
+		{desc: "estimated by template", estimated: true},
+		// the fee is not covered by the balance with the default estimate
+		{desc: "default estimate", err: errInsufficientBalance},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mvm := NewMockvmState(ctrl)
+			state := &estimatingState{
+				MockvmState: mvm,
+				spendingEstimatorFunc: func(header *types.TxHeader) (uint64, bool) {
+					return header.MaxSpend, tc.estimated
+				},
+			}
+			cs := NewConservativeState(state, statesql.InMemoryTest(t), WithLogger(zap.NewNop()))
+
+			signer, err := signing.NewEdSigner()
+			require.NoError(t, err)
+			addr := types.GenerateAddress(signer.PublicKey().Bytes())
+			mvm.EXPECT().GetBalance(addr).Return(defaultAmount, nil)
+			mvm.EXPECT().GetNonce(addr).Return(nonce, nil)
+			tx := newTx(t, nonce, defaultAmount, defaultFee, signer)
+			require.ErrorIs(t, cs.AddToCache(context.Background(), tx, time.Now()), tc.err)
+			require.Equal(t, tc.err == nil, cs.cache.Has(tx.ID))
+		})
+	}
+}
+
 func TestGetMeshTransaction(t *testing.T) {
 	tcs := createTestState(t, math.MaxUint64)
 	signer, err := signing.NewEdSigner()
@@ -453,6 +653,63 @@ func TestGetMeshTransaction(t *testing.T) {
 	require.Equal(t, types.MEMPOOL, mtx.State)
 }
 
+func TestGetTxStatus(t *testing.T) {
+	tcs := createTestState(t, math.MaxUint64)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	addr := types.GenerateAddress(signer.PublicKey().Bytes())
+	tcs.mvm.EXPECT().GetBalance(addr).Return(defaultBalance, nil).AnyTimes()
+	tcs.mvm.EXPECT().GetNonce(addr).Return(nonce, nil).AnyTimes()
+
+	_, err = tcs.GetTxStatus(types.RandomTransactionID())
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	inMempool := newTx(t, nonce, defaultAmount, defaultFee, signer)
+	require.NoError(t, tcs.AddToCache(context.Background(), inMempool, time.Now()))
+	gapped := newTx(t, nonce+2, defaultAmount, defaultFee, signer)
+	require.NoError(t, tcs.AddToCache(context.Background(), gapped, time.Now()))
+	ineffective := newTx(t, nonce-1, defaultAmount, defaultFee, signer)
+	require.NoError(t, transactions.Add(tcs.db, ineffective, time.Now()))
+	evicted := newTx(t, nonce+1, defaultAmount, defaultFee, signer)
+	require.NoError(t, transactions.Add(tcs.db, evicted, time.Now()))
+	insufficient := newTx(t, nonce+1, defaultBalance, defaultFee, signer)
+	require.NoError(t, transactions.Add(tcs.db, insufficient, time.Now()))
+	applied := newTx(t, nonce-2, defaultAmount, defaultFee, signer)
+	require.NoError(t, transactions.Add(tcs.db, applied, time.Now()))
+	require.NoError(t, tcs.db.WithTx(context.Background(), func(dbtx sql.Transaction) error {
+		return transactions.AddResult(dbtx, applied.ID, &types.TransactionResult{Layer: 9})
+	}))
+
+	for _, tc := range []struct {
+		desc     string
+		id       types.TransactionID
+		expected types.TXStatus
+	}{
+		{"in mempool", inMempool.ID, types.TXStatus{State: types.MEMPOOL, Reason: types.TXReasonInMempool}},
+		{"nonce gapped", gapped.ID, types.TXStatus{State: types.MEMPOOL, Reason: types.TXReasonNonceGapped}},
+		{"ineffective", ineffective.ID, types.TXStatus{State: types.MEMPOOL, Reason: types.TXReasonIneffective}},
+		{"evicted", evicted.ID, types.TXStatus{State: types.MEMPOOL, Reason: types.TXReasonEvicted}},
+		{
+			"insufficient balance",
+			insufficient.ID,
+			types.TXStatus{State: types.MEMPOOL, Reason: types.TXReasonInsufficientBalance},
+		},
+		{"applied", applied.ID, types.TXStatus{State: types.APPLIED, Reason: types.TXReasonApplied, Layer: 9}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			status, err := tcs.GetTxStatus(tc.id)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, *status)
+		})
+	}
+
+	lid := types.LayerID(10)
+	require.NoError(t, tcs.LinkTXsWithProposal(lid, types.ProposalID{1}, []types.TransactionID{inMempool.ID}))
+	status, err := tcs.GetTxStatus(inMempool.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.TXStatus{State: types.MEMPOOL, Reason: types.TXReasonInProposal, Layer: lid}, *status)
+}
+
 func TestUpdateCache_UpdateHeader(t *testing.T) {
 	tcs := createTestState(t, math.MaxUint64)
 	lid := types.LayerID(1)
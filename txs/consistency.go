@@ -0,0 +1,156 @@
+package txs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/exp/maps"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+)
+
+// AccountProjection is the state of an account in the cache.
+type AccountProjection struct {
+	StartNonce   uint64
+	StartBalance uint64
+	NextNonce    uint64
+	Balance      uint64
+	TXs          int
+}
+
+func (p AccountProjection) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddUint64("start_nonce", p.StartNonce)
+	encoder.AddUint64("start_balance", p.StartBalance)
+	encoder.AddUint64("next_nonce", p.NextNonce)
+	encoder.AddUint64("balance", p.Balance)
+	encoder.AddInt("txs", p.TXs)
+	return nil
+}
+
+func (ac *accountCache) projection() AccountProjection {
+	return AccountProjection{
+		StartNonce:   ac.startNonce,
+		StartBalance: ac.startBalance,
+		NextNonce:    ac.nextNonce(),
+		Balance:      ac.availBalance(),
+		TXs:          ac.txsByNonce.Len(),
+	}
+}
+
+// AccountDrift is a difference between the cached state of an account and the state
+// recomputed from the database.
+type AccountDrift struct {
+	Address types.Address
+	// Cached is nil if the account is not in the cache.
+	Cached   *AccountProjection
+	Expected AccountProjection
+	// MoreInDB is true if the cache is waiting to reconsider transactions that are in the database only.
+	// Such accounts can drift legitimately, e.g. when a better transaction arrived after a worse one
+	// made transactions with higher nonces infeasible.
+	MoreInDB bool
+}
+
+func (d *AccountDrift) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("address", d.Address.String())
+	if d.Cached != nil {
+		encoder.AddObject("cached", d.Cached)
+	}
+	encoder.AddObject("expected", d.Expected)
+	encoder.AddBool("more_in_db", d.MoreInDB)
+	return nil
+}
+
+// CheckConsistency recomputes accounts that are in the cache or have pending transactions from
+// the state and the database, and returns accounts which cached state differs.
+// The cache is locked for the whole check, it is meant for debugging only.
+func (c *Cache) CheckConsistency(db sql.StateDatabase) ([]*AccountDrift, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	applied, err := layers.GetLastApplied(db)
+	if err != nil {
+		return nil, fmt.Errorf("get last applied: %w", err)
+	}
+	pending, err := transactions.AddressesWithPendingTransactions(db)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]types.Address, 0, c.numPending())
+	for i := range c.shards {
+		addresses = append(addresses, maps.Keys(c.shards[i].pending)...)
+	}
+	for _, an := range pending {
+		if _, ok := c.account(an.Address); !ok {
+			addresses = append(addresses, an.Address)
+		}
+	}
+
+	// recomputed accounts are not shared with the cache
+	fresh := NewCache(c.stateF, zap.NewNop())
+	fresh.estimator = c.estimator
+	fresh.rewards = c.rewards
+	var drifts []*AccountDrift
+	for _, addr := range addresses {
+		expected := fresh.createAcctIfNotPresent(addr)
+		if err := expected.addPendingFromNonce(fresh.logger, db, expected.startNonce, applied); err != nil {
+			return nil, fmt.Errorf("recompute account %s: %w", addr, err)
+		}
+		drift := &AccountDrift{Address: addr, Expected: expected.projection()}
+		if acct, ok := c.account(addr); ok {
+			cached := acct.projection()
+			if cached == drift.Expected {
+				continue
+			}
+			drift.Cached = &cached
+			drift.MoreInDB = acct.moreInDB
+		} else if expected.txsByNonce.Len() == 0 {
+			continue
+		} else if _, ok := c.shard(addr).dormant[addr]; ok {
+			// evicted accounts are re-admitted up to dormantLayers after their state changed
+			drift.MoreInDB = true
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// CheckConsistency compares the cache with the state recomputed from the database and logs accounts
+// that drifted. It returns the drifted accounts.
+func (cs *ConservativeState) CheckConsistency() ([]*AccountDrift, error) {
+	start := time.Now()
+	drifts, err := cs.cache.CheckConsistency(cs.db)
+	if err != nil {
+		return nil, err
+	}
+	for _, drift := range drifts {
+		cs.logger.Error("conservative cache drifted from database", zap.Object("account", drift))
+	}
+	cs.logger.Debug("checked conservative cache consistency",
+		zap.Int("drifted", len(drifts)),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return drifts, nil
+}
+
+// RunConsistencyCheck checks consistency of the cache with the database every interval until
+// the context is canceled.
+func (cs *ConservativeState) RunConsistencyCheck(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := cs.CheckConsistency(); err != nil {
+				cs.logger.Warn("failed to check conservative cache consistency", zap.Error(err))
+			}
+		}
+	}
+}
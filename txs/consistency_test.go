@@ -0,0 +1,60 @@
+package txs
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	tcs := createTestState(t, math.MaxUint64)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	addr := types.GenerateAddress(signer.PublicKey().Bytes())
+	tcs.mvm.EXPECT().GetBalance(addr).Return(defaultBalance, nil).AnyTimes()
+	tcs.mvm.EXPECT().GetNonce(addr).Return(nonce, nil).AnyTimes()
+	for i := range uint64(3) {
+		tx := newTx(t, nonce+i, defaultAmount, defaultFee, signer)
+		require.NoError(t, tcs.AddToCache(context.Background(), tx, time.Now()))
+	}
+
+	drifts, err := tcs.CheckConsistency()
+	require.NoError(t, err)
+	require.Empty(t, drifts)
+
+	t.Run("cached balance drifted", func(t *testing.T) {
+		acct, _ := tcs.cache.account(addr)
+		expected := acct.projection()
+		acct.startBalance++
+		t.Cleanup(func() { acct.startBalance-- })
+
+		drifts, err := tcs.CheckConsistency()
+		require.NoError(t, err)
+		require.Len(t, drifts, 1)
+		require.Equal(t, addr, drifts[0].Address)
+		require.Equal(t, expected, drifts[0].Expected)
+		require.Equal(t, defaultBalance+1, drifts[0].Cached.StartBalance)
+	})
+	t.Run("account not cached", func(t *testing.T) {
+		other, err := signing.NewEdSigner()
+		require.NoError(t, err)
+		otherAddr := types.GenerateAddress(other.PublicKey().Bytes())
+		tcs.mvm.EXPECT().GetBalance(otherAddr).Return(defaultBalance, nil).AnyTimes()
+		tcs.mvm.EXPECT().GetNonce(otherAddr).Return(nonce, nil).AnyTimes()
+		require.NoError(t, tcs.AddToDB(newTx(t, nonce, defaultAmount, defaultFee, other)))
+
+		drifts, err := tcs.CheckConsistency()
+		require.NoError(t, err)
+		require.Len(t, drifts, 1)
+		require.Equal(t, otherAddr, drifts[0].Address)
+		require.Nil(t, drifts[0].Cached)
+		require.Equal(t, 1, drifts[0].Expected.TXs)
+		require.Equal(t, nonce+1, drifts[0].Expected.NextNonce)
+	})
+}
@@ -0,0 +1,84 @@
+package txs
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// dormantAccount is the state of an account when it was evicted from the cache.
+type dormantAccount struct {
+	nonce   uint64
+	balance uint64
+}
+
+// isDormant returns true if none of the pending transactions of the account are feasible,
+// but they are still in the database waiting to be reconsidered after a layer is applied.
+func (ac *accountCache) isDormant() bool {
+	return ac.txsByNonce.Len() == 0 && ac.moreInDB
+}
+
+// compactDormant evicts accounts that were dormant for dormantLayers and re-admits evicted accounts
+// whose state changed, as their transactions may be feasible now. Evicted accounts are re-admitted
+// right away if touched by the applied layer, otherwise their state is checked every dormantLayers.
+func (c *Cache) compactDormant(db sql.StateDatabase, lid types.LayerID, touched map[types.Address]struct{}) error {
+	if c.dormantLayers == 0 {
+		return nil
+	}
+	rescan := lid.Uint32()%c.dormantLayers == 0
+	readmitted := 0
+	for i := range c.shards {
+		for addr, evicted := range c.shards[i].dormant {
+			if _, ok := touched[addr]; !ok && !rescan {
+				continue
+			}
+			if nonce, balance := c.stateF(addr); nonce == evicted.nonce && balance == evicted.balance {
+				continue
+			}
+			acct := c.createAcctIfNotPresent(addr)
+			if err := acct.addPendingFromNonce(c.logger, db, acct.startNonce, lid); err != nil {
+				return fmt.Errorf("readmit account %s: %w", addr, err)
+			}
+			readmitted++
+		}
+	}
+
+	evicted := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		for addr, acct := range s.pending {
+			if !acct.isDormant() {
+				acct.dormantSince = 0
+				continue
+			}
+			if c.rewards != nil {
+				// expected rewards change the balance of own accounts without a change in the state
+				if _, ok := c.rewards.accounts[addr]; ok {
+					continue
+				}
+			}
+			switch {
+			case acct.dormantSince == 0:
+				acct.dormantSince = lid
+			case lid >= acct.dormantSince.Add(c.dormantLayers):
+				s.dormant[addr] = dormantAccount{nonce: acct.startNonce, balance: acct.startBalance}
+				delete(s.pending, addr)
+				evicted++
+			}
+		}
+	}
+	dormant := c.numDormant()
+	dormantAccounts.Set(float64(dormant))
+	if readmitted > 0 || evicted > 0 {
+		c.logger.Debug("compacted dormant accounts",
+			zap.Uint32("layer_id", lid.Uint32()),
+			zap.Int("evicted", evicted),
+			zap.Int("readmitted", readmitted),
+			zap.Int("dormant", dormant),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package txs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+)
+
+func createDormantAccount(t *testing.T, dormantLayers uint32) (*testCache, *testAcct, []*types.MeshTransaction) {
+	t.Helper()
+	tc, ta := createSingleAccountTestCache(t)
+	tc.dormantLayers = dormantLayers
+	now := time.Now()
+	mtxs := make([]*types.MeshTransaction, 0, 3)
+	for i := range uint64(3) {
+		mtx := newMeshTX(t, ta.nonce+i, ta.signer, defaultAmount, now.Add(time.Second*time.Duration(i)))
+		// make it so none of the txs is feasible
+		mtx.MaxSpend = ta.balance
+		mtxs = append(mtxs, mtx)
+		require.NoError(t, transactions.Add(tc.db, &mtx.Transaction, mtx.Received))
+	}
+	require.NoError(t, tc.buildFromScratch(tc.db))
+	require.True(t, tc.MoreInDB(ta.principal))
+	return tc, ta, mtxs
+}
+
+func applyEmptyLayer(t *testing.T, tc *testCache, lid types.LayerID) {
+	t.Helper()
+	require.NoError(t, layers.SetApplied(tc.db, lid.Sub(1), types.RandomBlockID()))
+	require.NoError(t, tc.ApplyLayer(context.Background(), tc.db, lid, types.EmptyBlockID, nil, nil))
+}
+
+func TestCache_DormantAccount(t *testing.T) {
+	tc, ta, mtxs := createDormantAccount(t, 4)
+
+	// the account turns dormant in layer 10 and is evicted 4 layers later
+	for lid := types.LayerID(10); lid < 14; lid++ {
+		applyEmptyLayer(t, tc, lid)
+		require.Contains(t, tc.shard(ta.principal).pending, ta.principal)
+	}
+	applyEmptyLayer(t, tc, 14)
+	require.NotContains(t, tc.shard(ta.principal).pending, ta.principal)
+	require.Contains(t, tc.shard(ta.principal).dormant, ta.principal)
+	checkProjection(t, tc.Cache, ta.principal, ta.nonce, ta.balance)
+
+	// the state is checked every 4 layers, the account stays evicted while the state doesn't change
+	applyEmptyLayer(t, tc, 15)
+	applyEmptyLayer(t, tc, 16)
+	require.Contains(t, tc.shard(ta.principal).dormant, ta.principal)
+
+	ta.balance *= 10
+	for lid := types.LayerID(17); lid < 20; lid++ {
+		applyEmptyLayer(t, tc, lid)
+		require.Contains(t, tc.shard(ta.principal).dormant, ta.principal)
+	}
+	applyEmptyLayer(t, tc, 20)
+	require.NotContains(t, tc.shard(ta.principal).dormant, ta.principal)
+	checkMempool(t, tc.Cache, map[types.Address][]*types.MeshTransaction{ta.principal: mtxs})
+	require.False(t, tc.MoreInDB(ta.principal))
+
+	drifts, err := tc.CheckConsistency(tc.db)
+	require.NoError(t, err)
+	require.Empty(t, drifts)
+}
+
+func TestCache_DormantAccount_Add(t *testing.T) {
+	tc, ta, _ := createDormantAccount(t, 1)
+	applyEmptyLayer(t, tc, 10)
+	applyEmptyLayer(t, tc, 11)
+	require.Contains(t, tc.shard(ta.principal).dormant, ta.principal)
+
+	// a new tx re-admits the account, txs in the database are reconsidered after the next layer
+	better := newTx(t, ta.nonce, defaultAmount, defaultFee+1, ta.signer)
+	require.NoError(t, tc.Add(context.Background(), tc.db, better, time.Now()))
+	require.NotContains(t, tc.shard(ta.principal).dormant, ta.principal)
+	require.True(t, tc.MoreInDB(ta.principal))
+	checkTX(t, tc.Cache, better.ID, 0, types.EmptyBlockID)
+}
+
+func TestCache_DormantAccount_Disabled(t *testing.T) {
+	tc, ta, _ := createDormantAccount(t, 0)
+	for lid := types.LayerID(10); lid < 20; lid++ {
+		applyEmptyLayer(t, tc, lid)
+	}
+	require.Contains(t, tc.shard(ta.principal).pending, ta.principal)
+	require.Zero(t, tc.numDormant())
+}
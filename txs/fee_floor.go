@@ -0,0 +1,98 @@
+package txs
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// targetFullness is the share of the block gas limit the adjusted fee floor aims for.
+const targetFullness = 0.5
+
+// FeeFloorConfig is the config for the fee floor of the mempool admission.
+type FeeFloorConfig struct {
+	// MinFeePerByte is the lowest fee per encoded byte of a transaction.
+	MinFeePerByte uint64
+	// MaxFeePerByte bounds the adjusted floor. Zero disables the adjustment.
+	MaxFeePerByte uint64
+	// Adjust enables adjusting the floor from the fullness of the recently applied blocks.
+	Adjust bool
+}
+
+// FeeTooLowError is returned for transactions that pay less than the current fee floor.
+type FeeTooLowError struct {
+	ID       types.TransactionID
+	Size     int
+	Fee      uint64
+	Required uint64
+}
+
+func (e *FeeTooLowError) Error() string {
+	return fmt.Sprintf("%s: %s fee %d for %d bytes < %d", errFeeTooLow, e.ID, e.Fee, e.Size, e.Required)
+}
+
+func (e *FeeTooLowError) Unwrap() error {
+	return errFeeTooLow
+}
+
+// FeeFloor is the minimal fee per encoded byte of transactions admitted to the mempool.
+//
+// If adjustment is enabled the floor moves after every applied layer, up when the block used
+// more than half of the gas limit and down when it used less. The step is proportional to the
+// distance from the target and is at most 1/8 of the current floor (but at least 1), the floor
+// stays within [MinFeePerByte, MaxFeePerByte].
+type FeeFloor struct {
+	cfg     FeeFloorConfig
+	current atomic.Uint64
+}
+
+// NewFeeFloor returns a FeeFloor starting at MinFeePerByte.
+func NewFeeFloor(cfg FeeFloorConfig) *FeeFloor {
+	f := &FeeFloor{cfg: cfg}
+	f.current.Store(cfg.MinFeePerByte)
+	feeFloor.Set(float64(cfg.MinFeePerByte))
+	return f
+}
+
+// PerByte returns the current fee floor per byte.
+func (f *FeeFloor) PerByte() uint64 {
+	return f.current.Load()
+}
+
+// Check returns FeeTooLowError if the fee of the transaction is below the floor.
+func (f *FeeFloor) Check(tx *types.Transaction) error {
+	perByte := f.PerByte()
+	if perByte == 0 {
+		return nil
+	}
+	size := len(tx.Raw)
+	required := perByte * uint64(size)
+	if required/uint64(size) != perByte {
+		required = ^uint64(0)
+	}
+	if fee := tx.Fee(); fee < required {
+		return &FeeTooLowError{ID: tx.ID, Size: size, Fee: fee, Required: required}
+	}
+	return nil
+}
+
+// OnLayer adjusts the floor with the gas used by the block applied in the layer.
+func (f *FeeFloor) OnLayer(used, limit uint64) {
+	if !f.cfg.Adjust || f.cfg.MaxFeePerByte <= f.cfg.MinFeePerByte || limit == 0 {
+		return
+	}
+	fullness := float64(used) / float64(limit)
+	current := f.current.Load()
+	delta := float64(current) * (fullness - targetFullness) / targetFullness / 8
+	next := current
+	switch {
+	case fullness > targetFullness:
+		next = current + max(uint64(delta), 1)
+	case fullness < targetFullness:
+		next = current - min(max(uint64(-delta), 1), current)
+	}
+	next = min(max(next, f.cfg.MinFeePerByte), f.cfg.MaxFeePerByte)
+	f.current.Store(next)
+	feeFloor.Set(float64(next))
+}
@@ -0,0 +1,63 @@
+package txs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestFeeFloor_Check(t *testing.T) {
+	tx := &types.Transaction{
+		RawTx:    types.NewRawTx(make([]byte, 100)),
+		TxHeader: &types.TxHeader{MaxGas: 50, GasPrice: 4},
+	}
+
+	require.NoError(t, NewFeeFloor(FeeFloorConfig{}).Check(tx))
+	require.NoError(t, NewFeeFloor(FeeFloorConfig{MinFeePerByte: 2}).Check(tx))
+
+	err := NewFeeFloor(FeeFloorConfig{MinFeePerByte: 3}).Check(tx)
+	var feeErr *FeeTooLowError
+	require.ErrorAs(t, err, &feeErr)
+	require.ErrorIs(t, err, errFeeTooLow)
+	require.Equal(t, uint64(300), feeErr.Required)
+	require.Equal(t, uint64(200), feeErr.Fee)
+
+	err = NewFeeFloor(FeeFloorConfig{MinFeePerByte: math.MaxUint64}).Check(tx)
+	require.ErrorAs(t, err, &feeErr)
+	require.Equal(t, uint64(math.MaxUint64), feeErr.Required)
+}
+
+func TestFeeFloor_OnLayer(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		floor := NewFeeFloor(FeeFloorConfig{MinFeePerByte: 10, MaxFeePerByte: 100})
+		floor.OnLayer(100, 100)
+		require.Equal(t, uint64(10), floor.PerByte())
+	})
+	t.Run("adjusts within bounds", func(t *testing.T) {
+		floor := NewFeeFloor(FeeFloorConfig{MinFeePerByte: 16, MaxFeePerByte: 20, Adjust: true})
+		floor.OnLayer(100, 100)
+		require.Equal(t, uint64(18), floor.PerByte())
+		floor.OnLayer(50, 100)
+		require.Equal(t, uint64(18), floor.PerByte())
+		floor.OnLayer(100, 100)
+		require.Equal(t, uint64(20), floor.PerByte())
+		floor.OnLayer(100, 100)
+		require.Equal(t, uint64(20), floor.PerByte())
+		floor.OnLayer(0, 100)
+		require.Equal(t, uint64(18), floor.PerByte())
+		for range 10 {
+			floor.OnLayer(0, 100)
+		}
+		require.Equal(t, uint64(16), floor.PerByte())
+	})
+	t.Run("rises from zero", func(t *testing.T) {
+		floor := NewFeeFloor(FeeFloorConfig{MaxFeePerByte: 5, Adjust: true})
+		floor.OnLayer(60, 100)
+		require.Equal(t, uint64(1), floor.PerByte())
+		floor.OnLayer(0, 100)
+		require.Equal(t, uint64(0), floor.PerByte())
+	})
+}
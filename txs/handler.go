@@ -22,22 +22,74 @@ var (
 	errDuplicateTX = errors.New("tx already exists")
 	errParse       = errors.New("failed to parse tx")
 	errVerify      = errors.New("failed to verify tx")
+	errTooLarge    = errors.New("tx too large")
+	errFeeTooLow   = errors.New("gas price below minimum")
+	// errForeignNetwork is returned for transactions that are signed for another network,
+	// e.g. replayed from a testnet.
+	errForeignNetwork = errors.New("tx signed for another network")
 )
 
+// TxHandlerOpt for configuring TxHandler.
+type TxHandlerOpt func(*TxHandler)
+
+// WithMaxTxSize rejects transactions larger than size bytes before they are parsed.
+// Zero disables the check.
+func WithMaxTxSize(size int) TxHandlerOpt {
+	return func(th *TxHandler) {
+		th.maxTxSize = size
+	}
+}
+
+// WithMinGasPrice rejects transactions received via gossip or api with a gas price lower than price.
+// Transactions fetched for proposals are not subject to it, as they were already admitted by other nodes.
+func WithMinGasPrice(price uint64) TxHandlerOpt {
+	return func(th *TxHandler) {
+		th.minGasPrice = max(price, 1)
+	}
+}
+
+// WithFeeFloor rejects transactions with a fee per encoded byte below the floor.
+// As WithMinGasPrice, it applies only to transactions received via gossip or api.
+func WithFeeFloor(floor *FeeFloor) TxHandlerOpt {
+	return func(th *TxHandler) {
+		th.feeFloor = floor
+	}
+}
+
+// WithForeignNetworks sets genesis ids of other known networks. Transactions submitted via api
+// that fail signature verification are checked against them, to reject transactions replayed
+// from those networks with a distinct error. Transactions received via gossip are not checked,
+// as it would multiply the cost of verifying spam with bad signatures.
+func WithForeignNetworks(genesis ...types.Hash20) TxHandlerOpt {
+	return func(th *TxHandler) {
+		th.foreignNetworks = genesis
+	}
+}
+
 // TxHandler handles the transactions received via gossip or sync.
 type TxHandler struct {
 	self   peer.ID
 	logger *zap.Logger
 	state  conservativeState
+
+	maxTxSize       int
+	minGasPrice     uint64
+	feeFloor        *FeeFloor
+	foreignNetworks []types.Hash20
 }
 
 // NewTxHandler returns a new TxHandler.
-func NewTxHandler(s conservativeState, id peer.ID, l *zap.Logger) *TxHandler {
-	return &TxHandler{
-		self:   id,
-		logger: l,
-		state:  s,
+func NewTxHandler(s conservativeState, id peer.ID, l *zap.Logger, opts ...TxHandlerOpt) *TxHandler {
+	th := &TxHandler{
+		self:        id,
+		logger:      l,
+		state:       s,
+		minGasPrice: 1,
+	}
+	for _, opt := range opts {
+		opt(th)
 	}
+	return th
 }
 
 func updateMetrics(err error, counter *prometheus.CounterVec) {
@@ -50,8 +102,14 @@ func updateMetrics(err error, counter *prometheus.CounterVec) {
 		counter.WithLabelValues(rejectedBadNonce).Inc()
 	case errors.Is(err, errParse):
 		counter.WithLabelValues(cantParse).Inc()
+	case errors.Is(err, errForeignNetwork):
+		counter.WithLabelValues(rejectedForeignNetwork).Inc()
 	case errors.Is(err, errVerify):
 		counter.WithLabelValues(cantVerify).Inc()
+	case errors.Is(err, errTooLarge):
+		counter.WithLabelValues(rejectedTooLarge).Inc()
+	case errors.Is(err, errFeeTooLow):
+		counter.WithLabelValues(rejectedFeeTooLow).Inc()
 	default:
 		counter.WithLabelValues(rejectedInternalErr).Inc()
 	}
@@ -63,7 +121,7 @@ func (th *TxHandler) HandleGossipTransaction(ctx context.Context, peer p2p.Peer,
 		return nil
 	}
 
-	err := th.VerifyAndCacheTx(ctx, msg)
+	err := th.verifyAndCache(ctx, types.Hash32{}, msg, fromGossip)
 	updateMetrics(err, gossipTxCount)
 	if err != nil {
 		if !errors.Is(err, errDuplicateTX) {
@@ -81,7 +139,7 @@ func (th *TxHandler) HandleProposalTransaction(
 	_ p2p.Peer,
 	msg []byte,
 ) error {
-	err := th.verifyAndCache(ctx, expHash, msg)
+	err := th.verifyAndCache(ctx, expHash, msg, fromProposal)
 	updateMetrics(err, proposalTxCount)
 	if errors.Is(err, errDuplicateTX) {
 		return nil
@@ -89,46 +147,100 @@ func (th *TxHandler) HandleProposalTransaction(
 	return err
 }
 
+// VerifyAndCacheTx verifies the transaction submitted via api and adds it to the conservative cache.
 func (th *TxHandler) VerifyAndCacheTx(ctx context.Context, msg []byte) error {
-	return th.verifyAndCache(ctx, types.Hash32{}, msg)
+	return th.verifyAndCache(ctx, types.Hash32{}, msg, fromAPI)
 }
 
-func (th *TxHandler) verifyAndCache(ctx context.Context, expHash types.Hash32, msg []byte) error {
+// origin of the transaction, it selects the checks that are run before the transaction is cached.
+type origin uint8
+
+const (
+	fromGossip origin = iota
+	fromAPI
+	fromProposal
+)
+
+func (th *TxHandler) verifyAndCache(ctx context.Context, expHash types.Hash32, msg []byte, from origin) error {
+	tx, err := th.preValidate(expHash, msg, from)
+	if err != nil {
+		return err
+	}
+	if err := th.state.AddToCache(ctx, tx, time.Now()); err != nil {
+		th.logger.With(log.ZContext(ctx)).Debug("failed to add tx to conservative cache",
+			zap.Stringer("tx_id", tx.ID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// PreValidate runs the checks of the transaction received via gossip or api before it is added
+// to the conservative cache: size, duplicates, structure and template, local fee policy and signature.
+// It doesn't depend on the state of the conservative cache and doesn't take its lock, only looks up
+// the transaction in the database, so it can run concurrently in the gossip workers and reject spam
+// before the serialized admission to the cache.
+//
+// If expHash is not empty the transaction is required to have this hash.
+func (th *TxHandler) PreValidate(expHash types.Hash32, msg []byte) (*types.Transaction, error) {
+	return th.preValidate(expHash, msg, fromGossip)
+}
+
+// preValidate runs the checks of PreValidate. The local fee policy is checked only on admission
+// of transactions received via gossip or api, transactions fetched for proposals and blocks are
+// accepted regardless of it, as other nodes may use a different policy. Transactions with invalid
+// signatures are checked against foreign networks only if they are submitted via api.
+func (th *TxHandler) preValidate(expHash types.Hash32, msg []byte, from origin) (*types.Transaction, error) {
+	admission := from != fromProposal
 	raw := types.NewRawTx(msg)
+	if th.maxTxSize > 0 && len(msg) > th.maxTxSize {
+		return nil, fmt.Errorf("%w: %s size %d > %d", errTooLarge, raw.ID, len(msg), th.maxTxSize)
+	}
 	mtx, err := th.state.GetMeshTransaction(raw.ID)
 	if err != nil && !errors.Is(err, sql.ErrNotFound) {
-		return fmt.Errorf("get tx %w", err)
+		return nil, fmt.Errorf("get tx %w", err)
 	}
 	if mtx != nil && mtx.TxHeader != nil {
-		return errDuplicateTX
+		return nil, errDuplicateTX
 	}
 
 	req := th.state.Validation(raw)
 	header, err := req.Parse()
 	if err != nil {
-		return fmt.Errorf("%w: %s (err: %s)", errParse, raw.ID, err)
+		return nil, fmt.Errorf("%w: %s (err: %s)", errParse, raw.ID, err)
 	}
 	tx := &types.Transaction{RawTx: raw, TxHeader: header}
 	if expHash != (types.Hash32{}) && tx.ID.Hash32() != expHash {
-		return fmt.Errorf("%w: proposal tx want %s, got %s", errWrongHash, expHash.ShortString(), tx.ID.ShortString())
+		return nil, fmt.Errorf("%w: proposal tx want %s, got %s",
+			errWrongHash, expHash.ShortString(), tx.ID.ShortString())
 	}
 	if header.LayerLimits.Min != 0 || header.LayerLimits.Max != 0 {
-		return fmt.Errorf("%w: layers limits are not enabled %s", errParse, raw.ID)
+		return nil, fmt.Errorf("%w: layers limits are not enabled %s", errParse, raw.ID)
 	}
 	if header.GasPrice == 0 || header.Fee() == 0 {
-		return fmt.Errorf("%w: zero gas price %s", errParse, raw.ID)
+		return nil, fmt.Errorf("%w: zero gas price %s", errParse, raw.ID)
 	}
-	if !req.Verify() {
-		return fmt.Errorf("%w: %s", errVerify, raw.ID)
+	if admission && header.GasPrice < th.minGasPrice {
+		return nil, fmt.Errorf("%w: %s gas price %d < %d", errFeeTooLow, raw.ID, header.GasPrice, th.minGasPrice)
 	}
-	if err := th.state.AddToCache(ctx, tx, time.Now()); err != nil {
-		th.logger.With(log.ZContext(ctx)).Debug("failed to add tx to conservative cache",
-			zap.Stringer("tx_id", raw.ID),
-			zap.Error(err),
-		)
-		return err
+	if admission && th.feeFloor != nil {
+		if err := th.feeFloor.Check(tx); err != nil {
+			return nil, err
+		}
 	}
-	return nil
+	if !req.Verify() {
+		if from != fromAPI {
+			return nil, fmt.Errorf("%w: %s", errVerify, raw.ID)
+		}
+		for _, genesis := range th.foreignNetworks {
+			if req.VerifyGenesis(genesis) {
+				return nil, fmt.Errorf("%w: %s (genesis %s)", errForeignNetwork, raw.ID, genesis.ShortString())
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", errVerify, raw.ID)
+	}
+	return tx, nil
 }
 
 // HandleBlockTransaction handles transactions received as a reference to a block.
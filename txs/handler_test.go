@@ -329,3 +329,169 @@ func Test_HandleProposal(t *testing.T) {
 		})
 	}
 }
+
+func Test_HandleProposal_BelowMinGasPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cstate := NewMockconservativeState(ctrl)
+	_, pub, err := crypto.GenerateEd25519Key(nil)
+	require.NoError(t, err)
+	id, err := peer.IDFromPublicKey(pub)
+	require.NoError(t, err)
+	th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithMinGasPrice(5))
+
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	tx := newTx(t, 3, 10, 4, signer)
+	cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil).Times(2)
+	req := smocks.NewMockValidationRequest(ctrl)
+	req.EXPECT().Parse().Return(tx.TxHeader, nil).Times(2)
+	cstate.EXPECT().Validation(tx.RawTx).Return(req).Times(2)
+
+	// rejected on admission via gossip
+	err = th.HandleGossipTransaction(context.Background(), p2p.NoPeer, tx.Raw)
+	require.ErrorIs(t, err, errFeeTooLow)
+
+	// but accepted when it's fetched for a proposal
+	req.EXPECT().Verify().Return(true)
+	cstate.EXPECT().AddToCache(gomock.Any(), tx, gomock.Any())
+	require.NoError(t, th.HandleProposalTransaction(context.Background(), tx.ID.Hash32(), p2p.NoPeer, tx.Raw))
+}
+
+func Test_HandleProposal_BelowFeeFloor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cstate := NewMockconservativeState(ctrl)
+	_, pub, err := crypto.GenerateEd25519Key(nil)
+	require.NoError(t, err)
+	id, err := peer.IDFromPublicKey(pub)
+	require.NoError(t, err)
+
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	tx := newTx(t, 3, 10, 1, signer)
+	floor := NewFeeFloor(FeeFloorConfig{MinFeePerByte: tx.Fee()/uint64(len(tx.Raw)) + 1})
+	th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithFeeFloor(floor))
+	cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil).Times(2)
+	req := smocks.NewMockValidationRequest(ctrl)
+	req.EXPECT().Parse().Return(tx.TxHeader, nil).Times(2)
+	cstate.EXPECT().Validation(tx.RawTx).Return(req).Times(2)
+
+	// rejected on admission via gossip
+	err = th.HandleGossipTransaction(context.Background(), p2p.NoPeer, tx.Raw)
+	require.ErrorIs(t, err, errFeeTooLow)
+
+	// but accepted when it's fetched for a proposal
+	req.EXPECT().Verify().Return(true)
+	cstate.EXPECT().AddToCache(gomock.Any(), tx, gomock.Any())
+	require.NoError(t, th.HandleProposalTransaction(context.Background(), tx.ID.Hash32(), p2p.NoPeer, tx.Raw))
+}
+
+func Test_PreValidate(t *testing.T) {
+	_, pub, err := crypto.GenerateEd25519Key(nil)
+	require.NoError(t, err)
+	id, err := peer.IDFromPublicKey(pub)
+	require.NoError(t, err)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+
+	t.Run("too large", func(t *testing.T) {
+		cstate := NewMockconservativeState(gomock.NewController(t)) // rejected before any state access
+		tx := newTx(t, 3, 10, 1, signer)
+		th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithMaxTxSize(len(tx.Raw)-1))
+
+		_, err := th.PreValidate(types.Hash32{}, tx.Raw)
+		require.ErrorIs(t, err, errTooLarge)
+	})
+	t.Run("fee too low", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cstate := NewMockconservativeState(ctrl)
+		th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithMinGasPrice(5))
+		tx := newTx(t, 3, 10, 4, signer)
+		cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil)
+		req := smocks.NewMockValidationRequest(ctrl)
+		req.EXPECT().Parse().Return(tx.TxHeader, nil)
+		cstate.EXPECT().Validation(tx.RawTx).Return(req)
+
+		_, err := th.PreValidate(types.Hash32{}, tx.Raw)
+		require.ErrorIs(t, err, errFeeTooLow)
+	})
+	t.Run("fee per byte too low", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cstate := NewMockconservativeState(ctrl)
+		tx := newTx(t, 3, 10, 1, signer)
+		floor := NewFeeFloor(FeeFloorConfig{MinFeePerByte: tx.Fee()/uint64(len(tx.Raw)) + 1})
+		th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithFeeFloor(floor))
+		cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil)
+		req := smocks.NewMockValidationRequest(ctrl)
+		req.EXPECT().Parse().Return(tx.TxHeader, nil)
+		cstate.EXPECT().Validation(tx.RawTx).Return(req)
+
+		_, err := th.PreValidate(types.Hash32{}, tx.Raw)
+		require.ErrorIs(t, err, errFeeTooLow)
+		var feeErr *FeeTooLowError
+		require.ErrorAs(t, err, &feeErr)
+		require.Equal(t, len(tx.Raw), feeErr.Size)
+		require.Equal(t, tx.Fee(), feeErr.Fee)
+	})
+	t.Run("valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cstate := NewMockconservativeState(ctrl)
+		th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithMaxTxSize(1024), WithMinGasPrice(5))
+		tx := newTx(t, 3, 10, 5, signer)
+		cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil)
+		req := smocks.NewMockValidationRequest(ctrl)
+		req.EXPECT().Parse().Return(tx.TxHeader, nil)
+		req.EXPECT().Verify().Return(true)
+		cstate.EXPECT().Validation(tx.RawTx).Return(req)
+
+		got, err := th.PreValidate(types.Hash32{}, tx.Raw)
+		require.NoError(t, err)
+		require.Equal(t, tx, got)
+	})
+	t.Run("replayed from foreign network", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cstate := NewMockconservativeState(ctrl)
+		testnet := types.Hash20{1}
+		th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithForeignNetworks(types.Hash20{2}, testnet))
+		tx := newTx(t, 3, 10, 1, signer)
+		cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil)
+		req := smocks.NewMockValidationRequest(ctrl)
+		req.EXPECT().Parse().Return(tx.TxHeader, nil)
+		req.EXPECT().Verify().Return(false)
+		req.EXPECT().VerifyGenesis(types.Hash20{2}).Return(false)
+		req.EXPECT().VerifyGenesis(testnet).Return(true)
+		cstate.EXPECT().Validation(tx.RawTx).Return(req)
+
+		err := th.VerifyAndCacheTx(context.Background(), tx.Raw)
+		require.ErrorIs(t, err, errForeignNetwork)
+		require.NotErrorIs(t, err, errVerify)
+	})
+	t.Run("gossip not checked against foreign networks", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cstate := NewMockconservativeState(ctrl)
+		th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithForeignNetworks(types.Hash20{1}))
+		tx := newTx(t, 3, 10, 1, signer)
+		cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil)
+		req := smocks.NewMockValidationRequest(ctrl)
+		req.EXPECT().Parse().Return(tx.TxHeader, nil)
+		req.EXPECT().Verify().Return(false)
+		cstate.EXPECT().Validation(tx.RawTx).Return(req)
+
+		_, err := th.PreValidate(types.Hash32{}, tx.Raw)
+		require.ErrorIs(t, err, errVerify)
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cstate := NewMockconservativeState(ctrl)
+		th := NewTxHandler(cstate, id, zaptest.NewLogger(t), WithForeignNetworks(types.Hash20{1}))
+		tx := newTx(t, 3, 10, 1, signer)
+		cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil)
+		req := smocks.NewMockValidationRequest(ctrl)
+		req.EXPECT().Parse().Return(tx.TxHeader, nil)
+		req.EXPECT().Verify().Return(false)
+		req.EXPECT().VerifyGenesis(types.Hash20{1}).Return(false)
+		cstate.EXPECT().Validation(tx.RawTx).Return(req)
+
+		err := th.VerifyAndCacheTx(context.Background(), tx.Raw)
+		require.ErrorIs(t, err, errVerify)
+	})
+}
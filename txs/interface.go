@@ -28,6 +28,13 @@ type vmState interface {
 	GetNonce(types.Address) (types.Nonce, error)
 }
 
+// SpendingEstimator is optionally implemented by the vm state to estimate the maximal spending
+// of transactions for templates with custom gas rules. It returns false if the template of
+// the transaction uses the default estimate.
+type SpendingEstimator interface {
+	MaxSpending(*types.TxHeader) (uint64, bool)
+}
+
 type conStateCache interface {
 	GetMempool() map[types.Address][]*NanoTX
 }
@@ -0,0 +1,67 @@
+package txs
+
+import (
+	"sync"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+)
+
+// mempoolSnapshot is the mempool as it was when transactions for a proposal were selected.
+type mempoolSnapshot map[types.Address][]*NanoTX
+
+// GetMempool implements conStateCache.
+func (s mempoolSnapshot) GetMempool() map[types.Address][]*NanoTX {
+	return s
+}
+
+// mempoolDiffs keeps the candidates of the last proposal to report the mempool diff
+// between consecutive proposals.
+type mempoolDiffs struct {
+	mu   sync.Mutex
+	prev map[types.TransactionID]struct{}
+}
+
+// diff returns the mempool diff for a proposal with the candidates from the mempool
+// and the included transactions. The candidates are remembered for the next proposal.
+func (d *mempoolDiffs) diff(
+	lid types.LayerID,
+	mempool map[types.Address][]*NanoTX,
+	included []types.TransactionID,
+) events.EventMempoolDiff {
+	candidates := make(map[types.TransactionID]struct{})
+	for _, ntxs := range mempool {
+		for _, ntx := range ntxs {
+			candidates[ntx.ID] = struct{}{}
+		}
+	}
+	inProposal := make(map[types.TransactionID]struct{}, len(included))
+	for _, tid := range included {
+		inProposal[tid] = struct{}{}
+	}
+	ev := events.EventMempoolDiff{
+		Layer:      lid,
+		Candidates: len(candidates),
+		Included:   included,
+	}
+	for tid := range candidates {
+		if _, ok := inProposal[tid]; !ok {
+			ev.Missed = append(ev.Missed, tid)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for tid := range candidates {
+		if _, ok := d.prev[tid]; !ok {
+			ev.Added = append(ev.Added, tid)
+		}
+	}
+	for tid := range d.prev {
+		if _, ok := candidates[tid]; !ok {
+			ev.Removed = append(ev.Removed, tid)
+		}
+	}
+	d.prev = candidates
+	return ev
+}
@@ -0,0 +1,43 @@
+package txs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestMempoolDiffs(t *testing.T) {
+	ntx := func(id byte) *NanoTX {
+		return &NanoTX{ID: types.TransactionID{id}}
+	}
+	ids := func(ids ...byte) []types.TransactionID {
+		rst := make([]types.TransactionID, 0, len(ids))
+		for _, id := range ids {
+			rst = append(rst, types.TransactionID{id})
+		}
+		return rst
+	}
+	var d mempoolDiffs
+
+	diff := d.diff(1, map[types.Address][]*NanoTX{
+		{1}: {ntx(1), ntx(2)},
+		{2}: {ntx(3)},
+	}, ids(1, 3))
+	require.Equal(t, types.LayerID(1), diff.Layer)
+	require.Equal(t, 3, diff.Candidates)
+	require.Equal(t, ids(1, 3), diff.Included)
+	require.Equal(t, ids(2), diff.Missed)
+	require.ElementsMatch(t, ids(1, 2, 3), diff.Added)
+	require.Empty(t, diff.Removed)
+
+	diff = d.diff(2, map[types.Address][]*NanoTX{
+		{1}: {ntx(2)},
+		{3}: {ntx(4)},
+	}, ids(2, 4))
+	require.Equal(t, 2, diff.Candidates)
+	require.Empty(t, diff.Missed)
+	require.Equal(t, ids(4), diff.Added)
+	require.ElementsMatch(t, ids(1, 3), diff.Removed)
+}
@@ -14,6 +14,8 @@ const (
 
 type item struct {
 	*NanoTX
+	// priority is set for txs of principals that are always ordered before others.
+	priority bool
 
 	// The index is needed by update and is maintained by the heap.Interface methods.
 	index int // The index of the item in the heap.
@@ -26,6 +28,10 @@ func (pq priorityQueue) Len() int { return len(pq) }
 
 // Less implements head.Interface.
 func (pq priorityQueue) Less(i, j int) bool {
+	// txs of priority principals go first regardless of the fee
+	if pq[i].priority != pq[j].priority {
+		return pq[i].priority
+	}
 	// We want Pop to give us the highest, not lowest, fee, so we use greater than here.
 	if pq[i].Fee() != pq[j].Fee() {
 		return pq[i].Fee() > pq[j].Fee()
@@ -77,16 +83,24 @@ type mempoolIterator struct {
 	gasRemaining uint64
 	pq           priorityQueue
 	txs          map[types.Address][]*NanoTX
+	priority     map[types.Address]struct{}
 }
 
 // newMempoolIterator builds and returns a mempoolIterator.
-func newMempoolIterator(logger *zap.Logger, cs conStateCache, gasLimit uint64) *mempoolIterator {
+// Txs of the priority principals are popped before all other txs.
+func newMempoolIterator(
+	logger *zap.Logger,
+	cs conStateCache,
+	gasLimit uint64,
+	priority map[types.Address]struct{},
+) *mempoolIterator {
 	txs := cs.GetMempool()
 	mi := &mempoolIterator{
 		logger:       logger,
 		gasRemaining: gasLimit,
 		pq:           make(priorityQueue, 0, len(txs)),
 		txs:          txs,
+		priority:     priority,
 	}
 	logger.Info("received mempool txs", zap.Int("num_accounts", len(txs)))
 	mi.buildPQ()
@@ -97,9 +111,11 @@ func (mi *mempoolIterator) buildPQ() {
 	i := 0
 	for addr, ntxs := range mi.txs {
 		ntx := ntxs[0]
+		_, priority := mi.priority[addr]
 		it := &item{
-			NanoTX: ntx,
-			index:  i,
+			NanoTX:   ntx,
+			priority: priority,
+			index:    i,
 		}
 		i++
 		mi.pq = append(mi.pq, it)
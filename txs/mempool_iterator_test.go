@@ -84,7 +84,7 @@ func TestPopAll(t *testing.T) {
 	mockCache := NewMockconStateCache(ctrl)
 	mockCache.EXPECT().GetMempool().Return(mempool)
 	gasLimit := uint64(3)
-	mi := newMempoolIterator(zaptest.NewLogger(t), mockCache, gasLimit)
+	mi := newMempoolIterator(zaptest.NewLogger(t), mockCache, gasLimit, nil)
 	testPopAll(t, mi, expected[:gasLimit])
 	require.NotEmpty(t, mempool)
 }
@@ -98,7 +98,7 @@ func TestPopAll_SkipSomeGasTooHigh(t *testing.T) {
 	// make the 2nd one too expensive to pick, therefore invalidated all txs from addr0
 	orderedByFee[1].MaxGas = 10
 	expected := []*NanoTX{orderedByFee[0], orderedByFee[4], orderedByFee[5]}
-	mi := newMempoolIterator(zaptest.NewLogger(t), mockCache, gasLimit)
+	mi := newMempoolIterator(zaptest.NewLogger(t), mockCache, gasLimit, nil)
 	testPopAll(t, mi, expected)
 	require.NotEmpty(t, mempool)
 }
@@ -109,7 +109,26 @@ func TestPopAll_ExhaustMempool(t *testing.T) {
 	mockCache := NewMockconStateCache(ctrl)
 	mockCache.EXPECT().GetMempool().Return(mempool)
 	gasLimit := uint64(100)
-	mi := newMempoolIterator(zaptest.NewLogger(t), mockCache, gasLimit)
+	mi := newMempoolIterator(zaptest.NewLogger(t), mockCache, gasLimit, nil)
 	testPopAll(t, mi, expected)
 	require.Empty(t, mempool)
 }
+
+func TestPopAll_Priority(t *testing.T) {
+	mempool, orderedByFee := makeMempool()
+	ctrl := gomock.NewController(t)
+	mockCache := NewMockconStateCache(ctrl)
+	mockCache.EXPECT().GetMempool().Return(mempool)
+	addr1 := types.Address{2, 3, 4}
+	expected := []*NanoTX{
+		mempool[addr1][0], // (2, 0)
+		mempool[addr1][1], // (2, 3)
+	}
+	for _, ntx := range orderedByFee {
+		if ntx.Principal != addr1 {
+			expected = append(expected, ntx)
+		}
+	}
+	mi := newMempoolIterator(zaptest.NewLogger(t), mockCache, 100, map[types.Address]struct{}{addr1: {}})
+	testPopAll(t, mi, expected)
+}
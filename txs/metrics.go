@@ -10,15 +10,18 @@ const (
 	namespace = "txs"
 
 	// labels for tx acceptance state by the cache.
-	duplicate           = "dupe"
-	saved               = "saved"
-	savedNoHdr          = "savedNoHdr"
-	cantParse           = "parse"
-	cantVerify          = "verify"
-	rejectedBadNonce    = "badNonce"
-	rejectedInternalErr = "err"
-	RawFromDB           = "raw"
-	updated             = "updated"
+	duplicate              = "dupe"
+	saved                  = "saved"
+	savedNoHdr             = "savedNoHdr"
+	cantParse              = "parse"
+	cantVerify             = "verify"
+	rejectedBadNonce       = "badNonce"
+	rejectedInternalErr    = "err"
+	rejectedTooLarge       = "too_large"
+	rejectedFeeTooLow      = "fee_too_low"
+	rejectedForeignNetwork = "foreign_network"
+	RawFromDB              = "raw"
+	updated                = "updated"
 
 	// label for tx acceptance state by the mempool.
 	mempool         = "mempool"
@@ -60,6 +63,42 @@ var (
 	)
 )
 
+var (
+	proposalPriorityTxs = metrics.NewCounter(
+		"proposal_priority_txs",
+		namespace,
+		"number of transactions of priority principals selected into proposals",
+		[]string{},
+	).WithLabelValues()
+	proposalPriorityGas = metrics.NewCounter(
+		"proposal_priority_gas",
+		namespace,
+		"max gas of transactions of priority principals selected into proposals",
+		[]string{},
+	).WithLabelValues()
+)
+
+var dormantAccounts = metrics.NewGauge(
+	"dormant_accounts",
+	namespace,
+	"number of accounts evicted from the mempool cache with infeasible transactions in the database",
+	[]string{},
+).WithLabelValues()
+
+var revertedTxs = metrics.NewCounter(
+	"reverted_txs",
+	namespace,
+	"number of applied transactions that returned to pending after their layer was reverted",
+	[]string{},
+).WithLabelValues()
+
+var feeFloor = metrics.NewGauge(
+	"fee_floor",
+	namespace,
+	"current fee floor per byte of transactions admitted to the mempool",
+	[]string{},
+).WithLabelValues()
+
 var (
 	cacheApplyDuration = metrics.NewHistogramWithBuckets(
 		"cache_apply_duration",
@@ -76,3 +115,20 @@ var (
 		prometheus.ExponentialBuckets(10_000_000, 2, 10),
 	).WithLabelValues()
 )
+
+var (
+	proposalInclusionLatency = metrics.NewHistogramWithBuckets(
+		"proposal_inclusion_latency_seconds",
+		namespace,
+		"time from receiving a transaction to its first inclusion in a proposal",
+		[]string{},
+		prometheus.ExponentialBuckets(1, 2, 14),
+	).WithLabelValues()
+	applicationLatency = metrics.NewHistogramWithBuckets(
+		"application_latency_seconds",
+		namespace,
+		"time from receiving a transaction to its application in a layer",
+		[]string{},
+		prometheus.ExponentialBuckets(1, 2, 14),
+	).WithLabelValues()
+)
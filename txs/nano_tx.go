@@ -17,6 +17,9 @@ type NanoTX struct {
 
 	Block types.BlockID
 	Layer types.LayerID
+
+	// maxSpending is set if spending of the transaction was estimated by its template.
+	maxSpending *uint64
 }
 
 // NewNanoTX converts a NanoTX instance from a MeshTransaction.
@@ -30,8 +33,23 @@ func NewNanoTX(mtx *types.MeshTransaction) *NanoTX {
 	}
 }
 
+// newNanoTX converts a NanoTX instance from a MeshTransaction, consulting the estimator
+// for the maximal spending of the transaction.
+func newNanoTX(mtx *types.MeshTransaction, estimator SpendingEstimator) *NanoTX {
+	ntx := NewNanoTX(mtx)
+	if estimator != nil {
+		if spending, ok := estimator.MaxSpending(&ntx.TxHeader); ok {
+			ntx.maxSpending = &spending
+		}
+	}
+	return ntx
+}
+
 // MaxSpending returns the maximal amount a transaction can spend.
 func (n *NanoTX) MaxSpending() uint64 {
+	if n.maxSpending != nil {
+		return *n.maxSpending
+	}
 	return n.Spending()
 }
 
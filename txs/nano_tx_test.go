@@ -139,3 +139,25 @@ func TestBetter(t *testing.T) {
 		require.Equal(t, better, ntx0.Better(ntx1, blockSeed))
 	}
 }
+
+type spendingEstimatorFunc func(*types.TxHeader) (uint64, bool)
+
+func (f spendingEstimatorFunc) MaxSpending(header *types.TxHeader) (uint64, bool) {
+	return f(header)
+}
+
+func TestNewNanoTX_SpendingEstimator(t *testing.T) {
+	sig, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	mtx := createMeshTX(t, sig, types.LayerID(13))
+
+	ntx := newNanoTX(mtx, spendingEstimatorFunc(func(*types.TxHeader) (uint64, bool) {
+		return 0, false
+	}))
+	require.Equal(t, mtx.MaxSpend+mtx.Fee(), ntx.MaxSpending())
+
+	ntx = newNanoTX(mtx, spendingEstimatorFunc(func(header *types.TxHeader) (uint64, bool) {
+		return header.MaxSpend, true
+	}))
+	require.Equal(t, mtx.MaxSpend, ntx.MaxSpending())
+}
@@ -0,0 +1,89 @@
+package txs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+)
+
+const pendingExportVersion = 1
+
+// PendingTx is a pending transaction in the export of the mempool.
+type PendingTx struct {
+	Raw      []byte    `json:"raw"`
+	Received time.Time `json:"received"`
+}
+
+type pendingExport struct {
+	Version int         `json:"version"`
+	Txs     []PendingTx `json:"txs"`
+}
+
+// ExportPending writes all pending transactions from the database to w, so that they can be
+// imported by another node with TxHandler.ImportPending. Transactions are ordered by principal
+// and nonce, the same database produces the same export.
+func ExportPending(db sql.Executor, w io.Writer) (int, error) {
+	pending, err := transactions.AddressesWithPendingTransactions(db)
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(pending, func(a, b types.AddressNonce) int {
+		return bytes.Compare(a.Address[:], b.Address[:])
+	})
+	export := pendingExport{Version: pendingExportVersion, Txs: []PendingTx{}}
+	for _, an := range pending {
+		mtxs, err := transactions.GetAcctPendingFromNonce(db, an.Address, an.Nonce)
+		if err != nil {
+			return 0, err
+		}
+		for _, mtx := range mtxs {
+			export.Txs = append(export.Txs, PendingTx{Raw: mtx.Raw, Received: mtx.Received.UTC()})
+		}
+	}
+	if err := json.NewEncoder(w).Encode(export); err != nil {
+		return 0, fmt.Errorf("encode pending txs: %w", err)
+	}
+	return len(export.Txs), nil
+}
+
+// ImportPending reads transactions exported by ExportPending from r and adds them to the
+// conservative cache, keeping the time when they were originally received. Transactions that are
+// known already or not valid anymore are skipped. Returns the number of imported transactions.
+func (th *TxHandler) ImportPending(ctx context.Context, r io.Reader) (int, error) {
+	var export pendingExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return 0, fmt.Errorf("decode pending txs: %w", err)
+	}
+	if export.Version != pendingExportVersion {
+		return 0, fmt.Errorf("unsupported pending txs export version %d", export.Version)
+	}
+	imported := 0
+	for _, ptx := range export.Txs {
+		tx, err := th.PreValidate(types.Hash32{}, ptx.Raw)
+		if err == nil {
+			err = th.state.AddToCache(ctx, tx, ptx.Received)
+		}
+		switch {
+		case errors.Is(err, errDuplicateTX):
+		case err != nil:
+			th.logger.Warn("skipping pending tx",
+				zap.Stringer("tx_id", types.NewRawTx(ptx.Raw).ID),
+				zap.Error(err),
+			)
+		default:
+			imported++
+		}
+	}
+	return imported, nil
+}
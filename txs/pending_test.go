@@ -0,0 +1,73 @@
+package txs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+	smocks "github.com/spacemeshos/go-spacemesh/system/mocks"
+)
+
+func TestExportImportPending(t *testing.T) {
+	db := statesql.InMemoryTest(t)
+	received := time.Unix(1000, 0).UTC()
+	var pending []*types.Transaction
+	for range 3 {
+		signer, err := signing.NewEdSigner()
+		require.NoError(t, err)
+		for nonce := range uint64(2) {
+			tx := newTx(t, nonce, defaultAmount, defaultFee, signer)
+			require.NoError(t, transactions.Add(db, tx, received))
+			pending = append(pending, tx)
+		}
+	}
+
+	var buf bytes.Buffer
+	n, err := ExportPending(db, &buf)
+	require.NoError(t, err)
+	require.Equal(t, len(pending), n)
+
+	var again bytes.Buffer
+	_, err = ExportPending(db, &again)
+	require.NoError(t, err)
+	require.Equal(t, buf.Bytes(), again.Bytes())
+
+	ctrl := gomock.NewController(t)
+	cstate := NewMockconservativeState(ctrl)
+	th := NewTxHandler(cstate, p2p.NoPeer, zaptest.NewLogger(t))
+	rejected := pending[0].ID
+	for _, tx := range pending {
+		cstate.EXPECT().GetMeshTransaction(tx.ID).Return(nil, nil)
+		req := smocks.NewMockValidationRequest(ctrl)
+		req.EXPECT().Parse().Return(tx.TxHeader, nil)
+		req.EXPECT().Verify().Return(true)
+		cstate.EXPECT().Validation(tx.RawTx).Return(req)
+	}
+	cstate.EXPECT().AddToCache(gomock.Any(), gomock.Any(), received).DoAndReturn(
+		func(_ context.Context, tx *types.Transaction, _ time.Time) error {
+			if tx.ID == rejected {
+				return errors.New("bad nonce")
+			}
+			return nil
+		}).Times(len(pending))
+	n, err = th.ImportPending(context.Background(), &buf)
+	require.NoError(t, err)
+	require.Equal(t, len(pending)-1, n)
+}
+
+func TestImportPending_Version(t *testing.T) {
+	th := NewTxHandler(NewMockconservativeState(gomock.NewController(t)), p2p.NoPeer, zaptest.NewLogger(t))
+	_, err := th.ImportPending(context.Background(), bytes.NewBufferString(`{"version":2,"txs":[]}`))
+	require.ErrorContains(t, err, "unsupported")
+}
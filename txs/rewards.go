@@ -0,0 +1,156 @@
+package txs
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/spacemeshos/economics/rewards"
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+)
+
+// expectedRewards are rewards that the node's own coinbase accounts receive in layers that
+// are not applied yet. They are a local estimate that only affects which transactions of these
+// accounts the node considers feasible, blocks are executed against the applied state only.
+//
+// Only one block of a layer is applied, so the smallest reward among the blocks of the layer
+// is expected. Rewards are estimated from the subsidy only, fees are not known before the block
+// is executed.
+type expectedRewards struct {
+	accounts map[types.Address]struct{}
+	layers   map[types.LayerID]map[types.BlockID]map[types.Address]uint64
+}
+
+func newExpectedRewards(accounts []types.Address) *expectedRewards {
+	r := &expectedRewards{
+		accounts: make(map[types.Address]struct{}, len(accounts)),
+		layers:   make(map[types.LayerID]map[types.BlockID]map[types.Address]uint64),
+	}
+	for _, addr := range accounts {
+		r.accounts[addr] = struct{}{}
+	}
+	return r
+}
+
+// add records rewards of the block for the own accounts. It returns false if the block
+// was already recorded.
+func (r *expectedRewards) add(lid types.LayerID, bid types.BlockID, amounts map[types.Address]uint64) bool {
+	blocks, ok := r.layers[lid]
+	if !ok {
+		blocks = make(map[types.BlockID]map[types.Address]uint64)
+		r.layers[lid] = blocks
+	}
+	if _, ok := blocks[bid]; ok {
+		return false
+	}
+	blocks[bid] = amounts
+	return true
+}
+
+// prune drops rewards of layers up to and including lid once the layer is applied. It is safe to call on nil.
+func (r *expectedRewards) prune(lid types.LayerID) {
+	if r == nil {
+		return
+	}
+	for layer := range r.layers {
+		if layer <= lid {
+			delete(r.layers, layer)
+		}
+	}
+}
+
+// incoming returns the sum of rewards expected by the account. It is safe to call on nil.
+func (r *expectedRewards) incoming(addr types.Address) uint64 {
+	if r == nil {
+		return 0
+	}
+	if _, ok := r.accounts[addr]; !ok {
+		return 0
+	}
+	var total uint64
+	for _, blocks := range r.layers {
+		least := uint64(math.MaxUint64)
+		for _, amounts := range blocks {
+			least = min(least, amounts[addr])
+		}
+		total += least
+	}
+	return total
+}
+
+// subsidyRewards returns the subsidy received by each account from the rewards of a block in the layer.
+// It follows the computation in the vm, except that fees are not accounted for.
+func subsidyRewards(lid types.LayerID, blockRewards []types.CoinbaseReward) (map[types.Address]uint64, error) {
+	subsidy := rewards.TotalSubsidyAtLayer(lid.Difference(types.FirstEffectiveGenesis()))
+	totalWeight := new(big.Rat)
+	for _, blockReward := range blockRewards {
+		totalWeight.Add(totalWeight, blockReward.Weight.ToBigRat())
+	}
+	result := make(map[types.Address]uint64, len(blockRewards))
+	for _, blockReward := range blockRewards {
+		relative := blockReward.Weight.ToBigRat()
+		relative.Quo(relative, totalWeight)
+		reward := new(big.Int).SetUint64(subsidy)
+		reward.Mul(reward, relative.Num()).Quo(reward, relative.Denom())
+		if !reward.IsUint64() {
+			return nil, fmt.Errorf("subsidy reward %v for %v overflows uint64", reward, blockReward.Coinbase)
+		}
+		result[blockReward.Coinbase] += reward.Uint64()
+	}
+	return result, nil
+}
+
+// ExpectRewards records rewards of a block that is not applied yet for the own accounts
+// and reconsiders their pending transactions with the expected balance.
+func (c *Cache) ExpectRewards(
+	db sql.StateDatabase,
+	lid types.LayerID,
+	bid types.BlockID,
+	blockRewards []types.CoinbaseReward,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rewards == nil {
+		return nil
+	}
+	applied, err := layers.GetLastApplied(db)
+	if err != nil {
+		return fmt.Errorf("get last applied: %w", err)
+	}
+	if lid <= applied || lid < types.FirstEffectiveGenesis() {
+		return nil
+	}
+	all, err := subsidyRewards(lid, blockRewards)
+	if err != nil {
+		return err
+	}
+	// blocks without rewards for an account are recorded as well, as they bring the expectation to zero
+	amounts := make(map[types.Address]uint64, len(c.rewards.accounts))
+	for addr := range c.rewards.accounts {
+		amounts[addr] = all[addr]
+	}
+	if !c.rewards.add(lid, bid, amounts) {
+		return nil
+	}
+	for addr := range c.rewards.accounts {
+		acct, ok := c.account(addr)
+		if !ok {
+			continue
+		}
+		c.logger.Debug("expected rewards changed",
+			zap.Stringer("address", addr),
+			zap.Uint32("layer_id", lid.Uint32()),
+			zap.Stringer("block_id", bid),
+			zap.Uint64("incoming", c.rewards.incoming(addr)),
+		)
+		nextNonce, balance := c.stateF(addr)
+		if err := acct.resetAfterApply(c.logger, db, nextNonce, balance, applied); err != nil {
+			return fmt.Errorf("reset account %s: %w", addr, err)
+		}
+	}
+	return nil
+}
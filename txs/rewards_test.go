@@ -0,0 +1,130 @@
+package txs
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/spacemeshos/economics/rewards"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/statesql"
+)
+
+func TestExpectedRewards(t *testing.T) {
+	own := types.Address{1}
+	other := types.Address{2}
+	r := newExpectedRewards([]types.Address{own})
+	require.Zero(t, r.incoming(own))
+
+	require.True(t, r.add(10, types.BlockID{1}, map[types.Address]uint64{own: 100}))
+	require.False(t, r.add(10, types.BlockID{1}, map[types.Address]uint64{own: 200}))
+	require.True(t, r.add(11, types.BlockID{2}, map[types.Address]uint64{own: 50}))
+	require.Equal(t, uint64(150), r.incoming(own))
+	require.Zero(t, r.incoming(other))
+
+	// only one block of the layer is applied
+	require.True(t, r.add(10, types.BlockID{3}, map[types.Address]uint64{own: 30}))
+	require.Equal(t, uint64(80), r.incoming(own))
+	require.True(t, r.add(10, types.BlockID{4}, map[types.Address]uint64{}))
+	require.Equal(t, uint64(50), r.incoming(own))
+
+	r.prune(10)
+	require.Equal(t, uint64(50), r.incoming(own))
+	r.prune(11)
+	require.Zero(t, r.incoming(own))
+
+	var disabled *expectedRewards
+	require.Zero(t, disabled.incoming(own))
+	disabled.prune(11)
+}
+
+func TestSubsidyRewards(t *testing.T) {
+	types.SetLayersPerEpoch(4)
+	lid := types.FirstEffectiveGenesis().Add(10)
+	subsidy := rewards.TotalSubsidyAtLayer(10)
+	a, b := types.Address{1}, types.Address{2}
+	got, err := subsidyRewards(lid, []types.CoinbaseReward{
+		{Coinbase: a, Weight: types.RatNum{Num: 1, Denom: 4}},
+		{Coinbase: b, Weight: types.RatNum{Num: 1, Denom: 2}},
+		{Coinbase: a, Weight: types.RatNum{Num: 1, Denom: 4}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[types.Address]uint64{a: 2 * (subsidy / 4), b: subsidy / 2}, got)
+}
+
+func TestConservativeState_ExpectRewards(t *testing.T) {
+	types.SetLayersPerEpoch(4)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	coinbase := types.GenerateAddress(signer.PublicKey().Bytes())
+
+	ctrl := gomock.NewController(t)
+	mvm := NewMockvmState(ctrl)
+	db := statesql.InMemory()
+	cs := NewConservativeState(mvm, db,
+		WithCSConfig(CSConfig{
+			BlockGasLimit:     math.MaxUint64,
+			NumTXsPerProposal: numTXsInProposal,
+			RewardAccounts:    []types.Address{coinbase},
+		}),
+		WithLogger(zaptest.NewLogger(t)),
+	)
+
+	tx1 := newTx(t, nonce, defaultAmount, defaultFee, signer)
+	tx2 := newTx(t, nonce+1, defaultAmount, defaultFee, signer)
+	spending := newNanoTX(&types.MeshTransaction{Transaction: *tx1}, nil).MaxSpending()
+	// the balance covers only the first transaction
+	balance := spending + 1
+	mvm.EXPECT().GetNonce(coinbase).Return(nonce, nil).AnyTimes()
+	mvm.EXPECT().GetBalance(coinbase).Return(balance, nil).AnyTimes()
+	require.NoError(t, cs.AddToCache(context.Background(), tx1, time.Now()))
+	require.ErrorIs(t, cs.AddToCache(context.Background(), tx2, time.Now()), errInsufficientBalance)
+	require.NoError(t, cs.AddToDB(tx2))
+
+	applied := types.FirstEffectiveGenesis().Add(1)
+	require.NoError(t, layers.SetApplied(db, applied, types.EmptyBlockID))
+	lid := applied.Add(1)
+	subsidy := rewards.TotalSubsidyAtLayer(lid.Difference(types.FirstEffectiveGenesis()))
+	blockRewards := []types.CoinbaseReward{
+		{Coinbase: coinbase, Weight: types.RatNum{Num: 1, Denom: 1}},
+	}
+
+	// rewards of applied layers are in the state already
+	require.NoError(t, cs.ExpectRewards(applied, types.BlockID{1}, blockRewards))
+	_, projected := cs.GetProjection(coinbase)
+	require.Equal(t, uint64(1), projected)
+
+	require.NoError(t, cs.ExpectRewards(lid, types.BlockID{2}, blockRewards))
+	require.True(t, cs.cache.Has(tx2.ID))
+	_, projected = cs.GetProjection(coinbase)
+	require.Equal(t, balance+subsidy-2*spending, projected)
+	_, appliedBalance := cs.GetProjectionWithCertainty(coinbase, types.ProjectApplied)
+	require.Equal(t, balance, appliedBalance)
+
+	t.Run("block without rewards for the account", func(t *testing.T) {
+		require.NoError(t, cs.ExpectRewards(lid, types.BlockID{3}, nil))
+		require.False(t, cs.cache.Has(tx2.ID))
+		_, projected := cs.GetProjection(coinbase)
+		require.Equal(t, uint64(1), projected)
+	})
+	t.Run("layer applied empty", func(t *testing.T) {
+		require.NoError(t, cs.ExpectRewards(lid.Add(1), types.BlockID{4}, blockRewards))
+		require.True(t, cs.cache.Has(tx2.ID))
+
+		require.NoError(t, cs.UpdateCache(context.Background(), lid, types.EmptyBlockID, nil, nil))
+		require.NoError(t, layers.SetApplied(db, lid, types.EmptyBlockID))
+		require.True(t, cs.cache.Has(tx2.ID), "rewards of the next layer are still expected")
+
+		require.NoError(t, cs.UpdateCache(context.Background(), lid.Add(1), types.EmptyBlockID, nil, nil))
+		require.False(t, cs.cache.Has(tx2.ID))
+		_, projected := cs.GetProjection(coinbase)
+		require.Equal(t, uint64(1), projected)
+	})
+}
@@ -0,0 +1,97 @@
+package txs
+
+import (
+	"sync"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// cacheShards is the number of shards the accounts of the Cache are split into.
+const cacheShards = 64
+
+// accountShard holds the accounts of the Cache that map to the shard by address.
+// Operations on a single account hold Cache.mu for reading and the lock of the shard.
+// Operations that span accounts hold Cache.mu exclusively and don't take shard locks.
+type accountShard struct {
+	mu      sync.Mutex
+	pending map[types.Address]*accountCache
+	dormant map[types.Address]dormantAccount
+}
+
+func (s *accountShard) reset() {
+	s.pending = make(map[types.Address]*accountCache)
+	s.dormant = make(map[types.Address]dormantAccount)
+}
+
+// txIndex is the index of the cached transactions. It's updated by accounts in different shards
+// concurrently and therefore has its own lock, that is only held for a single lookup or update.
+type txIndex struct {
+	mu  sync.RWMutex
+	txs map[types.TransactionID]*NanoTX
+}
+
+func newTXIndex() *txIndex {
+	return &txIndex{txs: make(map[types.TransactionID]*NanoTX)}
+}
+
+func (i *txIndex) get(tid types.TransactionID) *NanoTX {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.txs[tid]
+}
+
+func (i *txIndex) set(ntx *NanoTX) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.txs[ntx.ID] = ntx
+}
+
+func (i *txIndex) remove(tid types.TransactionID) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.txs, tid)
+}
+
+// all returns the indexed transactions. The caller holds Cache.mu exclusively, so that
+// there are no concurrent updates.
+func (i *txIndex) all() map[types.TransactionID]*NanoTX {
+	return i.txs
+}
+
+func (c *Cache) shard(addr types.Address) *accountShard {
+	return &c.shards[addr[types.AddressLength-1]%cacheShards]
+}
+
+// account returns the pending account. The caller holds Cache.mu exclusively, or for reading
+// together with the lock of the shard of the account.
+func (c *Cache) account(addr types.Address) (*accountCache, bool) {
+	acct, ok := c.shard(addr).pending[addr]
+	return acct, ok
+}
+
+// lockAccount holds the Cache for reading and locks the shard of the account.
+func (c *Cache) lockAccount(addr types.Address) func() {
+	c.mu.RLock()
+	s := c.shard(addr)
+	s.mu.Lock()
+	return func() {
+		s.mu.Unlock()
+		c.mu.RUnlock()
+	}
+}
+
+func (c *Cache) numPending() int {
+	n := 0
+	for i := range c.shards {
+		n += len(c.shards[i].pending)
+	}
+	return n
+}
+
+func (c *Cache) numDormant() int {
+	n := 0
+	for i := range c.shards {
+		n += len(c.shards[i].dormant)
+	}
+	return n
+}